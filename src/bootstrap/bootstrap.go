@@ -5,24 +5,46 @@ import (
 	"github.com/mikhailbolshakov/cryptocare/src/domain"
 	"github.com/mikhailbolshakov/cryptocare/src/domain/impl/arbitrage"
 	"github.com/mikhailbolshakov/cryptocare/src/domain/impl/auth"
+	"github.com/mikhailbolshakov/cryptocare/src/domain/impl/market"
 	"github.com/mikhailbolshakov/cryptocare/src/domain/impl/subscription"
+	"github.com/mikhailbolshakov/cryptocare/src/grpc"
 	"github.com/mikhailbolshakov/cryptocare/src/http"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/auth/impl"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/email"
+	kitGrpc "github.com/mikhailbolshakov/cryptocare/src/kit/grpc"
 	kitHttp "github.com/mikhailbolshakov/cryptocare/src/kit/http"
 	kitService "github.com/mikhailbolshakov/cryptocare/src/kit/service"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	"github.com/mikhailbolshakov/cryptocare/src/repository/rates"
 	"github.com/mikhailbolshakov/cryptocare/src/repository/storage"
 	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
 )
 
 type serviceImpl struct {
-	cfg                 *service.Config
-	http                *kitHttp.Server
-	arbitrageService    domain.ArbitrageService
-	bidProvider         domain.BidProvider
-	storageAdapter      storage.Adapter
-	bidTestGenerator    domain.BidGenerator
-	subscriptionService domain.SubscriptionService
+	cfg                     *service.Config
+	http                    *kitHttp.Server
+	grpc                    *kitGrpc.Server
+	arbitrageService        domain.ArbitrageService
+	bidProvider             domain.BidProvider
+	storageAdapter          storage.Adapter
+	bidTestGenerator        domain.BidGenerator
+	subscriptionService     domain.SubscriptionService
+	notificationChannels    domain.NotificationChannelRegistry
+	notificationRenderer    domain.NotificationRenderer
+	notificationOutbox      domain.NotificationOutbox
+	chainFeed               domain.ChainFeed
+	chainArchiver           domain.ChainArchiver
+	marketService           domain.MarketService
+	spreadDetector          domain.SpreadDetector
+	referenceRates          domain.ReferenceRateProvider
+	manualBidService        domain.ManualBidService
+	privateChainService     domain.PrivateChainService
+	telegramBot             domain.TelegramBot
+	telegramChannelVerifier domain.TelegramChannelVerifier
+	emailVerifier           domain.EmailVerifier
+	telegramAlerts          domain.TelegramAlerts
+	telegramBots            domain.TelegramBotRegistry
 }
 
 // New creates a new instance of the service
@@ -30,8 +52,10 @@ func New() kitService.Service {
 	s := &serviceImpl{}
 
 	s.storageAdapter = storage.NewAdapter()
-	s.bidProvider = arbitrage.NewBidProviderService(s.storageAdapter)
+	s.bidProvider = arbitrage.NewBidProviderService(s.storageAdapter, s.storageAdapter)
 	s.bidTestGenerator = arbitrage.NewBidGenerator(s.storageAdapter)
+	s.chainArchiver = arbitrage.NewChainArchiver(s.storageAdapter, s.storageAdapter)
+	s.manualBidService = arbitrage.NewManualBidService(s.storageAdapter)
 
 	return s
 }
@@ -53,12 +77,62 @@ func (s *serviceImpl) Init(ctx context.Context) error {
 	// set log config
 	service.Logger.Init(s.cfg.Log)
 
-	telegramNotifier := subscription.NewTelegramNotifier(telegram.NewTelegram(service.LF()),
+	// reference rates source depends on config, defaults are taken if market isn't configured
+	var ratesCfg *service.ReferenceRates
+	if s.cfg.Market != nil {
+		ratesCfg = s.cfg.Market.ReferenceRates
+	}
+	ratesSource, err := rates.NewSource(ctx, ratesCfg)
+	if err != nil {
+		return err
+	}
+	s.referenceRates = market.NewReferenceRateProvider(ratesSource)
+	s.marketService = market.NewMarketService(s.storageAdapter, s.bidProvider, s.referenceRates)
+
+	s.notificationRenderer = subscription.NewNotificationRenderer()
+	telegramClient := telegram.NewTelegram(service.LF(), &telegram.Config{
+		BaseUrl:   s.cfg.Arbitrage.Notification.Telegram.BaseUrl,
+		RateLimit: s.cfg.Arbitrage.Notification.Telegram.RateLimit,
+	})
+	telegramNotifier := subscription.NewTelegramNotifier(telegramClient,
 		&subscription.TelegramOptions{
 			Bot: s.cfg.Arbitrage.Notification.Telegram.Bot,
 		})
-	s.subscriptionService = subscription.NewSubscriptionService(s.storageAdapter, telegramNotifier)
-	s.arbitrageService = arbitrage.NewArbitrageService(s.storageAdapter, s.bidProvider, s.subscriptionService)
+	s.telegramBots = subscription.NewTelegramBotRegistry(telegramClient, s.storageAdapter)
+	s.telegramAlerts = subscription.NewTelegramAlerts(telegramNotifier, s.telegramBots, s.notificationRenderer, s.storageAdapter, s.bidProvider)
+	s.notificationChannels = subscription.NewNotificationChannelRegistry(
+		subscription.NewTelegramChannel(telegramNotifier, s.telegramAlerts, s.telegramBots, s.notificationRenderer),
+		subscription.NewWebhookChannel(s.notificationRenderer, &subscription.WebhookOptions{
+			Timeout: time.Duration(s.cfg.Arbitrage.Notification.Webhook.TimeoutSec) * time.Second,
+		}),
+	)
+	// email channel is available if smtp server is configured
+	var emailClient email.Email
+	if emailCfg := s.cfg.Arbitrage.Notification.Email; emailCfg != nil && emailCfg.Host != "" {
+		emailClient = email.NewEmail(service.LF(), &email.Config{
+			Host:     emailCfg.Host,
+			Port:     emailCfg.Port,
+			User:     emailCfg.User,
+			Password: emailCfg.Password,
+		})
+		s.notificationChannels.Register(subscription.NewEmailChannel(
+			emailClient,
+			s.notificationRenderer,
+			&subscription.EmailOptions{
+				From: emailCfg.From,
+			}))
+	}
+	s.notificationOutbox = subscription.NewNotificationOutbox(s.storageAdapter, s.notificationChannels)
+	s.telegramChannelVerifier = subscription.NewTelegramChannelVerifier(telegramClient, s.storageAdapter, s.storageAdapter, s.storageAdapter)
+	s.emailVerifier = subscription.NewEmailVerifier(emailClient, s.storageAdapter, s.storageAdapter)
+	s.subscriptionService = subscription.NewSubscriptionService(s.storageAdapter, s.notificationChannels, s.notificationOutbox, s.notificationRenderer, s.telegramChannelVerifier,
+		s.emailVerifier, s.telegramBots, s.storageAdapter, s.storageAdapter, s.storageAdapter)
+	s.chainFeed = subscription.NewChainFeed()
+	s.arbitrageService = arbitrage.NewArbitrageService(s.storageAdapter, s.storageAdapter, s.bidProvider, s.referenceRates,
+		[]domain.ChainUpdateNotifier{s.telegramAlerts}, s.subscriptionService, s.chainFeed)
+	s.spreadDetector = arbitrage.NewSpreadDetector(s.storageAdapter, s.bidProvider, s.subscriptionService)
+	s.privateChainService = arbitrage.NewPrivateChainService(s.bidProvider, s.referenceRates, s.subscriptionService)
+	s.telegramBot = subscription.NewTelegramBot(telegramClient, telegramNotifier, s.subscriptionService, s.arbitrageService, s.storageAdapter, s.telegramChannelVerifier)
 
 	// create HTTP server
 	s.http = kitHttp.NewHttpServer(s.cfg.Http, service.LF())
@@ -78,7 +152,7 @@ func (s *serviceImpl) Init(ctx context.Context) error {
 
 	// setup routes & controllers
 	routers := []kitHttp.RouteSetter{
-		http.NewRouter(http.NewController(s.arbitrageService, sessionService, userService, s.subscriptionService, s.bidProvider), routeBuilder),
+		http.NewRouter(http.NewController(s.arbitrageService, sessionService, userService, s.subscriptionService, s.bidProvider, s.marketService, s.spreadDetector, s.manualBidService, s.privateChainService, s.notificationOutbox, s.telegramBot, s.telegramChannelVerifier, s.telegramBots, s.emailVerifier), routeBuilder),
 	}
 	for _, r := range routers {
 		if err := r.Set(); err != nil {
@@ -86,13 +160,37 @@ func (s *serviceImpl) Init(ctx context.Context) error {
 		}
 	}
 
+	// create gRPC server and register services
+	s.grpc = kitGrpc.NewGrpcServer(s.cfg.Grpc, service.LF(), sessionService, authorizeSession, resourcePolicyManager)
+	grpcServices := []kitGrpc.ServiceSetter{
+		grpc.NewRouter(s.grpc, s.arbitrageService, s.subscriptionService, s.bidProvider, s.chainFeed),
+	}
+	for _, r := range grpcServices {
+		if err := r.Set(); err != nil {
+			return err
+		}
+	}
+
 	// init services
 	s.arbitrageService.Init(s.cfg)
 	sessionService.Init(s.cfg.Auth)
 	s.bidTestGenerator.Init(s.cfg)
 	s.bidProvider.Init(s.cfg)
+	s.manualBidService.Init(s.cfg)
+	s.chainArchiver.Init(s.cfg)
+	s.spreadDetector.Init(s.cfg)
+	s.privateChainService.Init(s.cfg)
+	s.referenceRates.Init(s.cfg)
+	s.marketService.Init(s.cfg)
+	s.notificationRenderer.Init(s.cfg)
 	s.subscriptionService.Init(s.cfg)
-	_ = telegramNotifier.Init(ctx)
+	s.chainFeed.Init(s.cfg)
+	s.notificationOutbox.Init(s.cfg)
+	s.telegramChannelVerifier.Init(s.cfg)
+	s.emailVerifier.Init(s.cfg)
+	s.telegramBots.Init(s.cfg)
+	s.telegramAlerts.Init(s.cfg)
+	s.telegramBot.Init(s.cfg)
 
 	if err := s.storageAdapter.Init(ctx, s.cfg); err != nil {
 		return err
@@ -106,9 +204,19 @@ func (s *serviceImpl) Start(ctx context.Context) error {
 	// start listening REST
 	s.http.Listen()
 
+	// start listening gRPC
+	s.grpc.Listen()
+
 	// run bids generator for development mode
 	if s.cfg.Dev.Enabled {
-		s.bidTestGenerator.Run(ctx)
+		if err := s.bidTestGenerator.Run(ctx); err != nil {
+			return err
+		}
+	}
+
+	// start refreshing reference rates
+	if err := s.referenceRates.Run(ctx); err != nil {
+		return err
 	}
 
 	// start background arbitrage
@@ -116,12 +224,55 @@ func (s *serviceImpl) Start(ctx context.Context) error {
 		return err
 	}
 
+	// start detecting spreads
+	if err := s.spreadDetector.Run(ctx); err != nil {
+		return err
+	}
+
+	// start notifying owners about chains with their private bids
+	if err := s.privateChainService.Run(ctx); err != nil {
+		return err
+	}
+
+	// start delivering notifications
+	if err := s.notificationOutbox.Run(ctx); err != nil {
+		return err
+	}
+
+	// start sending digests
+	if err := s.subscriptionService.Run(ctx); err != nil {
+		return err
+	}
+
+	// start receiving bot commands
+	if err := s.telegramBot.Run(ctx); err != nil {
+		return err
+	}
+
+	if err := s.telegramAlerts.Run(ctx); err != nil {
+		return err
+	}
+
+	// start archiving expiring chains
+	if err := s.chainArchiver.Run(ctx); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func (s *serviceImpl) Close(ctx context.Context) {
 	s.bidTestGenerator.Stop(ctx)
 	_ = s.arbitrageService.StopCalculation(ctx)
+	_ = s.chainArchiver.Stop(ctx)
+	_ = s.spreadDetector.Stop(ctx)
+	_ = s.privateChainService.Stop(ctx)
+	_ = s.subscriptionService.Stop(ctx)
+	_ = s.notificationOutbox.Stop(ctx)
+	_ = s.telegramBot.Stop(ctx)
+	_ = s.telegramAlerts.Stop(ctx)
+	_ = s.referenceRates.Stop(ctx)
 	_ = s.storageAdapter.Close(ctx)
 	s.http.Close()
+	s.grpc.Close()
 }
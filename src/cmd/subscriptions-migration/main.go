@@ -0,0 +1,35 @@
+// subscriptions-migration copies subscriptions from aerospike to postgres
+// it uses the same configuration as the service
+package main
+
+import (
+	"context"
+	kitContext "github.com/mikhailbolshakov/cryptocare/src/kit/context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/repository/storage"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"os"
+)
+
+func main() {
+
+	// init context
+	ctx := kitContext.NewRequestCtx().Empty().WithNewRequestId().ToContext(context.Background())
+
+	l := service.L().Mth("subscriptions-migration")
+
+	// load config
+	cfg, err := service.LoadConfig()
+	if err != nil {
+		l.E(err).St().Err("config")
+		os.Exit(1)
+	}
+	service.Logger.Init(cfg.Log)
+
+	count, err := storage.MigrateSubscriptionsAeroToPg(ctx, cfg)
+	if err != nil {
+		l.E(err).St().F(log.FF{"copied": count}).Err("migration failed")
+		os.Exit(1)
+	}
+	l.F(log.FF{"copied": count}).Inf("migration completed")
+}
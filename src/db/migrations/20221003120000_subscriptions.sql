@@ -0,0 +1,22 @@
+-- +goose Up
+set schema 'trading';
+
+create table subscriptions
+(
+  id uuid primary key,
+  user_id varchar,
+  is_active boolean not null,
+  filter jsonb,
+  details jsonb,
+  created_at timestamp not null,
+  updated_at timestamp not null,
+  deleted_at timestamp null
+);
+
+create index idx_subs_user_id on subscriptions(user_id);
+create index idx_subs_active on subscriptions(is_active) where deleted_at is null;
+
+-- +goose Down
+set schema 'trading';
+
+drop table subscriptions;
@@ -0,0 +1,23 @@
+-- +goose Up
+set schema 'trading';
+
+create table archived_chains
+(
+  id varchar primary key,
+  asset varchar not null,
+  profit_share numeric not null,
+  depth int not null,
+  data jsonb,
+  chain_created_at timestamp not null,
+  expires_at timestamp null,
+  created_at timestamp not null,
+  updated_at timestamp not null,
+  deleted_at timestamp null
+);
+
+create index idx_archived_chains_created_at on archived_chains(chain_created_at);
+
+-- +goose Down
+set schema 'trading';
+
+drop table archived_chains;
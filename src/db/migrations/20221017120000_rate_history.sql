@@ -0,0 +1,23 @@
+-- +goose Up
+set schema 'trading';
+
+create table rate_history
+(
+  src_asset varchar not null,
+  trg_asset varchar not null,
+  exchange_code varchar not null,
+  method varchar not null,
+  point_time timestamp not null,
+  best_rate numeric not null,
+  median_rate numeric not null,
+  volume numeric not null,
+  bids int not null,
+  primary key (src_asset, trg_asset, point_time, exchange_code, method)
+);
+
+create index idx_rate_history_point_time on rate_history(point_time);
+
+-- +goose Down
+set schema 'trading';
+
+drop table rate_history;
@@ -0,0 +1,31 @@
+-- +goose Up
+set schema 'trading';
+
+create table notification_outbox
+(
+  id uuid primary key,
+  subscription_id varchar not null,
+  user_id varchar,
+  notification_id varchar not null,
+  channel varchar not null,
+  opportunity_type varchar not null,
+  opportunity_id varchar not null,
+  status varchar not null,
+  attempts int not null,
+  next_attempt_at timestamp not null,
+  last_error varchar,
+  delivered_at timestamp,
+  data jsonb not null,
+  created_at timestamp not null,
+  updated_at timestamp not null
+);
+
+-- workers claim due deliveries
+create index idx_outbox_due on notification_outbox(next_attempt_at) where status in ('pending', 'sending');
+create index idx_outbox_status on notification_outbox(status, created_at);
+create index idx_outbox_subs on notification_outbox(subscription_id);
+
+-- +goose Down
+set schema 'trading';
+
+drop table notification_outbox;
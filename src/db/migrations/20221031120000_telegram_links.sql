@@ -0,0 +1,28 @@
+-- +goose Up
+set schema 'trading';
+
+create table telegram_link_codes
+(
+  code varchar primary key,
+  user_id varchar not null,
+  url varchar,
+  expires_at timestamp not null
+);
+
+create table telegram_links
+(
+  user_id varchar primary key,
+  telegram_user_id bigint not null,
+  chat_id bigint not null,
+  username varchar,
+  created_at timestamp not null
+);
+
+-- telegram account is linked to one user only
+create unique index idx_telegram_links_tg_user on telegram_links(telegram_user_id);
+
+-- +goose Down
+set schema 'trading';
+
+drop table telegram_links;
+drop table telegram_link_codes;
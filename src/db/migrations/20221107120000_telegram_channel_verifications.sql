@@ -0,0 +1,22 @@
+-- +goose Up
+set schema 'trading';
+
+create table telegram_channel_verifications
+(
+  user_id varchar not null,
+  chat_id bigint not null,
+  code varchar,
+  status varchar not null,
+  method varchar,
+  expires_at timestamp not null,
+  verified_at timestamp,
+  created_at timestamp not null,
+  primary key (user_id, chat_id)
+);
+
+create index idx_telegram_channel_verifications_code on telegram_channel_verifications(chat_id, code);
+
+-- +goose Down
+set schema 'trading';
+
+drop table telegram_channel_verifications;
@@ -0,0 +1,28 @@
+-- +goose Up
+set schema 'trading';
+
+create table telegram_alerts
+(
+  chat_id bigint not null,
+  message_id bigint not null,
+  chain_id varchar not null,
+  delivery_id varchar not null,
+  subscription_id varchar not null,
+  user_id varchar,
+  profit double precision not null,
+  status varchar not null,
+  pending boolean not null,
+  data jsonb not null,
+  created_at timestamp not null,
+  updated_at timestamp not null,
+  primary key (chat_id, message_id)
+);
+
+-- alerts of recalculated chains and alerts the worker refreshes
+create index idx_telegram_alerts_chain on telegram_alerts(chain_id) where status = 'active';
+create index idx_telegram_alerts_updated on telegram_alerts(updated_at);
+
+-- +goose Down
+set schema 'trading';
+
+drop table telegram_alerts;
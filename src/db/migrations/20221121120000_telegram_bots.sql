@@ -0,0 +1,29 @@
+-- +goose Up
+set schema 'trading';
+
+create table telegram_bots
+(
+  id varchar primary key,
+  name varchar not null,
+  username varchar,
+  token varchar not null,
+  secondary_id varchar,
+  rate_per_sec double precision not null,
+  is_active boolean not null,
+  health varchar not null,
+  last_error varchar,
+  failed_at timestamp,
+  data jsonb not null,
+  created_at timestamp not null,
+  updated_at timestamp not null
+);
+
+-- messages are edited by the bot they were sent from
+alter table telegram_alerts add column bot_id varchar;
+
+-- +goose Down
+set schema 'trading';
+
+alter table telegram_alerts drop column bot_id;
+
+drop table telegram_bots;
@@ -0,0 +1,19 @@
+-- +goose Up
+set schema 'trading';
+
+create table email_verifications
+(
+  user_id varchar not null,
+  email varchar not null,
+  code varchar,
+  status varchar not null,
+  expires_at timestamp not null,
+  verified_at timestamp,
+  created_at timestamp not null,
+  primary key (user_id, email)
+);
+
+-- +goose Down
+set schema 'trading';
+
+drop table email_verifications;
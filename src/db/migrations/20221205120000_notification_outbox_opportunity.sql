@@ -0,0 +1,19 @@
+-- +goose Up
+set schema 'trading';
+
+-- duplicates recorded before, the earliest delivery is kept
+delete from notification_outbox o
+  using notification_outbox d
+where o.opportunity_id <> ''
+  and d.subscription_id = o.subscription_id
+  and d.notification_id = o.notification_id
+  and d.opportunity_id = o.opportunity_id
+  and (d.created_at, d.id) < (o.created_at, o.id);
+
+-- an opportunity is delivered once by a notification of the subscription, digests have no opportunity
+create unique index idx_outbox_opportunity on notification_outbox(subscription_id, notification_id, opportunity_id) where opportunity_id <> '';
+
+-- +goose Down
+set schema 'trading';
+
+drop index idx_outbox_opportunity;
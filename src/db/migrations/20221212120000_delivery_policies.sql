@@ -0,0 +1,32 @@
+-- +goose Up
+set schema 'trading';
+
+-- messages sent by subscriptions within throttling windows
+create table delivery_messages
+(
+  id uuid primary key,
+  subscription_id varchar not null,
+  sent_at timestamp not null
+);
+
+create index idx_delivery_messages_subs on delivery_messages(subscription_id, sent_at);
+create index idx_delivery_messages_sent on delivery_messages(sent_at);
+
+-- digests being collected, a subscription has no more than one pending digest
+create table pending_digests
+(
+  id uuid primary key,
+  subscription_id varchar not null,
+  matched int not null,
+  items jsonb not null,
+  started_at timestamp not null,
+  updated_at timestamp not null
+);
+
+create unique index idx_pending_digests_subs on pending_digests(subscription_id);
+
+-- +goose Down
+set schema 'trading';
+
+drop table pending_digests;
+drop table delivery_messages;
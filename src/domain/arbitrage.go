@@ -13,32 +13,70 @@ const (
 	BidTypeManual = "manual"
 )
 
+// DefaultBidTtlSec ttl of bids if it isn't specified or configured
+const DefaultBidTtlSec = 60 * 60 * 4
+
+const (
+	ChainSortFieldProfit    = "profit"    // ChainSortFieldProfit sort chains by profit share
+	ChainSortFieldCreatedAt = "createdAt" // ChainSortFieldCreatedAt sort chains by creation time
+	ChainSortFieldScore     = "score"     // ChainSortFieldScore sort chains by score
+	// ChainSortFieldBaseProfit sort chains by absolute profit in the base currency
+	ChainSortFieldBaseProfit = "baseProfit"
+	// ChainSortFieldBaseVolume sort chains by executable volume in the base currency
+	ChainSortFieldBaseVolume = "baseVolume"
+)
+
+const (
+	// DefaultChainsPageSize page size of chains if it isn't specified
+	DefaultChainsPageSize = 100
+	// MaxChainsPageSize max page size of chains, a larger page is rejected
+	MaxChainsPageSize = 1000
+)
+
 // Bid is a bid exposed on the exchange
 type Bid struct {
-	Id           string   `json:"id"`           // Id
-	Type         string   `json:"type"`         // Type (p2p, spot)
-	SrcAsset     string   `json:"src"`          // SrcAsset - source asset
-	TrgAsset     string   `json:"trg"`          // TrgAsset - target asset
-	Rate         float64  `json:"rate"`         // Rate - conversion rate
-	ExchangeCode string   `json:"exchangeCode"` // ExchangeCode - exchange code
-	Available    float64  `json:"available"`    // Available available volume
-	MinLimit     float64  `json:"minLimit"`     // MinLimit - minimum limit
-	MaxLimit     float64  `json:"maxLimit"`     // MaxLimit - max limit
-	Methods      []string `json:"methods"`      // Methods - methods
-	UserId       string   `json:"userId"`       // UserId - user who expose the bid
-	Link         string   `json:"link"`         // Link - link to the bid
+	Id           string    `json:"id"`           // Id
+	Type         string    `json:"type"`         // Type (p2p, spot)
+	SrcAsset     string    `json:"src"`          // SrcAsset - source asset
+	TrgAsset     string    `json:"trg"`          // TrgAsset - target asset
+	Rate         float64   `json:"rate"`         // Rate - conversion rate
+	ExchangeCode string    `json:"exchangeCode"` // ExchangeCode - exchange code
+	Available    float64   `json:"available"`    // Available available volume
+	MinLimit     float64   `json:"minLimit"`     // MinLimit - minimum limit
+	MaxLimit     float64   `json:"maxLimit"`     // MaxLimit - max limit
+	Methods      []string  `json:"methods"`      // Methods - methods
+	UserId       string    `json:"userId"`       // UserId - user who expose the bid
+	Link         string    `json:"link"`         // Link - link to the bid
+	ObservedAt   time.Time `json:"observedAt"`   // ObservedAt - when the bid has been observed on the source
+	IngestedAt   time.Time `json:"ingestedAt"`   // IngestedAt - when the bid has been put to the storage
+	OwnerId      string    `json:"ownerId"`      // OwnerId - user who manages the manual bid, empty for bids of exchanges
+	ExpiresAt    time.Time `json:"expiresAt"`    // ExpiresAt - when the bid expires in the storage
+	Private      bool      `json:"private"`      // Private - if true, the bid is taken into account only in calculations of the owner
 }
 
 // Bid is a bid exposed on the exchange
 type BidLight struct {
-	Id        string  `json:"id"`        // Id
-	Type      string  `json:"type"`      // Type (p2p, spot)
-	SrcAsset  string  `json:"src"`       // SrcAsset - source asset
-	TrgAsset  string  `json:"trg"`       // TrgAsset - target asset
-	Rate      float64 `json:"rate"`      // Rate - conversion rate
-	Available float64 `json:"available"` // Available - available amount of asset
-	MinLimit  float64 `json:"minLimit"`  // MinLimit - bid min limit
-	MaxLimit  float64 `json:"maxLimit"`  // MaxLimit - bid max limit
+	Id           string    `json:"id"`           // Id
+	Type         string    `json:"type"`         // Type (p2p, spot)
+	SrcAsset     string    `json:"src"`          // SrcAsset - source asset
+	TrgAsset     string    `json:"trg"`          // TrgAsset - target asset
+	Rate         float64   `json:"rate"`         // Rate - conversion rate
+	Available    float64   `json:"available"`    // Available - available amount of asset
+	MinLimit     float64   `json:"minLimit"`     // MinLimit - bid min limit
+	MaxLimit     float64   `json:"maxLimit"`     // MaxLimit - bid max limit
+	ExchangeCode string    `json:"exchangeCode"` // ExchangeCode - exchange code
+	Methods      []string  `json:"methods"`      // Methods - methods
+	ObservedAt   time.Time `json:"observedAt"`   // ObservedAt - when the bid has been observed on the source
+	OwnerId      string    `json:"ownerId"`      // OwnerId - owner of the private bid
+}
+
+// ExchangeStaleness shows how fresh bids of the exchange are
+type ExchangeStaleness struct {
+	ExchangeCode     string    // ExchangeCode - exchange code
+	Bids             int       // Bids - number of bids
+	StaleBids        int       // StaleBids - number of bids older than the max age
+	OldestObservedAt time.Time // OldestObservedAt - when the oldest bid has been observed
+	LatestObservedAt time.Time // LatestObservedAt - when the latest bid has been observed
 }
 
 // CandidateChain is a sequence of bids to be a candidate to profitable chain
@@ -63,7 +101,17 @@ type ProfitableChain struct {
 	Bids          []*Bid    // Bids sequence of bids
 	Depth         int       // Depth chain depth
 	ExchangeCodes []string  // ExchangeCodes through all bids
+	BidTypes      []string  // BidTypes distinct types of bids
+	Score         float64   // Score profit (in percents) per one conversion, so shorter chains are scored higher
+	Volume        float64   // Volume executable volume in the chain asset, 0 if bids don't specify available amounts
+	BaseCurrency  string    // BaseCurrency currency BaseVolume and BaseProfit are normalized to, empty if reference rate of the asset is unknown
+	BaseVolume    float64   // BaseVolume executable volume in the base currency
+	BaseProfit    float64   // BaseProfit absolute profit in the base currency if the whole volume is executed
 	CreatedAt     time.Time // CreatedAt - when this chain has been created
+	ObservedAt    time.Time // ObservedAt - when the oldest bid of the chain has been observed, so it shows how old the chain quotes are
+	ExpiresAt     time.Time // ExpiresAt - when this chain expires in the hot storage, depends on the retention class
+	Archived      bool      // Archived - if the chain is retrieved from the archive
+	OwnerId       string    // OwnerId - user whose private bids the chain contains, such chains are never stored nor published globally
 }
 
 // ProfitableChains bilk of chains
@@ -72,17 +120,30 @@ type ProfitableChains struct {
 }
 
 // GetProfitableChainsRequest request to retrieve order chains
+// PagingRequest.SortBy supports fields: profit, createdAt, score, baseProfit, baseVolume. By default, the latest chains go first
+// if Cursor is specified, PagingRequest.Index is ignored and the page following the cursor is retrieved
+// if PagingRequest.Size isn't specified, DefaultChainsPageSize is taken, size above MaxChainsPageSize is rejected
 type GetProfitableChainsRequest struct {
 	kit.PagingRequest
-	Assets        []string // Assets - retrieves chains by the given assets
-	WithBids      bool     // WithBids - if true, retrieve chains with bids
-	Methods       []string // Methods - retrieves by methods
-	ExchangeCodes []string // ExchangeCodes - retrieves by exchange codes
+	Assets        []string   // Assets - retrieves chains by the given assets
+	WithBids      bool       // WithBids - if true, retrieve chains with bids
+	Methods       []string   // Methods - retrieves chains which methods are among the given ones
+	ExchangeCodes []string   // ExchangeCodes - retrieves chains which exchanges are among the given ones
+	BidTypes      []string   // BidTypes - retrieves chains which bid types are among the given ones
+	MinProfit     float64    // MinProfit - min profit in percents
+	MaxProfit     float64    // MaxProfit - max profit in percents
+	MinDepth      int        // MinDepth - min chain depth
+	MaxDepth      int        // MaxDepth - max chain depth
+	MinBaseProfit float64    // MinBaseProfit - min absolute profit in the base currency
+	MinBaseVolume float64    // MinBaseVolume - min executable volume in the base currency
+	CreatedAfter  *time.Time // CreatedAfter - retrieves chains created after the given time
+	Cursor        string     // Cursor - cursor returned with the previous page
 }
 
 type GetProfitableChainsResponse struct {
 	kit.PagingResponse
-	Chains []*ProfitableChain
+	Chains     []*ProfitableChain
+	NextCursor string // NextCursor - cursor to retrieve the next page, empty if there are no more chains
 }
 
 // BidProvider provides bids data for analysis
@@ -97,10 +158,81 @@ type BidProvider interface {
 	GetAssets(ctx context.Context) ([]string, error)
 	// GetBidLightsBySourceAsset returns bods by the source asset
 	GetBidLightsBySourceAsset(ctx context.Context, srcAsset string) ([]*BidLight, error)
+	// GetBidLights returns all bids of the current snapshot
+	GetBidLights(ctx context.Context) ([]*BidLight, error)
 	// GetBidsByIds retrieves full bids by Ids
 	GetBidsByIds(ctx context.Context, ids []string) ([]*Bid, error)
-	// PutBid puts a manual bid
-	PutBid(ctx context.Context, bid *Bid) (*Bid, error)
+	// PutBids puts bids of exchanges in bulk. If type isn't specified, bid is considered as p2p
+	// ids given by the source are scoped with the exchange, manual bids aren't accepted
+	PutBids(ctx context.Context, bids []*Bid) ([]*Bid, error)
+	// GetExchangesStaleness returns bids freshness by exchanges
+	GetExchangesStaleness(ctx context.Context) ([]*ExchangeStaleness, error)
+	// GetPrivateBidLights returns private bids of the owner
+	GetPrivateBidLights(ctx context.Context, ownerId string) ([]*BidLight, error)
+	// GetPrivateBidsByIds retrieves full private bids by Ids
+	GetPrivateBidsByIds(ctx context.Context, ids []string) ([]*Bid, error)
+	// GetPrivateBidOwners returns users having private bids
+	GetPrivateBidOwners(ctx context.Context) ([]string, error)
+}
+
+// ManualBidRequest request to create or update a manual bid
+type ManualBidRequest struct {
+	SrcAsset     string    // SrcAsset - source asset
+	TrgAsset     string    // TrgAsset - target asset
+	Rate         float64   // Rate - conversion rate
+	ExchangeCode string    // ExchangeCode - exchange code, might be a name of the OTC desk
+	Available    float64   // Available - available volume
+	MinLimit     float64   // MinLimit - minimum limit
+	MaxLimit     float64   // MaxLimit - max limit
+	Methods      []string  // Methods - methods
+	Link         string    // Link - link to the quote
+	ObservedAt   time.Time // ObservedAt - when the quote has been observed, if empty, the current time is taken
+	TtlSec       int       // TtlSec - how long the bid lives, if empty, the configured default ttl of manual bids is taken
+	Private      bool      // Private - if true, the bid is combined with the public market only in calculations of the owner
+}
+
+// ManualBidService manages manual bids (e.g. OTC quotes) owned by users
+// only the owner or sysadmin is allowed to access the bid
+type ManualBidService interface {
+	// Init initializes service
+	Init(cfg *service.Config)
+	// CreateBids creates manual bids owned by the given user
+	CreateBids(ctx context.Context, ownerId string, rqs []*ManualBidRequest) ([]*Bid, error)
+	// UpdateBid updates manual bid, ttl is counted from the update time
+	UpdateBid(ctx context.Context, bidId string, rq *ManualBidRequest) (*Bid, error)
+	// DeleteBid withdraws manual bid
+	DeleteBid(ctx context.Context, bidId string) error
+	// GetBid retrieves manual bid by id
+	GetBid(ctx context.Context, bidId string) (*Bid, error)
+	// GetBids retrieves manual bids of the owner
+	GetBids(ctx context.Context, ownerId string) ([]*Bid, error)
+}
+
+// SearchChainsRequest request to find chains on demand combining the public market with private bids of the user
+type SearchChainsRequest struct {
+	UserId      string   // UserId - user whose private bids are taken into account
+	Assets      []string // Assets - assets chains start and end with, if empty, assets of the user's private bids are taken
+	OnlyPrivate bool     // OnlyPrivate - retrieves only chains containing private bids
+	Limit       int      // Limit - max number of chains, the most profitable go first
+}
+
+// PrivateChainNotifier responsible for notification the owner about chains containing their private bids
+type PrivateChainNotifier interface {
+	// NotifyPrivate notifies the user
+	NotifyPrivate(ctx context.Context, userId string, chains []*ProfitableChain) error
+}
+
+// PrivateChainService finds chains combining the public market with private bids of users
+// such chains are visible to the owner only and never go to the global feed
+type PrivateChainService interface {
+	// Init initializes service
+	Init(cfg *service.Config)
+	// Run runs worker which finds private chains and notifies owners
+	Run(ctx context.Context) error
+	// Stop stops worker
+	Stop(ctx context.Context) error
+	// SearchChains finds chains on demand
+	SearchChains(ctx context.Context, rq *SearchChainsRequest) ([]*ProfitableChain, error)
 }
 
 // Notifier responsible for notification users about chains
@@ -109,6 +241,12 @@ type Notifier interface {
 	Notify(ctx context.Context, chains []*ProfitableChain) error
 }
 
+// ChainUpdateNotifier responsible for notification about recalculated chains which have been found before
+type ChainUpdateNotifier interface {
+	// NotifyUpdated notifies about recalculated chains
+	NotifyUpdated(ctx context.Context, chains []*ProfitableChain) error
+}
+
 // ArbitrageService provides arbitrage functions
 type ArbitrageService interface {
 	// Init initializes service
@@ -119,13 +257,25 @@ type ArbitrageService interface {
 	StopCalculation(ctx context.Context) error
 	// GetProfitableChains retrieves profitable chains by criteria
 	GetProfitableChains(ctx context.Context, rq *GetProfitableChainsRequest) (*GetProfitableChainsResponse, error)
-	// GetProfitableChain retrieves profitable chain by id
+	// GetProfitableChain retrieves profitable chain by id. If the chain has expired, it's looked up in the archive
 	GetProfitableChain(ctx context.Context, chainId string) (*ProfitableChain, error)
+	// GetArchivedChain retrieves archived chain by id
+	GetArchivedChain(ctx context.Context, chainId string) (*ProfitableChain, error)
+}
+
+// ChainArchiver moves expiring chains to the archive storage
+type ChainArchiver interface {
+	// Init initializes archiver
+	Init(cfg *service.Config)
+	// Run runs archiver worker
+	Run(ctx context.Context) error
+	// Stop stops archiver worker
+	Stop(ctx context.Context) error
 }
 
-// BidGenerator generates bid data (for test purposes only) // TODO: remove
+// BidGenerator generates bids of the simulated market (for dev purposes only)
 type BidGenerator interface {
 	Init(cfg *service.Config)
-	Run(ctx context.Context)
+	Run(ctx context.Context) error
 	Stop(ctx context.Context)
 }
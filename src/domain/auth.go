@@ -18,6 +18,11 @@ const (
 	AuthResUserProfileAll     = "users.all"
 	AuthResUserProfileMy      = "users.my"
 	AuthResArbitrageChainsAll = "arbitrage.chains.all"
+	AuthResMarketAll          = "market.all"
+	AuthResArbitrageBidsMy    = "arbitrage.bids.my"
+	AuthResArbitrageBidsAll   = "arbitrage.bids.all"
+	AuthResNotificationsAll   = "notifications.all"
+	AuthResNotificationTpls   = "notifications.templates"
 )
 
 type UserService interface {
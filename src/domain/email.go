@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
+)
+
+const (
+	EmailVerificationPending  = "pending"  // EmailVerificationPending code is sent, waiting for confirmation
+	EmailVerificationVerified = "verified" // EmailVerificationVerified the user has confirmed the address
+)
+
+// EmailVerification confirmation the email address belongs to the user or the owner of the address agrees to receive notifications
+type EmailVerification struct {
+	UserId     string     // UserId user verifying the address
+	Email      string     // Email address (lowercase)
+	Code       string     // Code one-time code sent to the address
+	Status     string     // Status verification status
+	ExpiresAt  time.Time  // ExpiresAt the code expiration time
+	VerifiedAt *time.Time // VerifiedAt when verified
+	CreatedAt  time.Time  // CreatedAt when the code was sent
+}
+
+// EmailVerificationStorage provides an access to email verifications
+type EmailVerificationStorage interface {
+	// SaveEmailVerification creates or updates verification of the address by the user
+	SaveEmailVerification(ctx context.Context, v *EmailVerification) error
+	// GetEmailVerification retrieves verification of the address by the user, nil if not found
+	GetEmailVerification(ctx context.Context, userId, email string) (*EmailVerification, error)
+	// GetEmailVerifications retrieves verifications of the user
+	GetEmailVerifications(ctx context.Context, userId string) ([]*EmailVerification, error)
+}
+
+// EmailVerifier verifies email addresses notifications are sent to
+// a one-time code is sent to the address, the user confirms the address with the code
+type EmailVerifier interface {
+	// Init initializes verifier
+	Init(cfg *service.Config)
+	// Request sends a one-time code to the address
+	Request(ctx context.Context, userId, email string) (*EmailVerification, error)
+	// Confirm confirms the address with the code and activates email notifications of the user waiting for verification
+	Confirm(ctx context.Context, userId, email, code string) (*EmailVerification, error)
+	// GetVerifications retrieves verifications of the user
+	GetVerifications(ctx context.Context, userId string) ([]*EmailVerification, error)
+	// IsVerified checks if all the addresses are verified by the user
+	IsVerified(ctx context.Context, userId string, emails []string) (bool, error)
+}
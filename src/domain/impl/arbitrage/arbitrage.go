@@ -15,29 +15,60 @@ import (
 	"time"
 )
 
+const (
+	// defaultChainTtlSec ttl of chains if retention isn't configured
+	defaultChainTtlSec = 60 * 60
+)
+
+// chainSortFields fields allowed to sort chains by
+var chainSortFields = map[string]bool{
+	domain.ChainSortFieldProfit:     true,
+	domain.ChainSortFieldCreatedAt:  true,
+	domain.ChainSortFieldScore:      true,
+	domain.ChainSortFieldBaseProfit: true,
+	domain.ChainSortFieldBaseVolume: true,
+}
+
+// bidSource provides bids chains are searched over
+type bidSource interface {
+	// GetBidLightsBySourceAsset returns bids by the source asset
+	GetBidLightsBySourceAsset(ctx context.Context, srcAsset string) ([]*domain.BidLight, error)
+	// GetBidsByIds retrieves full bids by Ids
+	GetBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error)
+}
+
 type arbitrageSvcImpl struct {
 	bidProvider                 domain.BidProvider
 	chainStorage                domain.ChainStorage
+	chainArchive                domain.ChainArchiveStorage
+	referenceRates              domain.ReferenceRateProvider
 	assetsToCalculateChan       chan string
 	saveProfitableChainsChan    chan []*domain.ProfitableChain
 	processProfitableChainsChan chan []*domain.CandidateChain
 	profitableChainsNotifyChan  chan []*domain.ProfitableChain
+	profitableChainsUpdateChan  chan []*domain.ProfitableChain
 	cancelFunc                  context.CancelFunc
 	running                     *atomic.Bool
 	cfg                         *service.Config
-	notifier                    domain.Notifier
+	notifiers                   []domain.Notifier
+	updateNotifiers             []domain.ChainUpdateNotifier
 }
 
-func NewArbitrageService(chainStorage domain.ChainStorage, bidProvider domain.BidProvider, notifier domain.Notifier) domain.ArbitrageService {
+func NewArbitrageService(chainStorage domain.ChainStorage, chainArchive domain.ChainArchiveStorage, bidProvider domain.BidProvider,
+	referenceRates domain.ReferenceRateProvider, updateNotifiers []domain.ChainUpdateNotifier, notifiers ...domain.Notifier) domain.ArbitrageService {
 	return &arbitrageSvcImpl{
 		chainStorage:                chainStorage,
+		chainArchive:                chainArchive,
+		referenceRates:              referenceRates,
 		bidProvider:                 bidProvider,
 		assetsToCalculateChan:       make(chan string, 10),
 		processProfitableChainsChan: make(chan []*domain.CandidateChain, 10),
 		saveProfitableChainsChan:    make(chan []*domain.ProfitableChain, 10),
 		profitableChainsNotifyChan:  make(chan []*domain.ProfitableChain, 10),
+		profitableChainsUpdateChan:  make(chan []*domain.ProfitableChain, 10),
 		running:                     atomic.NewBool(false),
-		notifier:                    notifier,
+		notifiers:                   notifiers,
+		updateNotifiers:             updateNotifiers,
 	}
 }
 
@@ -60,7 +91,7 @@ func (s *arbitrageSvcImpl) copyChain(chain *domain.CandidateChain) *domain.Candi
 }
 
 // findChainsRecurse is a recursive func used for calculating one stage of deals
-func (s *arbitrageSvcImpl) findChainsRecurse(ctx context.Context, currentAsset, targetAsset string, chain *domain.CandidateChain, chains *domain.CandidateChains, depth int) error {
+func (s *arbitrageSvcImpl) findChainsRecurse(ctx context.Context, source bidSource, currentAsset, targetAsset string, chain *domain.CandidateChain, chains *domain.CandidateChains, depth int) error {
 
 	// create if nil
 	if chain == nil {
@@ -73,11 +104,14 @@ func (s *arbitrageSvcImpl) findChainsRecurse(ctx context.Context, currentAsset,
 	}
 
 	// request bids from provider
-	bids, err := s.bidProvider.GetBidLightsBySourceAsset(ctx, currentAsset)
+	bids, err := source.GetBidLightsBySourceAsset(ctx, currentAsset)
 	if err != nil {
 		return err
 	}
 
+	// bids with quotes older than max age aren't reliable
+	staleBefore := bidStaleBefore(s.cfg, kit.Now())
+
 	// go through bids and looking for possible conversions from the current asset
 	var amount float64
 	for _, r := range bids {
@@ -87,6 +121,10 @@ func (s *arbitrageSvcImpl) findChainsRecurse(ctx context.Context, currentAsset,
 			continue
 		}
 
+		if bidStale(r, staleBefore) {
+			continue
+		}
+
 		if s.cfg.Arbitrage.CheckLimit {
 			// skip chains which don't correspond minimum limits
 			// we take prev amount here because limit is specified in the source asset
@@ -119,7 +157,7 @@ func (s *arbitrageSvcImpl) findChainsRecurse(ctx context.Context, currentAsset,
 			chains.Chains = append(chains.Chains, ch)
 		} else {
 			// analyze further stages recursively
-			err = s.findChainsRecurse(ctx, r.TrgAsset, targetAsset, ch, chains, depth+1)
+			err = s.findChainsRecurse(ctx, source, r.TrgAsset, targetAsset, ch, chains, depth+1)
 			if err != nil {
 				return err
 			}
@@ -134,11 +172,18 @@ func (s *arbitrageSvcImpl) profitableChainGenId(bidIds []string) string {
 }
 
 // buildProfitableChains converts candidate chains to profitable chains
-func (s *arbitrageSvcImpl) buildProfitableChains(ctx context.Context, candidates []*domain.CandidateChain) ([]*domain.ProfitableChain, error) {
+// chains which are already stored are returned separately as recalculated ones
+func (s *arbitrageSvcImpl) buildProfitableChains(ctx context.Context, candidates []*domain.CandidateChain) ([]*domain.ProfitableChain, []*domain.ProfitableChain, error) {
+	return s.buildChains(ctx, s.bidProvider, candidates, true)
+}
+
+// buildChains converts candidate chains to profitable chains taking full bids from the source
+// if skipStored, chains which are already stored are returned as the second result (recalculated chains)
+func (s *arbitrageSvcImpl) buildChains(ctx context.Context, source bidSource, candidates []*domain.CandidateChain, skipStored bool) ([]*domain.ProfitableChain, []*domain.ProfitableChain, error) {
 	l := s.l().C(ctx).Mth("calc-profit").Trc()
 
 	if len(candidates) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	// gather bids Ids for all chains
@@ -151,9 +196,9 @@ func (s *arbitrageSvcImpl) buildProfitableChains(ctx context.Context, candidates
 	bidIds = kit.Strings(bidIds).Distinct()
 
 	// get full bids by ids from storage
-	bidDetails, err := s.bidProvider.GetBidsByIds(ctx, bidIds)
+	bidDetails, err := source.GetBidsByIds(ctx, bidIds)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// build map
@@ -163,7 +208,7 @@ func (s *arbitrageSvcImpl) buildProfitableChains(ctx context.Context, candidates
 	}
 
 	// for each candidate build a profitable chain
-	var profitableChains []*domain.ProfitableChain
+	var profitableChains, recalculatedChains []*domain.ProfitableChain
 	now := kit.Now()
 	for _, candidate := range candidates {
 		bidsCount := len(candidate.BidIds)
@@ -171,6 +216,8 @@ func (s *arbitrageSvcImpl) buildProfitableChains(ctx context.Context, candidates
 		var methods kit.Strings
 		var bidAssets kit.Strings
 		var exchangeCodes kit.Strings
+		var bidTypes kit.Strings
+		var observedAt time.Time
 		for i, bidId := range candidate.BidIds {
 			bid, ok := bidMap[bidId]
 			// turns out haven't found a full bid (e.g. the bid gone away already), so skip such candidate
@@ -181,18 +228,21 @@ func (s *arbitrageSvcImpl) buildProfitableChains(ctx context.Context, candidates
 			methods = append(methods, bid.Methods...)
 			bidAssets = append(bidAssets, bid.TrgAsset)
 			exchangeCodes = append(exchangeCodes, bid.ExchangeCode)
+			bidTypes = append(bidTypes, bid.Type)
+			if observedAt.IsZero() || (!bid.ObservedAt.IsZero() && bid.ObservedAt.Before(observedAt)) {
+				observedAt = bid.ObservedAt
+			}
 			// if the last bid, add a profitable chain
 			if i == bidsCount-1 {
 				// build chain id
 				chainId := s.profitableChainGenId(candidate.BidIds)
 				// check if profitable chain already exists
-				exists, err := s.chainStorage.ProfitableChainExists(ctx, chainId)
-				if err != nil {
-					return nil, err
-				}
-				if exists {
-					l.TrcF("%s exists", chainId)
-					break
+				exists := false
+				if skipStored {
+					exists, err = s.chainStorage.ProfitableChainExists(ctx, chainId)
+					if err != nil {
+						return nil, nil, err
+					}
 				}
 				bidAssets = append([]string{bids[i].TrgAsset}, bidAssets...)
 				chain := &domain.ProfitableChain{
@@ -204,7 +254,19 @@ func (s *arbitrageSvcImpl) buildProfitableChains(ctx context.Context, candidates
 					Bids:          bids,
 					Depth:         bidsCount,
 					ExchangeCodes: exchangeCodes.Distinct(),
+					BidTypes:      bidTypes.Distinct(),
+					Score:         s.chainScore(candidate.TotalRate, bidsCount),
+					ObservedAt:    observedAt,
 					CreatedAt:     now,
+					ExpiresAt:     now.Add(s.chainRetention(candidate.TotalRate)),
+					Volume:        chainVolume(bids),
+				}
+				s.normalizeChain(ctx, chain)
+				// stored chain isn't saved again, but its recalculated profit is still of interest (e.g. for live alerts)
+				if exists {
+					l.TrcF("%s exists", chainId)
+					recalculatedChains = append(recalculatedChains, chain)
+					break
 				}
 				profitableChains = append(profitableChains, chain)
 				l.DbgF("chain(%s): asset:%s; ", chain.Id, chain.Asset)
@@ -212,17 +274,88 @@ func (s *arbitrageSvcImpl) buildProfitableChains(ctx context.Context, candidates
 		}
 	}
 
-	// remove duplication
+	return distinctChains(profitableChains), distinctChains(recalculatedChains), nil
+}
+
+// distinctChains removes duplication
+func distinctChains(chains []*domain.ProfitableChain) []*domain.ProfitableChain {
 	chMap := make(map[string]*domain.ProfitableChain)
-	for _, ch := range profitableChains {
+	for _, ch := range chains {
 		chMap[ch.Id] = ch
 	}
-	profitableChains = []*domain.ProfitableChain{}
+	res := []*domain.ProfitableChain{}
 	for _, v := range chMap {
-		profitableChains = append(profitableChains, v)
+		res = append(res, v)
 	}
+	return res
+}
 
-	return profitableChains, nil
+// chainScore calculates score of the chain as profit in percents per one conversion
+func (s *arbitrageSvcImpl) chainScore(profitShare float64, depth int) float64 {
+	if depth == 0 {
+		return 0
+	}
+	return (profitShare - 1) * 100 / float64(depth)
+}
+
+// chainVolume calculates executable volume of the chain in the chain asset
+// each bid restricts the amount it takes by max limit (in the source asset) and available amount (in the target asset)
+// bids specifying neither available amount nor max limit don't restrict the volume
+func chainVolume(bids []*domain.Bid) float64 {
+	volume := math.Inf(1)
+	// rate converts the chain asset to the source asset of the current bid
+	rate := 1.0
+	for _, b := range bids {
+		if b.Rate <= 0.0 {
+			return 0
+		}
+		if b.MaxLimit > 0.0 {
+			volume = math.Min(volume, b.MaxLimit/rate)
+		}
+		if b.Available > 0.0 {
+			volume = math.Min(volume, b.Available/b.Rate/rate)
+		}
+		rate *= b.Rate
+	}
+	if math.IsInf(volume, 1) {
+		return 0
+	}
+	return volume
+}
+
+// normalizeChain calculates volume and absolute profit of the chain in the base currency
+// if reference rate of the chain asset is unknown, chain isn't normalized
+func (s *arbitrageSvcImpl) normalizeChain(ctx context.Context, chain *domain.ProfitableChain) {
+	baseVolume, ok := s.referenceRates.ToBase(ctx, chain.Asset, chain.Volume)
+	if !ok {
+		return
+	}
+	chain.BaseCurrency = s.referenceRates.BaseCurrency()
+	chain.BaseVolume = baseVolume
+	chain.BaseProfit = baseVolume * (chain.ProfitShare - 1)
+}
+
+// chainRetention defines how long the chain is kept in the storage depending on its profit
+// the class with the highest min profit not greater than the chain profit is taken
+func (s *arbitrageSvcImpl) chainRetention(profitShare float64) time.Duration {
+	if s.cfg.Retention == nil || s.cfg.Retention.Chains == nil {
+		return defaultChainTtlSec * time.Second
+	}
+	ttlSec := s.cfg.Retention.Chains.DefaultTtlSec
+	if ttlSec <= 0 {
+		ttlSec = defaultChainTtlSec
+	}
+	profit := (profitShare - 1) * 100
+	var class *service.ChainRetentionClass
+	for _, c := range s.cfg.Retention.Chains.Classes {
+		if profit >= c.MinProfit && (class == nil || c.MinProfit > class.MinProfit) {
+			class = c
+		}
+	}
+	if class != nil && class.TtlSec > 0 {
+		ttlSec = class.TtlSec
+	}
+	return time.Duration(ttlSec) * time.Second
 }
 
 func (s *arbitrageSvcImpl) assetsProviderWorker(ctx context.Context, tick time.Duration) {
@@ -270,7 +403,7 @@ func (s *arbitrageSvcImpl) findChainsWorker(ctx context.Context, numWorkers int)
 						// find chains
 						l.DbgF("analyzing %s", asset)
 						chains := &domain.CandidateChains{}
-						err := s.findChainsRecurse(ctx, asset, asset, nil, chains, 0)
+						err := s.findChainsRecurse(ctx, s.bidProvider, asset, asset, nil, chains, 0)
 						if err != nil {
 							l.E(err).Err("find chains")
 							continue
@@ -302,7 +435,7 @@ func (s *arbitrageSvcImpl) profitableChainsProcessWorker(ctx context.Context, nu
 					select {
 					case candidates := <-s.processProfitableChainsChan:
 						// calc profit
-						profitableChains, err := s.buildProfitableChains(ctx, candidates)
+						profitableChains, recalculatedChains, err := s.buildProfitableChains(ctx, candidates)
 						if err != nil {
 							l.E(err).Err("calc profit chains")
 							continue
@@ -310,6 +443,9 @@ func (s *arbitrageSvcImpl) profitableChainsProcessWorker(ctx context.Context, nu
 						if len(profitableChains) > 0 {
 							s.saveProfitableChainsChan <- profitableChains
 						}
+						if len(recalculatedChains) > 0 && len(s.updateNotifiers) > 0 {
+							s.profitableChainsUpdateChan <- recalculatedChains
+						}
 					case <-ctx.Done():
 						l.Inf("stop")
 						return
@@ -361,8 +497,15 @@ func (s *arbitrageSvcImpl) profitableChainsNotifyWorker(ctx context.Context, num
 					select {
 					case chains := <-s.profitableChainsNotifyChan:
 						l.TrcF("chains: %d", len(chains))
-						if s.notifier != nil {
-							if err := s.notifier.Notify(ctx, chains); err != nil {
+						for _, notifier := range s.notifiers {
+							if err := notifier.Notify(ctx, chains); err != nil {
+								s.l().C(ctx).Mth("chains-notify-worker").E(err).Err()
+							}
+						}
+					case chains := <-s.profitableChainsUpdateChan:
+						l.TrcF("recalculated chains: %d", len(chains))
+						for _, notifier := range s.updateNotifiers {
+							if err := notifier.NotifyUpdated(ctx, chains); err != nil {
 								s.l().C(ctx).Mth("chains-notify-worker").E(err).Err()
 							}
 						}
@@ -419,15 +562,55 @@ func (s *arbitrageSvcImpl) StopCalculation(ctx context.Context) error {
 	return nil
 }
 
+func (s *arbitrageSvcImpl) validateGetProfitableChainsRequest(ctx context.Context, rq *domain.GetProfitableChainsRequest) error {
+	if rq.Size <= 0 {
+		rq.Size = domain.DefaultChainsPageSize
+	}
+	if rq.Size > domain.MaxChainsPageSize {
+		return errors.ErrChainsPageSizeExceeded(ctx, domain.MaxChainsPageSize)
+	}
+	if rq.Index < 0 {
+		rq.Index = 0
+	}
+	for _, sortRq := range rq.SortBy {
+		if !chainSortFields[sortRq.Field] {
+			return errors.ErrChainSortFieldInvalid(ctx, sortRq.Field)
+		}
+	}
+	if rq.MinProfit < 0.0 || rq.MaxProfit < 0.0 || (rq.MaxProfit != 0.0 && rq.MinProfit > rq.MaxProfit) {
+		return errors.ErrChainProfitRangeInvalid(ctx)
+	}
+	if rq.MinDepth < 0 || rq.MaxDepth < 0 || (rq.MaxDepth != 0 && rq.MinDepth > rq.MaxDepth) {
+		return errors.ErrChainDepthRangeInvalid(ctx)
+	}
+	if rq.MinBaseProfit < 0.0 || rq.MinBaseVolume < 0.0 {
+		return errors.ErrChainBaseAmountInvalid(ctx)
+	}
+	return nil
+}
+
 func (s *arbitrageSvcImpl) GetProfitableChains(ctx context.Context, rq *domain.GetProfitableChainsRequest) (*domain.GetProfitableChainsResponse, error) {
 	s.l().C(ctx).Mth("get-profitable-chains").Trc()
-	if rq.Size <= 0 {
-		rq.Size = 100
+	if err := s.validateGetProfitableChainsRequest(ctx, rq); err != nil {
+		return nil, err
 	}
 	return s.chainStorage.GetProfitableChains(ctx, rq)
 }
 
 func (s *arbitrageSvcImpl) GetProfitableChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
 	s.l().C(ctx).Mth("get-profitable-chain-details").Trc()
-	return s.chainStorage.GetProfitableChain(ctx, chainId)
+	chain, err := s.chainStorage.GetProfitableChain(ctx, chainId)
+	if err != nil {
+		return nil, err
+	}
+	if chain != nil {
+		return chain, nil
+	}
+	// chain might have expired already, so look it up in the archive
+	return s.chainArchive.GetArchivedChain(ctx, chainId)
+}
+
+func (s *arbitrageSvcImpl) GetArchivedChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
+	s.l().C(ctx).Mth("get-archived-chain").F(log.FF{"chainId": chainId}).Trc()
+	return s.chainArchive.GetArchivedChain(ctx, chainId)
 }
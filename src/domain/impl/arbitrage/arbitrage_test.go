@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
 	"github.com/mikhailbolshakov/cryptocare/src/kit"
 	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
 	"github.com/mikhailbolshakov/cryptocare/src/mocks"
@@ -11,14 +12,17 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 type arbitrageTestSuite struct {
 	kitTestSuite.Suite
-	bidsProvider *mocks.BidProvider
-	chainStorage *mocks.ChainStorage
-	notifier     *mocks.Notifier
-	svc          domain.ArbitrageService
+	bidsProvider   *mocks.BidProvider
+	chainStorage   *mocks.ChainStorage
+	chainArchive   *mocks.ChainArchiveStorage
+	referenceRates *mocks.ReferenceRateProvider
+	notifier       *mocks.Notifier
+	svc            domain.ArbitrageService
 }
 
 func (s *arbitrageTestSuite) SetupSuite() {
@@ -32,9 +36,24 @@ func TestArbitrageSuite(t *testing.T) {
 func (s *arbitrageTestSuite) SetupTest() {
 	s.bidsProvider = &mocks.BidProvider{}
 	s.chainStorage = &mocks.ChainStorage{}
+	s.chainArchive = &mocks.ChainArchiveStorage{}
+	s.referenceRates = &mocks.ReferenceRateProvider{}
+	s.referenceRates.On("BaseCurrency").Return("USD").Maybe()
+	s.referenceRates.On("ToBase", mock.Anything, mock.Anything, mock.Anything).Return(0.0, false).Maybe()
 	s.notifier = &mocks.Notifier{}
-	s.svc = NewArbitrageService(s.chainStorage, s.bidsProvider, s.notifier)
-	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Depth: 5, MinProfit: 1.0005, CheckLimit: true}})
+	s.svc = NewArbitrageService(s.chainStorage, s.chainArchive, s.bidsProvider, s.referenceRates, nil, s.notifier)
+	s.svc.Init(&service.Config{
+		Arbitrage: &service.Arbitrage{Depth: 5, MinProfit: 1.0005, CheckLimit: true, BidMaxAgeSec: 600},
+		Retention: &service.Retention{
+			Chains: &service.ChainRetention{
+				DefaultTtlSec: 3600,
+				Classes: []*service.ChainRetentionClass{
+					{MinProfit: 5, TtlSec: 86400},
+					{MinProfit: 2, TtlSec: 10800},
+				},
+			},
+		},
+	})
 }
 
 var (
@@ -80,7 +99,7 @@ func (s *arbitrageTestSuite) Test_FindChains() {
 				m.ReturnArguments = mock.Arguments{bidsMap[args.Get(1).(string)], nil}
 			}
 			actual := &domain.CandidateChains{}
-			err := svc.findChainsRecurse(s.Ctx, tt.Asset, tt.Asset, nil, actual, 0)
+			err := svc.findChainsRecurse(s.Ctx, s.bidsProvider, tt.Asset, tt.Asset, nil, actual, 0)
 			s.Nil(err)
 			actualStr := s.ChainsToStr(actual)
 			s.Equal(actualStr, tt.Expected)
@@ -92,7 +111,7 @@ func (s *arbitrageTestSuite) Test_FindChains() {
 func (s *arbitrageTestSuite) Test_BuildProfitableChains_WhenEmptyCandidates_Empty_Ok() {
 	svc := s.svc.(*arbitrageSvcImpl)
 	var candidates []*domain.CandidateChain
-	profitableChains, err := svc.buildProfitableChains(s.Ctx, candidates)
+	profitableChains, _, err := svc.buildProfitableChains(s.Ctx, candidates)
 	s.Nil(err)
 	s.Empty(profitableChains)
 }
@@ -131,7 +150,7 @@ func (s *arbitrageTestSuite) Test_BuildProfitableChains_WhenSingleNewChain_Ok()
 	}
 	s.bidsProvider.On("GetBidsByIds", s.Ctx, candidates[0].BidIds).Return(bids, nil)
 	s.chainStorage.On("ProfitableChainExists", s.Ctx, mock.AnythingOfType("string")).Return(false, nil)
-	profitableChains, err := svc.buildProfitableChains(s.Ctx, candidates)
+	profitableChains, _, err := svc.buildProfitableChains(s.Ctx, candidates)
 	s.Nil(err)
 	s.Len(profitableChains, 1)
 	s.NotEmpty(profitableChains[0].Id)
@@ -141,10 +160,99 @@ func (s *arbitrageTestSuite) Test_BuildProfitableChains_WhenSingleNewChain_Ok()
 	s.Equal("USD", profitableChains[0].Asset)
 	s.Equal(2, profitableChains[0].Depth)
 	s.Equal(candidates[0].TotalRate, profitableChains[0].ProfitShare)
+	s.Equal([]string{domain.BidTypeP2P}, profitableChains[0].BidTypes)
+	s.InDelta(5.0, profitableChains[0].Score, 0.0001)
 	s.NotEmpty(profitableChains[0].CreatedAt)
+	s.Equal(profitableChains[0].CreatedAt.Add(time.Hour*24), profitableChains[0].ExpiresAt)
 	s.Len(profitableChains[0].Bids, 2)
 }
 
+func (s *arbitrageTestSuite) Test_ChainVolume() {
+	tests := []struct {
+		name   string
+		bids   []*domain.Bid
+		volume float64
+	}{
+		{
+			name: "unconstrained",
+			bids: []*domain.Bid{{Rate: 60}, {Rate: 0.02}},
+		},
+		{
+			name:   "max limit of the first bid",
+			bids:   []*domain.Bid{{Rate: 60, MaxLimit: 100}, {Rate: 0.02}},
+			volume: 100,
+		},
+		{
+			name:   "max limit of the second bid in its source asset",
+			bids:   []*domain.Bid{{Rate: 60, MaxLimit: 100}, {Rate: 0.02, MaxLimit: 3000}},
+			volume: 50,
+		},
+		{
+			name:   "available amount of the second bid in its target asset",
+			bids:   []*domain.Bid{{Rate: 60, MaxLimit: 100}, {Rate: 0.02, Available: 30}},
+			volume: 25,
+		},
+		{
+			name: "invalid rate",
+			bids: []*domain.Bid{{Rate: 60, MaxLimit: 100}, {Rate: 0}},
+		},
+	}
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			s.InDelta(tt.volume, chainVolume(tt.bids), 0.0001)
+		})
+	}
+}
+
+func (s *arbitrageTestSuite) Test_BuildProfitableChains_NormalizedToBase() {
+	referenceRates := &mocks.ReferenceRateProvider{}
+	referenceRates.On("BaseCurrency").Return("EUR")
+	referenceRates.On("ToBase", s.Ctx, "USD", 100.0).Return(90.0, true)
+	svc := NewArbitrageService(s.chainStorage, s.chainArchive, s.bidsProvider, referenceRates, nil).(*arbitrageSvcImpl)
+	svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Depth: 5, MinProfit: 1.0005, BidMaxAgeSec: 600}})
+	candidates := []*domain.CandidateChain{
+		{
+			BidIds:    []string{kit.NewRandString(), kit.NewRandString()},
+			TotalRate: 1.1,
+		},
+	}
+	bids := []*domain.Bid{
+		{Id: candidates[0].BidIds[0], Type: domain.BidTypeP2P, SrcAsset: "USD", TrgAsset: "RUB", Rate: 55, MaxLimit: 100, ExchangeCode: "binance"},
+		{Id: candidates[0].BidIds[1], Type: domain.BidTypeP2P, SrcAsset: "RUB", TrgAsset: "USD", Rate: 0.02, ExchangeCode: "bitnami"},
+	}
+	s.bidsProvider.On("GetBidsByIds", s.Ctx, candidates[0].BidIds).Return(bids, nil)
+	s.chainStorage.On("ProfitableChainExists", s.Ctx, mock.AnythingOfType("string")).Return(false, nil)
+	profitableChains, _, err := svc.buildProfitableChains(s.Ctx, candidates)
+	s.Nil(err)
+	s.Len(profitableChains, 1)
+	s.InDelta(100.0, profitableChains[0].Volume, 0.0001)
+	s.Equal("EUR", profitableChains[0].BaseCurrency)
+	s.InDelta(90.0, profitableChains[0].BaseVolume, 0.0001)
+	s.InDelta(9.0, profitableChains[0].BaseProfit, 0.0001)
+}
+
+func (s *arbitrageTestSuite) Test_BuildProfitableChains_WhenNoReferenceRate_NotNormalized() {
+	svc := s.svc.(*arbitrageSvcImpl)
+	candidates := []*domain.CandidateChain{
+		{
+			BidIds:    []string{kit.NewRandString(), kit.NewRandString()},
+			TotalRate: 1.1,
+		},
+	}
+	bids := []*domain.Bid{
+		{Id: candidates[0].BidIds[0], Type: domain.BidTypeP2P, SrcAsset: "USD", TrgAsset: "RUB", Rate: 55, MaxLimit: 100, ExchangeCode: "binance"},
+		{Id: candidates[0].BidIds[1], Type: domain.BidTypeP2P, SrcAsset: "RUB", TrgAsset: "USD", Rate: 0.02, ExchangeCode: "bitnami"},
+	}
+	s.bidsProvider.On("GetBidsByIds", s.Ctx, candidates[0].BidIds).Return(bids, nil)
+	s.chainStorage.On("ProfitableChainExists", s.Ctx, mock.AnythingOfType("string")).Return(false, nil)
+	profitableChains, _, err := svc.buildProfitableChains(s.Ctx, candidates)
+	s.Nil(err)
+	s.Len(profitableChains, 1)
+	s.InDelta(100.0, profitableChains[0].Volume, 0.0001)
+	s.Empty(profitableChains[0].BaseCurrency)
+	s.Empty(profitableChains[0].BaseProfit)
+}
+
 func (s *arbitrageTestSuite) Test_BuildProfitableChains_WhenChainExists_Ok() {
 	svc := s.svc.(*arbitrageSvcImpl)
 	candidates := []*domain.CandidateChain{
@@ -179,9 +287,13 @@ func (s *arbitrageTestSuite) Test_BuildProfitableChains_WhenChainExists_Ok() {
 	}
 	s.bidsProvider.On("GetBidsByIds", s.Ctx, candidates[0].BidIds).Return(bids, nil)
 	s.chainStorage.On("ProfitableChainExists", s.Ctx, mock.AnythingOfType("string")).Return(true, nil)
-	profitableChains, err := svc.buildProfitableChains(s.Ctx, candidates)
+	profitableChains, recalculatedChains, err := svc.buildProfitableChains(s.Ctx, candidates)
 	s.Nil(err)
 	s.Empty(profitableChains)
+	// stored chain is recalculated with the current profit
+	s.Len(recalculatedChains, 1)
+	s.Equal(svc.profitableChainGenId(candidates[0].BidIds), recalculatedChains[0].Id)
+	s.Equal(1.1, recalculatedChains[0].ProfitShare)
 }
 
 func (s *arbitrageTestSuite) Test_BuildProfitableChains_WhenDuplicatedNewChains_Ok() {
@@ -220,7 +332,7 @@ func (s *arbitrageTestSuite) Test_BuildProfitableChains_WhenDuplicatedNewChains_
 	s.bidsProvider.On("GetBidsByIds", s.Ctx, candidates[0].BidIds).Return(bids, nil)
 	s.bidsProvider.On("GetBidsByIds", s.Ctx, candidates[1].BidIds).Return(bids, nil)
 	s.chainStorage.On("ProfitableChainExists", s.Ctx, mock.AnythingOfType("string")).Return(false, nil)
-	profitableChains, err := svc.buildProfitableChains(s.Ctx, candidates)
+	profitableChains, _, err := svc.buildProfitableChains(s.Ctx, candidates)
 	s.Nil(err)
 	s.Len(profitableChains, 1)
 }
@@ -285,7 +397,107 @@ func (s *arbitrageTestSuite) Test_BuildProfitableChains_WhenMultipleNewChains_Ok
 	}
 	s.bidsProvider.On("GetBidsByIds", s.Ctx, append(candidates[0].BidIds, candidates[1].BidIds...)).Return(bids, nil)
 	s.chainStorage.On("ProfitableChainExists", s.Ctx, mock.AnythingOfType("string")).Return(false, nil)
-	profitableChains, err := svc.buildProfitableChains(s.Ctx, candidates)
+	profitableChains, _, err := svc.buildProfitableChains(s.Ctx, candidates)
 	s.Nil(err)
 	s.Len(profitableChains, 2)
 }
+
+func (s *arbitrageTestSuite) Test_GetProfitableChains_Validation() {
+	tests := []struct {
+		rq   *domain.GetProfitableChainsRequest
+		code string
+	}{
+		{&domain.GetProfitableChainsRequest{PagingRequest: kit.PagingRequest{Size: domain.MaxChainsPageSize + 1}}, errors.ErrCodeChainsPageSizeExceeded},
+		{&domain.GetProfitableChainsRequest{PagingRequest: kit.PagingRequest{SortBy: []*kit.SortRequest{{Field: "unknown"}}}}, errors.ErrCodeChainSortFieldInvalid},
+		{&domain.GetProfitableChainsRequest{MinProfit: -1}, errors.ErrCodeChainProfitRangeInvalid},
+		{&domain.GetProfitableChainsRequest{MinProfit: 2, MaxProfit: 1}, errors.ErrCodeChainProfitRangeInvalid},
+		{&domain.GetProfitableChainsRequest{MinDepth: 4, MaxDepth: 3}, errors.ErrCodeChainDepthRangeInvalid},
+		{&domain.GetProfitableChainsRequest{MinBaseProfit: -1}, errors.ErrCodeChainBaseAmountInvalid},
+	}
+	for _, tt := range tests {
+		_, err := s.svc.GetProfitableChains(s.Ctx, tt.rq)
+		s.AssertAppErr(err, tt.code)
+	}
+}
+
+func (s *arbitrageTestSuite) Test_GetProfitableChains_DefaultSize() {
+	rq := &domain.GetProfitableChainsRequest{PagingRequest: kit.PagingRequest{SortBy: []*kit.SortRequest{{Field: domain.ChainSortFieldScore}}}}
+	s.chainStorage.On("GetProfitableChains", s.Ctx, rq).Return(&domain.GetProfitableChainsResponse{}, nil)
+	_, err := s.svc.GetProfitableChains(s.Ctx, rq)
+	s.NoError(err)
+	s.Equal(domain.DefaultChainsPageSize, rq.Size)
+}
+
+func (s *arbitrageTestSuite) Test_ChainRetention() {
+	svc := s.svc.(*arbitrageSvcImpl)
+	tests := []struct {
+		profitShare float64
+		expected    time.Duration
+	}{
+		{1.001, time.Hour},
+		{1.02, time.Hour * 3},
+		{1.03, time.Hour * 3},
+		{1.05, time.Hour * 24},
+		{1.5, time.Hour * 24},
+	}
+	for _, tt := range tests {
+		s.Equal(tt.expected, svc.chainRetention(tt.profitShare))
+	}
+}
+
+func (s *arbitrageTestSuite) Test_GetProfitableChain_WhenExpired_FromArchive() {
+	chainId := kit.NewRandString()
+	s.chainStorage.On("GetProfitableChain", s.Ctx, chainId).Return(nil, nil)
+	s.chainArchive.On("GetArchivedChain", s.Ctx, chainId).Return(&domain.ProfitableChain{Id: chainId, Archived: true}, nil)
+	chain, err := s.svc.GetProfitableChain(s.Ctx, chainId)
+	s.NoError(err)
+	s.NotNil(chain)
+	s.True(chain.Archived)
+}
+
+func (s *arbitrageTestSuite) Test_GetProfitableChain_WhenExists_NoArchive() {
+	chainId := kit.NewRandString()
+	s.chainStorage.On("GetProfitableChain", s.Ctx, chainId).Return(&domain.ProfitableChain{Id: chainId}, nil)
+	chain, err := s.svc.GetProfitableChain(s.Ctx, chainId)
+	s.NoError(err)
+	s.NotNil(chain)
+	s.False(chain.Archived)
+	s.chainArchive.AssertNotCalled(s.T(), "GetArchivedChain", s.Ctx, chainId)
+}
+
+func (s *arbitrageTestSuite) Test_FindChains_StaleBidsSkipped() {
+	svc := s.svc.(*arbitrageSvcImpl)
+	now := kit.Now()
+	bidsMap := map[string][]*domain.BidLight{
+		"USD": {
+			{Id: "1", SrcAsset: "USD", TrgAsset: "RUB", Rate: 60, Available: 6000, MaxLimit: 100, ObservedAt: now.Add(-time.Minute)},
+			{Id: "2", SrcAsset: "USD", TrgAsset: "EUR", Rate: 1.1, Available: 110, MaxLimit: 100, ObservedAt: now.Add(-time.Hour)},
+		},
+		"RUB": {{Id: "3", SrcAsset: "RUB", TrgAsset: "USD", Rate: 0.02, Available: 120, MaxLimit: 6000, ObservedAt: now}},
+		"EUR": {{Id: "4", SrcAsset: "EUR", TrgAsset: "USD", Rate: 1, Available: 110, MaxLimit: 110}},
+	}
+	m := s.bidsProvider.On("GetBidLightsBySourceAsset", s.Ctx, mock.AnythingOfType("string"))
+	m.RunFn = func(args mock.Arguments) {
+		m.ReturnArguments = mock.Arguments{bidsMap[args.Get(1).(string)], nil}
+	}
+	actual := &domain.CandidateChains{}
+	s.NoError(svc.findChainsRecurse(s.Ctx, s.bidsProvider, "USD", "USD", nil, actual, 0))
+	// chain through EUR is skipped as the first bid is stale
+	s.Equal([]string{"1->3->"}, s.ChainsToStr(actual))
+}
+
+func (s *arbitrageTestSuite) Test_BuildProfitableChains_ObservedAtIsOldestBid() {
+	svc := s.svc.(*arbitrageSvcImpl)
+	now := kit.Now()
+	candidates := []*domain.CandidateChain{{BidIds: []string{"1", "2"}, TotalRate: 1.1}}
+	bids := []*domain.Bid{
+		{Id: "1", Type: domain.BidTypeP2P, SrcAsset: "USD", TrgAsset: "RUB", Rate: 60, ObservedAt: now.Add(-time.Minute)},
+		{Id: "2", Type: domain.BidTypeP2P, SrcAsset: "RUB", TrgAsset: "USD", Rate: 0.02, ObservedAt: now.Add(-time.Second)},
+	}
+	s.bidsProvider.On("GetBidsByIds", s.Ctx, candidates[0].BidIds).Return(bids, nil)
+	s.chainStorage.On("ProfitableChainExists", s.Ctx, mock.AnythingOfType("string")).Return(false, nil)
+	chains, _, err := svc.buildProfitableChains(s.Ctx, candidates)
+	s.NoError(err)
+	s.Len(chains, 1)
+	s.Equal(bids[0].ObservedAt, chains[0].ObservedAt)
+}
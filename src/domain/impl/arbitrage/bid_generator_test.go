@@ -1,6 +1,8 @@
 package arbitrage
 
 import (
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
 	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
 	"github.com/mikhailbolshakov/cryptocare/src/service"
 	"github.com/stretchr/testify/suite"
@@ -24,17 +26,29 @@ func (s *bidGenTestSuite) SetupTest() {
 	s.svc = NewBidGenerator(nil).(*bidGeneratorImpl)
 }
 
-func (s *bidGenTestSuite) Test_GenBin() {
-	bin := s.svc.getBid()
-	s.NotEmpty(bin)
-	s.NotEmpty(bin.Id)
-	s.NotEmpty(bin.TrgAsset)
-	s.NotEmpty(bin.SrcAsset)
-	s.NotEmpty(bin.Rate)
-	s.NotEmpty(bin.MinLimit)
-	s.NotEmpty(bin.MaxLimit)
-	s.NotEmpty(bin.Available)
-	s.Greater(bin.MaxLimit, bin.MinLimit)
-	s.Greater(bin.MaxLimit*bin.Rate, bin.Available)
-	s.Greater(bin.Available, bin.MinLimit*bin.Rate)
+func (s *bidGenTestSuite) Test_NewSimulator_WithScenario() {
+	s.svc.Init(&service.Config{Dev: &service.Dev{
+		BidGeneratorPeriodSec: 10,
+		BidGeneratorBidsCount: 100,
+		Simulator:             &service.MarketSimulator{Seed: 42, Scenario: "./market_simulator_test_scenario.yml"},
+	}})
+	sim, err := s.svc.newSimulator(s.Ctx)
+	s.NoError(err)
+	s.Equal(int64(42), sim.seed)
+	s.Len(sim.scenario.Events, 2)
+	s.Len(sim.next(kit.Now()), 100)
+}
+
+func (s *bidGenTestSuite) Test_NewSimulator_RandomSeed() {
+	s.svc.Init(&service.Config{Dev: &service.Dev{BidGeneratorPeriodSec: 10}})
+	sim, err := s.svc.newSimulator(s.Ctx)
+	s.NoError(err)
+	s.NotEmpty(sim.seed)
+	s.Nil(sim.scenario)
+}
+
+func (s *bidGenTestSuite) Test_NewSimulator_ScenarioNotFound() {
+	s.svc.Init(&service.Config{Dev: &service.Dev{Simulator: &service.MarketSimulator{Scenario: "./not-found.yml"}}})
+	_, err := s.svc.newSimulator(s.Ctx)
+	s.AssertAppErr(err, errors.ErrCodeSimulatorScenarioLoad)
 }
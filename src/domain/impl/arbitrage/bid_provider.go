@@ -8,7 +8,10 @@ import (
 	"github.com/mikhailbolshakov/cryptocare/src/kit/goroutine"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
 	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/mitchellh/hashstructure/v2"
 	"go.uber.org/atomic"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -17,17 +20,23 @@ import (
 type bidProviderImpl struct {
 	sync.RWMutex
 	bidStorage        domain.BidStorage
+	rateHistory       domain.RateHistoryStorage
 	bidLightsMap      map[string][]*domain.BidLight
 	assets            map[string]struct{}
 	assetsRestriction map[string]struct{}
 	cancelFunc        context.CancelFunc
 	running           *atomic.Bool
 	cfg               *service.Config
+	// historyTime time of the last rate history point
+	historyTime time.Time
+	// historyCleanupTime time rate history has been cleaned up last time
+	historyCleanupTime time.Time
 }
 
-func NewBidProviderService(bidStorage domain.BidStorage) domain.BidProvider {
+func NewBidProviderService(bidStorage domain.BidStorage, rateHistory domain.RateHistoryStorage) domain.BidProvider {
 	return &bidProviderImpl{
 		bidStorage:        bidStorage,
+		rateHistory:       rateHistory,
 		running:           atomic.NewBool(false),
 		assetsRestriction: make(map[string]struct{}),
 	}
@@ -94,6 +103,11 @@ func (s *bidProviderImpl) Run(ctx context.Context) error {
 					s.assets = assets
 					s.Unlock()
 
+					// aggregate snapshot to the rate history
+					if err := s.saveRateHistory(ctx, bids, kit.Now()); err != nil {
+						s.l().C(ctx).Mth("rate-history").E(err).Err()
+					}
+
 				case <-ctx.Done():
 					l.Inf("stop")
 					return
@@ -133,19 +147,157 @@ func (s *bidProviderImpl) GetBidLightsBySourceAsset(ctx context.Context, srcAsse
 	return s.bidLightsMap[srcAsset], nil
 }
 
+func (s *bidProviderImpl) GetBidLights(ctx context.Context) ([]*domain.BidLight, error) {
+	s.RLock()
+	defer s.RUnlock()
+	var r []*domain.BidLight
+	for _, bids := range s.bidLightsMap {
+		r = append(r, bids...)
+	}
+	return r, nil
+}
+
 func (s *bidProviderImpl) GetBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
 	return s.bidStorage.GetBidsByIds(ctx, ids)
 }
 
-func (s *bidProviderImpl) PutBid(ctx context.Context, bid *domain.Bid) (*domain.Bid, error) {
-	s.l().C(ctx).Mth("put").Trc()
-	if bid.Id == "" {
-		bid.Id = kit.NewRandString()
+func (s *bidProviderImpl) GetPrivateBidLights(ctx context.Context, ownerId string) ([]*domain.BidLight, error) {
+	return s.bidStorage.GetPrivateBidsLightByOwner(ctx, ownerId)
+}
+
+func (s *bidProviderImpl) GetPrivateBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
+	return s.bidStorage.GetPrivateBidsByIds(ctx, ids)
+}
+
+func (s *bidProviderImpl) GetPrivateBidOwners(ctx context.Context) ([]string, error) {
+	return s.bidStorage.GetPrivateBidOwners(ctx)
+}
+
+// stampBid sets ingestion time. If the source hasn't provided observation time, the bid is considered observed on ingestion
+func stampBid(bid *domain.Bid, now time.Time) {
+	bid.IngestedAt = now
+	if bid.ObservedAt.IsZero() || bid.ObservedAt.After(now) {
+		bid.ObservedAt = now
+	}
+}
+
+// exchangeBidId scopes the id given by the source with the exchange
+// so that the put bid never replaces a manual bid or a bid of another exchange, the id is kept stable between puts
+func exchangeBidId(exchangeCode, id string) string {
+	hash, _ := hashstructure.Hash([]string{exchangeCode, id}, hashstructure.FormatV2, nil)
+	return strconv.FormatUint(hash, 10)
+}
+
+// bidTtl returns ttl of bids of the given type
+func bidTtl(cfg *service.Config, bidType string) uint32 {
+	ttlSec := 0
+	if cfg != nil && cfg.Retention != nil && cfg.Retention.Bids != nil {
+		switch bidType {
+		case domain.BidTypeP2P:
+			ttlSec = cfg.Retention.Bids.P2PTtlSec
+		case domain.BidTypeSpot:
+			ttlSec = cfg.Retention.Bids.SpotTtlSec
+		case domain.BidTypeManual:
+			ttlSec = cfg.Retention.Bids.ManualTtlSec
+		}
+	}
+	if ttlSec <= 0 {
+		ttlSec = domain.DefaultBidTtlSec
+	}
+	return uint32(ttlSec)
+}
+
+func (s *bidProviderImpl) PutBids(ctx context.Context, bids []*domain.Bid) ([]*domain.Bid, error) {
+	s.l().C(ctx).Mth("put-bulk").F(log.FF{"count": len(bids)}).Trc()
+
+	if len(bids) == 0 {
+		return bids, nil
+	}
+
+	now := kit.Now()
+	bidsByType := make(map[string][]*domain.Bid)
+	for _, bid := range bids {
+		if bid.SrcAsset == "" || bid.TrgAsset == "" || bid.Rate <= 0 {
+			return nil, errors.ErrBidInvalid(ctx)
+		}
+		// manual bids are managed by owners, so they can't be put here
+		switch bid.Type {
+		case "":
+			bid.Type = domain.BidTypeP2P
+		case domain.BidTypeP2P, domain.BidTypeSpot:
+		default:
+			return nil, errors.ErrBidTypeInvalid(ctx, bid.Type)
+		}
+		if bid.Id == "" {
+			bid.Id = kit.NewRandString()
+		} else {
+			bid.Id = exchangeBidId(bid.ExchangeCode, bid.Id)
+		}
+		bid.OwnerId, bid.Private = "", false
+		stampBid(bid, now)
+		bid.ExpiresAt = now.Add(time.Duration(bidTtl(s.cfg, bid.Type)) * time.Second)
+		bidsByType[bid.Type] = append(bidsByType[bid.Type], bid)
+	}
+
+	// ttl depends on bid type
+	for bidType, typeBids := range bidsByType {
+		if err := s.bidStorage.PutBids(ctx, typeBids, bidTtl(s.cfg, bidType)); err != nil {
+			return nil, err
+		}
+	}
+	return bids, nil
+}
+
+func (s *bidProviderImpl) GetExchangesStaleness(ctx context.Context) ([]*domain.ExchangeStaleness, error) {
+	s.l().C(ctx).Mth("get-staleness").Trc()
+
+	staleBefore := bidStaleBefore(s.cfg, kit.Now())
+
+	s.RLock()
+	defer s.RUnlock()
+
+	exchanges := make(map[string]*domain.ExchangeStaleness)
+	for _, bids := range s.bidLightsMap {
+		for _, b := range bids {
+			st, ok := exchanges[b.ExchangeCode]
+			if !ok {
+				st = &domain.ExchangeStaleness{ExchangeCode: b.ExchangeCode}
+				exchanges[b.ExchangeCode] = st
+			}
+			st.Bids++
+			if bidStale(b, staleBefore) {
+				st.StaleBids++
+			}
+			if b.ObservedAt.IsZero() {
+				continue
+			}
+			if st.OldestObservedAt.IsZero() || b.ObservedAt.Before(st.OldestObservedAt) {
+				st.OldestObservedAt = b.ObservedAt
+			}
+			if b.ObservedAt.After(st.LatestObservedAt) {
+				st.LatestObservedAt = b.ObservedAt
+			}
+		}
 	}
-	bid.Type = domain.BidTypeManual
-	err := s.bidStorage.PutBids(ctx, []*domain.Bid{bid}, 60*60*4)
-	if err != nil {
-		return nil, err
+
+	r := make([]*domain.ExchangeStaleness, 0, len(exchanges))
+	for _, st := range exchanges {
+		r = append(r, st)
 	}
-	return bid, nil
+	sort.Slice(r, func(i, j int) bool { return r[i].ExchangeCode < r[j].ExchangeCode })
+	return r, nil
+}
+
+// bidStaleBefore returns time bids observed before are considered stale. Zero time means no restriction
+func bidStaleBefore(cfg *service.Config, now time.Time) time.Time {
+	if cfg == nil || cfg.Arbitrage == nil || cfg.Arbitrage.BidMaxAgeSec <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-time.Duration(cfg.Arbitrage.BidMaxAgeSec) * time.Second)
+}
+
+// bidStale checks if the bid is stale
+// bids without observation time (put before timestamps were introduced) aren't considered stale, they go away by ttl
+func bidStale(bid *domain.BidLight, staleBefore time.Time) bool {
+	return !staleBefore.IsZero() && !bid.ObservedAt.IsZero() && bid.ObservedAt.Before(staleBefore)
 }
@@ -0,0 +1,190 @@
+package arbitrage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type bidProviderTestSuite struct {
+	kitTestSuite.Suite
+	bidStorage  *mocks.BidStorage
+	rateHistory *mocks.RateHistoryStorage
+	svc         *bidProviderImpl
+}
+
+func (s *bidProviderTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestBidProviderSuite(t *testing.T) {
+	suite.Run(t, new(bidProviderTestSuite))
+}
+
+func (s *bidProviderTestSuite) SetupTest() {
+	s.bidStorage = &mocks.BidStorage{}
+	s.rateHistory = &mocks.RateHistoryStorage{}
+	s.svc = NewBidProviderService(s.bidStorage, s.rateHistory).(*bidProviderImpl)
+	s.svc.Init(&service.Config{
+		Arbitrage: &service.Arbitrage{BidMaxAgeSec: 600},
+		Retention: &service.Retention{Bids: &service.BidRetention{P2PTtlSec: 60, ManualTtlSec: 120}},
+		Market:    &service.Market{History: &service.RateHistory{Enabled: true, ResolutionSec: 60, RetentionDays: 1}},
+	})
+}
+
+func (s *bidProviderTestSuite) Test_PutBids_TimestampsAndTtl() {
+	observedAt := kit.Now().Add(-time.Minute)
+	bids := []*domain.Bid{
+		{SrcAsset: "USD", TrgAsset: "RUB", Rate: 60, Type: domain.BidTypeP2P, ObservedAt: observedAt},
+		{SrcAsset: "RUB", TrgAsset: "USD", Rate: 0.02},
+	}
+	s.bidStorage.On("PutBids", s.Ctx, mock.Anything, uint32(60)).Return(nil).Once()
+	rs, err := s.svc.PutBids(s.Ctx, bids)
+	s.NoError(err)
+	s.Len(rs, 2)
+	// type isn't specified, so it's p2p
+	s.Equal(domain.BidTypeP2P, rs[1].Type)
+	s.Equal(observedAt, rs[0].ObservedAt)
+	s.False(rs[0].IngestedAt.IsZero())
+	// observation time isn't specified, so it's taken from ingestion
+	s.Equal(rs[1].IngestedAt, rs[1].ObservedAt)
+	s.bidStorage.AssertExpectations(s.T())
+}
+
+func (s *bidProviderTestSuite) Test_PutBids_DefaultTtl() {
+	s.bidStorage.On("PutBids", s.Ctx, mock.Anything, uint32(domain.DefaultBidTtlSec)).Return(nil).Once()
+	_, err := s.svc.PutBids(s.Ctx, []*domain.Bid{{SrcAsset: "USD", TrgAsset: "RUB", Rate: 60, Type: domain.BidTypeSpot}})
+	s.NoError(err)
+	s.bidStorage.AssertExpectations(s.T())
+}
+
+func (s *bidProviderTestSuite) Test_PutBids_IdScopedWithExchange() {
+	s.bidStorage.On("PutBids", s.Ctx, mock.Anything, uint32(60)).Return(nil)
+	bids := []*domain.Bid{
+		{Id: "1", SrcAsset: "USD", TrgAsset: "RUB", Rate: 60, ExchangeCode: "binance", OwnerId: "owner", Private: true},
+		{Id: "1", SrcAsset: "USD", TrgAsset: "RUB", Rate: 60, ExchangeCode: "bybit"},
+	}
+	rs, err := s.svc.PutBids(s.Ctx, bids)
+	s.NoError(err)
+	s.NotEqual("1", rs[0].Id)
+	s.NotEqual(rs[0].Id, rs[1].Id)
+	s.Empty(rs[0].OwnerId)
+	s.False(rs[0].Private)
+	// the same bid of the exchange gets the same id
+	rs2, err := s.svc.PutBids(s.Ctx, []*domain.Bid{{Id: "1", SrcAsset: "USD", TrgAsset: "RUB", Rate: 61, ExchangeCode: "binance"}})
+	s.NoError(err)
+	s.Equal(rs[0].Id, rs2[0].Id)
+}
+
+func (s *bidProviderTestSuite) Test_PutBids_WhenManual_Fail() {
+	_, err := s.svc.PutBids(s.Ctx, []*domain.Bid{{Id: "1", SrcAsset: "USD", TrgAsset: "RUB", Rate: 60, Type: domain.BidTypeManual}})
+	s.AssertAppErr(err, errors.ErrCodeBidTypeInvalid)
+	s.bidStorage.AssertNotCalled(s.T(), "PutBids", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *bidProviderTestSuite) Test_GetExchangesStaleness() {
+	now := kit.Now()
+	s.svc.bidLightsMap = map[string][]*domain.BidLight{
+		"USD": {
+			{Id: "1", ExchangeCode: "binance", ObservedAt: now.Add(-time.Minute)},
+			{Id: "2", ExchangeCode: "binance", ObservedAt: now.Add(-time.Hour)},
+		},
+		"RUB": {
+			{Id: "3", ExchangeCode: "binance", ObservedAt: now},
+			{Id: "4", ExchangeCode: "huobi"},
+		},
+	}
+	rs, err := s.svc.GetExchangesStaleness(s.Ctx)
+	s.NoError(err)
+	s.Len(rs, 2)
+	s.Equal("binance", rs[0].ExchangeCode)
+	s.Equal(3, rs[0].Bids)
+	s.Equal(1, rs[0].StaleBids)
+	s.Equal(now.Add(-time.Hour), rs[0].OldestObservedAt)
+	s.Equal(now, rs[0].LatestObservedAt)
+	s.Equal("huobi", rs[1].ExchangeCode)
+	s.Equal(1, rs[1].Bids)
+	s.Equal(0, rs[1].StaleBids)
+	s.True(rs[1].LatestObservedAt.IsZero())
+}
+
+func (s *bidProviderTestSuite) Test_AggregateRatePoints() {
+	now := kit.Now()
+	bids := []*domain.BidLight{
+		{Id: "1", SrcAsset: "USD", TrgAsset: "RUB", ExchangeCode: "binance", Rate: 60, Available: 100, Methods: []string{"M1", "M2"}},
+		{Id: "2", SrcAsset: "USD", TrgAsset: "RUB", ExchangeCode: "binance", Rate: 62, Available: 50, Methods: []string{"M1"}},
+		{Id: "3", SrcAsset: "USD", TrgAsset: "RUB", ExchangeCode: "binance", Rate: 61, Available: 10, Methods: []string{"M1"}},
+		{Id: "4", SrcAsset: "USD", TrgAsset: "RUB", ExchangeCode: "huobi", Rate: 59, Available: 20},
+	}
+	points := aggregateRatePoints(bids, now)
+	// binance: M1, M2, all methods; huobi: no method, all methods; all exchanges: M1, M2, no method, all methods
+	s.Len(points, 9)
+	byMethod := make(map[string]*domain.RatePoint)
+	for _, p := range points {
+		s.Equal(now, p.Time)
+		byMethod[p.ExchangeCode+"/"+p.Method] = p
+	}
+	s.Equal(62.0, byMethod["binance/M1"].BestRate)
+	s.Equal(61.0, byMethod["binance/M1"].MedianRate)
+	s.Equal(160.0, byMethod["binance/M1"].Volume)
+	s.Equal(3, byMethod["binance/M1"].Bids)
+	s.Equal(60.0, byMethod["binance/M2"].BestRate)
+	s.Equal(1, byMethod["binance/M2"].Bids)
+	s.Equal(59.0, byMethod["huobi/"].BestRate)
+	// bid with several methods is counted once over all methods
+	s.Equal(160.0, byMethod["binance/*"].Volume)
+	s.Equal(3, byMethod["binance/*"].Bids)
+	// median of all the rates, not of series medians
+	all := byMethod["*/*"]
+	s.Equal(62.0, all.BestRate)
+	s.Equal(60.5, all.MedianRate)
+	s.Equal(180.0, all.Volume)
+	s.Equal(4, all.Bids)
+	s.Equal(3, byMethod["*/M1"].Bids)
+	s.Equal(1, byMethod["*/M2"].Bids)
+}
+
+func (s *bidProviderTestSuite) Test_AggregateRatePoints_MultiMethodBid_CountedOnce() {
+	now := kit.Now()
+	bids := []*domain.BidLight{
+		{Id: "1", SrcAsset: "USD", TrgAsset: "RUB", ExchangeCode: "binance", Rate: 60, Available: 100, Methods: []string{"M1", "M2", "M3"}},
+	}
+	allMethods := 0
+	for _, p := range aggregateRatePoints(bids, now) {
+		if p.Method == domain.RatePointAll {
+			allMethods++
+			s.Equal(100.0, p.Volume)
+			s.Equal(1, p.Bids)
+			s.Equal(60.0, p.MedianRate)
+		}
+	}
+	// by the exchange and over all exchanges
+	s.Equal(2, allMethods)
+	// the bid's methods are kept
+	s.Equal([]string{"M1", "M2", "M3"}, bids[0].Methods)
+}
+
+func (s *bidProviderTestSuite) Test_SaveRateHistory_OncePerResolution() {
+	now := time.Date(2022, 10, 17, 12, 0, 30, 0, time.UTC)
+	bids := []*domain.BidLight{{Id: "1", SrcAsset: "USD", TrgAsset: "RUB", ExchangeCode: "binance", Rate: 60}}
+	s.rateHistory.On("SaveRatePoints", s.Ctx, mock.Anything).Return(nil)
+	s.rateHistory.On("DeleteRatePoints", s.Ctx, now.AddDate(0, 0, -1)).Return(nil).Once()
+
+	s.NoError(s.svc.saveRateHistory(s.Ctx, bids, now))
+	// the same period, skipped
+	s.NoError(s.svc.saveRateHistory(s.Ctx, bids, now.Add(time.Second*20)))
+	// the next period
+	s.NoError(s.svc.saveRateHistory(s.Ctx, bids, now.Add(time.Second*40)))
+
+	s.rateHistory.AssertNumberOfCalls(s.T(), "SaveRatePoints", 2)
+	s.rateHistory.AssertNumberOfCalls(s.T(), "DeleteRatePoints", 1)
+	points := s.rateHistory.Calls[0].Arguments.Get(1).([]*domain.RatePoint)
+	s.Equal(time.Date(2022, 10, 17, 12, 0, 0, 0, time.UTC), points[0].Time)
+}
@@ -0,0 +1,134 @@
+package arbitrage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/goroutine"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"go.uber.org/atomic"
+	"time"
+)
+
+const (
+	defaultArchivePeriodSec = 60
+)
+
+type chainArchiverImpl struct {
+	chainStorage domain.ChainStorage
+	chainArchive domain.ChainArchiveStorage
+	cancelFunc   context.CancelFunc
+	running      *atomic.Bool
+	cfg          *service.Config
+	// watermark chains expiring before watermark have been archived already
+	watermark time.Time
+}
+
+func NewChainArchiver(chainStorage domain.ChainStorage, chainArchive domain.ChainArchiveStorage) domain.ChainArchiver {
+	return &chainArchiverImpl{
+		chainStorage: chainStorage,
+		chainArchive: chainArchive,
+		running:      atomic.NewBool(false),
+	}
+}
+
+func (s *chainArchiverImpl) l() log.CLogger {
+	return service.L().Cmp("chain-archiver")
+}
+
+func (s *chainArchiverImpl) Init(cfg *service.Config) {
+	s.cfg = cfg
+}
+
+// archiveCfg returns archive config, nil if not configured
+func (s *chainArchiverImpl) archiveCfg() *service.ChainArchive {
+	if s.cfg == nil || s.cfg.Retention == nil {
+		return nil
+	}
+	return s.cfg.Retention.Archive
+}
+
+func (s *chainArchiverImpl) period() time.Duration {
+	periodSec := 0
+	if cfg := s.archiveCfg(); cfg != nil {
+		periodSec = cfg.PeriodSec
+	}
+	if periodSec <= 0 {
+		periodSec = defaultArchivePeriodSec
+	}
+	return time.Duration(periodSec) * time.Second
+}
+
+// archive moves chains expiring before the next run to the archive
+// chains are archived in advance (two periods ahead), so they are still in the hot storage when archived
+func (s *chainArchiverImpl) archive(ctx context.Context, now time.Time) error {
+	l := s.l().C(ctx).Mth("archive")
+	to := now.Add(2 * s.period())
+	chains, err := s.chainStorage.GetExpiringChains(ctx, s.watermark, to)
+	if err != nil {
+		return err
+	}
+	if len(chains) > 0 {
+		if err := s.chainArchive.ArchiveChains(ctx, chains); err != nil {
+			return err
+		}
+		l.DbgF("archived: %d", len(chains))
+	}
+	s.watermark = to
+	return nil
+}
+
+func (s *chainArchiverImpl) Run(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("run").Trc()
+
+	if cfg := s.archiveCfg(); cfg == nil || !cfg.Enabled {
+		l.Inf("disabled")
+		return nil
+	}
+
+	// check running
+	if s.running.Load() {
+		return errors.ErrChainArchiverAlreadyRun(ctx)
+	}
+
+	ctx, s.cancelFunc = context.WithCancel(ctx)
+	s.running.Store(true)
+	s.watermark = kit.Now()
+
+	goroutine.New().
+		WithLogger(s.l().C(ctx).Mth("archive-worker")).
+		WithRetry(goroutine.Unrestricted).
+		WithRetryDelay(time.Second*10).
+		Go(ctx, func() {
+			ticker := time.NewTicker(s.period())
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := s.archive(ctx, kit.Now()); err != nil {
+						s.l().C(ctx).Mth("archive-worker").E(err).Err()
+					}
+				case <-ctx.Done():
+					l.Inf("stop")
+					return
+				}
+			}
+		})
+
+	l.Inf("ok")
+	return nil
+}
+
+func (s *chainArchiverImpl) Stop(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("stop").Trc()
+	// cancel if running
+	if s.cancelFunc != nil && s.running.Load() {
+		s.cancelFunc()
+		s.running.Store(false)
+		s.cancelFunc = nil
+		l.Inf("ok")
+	}
+	return nil
+}
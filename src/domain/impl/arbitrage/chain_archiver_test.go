@@ -0,0 +1,75 @@
+package arbitrage
+
+import (
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type chainArchiverTestSuite struct {
+	kitTestSuite.Suite
+	chainStorage *mocks.ChainStorage
+	chainArchive *mocks.ChainArchiveStorage
+	archiver     *chainArchiverImpl
+}
+
+func (s *chainArchiverTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestChainArchiverSuite(t *testing.T) {
+	suite.Run(t, new(chainArchiverTestSuite))
+}
+
+func (s *chainArchiverTestSuite) SetupTest() {
+	s.chainStorage = &mocks.ChainStorage{}
+	s.chainArchive = &mocks.ChainArchiveStorage{}
+	s.archiver = NewChainArchiver(s.chainStorage, s.chainArchive).(*chainArchiverImpl)
+	s.archiver.Init(&service.Config{Retention: &service.Retention{Archive: &service.ChainArchive{Enabled: true, PeriodSec: 60}}})
+}
+
+func (s *chainArchiverTestSuite) Test_Archive_WatermarkMoved() {
+	now := time.Now()
+	s.archiver.watermark = now
+	chains := []*domain.ProfitableChain{{Id: "1"}, {Id: "2"}}
+
+	// first run archives chains expiring within two periods
+	s.chainStorage.On("GetExpiringChains", s.Ctx, now, now.Add(time.Minute*2)).Return(chains, nil).Once()
+	s.chainArchive.On("ArchiveChains", s.Ctx, chains).Return(nil).Once()
+	s.NoError(s.archiver.archive(s.Ctx, now))
+	s.Equal(now.Add(time.Minute*2), s.archiver.watermark)
+
+	// next run starts from the watermark, nothing to archive
+	next := now.Add(time.Minute)
+	s.chainStorage.On("GetExpiringChains", s.Ctx, now.Add(time.Minute*2), next.Add(time.Minute*2)).Return(nil, nil).Once()
+	s.NoError(s.archiver.archive(s.Ctx, next))
+	s.Equal(next.Add(time.Minute*2), s.archiver.watermark)
+
+	s.chainStorage.AssertExpectations(s.T())
+	s.chainArchive.AssertExpectations(s.T())
+}
+
+func (s *chainArchiverTestSuite) Test_Archive_WhenFails_WatermarkKept() {
+	now := time.Now()
+	s.archiver.watermark = now
+	chains := []*domain.ProfitableChain{{Id: "1"}}
+	s.chainStorage.On("GetExpiringChains", s.Ctx, now, now.Add(time.Minute*2)).Return(chains, nil)
+	s.chainArchive.On("ArchiveChains", s.Ctx, chains).Return(fmt.Errorf("test"))
+	s.Error(s.archiver.archive(s.Ctx, now))
+	s.Equal(now, s.archiver.watermark)
+}
+
+func (s *chainArchiverTestSuite) Test_Run_WhenArchiveNotConfigured_Disabled() {
+	for _, cfg := range []*service.Config{{}, {Retention: &service.Retention{}}} {
+		archiver := NewChainArchiver(s.chainStorage, s.chainArchive).(*chainArchiverImpl)
+		archiver.Init(cfg)
+		s.NoError(archiver.Run(s.Ctx))
+		s.False(archiver.running.Load())
+		s.Equal(time.Duration(defaultArchivePeriodSec)*time.Second, archiver.period())
+	}
+}
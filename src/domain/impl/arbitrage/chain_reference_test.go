@@ -0,0 +1,331 @@
+package arbitrage
+
+import (
+	"context"
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chainEngine finds candidate chains starting and ending with the asset
+// any implementation of chain finding is checked against the reference solver through this func
+type chainEngine func(ctx context.Context, cfg *service.Config, source bidSource, asset string) ([]*domain.CandidateChain, error)
+
+// recursiveEngine is the current chain finding implementation
+func recursiveEngine(ctx context.Context, cfg *service.Config, source bidSource, asset string) ([]*domain.CandidateChain, error) {
+	svc := &arbitrageSvcImpl{cfg: cfg}
+	chains := &domain.CandidateChains{}
+	if err := svc.findChainsRecurse(ctx, source, asset, asset, nil, chains, 0); err != nil {
+		return nil, err
+	}
+	return chains.Chains, nil
+}
+
+// referenceChains is a brute-force solver, it goes through all sequences of bids up to the max depth and checks each of them
+// it's slow but simple enough to be trusted, so engines are verified against it
+// rules:
+//   - a chain starts and ends with the asset and doesn't pass through it in between
+//   - bids with rate 1 and stale bids are skipped
+//   - total rate of the chain must be not less than min profit
+//
+// limits and amounts aren't checked by the solver, engines are verified against hand-derived fixtures for them
+func referenceChains(cfg *service.Config, bids []*domain.Bid, asset string, staleBefore time.Time) []*domain.CandidateChain {
+	var valid []*domain.Bid
+	for _, b := range bids {
+		if b.Rate == 1.0 || (!staleBefore.IsZero() && !b.ObservedAt.IsZero() && b.ObservedAt.Before(staleBefore)) {
+			continue
+		}
+		valid = append(valid, b)
+	}
+
+	var r []*domain.CandidateChain
+	for depth := 1; depth <= cfg.Arbitrage.Depth; depth++ {
+		idx := make([]int, depth)
+		for {
+			if chain, ok := referenceCheck(cfg, valid, idx, asset); ok {
+				r = append(r, chain)
+			}
+			// next sequence of indexes
+			i := depth - 1
+			for ; i >= 0; i-- {
+				idx[i]++
+				if idx[i] < len(valid) {
+					break
+				}
+				idx[i] = 0
+			}
+			if i < 0 || len(valid) == 0 {
+				break
+			}
+		}
+	}
+	return r
+}
+
+// referenceCheck checks if the sequence of bids makes a profitable chain
+func referenceCheck(cfg *service.Config, bids []*domain.Bid, idx []int, asset string) (*domain.CandidateChain, bool) {
+	chain := &domain.CandidateChain{TotalRate: 1.0}
+	current := asset
+	for i, ix := range idx {
+		b := bids[ix]
+		if b.SrcAsset != current {
+			return nil, false
+		}
+		last := i == len(idx)-1
+		if (b.TrgAsset == asset) != last {
+			return nil, false
+		}
+		chain.TotalRate *= b.Rate
+		chain.BidIds = append(chain.BidIds, b.Id)
+		current = b.TrgAsset
+	}
+	return chain, chain.TotalRate >= cfg.Arbitrage.MinProfit
+}
+
+// bidGraphParams params of a random bid graph
+type bidGraphParams struct {
+	assets        int     // assets number of assets
+	bids          int     // bids number of bids
+	noise         float64 // noise max deviation of rates from fair rates, the higher the more profitable cycles
+	staleShare    float64 // staleShare share of stale bids
+	unitRateShare float64 // unitRateShare share of bids with rate 1
+	maxAge        time.Duration
+}
+
+// genBidGraph generates random bids over assets with fair prices
+// rates deviate from fair ones, so that some cycles are profitable, limits aren't set as the reference solver doesn't check them
+// bids are observed either well before or well after the stale boundary, so the result doesn't depend on the time of the check
+func genBidGraph(rnd *rand.Rand, p bidGraphParams, now time.Time) []*domain.Bid {
+	assets := make([]string, p.assets)
+	prices := make([]float64, p.assets)
+	for i := range assets {
+		assets[i] = fmt.Sprintf("A%d", i)
+		prices[i] = math.Exp(rnd.Float64()*6 - 3)
+	}
+	bids := make([]*domain.Bid, 0, p.bids)
+	for i := 0; i < p.bids; i++ {
+		src := rnd.Intn(p.assets)
+		trg := rnd.Intn(p.assets - 1)
+		if trg >= src {
+			trg++
+		}
+		rate := prices[src] / prices[trg] * (1 + p.noise*(2*rnd.Float64()-1))
+		if rnd.Float64() < p.unitRateShare {
+			rate = 1.0
+		}
+		b := &domain.Bid{
+			Id:         fmt.Sprintf("b%d", i),
+			SrcAsset:   assets[src],
+			TrgAsset:   assets[trg],
+			Rate:       rate,
+			ObservedAt: now.Add(-time.Duration(rnd.Float64() * float64(p.maxAge) / 2)),
+		}
+		if rnd.Float64() < p.staleShare {
+			b.ObservedAt = now.Add(-p.maxAge * time.Duration(2+rnd.Intn(3)))
+		}
+		bids = append(bids, b)
+	}
+	return bids
+}
+
+func chainKey(chain *domain.CandidateChain) string {
+	return strings.Join(chain.BidIds, ",")
+}
+
+type chainReferenceTestSuite struct {
+	kitTestSuite.Suite
+}
+
+func (s *chainReferenceTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestChainReferenceSuite(t *testing.T) {
+	suite.Run(t, new(chainReferenceTestSuite))
+}
+
+// assertEngine checks the engine finds exactly the same chains as the reference solver on random graphs
+// limits aren't checked by the solver, so the config mustn't check them
+func (s *chainReferenceTestSuite) assertEngine(engine chainEngine, cfg *service.Config, p bidGraphParams, seeds int) {
+	for seed := int64(1); seed <= int64(seeds); seed++ {
+		now := kit.Now()
+		bids := genBidGraph(rand.New(rand.NewSource(seed)), p, now)
+		source := newStaticBidSource(bids)
+		staleBefore := bidStaleBefore(cfg, now)
+		for a := 0; a < p.assets; a++ {
+			asset := fmt.Sprintf("A%d", a)
+			msg := fmt.Sprintf("seed: %d, asset: %s, cfg: %+v", seed, asset, *cfg.Arbitrage)
+
+			actual, err := engine(s.Ctx, cfg, source, asset)
+			s.NoError(err)
+			expected := referenceChains(cfg, bids, asset, staleBefore)
+
+			expectedMap := make(map[string]*domain.CandidateChain, len(expected))
+			for _, ch := range expected {
+				expectedMap[chainKey(ch)] = ch
+			}
+			actualMap := make(map[string]*domain.CandidateChain, len(actual))
+			for _, ch := range actual {
+				key := chainKey(ch)
+				_, dup := actualMap[key]
+				s.False(dup, "duplicated chain %s; %s", key, msg)
+				actualMap[key] = ch
+				// no false positives
+				exp, ok := expectedMap[key]
+				s.True(ok, "unexpected chain %s; %s", key, msg)
+				if !ok {
+					continue
+				}
+				s.Equal(exp.TotalRate, ch.TotalRate, "rate of %s; %s", key, msg)
+			}
+			// all profitable chains found
+			for key := range expectedMap {
+				_, ok := actualMap[key]
+				s.True(ok, "chain not found %s; %s", key, msg)
+			}
+		}
+	}
+}
+
+func (s *chainReferenceTestSuite) Test_Reference_KnownGraph() {
+	bids := []*domain.Bid{
+		{Id: "ab", SrcAsset: "A", TrgAsset: "B", Rate: 2, Available: 100},
+		{Id: "ba", SrcAsset: "B", TrgAsset: "A", Rate: 0.6, Available: 100},
+		{Id: "bc", SrcAsset: "B", TrgAsset: "C", Rate: 3, Available: 100},
+		{Id: "ca", SrcAsset: "C", TrgAsset: "A", Rate: 0.1, Available: 100},
+		{Id: "ca1", SrcAsset: "C", TrgAsset: "A", Rate: 0.2, Available: 100, MinLimit: 1000},
+		{Id: "aa", SrcAsset: "A", TrgAsset: "B", Rate: 1},
+	}
+	cfg := &service.Config{Arbitrage: &service.Arbitrage{Depth: 3, MinProfit: 1.0}}
+	var keys []string
+	for _, ch := range referenceChains(cfg, bids, "A", time.Time{}) {
+		keys = append(keys, chainKey(ch))
+	}
+	sort.Strings(keys)
+	// A->B->C->A gives 0.6 (with ca) and 1.2 (with ca1)
+	s.Equal([]string{"ab,ba", "ab,bc,ca1"}, keys)
+
+	// depth restriction
+	cfg.Arbitrage.Depth = 1
+	s.Empty(referenceChains(cfg, bids, "A", time.Time{}))
+}
+
+// handDerivedChain expected chain, rate and amount are calculated by hand
+type handDerivedChain struct {
+	rate   float64
+	amount float64
+}
+
+// handDerivedBids small graph starting with A, amounts are calculated by hand in the tests:
+//   - amount of the first bid is its available amount converted by rate
+//   - each next bid takes min of the previous amount converted by rate and its available amount
+//     and requires the previous amount to be not less than its min limit
+//   - zero amount is unknown, so the next bid isn't restricted and counts as the first one
+func handDerivedBids(now time.Time) []*domain.Bid {
+	return []*domain.Bid{
+		{Id: "ab", SrcAsset: "A", TrgAsset: "B", Rate: 2, Available: 100, ObservedAt: now},
+		// no available amount
+		{Id: "ab0", SrcAsset: "A", TrgAsset: "B", Rate: 2.5, ObservedAt: now},
+		{Id: "ba", SrcAsset: "B", TrgAsset: "A", Rate: 0.55, Available: 30, ObservedAt: now},
+		{Id: "bc", SrcAsset: "B", TrgAsset: "C", Rate: 3, Available: 150, MinLimit: 50, ObservedAt: now},
+		{Id: "bc2", SrcAsset: "B", TrgAsset: "C", Rate: 3.5, Available: 1000, MinLimit: 300, ObservedAt: now},
+		{Id: "ca", SrcAsset: "C", TrgAsset: "A", Rate: 0.2, Available: 40, MinLimit: 100, ObservedAt: now},
+		// not profitable
+		{Id: "ca1", SrcAsset: "C", TrgAsset: "A", Rate: 0.1, Available: 1000, ObservedAt: now},
+		// rate 1 is skipped
+		{Id: "bb", SrcAsset: "B", TrgAsset: "A", Rate: 1, Available: 1000, ObservedAt: now},
+		// stale
+		{Id: "bs", SrcAsset: "B", TrgAsset: "A", Rate: 0.9, Available: 1000, ObservedAt: now.Add(-time.Hour)},
+	}
+}
+
+func (s *chainReferenceTestSuite) assertHandDerived(engine chainEngine, cfg *service.Config, expected map[string]handDerivedChain) {
+	source := newStaticBidSource(handDerivedBids(kit.Now()))
+	actual, err := engine(s.Ctx, cfg, source, "A")
+	s.NoError(err)
+	actualMap := make(map[string]*domain.CandidateChain, len(actual))
+	for _, ch := range actual {
+		actualMap[chainKey(ch)] = ch
+	}
+	s.Len(actualMap, len(expected))
+	for key, exp := range expected {
+		ch, ok := actualMap[key]
+		s.True(ok, "chain not found %s", key)
+		if !ok {
+			continue
+		}
+		s.InDelta(exp.rate, ch.TotalRate, 1e-9, "rate of %s", key)
+		if cfg.Arbitrage.CheckLimit {
+			s.InDelta(exp.amount, ch.Amount, 1e-9, "amount of %s", key)
+		}
+	}
+}
+
+func (s *chainReferenceTestSuite) Test_RecursiveEngine_HandDerived_NoLimits() {
+	cfg := &service.Config{Arbitrage: &service.Arbitrage{Depth: 3, MinProfit: 1.0, BidMaxAgeSec: 600}}
+	s.assertHandDerived(recursiveEngine, cfg, map[string]handDerivedChain{
+		"ab,ba":      {rate: 1.1},   // 2 * 0.55
+		"ab0,ba":     {rate: 1.375}, // 2.5 * 0.55
+		"ab,bc,ca":   {rate: 1.2},   // 2 * 3 * 0.2
+		"ab,bc2,ca":  {rate: 1.4},   // 2 * 3.5 * 0.2
+		"ab0,bc,ca":  {rate: 1.5},   // 2.5 * 3 * 0.2
+		"ab0,bc2,ca": {rate: 1.75},  // 2.5 * 3.5 * 0.2
+	})
+}
+
+func (s *chainReferenceTestSuite) Test_RecursiveEngine_HandDerived_Limits() {
+	cfg := &service.Config{Arbitrage: &service.Arbitrage{Depth: 3, MinProfit: 1.0, BidMaxAgeSec: 600, CheckLimit: true}}
+	s.assertHandDerived(recursiveEngine, cfg, map[string]handDerivedChain{
+		// ab: 100 * 2 = 200, ba: min(200 * 0.55 = 110, 30) = 30
+		"ab,ba": {rate: 1.1, amount: 30},
+		// ab0: unknown, ba counts as the first: 30 * 0.55 = 16.5
+		"ab0,ba": {rate: 1.375, amount: 16.5},
+		// ab: 200, bc: 200 >= 50, min(600, 150) = 150, ca: 150 >= 100, min(30, 40) = 30
+		"ab,bc,ca": {rate: 1.2, amount: 30},
+		// ab,bc2,ca: 200 < 300 of bc2, not executable
+		// ab0: unknown, bc: 150 * 3 = 450, ca: 450 >= 100, min(90, 40) = 40
+		"ab0,bc,ca": {rate: 1.5, amount: 40},
+		// ab0: unknown, bc2 isn't restricted: 1000 * 3.5 = 3500, ca: min(700, 40) = 40
+		"ab0,bc2,ca": {rate: 1.75, amount: 40},
+	})
+}
+
+func (s *chainReferenceTestSuite) Test_GenBidGraph_Deterministic() {
+	p := bidGraphParams{assets: 5, bids: 30, noise: 0.05, staleShare: 0.1, unitRateShare: 0.05, maxAge: time.Minute}
+	now := kit.Now()
+	bids1 := genBidGraph(rand.New(rand.NewSource(1)), p, now)
+	bids2 := genBidGraph(rand.New(rand.NewSource(1)), p, now)
+	s.Equal(bids1, bids2)
+	for _, b := range bids1 {
+		s.NotEqual(b.SrcAsset, b.TrgAsset)
+	}
+}
+
+func (s *chainReferenceTestSuite) Test_RecursiveEngine_NoLimits() {
+	for _, depth := range []int{2, 3} {
+		cfg := &service.Config{Arbitrage: &service.Arbitrage{Depth: depth, MinProfit: 1.0}}
+		p := bidGraphParams{assets: 5, bids: 30, noise: 0.05, unitRateShare: 0.05}
+		s.assertEngine(recursiveEngine, cfg, p, 50)
+	}
+}
+
+func (s *chainReferenceTestSuite) Test_RecursiveEngine_StaleBids() {
+	cfg := &service.Config{Arbitrage: &service.Arbitrage{Depth: 3, MinProfit: 1.0, BidMaxAgeSec: 600}}
+	p := bidGraphParams{assets: 4, bids: 25, noise: 0.05, staleShare: 0.3, maxAge: time.Second * 600}
+	s.assertEngine(recursiveEngine, cfg, p, 50)
+}
+
+func (s *chainReferenceTestSuite) Test_RecursiveEngine_Deep() {
+	cfg := &service.Config{Arbitrage: &service.Arbitrage{Depth: 4, MinProfit: 1.0}}
+	p := bidGraphParams{assets: 3, bids: 14, noise: 0.2}
+	s.assertEngine(recursiveEngine, cfg, p, 20)
+}
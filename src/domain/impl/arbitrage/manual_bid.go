@@ -0,0 +1,207 @@
+package arbitrage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	kitContext "github.com/mikhailbolshakov/cryptocare/src/kit/context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
+)
+
+const (
+	defaultManualBidMinTtlSec = 60
+	defaultManualBidMaxTtlSec = 60 * 60 * 24 * 7
+	maxManualBidsBulk         = 1000
+)
+
+type manualBidSvcImpl struct {
+	bidStorage domain.BidStorage
+	cfg        *service.Config
+}
+
+func NewManualBidService(bidStorage domain.BidStorage) domain.ManualBidService {
+	return &manualBidSvcImpl{
+		bidStorage: bidStorage,
+	}
+}
+
+func (s *manualBidSvcImpl) l() log.CLogger {
+	return service.L().Cmp("manual-bid-svc")
+}
+
+func (s *manualBidSvcImpl) Init(cfg *service.Config) {
+	s.cfg = cfg
+}
+
+// ttlBounds returns min and max ttl of manual bids
+func (s *manualBidSvcImpl) ttlBounds() (int, int) {
+	min, max := defaultManualBidMinTtlSec, defaultManualBidMaxTtlSec
+	if s.cfg != nil && s.cfg.Retention != nil && s.cfg.Retention.Bids != nil {
+		if s.cfg.Retention.Bids.ManualMinTtlSec > 0 {
+			min = s.cfg.Retention.Bids.ManualMinTtlSec
+		}
+		if s.cfg.Retention.Bids.ManualMaxTtlSec > 0 {
+			max = s.cfg.Retention.Bids.ManualMaxTtlSec
+		}
+	}
+	return min, max
+}
+
+// ttl returns ttl of the bid. If not specified, the default ttl of manual bids is taken
+func (s *manualBidSvcImpl) ttl(ctx context.Context, ttlSec int) (uint32, error) {
+	if ttlSec == 0 {
+		return bidTtl(s.cfg, domain.BidTypeManual), nil
+	}
+	min, max := s.ttlBounds()
+	if ttlSec < min || ttlSec > max {
+		return 0, errors.ErrManualBidTtlInvalid(ctx, min, max)
+	}
+	return uint32(ttlSec), nil
+}
+
+// authorize checks the caller is allowed to access bids of the owner
+// only the owner and sysadmin are allowed, internal calls without request context aren't restricted
+func (s *manualBidSvcImpl) authorize(ctx context.Context, ownerId string) error {
+	rq, ok := kitContext.Request(ctx)
+	if !ok {
+		return nil
+	}
+	if rq.GetUserId() == ownerId || kit.Strings(rq.GetRoles()).Contains(domain.AuthRoleSysAdmin) {
+		return nil
+	}
+	return errors.ErrNotAllowed(ctx)
+}
+
+// toBid validates request and builds a manual bid
+func (s *manualBidSvcImpl) toBid(ctx context.Context, bidId, ownerId string, rq *domain.ManualBidRequest, now time.Time) (*domain.Bid, uint32, error) {
+	if rq.SrcAsset == "" || rq.TrgAsset == "" || rq.Rate <= 0 {
+		return nil, 0, errors.ErrBidInvalid(ctx)
+	}
+	ttl, err := s.ttl(ctx, rq.TtlSec)
+	if err != nil {
+		return nil, 0, err
+	}
+	bid := &domain.Bid{
+		Id:           bidId,
+		Type:         domain.BidTypeManual,
+		SrcAsset:     rq.SrcAsset,
+		TrgAsset:     rq.TrgAsset,
+		Rate:         rq.Rate,
+		ExchangeCode: rq.ExchangeCode,
+		Available:    rq.Available,
+		MinLimit:     rq.MinLimit,
+		MaxLimit:     rq.MaxLimit,
+		Methods:      rq.Methods,
+		Link:         rq.Link,
+		Private:      rq.Private,
+		UserId:       ownerId,
+		OwnerId:      ownerId,
+		ObservedAt:   rq.ObservedAt,
+		ExpiresAt:    now.Add(time.Duration(ttl) * time.Second),
+	}
+	stampBid(bid, now)
+	return bid, ttl, nil
+}
+
+func (s *manualBidSvcImpl) CreateBids(ctx context.Context, ownerId string, rqs []*domain.ManualBidRequest) ([]*domain.Bid, error) {
+	s.l().C(ctx).Mth("create").F(log.FF{"ownerId": ownerId, "count": len(rqs)}).Trc()
+
+	if ownerId == "" {
+		return nil, errors.ErrManualBidOwnerEmpty(ctx)
+	}
+	if len(rqs) == 0 {
+		return nil, errors.ErrManualBidsEmpty(ctx)
+	}
+	if len(rqs) > maxManualBidsBulk {
+		return nil, errors.ErrManualBidsTooMany(ctx, maxManualBidsBulk)
+	}
+	if err := s.authorize(ctx, ownerId); err != nil {
+		return nil, err
+	}
+
+	// validate all bids before storing, so bulk is either stored or rejected as a whole
+	now := kit.Now()
+	bids := make([]*domain.Bid, 0, len(rqs))
+	ttls := make([]uint32, 0, len(rqs))
+	for _, rq := range rqs {
+		bid, ttl, err := s.toBid(ctx, kit.NewRandString(), ownerId, rq, now)
+		if err != nil {
+			return nil, err
+		}
+		bids = append(bids, bid)
+		ttls = append(ttls, ttl)
+	}
+	for i, bid := range bids {
+		if err := s.bidStorage.PutBids(ctx, []*domain.Bid{bid}, ttls[i]); err != nil {
+			return nil, err
+		}
+	}
+	return bids, nil
+}
+
+func (s *manualBidSvcImpl) UpdateBid(ctx context.Context, bidId string, rq *domain.ManualBidRequest) (*domain.Bid, error) {
+	s.l().C(ctx).Mth("update").F(log.FF{"bidId": bidId}).Trc()
+
+	stored, err := s.GetBid(ctx, bidId)
+	if err != nil {
+		return nil, err
+	}
+	bid, ttl, err := s.toBid(ctx, stored.Id, stored.OwnerId, rq, kit.Now())
+	if err != nil {
+		return nil, err
+	}
+	// private and public bids are kept separately, so the old record is removed when visibility changes
+	if stored.Private != bid.Private {
+		if err := s.bidStorage.DeleteBid(ctx, bid.Id); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.bidStorage.PutBids(ctx, []*domain.Bid{bid}, ttl); err != nil {
+		return nil, err
+	}
+	return bid, nil
+}
+
+func (s *manualBidSvcImpl) DeleteBid(ctx context.Context, bidId string) error {
+	s.l().C(ctx).Mth("delete").F(log.FF{"bidId": bidId}).Trc()
+	if _, err := s.GetBid(ctx, bidId); err != nil {
+		return err
+	}
+	return s.bidStorage.DeleteBid(ctx, bidId)
+}
+
+func (s *manualBidSvcImpl) GetBid(ctx context.Context, bidId string) (*domain.Bid, error) {
+	s.l().C(ctx).Mth("get").F(log.FF{"bidId": bidId}).Trc()
+	bids, err := s.bidStorage.GetBidsByIds(ctx, []string{bidId})
+	if err != nil {
+		return nil, err
+	}
+	if len(bids) == 0 {
+		bids, err = s.bidStorage.GetPrivateBidsByIds(ctx, []string{bidId})
+		if err != nil {
+			return nil, err
+		}
+	}
+	// bids of exchanges aren't managed manually
+	if len(bids) == 0 || bids[0].OwnerId == "" {
+		return nil, errors.ErrManualBidNotFound(ctx, bidId)
+	}
+	if err := s.authorize(ctx, bids[0].OwnerId); err != nil {
+		return nil, err
+	}
+	return bids[0], nil
+}
+
+func (s *manualBidSvcImpl) GetBids(ctx context.Context, ownerId string) ([]*domain.Bid, error) {
+	s.l().C(ctx).Mth("get-bids").F(log.FF{"ownerId": ownerId}).Trc()
+	if ownerId == "" {
+		return nil, errors.ErrManualBidOwnerEmpty(ctx)
+	}
+	if err := s.authorize(ctx, ownerId); err != nil {
+		return nil, err
+	}
+	return s.bidStorage.GetBidsByOwner(ctx, ownerId)
+}
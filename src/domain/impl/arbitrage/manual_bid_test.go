@@ -0,0 +1,199 @@
+package arbitrage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	kitContext "github.com/mikhailbolshakov/cryptocare/src/kit/context"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type manualBidTestSuite struct {
+	kitTestSuite.Suite
+	bidStorage *mocks.BidStorage
+	svc        domain.ManualBidService
+}
+
+func (s *manualBidTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestManualBidSuite(t *testing.T) {
+	suite.Run(t, new(manualBidTestSuite))
+}
+
+func (s *manualBidTestSuite) SetupTest() {
+	s.bidStorage = &mocks.BidStorage{}
+	s.svc = NewManualBidService(s.bidStorage)
+	s.svc.Init(&service.Config{
+		Retention: &service.Retention{Bids: &service.BidRetention{ManualTtlSec: 3600, ManualMinTtlSec: 60, ManualMaxTtlSec: 86400}},
+	})
+}
+
+func (s *manualBidTestSuite) userCtx(userId string, roles ...string) context.Context {
+	return kitContext.NewRequestCtx().Test().WithUser(userId, "user").WithRoles(roles...).ToContext(context.Background())
+}
+
+func (s *manualBidTestSuite) bidRq() *domain.ManualBidRequest {
+	return &domain.ManualBidRequest{SrcAsset: "USDT", TrgAsset: "RUB", Rate: 61.5, ExchangeCode: "otc", Available: 1000}
+}
+
+func (s *manualBidTestSuite) storedBid(ownerId string) *domain.Bid {
+	return &domain.Bid{Id: kit.NewRandString(), Type: domain.BidTypeManual, SrcAsset: "USDT", TrgAsset: "RUB", Rate: 61, OwnerId: ownerId}
+}
+
+func (s *manualBidTestSuite) Test_CreateBids_Ok() {
+	ownerId := kit.NewId()
+	ctx := s.userCtx(ownerId)
+	rq1, rq2 := s.bidRq(), s.bidRq()
+	rq2.TtlSec = 600
+	s.bidStorage.On("PutBids", ctx, mock.Anything, uint32(3600)).Return(nil).Once()
+	s.bidStorage.On("PutBids", ctx, mock.Anything, uint32(600)).Return(nil).Once()
+	bids, err := s.svc.CreateBids(ctx, ownerId, []*domain.ManualBidRequest{rq1, rq2})
+	s.NoError(err)
+	s.Len(bids, 2)
+	for _, b := range bids {
+		s.NotEmpty(b.Id)
+		s.Equal(domain.BidTypeManual, b.Type)
+		s.Equal(ownerId, b.OwnerId)
+		s.False(b.ObservedAt.IsZero())
+	}
+	s.Equal(bids[0].IngestedAt.Add(time.Hour), bids[0].ExpiresAt)
+	s.Equal(bids[1].IngestedAt.Add(time.Minute*10), bids[1].ExpiresAt)
+	s.bidStorage.AssertExpectations(s.T())
+}
+
+func (s *manualBidTestSuite) Test_CreateBids_Validation() {
+	ownerId := kit.NewId()
+	ctx := s.userCtx(ownerId)
+	invalid := s.bidRq()
+	invalid.Rate = 0
+	shortTtl, longTtl := s.bidRq(), s.bidRq()
+	shortTtl.TtlSec, longTtl.TtlSec = 10, 86401
+	tooMany := make([]*domain.ManualBidRequest, maxManualBidsBulk+1)
+	tests := []struct {
+		ownerId string
+		rqs     []*domain.ManualBidRequest
+		code    string
+	}{
+		{"", []*domain.ManualBidRequest{s.bidRq()}, errors.ErrCodeManualBidOwnerEmpty},
+		{ownerId, nil, errors.ErrCodeManualBidsEmpty},
+		{ownerId, tooMany, errors.ErrCodeManualBidsTooMany},
+		{ownerId, []*domain.ManualBidRequest{s.bidRq(), invalid}, errors.ErrCodeBidInvalid},
+		{ownerId, []*domain.ManualBidRequest{shortTtl}, errors.ErrCodeManualBidTtlInvalid},
+		{ownerId, []*domain.ManualBidRequest{longTtl}, errors.ErrCodeManualBidTtlInvalid},
+		{kit.NewId(), []*domain.ManualBidRequest{s.bidRq()}, errors.ErrCodeNotAllowed},
+	}
+	for _, tt := range tests {
+		_, err := s.svc.CreateBids(ctx, tt.ownerId, tt.rqs)
+		s.AssertAppErr(err, tt.code)
+	}
+	// nothing is stored if any bid in bulk is invalid
+	s.bidStorage.AssertNotCalled(s.T(), "PutBids", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *manualBidTestSuite) Test_UpdateBid_ByOwner() {
+	stored := s.storedBid(kit.NewId())
+	ctx := s.userCtx(stored.OwnerId)
+	s.bidStorage.On("GetBidsByIds", ctx, []string{stored.Id}).Return([]*domain.Bid{stored}, nil)
+	s.bidStorage.On("PutBids", ctx, mock.Anything, uint32(3600)).Return(nil).Once()
+	bid, err := s.svc.UpdateBid(ctx, stored.Id, s.bidRq())
+	s.NoError(err)
+	s.Equal(stored.Id, bid.Id)
+	s.Equal(stored.OwnerId, bid.OwnerId)
+	s.Equal(61.5, bid.Rate)
+}
+
+func (s *manualBidTestSuite) Test_UpdateBid_ByAdmin() {
+	stored := s.storedBid(kit.NewId())
+	ctx := s.userCtx(kit.NewId(), domain.AuthRoleSysAdmin)
+	s.bidStorage.On("GetBidsByIds", ctx, []string{stored.Id}).Return([]*domain.Bid{stored}, nil)
+	s.bidStorage.On("PutBids", ctx, mock.Anything, uint32(3600)).Return(nil).Once()
+	bid, err := s.svc.UpdateBid(ctx, stored.Id, s.bidRq())
+	s.NoError(err)
+	s.Equal(stored.OwnerId, bid.OwnerId)
+}
+
+func (s *manualBidTestSuite) Test_UpdateBid_NotOwner() {
+	stored := s.storedBid(kit.NewId())
+	ctx := s.userCtx(kit.NewId(), domain.AuthRoleArbitrageClient)
+	s.bidStorage.On("GetBidsByIds", ctx, []string{stored.Id}).Return([]*domain.Bid{stored}, nil)
+	_, err := s.svc.UpdateBid(ctx, stored.Id, s.bidRq())
+	s.AssertAppErr(err, errors.ErrCodeNotAllowed)
+	s.bidStorage.AssertNotCalled(s.T(), "PutBids", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *manualBidTestSuite) Test_GetBid_WhenExchangeBid_NotFound() {
+	stored := s.storedBid("")
+	stored.Type = domain.BidTypeP2P
+	ctx := s.userCtx(kit.NewId())
+	s.bidStorage.On("GetBidsByIds", ctx, []string{stored.Id}).Return([]*domain.Bid{stored}, nil)
+	_, err := s.svc.GetBid(ctx, stored.Id)
+	s.AssertAppErr(err, errors.ErrCodeManualBidNotFound)
+}
+
+func (s *manualBidTestSuite) Test_DeleteBid() {
+	stored := s.storedBid(kit.NewId())
+	ctx := s.userCtx(stored.OwnerId)
+	s.bidStorage.On("GetBidsByIds", ctx, []string{stored.Id}).Return([]*domain.Bid{stored}, nil)
+	s.bidStorage.On("DeleteBid", ctx, stored.Id).Return(nil).Once()
+	s.NoError(s.svc.DeleteBid(ctx, stored.Id))
+	s.bidStorage.AssertExpectations(s.T())
+
+	// not found
+	s.bidStorage.On("GetBidsByIds", ctx, []string{"unknown"}).Return(nil, nil)
+	s.bidStorage.On("GetPrivateBidsByIds", ctx, []string{"unknown"}).Return(nil, nil)
+	s.AssertAppErr(s.svc.DeleteBid(ctx, "unknown"), errors.ErrCodeManualBidNotFound)
+}
+
+func (s *manualBidTestSuite) Test_GetBids() {
+	ownerId := kit.NewId()
+	s.bidStorage.On("GetBidsByOwner", mock.Anything, ownerId).Return([]*domain.Bid{s.storedBid(ownerId)}, nil)
+
+	bids, err := s.svc.GetBids(s.userCtx(ownerId), ownerId)
+	s.NoError(err)
+	s.Len(bids, 1)
+
+	bids, err = s.svc.GetBids(s.userCtx(kit.NewId(), domain.AuthRoleSysAdmin), ownerId)
+	s.NoError(err)
+	s.Len(bids, 1)
+
+	_, err = s.svc.GetBids(s.userCtx(kit.NewId()), ownerId)
+	s.AssertAppErr(err, errors.ErrCodeNotAllowed)
+}
+
+func (s *manualBidTestSuite) Test_GetBid_Private() {
+	stored := s.storedBid(kit.NewId())
+	stored.Private = true
+	ctx := s.userCtx(stored.OwnerId)
+	s.bidStorage.On("GetBidsByIds", mock.Anything, []string{stored.Id}).Return(nil, nil)
+	s.bidStorage.On("GetPrivateBidsByIds", mock.Anything, []string{stored.Id}).Return([]*domain.Bid{stored}, nil)
+	bid, err := s.svc.GetBid(ctx, stored.Id)
+	s.NoError(err)
+	s.True(bid.Private)
+
+	// others can't see private bid
+	_, err = s.svc.GetBid(s.userCtx(kit.NewId()), stored.Id)
+	s.AssertAppErr(err, errors.ErrCodeNotAllowed)
+}
+
+func (s *manualBidTestSuite) Test_UpdateBid_VisibilityChanged() {
+	stored := s.storedBid(kit.NewId())
+	ctx := s.userCtx(stored.OwnerId)
+	rq := s.bidRq()
+	rq.Private = true
+	s.bidStorage.On("GetBidsByIds", ctx, []string{stored.Id}).Return([]*domain.Bid{stored}, nil)
+	s.bidStorage.On("DeleteBid", ctx, stored.Id).Return(nil).Once()
+	s.bidStorage.On("PutBids", ctx, mock.Anything, uint32(3600)).Return(nil).Once()
+	bid, err := s.svc.UpdateBid(ctx, stored.Id, rq)
+	s.NoError(err)
+	s.True(bid.Private)
+	s.bidStorage.AssertExpectations(s.T())
+}
@@ -0,0 +1,314 @@
+package arbitrage
+
+import (
+	"context"
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"gopkg.in/yaml.v3"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSimMerchants   = 10
+	defaultSimVolatility  = 0.1
+	defaultSimCorrelation = 0.5
+	defaultSimSpread      = 1.0
+	// simNumeraire asset prices are measured in, its price never changes
+	simNumeraire = "USD"
+	// simMinLimitUsd, simMaxLimitUsd limits of simulated bids in the numeraire
+	simMinLimitUsd = 10.0
+	simMaxLimitUsd = 1000.0
+	// simCycleLimitUsd limit of bids injected by scenarios in the numeraire
+	simCycleLimitUsd = 100000.0
+)
+
+var (
+	defaultSimExchanges = []string{"binance", "huobi", "bybit"}
+	defaultSimMethods   = []string{"M1", "M2", "M3"}
+	// simInitialPrices initial prices of simulated assets in the numeraire
+	simInitialPrices = map[string]float64{
+		"RUB":  0.018,
+		"USD":  1,
+		"EUR":  1.02,
+		"BTC":  20300,
+		"ETH":  1488.7,
+		"SLN":  31.4,
+		"USDT": 1,
+		"AVL":  18.25,
+	}
+)
+
+// SimScenario scripted scenario injecting arbitrage cycles to the simulated market
+type SimScenario struct {
+	Name   string           `yaml:"name"`   // Name - scenario name
+	Events []*SimCycleEvent `yaml:"events"` // Events - cycles injected
+}
+
+// SimCycleEvent exposes a profitable cycle of bids for the given period
+type SimCycleEvent struct {
+	Name     string         `yaml:"name"`     // Name - unique name of the event, bid ids of the cycle are built from it
+	At       time.Duration  `yaml:"at"`       // At - when the cycle appears since the simulator start
+	Duration time.Duration  `yaml:"duration"` // Duration - how long the cycle is exposed, 0 - until the simulator stops
+	Profit   float64        `yaml:"profit"`   // Profit - profit of the cycle in percents
+	Legs     []*SimCycleLeg `yaml:"legs"`     // Legs - conversions of the cycle, the last leg must return to the first asset
+}
+
+// SimCycleLeg is a bid of the injected cycle
+type SimCycleLeg struct {
+	Src      string `yaml:"src"`      // Src - source asset
+	Trg      string `yaml:"trg"`      // Trg - target asset
+	Exchange string `yaml:"exchange"` // Exchange - exchange code, the first simulated exchange if empty
+	Method   string `yaml:"method"`   // Method - payment method, the first simulated method if empty
+}
+
+// legPremium is a share each leg of the cycle exceeds the mid rate, so that the cycle gives the required profit
+func (e *SimCycleEvent) legPremium() float64 {
+	return math.Pow(1+e.Profit/100, 1/float64(len(e.Legs))) - 1
+}
+
+// active checks if the cycle is exposed at the given time since the simulator start
+func (e *SimCycleEvent) active(elapsed time.Duration) bool {
+	return elapsed >= e.At && (e.Duration == 0 || elapsed < e.At+e.Duration)
+}
+
+// bidId builds stable id of the cycle bid, so the same chain is found while the cycle is exposed
+func (e *SimCycleEvent) bidId(leg int) string {
+	return fmt.Sprintf("scn-%s-%d", e.Name, leg)
+}
+
+// ParseSimScenario parses and validates yaml scenario against the simulated market
+// injected bids must not make cycles with bids of the market, so the premium of each leg must be less than the market spread
+func ParseSimScenario(ctx context.Context, data []byte, spread float64) (*SimScenario, error) {
+	scenario := &SimScenario{}
+	if err := yaml.Unmarshal(data, scenario); err != nil {
+		return nil, errors.ErrSimulatorScenarioLoad(err, ctx)
+	}
+	names := make(map[string]struct{}, len(scenario.Events))
+	for i, e := range scenario.Events {
+		if e.Name == "" {
+			return nil, errors.ErrSimulatorScenarioInvalid(ctx, fmt.Sprintf("#%d", i), "name empty")
+		}
+		if _, ok := names[e.Name]; ok {
+			return nil, errors.ErrSimulatorScenarioInvalid(ctx, e.Name, "name isn't unique")
+		}
+		names[e.Name] = struct{}{}
+		if e.At < 0 || e.Duration < 0 {
+			return nil, errors.ErrSimulatorScenarioInvalid(ctx, e.Name, "negative time")
+		}
+		if e.Profit <= 0 {
+			return nil, errors.ErrSimulatorScenarioInvalid(ctx, e.Name, "profit must be positive")
+		}
+		if len(e.Legs) < 2 {
+			return nil, errors.ErrSimulatorScenarioInvalid(ctx, e.Name, "at least two legs required")
+		}
+		for j, leg := range e.Legs {
+			if _, ok := simInitialPrices[leg.Src]; !ok {
+				return nil, errors.ErrSimulatorScenarioInvalid(ctx, e.Name, fmt.Sprintf("unknown asset %s", leg.Src))
+			}
+			if _, ok := simInitialPrices[leg.Trg]; !ok {
+				return nil, errors.ErrSimulatorScenarioInvalid(ctx, e.Name, fmt.Sprintf("unknown asset %s", leg.Trg))
+			}
+			if next := e.Legs[(j+1)%len(e.Legs)]; leg.Trg != next.Src {
+				return nil, errors.ErrSimulatorScenarioInvalid(ctx, e.Name, "legs don't make a cycle")
+			}
+		}
+		if e.legPremium() >= spread/100 {
+			return nil, errors.ErrSimulatorScenarioInvalid(ctx, e.Name, "profit is too high for the market spread")
+		}
+	}
+	return scenario, nil
+}
+
+// marketSimulator generates a reproducible market
+// asset prices follow correlated random walks, bids are quoted below the mid rate, so the market itself has no arbitrage
+// profitable cycles appear only if injected by the scenario
+type marketSimulator struct {
+	seed        int64
+	rnd         *rand.Rand
+	assets      []string
+	prices      map[string]float64
+	exchanges   []string
+	methods     []string
+	merchants   []string
+	volatility  float64
+	correlation float64
+	spread      float64
+	bidsCount   int
+	period      time.Duration
+	scenario    *SimScenario
+	step        int
+}
+
+func simStrings(v string, def []string) []string {
+	var r []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			r = append(r, s)
+		}
+	}
+	if len(r) == 0 {
+		return def
+	}
+	return r
+}
+
+func newMarketSimulator(cfg *service.MarketSimulator, seed int64, bidsCount int, period time.Duration, scenario *SimScenario) *marketSimulator {
+	if cfg == nil {
+		cfg = &service.MarketSimulator{}
+	}
+	m := &marketSimulator{
+		seed:        seed,
+		rnd:         rand.New(rand.NewSource(seed)),
+		prices:      make(map[string]float64, len(simInitialPrices)),
+		exchanges:   simStrings(cfg.Exchanges, defaultSimExchanges),
+		methods:     simStrings(cfg.Methods, defaultSimMethods),
+		volatility:  cfg.Volatility / 100,
+		correlation: cfg.Correlation,
+		spread:      cfg.Spread / 100,
+		bidsCount:   bidsCount,
+		period:      period,
+		scenario:    scenario,
+	}
+	if m.volatility <= 0 {
+		m.volatility = defaultSimVolatility / 100
+	}
+	if m.correlation <= 0 || m.correlation > 1 {
+		m.correlation = defaultSimCorrelation
+	}
+	if m.spread <= 0 {
+		m.spread = defaultSimSpread / 100
+	}
+	merchants := cfg.Merchants
+	if merchants <= 0 {
+		merchants = defaultSimMerchants
+	}
+	for i := 0; i < merchants; i++ {
+		m.merchants = append(m.merchants, fmt.Sprintf("merchant-%d", i+1))
+	}
+	// assets are ordered, so random numbers are drawn in the same order on each run
+	for a, p := range simInitialPrices {
+		m.assets = append(m.assets, a)
+		m.prices[a] = p
+	}
+	sort.Strings(m.assets)
+	return m
+}
+
+// walk moves prices of all assets
+// the log return of each asset is a mix of the common market move and its own move weighted by correlation
+func (m *marketSimulator) walk() {
+	market := m.rnd.NormFloat64()
+	for _, a := range m.assets {
+		own := m.rnd.NormFloat64()
+		if a == simNumeraire {
+			continue
+		}
+		ret := m.volatility * (math.Sqrt(m.correlation)*market + math.Sqrt(1-m.correlation)*own)
+		m.prices[a] *= math.Exp(ret)
+	}
+}
+
+// midRate is a fair conversion rate from src to trg asset
+func (m *marketSimulator) midRate(src, trg string) float64 {
+	return m.prices[src] / m.prices[trg]
+}
+
+func (m *marketSimulator) pick(v []string) string {
+	return v[m.rnd.Intn(len(v))]
+}
+
+// marketBid generates a bid quoted with discount between one and two spreads to the mid rate
+func (m *marketSimulator) marketBid(i int, now time.Time) *domain.Bid {
+	src := m.pick(m.assets)
+	trg := m.pick(m.assets)
+	for trg == src {
+		trg = m.pick(m.assets)
+	}
+	rate := m.midRate(src, trg) * (1 - m.spread*(1+m.rnd.Float64()))
+	// limits are specified in the source asset
+	minLimit := simMinLimitUsd * m.rnd.Float64() / m.prices[src]
+	maxLimit := (simMinLimitUsd + (simMaxLimitUsd-simMinLimitUsd)*m.rnd.Float64()) / m.prices[src]
+	available := (minLimit + (maxLimit-minLimit)*m.rnd.Float64()) * rate
+	methods := kit.Strings{m.pick(m.methods), m.pick(m.methods)}.Distinct()
+	id := fmt.Sprintf("sim-%d-%d-%d", m.seed, m.step, i)
+	exchange := m.pick(m.exchanges)
+	return &domain.Bid{
+		Id:           id,
+		Type:         domain.BidTypeP2P,
+		SrcAsset:     src,
+		TrgAsset:     trg,
+		Rate:         rate,
+		ExchangeCode: exchange,
+		Available:    available,
+		MinLimit:     minLimit,
+		MaxLimit:     maxLimit,
+		Methods:      methods,
+		Link:         fmt.Sprintf("https://%s.com/orders?order=%s", exchange, id),
+		UserId:       m.pick(m.merchants),
+		ObservedAt:   now,
+		IngestedAt:   now,
+	}
+}
+
+// cycleBids builds bids of the injected cycle from the current mid rates
+func (m *marketSimulator) cycleBids(e *SimCycleEvent, now time.Time) []*domain.Bid {
+	premium := e.legPremium()
+	var r []*domain.Bid
+	for i, leg := range e.Legs {
+		exchange, method := leg.Exchange, leg.Method
+		if exchange == "" {
+			exchange = m.exchanges[0]
+		}
+		if method == "" {
+			method = m.methods[0]
+		}
+		rate := m.midRate(leg.Src, leg.Trg) * (1 + premium)
+		maxLimit := simCycleLimitUsd / m.prices[leg.Src]
+		r = append(r, &domain.Bid{
+			Id:           e.bidId(i),
+			Type:         domain.BidTypeP2P,
+			SrcAsset:     leg.Src,
+			TrgAsset:     leg.Trg,
+			Rate:         rate,
+			ExchangeCode: exchange,
+			Available:    maxLimit * rate,
+			MaxLimit:     maxLimit,
+			Methods:      []string{method},
+			Link:         fmt.Sprintf("https://%s.com/orders?order=%s", exchange, e.bidId(i)),
+			UserId:       e.Name,
+			ObservedAt:   now,
+			IngestedAt:   now,
+		})
+	}
+	return r
+}
+
+// elapsed is simulated time since the start, it's counted in steps, so scenarios are reproducible regardless of delays
+func (m *marketSimulator) elapsed() time.Duration {
+	return time.Duration(m.step) * m.period
+}
+
+// next moves the market one step and returns bids exposed at this step
+func (m *marketSimulator) next(now time.Time) []*domain.Bid {
+	m.step++
+	m.walk()
+	bids := make([]*domain.Bid, 0, m.bidsCount)
+	for i := 0; i < m.bidsCount; i++ {
+		bids = append(bids, m.marketBid(i, now))
+	}
+	if m.scenario != nil {
+		for _, e := range m.scenario.Events {
+			if e.active(m.elapsed()) {
+				bids = append(bids, m.cycleBids(e, now)...)
+			}
+		}
+	}
+	return bids
+}
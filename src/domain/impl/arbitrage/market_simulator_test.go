@@ -0,0 +1,256 @@
+package arbitrage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const simTestPeriod = time.Second * 10
+
+// staticBidSource serves a fixed set of bids
+type staticBidSource struct {
+	lights map[string][]*domain.BidLight
+	bids   map[string]*domain.Bid
+}
+
+func newStaticBidSource(bids []*domain.Bid) *staticBidSource {
+	r := &staticBidSource{
+		lights: make(map[string][]*domain.BidLight),
+		bids:   make(map[string]*domain.Bid, len(bids)),
+	}
+	for _, b := range bids {
+		r.bids[b.Id] = b
+		r.lights[b.SrcAsset] = append(r.lights[b.SrcAsset], &domain.BidLight{
+			Id:           b.Id,
+			SrcAsset:     b.SrcAsset,
+			TrgAsset:     b.TrgAsset,
+			Rate:         b.Rate,
+			Available:    b.Available,
+			MinLimit:     b.MinLimit,
+			MaxLimit:     b.MaxLimit,
+			ExchangeCode: b.ExchangeCode,
+			Methods:      b.Methods,
+			ObservedAt:   b.ObservedAt,
+		})
+	}
+	return r
+}
+
+func (s *staticBidSource) GetBidLightsBySourceAsset(ctx context.Context, srcAsset string) ([]*domain.BidLight, error) {
+	return s.lights[srcAsset], nil
+}
+
+func (s *staticBidSource) GetBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
+	var r []*domain.Bid
+	for _, id := range ids {
+		if b, ok := s.bids[id]; ok {
+			r = append(r, b)
+		}
+	}
+	return r, nil
+}
+
+type simulatorTestSuite struct {
+	kitTestSuite.Suite
+}
+
+func (s *simulatorTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestSimulatorSuite(t *testing.T) {
+	suite.Run(t, new(simulatorTestSuite))
+}
+
+func (s *simulatorTestSuite) scenario() *SimScenario {
+	data, err := os.ReadFile("./market_simulator_test_scenario.yml")
+	s.NoError(err)
+	scenario, err := ParseSimScenario(s.Ctx, data, defaultSimSpread)
+	s.NoError(err)
+	return scenario
+}
+
+func (s *simulatorTestSuite) Test_SameSeed_SameMarket() {
+	now := time.Now().UTC()
+	sim1 := newMarketSimulator(&service.MarketSimulator{}, 42, 50, simTestPeriod, nil)
+	sim2 := newMarketSimulator(&service.MarketSimulator{}, 42, 50, simTestPeriod, nil)
+	sim3 := newMarketSimulator(&service.MarketSimulator{}, 43, 50, simTestPeriod, nil)
+	for i := 0; i < 10; i++ {
+		bids1, bids2, bids3 := sim1.next(now), sim2.next(now), sim3.next(now)
+		s.Equal(bids1, bids2)
+		s.NotEqual(bids1[0].Rate, bids3[0].Rate)
+	}
+}
+
+func (s *simulatorTestSuite) Test_Walk_FullCorrelation() {
+	sim := newMarketSimulator(&service.MarketSimulator{Correlation: 1, Volatility: 1}, 1, 0, simTestPeriod, nil)
+	crossRate := sim.midRate("EUR", "RUB")
+	usdRate := sim.midRate("EUR", "USD")
+	for i := 0; i < 100; i++ {
+		sim.walk()
+	}
+	// all assets move together against the numeraire, so cross rates keep
+	s.InDelta(crossRate, sim.midRate("EUR", "RUB"), crossRate*1e-9)
+	s.NotEqual(usdRate, sim.midRate("EUR", "USD"))
+}
+
+func (s *simulatorTestSuite) Test_MarketBids() {
+	now := time.Now().UTC()
+	sim := newMarketSimulator(&service.MarketSimulator{Exchanges: "ex1,ex2", Methods: "m1", Merchants: 2}, 1, 200, simTestPeriod, nil)
+	for _, b := range sim.next(now) {
+		s.NotEqual(b.SrcAsset, b.TrgAsset)
+		s.Contains([]string{"ex1", "ex2"}, b.ExchangeCode)
+		s.Equal([]string{"m1"}, b.Methods)
+		s.Contains([]string{"merchant-1", "merchant-2"}, b.UserId)
+		s.Greater(b.MaxLimit, b.MinLimit)
+		s.GreaterOrEqual(b.Available, b.MinLimit*b.Rate)
+		s.LessOrEqual(b.Available, b.MaxLimit*b.Rate)
+		// bids are quoted below the mid rate
+		mid := sim.midRate(b.SrcAsset, b.TrgAsset)
+		s.Less(b.Rate, mid*(1-sim.spread)+mid*1e-9)
+		s.Equal(now, b.ObservedAt)
+	}
+}
+
+func (s *simulatorTestSuite) Test_ParseScenario_Invalid() {
+	tests := []struct {
+		name string
+		yml  string
+	}{
+		{"no name", "events: [{profit: 0.5, legs: [{src: RUB, trg: USD}, {src: USD, trg: RUB}]}]"},
+		{"not unique", "events: [{name: a, profit: 0.5, legs: [{src: RUB, trg: USD}, {src: USD, trg: RUB}]}, {name: a, profit: 0.5, legs: [{src: RUB, trg: USD}, {src: USD, trg: RUB}]}]"},
+		{"no profit", "events: [{name: a, legs: [{src: RUB, trg: USD}, {src: USD, trg: RUB}]}]"},
+		{"one leg", "events: [{name: a, profit: 0.5, legs: [{src: RUB, trg: RUB}]}]"},
+		{"not cycle", "events: [{name: a, profit: 0.5, legs: [{src: RUB, trg: USD}, {src: EUR, trg: RUB}]}]"},
+		{"unknown asset", "events: [{name: a, profit: 0.5, legs: [{src: RUB, trg: XXX}, {src: XXX, trg: RUB}]}]"},
+		{"profit too high", "events: [{name: a, profit: 3, legs: [{src: RUB, trg: USD}, {src: USD, trg: RUB}]}]"},
+	}
+	for _, tt := range tests {
+		_, err := ParseSimScenario(s.Ctx, []byte(tt.yml), defaultSimSpread)
+		s.AssertAppErr(err, errors.ErrCodeSimulatorScenarioInvalid)
+	}
+	_, err := ParseSimScenario(s.Ctx, []byte("events: ["), defaultSimSpread)
+	s.AssertAppErr(err, errors.ErrCodeSimulatorScenarioLoad)
+}
+
+func (s *simulatorTestSuite) Test_Scenario_CycleBids() {
+	now := time.Now().UTC()
+	sim := newMarketSimulator(&service.MarketSimulator{}, 1, 0, simTestPeriod, s.scenario())
+	// before the first event
+	s.Empty(sim.next(now))
+	s.Empty(sim.next(now))
+	// rub-usdt appears at 30s
+	bids := sim.next(now)
+	s.Len(bids, 2)
+	s.Equal("scn-rub-usdt-0", bids[0].Id)
+	s.Equal("binance", bids[0].ExchangeCode)
+	s.Equal([]string{"M2"}, bids[1].Methods)
+	s.InDelta(1.015, bids[0].Rate*bids[1].Rate, 1e-9)
+	s.Len(sim.next(now), 2)
+	s.Len(sim.next(now), 2)
+	// eur-btc-eth joins at 1m and rub-usdt goes away at 1m30s
+	for i := 0; i < 3; i++ {
+		s.Len(sim.next(now), 5)
+	}
+	bids = sim.next(now)
+	s.Len(bids, 3)
+	s.InDelta(1.01, bids[0].Rate*bids[1].Rate*bids[2].Rate, 1e-9)
+}
+
+func (s *simulatorTestSuite) Test_Scenario_OnlyInjectedChainsFoundAndNotified() {
+	chainStorage := &mocks.ChainStorage{}
+	notifier := &mocks.Notifier{}
+	rates := &mocks.ReferenceRateProvider{}
+	rates.On("ToBase", mock.Anything, mock.Anything, mock.Anything).Return(0.0, false)
+
+	// chains are stored and notified once
+	stored := make(map[string]bool)
+	chainStorage.On("ProfitableChainExists", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, id string) bool { return stored[id] }, nil)
+	chainStorage.On("SaveProfitableChains", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			for _, ch := range args.Get(1).([]*domain.ProfitableChain) {
+				stored[ch.Id] = true
+			}
+		}).
+		Return(nil)
+	notifiedChan := make(chan []*domain.ProfitableChain, 10)
+	notifier.On("Notify", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			notifiedChan <- args.Get(1).([]*domain.ProfitableChain)
+		}).
+		Return(nil)
+
+	svc := NewArbitrageService(chainStorage, nil, nil, rates, nil, notifier).(*arbitrageSvcImpl)
+	svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Depth: 3, MinProfit: 1.0}})
+	ctx, cancel := context.WithCancel(s.Ctx)
+	defer cancel()
+	svc.saveProfitableChainsWorker(ctx, 1)
+	svc.profitableChainsNotifyWorker(ctx, 1)
+
+	sim := newMarketSimulator(&service.MarketSimulator{Merchants: 20}, 7, 200, simTestPeriod, s.scenario())
+	now := time.Now().UTC()
+	var notified []*domain.ProfitableChain
+	for step := 0; step < 12; step++ {
+		source := newStaticBidSource(sim.next(now))
+		// the same stages the background workers go through, chains are saved and notified by the service workers
+		for _, asset := range sim.assets {
+			candidates := &domain.CandidateChains{}
+			s.NoError(svc.findChainsRecurse(s.Ctx, source, asset, asset, nil, candidates, 0))
+			chains, _, err := svc.buildChains(s.Ctx, source, candidates.Chains, true)
+			s.NoError(err)
+			if len(chains) == 0 {
+				continue
+			}
+			svc.saveProfitableChainsChan <- chains
+			// wait for the notification, so the next step sees the chains stored
+			select {
+			case batch := <-notifiedChan:
+				notified = append(notified, batch...)
+			case <-time.After(time.Second * 5):
+				s.Fail("chains not notified")
+				return
+			}
+		}
+	}
+	chainStorage.AssertNumberOfCalls(s.T(), "SaveProfitableChains", len(notifier.Calls))
+
+	// each injected cycle is found once per its starting asset
+	var found []string
+	for _, ch := range notified {
+		var ids []string
+		for _, b := range ch.Bids {
+			s.True(strings.HasPrefix(b.Id, "scn-"), "market bid in chain %s", b.Id)
+			ids = append(ids, b.Id)
+		}
+		found = append(found, strings.Join(ids, ","))
+		s.True(ch.ProfitShare > 1.0)
+	}
+	sort.Strings(found)
+	s.Equal([]string{
+		"scn-eur-btc-eth-0,scn-eur-btc-eth-1,scn-eur-btc-eth-2",
+		"scn-eur-btc-eth-1,scn-eur-btc-eth-2,scn-eur-btc-eth-0",
+		"scn-eur-btc-eth-2,scn-eur-btc-eth-0,scn-eur-btc-eth-1",
+		"scn-rub-usdt-0,scn-rub-usdt-1",
+		"scn-rub-usdt-1,scn-rub-usdt-0",
+	}, found)
+	for _, ch := range notified {
+		if ch.Depth == 2 {
+			s.InDelta(1.015, ch.ProfitShare, 1e-9)
+		} else {
+			s.Less(math.Abs(ch.ProfitShare-1.01), 1e-9)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package arbitrage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	memcache "github.com/mikhailbolshakov/cryptocare/src/kit/cache"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/goroutine"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"go.uber.org/atomic"
+	"sort"
+	"time"
+)
+
+const (
+	defaultPrivateChainsPeriodSec = 60
+	defaultSearchChainsLimit      = 100
+	maxSearchChainsLimit          = 1000
+)
+
+// privateBidSource combines the public market with private bids of the user
+type privateBidSource struct {
+	bidProvider domain.BidProvider
+	bySrcAsset  map[string][]*domain.BidLight
+	privateIds  map[string]struct{}
+}
+
+func newPrivateBidSource(bidProvider domain.BidProvider, privateBids []*domain.BidLight) *privateBidSource {
+	r := &privateBidSource{
+		bidProvider: bidProvider,
+		bySrcAsset:  make(map[string][]*domain.BidLight),
+		privateIds:  make(map[string]struct{}, len(privateBids)),
+	}
+	for _, b := range privateBids {
+		r.bySrcAsset[b.SrcAsset] = append(r.bySrcAsset[b.SrcAsset], b)
+		r.privateIds[b.Id] = struct{}{}
+	}
+	return r
+}
+
+func (p *privateBidSource) GetBidLightsBySourceAsset(ctx context.Context, srcAsset string) ([]*domain.BidLight, error) {
+	bids, err := p.bidProvider.GetBidLightsBySourceAsset(ctx, srcAsset)
+	if err != nil {
+		return nil, err
+	}
+	private := p.bySrcAsset[srcAsset]
+	if len(private) == 0 {
+		return bids, nil
+	}
+	r := make([]*domain.BidLight, 0, len(bids)+len(private))
+	r = append(r, bids...)
+	return append(r, private...), nil
+}
+
+func (p *privateBidSource) GetBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
+	var publicIds, privateIds []string
+	for _, id := range ids {
+		if _, ok := p.privateIds[id]; ok {
+			privateIds = append(privateIds, id)
+		} else {
+			publicIds = append(publicIds, id)
+		}
+	}
+	var r []*domain.Bid
+	if len(publicIds) > 0 {
+		bids, err := p.bidProvider.GetBidsByIds(ctx, publicIds)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, bids...)
+	}
+	if len(privateIds) > 0 {
+		bids, err := p.bidProvider.GetPrivateBidsByIds(ctx, privateIds)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, bids...)
+	}
+	return r, nil
+}
+
+type privateChainSvcImpl struct {
+	bidProvider domain.BidProvider
+	search      *arbitrageSvcImpl
+	notifiers   []domain.PrivateChainNotifier
+	notified    memcache.MemCache
+	cancelFunc  context.CancelFunc
+	running     *atomic.Bool
+	cfg         *service.Config
+}
+
+func NewPrivateChainService(bidProvider domain.BidProvider, referenceRates domain.ReferenceRateProvider, notifiers ...domain.PrivateChainNotifier) domain.PrivateChainService {
+	return &privateChainSvcImpl{
+		bidProvider: bidProvider,
+		search: &arbitrageSvcImpl{
+			bidProvider:    bidProvider,
+			referenceRates: referenceRates,
+		},
+		notifiers: notifiers,
+		notified:  memcache.NewMemCache(),
+		running:   atomic.NewBool(false),
+	}
+}
+
+func (s *privateChainSvcImpl) l() log.CLogger {
+	return service.L().Cmp("private-chain-svc")
+}
+
+func (s *privateChainSvcImpl) Init(cfg *service.Config) {
+	s.cfg = cfg
+	s.search.Init(cfg)
+}
+
+func (s *privateChainSvcImpl) period() time.Duration {
+	periodSec := s.cfg.Arbitrage.Private.PeriodSec
+	if periodSec <= 0 {
+		periodSec = defaultPrivateChainsPeriodSec
+	}
+	return time.Duration(periodSec) * time.Second
+}
+
+// chainHasPrivateBids checks if any bid of the chain is private
+func chainHasPrivateBids(chain *domain.ProfitableChain) bool {
+	for _, b := range chain.Bids {
+		if b.Private {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *privateChainSvcImpl) SearchChains(ctx context.Context, rq *domain.SearchChainsRequest) ([]*domain.ProfitableChain, error) {
+	l := s.l().C(ctx).Mth("search").F(log.FF{"userId": rq.UserId}).Trc()
+
+	if rq.UserId == "" {
+		return nil, errors.ErrChainsSearchUserEmpty(ctx)
+	}
+	if rq.Limit > maxSearchChainsLimit {
+		return nil, errors.ErrChainsSearchLimitExceeded(ctx, maxSearchChainsLimit)
+	}
+	limit := rq.Limit
+	if limit <= 0 {
+		limit = defaultSearchChainsLimit
+	}
+
+	privateBids, err := s.bidProvider.GetPrivateBidLights(ctx, rq.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if rq.OnlyPrivate && len(privateBids) == 0 {
+		return []*domain.ProfitableChain{}, nil
+	}
+
+	// if assets aren't specified, chains are searched through assets of the private bids
+	assets := kit.Strings(rq.Assets)
+	if len(assets) == 0 {
+		for _, b := range privateBids {
+			assets = append(assets, b.SrcAsset, b.TrgAsset)
+		}
+	}
+	assets = assets.Distinct()
+
+	source := newPrivateBidSource(s.bidProvider, privateBids)
+	chainMap := make(map[string]*domain.ProfitableChain)
+	for _, asset := range assets {
+		candidates := &domain.CandidateChains{}
+		if err := s.search.findChainsRecurse(ctx, source, asset, asset, nil, candidates, 0); err != nil {
+			return nil, err
+		}
+		chains, _, err := s.search.buildChains(ctx, source, candidates.Chains, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, ch := range chains {
+			if chainHasPrivateBids(ch) {
+				ch.OwnerId = rq.UserId
+			} else if rq.OnlyPrivate {
+				continue
+			}
+			chainMap[ch.Id] = ch
+		}
+	}
+
+	r := make([]*domain.ProfitableChain, 0, len(chainMap))
+	for _, ch := range chainMap {
+		r = append(r, ch)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].ProfitShare == r[j].ProfitShare {
+			return r[i].Id < r[j].Id
+		}
+		return r[i].ProfitShare > r[j].ProfitShare
+	})
+	if len(r) > limit {
+		r = r[:limit]
+	}
+	l.DbgF("found: %d", len(r))
+	return r, nil
+}
+
+// notifyOwner searches chains with private bids of the owner and notifies about new ones
+func (s *privateChainSvcImpl) notifyOwner(ctx context.Context, ownerId string, now time.Time) error {
+	chains, err := s.SearchChains(ctx, &domain.SearchChainsRequest{UserId: ownerId, OnlyPrivate: true, Limit: maxSearchChainsLimit})
+	if err != nil {
+		return err
+	}
+
+	// the owner is notified about the chain once while it's alive
+	var newChains []*domain.ProfitableChain
+	for _, ch := range chains {
+		key := ownerId + ":" + ch.Id
+		if _, ok := s.notified.Get(key); ok {
+			continue
+		}
+		s.notified.Set(key, struct{}{}, ch.ExpiresAt.Sub(now))
+		newChains = append(newChains, ch)
+	}
+	if len(newChains) == 0 {
+		return nil
+	}
+
+	for _, notifier := range s.notifiers {
+		if err := notifier.NotifyPrivate(ctx, ownerId, newChains); err != nil {
+			s.l().C(ctx).Mth("notify-owner").E(err).Err()
+		}
+	}
+	return nil
+}
+
+// process goes through owners of private bids
+func (s *privateChainSvcImpl) process(ctx context.Context, now time.Time) error {
+	owners, err := s.bidProvider.GetPrivateBidOwners(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ownerId := range owners {
+		if err := s.notifyOwner(ctx, ownerId, now); err != nil {
+			s.l().C(ctx).Mth("process").F(log.FF{"ownerId": ownerId}).E(err).Err()
+		}
+	}
+	return nil
+}
+
+func (s *privateChainSvcImpl) Run(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("run").Trc()
+
+	if s.cfg.Arbitrage.Private == nil || !s.cfg.Arbitrage.Private.Enabled {
+		l.Inf("disabled")
+		return nil
+	}
+
+	// check running
+	if s.running.Load() {
+		return errors.ErrPrivateChainsAlreadyRun(ctx)
+	}
+
+	ctx, s.cancelFunc = context.WithCancel(ctx)
+	s.running.Store(true)
+
+	goroutine.New().
+		WithLogger(s.l().C(ctx).Mth("private-chains-worker")).
+		WithRetry(goroutine.Unrestricted).
+		WithRetryDelay(time.Second*10).
+		Go(ctx, func() {
+			ticker := time.NewTicker(s.period())
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := s.process(ctx, kit.Now()); err != nil {
+						s.l().C(ctx).Mth("private-chains-worker").E(err).Err()
+					}
+				case <-ctx.Done():
+					l.Inf("stop")
+					return
+				}
+			}
+		})
+
+	l.Inf("ok")
+	return nil
+}
+
+func (s *privateChainSvcImpl) Stop(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("stop").Trc()
+	// cancel if running
+	if s.cancelFunc != nil && s.running.Load() {
+		s.cancelFunc()
+		s.running.Store(false)
+		s.cancelFunc = nil
+		l.Inf("ok")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package arbitrage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type privateChainsTestSuite struct {
+	kitTestSuite.Suite
+	bidProvider *mocks.BidProvider
+	rates       *mocks.ReferenceRateProvider
+	notifier    *mocks.PrivateChainNotifier
+	svc         *privateChainSvcImpl
+}
+
+func (s *privateChainsTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestPrivateChainsSuite(t *testing.T) {
+	suite.Run(t, new(privateChainsTestSuite))
+}
+
+func (s *privateChainsTestSuite) SetupTest() {
+	s.bidProvider = &mocks.BidProvider{}
+	s.rates = &mocks.ReferenceRateProvider{}
+	s.rates.On("ToBase", mock.Anything, mock.Anything, mock.Anything).Return(0.0, false).Maybe()
+	s.notifier = &mocks.PrivateChainNotifier{}
+	s.svc = NewPrivateChainService(s.bidProvider, s.rates, s.notifier).(*privateChainSvcImpl)
+	s.svc.Init(&service.Config{
+		Arbitrage: &service.Arbitrage{Depth: 3, MinProfit: 1.0, Private: &service.PrivateChains{Enabled: true}},
+	})
+}
+
+// market sets up the public market USDT -> RUB and the private bid RUB -> USDT of the owner
+func (s *privateChainsTestSuite) market(ownerId string) {
+	public := &domain.Bid{Id: "public", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 62, ExchangeCode: "binance"}
+	private := &domain.Bid{Id: "private", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 1.0 / 60, ExchangeCode: "otc", OwnerId: ownerId, Private: true}
+	s.bidProvider.On("GetPrivateBidLights", mock.Anything, ownerId).Return([]*domain.BidLight{
+		{Id: private.Id, SrcAsset: private.SrcAsset, TrgAsset: private.TrgAsset, Rate: private.Rate, ExchangeCode: private.ExchangeCode, OwnerId: ownerId},
+	}, nil)
+	s.bidProvider.On("GetBidLightsBySourceAsset", mock.Anything, "USDT").Return([]*domain.BidLight{
+		{Id: public.Id, SrcAsset: public.SrcAsset, TrgAsset: public.TrgAsset, Rate: public.Rate, ExchangeCode: public.ExchangeCode},
+	}, nil)
+	s.bidProvider.On("GetBidLightsBySourceAsset", mock.Anything, "RUB").Return(nil, nil)
+	s.bidProvider.On("GetBidsByIds", mock.Anything, []string{public.Id}).Return([]*domain.Bid{public}, nil)
+	s.bidProvider.On("GetPrivateBidsByIds", mock.Anything, []string{private.Id}).Return([]*domain.Bid{private}, nil)
+}
+
+func (s *privateChainsTestSuite) Test_SearchChains_CombinesPrivateWithPublic() {
+	ownerId := kit.NewId()
+	s.market(ownerId)
+
+	chains, err := s.svc.SearchChains(s.Ctx, &domain.SearchChainsRequest{UserId: ownerId, OnlyPrivate: true})
+	s.NoError(err)
+	// the same cycle is found starting from both assets
+	s.Len(chains, 2)
+	for _, ch := range chains {
+		s.Equal(ownerId, ch.OwnerId)
+		s.Len(ch.Bids, 2)
+		s.InDelta(62.0/60, ch.ProfitShare, 0.0001)
+	}
+}
+
+func (s *privateChainsTestSuite) Test_SearchChains_NoPrivateBids() {
+	ownerId := kit.NewId()
+	s.bidProvider.On("GetPrivateBidLights", mock.Anything, ownerId).Return(nil, nil)
+	chains, err := s.svc.SearchChains(s.Ctx, &domain.SearchChainsRequest{UserId: ownerId, OnlyPrivate: true})
+	s.NoError(err)
+	s.Empty(chains)
+	s.bidProvider.AssertNotCalled(s.T(), "GetBidLightsBySourceAsset", mock.Anything, mock.Anything)
+}
+
+func (s *privateChainsTestSuite) Test_SearchChains_Validation() {
+	_, err := s.svc.SearchChains(s.Ctx, &domain.SearchChainsRequest{})
+	s.AssertAppErr(err, errors.ErrCodeChainsSearchUserEmpty)
+	_, err = s.svc.SearchChains(s.Ctx, &domain.SearchChainsRequest{UserId: kit.NewId(), Limit: maxSearchChainsLimit + 1})
+	s.AssertAppErr(err, errors.ErrCodeChainsSearchLimitExceeded)
+}
+
+func (s *privateChainsTestSuite) Test_Process_NotifiesOwnerOnce() {
+	ownerId := kit.NewId()
+	s.market(ownerId)
+	s.bidProvider.On("GetPrivateBidOwners", mock.Anything).Return([]string{ownerId}, nil)
+	s.notifier.On("NotifyPrivate", mock.Anything, ownerId, mock.MatchedBy(func(chains []*domain.ProfitableChain) bool {
+		return len(chains) == 2
+	})).Return(nil).Once()
+
+	now := kit.Now()
+	s.NoError(s.svc.process(s.Ctx, now))
+	// chains already notified
+	s.NoError(s.svc.process(s.Ctx, now))
+	s.notifier.AssertExpectations(s.T())
+}
@@ -0,0 +1,94 @@
+package arbitrage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"time"
+)
+
+const (
+	defaultRateHistoryResolutionSec = 60
+	// rateHistoryCleanupPeriod how often old points are deleted
+	rateHistoryCleanupPeriod = time.Hour
+)
+
+type ratePointKey struct {
+	src, trg, exchange, method string
+}
+
+// aggregateRatePoints aggregates bids by pair, exchange and method
+// bid with several methods goes to each method's series, bid without methods goes to the series with empty method
+// besides, each bid goes once to the series aggregated over all exchanges and/or all methods, so totals aren't counted per method
+func aggregateRatePoints(bids []*domain.BidLight, t time.Time) []*domain.RatePoint {
+	rates := make(map[ratePointKey][]float64)
+	points := make(map[ratePointKey]*domain.RatePoint)
+	for _, b := range bids {
+		methods := b.Methods
+		if len(methods) == 0 {
+			methods = []string{""}
+		}
+		methods = append(methods[:len(methods):len(methods)], domain.RatePointAll)
+		for _, exchange := range []string{b.ExchangeCode, domain.RatePointAll} {
+			for _, m := range methods {
+				key := ratePointKey{b.SrcAsset, b.TrgAsset, exchange, m}
+				p, ok := points[key]
+				if !ok {
+					p = &domain.RatePoint{
+						SrcAsset:     b.SrcAsset,
+						TrgAsset:     b.TrgAsset,
+						ExchangeCode: exchange,
+						Method:       m,
+						Time:         t,
+					}
+					points[key] = p
+				}
+				if b.Rate > p.BestRate {
+					p.BestRate = b.Rate
+				}
+				p.Volume += b.Available
+				p.Bids++
+				rates[key] = append(rates[key], b.Rate)
+			}
+		}
+	}
+	r := make([]*domain.RatePoint, 0, len(points))
+	for key, p := range points {
+		p.MedianRate = kit.Median(rates[key])
+		r = append(r, p)
+	}
+	return r
+}
+
+// saveRateHistory saves the bids snapshot to the rate history once per resolution period
+func (s *bidProviderImpl) saveRateHistory(ctx context.Context, bids []*domain.BidLight, now time.Time) error {
+	if s.rateHistory == nil || s.cfg.Market == nil || s.cfg.Market.History == nil || !s.cfg.Market.History.Enabled {
+		return nil
+	}
+	cfg := s.cfg.Market.History
+
+	resolutionSec := cfg.ResolutionSec
+	if resolutionSec <= 0 {
+		resolutionSec = defaultRateHistoryResolutionSec
+	}
+	t := now.Truncate(time.Duration(resolutionSec) * time.Second)
+	if !t.After(s.historyTime) {
+		return nil
+	}
+
+	points := aggregateRatePoints(bids, t)
+	if err := s.rateHistory.SaveRatePoints(ctx, points); err != nil {
+		return err
+	}
+	s.historyTime = t
+	s.l().C(ctx).Mth("rate-history").DbgF("points: %d", len(points))
+
+	// clean up old points
+	if cfg.RetentionDays > 0 && now.Sub(s.historyCleanupTime) >= rateHistoryCleanupPeriod {
+		if err := s.rateHistory.DeleteRatePoints(ctx, now.AddDate(0, 0, -cfg.RetentionDays)); err != nil {
+			return err
+		}
+		s.historyCleanupTime = now
+	}
+	return nil
+}
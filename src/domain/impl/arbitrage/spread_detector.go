@@ -0,0 +1,327 @@
+package arbitrage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/goroutine"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/mitchellh/hashstructure/v2"
+	"go.uber.org/atomic"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultSpreadPeriodSec = 30
+	defaultSpreadTtlSec    = 60 * 60
+	defaultSpreadsPageSize = 100
+	maxSpreadsPageSize     = 1000
+)
+
+// spreadCandidate is a pair of bids making a spread
+type spreadCandidate struct {
+	spreadType string
+	buy        *domain.BidLight // buy converts the quote asset to the base asset
+	sell       *domain.BidLight // sell converts the base asset to the quote asset
+	volume     float64          // volume in the base asset
+}
+
+type spreadDetectorImpl struct {
+	bidProvider   domain.BidProvider
+	spreadStorage domain.SpreadStorage
+	notifiers     []domain.SpreadNotifier
+	cancelFunc    context.CancelFunc
+	running       *atomic.Bool
+	cfg           *service.Config
+}
+
+func NewSpreadDetector(spreadStorage domain.SpreadStorage, bidProvider domain.BidProvider, notifiers ...domain.SpreadNotifier) domain.SpreadDetector {
+	return &spreadDetectorImpl{
+		spreadStorage: spreadStorage,
+		bidProvider:   bidProvider,
+		notifiers:     notifiers,
+		running:       atomic.NewBool(false),
+	}
+}
+
+func (s *spreadDetectorImpl) l() log.CLogger {
+	return service.L().Cmp("spread-detector")
+}
+
+func (s *spreadDetectorImpl) Init(cfg *service.Config) {
+	s.cfg = cfg
+}
+
+func (s *spreadDetectorImpl) period() time.Duration {
+	periodSec := s.cfg.Arbitrage.Spread.PeriodSec
+	if periodSec <= 0 {
+		periodSec = defaultSpreadPeriodSec
+	}
+	return time.Duration(periodSec) * time.Second
+}
+
+func (s *spreadDetectorImpl) ttl() time.Duration {
+	ttlSec := s.cfg.Arbitrage.Spread.TtlSec
+	if ttlSec <= 0 {
+		ttlSec = defaultSpreadTtlSec
+	}
+	return time.Duration(ttlSec) * time.Second
+}
+
+func spreadGenId(buyBidId, sellBidId string) string {
+	hash, _ := hashstructure.Hash([]string{buyBidId, sellBidId}, hashstructure.FormatV2, nil)
+	return strconv.FormatUint(hash, 10)
+}
+
+// spreadType defines type of the spread by its legs, empty if legs don't make a cross spread
+func spreadType(buy, sell *domain.BidLight) string {
+	if buy.ExchangeCode != sell.ExchangeCode {
+		return domain.SpreadTypeCrossExchange
+	}
+	if len(buy.Methods) > 0 && len(sell.Methods) > 0 &&
+		len(kit.Strings(buy.Methods).Sanitize().Intersect(kit.Strings(sell.Methods).Sanitize())) == 0 {
+		return domain.SpreadTypeCrossMethod
+	}
+	return ""
+}
+
+// spreadVolume calculates amount of the base asset which can be bought with the buy bid and sold with the sell bid
+// bid available amount is specified in the target asset, limits are in the source asset
+func spreadVolume(buy, sell *domain.BidLight, checkLimit bool) (float64, bool) {
+	volume := math.Min(buy.Available, sell.Available/sell.Rate)
+	if !checkLimit {
+		return volume, true
+	}
+	if buy.MaxLimit > 0.0 {
+		volume = math.Min(volume, buy.MaxLimit*buy.Rate)
+	}
+	if sell.MaxLimit > 0.0 {
+		volume = math.Min(volume, sell.MaxLimit)
+	}
+	if volume <= 0.0 || volume/buy.Rate < buy.MinLimit || volume < sell.MinLimit {
+		return 0, false
+	}
+	return volume, true
+}
+
+// findSpreads goes through all pairs of opposite bids and finds ones with the spread not less than min share
+func findSpreads(bids []*domain.BidLight, minShare float64, checkLimit bool, staleBefore time.Time) []*spreadCandidate {
+
+	// group bids by direction
+	byPair := make(map[[2]string][]*domain.BidLight)
+	for _, b := range bids {
+		if b.Rate <= 0.0 || bidStale(b, staleBefore) {
+			continue
+		}
+		key := [2]string{b.SrcAsset, b.TrgAsset}
+		byPair[key] = append(byPair[key], b)
+	}
+
+	var r []*spreadCandidate
+	for key, direct := range byPair {
+		// each pair of directions is processed once
+		if key[0] > key[1] {
+			continue
+		}
+		for _, d := range direct {
+			for _, o := range byPair[[2]string{key[1], key[0]}] {
+				// the base asset is the one which costs more than the quote asset
+				buy, sell := o, d
+				if d.Rate < 1.0 {
+					buy, sell = d, o
+				}
+				share := buy.Rate * sell.Rate
+				if share <= 1.0 || share < minShare {
+					continue
+				}
+				t := spreadType(buy, sell)
+				if t == "" {
+					continue
+				}
+				volume, ok := spreadVolume(buy, sell, checkLimit)
+				if !ok {
+					continue
+				}
+				r = append(r, &spreadCandidate{spreadType: t, buy: buy, sell: sell, volume: volume})
+			}
+		}
+	}
+	return r
+}
+
+// buildSpreads builds new spreads from candidates, spreads which are already stored are skipped
+func (s *spreadDetectorImpl) buildSpreads(ctx context.Context, candidates []*spreadCandidate, now time.Time) ([]*domain.Spread, error) {
+
+	var newCandidates []*spreadCandidate
+	var bidIds kit.Strings
+	for _, c := range candidates {
+		exists, err := s.spreadStorage.SpreadExists(ctx, spreadGenId(c.buy.Id, c.sell.Id))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+		newCandidates = append(newCandidates, c)
+		bidIds = append(bidIds, c.buy.Id, c.sell.Id)
+	}
+	if len(newCandidates) == 0 {
+		return nil, nil
+	}
+
+	bids, err := s.bidProvider.GetBidsByIds(ctx, bidIds.Distinct())
+	if err != nil {
+		return nil, err
+	}
+	bidMap := make(map[string]*domain.Bid, len(bids))
+	for _, b := range bids {
+		bidMap[b.Id] = b
+	}
+
+	var spreads []*domain.Spread
+	for _, c := range newCandidates {
+		buy, sell := bidMap[c.buy.Id], bidMap[c.sell.Id]
+		// bid might have gone away already
+		if buy == nil || sell == nil {
+			continue
+		}
+		var methods kit.Strings
+		methods = append(methods, buy.Methods...)
+		methods = append(methods, sell.Methods...)
+		spread := &domain.Spread{
+			Id:            spreadGenId(buy.Id, sell.Id),
+			Type:          c.spreadType,
+			BaseAsset:     sell.SrcAsset,
+			QuoteAsset:    sell.TrgAsset,
+			Buy:           buy,
+			Sell:          sell,
+			BuyPrice:      1 / buy.Rate,
+			SellPrice:     sell.Rate,
+			SpreadShare:   buy.Rate * sell.Rate,
+			Volume:        c.volume,
+			ExchangeCodes: kit.Strings{buy.ExchangeCode, sell.ExchangeCode}.Distinct(),
+			Methods:       methods.Distinct(),
+			CreatedAt:     now,
+			ExpiresAt:     now.Add(s.ttl()),
+		}
+		spread.Profit = spread.Volume * (spread.SellPrice - spread.BuyPrice)
+		spread.ObservedAt = buy.ObservedAt
+		if spread.ObservedAt.IsZero() || (!sell.ObservedAt.IsZero() && sell.ObservedAt.Before(spread.ObservedAt)) {
+			spread.ObservedAt = sell.ObservedAt
+		}
+		spreads = append(spreads, spread)
+	}
+	return spreads, nil
+}
+
+// detect computes spreads from the current bids, saves and notifies new ones
+func (s *spreadDetectorImpl) detect(ctx context.Context, now time.Time) error {
+	l := s.l().C(ctx).Mth("detect")
+
+	bids, err := s.bidProvider.GetBidLights(ctx)
+	if err != nil {
+		return err
+	}
+
+	candidates := findSpreads(bids, 1+s.cfg.Arbitrage.Spread.MinSpread*0.01, s.cfg.Arbitrage.CheckLimit, bidStaleBefore(s.cfg, now))
+	spreads, err := s.buildSpreads(ctx, candidates, now)
+	if err != nil {
+		return err
+	}
+	if len(spreads) == 0 {
+		return nil
+	}
+	l.DbgF("new spreads: %d", len(spreads))
+
+	if err := s.spreadStorage.SaveSpreads(ctx, spreads); err != nil {
+		return err
+	}
+	for _, notifier := range s.notifiers {
+		if err := notifier.NotifySpreads(ctx, spreads); err != nil {
+			s.l().C(ctx).Mth("detect").E(err).Err()
+		}
+	}
+	return nil
+}
+
+func (s *spreadDetectorImpl) Run(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("run").Trc()
+
+	if s.cfg.Arbitrage.Spread == nil || !s.cfg.Arbitrage.Spread.Enabled {
+		l.Inf("disabled")
+		return nil
+	}
+
+	// check running
+	if s.running.Load() {
+		return errors.ErrSpreadDetectorAlreadyRun(ctx)
+	}
+
+	ctx, s.cancelFunc = context.WithCancel(ctx)
+	s.running.Store(true)
+
+	goroutine.New().
+		WithLogger(s.l().C(ctx).Mth("spread-worker")).
+		WithRetry(goroutine.Unrestricted).
+		WithRetryDelay(time.Second*10).
+		Go(ctx, func() {
+			ticker := time.NewTicker(s.period())
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := s.detect(ctx, kit.Now()); err != nil {
+						s.l().C(ctx).Mth("spread-worker").E(err).Err()
+					}
+				case <-ctx.Done():
+					l.Inf("stop")
+					return
+				}
+			}
+		})
+
+	l.Inf("ok")
+	return nil
+}
+
+func (s *spreadDetectorImpl) Stop(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("stop").Trc()
+	// cancel if running
+	if s.cancelFunc != nil && s.running.Load() {
+		s.cancelFunc()
+		s.running.Store(false)
+		s.cancelFunc = nil
+		l.Inf("ok")
+	}
+	return nil
+}
+
+func (s *spreadDetectorImpl) GetSpreads(ctx context.Context, rq *domain.GetSpreadsRequest) (*domain.GetSpreadsResponse, error) {
+	s.l().C(ctx).Mth("get-spreads").Trc()
+	if rq.Size <= 0 {
+		rq.Size = defaultSpreadsPageSize
+	}
+	if rq.Size > maxSpreadsPageSize {
+		return nil, errors.ErrSpreadsPageSizeExceeded(ctx, maxSpreadsPageSize)
+	}
+	if rq.Index < 0 {
+		rq.Index = 0
+	}
+	return s.spreadStorage.GetSpreads(ctx, rq)
+}
+
+func (s *spreadDetectorImpl) GetSpread(ctx context.Context, spreadId string) (*domain.Spread, error) {
+	s.l().C(ctx).Mth("get-spread").F(log.FF{"spreadId": spreadId}).Trc()
+	spread, err := s.spreadStorage.GetSpread(ctx, spreadId)
+	if err != nil {
+		return nil, err
+	}
+	if spread == nil {
+		return nil, errors.ErrSpreadNotFound(ctx, spreadId)
+	}
+	return spread, nil
+}
@@ -0,0 +1,166 @@
+package arbitrage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type spreadDetectorTestSuite struct {
+	kitTestSuite.Suite
+	spreadStorage *mocks.SpreadStorage
+	bidProvider   *mocks.BidProvider
+	notifier      *mocks.SpreadNotifier
+	detector      *spreadDetectorImpl
+}
+
+func (s *spreadDetectorTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestSpreadDetectorSuite(t *testing.T) {
+	suite.Run(t, new(spreadDetectorTestSuite))
+}
+
+func (s *spreadDetectorTestSuite) SetupTest() {
+	s.spreadStorage = &mocks.SpreadStorage{}
+	s.bidProvider = &mocks.BidProvider{}
+	s.notifier = &mocks.SpreadNotifier{}
+	s.detector = NewSpreadDetector(s.spreadStorage, s.bidProvider, s.notifier).(*spreadDetectorImpl)
+	s.detector.Init(&service.Config{Arbitrage: &service.Arbitrage{Spread: &service.SpreadDetector{Enabled: true, MinSpread: 1, TtlSec: 600}}})
+}
+
+func (s *spreadDetectorTestSuite) Test_FindSpreads() {
+	tests := []struct {
+		name  string
+		bids  []*domain.BidLight
+		found []string
+	}{
+		{
+			name: "cross-exchange",
+			bids: []*domain.BidLight{
+				{Id: "buy", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 1.0 / 60, ExchangeCode: "binance", Methods: []string{"M1"}},
+				{Id: "sell", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 62, ExchangeCode: "huobi", Methods: []string{"M1"}},
+			},
+			found: []string{domain.SpreadTypeCrossExchange},
+		},
+		{
+			name: "cross-method",
+			bids: []*domain.BidLight{
+				{Id: "buy", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 1.0 / 60, ExchangeCode: "binance", Methods: []string{"M1"}},
+				{Id: "sell", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 62, ExchangeCode: "binance", Methods: []string{"M2"}},
+			},
+			found: []string{domain.SpreadTypeCrossMethod},
+		},
+		{
+			name: "same exchange and method",
+			bids: []*domain.BidLight{
+				{Id: "buy", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 1.0 / 60, ExchangeCode: "binance", Methods: []string{"M1"}},
+				{Id: "sell", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 62, ExchangeCode: "binance", Methods: []string{"M1", "M2"}},
+			},
+		},
+		{
+			name: "spread less than min",
+			bids: []*domain.BidLight{
+				{Id: "buy", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 1.0 / 60, ExchangeCode: "binance"},
+				{Id: "sell", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 60.3, ExchangeCode: "huobi"},
+			},
+		},
+		{
+			name: "no profit",
+			bids: []*domain.BidLight{
+				{Id: "buy", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 1.0 / 62, ExchangeCode: "binance"},
+				{Id: "sell", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 60, ExchangeCode: "huobi"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		candidates := findSpreads(tt.bids, 1.01, false, time.Time{})
+		s.Len(candidates, len(tt.found), tt.name)
+		for i, c := range candidates {
+			s.Equal(tt.found[i], c.spreadType, tt.name)
+			s.Equal("buy", c.buy.Id, tt.name)
+			s.Equal("sell", c.sell.Id, tt.name)
+		}
+	}
+}
+
+func (s *spreadDetectorTestSuite) Test_FindSpreads_Volume() {
+	bids := []*domain.BidLight{
+		// buys up to 10 USDT for 600 RUB
+		{Id: "buy", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 1.0 / 60, Available: 10, MinLimit: 100, MaxLimit: 600, ExchangeCode: "binance"},
+		// sells up to 5 USDT for 310 RUB
+		{Id: "sell", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 62, Available: 310, MinLimit: 1, MaxLimit: 100, ExchangeCode: "huobi"},
+	}
+	candidates := findSpreads(bids, 1.01, true, time.Time{})
+	s.Len(candidates, 1)
+	s.InDelta(5.0, candidates[0].volume, 0.0001)
+
+	// min limit of the sell leg isn't reached
+	bids[1].MinLimit = 6
+	s.Empty(findSpreads(bids, 1.01, true, time.Time{}))
+	// limits aren't checked
+	s.Len(findSpreads(bids, 1.01, false, time.Time{}), 1)
+}
+
+func (s *spreadDetectorTestSuite) Test_FindSpreads_StaleSkipped() {
+	now := time.Now()
+	bids := []*domain.BidLight{
+		{Id: "buy", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 1.0 / 60, ExchangeCode: "binance", ObservedAt: now.Add(-time.Hour)},
+		{Id: "sell", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 62, ExchangeCode: "huobi", ObservedAt: now},
+	}
+	s.Empty(findSpreads(bids, 1.01, false, now.Add(-time.Minute)))
+}
+
+func (s *spreadDetectorTestSuite) Test_Detect() {
+	now := time.Now()
+	lights := []*domain.BidLight{
+		{Id: "buy", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 1.0 / 60, Available: 10, ExchangeCode: "binance", Methods: []string{"M1"}},
+		{Id: "sell", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 62, Available: 310, ExchangeCode: "huobi", Methods: []string{"M2"}},
+		{Id: "sell2", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 63, Available: 630, ExchangeCode: "huobi", Methods: []string{"M2"}},
+	}
+	bids := []*domain.Bid{
+		{Id: "buy", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 1.0 / 60, Available: 10, ExchangeCode: "binance", Methods: []string{"M1"}, ObservedAt: now.Add(-time.Minute)},
+		{Id: "sell", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 62, Available: 310, ExchangeCode: "huobi", Methods: []string{"M2"}, ObservedAt: now},
+	}
+	s.bidProvider.On("GetBidLights", s.Ctx).Return(lights, nil)
+	s.spreadStorage.On("SpreadExists", s.Ctx, spreadGenId("buy", "sell")).Return(false, nil)
+	// the second spread has been detected already
+	s.spreadStorage.On("SpreadExists", s.Ctx, spreadGenId("buy", "sell2")).Return(true, nil)
+	s.bidProvider.On("GetBidsByIds", s.Ctx, mock.Anything).Return(bids, nil)
+	var saved []*domain.Spread
+	s.spreadStorage.On("SaveSpreads", s.Ctx, mock.Anything).
+		Run(func(args mock.Arguments) { saved = args.Get(1).([]*domain.Spread) }).
+		Return(nil)
+	s.notifier.On("NotifySpreads", s.Ctx, mock.Anything).Return(nil)
+
+	s.NoError(s.detector.detect(s.Ctx, now))
+	s.Len(saved, 1)
+	spread := saved[0]
+	s.Equal(spreadGenId("buy", "sell"), spread.Id)
+	s.Equal(domain.SpreadTypeCrossExchange, spread.Type)
+	s.Equal("USDT", spread.BaseAsset)
+	s.Equal("RUB", spread.QuoteAsset)
+	s.InDelta(60.0, spread.BuyPrice, 0.0001)
+	s.InDelta(62.0, spread.SellPrice, 0.0001)
+	s.InDelta(62.0/60, spread.SpreadShare, 0.0001)
+	s.InDelta(5.0, spread.Volume, 0.0001)
+	s.InDelta(10.0, spread.Profit, 0.0001)
+	s.Equal([]string{"binance", "huobi"}, spread.ExchangeCodes)
+	s.Equal([]string{"M1", "M2"}, spread.Methods)
+	s.Equal(now.Add(-time.Minute), spread.ObservedAt)
+	s.Equal(now.Add(time.Minute*10), spread.ExpiresAt)
+	s.notifier.AssertCalled(s.T(), "NotifySpreads", s.Ctx, saved)
+}
+
+func (s *spreadDetectorTestSuite) Test_GetSpread_NotFound() {
+	s.spreadStorage.On("GetSpread", s.Ctx, "id").Return(nil, nil)
+	_, err := s.detector.GetSpread(s.Ctx, "id")
+	s.AssertAppErr(err, errors.ErrCodeSpreadNotFound)
+}
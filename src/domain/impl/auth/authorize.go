@@ -50,6 +50,11 @@ var permissions = map[string][]rolePermissions{
 	domain.AuthResUserProfileAll:     {rolePermissions{Role: domain.AuthRoleSysAdmin, Permissions: []string{auth.AccessR, auth.AccessW, auth.AccessD}}},
 	domain.AuthResArbitrageChainsAll: {rolePermissions{Role: domain.AuthRoleArbitrageClient, Permissions: []string{auth.AccessR}}},
 	domain.AuthResUserProfileMy:      {rolePermissions{Role: domain.AuthRoleArbitrageClient, Permissions: []string{auth.AccessR, auth.AccessW}}},
+	domain.AuthResMarketAll:          {rolePermissions{Role: domain.AuthRoleArbitrageClient, Permissions: []string{auth.AccessR}}},
+	domain.AuthResArbitrageBidsMy:    {rolePermissions{Role: domain.AuthRoleArbitrageClient, Permissions: []string{auth.AccessR, auth.AccessW, auth.AccessD}}},
+	domain.AuthResArbitrageBidsAll:   {rolePermissions{Role: domain.AuthRoleSysAdmin, Permissions: []string{auth.AccessR, auth.AccessW, auth.AccessD}}},
+	domain.AuthResNotificationsAll:   {rolePermissions{Role: domain.AuthRoleSysAdmin, Permissions: []string{auth.AccessR, auth.AccessW, auth.AccessD}}},
+	domain.AuthResNotificationTpls:   {rolePermissions{Role: domain.AuthRoleArbitrageClient, Permissions: []string{auth.AccessR}}},
 }
 
 func (s *authorizeSvcImpl) authorizeSession(ctx context.Context, rq *auth.AuthorizationRequest) error {
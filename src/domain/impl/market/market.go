@@ -0,0 +1,139 @@
+package market
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
+)
+
+const (
+	defaultHistoryPeriod     = time.Hour * 24
+	defaultHistoryResolution = time.Hour
+	maxHistoryCandles        = 1000
+)
+
+type marketSvcImpl struct {
+	rateHistory    domain.RateHistoryStorage
+	bidProvider    domain.BidProvider
+	referenceRates domain.ReferenceRateProvider
+	cfg            *service.Config
+}
+
+func NewMarketService(rateHistory domain.RateHistoryStorage, bidProvider domain.BidProvider, referenceRates domain.ReferenceRateProvider) domain.MarketService {
+	return &marketSvcImpl{
+		rateHistory:    rateHistory,
+		bidProvider:    bidProvider,
+		referenceRates: referenceRates,
+	}
+}
+
+func (s *marketSvcImpl) l() log.CLogger {
+	return service.L().Cmp("market-svc")
+}
+
+func (s *marketSvcImpl) Init(cfg *service.Config) {
+	s.cfg = cfg
+}
+
+// minResolution candles can't be shorter than the time series resolution
+func (s *marketSvcImpl) minResolution() time.Duration {
+	if s.cfg == nil || s.cfg.Market == nil || s.cfg.Market.History == nil || s.cfg.Market.History.ResolutionSec <= 0 {
+		return time.Minute
+	}
+	return time.Duration(s.cfg.Market.History.ResolutionSec) * time.Second
+}
+
+func (s *marketSvcImpl) validateGetRateHistoryRequest(ctx context.Context, rq *domain.GetRateHistoryRequest) error {
+	if rq.SrcAsset == "" || rq.TrgAsset == "" {
+		return errors.ErrRateHistoryPairInvalid(ctx)
+	}
+	if rq.To.IsZero() {
+		rq.To = kit.Now()
+	}
+	if rq.From.IsZero() {
+		rq.From = rq.To.Add(-defaultHistoryPeriod)
+	}
+	if !rq.From.Before(rq.To) {
+		return errors.ErrRateHistoryPeriodInvalid(ctx)
+	}
+	if rq.Resolution == 0 {
+		rq.Resolution = defaultHistoryResolution
+	}
+	if minRes := s.minResolution(); rq.Resolution < minRes {
+		return errors.ErrRateHistoryResolutionInvalid(ctx, int(minRes.Seconds()))
+	}
+	// candles are aligned by resolution, so the first and the last candles might be partial
+	if int(rq.To.Sub(rq.From.Truncate(rq.Resolution))/rq.Resolution) >= maxHistoryCandles {
+		return errors.ErrRateHistoryTooManyCandles(ctx, maxHistoryCandles)
+	}
+	return nil
+}
+
+func (s *marketSvcImpl) GetRateHistory(ctx context.Context, rq *domain.GetRateHistoryRequest) ([]*domain.RateCandle, error) {
+	s.l().C(ctx).Mth("get-rate-history").F(log.FF{"src": rq.SrcAsset, "trg": rq.TrgAsset}).Trc()
+
+	if err := s.validateGetRateHistoryRequest(ctx, rq); err != nil {
+		return nil, err
+	}
+
+	// if exchange or method isn't specified, the series aggregated over all of them is taken
+	pointsRq := rq.GetRatePointsRequest
+	if pointsRq.ExchangeCode == "" {
+		pointsRq.ExchangeCode = domain.RatePointAll
+	}
+	if pointsRq.Method == "" {
+		pointsRq.Method = domain.RatePointAll
+	}
+	points, err := s.rateHistory.GetRatePoints(ctx, &pointsRq)
+	if err != nil {
+		return nil, err
+	}
+	return buildCandles(points, rq.Resolution), nil
+}
+
+func (s *marketSvcImpl) GetReferenceRates(ctx context.Context) (*domain.ReferenceRates, error) {
+	s.l().C(ctx).Mth("get-reference-rates").Trc()
+	return s.referenceRates.GetRates(ctx), nil
+}
+
+// buildCandles builds OHLC candles by points ordered by time
+func buildCandles(points []*domain.RatePoint, resolution time.Duration) []*domain.RateCandle {
+	var candles []*domain.RateCandle
+	var candle *domain.RateCandle
+	var volumes, medians []float64
+	closeCandle := func() {
+		if candle == nil {
+			return
+		}
+		var volume float64
+		for _, v := range volumes {
+			volume += v
+		}
+		candle.Volume = volume / float64(len(volumes))
+		candle.MedianRate = kit.Median(medians)
+		candles = append(candles, candle)
+	}
+	for _, p := range points {
+		t := p.Time.Truncate(resolution)
+		if candle == nil || !candle.Time.Equal(t) {
+			closeCandle()
+			candle = &domain.RateCandle{Time: t, Open: p.BestRate, High: p.BestRate, Low: p.BestRate}
+			volumes, medians = nil, nil
+		}
+		if p.BestRate > candle.High {
+			candle.High = p.BestRate
+		}
+		if p.BestRate < candle.Low {
+			candle.Low = p.BestRate
+		}
+		candle.Close = p.BestRate
+		volumes = append(volumes, p.Volume)
+		medians = append(medians, p.MedianRate)
+	}
+	closeCandle()
+	return candles
+}
@@ -0,0 +1,123 @@
+package market
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type marketTestSuite struct {
+	kitTestSuite.Suite
+	rateHistory    *mocks.RateHistoryStorage
+	bidProvider    *mocks.BidProvider
+	referenceRates *mocks.ReferenceRateProvider
+	svc            domain.MarketService
+}
+
+func (s *marketTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestMarketSuite(t *testing.T) {
+	suite.Run(t, new(marketTestSuite))
+}
+
+func (s *marketTestSuite) SetupTest() {
+	s.rateHistory = &mocks.RateHistoryStorage{}
+	s.bidProvider = &mocks.BidProvider{}
+	s.referenceRates = &mocks.ReferenceRateProvider{}
+	s.svc = NewMarketService(s.rateHistory, s.bidProvider, s.referenceRates)
+	s.svc.Init(&service.Config{Market: &service.Market{History: &service.RateHistory{Enabled: true, ResolutionSec: 60}}})
+}
+
+func (s *marketTestSuite) Test_GetRateHistory_Validation() {
+	now := time.Now()
+	tests := []struct {
+		rq   *domain.GetRateHistoryRequest
+		code string
+	}{
+		{&domain.GetRateHistoryRequest{GetRatePointsRequest: domain.GetRatePointsRequest{SrcAsset: "USD"}}, errors.ErrCodeRateHistoryPairInvalid},
+		{&domain.GetRateHistoryRequest{GetRatePointsRequest: domain.GetRatePointsRequest{SrcAsset: "USD", TrgAsset: "RUB", From: now, To: now.Add(-time.Hour)}}, errors.ErrCodeRateHistoryPeriodInvalid},
+		{&domain.GetRateHistoryRequest{GetRatePointsRequest: domain.GetRatePointsRequest{SrcAsset: "USD", TrgAsset: "RUB"}, Resolution: time.Second}, errors.ErrCodeRateHistoryResolutionInvalid},
+		{&domain.GetRateHistoryRequest{GetRatePointsRequest: domain.GetRatePointsRequest{SrcAsset: "USD", TrgAsset: "RUB", From: now.AddDate(0, 0, -30)}, Resolution: time.Minute}, errors.ErrCodeRateHistoryTooManyCandles},
+	}
+	for _, tt := range tests {
+		_, err := s.svc.GetRateHistory(s.Ctx, tt.rq)
+		s.AssertAppErr(err, tt.code)
+	}
+}
+
+func (s *marketTestSuite) Test_GetRateHistory_Candles() {
+	start := time.Date(2022, 10, 17, 12, 0, 0, 0, time.UTC)
+	point := func(minutes int, exchange string, best, median, volume float64) *domain.RatePoint {
+		return &domain.RatePoint{SrcAsset: "USD", TrgAsset: "RUB", ExchangeCode: exchange, Time: start.Add(time.Duration(minutes) * time.Minute),
+			BestRate: best, MedianRate: median, Volume: volume, Bids: 1}
+	}
+	points := []*domain.RatePoint{
+		point(0, domain.RatePointAll, 60, 59, 100),
+		point(1, domain.RatePointAll, 63, 61, 300),
+		point(2, domain.RatePointAll, 58, 57, 200),
+		point(5, domain.RatePointAll, 62, 60, 100),
+	}
+	rq := &domain.GetRateHistoryRequest{
+		GetRatePointsRequest: domain.GetRatePointsRequest{SrcAsset: "USD", TrgAsset: "RUB", From: start, To: start.Add(time.Minute * 10)},
+		Resolution:           time.Minute * 5,
+	}
+	// without exchange and method, the series over all of them is requested
+	s.rateHistory.On("GetRatePoints", s.Ctx, mock.MatchedBy(func(rq *domain.GetRatePointsRequest) bool {
+		return rq.ExchangeCode == domain.RatePointAll && rq.Method == domain.RatePointAll
+	})).Return(points, nil)
+
+	candles, err := s.svc.GetRateHistory(s.Ctx, rq)
+	s.NoError(err)
+	s.Len(candles, 2)
+
+	s.Equal(start, candles[0].Time)
+	s.Equal(60.0, candles[0].Open)
+	s.Equal(63.0, candles[0].High)
+	s.Equal(58.0, candles[0].Low)
+	s.Equal(58.0, candles[0].Close)
+	s.Equal(59.0, candles[0].MedianRate)
+	s.Equal(200.0, candles[0].Volume)
+
+	s.Equal(start.Add(time.Minute*5), candles[1].Time)
+	s.Equal(62.0, candles[1].Open)
+	s.Equal(62.0, candles[1].Close)
+	s.Equal(100.0, candles[1].Volume)
+}
+
+func (s *marketTestSuite) Test_GetMarketOverview() {
+	s.bidProvider.On("GetBidLights", mock.Anything).Return([]*domain.BidLight{
+		{SrcAsset: "USDT", TrgAsset: "RUB", ExchangeCode: "binance", Rate: 60, Available: 100, Methods: []string{"tinkoff"}},
+		{SrcAsset: "USDT", TrgAsset: "RUB", ExchangeCode: "binance", Rate: 61, Available: 50, Methods: []string{"sber"}},
+		{SrcAsset: "USDT", TrgAsset: "RUB", ExchangeCode: "huobi", Rate: 61, Available: 10, Methods: []string{"tinkoff"}},
+		{SrcAsset: "BTC", TrgAsset: "USDT", ExchangeCode: "huobi", Rate: 19000, Available: 1, Methods: []string{"tinkoff", "sber"}},
+	}, nil)
+
+	overview, err := s.svc.GetMarketOverview(s.Ctx, &domain.MarketOverviewRequest{})
+	s.NoError(err)
+	s.Equal([]string{"BTC", "RUB", "USDT"}, overview.Assets)
+	s.Equal([]string{"binance", "huobi"}, overview.Exchanges)
+	s.Len(overview.Pairs, 2)
+	s.Equal("BTC", overview.Pairs[0].SrcAsset)
+	pair := overview.Pairs[1]
+	s.Equal("USDT", pair.SrcAsset)
+	s.Equal("RUB", pair.TrgAsset)
+	s.Equal(&domain.PairRate{ExchangeCode: "binance", BestRate: 61, Volume: 160, Bids: 3}, pair.Overall)
+	s.Len(pair.Exchanges, 2)
+	s.Equal(&domain.PairRate{ExchangeCode: "binance", BestRate: 61, Volume: 150, Bids: 2}, pair.Exchanges[0])
+	s.Equal(&domain.PairRate{ExchangeCode: "huobi", BestRate: 61, Volume: 10, Bids: 1}, pair.Exchanges[1])
+
+	overview, err = s.svc.GetMarketOverview(s.Ctx, &domain.MarketOverviewRequest{Method: "tinkoff"})
+	s.NoError(err)
+	s.Len(overview.Pairs, 2)
+	pair = overview.Pairs[1]
+	s.Equal(&domain.PairRate{ExchangeCode: "huobi", BestRate: 61, Volume: 110, Bids: 2}, pair.Overall)
+	s.Equal(&domain.PairRate{ExchangeCode: "binance", BestRate: 60, Volume: 100, Bids: 1}, pair.Exchanges[0])
+}
@@ -0,0 +1,98 @@
+package market
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"sort"
+)
+
+type pairKey struct {
+	src, trg string
+}
+
+func (s *marketSvcImpl) GetMarketOverview(ctx context.Context, rq *domain.MarketOverviewRequest) (*domain.MarketOverview, error) {
+	s.l().C(ctx).Mth("get-market-overview").F(log.FF{"method": rq.Method}).Trc()
+
+	bids, err := s.bidProvider.GetBidLights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildMarketOverview(bids, rq), nil
+}
+
+// buildMarketOverview aggregates bids by pairs and exchanges
+func buildMarketOverview(bids []*domain.BidLight, rq *domain.MarketOverviewRequest) *domain.MarketOverview {
+	assets := make(map[string]struct{})
+	exchanges := make(map[string]struct{})
+	pairs := make(map[pairKey]map[string]*domain.PairRate)
+
+	for _, b := range bids {
+		if rq.Method != "" && !kit.Strings(b.Methods).Contains(rq.Method) {
+			continue
+		}
+		assets[b.SrcAsset] = struct{}{}
+		assets[b.TrgAsset] = struct{}{}
+		exchanges[b.ExchangeCode] = struct{}{}
+
+		key := pairKey{b.SrcAsset, b.TrgAsset}
+		pairExchanges, ok := pairs[key]
+		if !ok {
+			pairExchanges = make(map[string]*domain.PairRate)
+			pairs[key] = pairExchanges
+		}
+		rate, ok := pairExchanges[b.ExchangeCode]
+		if !ok {
+			rate = &domain.PairRate{ExchangeCode: b.ExchangeCode}
+			pairExchanges[b.ExchangeCode] = rate
+		}
+		if b.Rate > rate.BestRate {
+			rate.BestRate = b.Rate
+		}
+		rate.Volume += b.Available
+		rate.Bids++
+	}
+
+	r := &domain.MarketOverview{
+		Assets:    sortedKeys(assets),
+		Exchanges: sortedKeys(exchanges),
+		Pairs:     make([]*domain.PairOverview, 0, len(pairs)),
+	}
+	for key, pairExchanges := range pairs {
+		pair := &domain.PairOverview{
+			SrcAsset: key.src,
+			TrgAsset: key.trg,
+			Overall:  &domain.PairRate{},
+		}
+		for _, rate := range pairExchanges {
+			pair.Exchanges = append(pair.Exchanges, rate)
+			// the best rate is attributed to the exchange, the first one by code if rates are equal
+			if rate.BestRate > pair.Overall.BestRate ||
+				(rate.BestRate == pair.Overall.BestRate && rate.ExchangeCode < pair.Overall.ExchangeCode) {
+				pair.Overall.BestRate = rate.BestRate
+				pair.Overall.ExchangeCode = rate.ExchangeCode
+			}
+			pair.Overall.Volume += rate.Volume
+			pair.Overall.Bids += rate.Bids
+		}
+		sort.Slice(pair.Exchanges, func(i, j int) bool { return pair.Exchanges[i].ExchangeCode < pair.Exchanges[j].ExchangeCode })
+		r.Pairs = append(r.Pairs, pair)
+	}
+	sort.Slice(r.Pairs, func(i, j int) bool {
+		if r.Pairs[i].SrcAsset != r.Pairs[j].SrcAsset {
+			return r.Pairs[i].SrcAsset < r.Pairs[j].SrcAsset
+		}
+		return r.Pairs[i].TrgAsset < r.Pairs[j].TrgAsset
+	})
+	return r
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	r := make([]string, 0, len(m))
+	for k := range m {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r
+}
@@ -0,0 +1,161 @@
+package market
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/goroutine"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"go.uber.org/atomic"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBaseCurrency            = "USD"
+	defaultReferenceRatesPeriodSec = 300
+)
+
+type referenceRateProviderImpl struct {
+	sync.RWMutex
+	source     domain.ReferenceRateSource
+	rates      map[string]float64
+	updatedAt  time.Time
+	cancelFunc context.CancelFunc
+	running    *atomic.Bool
+	cfg        *service.Config
+}
+
+func NewReferenceRateProvider(source domain.ReferenceRateSource) domain.ReferenceRateProvider {
+	return &referenceRateProviderImpl{
+		source:  source,
+		rates:   make(map[string]float64),
+		running: atomic.NewBool(false),
+	}
+}
+
+func (s *referenceRateProviderImpl) l() log.CLogger {
+	return service.L().Cmp("reference-rates")
+}
+
+func (s *referenceRateProviderImpl) Init(cfg *service.Config) {
+	s.cfg = cfg
+}
+
+func (s *referenceRateProviderImpl) BaseCurrency() string {
+	if s.cfg == nil || s.cfg.Market == nil || s.cfg.Market.ReferenceRates == nil || s.cfg.Market.ReferenceRates.Base == "" {
+		return defaultBaseCurrency
+	}
+	return strings.ToUpper(s.cfg.Market.ReferenceRates.Base)
+}
+
+func (s *referenceRateProviderImpl) period() time.Duration {
+	periodSec := defaultReferenceRatesPeriodSec
+	if s.cfg != nil && s.cfg.Market != nil && s.cfg.Market.ReferenceRates != nil && s.cfg.Market.ReferenceRates.PeriodSec > 0 {
+		periodSec = s.cfg.Market.ReferenceRates.PeriodSec
+	}
+	return time.Duration(periodSec) * time.Second
+}
+
+// refresh retrieves rates from the source
+// source rates are quoted as units of the asset per one unit of base, they are inverted to get price of the asset in base
+func (s *referenceRateProviderImpl) refresh(ctx context.Context) error {
+	base := s.BaseCurrency()
+	sourceRates, err := s.source.GetRates(ctx, base)
+	if err != nil {
+		return err
+	}
+	rates := make(map[string]float64, len(sourceRates)+1)
+	for asset, rate := range sourceRates {
+		if rate > 0.0 {
+			rates[asset] = 1 / rate
+		}
+	}
+	rates[base] = 1.0
+
+	s.Lock()
+	defer s.Unlock()
+	s.rates = rates
+	s.updatedAt = kit.Now()
+	s.l().C(ctx).Mth("refresh").DbgF("rates: %d", len(rates))
+	return nil
+}
+
+func (s *referenceRateProviderImpl) Run(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("run").Trc()
+
+	// check running
+	if s.running.Load() {
+		return errors.ErrReferenceRatesProviderAlreadyRun(ctx)
+	}
+
+	ctx, s.cancelFunc = context.WithCancel(ctx)
+	s.running.Store(true)
+
+	// source might be temporary unavailable, chains aren't normalized until rates are loaded
+	if err := s.refresh(ctx); err != nil {
+		l.E(err).Err("initial load")
+	}
+
+	goroutine.New().
+		WithLogger(s.l().C(ctx).Mth("refresh-worker")).
+		WithRetry(goroutine.Unrestricted).
+		WithRetryDelay(time.Second*10).
+		Go(ctx, func() {
+			ticker := time.NewTicker(s.period())
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := s.refresh(ctx); err != nil {
+						s.l().C(ctx).Mth("refresh-worker").E(err).Err()
+					}
+				case <-ctx.Done():
+					l.Inf("stop")
+					return
+				}
+			}
+		})
+
+	l.Inf("ok")
+	return nil
+}
+
+func (s *referenceRateProviderImpl) Stop(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("stop").Trc()
+	// cancel if running
+	if s.cancelFunc != nil && s.running.Load() {
+		s.cancelFunc()
+		s.running.Store(false)
+		s.cancelFunc = nil
+		l.Inf("ok")
+	}
+	return nil
+}
+
+func (s *referenceRateProviderImpl) GetRates(ctx context.Context) *domain.ReferenceRates {
+	s.RLock()
+	defer s.RUnlock()
+	r := &domain.ReferenceRates{
+		Base:      s.BaseCurrency(),
+		Rates:     make(map[string]float64, len(s.rates)),
+		UpdatedAt: s.updatedAt,
+	}
+	for asset, rate := range s.rates {
+		r.Rates[asset] = rate
+	}
+	return r
+}
+
+func (s *referenceRateProviderImpl) ToBase(ctx context.Context, asset string, amount float64) (float64, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	rate, ok := s.rates[strings.ToUpper(asset)]
+	if !ok {
+		return 0, false
+	}
+	return amount * rate, true
+}
@@ -0,0 +1,77 @@
+package market
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type referenceRatesTestSuite struct {
+	kitTestSuite.Suite
+	source   *mocks.ReferenceRateSource
+	provider domain.ReferenceRateProvider
+}
+
+func (s *referenceRatesTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestReferenceRatesSuite(t *testing.T) {
+	suite.Run(t, new(referenceRatesTestSuite))
+}
+
+func (s *referenceRatesTestSuite) SetupTest() {
+	s.source = &mocks.ReferenceRateSource{}
+	s.provider = NewReferenceRateProvider(s.source)
+	s.provider.Init(&service.Config{Market: &service.Market{ReferenceRates: &service.ReferenceRates{Base: "usd"}}})
+}
+
+func (s *referenceRatesTestSuite) Test_Refresh() {
+	s.source.On("GetRates", s.Ctx, "USD").Return(map[string]float64{"RUB": 80, "EUR": 0.8, "BAD": 0}, nil)
+	s.NoError(s.provider.(*referenceRateProviderImpl).refresh(s.Ctx))
+
+	rates := s.provider.GetRates(s.Ctx)
+	s.Equal("USD", rates.Base)
+	s.NotEmpty(rates.UpdatedAt)
+	s.Len(rates.Rates, 3)
+	s.InDelta(1.0, rates.Rates["USD"], 0.0001)
+	s.InDelta(0.0125, rates.Rates["RUB"], 0.0001)
+	s.InDelta(1.25, rates.Rates["EUR"], 0.0001)
+
+	v, ok := s.provider.ToBase(s.Ctx, "rub", 8000)
+	s.True(ok)
+	s.InDelta(100.0, v, 0.0001)
+	v, ok = s.provider.ToBase(s.Ctx, "USD", 10)
+	s.True(ok)
+	s.InDelta(10.0, v, 0.0001)
+	_, ok = s.provider.ToBase(s.Ctx, "BAD", 10)
+	s.False(ok)
+}
+
+func (s *referenceRatesTestSuite) Test_Refresh_WhenSourceFails_KeepRates() {
+	s.source.On("GetRates", s.Ctx, "USD").Return(map[string]float64{"RUB": 80}, nil).Once()
+	s.source.On("GetRates", s.Ctx, "USD").Return(nil, errors.ErrReferenceRatesSourceStatus(s.Ctx, 500)).Once()
+	s.NoError(s.provider.(*referenceRateProviderImpl).refresh(s.Ctx))
+	s.AssertAppErr(s.provider.(*referenceRateProviderImpl).refresh(s.Ctx), errors.ErrCodeReferenceRatesSourceStatus)
+
+	v, ok := s.provider.ToBase(s.Ctx, "RUB", 80)
+	s.True(ok)
+	s.InDelta(1.0, v, 0.0001)
+}
+
+func (s *referenceRatesTestSuite) Test_ToBase_WhenNotLoaded() {
+	_, ok := s.provider.ToBase(s.Ctx, "RUB", 80)
+	s.False(ok)
+	s.Empty(s.provider.GetRates(s.Ctx).Rates)
+}
+
+func (s *referenceRatesTestSuite) Test_WhenNotConfigured_Defaults() {
+	s.provider.Init(&service.Config{})
+	s.Equal(defaultBaseCurrency, s.provider.BaseCurrency())
+	s.Equal(defaultReferenceRatesPeriodSec*time.Second, s.provider.(*referenceRateProviderImpl).period())
+}
@@ -0,0 +1,94 @@
+package subscription
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sync"
+)
+
+const (
+	// feedSubscriberBufferSize size of subscriber's buffer. If subscriber is too slow and buffer is full, chains are dropped
+	feedSubscriberBufferSize = 256
+)
+
+type feedSubscriber struct {
+	filter *domain.SubscriptionChainFilter
+	ch     chan *domain.ProfitableChain
+}
+
+type chainFeedImpl struct {
+	sync.RWMutex
+	subscribers map[string]*feedSubscriber
+	cfg         *service.Config
+}
+
+func NewChainFeed() domain.ChainFeed {
+	return &chainFeedImpl{
+		subscribers: make(map[string]*feedSubscriber),
+	}
+}
+
+func (s *chainFeedImpl) l() log.CLogger {
+	return service.L().Cmp("chain-feed")
+}
+
+func (s *chainFeedImpl) Init(cfg *service.Config) {
+	s.cfg = cfg
+}
+
+func (s *chainFeedImpl) Subscribe(ctx context.Context, filter *domain.SubscriptionChainFilter) (<-chan *domain.ProfitableChain, func(), error) {
+	id := kit.NewRandString()
+	s.l().C(ctx).Mth("subscribe").F(log.FF{"subscriberId": id}).Trc()
+
+	if filter != nil {
+		if err := validateFilterExpression(ctx, filter, filterBaseCurrency(s.cfg)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	sub := &feedSubscriber{
+		filter: filter,
+		ch:     make(chan *domain.ProfitableChain, feedSubscriberBufferSize),
+	}
+
+	s.Lock()
+	s.subscribers[id] = sub
+	s.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			s.Lock()
+			delete(s.subscribers, id)
+			close(sub.ch)
+			s.Unlock()
+			s.l().C(ctx).Mth("unsubscribe").F(log.FF{"subscriberId": id}).Trc()
+		})
+	}
+
+	return sub.ch, unsubscribe, nil
+}
+
+func (s *chainFeedImpl) Notify(ctx context.Context, chains []*domain.ProfitableChain) error {
+	l := s.l().C(ctx).Mth("notify")
+
+	s.RLock()
+	defer s.RUnlock()
+
+	for id, sub := range s.subscribers {
+		for _, chain := range chains {
+			if !matchChain(sub.filter, chain) {
+				continue
+			}
+			select {
+			case sub.ch <- chain:
+			default:
+				l.F(log.FF{"subscriberId": id, "chainId": chain.Id}).Warn("subscriber is too slow, chain dropped")
+			}
+		}
+	}
+	return nil
+}
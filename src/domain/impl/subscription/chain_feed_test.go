@@ -0,0 +1,86 @@
+package subscription
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type chainFeedTestSuite struct {
+	kitTestSuite.Suite
+	feed domain.ChainFeed
+}
+
+func (s *chainFeedTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestChainFeedSuite(t *testing.T) {
+	suite.Run(t, new(chainFeedTestSuite))
+}
+
+func (s *chainFeedTestSuite) SetupTest() {
+	s.feed = NewChainFeed()
+}
+
+func (s *chainFeedTestSuite) Test_Notify_FilteredBySubscriber() {
+	usdCh, usdUnsubscribe, err := s.feed.Subscribe(s.Ctx, &domain.SubscriptionChainFilter{Assets: []string{"USD"}})
+	s.NoError(err)
+	defer usdUnsubscribe()
+	allCh, allUnsubscribe, err := s.feed.Subscribe(s.Ctx, nil)
+	s.NoError(err)
+	defer allUnsubscribe()
+
+	chains := []*domain.ProfitableChain{
+		{Id: "1", Asset: "USD", Depth: 3, ProfitShare: 1.01},
+		{Id: "2", Asset: "RUB", Depth: 3, ProfitShare: 1.01},
+	}
+	s.NoError(s.feed.Notify(s.Ctx, chains))
+
+	s.Len(usdCh, 1)
+	s.Equal("1", (<-usdCh).Id)
+	s.Len(allCh, 2)
+}
+
+func (s *chainFeedTestSuite) Test_Unsubscribe_ChannelClosed() {
+	ch, unsubscribe, err := s.feed.Subscribe(s.Ctx, nil)
+	s.NoError(err)
+	unsubscribe()
+	// second call is safe
+	unsubscribe()
+	_, ok := <-ch
+	s.False(ok)
+	s.NoError(s.feed.Notify(s.Ctx, []*domain.ProfitableChain{{Id: "1", Asset: "USD"}}))
+}
+
+func (s *chainFeedTestSuite) Test_SlowSubscriber_ChainsDropped() {
+	ch, unsubscribe, err := s.feed.Subscribe(s.Ctx, nil)
+	s.NoError(err)
+	defer unsubscribe()
+	for i := 0; i < feedSubscriberBufferSize+10; i++ {
+		s.NoError(s.feed.Notify(s.Ctx, []*domain.ProfitableChain{{Id: "1", Asset: "USD"}}))
+	}
+	s.Len(ch, feedSubscriberBufferSize)
+}
+
+func (s *chainFeedTestSuite) Test_Notify_FilteredByExpression() {
+	ch, unsubscribe, err := s.feed.Subscribe(s.Ctx, &domain.SubscriptionChainFilter{Expression: "profit > 1.5 && depth <= 3"})
+	s.NoError(err)
+	defer unsubscribe()
+
+	s.NoError(s.feed.Notify(s.Ctx, []*domain.ProfitableChain{
+		{Id: "1", Asset: "USD", Depth: 3, ProfitShare: 1.02},
+		{Id: "2", Asset: "USD", Depth: 3, ProfitShare: 1.01},
+		{Id: "3", Asset: "USD", Depth: 4, ProfitShare: 1.02},
+	}))
+	s.Len(ch, 1)
+	s.Equal("1", (<-ch).Id)
+}
+
+func (s *chainFeedTestSuite) Test_Subscribe_WhenExpressionInvalid_Err() {
+	_, _, err := s.feed.Subscribe(s.Ctx, &domain.SubscriptionChainFilter{Expression: "profit > "})
+	s.AssertAppErr(err, errors.ErrCodeSubscriptionFilterExpressionInvalid)
+}
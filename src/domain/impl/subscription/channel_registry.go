@@ -0,0 +1,46 @@
+package subscription
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"sort"
+	"sync"
+)
+
+type channelRegistryImpl struct {
+	sync.RWMutex
+	channels map[string]domain.NotificationChannel
+}
+
+func NewNotificationChannelRegistry(channels ...domain.NotificationChannel) domain.NotificationChannelRegistry {
+	r := &channelRegistryImpl{
+		channels: make(map[string]domain.NotificationChannel, len(channels)),
+	}
+	for _, ch := range channels {
+		r.Register(ch)
+	}
+	return r
+}
+
+func (r *channelRegistryImpl) Register(channel domain.NotificationChannel) {
+	r.Lock()
+	defer r.Unlock()
+	r.channels[channel.Type()] = channel
+}
+
+func (r *channelRegistryImpl) Get(channelType string) (domain.NotificationChannel, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	ch, ok := r.channels[channelType]
+	return ch, ok
+}
+
+func (r *channelRegistryImpl) Types() []string {
+	r.RLock()
+	defer r.RUnlock()
+	var types []string
+	for t := range r.channels {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
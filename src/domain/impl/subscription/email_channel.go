@@ -0,0 +1,84 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/email"
+	"net/mail"
+	"strings"
+)
+
+const (
+	maxEmailRecipients = 10
+)
+
+type EmailOptions struct {
+	From string // From sender address
+}
+
+// emailChannel delivers notifications by email, each notification gets its own message, so recipients of different subscriptions don't see each other
+type emailChannel struct {
+	email    email.Email
+	renderer domain.NotificationRenderer
+	opt      *EmailOptions
+}
+
+// normalizeEmail parses the address and converts it to lowercase
+func normalizeEmail(rcpt string) (string, bool) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(rcpt))
+	if err != nil {
+		return "", false
+	}
+	return strings.ToLower(addr.Address), true
+}
+
+func NewEmailChannel(client email.Email, renderer domain.NotificationRenderer, opt *EmailOptions) domain.NotificationChannel {
+	return &emailChannel{
+		email:    client,
+		renderer: renderer,
+		opt:      opt,
+	}
+}
+
+func (e *emailChannel) Type() string {
+	return domain.SubscriptionNotificationChannelEmail
+}
+
+func (e *emailChannel) Validate(ctx context.Context, notification *domain.SubscriptionNotification) error {
+	if notification.Email == nil || len(notification.Email.To) == 0 {
+		return errors.ErrSubscriptionNotificationEmailInvalid(ctx, "recipients empty")
+	}
+	var to kit.Strings
+	for _, rcpt := range notification.Email.To {
+		addr, ok := normalizeEmail(rcpt)
+		if !ok {
+			return errors.ErrSubscriptionNotificationEmailInvalid(ctx, fmt.Sprintf("invalid address %s", rcpt))
+		}
+		to = append(to, addr)
+	}
+	to = to.Distinct()
+	if len(to) > maxEmailRecipients {
+		return errors.ErrSubscriptionNotificationEmailInvalid(ctx, fmt.Sprintf("max %d recipients allowed", maxEmailRecipients))
+	}
+	notification.Email.To = to
+	return nil
+}
+
+func (e *emailChannel) Send(ctx context.Context, delivery *domain.OutboxDelivery) error {
+	if delivery.Notification == nil || delivery.Notification.Email == nil || len(delivery.Notification.Email.To) == 0 {
+		return errors.ErrOutboxDeliveryInvalid(ctx, delivery.Id)
+	}
+	msg, err := e.renderer.Render(ctx, delivery)
+	if err != nil {
+		return err
+	}
+	return e.email.Send(ctx, &email.Message{
+		From:    e.opt.From,
+		To:      delivery.Notification.Email.To,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+	})
+}
@@ -0,0 +1,102 @@
+package subscription
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/email"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type emailChannelTestSuite struct {
+	kitTestSuite.Suite
+	smtp *email.TestSmtpServer
+	svc  domain.NotificationChannel
+}
+
+func (s *emailChannelTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestEmailChannelSuite(t *testing.T) {
+	suite.Run(t, new(emailChannelTestSuite))
+}
+
+func (s *emailChannelTestSuite) SetupTest() {
+	var err error
+	s.smtp, err = email.NewTestSmtpServer()
+	s.NoError(err)
+	s.svc = NewEmailChannel(email.NewEmail(s.L, s.smtp.Config()), NewNotificationRenderer(), &EmailOptions{From: "noreply@cryptocare.ai"})
+}
+
+func (s *emailChannelTestSuite) TearDownTest() {
+	s.smtp.Close()
+}
+
+func (s *emailChannelTestSuite) Test_Send_Chain() {
+	chain := &domain.ProfitableChain{
+		Id:          "chain-id",
+		Asset:       "USDT",
+		ProfitShare: 1.025,
+		Bids: []*domain.Bid{
+			{SrcAsset: "USDT", TrgAsset: "RUB", ExchangeCode: "binance", Rate: 62, Methods: []string{"M1"}},
+			{SrcAsset: "RUB", TrgAsset: "USDT", ExchangeCode: "huobi", Rate: 1.0 / 60},
+		},
+	}
+	s.NoError(s.svc.Send(s.Ctx, &domain.OutboxDelivery{
+		Id: "delivery-id",
+		Notification: &domain.SubscriptionNotification{
+			Id:      "1",
+			Channel: domain.SubscriptionNotificationChannelEmail,
+			Email:   &domain.SubscriptionEmailNotificationDetails{To: []string{"b@example.com", "c@example.com"}},
+		},
+		Chain: chain,
+	}))
+
+	messages := s.smtp.Messages()
+	s.Len(messages, 1)
+	s.Equal("noreply@cryptocare.ai", messages[0].From)
+	s.Equal([]string{"b@example.com", "c@example.com"}, messages[0].To)
+	s.Contains(messages[0].Data, "Subject: USDT chain, profit 2.50%")
+	s.Contains(messages[0].Data, "USDT:RUB (binance, 62.00000, M1)")
+	s.Contains(messages[0].Data, "/trading/details/chain-id")
+}
+
+func (s *emailChannelTestSuite) Test_Send_WhenNoRecipients_Fail() {
+	err := s.svc.Send(s.Ctx, &domain.OutboxDelivery{
+		Id:           "delivery-id",
+		Notification: &domain.SubscriptionNotification{Channel: domain.SubscriptionNotificationChannelEmail},
+		Chain:        &domain.ProfitableChain{Id: "chain-id"},
+	})
+	s.AssertAppErr(err, errors.ErrCodeOutboxDeliveryInvalid)
+	s.Empty(s.smtp.Messages())
+}
+
+func (s *emailChannelTestSuite) Test_Send_Digest() {
+	from := time.Date(2022, 10, 24, 12, 0, 0, 0, time.UTC)
+	s.NoError(s.svc.Send(s.Ctx, &domain.OutboxDelivery{
+		Id: "delivery-id",
+		Notification: &domain.SubscriptionNotification{
+			Id:      "1",
+			Channel: domain.SubscriptionNotificationChannelEmail,
+			Email:   &domain.SubscriptionEmailNotificationDetails{To: []string{"a@example.com"}},
+		},
+		OpportunityType: domain.DeliveryTypeDigest,
+		Digest: &domain.NotificationDigest{
+			Chains:  []*domain.ProfitableChain{{Id: "chain-id", Asset: "USDT", ProfitShare: 1.025, ExchangeCodes: []string{"binance"}}},
+			Spreads: []*domain.Spread{{BaseAsset: "USDT", QuoteAsset: "RUB", SpreadShare: 1.01, ExchangeCodes: []string{"bybit"}}},
+			Matched: 7,
+			From:    from,
+			To:      from.Add(time.Hour),
+		},
+	}))
+
+	messages := s.smtp.Messages()
+	s.Len(messages, 1)
+	s.Contains(messages[0].Data, "Subject: Digest: 7 opportunities")
+	s.Contains(messages[0].Data, "1. USDT, profit 2.50%, binance, https://panel.cryptocare.ai/trading/details/chain-id")
+	s.Contains(messages[0].Data, "1. USDT:RUB, spread 1.00%, bybit")
+}
@@ -0,0 +1,237 @@
+package subscription
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/email"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
+)
+
+const (
+	defaultEmailCodeTtl     = 24 * time.Hour
+	emailCodeResendInterval = time.Minute
+)
+
+type emailVerifierImpl struct {
+	email         email.Email
+	storage       domain.EmailVerificationStorage
+	subscriptions domain.SubscriptionStorage
+	from          string
+	codeTtl       time.Duration
+}
+
+// NewEmailVerifier creates a verifier, client is nil if smtp server isn't configured
+func NewEmailVerifier(client email.Email, storage domain.EmailVerificationStorage, subscriptions domain.SubscriptionStorage) domain.EmailVerifier {
+	return &emailVerifierImpl{
+		email:         client,
+		storage:       storage,
+		subscriptions: subscriptions,
+		codeTtl:       defaultEmailCodeTtl,
+	}
+}
+
+func (e *emailVerifierImpl) l() log.CLogger {
+	return service.L().Cmp("email-verifier")
+}
+
+func (e *emailVerifierImpl) Init(cfg *service.Config) {
+	if cfg.Arbitrage == nil || cfg.Arbitrage.Notification == nil || cfg.Arbitrage.Notification.Email == nil {
+		return
+	}
+	emailCfg := cfg.Arbitrage.Notification.Email
+	e.from = emailCfg.From
+	if emailCfg.CodeTtlSec > 0 {
+		e.codeTtl = time.Duration(emailCfg.CodeTtlSec) * time.Second
+	}
+}
+
+// validate validates params and returns normalized address
+func (e *emailVerifierImpl) validate(ctx context.Context, userId, address string) (string, error) {
+	if userId == "" {
+		return "", errors.ErrEmailVerificationUserIdEmpty(ctx)
+	}
+	addr, ok := normalizeEmail(address)
+	if !ok {
+		return "", errors.ErrEmailVerificationAddressInvalid(ctx, address)
+	}
+	return addr, nil
+}
+
+func (e *emailVerifierImpl) Request(ctx context.Context, userId, address string) (*domain.EmailVerification, error) {
+	l := e.l().C(ctx).Mth("request").F(log.FF{"userId": userId}).Trc()
+
+	addr, err := e.validate(ctx, userId, address)
+	if err != nil {
+		return nil, err
+	}
+	if e.email == nil {
+		return nil, errors.ErrEmailVerificationNotConfigured(ctx)
+	}
+
+	stored, err := e.storage.GetEmailVerification(ctx, userId, addr)
+	if err != nil {
+		return nil, err
+	}
+	// already verified, nothing to confirm
+	if stored != nil && stored.Status == domain.EmailVerificationVerified {
+		return stored, nil
+	}
+	now := kit.Now()
+	// codes are sent to addresses given by users, don't let them flood the address
+	if stored != nil && stored.CreatedAt.Add(emailCodeResendInterval).After(now) {
+		return nil, errors.ErrEmailVerificationTooFrequent(ctx)
+	}
+
+	v := &domain.EmailVerification{
+		UserId:    userId,
+		Email:     addr,
+		Code:      kit.NewRandString(),
+		Status:    domain.EmailVerificationPending,
+		ExpiresAt: now.Add(e.codeTtl),
+		CreatedAt: now,
+	}
+	if err := e.storage.SaveEmailVerification(ctx, v); err != nil {
+		return nil, err
+	}
+	if err := e.email.Send(ctx, &email.Message{
+		From:    e.from,
+		To:      []string{addr},
+		Subject: "Confirm cryptocare notifications",
+		Body: fmt.Sprintf("Arbitrage notifications have been requested to this address.\n\n"+
+			"Confirmation code: %s\n\nThe code is valid until %s. If you didn't request notifications, ignore this message.",
+			v.Code, v.ExpiresAt.UTC().Format(time.RFC1123)),
+	}); err != nil {
+		return nil, err
+	}
+
+	l.Dbg("sent")
+	return v, nil
+}
+
+func (e *emailVerifierImpl) Confirm(ctx context.Context, userId, address, code string) (*domain.EmailVerification, error) {
+	e.l().C(ctx).Mth("confirm").F(log.FF{"userId": userId}).Trc()
+
+	addr, err := e.validate(ctx, userId, address)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := e.storage.GetEmailVerification(ctx, userId, addr)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil && stored.Status == domain.EmailVerificationVerified {
+		return stored, nil
+	}
+	if stored == nil || stored.Code == "" {
+		return nil, errors.ErrEmailVerificationNotFound(ctx)
+	}
+	if stored.ExpiresAt.Before(kit.Now()) {
+		return nil, errors.ErrEmailVerificationExpired(ctx)
+	}
+	if subtle.ConstantTimeCompare([]byte(stored.Code), []byte(code)) != 1 {
+		return nil, errors.ErrEmailVerificationCodeInvalid(ctx)
+	}
+	return e.verify(ctx, stored)
+}
+
+func (e *emailVerifierImpl) GetVerifications(ctx context.Context, userId string) ([]*domain.EmailVerification, error) {
+	e.l().C(ctx).Mth("get").F(log.FF{"userId": userId}).Trc()
+	if userId == "" {
+		return nil, errors.ErrEmailVerificationUserIdEmpty(ctx)
+	}
+	return e.storage.GetEmailVerifications(ctx, userId)
+}
+
+// verifiedAddresses retrieves addresses verified by the user
+func (e *emailVerifierImpl) verifiedAddresses(ctx context.Context, userId string) (map[string]struct{}, error) {
+	vv, err := e.storage.GetEmailVerifications(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	r := make(map[string]struct{}, len(vv))
+	for _, v := range vv {
+		if v.Status == domain.EmailVerificationVerified {
+			r[v.Email] = struct{}{}
+		}
+	}
+	return r, nil
+}
+
+// allVerified checks all the addresses are in the verified set
+func allVerified(verified map[string]struct{}, emails []string) bool {
+	if len(emails) == 0 {
+		return false
+	}
+	for _, addr := range emails {
+		if _, ok := verified[addr]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *emailVerifierImpl) IsVerified(ctx context.Context, userId string, emails []string) (bool, error) {
+	e.l().C(ctx).Mth("is-verified").F(log.FF{"userId": userId}).Trc()
+
+	if userId == "" || len(emails) == 0 {
+		return false, nil
+	}
+	verified, err := e.verifiedAddresses(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+	return allVerified(verified, emails), nil
+}
+
+// verify marks the address verified and activates email notifications of the user waiting for verification, whose recipients are all verified now
+// notifications deactivated by the user stay inactive
+func (e *emailVerifierImpl) verify(ctx context.Context, v *domain.EmailVerification) (*domain.EmailVerification, error) {
+	l := e.l().C(ctx).Mth("verify").F(log.FF{"userId": v.UserId})
+
+	now := kit.Now()
+	v.Status = domain.EmailVerificationVerified
+	v.VerifiedAt = &now
+	// code can't be reused
+	v.Code = ""
+	if err := e.storage.SaveEmailVerification(ctx, v); err != nil {
+		return nil, err
+	}
+
+	verified, err := e.verifiedAddresses(ctx, v.UserId)
+	if err != nil {
+		return nil, err
+	}
+	subs, err := e.subscriptions.SearchSubscriptions(ctx, &domain.SearchSubscriptionsRequest{UserId: v.UserId, WithInActive: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		changed := false
+		for _, n := range sub.Notifications {
+			if n.Channel == domain.SubscriptionNotificationChannelEmail && n.Email != nil && !n.Email.Verified &&
+				allVerified(verified, n.Email.To) {
+				n.Email.Verified = true
+				if n.Email.AwaitingVerification {
+					n.IsActive = true
+					n.Email.AwaitingVerification = false
+				}
+				changed = true
+			}
+		}
+		if changed {
+			if err := e.subscriptions.SaveSubscription(ctx, sub); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	l.Inf("ok")
+	return v, nil
+}
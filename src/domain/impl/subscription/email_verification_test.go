@@ -0,0 +1,162 @@
+package subscription
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/email"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testEmail = "a@example.com"
+
+type emailVerifierTestSuite struct {
+	kitTestSuite.Suite
+	email         *mocks.Email
+	storage       *mocks.EmailVerificationStorage
+	subscriptions *mocks.SubscriptionStorage
+	verifier      domain.EmailVerifier
+	userId        string
+}
+
+func (s *emailVerifierTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestEmailVerifierSuite(t *testing.T) {
+	suite.Run(t, new(emailVerifierTestSuite))
+}
+
+func (s *emailVerifierTestSuite) SetupTest() {
+	s.email = &mocks.Email{}
+	s.storage = &mocks.EmailVerificationStorage{}
+	s.subscriptions = &mocks.SubscriptionStorage{}
+	s.userId = kit.NewId()
+	s.verifier = NewEmailVerifier(s.email, s.storage, s.subscriptions)
+	s.verifier.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{
+		Email: &service.ArbitrageNotificationEmail{From: "noreply@cryptocare.ai"},
+	}}})
+}
+
+func (s *emailVerifierTestSuite) pending(createdAt, expiresAt time.Time) *domain.EmailVerification {
+	return &domain.EmailVerification{
+		UserId:    s.userId,
+		Email:     testEmail,
+		Code:      "code",
+		Status:    domain.EmailVerificationPending,
+		ExpiresAt: expiresAt,
+		CreatedAt: createdAt,
+	}
+}
+
+func (s *emailVerifierTestSuite) Test_Request() {
+	s.storage.On("GetEmailVerification", s.Ctx, s.userId, testEmail).Return(nil, nil)
+	s.storage.On("SaveEmailVerification", s.Ctx, mock.AnythingOfType("*domain.EmailVerification")).Return(nil)
+	var sent *email.Message
+	s.email.On("Send", s.Ctx, mock.AnythingOfType("*email.Message")).
+		Run(func(args mock.Arguments) {
+			sent = args.Get(1).(*email.Message)
+		}).Return(nil)
+
+	v, err := s.verifier.Request(s.Ctx, s.userId, " A@Example.com ")
+	s.Nil(err)
+	s.Equal(testEmail, v.Email)
+	s.Equal(domain.EmailVerificationPending, v.Status)
+	s.NotEmpty(v.Code)
+	// the code goes to the address
+	s.NotNil(sent)
+	s.Equal([]string{testEmail}, sent.To)
+	s.Equal("noreply@cryptocare.ai", sent.From)
+	s.True(strings.Contains(sent.Body, v.Code))
+}
+
+func (s *emailVerifierTestSuite) Test_Request_WhenInvalid_Fail() {
+	_, err := s.verifier.Request(s.Ctx, "", testEmail)
+	s.AssertAppErr(err, errors.ErrCodeEmailVerificationUserIdEmpty)
+	_, err = s.verifier.Request(s.Ctx, s.userId, "not an address")
+	s.AssertAppErr(err, errors.ErrCodeEmailVerificationAddressInvalid)
+	// smtp isn't configured
+	_, err = NewEmailVerifier(nil, s.storage, s.subscriptions).Request(s.Ctx, s.userId, testEmail)
+	s.AssertAppErr(err, errors.ErrCodeEmailVerificationNotConfigured)
+}
+
+func (s *emailVerifierTestSuite) Test_Request_WhenTooFrequent_Fail() {
+	s.storage.On("GetEmailVerification", s.Ctx, s.userId, testEmail).Return(s.pending(kit.Now(), kit.Now().Add(time.Hour)), nil)
+	_, err := s.verifier.Request(s.Ctx, s.userId, testEmail)
+	s.AssertAppErr(err, errors.ErrCodeEmailVerificationTooFrequent)
+	s.email.AssertNotCalled(s.T(), "Send", mock.Anything, mock.Anything)
+}
+
+func (s *emailVerifierTestSuite) Test_Confirm() {
+	s.storage.On("GetEmailVerification", s.Ctx, s.userId, testEmail).Return(s.pending(kit.Now(), kit.Now().Add(time.Hour)), nil)
+	s.storage.On("SaveEmailVerification", s.Ctx, mock.AnythingOfType("*domain.EmailVerification")).Return(nil)
+	s.storage.On("GetEmailVerifications", s.Ctx, s.userId).Return([]*domain.EmailVerification{
+		{UserId: s.userId, Email: testEmail, Status: domain.EmailVerificationVerified},
+		{UserId: s.userId, Email: "b@example.com", Status: domain.EmailVerificationVerified},
+		{UserId: s.userId, Email: "c@example.com", Status: domain.EmailVerificationPending},
+	}, nil)
+	subs := &domain.Subscription{Id: kit.NewId(), UserId: s.userId, Notifications: []*domain.SubscriptionNotification{
+		{Channel: domain.SubscriptionNotificationChannelEmail, Email: &domain.SubscriptionEmailNotificationDetails{To: []string{testEmail, "b@example.com"}, AwaitingVerification: true}},
+		{Channel: domain.SubscriptionNotificationChannelEmail, Email: &domain.SubscriptionEmailNotificationDetails{To: []string{testEmail, "c@example.com"}, AwaitingVerification: true}},
+		{Channel: domain.SubscriptionNotificationChannelEmail, Email: &domain.SubscriptionEmailNotificationDetails{To: []string{testEmail}}},
+	}}
+	s.subscriptions.On("SearchSubscriptions", s.Ctx, &domain.SearchSubscriptionsRequest{UserId: s.userId, WithInActive: true}).
+		Return([]*domain.Subscription{subs}, nil)
+	s.subscriptions.On("SaveSubscription", s.Ctx, subs).Return(nil)
+
+	v, err := s.verifier.Confirm(s.Ctx, s.userId, testEmail, "code")
+	s.Nil(err)
+	s.Equal(domain.EmailVerificationVerified, v.Status)
+	s.NotNil(v.VerifiedAt)
+	s.Empty(v.Code)
+
+	// all recipients are verified, awaiting notification is activated
+	s.True(subs.Notifications[0].IsActive)
+	s.True(subs.Notifications[0].Email.Verified)
+	s.False(subs.Notifications[0].Email.AwaitingVerification)
+	// one of the recipients isn't verified
+	s.False(subs.Notifications[1].IsActive)
+	s.False(subs.Notifications[1].Email.Verified)
+	// deactivated by the user, stays inactive
+	s.False(subs.Notifications[2].IsActive)
+	s.True(subs.Notifications[2].Email.Verified)
+}
+
+func (s *emailVerifierTestSuite) Test_Confirm_Fail() {
+	s.storage.On("GetEmailVerification", s.Ctx, s.userId, testEmail).Return(s.pending(kit.Now(), kit.Now().Add(time.Hour)), nil).Once()
+	_, err := s.verifier.Confirm(s.Ctx, s.userId, testEmail, "another")
+	s.AssertAppErr(err, errors.ErrCodeEmailVerificationCodeInvalid)
+
+	s.storage.On("GetEmailVerification", s.Ctx, s.userId, testEmail).Return(s.pending(kit.Now().Add(-time.Hour), kit.Now().Add(-time.Minute)), nil).Once()
+	_, err = s.verifier.Confirm(s.Ctx, s.userId, testEmail, "code")
+	s.AssertAppErr(err, errors.ErrCodeEmailVerificationExpired)
+
+	s.storage.On("GetEmailVerification", s.Ctx, s.userId, testEmail).Return(nil, nil).Once()
+	_, err = s.verifier.Confirm(s.Ctx, s.userId, testEmail, "code")
+	s.AssertAppErr(err, errors.ErrCodeEmailVerificationNotFound)
+
+	s.storage.AssertNotCalled(s.T(), "SaveEmailVerification", mock.Anything, mock.Anything)
+}
+
+func (s *emailVerifierTestSuite) Test_IsVerified() {
+	s.storage.On("GetEmailVerifications", s.Ctx, s.userId).Return([]*domain.EmailVerification{
+		{UserId: s.userId, Email: testEmail, Status: domain.EmailVerificationVerified},
+		{UserId: s.userId, Email: "b@example.com", Status: domain.EmailVerificationPending},
+	}, nil)
+	verified, err := s.verifier.IsVerified(s.Ctx, s.userId, []string{testEmail})
+	s.Nil(err)
+	s.True(verified)
+	verified, err = s.verifier.IsVerified(s.Ctx, s.userId, []string{testEmail, "b@example.com"})
+	s.Nil(err)
+	s.False(verified)
+	verified, err = s.verifier.IsVerified(s.Ctx, s.userId, nil)
+	s.Nil(err)
+	s.False(verified)
+}
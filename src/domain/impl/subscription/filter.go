@@ -0,0 +1,174 @@
+package subscription
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	memcache "github.com/mikhailbolshakov/cryptocare/src/kit/cache"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/expr"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"strings"
+	"time"
+)
+
+const (
+	maxFilterExpressionLength = 1024
+	maxFilterPrograms         = 4096 // maxFilterPrograms max number of compiled expressions kept in cache
+	usdCurrency               = "USD"
+)
+
+// filterVars variables of the opportunity model filter expressions are evaluated against
+// the model is documented on domain.SubscriptionChainFilter.Expression
+var filterVars = expr.Vars{
+	"opportunity":  expr.String,
+	"profit":       expr.Number,
+	"asset":        expr.String,
+	"assets":       expr.StringList,
+	"exchanges":    expr.StringList,
+	"methods":      expr.StringList,
+	"bidTypes":     expr.StringList,
+	"depth":        expr.Number,
+	"score":        expr.Number,
+	"volume":       expr.Number,
+	"baseCurrency": expr.String,
+	"baseVolume":   expr.Number,
+	"baseProfit":   expr.Number,
+	"volumeUsd":    expr.Number,
+	"profitUsd":    expr.Number,
+	"ageSec":       expr.Number,
+}
+
+// usdFilterVars variables available if chains are normalized to USD
+var usdFilterVars = []string{"volumeUsd", "profitUsd"}
+
+// filterVarsOf returns variables available with the base currency
+// amounts in USD aren't available if the base currency isn't USD, so expressions using them are rejected rather than never matching
+func filterVarsOf(baseCurrency string) expr.Vars {
+	if baseCurrency == usdCurrency {
+		return filterVars
+	}
+	r := make(expr.Vars, len(filterVars))
+	for k, v := range filterVars {
+		r[k] = v
+	}
+	for _, v := range usdFilterVars {
+		delete(r, v)
+	}
+	return r
+}
+
+// filterBaseCurrency returns currency chains are normalized to, USD by default as the reference rates provider does
+func filterBaseCurrency(cfg *service.Config) string {
+	if cfg == nil || cfg.Market == nil || cfg.Market.ReferenceRates == nil || cfg.Market.ReferenceRates.Base == "" {
+		return usdCurrency
+	}
+	return strings.ToUpper(cfg.Market.ReferenceRates.Base)
+}
+
+// filterPrograms compiled filter expressions by source
+var filterPrograms = memcache.NewLRU(maxFilterPrograms)
+
+// compileFilterExpression compiles the expression, programs are cached by source
+func compileFilterExpression(expression string) (*expr.Program, error) {
+	if p, ok := filterPrograms.Get(expression); ok {
+		return p.(*expr.Program), nil
+	}
+	p, err := expr.Compile(expression, filterVars)
+	if err != nil {
+		return nil, err
+	}
+	filterPrograms.Set(expression, p)
+	return p, nil
+}
+
+// validateFilterExpression trims and compiles the expression against variables available with the base currency, so that invalid expressions are never saved
+func validateFilterExpression(ctx context.Context, filter *domain.SubscriptionChainFilter, baseCurrency string) error {
+	filter.Expression = strings.TrimSpace(filter.Expression)
+	if filter.Expression == "" {
+		return nil
+	}
+	if len(filter.Expression) > maxFilterExpressionLength {
+		return errors.ErrSubscriptionFilterExpressionInvalid(ctx, "expression is too long", maxFilterExpressionLength+1)
+	}
+	if _, err := expr.Compile(filter.Expression, filterVarsOf(baseCurrency)); err != nil {
+		if e, ok := err.(*expr.Error); ok {
+			return errors.ErrSubscriptionFilterExpressionInvalid(ctx, e.Msg, e.Pos)
+		}
+		return errors.ErrSubscriptionFilterExpressionInvalid(ctx, err.Error(), 0)
+	}
+	return nil
+}
+
+// matchExpression evaluates the filter expression, an empty expression matches everything
+// expressions are validated on save, so the ones which fail anyway (e.g. refer to amounts the opportunity doesn't have) don't match
+func matchExpression(filter *domain.SubscriptionChainFilter, values func() expr.Values) bool {
+	if filter.Expression == "" {
+		return true
+	}
+	p, err := compileFilterExpression(filter.Expression)
+	if err != nil {
+		return false
+	}
+	res, err := p.Eval(values())
+	return err == nil && res
+}
+
+func ageSec(observedAt time.Time) float64 {
+	if observedAt.IsZero() {
+		return 0
+	}
+	return kit.Now().Sub(observedAt).Seconds()
+}
+
+// chainValues builds the opportunity model of the chain
+// amounts in USD are set only if the chain is normalized to USD
+func chainValues(chain *domain.ProfitableChain) expr.Values {
+	r := expr.Values{
+		"opportunity":  domain.OpportunityTypeChain,
+		"profit":       (chain.ProfitShare - 1) * 100,
+		"asset":        chain.Asset,
+		"assets":       chain.BidAssets,
+		"exchanges":    chain.ExchangeCodes,
+		"methods":      chain.Methods,
+		"bidTypes":     chain.BidTypes,
+		"depth":        float64(chain.Depth),
+		"score":        chain.Score,
+		"volume":       chain.Volume,
+		"baseCurrency": chain.BaseCurrency,
+		"baseVolume":   chain.BaseVolume,
+		"baseProfit":   chain.BaseProfit,
+		"ageSec":       ageSec(chain.ObservedAt),
+	}
+	if chain.BaseCurrency == usdCurrency {
+		r["volumeUsd"] = chain.BaseVolume
+		r["profitUsd"] = chain.BaseProfit
+	}
+	return r
+}
+
+// spreadValues builds the opportunity model of the spread, spread has no base currency amounts, so expressions with amounts in USD don't match spreads
+func spreadValues(spread *domain.Spread) expr.Values {
+	var bidTypes []string
+	for _, b := range []*domain.Bid{spread.Buy, spread.Sell} {
+		if b != nil && b.Type != "" {
+			bidTypes = append(bidTypes, b.Type)
+		}
+	}
+	return expr.Values{
+		"opportunity":  domain.OpportunityTypeSpread,
+		"profit":       (spread.SpreadShare - 1) * 100,
+		"asset":        spread.BaseAsset,
+		"assets":       []string{spread.BaseAsset, spread.QuoteAsset},
+		"exchanges":    spread.ExchangeCodes,
+		"methods":      spread.Methods,
+		"bidTypes":     []string(kit.Strings(bidTypes).Distinct()),
+		"depth":        2.0,
+		"score":        (spread.SpreadShare - 1) * 50,
+		"volume":       spread.Volume,
+		"baseCurrency": "",
+		"baseVolume":   0.0,
+		"baseProfit":   0.0,
+		"ageSec":       ageSec(spread.ObservedAt),
+	}
+}
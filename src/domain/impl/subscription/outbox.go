@@ -0,0 +1,339 @@
+package subscription
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/goroutine"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"go.uber.org/atomic"
+	"time"
+)
+
+const (
+	defaultOutboxWorkers        = 4
+	defaultOutboxBatchSize      = 50
+	defaultOutboxPeriodMs       = 1000
+	defaultOutboxMaxAttempts    = 8
+	defaultOutboxBackoffSec     = 5
+	defaultOutboxMaxBackoffSec  = 3600
+	defaultOutboxLeaseSec       = 60
+	defaultOutboxRetentionHours = 72
+	// defaultOutboxDeadRetentionHours dead deliveries are kept longer, so there is time to look into them and replay
+	defaultOutboxDeadRetentionHours = 720
+	defaultOutboxSearchSize         = 100
+	maxOutboxSearchSize             = 1000
+	outboxCleanupPeriod             = time.Hour
+)
+
+// outboxOptions outbox config with defaults applied
+type outboxOptions struct {
+	workers     int
+	batchSize   int
+	period      time.Duration
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	lease       time.Duration
+	retention   time.Duration
+	// deadRetention how long dead deliveries are kept
+	deadRetention time.Duration
+}
+
+func orDefault(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+func newOutboxOptions(cfg *service.NotificationOutbox) *outboxOptions {
+	if cfg == nil {
+		cfg = &service.NotificationOutbox{}
+	}
+	return &outboxOptions{
+		workers:       orDefault(cfg.Workers, defaultOutboxWorkers),
+		batchSize:     orDefault(cfg.BatchSize, defaultOutboxBatchSize),
+		period:        time.Duration(orDefault(cfg.PeriodMs, defaultOutboxPeriodMs)) * time.Millisecond,
+		maxAttempts:   orDefault(cfg.MaxAttempts, defaultOutboxMaxAttempts),
+		backoff:       time.Duration(orDefault(cfg.BackoffSec, defaultOutboxBackoffSec)) * time.Second,
+		maxBackoff:    time.Duration(orDefault(cfg.MaxBackoffSec, defaultOutboxMaxBackoffSec)) * time.Second,
+		lease:         time.Duration(orDefault(cfg.LeaseSec, defaultOutboxLeaseSec)) * time.Second,
+		retention:     time.Duration(orDefault(cfg.RetentionHours, defaultOutboxRetentionHours)) * time.Hour,
+		deadRetention: time.Duration(orDefault(cfg.DeadRetentionHours, defaultOutboxDeadRetentionHours)) * time.Hour,
+	}
+}
+
+type outboxImpl struct {
+	storage    domain.NotificationOutboxStorage
+	channels   domain.NotificationChannelRegistry
+	opt        *outboxOptions
+	cancelFunc context.CancelFunc
+	running    *atomic.Bool
+}
+
+func NewNotificationOutbox(storage domain.NotificationOutboxStorage, channels domain.NotificationChannelRegistry) domain.NotificationOutbox {
+	return &outboxImpl{
+		storage:  storage,
+		channels: channels,
+		opt:      newOutboxOptions(nil),
+		running:  atomic.NewBool(false),
+	}
+}
+
+func (s *outboxImpl) l() log.CLogger {
+	return service.L().Cmp("notification-outbox")
+}
+
+func (s *outboxImpl) Init(cfg *service.Config) {
+	if cfg.Arbitrage != nil && cfg.Arbitrage.Notification != nil {
+		s.opt = newOutboxOptions(cfg.Arbitrage.Notification.Outbox)
+	}
+}
+
+func (s *outboxImpl) Enqueue(ctx context.Context, deliveries []*domain.OutboxDelivery) error {
+	s.l().C(ctx).Mth("enqueue").F(log.FF{"count": len(deliveries)}).Trc()
+	if len(deliveries) == 0 {
+		return nil
+	}
+	now := kit.Now()
+	for _, d := range deliveries {
+		if d.Id == "" {
+			d.Id = kit.NewId()
+		}
+		d.Status = domain.OutboxStatusPending
+		d.Attempts = 0
+		d.NextAttemptAt = now
+		d.CreatedAt = now
+		d.UpdatedAt = now
+	}
+	return s.storage.AddDeliveries(ctx, deliveries)
+}
+
+// backoff is a delay before the next attempt, it's doubled with each failed attempt up to the max backoff
+func (s *outboxImpl) backoff(attempts int) time.Duration {
+	d := s.opt.backoff
+	for i := 1; i < attempts && d < s.opt.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > s.opt.maxBackoff {
+		d = s.opt.maxBackoff
+	}
+	return d
+}
+
+// permanent checks if the delivery can never succeed, so it goes to the dead-letter state without retries
+func (s *outboxImpl) permanent(err error) bool {
+	if telegram.IsRejected(err) {
+		return true
+	}
+	if appErr, ok := er.Is(err); ok {
+		return appErr.Code() == errors.ErrCodeOutboxDeliveryInvalid || appErr.Code() == errors.ErrCodeSubscriptionNotificationChannelNotSupported ||
+			appErr.Code() == errors.ErrCodeNotificationTemplateRender
+	}
+	return false
+}
+
+func (s *outboxImpl) send(ctx context.Context, d *domain.OutboxDelivery) error {
+	if d.Notification == nil {
+		return errors.ErrOutboxDeliveryInvalid(ctx, d.Id)
+	}
+	channel, ok := s.channels.Get(d.Notification.Channel)
+	if !ok {
+		return errors.ErrSubscriptionNotificationChannelNotSupported(ctx, d.Notification.Channel)
+	}
+	return channel.Send(ctx, d)
+}
+
+// deliver sends claimed delivery and updates its state
+// attempts are counted when the delivery is claimed, so a delivery which crashes the worker doesn't loop forever
+func (s *outboxImpl) deliver(ctx context.Context, d *domain.OutboxDelivery, now time.Time) error {
+	l := s.l().C(ctx).Mth("deliver").F(log.FF{"deliveryId": d.Id, "attempts": d.Attempts})
+
+	err := s.send(ctx, d)
+	d.UpdatedAt = now
+	if err == nil {
+		d.Status = domain.OutboxStatusDelivered
+		d.DeliveredAt = &now
+		d.LastError = ""
+		l.Dbg("delivered")
+	} else {
+		d.LastError = err.Error()
+		if d.Attempts >= s.opt.maxAttempts || s.permanent(err) {
+			d.Status = domain.OutboxStatusDead
+			l.E(err).Warn("dead")
+		} else {
+			d.Status = domain.OutboxStatusPending
+			delay := s.backoff(d.Attempts)
+			// the channel asked to wait longer than the backoff
+			if retryAfter, ok := telegram.RetryAfter(err); ok && retryAfter > delay {
+				delay = retryAfter
+			}
+			d.NextAttemptAt = now.Add(delay)
+			l.E(err).Dbg("retry")
+		}
+	}
+	return s.storage.UpdateDelivery(ctx, d)
+}
+
+// process claims a batch of due deliveries and delivers them, returns number of claimed deliveries
+func (s *outboxImpl) process(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := s.storage.ClaimDeliveries(ctx, now, s.opt.batchSize, now.Add(s.opt.lease))
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range deliveries {
+		if err := s.deliver(ctx, d, kit.Now()); err != nil {
+			s.l().C(ctx).Mth("process").F(log.FF{"deliveryId": d.Id}).E(err).Err()
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (s *outboxImpl) Run(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("run").Trc()
+
+	// check running
+	if s.running.Load() {
+		return errors.ErrOutboxAlreadyRun(ctx)
+	}
+
+	ctx, s.cancelFunc = context.WithCancel(ctx)
+	s.running.Store(true)
+
+	for i := 0; i < s.opt.workers; i++ {
+		goroutine.New().
+			WithLogger(s.l().C(ctx).Mth("outbox-worker")).
+			WithRetry(goroutine.Unrestricted).
+			WithRetryDelay(time.Second*10).
+			Go(ctx, func() {
+				ticker := time.NewTicker(s.opt.period)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ticker.C:
+						// drain the outbox while there are due deliveries
+						for {
+							n, err := s.process(ctx, kit.Now())
+							if err != nil {
+								s.l().C(ctx).Mth("outbox-worker").E(err).Err()
+							}
+							if err != nil || n < s.opt.batchSize || ctx.Err() != nil {
+								break
+							}
+						}
+					case <-ctx.Done():
+						return
+					}
+				}
+			})
+	}
+
+	goroutine.New().
+		WithLogger(s.l().C(ctx).Mth("outbox-cleanup")).
+		WithRetry(goroutine.Unrestricted).
+		WithRetryDelay(time.Second*10).
+		Go(ctx, func() {
+			ticker := time.NewTicker(outboxCleanupPeriod)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					s.cleanup(ctx, kit.Now())
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+
+	l.InfF("ok, workers: %d", s.opt.workers)
+	return nil
+}
+
+// cleanup deletes delivered and dead deliveries which retention has passed
+func (s *outboxImpl) cleanup(ctx context.Context, now time.Time) {
+	if err := s.storage.DeleteDeliveries(ctx, domain.OutboxStatusDelivered, now.Add(-s.opt.retention)); err != nil {
+		s.l().C(ctx).Mth("outbox-cleanup").E(err).Err()
+	}
+	if err := s.storage.DeleteDeliveries(ctx, domain.OutboxStatusDead, now.Add(-s.opt.deadRetention)); err != nil {
+		s.l().C(ctx).Mth("outbox-cleanup").E(err).Err()
+	}
+}
+
+func (s *outboxImpl) Stop(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("stop").Trc()
+	// cancel if running
+	if s.cancelFunc != nil && s.running.Load() {
+		s.cancelFunc()
+		s.running.Store(false)
+		s.cancelFunc = nil
+		l.Inf("ok")
+	}
+	return nil
+}
+
+func (s *outboxImpl) Search(ctx context.Context, rq *domain.SearchOutboxRequest) (*domain.SearchOutboxResponse, error) {
+	s.l().C(ctx).Mth("search").Trc()
+	for _, st := range rq.Statuses {
+		switch st {
+		case domain.OutboxStatusPending, domain.OutboxStatusSending, domain.OutboxStatusDelivered, domain.OutboxStatusDead:
+		default:
+			return nil, errors.ErrOutboxStatusInvalid(ctx, st)
+		}
+	}
+	if rq.Size <= 0 {
+		rq.Size = defaultOutboxSearchSize
+	}
+	if rq.Size > maxOutboxSearchSize {
+		rq.Size = maxOutboxSearchSize
+	}
+	return s.storage.SearchDeliveries(ctx, rq)
+}
+
+func (s *outboxImpl) Replay(ctx context.Context, deliveryIds []string) ([]*domain.OutboxDelivery, error) {
+	s.l().C(ctx).Mth("replay").F(log.FF{"ids": deliveryIds}).Trc()
+
+	deliveryIds = kit.Strings(deliveryIds).Distinct()
+	if len(deliveryIds) == 0 {
+		return nil, errors.ErrOutboxReplayEmpty(ctx)
+	}
+
+	deliveries, err := s.storage.GetDeliveries(ctx, deliveryIds)
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[string]*domain.OutboxDelivery, len(deliveries))
+	for _, d := range deliveries {
+		byId[d.Id] = d
+	}
+
+	// check all before replaying any
+	for _, id := range deliveryIds {
+		d, ok := byId[id]
+		if !ok {
+			return nil, errors.ErrOutboxDeliveryNotFound(ctx, id)
+		}
+		if d.Status != domain.OutboxStatusDead {
+			return nil, errors.ErrOutboxDeliveryNotDead(ctx, id, d.Status)
+		}
+	}
+
+	now := kit.Now()
+	r := make([]*domain.OutboxDelivery, 0, len(deliveryIds))
+	for _, id := range deliveryIds {
+		d := byId[id]
+		d.Status = domain.OutboxStatusPending
+		d.Attempts = 0
+		d.NextAttemptAt = now
+		d.UpdatedAt = now
+		if err := s.storage.UpdateDelivery(ctx, d); err != nil {
+			return nil, err
+		}
+		r = append(r, d)
+	}
+	return r, nil
+}
@@ -0,0 +1,195 @@
+package subscription
+
+import (
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type outboxTestSuite struct {
+	kitTestSuite.Suite
+	storage *mocks.NotificationOutboxStorage
+	channel *mocks.NotificationChannel
+	svc     domain.NotificationOutbox
+}
+
+func (s *outboxTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestOutboxSuite(t *testing.T) {
+	suite.Run(t, new(outboxTestSuite))
+}
+
+func (s *outboxTestSuite) SetupTest() {
+	s.storage = &mocks.NotificationOutboxStorage{}
+	s.channel = &mocks.NotificationChannel{}
+	s.channel.On("Type").Return(domain.SubscriptionNotificationChannelWebhook)
+	s.svc = NewNotificationOutbox(s.storage, NewNotificationChannelRegistry(s.channel))
+	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{
+		Outbox: &service.NotificationOutbox{MaxAttempts: 3, BackoffSec: 10, MaxBackoffSec: 30},
+	}}})
+}
+
+func (s *outboxTestSuite) delivery(status string, attempts int) *domain.OutboxDelivery {
+	return &domain.OutboxDelivery{
+		Id:           kit.NewId(),
+		Notification: &domain.SubscriptionNotification{Id: "n", Channel: domain.SubscriptionNotificationChannelWebhook},
+		Chain:        &domain.ProfitableChain{Id: kit.NewId()},
+		Status:       status,
+		Attempts:     attempts,
+	}
+}
+
+func (s *outboxTestSuite) Test_Enqueue() {
+	deliveries := []*domain.OutboxDelivery{{}, {}}
+	s.storage.On("AddDeliveries", s.Ctx, deliveries).Return(nil)
+	s.NoError(s.svc.Enqueue(s.Ctx, deliveries))
+	for _, d := range deliveries {
+		s.NotEmpty(d.Id)
+		s.Equal(domain.OutboxStatusPending, d.Status)
+		s.False(d.NextAttemptAt.IsZero())
+	}
+	s.NotEqual(deliveries[0].Id, deliveries[1].Id)
+
+	// nothing to store
+	s.NoError(s.svc.Enqueue(s.Ctx, nil))
+	s.storage.AssertNumberOfCalls(s.T(), "AddDeliveries", 1)
+}
+
+func (s *outboxTestSuite) Test_Backoff() {
+	svc := s.svc.(*outboxImpl)
+	s.Equal(time.Second*10, svc.backoff(1))
+	s.Equal(time.Second*20, svc.backoff(2))
+	s.Equal(time.Second*30, svc.backoff(3))
+	s.Equal(time.Second*30, svc.backoff(10))
+}
+
+func (s *outboxTestSuite) Test_Deliver_Ok() {
+	d := s.delivery(domain.OutboxStatusSending, 1)
+	now := kit.Now()
+	s.channel.On("Send", s.Ctx, d).Return(nil)
+	s.storage.On("UpdateDelivery", s.Ctx, d).Return(nil)
+	s.NoError(s.svc.(*outboxImpl).deliver(s.Ctx, d, now))
+	s.Equal(domain.OutboxStatusDelivered, d.Status)
+	s.Equal(now, *d.DeliveredAt)
+	s.Empty(d.LastError)
+}
+
+func (s *outboxTestSuite) Test_Deliver_WhenFailed_Retry() {
+	d := s.delivery(domain.OutboxStatusSending, 2)
+	now := kit.Now()
+	s.channel.On("Send", s.Ctx, d).Return(fmt.Errorf("unavailable"))
+	s.storage.On("UpdateDelivery", s.Ctx, d).Return(nil)
+	s.NoError(s.svc.(*outboxImpl).deliver(s.Ctx, d, now))
+	s.Equal(domain.OutboxStatusPending, d.Status)
+	s.Equal(now.Add(time.Second*20), d.NextAttemptAt)
+	s.Equal("unavailable", d.LastError)
+	s.Nil(d.DeliveredAt)
+}
+
+func (s *outboxTestSuite) Test_Deliver_WhenMaxAttempts_Dead() {
+	d := s.delivery(domain.OutboxStatusSending, 3)
+	s.channel.On("Send", s.Ctx, d).Return(fmt.Errorf("unavailable"))
+	s.storage.On("UpdateDelivery", s.Ctx, d).Return(nil)
+	s.NoError(s.svc.(*outboxImpl).deliver(s.Ctx, d, kit.Now()))
+	s.Equal(domain.OutboxStatusDead, d.Status)
+}
+
+func (s *outboxTestSuite) Test_Deliver_WhenPermanentError_Dead() {
+	// channel isn't registered
+	d := s.delivery(domain.OutboxStatusSending, 1)
+	d.Notification.Channel = domain.SubscriptionNotificationChannelEmail
+	s.storage.On("UpdateDelivery", s.Ctx, d).Return(nil)
+	s.NoError(s.svc.(*outboxImpl).deliver(s.Ctx, d, kit.Now()))
+	s.Equal(domain.OutboxStatusDead, d.Status)
+
+	// channel rejects the delivery
+	d = s.delivery(domain.OutboxStatusSending, 1)
+	s.channel.On("Send", s.Ctx, d).Return(errors.ErrOutboxDeliveryInvalid(s.Ctx, d.Id))
+	s.storage.On("UpdateDelivery", s.Ctx, d).Return(nil)
+	s.NoError(s.svc.(*outboxImpl).deliver(s.Ctx, d, kit.Now()))
+	s.Equal(domain.OutboxStatusDead, d.Status)
+
+	// telegram chat isn't found
+	d = s.delivery(domain.OutboxStatusSending, 1)
+	s.channel.On("Send", s.Ctx, d).Return(telegram.ErrTelegramRequestRejected(s.Ctx, "400 Bad Request", "Bad Request: chat not found"))
+	s.storage.On("UpdateDelivery", s.Ctx, d).Return(nil)
+	s.NoError(s.svc.(*outboxImpl).deliver(s.Ctx, d, kit.Now()))
+	s.Equal(domain.OutboxStatusDead, d.Status)
+}
+
+func (s *outboxTestSuite) Test_Deliver_WhenTooManyRequests_RetryAfter() {
+	d := s.delivery(domain.OutboxStatusSending, 1)
+	now := kit.Now()
+	s.channel.On("Send", s.Ctx, d).Return(telegram.ErrTelegramTooManyRequests(s.Ctx, 120))
+	s.storage.On("UpdateDelivery", s.Ctx, d).Return(nil)
+	s.NoError(s.svc.(*outboxImpl).deliver(s.Ctx, d, now))
+	s.Equal(domain.OutboxStatusPending, d.Status)
+	s.Equal(now.Add(time.Minute*2), d.NextAttemptAt)
+}
+
+func (s *outboxTestSuite) Test_Process() {
+	d1, d2 := s.delivery(domain.OutboxStatusSending, 1), s.delivery(domain.OutboxStatusSending, 1)
+	now := kit.Now()
+	s.storage.On("ClaimDeliveries", s.Ctx, now, defaultOutboxBatchSize, now.Add(time.Second*defaultOutboxLeaseSec)).
+		Return([]*domain.OutboxDelivery{d1, d2}, nil)
+	s.channel.On("Send", s.Ctx, mock.AnythingOfType("*domain.OutboxDelivery")).Return(nil)
+	s.storage.On("UpdateDelivery", s.Ctx, mock.AnythingOfType("*domain.OutboxDelivery")).Return(nil)
+	n, err := s.svc.(*outboxImpl).process(s.Ctx, now)
+	s.NoError(err)
+	s.Equal(2, n)
+	s.Equal(domain.OutboxStatusDelivered, d1.Status)
+	s.Equal(domain.OutboxStatusDelivered, d2.Status)
+}
+
+func (s *outboxTestSuite) Test_Cleanup_DeadKeptLonger() {
+	now := kit.Now()
+	s.storage.On("DeleteDeliveries", s.Ctx, domain.OutboxStatusDelivered, now.Add(-time.Hour*defaultOutboxRetentionHours)).Return(nil).Once()
+	s.storage.On("DeleteDeliveries", s.Ctx, domain.OutboxStatusDead, now.Add(-time.Hour*defaultOutboxDeadRetentionHours)).Return(nil).Once()
+	s.svc.(*outboxImpl).cleanup(s.Ctx, now)
+	s.storage.AssertExpectations(s.T())
+}
+
+func (s *outboxTestSuite) Test_Search_WhenStatusInvalid_Fail() {
+	_, err := s.svc.Search(s.Ctx, &domain.SearchOutboxRequest{Statuses: []string{"unknown"}})
+	s.AssertAppErr(err, errors.ErrCodeOutboxStatusInvalid)
+}
+
+func (s *outboxTestSuite) Test_Replay_Ok() {
+	d := s.delivery(domain.OutboxStatusDead, 3)
+	s.storage.On("GetDeliveries", s.Ctx, []string{d.Id}).Return([]*domain.OutboxDelivery{d}, nil)
+	s.storage.On("UpdateDelivery", s.Ctx, d).Return(nil)
+	rs, err := s.svc.Replay(s.Ctx, []string{d.Id, d.Id})
+	s.NoError(err)
+	s.Len(rs, 1)
+	s.Equal(domain.OutboxStatusPending, rs[0].Status)
+	s.Equal(0, rs[0].Attempts)
+}
+
+func (s *outboxTestSuite) Test_Replay_Fail() {
+	_, err := s.svc.Replay(s.Ctx, nil)
+	s.AssertAppErr(err, errors.ErrCodeOutboxReplayEmpty)
+
+	dead, delivered := s.delivery(domain.OutboxStatusDead, 3), s.delivery(domain.OutboxStatusDelivered, 1)
+	s.storage.On("GetDeliveries", s.Ctx, []string{dead.Id, delivered.Id}).Return([]*domain.OutboxDelivery{dead, delivered}, nil)
+	_, err = s.svc.Replay(s.Ctx, []string{dead.Id, delivered.Id})
+	s.AssertAppErr(err, errors.ErrCodeOutboxDeliveryNotDead)
+
+	missing := kit.NewId()
+	s.storage.On("GetDeliveries", s.Ctx, []string{dead.Id, missing}).Return([]*domain.OutboxDelivery{dead}, nil)
+	_, err = s.svc.Replay(s.Ctx, []string{dead.Id, missing})
+	s.AssertAppErr(err, errors.ErrCodeOutboxDeliveryNotFound)
+
+	// nothing is replayed if any delivery is invalid
+	s.storage.AssertNotCalled(s.T(), "UpdateDelivery", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,237 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"sort"
+	"strings"
+	"time"
+	// timezones of subscribers don't depend on tzdata installed on the host
+	_ "time/tzdata"
+)
+
+const (
+	maxThrottleMessages   = 1000
+	minThrottleWindowSec  = 60
+	maxThrottleWindowSec  = 86400
+	minDigestPeriodMin    = 5
+	maxDigestPeriodMin    = 1440
+	defaultDigestMaxItems = 10
+	maxDigestMaxItems     = 50
+	digestCheckPeriod     = time.Second * 30
+	throttleCleanupPeriod = time.Minute * 10
+)
+
+// parseDayMinute parses HH:MM to minutes since midnight
+func parseDayMinute(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// validatePolicy validates and populates delivery policy
+func validatePolicy(ctx context.Context, policy *domain.SubscriptionDeliveryPolicy) error {
+	if policy == nil {
+		return nil
+	}
+
+	if policy.MaxMessages < 0 || policy.MaxMessages > maxThrottleMessages {
+		return errors.ErrSubscriptionPolicyInvalid(ctx, fmt.Sprintf("max messages must be between 0 and %d", maxThrottleMessages))
+	}
+	if policy.MaxMessages > 0 && (policy.WindowSec < minThrottleWindowSec || policy.WindowSec > maxThrottleWindowSec) {
+		return errors.ErrSubscriptionPolicyInvalid(ctx, fmt.Sprintf("window must be between %d and %d seconds", minThrottleWindowSec, maxThrottleWindowSec))
+	}
+	if policy.MaxMessages == 0 {
+		policy.WindowSec = 0
+	}
+
+	if q := policy.QuietHours; q != nil {
+		from, err := parseDayMinute(q.From)
+		if err != nil {
+			return errors.ErrSubscriptionPolicyInvalid(ctx, "quiet hours start must be HH:MM")
+		}
+		to, err := parseDayMinute(q.To)
+		if err != nil {
+			return errors.ErrSubscriptionPolicyInvalid(ctx, "quiet hours end must be HH:MM")
+		}
+		if from == to {
+			return errors.ErrSubscriptionPolicyInvalid(ctx, "quiet hours are empty")
+		}
+		q.From, q.To = strings.TrimSpace(q.From), strings.TrimSpace(q.To)
+		q.Timezone = strings.TrimSpace(q.Timezone)
+		if _, err := time.LoadLocation(q.Timezone); err != nil {
+			return errors.ErrSubscriptionPolicyInvalid(ctx, fmt.Sprintf("unknown timezone %s", q.Timezone))
+		}
+	}
+
+	if d := policy.Digest; d != nil {
+		if d.PeriodMin < minDigestPeriodMin || d.PeriodMin > maxDigestPeriodMin {
+			return errors.ErrSubscriptionPolicyInvalid(ctx, fmt.Sprintf("digest period must be between %d and %d minutes", minDigestPeriodMin, maxDigestPeriodMin))
+		}
+		if d.MaxItems == 0 {
+			d.MaxItems = defaultDigestMaxItems
+		}
+		if d.MaxItems < 0 || d.MaxItems > maxDigestMaxItems {
+			return errors.ErrSubscriptionPolicyInvalid(ctx, fmt.Sprintf("digest items must be between 1 and %d", maxDigestMaxItems))
+		}
+	}
+
+	return nil
+}
+
+// inQuietHours checks if the time is within quiet hours in the subscriber's timezone
+func inQuietHours(q *domain.SubscriptionQuietHours, now time.Time) bool {
+	if q == nil {
+		return false
+	}
+	from, err := parseDayMinute(q.From)
+	if err != nil {
+		return false
+	}
+	to, err := parseDayMinute(q.To)
+	if err != nil {
+		return false
+	}
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	m := local.Hour()*60 + local.Minute()
+	if from < to {
+		return m >= from && m < to
+	}
+	// quiet hours cross midnight
+	return m >= from || m < to
+}
+
+// opportunityProfit profit share of the opportunity the delivery is made for
+func opportunityProfit(d *domain.OutboxDelivery) float64 {
+	if d.Chain != nil {
+		return d.Chain.ProfitShare
+	}
+	if d.Spread != nil {
+		return d.Spread.SpreadShare
+	}
+	return 0
+}
+
+// byProfit sorts opportunities by profit, the most profitable first
+func byProfit(opportunities []*domain.OutboxDelivery) []*domain.OutboxDelivery {
+	r := make([]*domain.OutboxDelivery, len(opportunities))
+	copy(r, opportunities)
+	sort.SliceStable(r, func(i, j int) bool { return opportunityProfit(r[i]) > opportunityProfit(r[j]) })
+	return r
+}
+
+// deliveryPolicies enforces delivery policies of subscriptions
+// sent messages and pending digests are kept in the delivery policy storage
+type deliveryPolicies struct {
+	storage domain.DeliveryPolicyStorage
+}
+
+func newDeliveryPolicies(storage domain.DeliveryPolicyStorage) *deliveryPolicies {
+	return &deliveryPolicies{
+		storage: storage,
+	}
+}
+
+// apply applies delivery policy of the subscription to the matched opportunities and returns ones to be sent immediately
+// when throttled, the most profitable opportunities are sent
+func (p *deliveryPolicies) apply(ctx context.Context, subs *domain.Subscription, matched []*domain.OutboxDelivery, now time.Time) ([]*domain.OutboxDelivery, error) {
+	policy := subs.Policy
+	if policy == nil || len(matched) == 0 {
+		return matched, nil
+	}
+
+	// digest collects opportunities regardless of quiet hours, sending is postponed instead
+	if policy.Digest != nil {
+		return nil, p.collect(ctx, subs.Id, policy.Digest, matched, now)
+	}
+
+	if inQuietHours(policy.QuietHours, now) {
+		return nil, nil
+	}
+
+	if policy.MaxMessages <= 0 {
+		return matched, nil
+	}
+	windowStart := now.Add(-time.Duration(policy.WindowSec) * time.Second)
+	reserved, err := p.storage.ReserveMessages(ctx, subs.Id, len(matched), policy.MaxMessages, windowStart, now)
+	if err != nil {
+		return nil, err
+	}
+	if reserved <= 0 {
+		return nil, nil
+	}
+	if len(matched) > reserved {
+		matched = byProfit(matched)[:reserved]
+	}
+	return matched, nil
+}
+
+func (p *deliveryPolicies) collect(ctx context.Context, subscriptionId string, digest *domain.SubscriptionDigest, matched []*domain.OutboxDelivery, now time.Time) error {
+	items := make([]*domain.DigestItem, 0, len(matched))
+	for _, o := range matched {
+		items = append(items, &domain.DigestItem{
+			OpportunityType: o.OpportunityType,
+			OpportunityId:   o.OpportunityId,
+			Profit:          opportunityProfit(o),
+			Chain:           o.Chain,
+			Spread:          o.Spread,
+		})
+	}
+	maxItems := digest.MaxItems
+	if maxItems <= 0 {
+		maxItems = defaultDigestMaxItems
+	}
+	return p.storage.AddDigestItems(ctx, subscriptionId, items, maxItems, now)
+}
+
+// due splits pending digests into ones which are due to be sent and ones of subscriptions which aren't active or don't use digests anymore
+// digests which period isn't over or which subscriptions are in quiet hours are neither of them
+func (p *deliveryPolicies) due(pending []*domain.PendingDigest, subs []*domain.Subscription, now time.Time) (due, dropped []*domain.PendingDigest) {
+	active := make(map[string]*domain.Subscription, len(subs))
+	for _, sub := range subs {
+		active[sub.Id] = sub
+	}
+	for _, digest := range pending {
+		sub, ok := active[digest.SubscriptionId]
+		if !ok || sub.Policy == nil || sub.Policy.Digest == nil {
+			dropped = append(dropped, digest)
+			continue
+		}
+		if now.Before(digest.From.Add(time.Duration(sub.Policy.Digest.PeriodMin)*time.Minute)) || inQuietHours(sub.Policy.QuietHours, now) {
+			continue
+		}
+		due = append(due, digest)
+	}
+	return due, dropped
+}
+
+// notificationDigest builds notification of the pending digest
+func notificationDigest(pending *domain.PendingDigest, now time.Time) *domain.NotificationDigest {
+	digest := &domain.NotificationDigest{
+		Matched: pending.Matched,
+		From:    pending.From,
+		To:      now,
+	}
+	for _, item := range pending.Items {
+		if item.Chain != nil {
+			digest.Chains = append(digest.Chains, item.Chain)
+		}
+		if item.Spread != nil {
+			digest.Spreads = append(digest.Spreads, item.Spread)
+		}
+	}
+	return digest
+}
+
+// forget deletes messages of throttling windows which are over
+func (p *deliveryPolicies) forget(ctx context.Context, now time.Time) error {
+	return p.storage.DeleteMessages(ctx, now.Add(-time.Second*maxThrottleWindowSec))
+}
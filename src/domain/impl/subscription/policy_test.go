@@ -0,0 +1,233 @@
+package subscription
+
+import (
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type policyTestSuite struct {
+	kitTestSuite.Suite
+}
+
+func (s *policyTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestPolicySuite(t *testing.T) {
+	suite.Run(t, new(policyTestSuite))
+}
+
+func (s *policyTestSuite) chain(profit float64) *domain.OutboxDelivery {
+	chain := &domain.ProfitableChain{Id: kit.NewId(), ProfitShare: profit}
+	return &domain.OutboxDelivery{OpportunityType: domain.OpportunityTypeChain, OpportunityId: chain.Id, Chain: chain}
+}
+
+func (s *policyTestSuite) Test_Validate_Ok() {
+	policy := &domain.SubscriptionDeliveryPolicy{
+		WindowSec:  100,
+		QuietHours: &domain.SubscriptionQuietHours{From: " 23:00", To: "07:30 ", Timezone: "Europe/Moscow"},
+		Digest:     &domain.SubscriptionDigest{PeriodMin: 60},
+	}
+	s.NoError(validatePolicy(s.Ctx, policy))
+	// window is ignored if messages aren't limited
+	s.Equal(0, policy.WindowSec)
+	s.Equal("23:00", policy.QuietHours.From)
+	s.Equal("07:30", policy.QuietHours.To)
+	s.Equal(defaultDigestMaxItems, policy.Digest.MaxItems)
+	s.NoError(validatePolicy(s.Ctx, nil))
+}
+
+func (s *policyTestSuite) Test_Validate_Fail() {
+	tests := []*domain.SubscriptionDeliveryPolicy{
+		{MaxMessages: -1},
+		{MaxMessages: maxThrottleMessages + 1, WindowSec: 60},
+		{MaxMessages: 10},
+		{MaxMessages: 10, WindowSec: maxThrottleWindowSec + 1},
+		{QuietHours: &domain.SubscriptionQuietHours{From: "25:00", To: "07:00"}},
+		{QuietHours: &domain.SubscriptionQuietHours{From: "23:00", To: "7"}},
+		{QuietHours: &domain.SubscriptionQuietHours{From: "23:00", To: "23:00"}},
+		{QuietHours: &domain.SubscriptionQuietHours{From: "23:00", To: "07:00", Timezone: "Mars/Olympus"}},
+		{Digest: &domain.SubscriptionDigest{}},
+		{Digest: &domain.SubscriptionDigest{PeriodMin: maxDigestPeriodMin + 1}},
+		{Digest: &domain.SubscriptionDigest{PeriodMin: 60, MaxItems: maxDigestMaxItems + 1}},
+	}
+	for _, tt := range tests {
+		s.AssertAppErr(validatePolicy(s.Ctx, tt), errors.ErrCodeSubscriptionPolicyInvalid)
+	}
+}
+
+func (s *policyTestSuite) Test_InQuietHours() {
+	// 20:30 UTC is 23:30 in Moscow
+	now := time.Date(2022, 10, 24, 20, 30, 0, 0, time.UTC)
+	tests := []struct {
+		quiet    *domain.SubscriptionQuietHours
+		expected bool
+	}{
+		{nil, false},
+		{&domain.SubscriptionQuietHours{From: "20:00", To: "21:00"}, true},
+		{&domain.SubscriptionQuietHours{From: "21:00", To: "22:00"}, false},
+		{&domain.SubscriptionQuietHours{From: "20:30", To: "21:00"}, true},
+		{&domain.SubscriptionQuietHours{From: "20:00", To: "20:30"}, false},
+		{&domain.SubscriptionQuietHours{From: "23:00", To: "07:00"}, false},
+		{&domain.SubscriptionQuietHours{From: "23:00", To: "07:00", Timezone: "Europe/Moscow"}, true},
+		{&domain.SubscriptionQuietHours{From: "00:00", To: "23:00", Timezone: "Europe/Moscow"}, false},
+	}
+	for _, tt := range tests {
+		s.Equal(tt.expected, inQuietHours(tt.quiet, now), "%+v", tt.quiet)
+	}
+}
+
+func (s *policyTestSuite) Test_Apply_NoPolicy() {
+	storage := &mocks.DeliveryPolicyStorage{}
+	p := newDeliveryPolicies(storage)
+	matched := []*domain.OutboxDelivery{s.chain(1.01), s.chain(1.02)}
+	sent, err := p.apply(s.Ctx, &domain.Subscription{Id: kit.NewId()}, matched, kit.Now())
+	s.NoError(err)
+	s.Equal(matched, sent)
+	storage.AssertExpectations(s.T())
+}
+
+func (s *policyTestSuite) Test_Apply_Throttle() {
+	storage := &mocks.DeliveryPolicyStorage{}
+	p := newDeliveryPolicies(storage)
+	subs := &domain.Subscription{Id: kit.NewId(), Policy: &domain.SubscriptionDeliveryPolicy{MaxMessages: 2, WindowSec: 60}}
+	now := kit.Now()
+	storage.On("ReserveMessages", s.Ctx, subs.Id, 3, 2, now.Add(-time.Minute), now).Return(2, nil)
+	storage.On("ReserveMessages", s.Ctx, subs.Id, 1, 2, now.Add(-time.Second*30), now.Add(time.Second*30)).Return(0, nil)
+
+	low, high, mid := s.chain(1.01), s.chain(1.05), s.chain(1.03)
+	// the most profitable are sent when throttled
+	sent, err := p.apply(s.Ctx, subs, []*domain.OutboxDelivery{low, high, mid}, now)
+	s.NoError(err)
+	s.Equal([]*domain.OutboxDelivery{high, mid}, sent)
+	// the limit is reached within the window
+	sent, err = p.apply(s.Ctx, subs, []*domain.OutboxDelivery{s.chain(1.1)}, now.Add(time.Second*30))
+	s.NoError(err)
+	s.Empty(sent)
+	storage.AssertExpectations(s.T())
+}
+
+func (s *policyTestSuite) Test_Apply_Throttle_WhenStorageFails_Fail() {
+	storage := &mocks.DeliveryPolicyStorage{}
+	p := newDeliveryPolicies(storage)
+	subs := &domain.Subscription{Id: kit.NewId(), Policy: &domain.SubscriptionDeliveryPolicy{MaxMessages: 2, WindowSec: 60}}
+	storage.On("ReserveMessages", s.Ctx, subs.Id, 1, 2, mock.Anything, mock.Anything).Return(0, errors.ErrDeliveryPolicyStoragePut(fmt.Errorf("error"), s.Ctx))
+	_, err := p.apply(s.Ctx, subs, []*domain.OutboxDelivery{s.chain(1.01)}, kit.Now())
+	s.AssertAppErr(err, errors.ErrCodeDeliveryPolicyStoragePut)
+}
+
+func (s *policyTestSuite) Test_Apply_QuietHours() {
+	storage := &mocks.DeliveryPolicyStorage{}
+	p := newDeliveryPolicies(storage)
+	now := time.Date(2022, 10, 24, 2, 0, 0, 0, time.UTC)
+	subs := &domain.Subscription{Id: kit.NewId(), Policy: &domain.SubscriptionDeliveryPolicy{
+		QuietHours: &domain.SubscriptionQuietHours{From: "23:00", To: "07:00"},
+	}}
+	matched := []*domain.OutboxDelivery{s.chain(1.01)}
+	sent, err := p.apply(s.Ctx, subs, matched, now)
+	s.NoError(err)
+	s.Empty(sent)
+	sent, err = p.apply(s.Ctx, subs, matched, now.Add(time.Hour*6))
+	s.NoError(err)
+	s.Equal(matched, sent)
+	// nothing is collected
+	storage.AssertExpectations(s.T())
+}
+
+func (s *policyTestSuite) Test_Apply_Digest() {
+	storage := &mocks.DeliveryPolicyStorage{}
+	p := newDeliveryPolicies(storage)
+	now := time.Date(2022, 10, 24, 22, 0, 0, 0, time.UTC)
+	subs := &domain.Subscription{Id: kit.NewId(), Policy: &domain.SubscriptionDeliveryPolicy{
+		QuietHours: &domain.SubscriptionQuietHours{From: "21:00", To: "07:00"},
+		Digest:     &domain.SubscriptionDigest{PeriodMin: 10},
+	}}
+	var items []*domain.DigestItem
+	storage.On("AddDigestItems", s.Ctx, subs.Id, mock.AnythingOfType("[]*domain.DigestItem"), defaultDigestMaxItems, now).
+		Run(func(args mock.Arguments) { items = args.Get(2).([]*domain.DigestItem) }).
+		Return(nil)
+
+	// opportunities matched during quiet hours are collected
+	chain := s.chain(1.01)
+	spread := &domain.OutboxDelivery{OpportunityType: domain.OpportunityTypeSpread, OpportunityId: kit.NewId(), Spread: &domain.Spread{SpreadShare: 1.04}}
+	sent, err := p.apply(s.Ctx, subs, []*domain.OutboxDelivery{chain, spread}, now)
+	s.NoError(err)
+	s.Empty(sent)
+	s.Equal([]*domain.DigestItem{
+		{OpportunityType: domain.OpportunityTypeChain, OpportunityId: chain.OpportunityId, Profit: 1.01, Chain: chain.Chain},
+		{OpportunityType: domain.OpportunityTypeSpread, OpportunityId: spread.OpportunityId, Profit: 1.04, Spread: spread.Spread},
+	}, items)
+	storage.AssertExpectations(s.T())
+}
+
+func (s *policyTestSuite) Test_Due() {
+	p := newDeliveryPolicies(&mocks.DeliveryPolicyStorage{})
+	now := time.Date(2022, 10, 24, 12, 0, 0, 0, time.UTC)
+	subs := &domain.Subscription{Id: kit.NewId(), Policy: &domain.SubscriptionDeliveryPolicy{
+		Digest: &domain.SubscriptionDigest{PeriodMin: 10, MaxItems: 2},
+	}}
+	high, spread := s.chain(1.05), &domain.Spread{Id: kit.NewId(), SpreadShare: 1.04}
+	pending := &domain.PendingDigest{
+		Id:             kit.NewId(),
+		SubscriptionId: subs.Id,
+		Items: []*domain.DigestItem{
+			{OpportunityType: domain.OpportunityTypeChain, OpportunityId: high.OpportunityId, Profit: 1.05, Chain: high.Chain},
+			{OpportunityType: domain.OpportunityTypeSpread, OpportunityId: spread.Id, Profit: 1.04, Spread: spread},
+		},
+		Matched: 4,
+		From:    now,
+	}
+
+	// period isn't over
+	due, dropped := p.due([]*domain.PendingDigest{pending}, []*domain.Subscription{subs}, now.Add(time.Minute*9))
+	s.Empty(due)
+	s.Empty(dropped)
+
+	due, dropped = p.due([]*domain.PendingDigest{pending}, []*domain.Subscription{subs}, now.Add(time.Minute*10))
+	s.Equal([]*domain.PendingDigest{pending}, due)
+	s.Empty(dropped)
+
+	digest := notificationDigest(pending, now.Add(time.Minute*10))
+	s.Equal(4, digest.Matched)
+	s.Equal(now, digest.From)
+	s.Equal(now.Add(time.Minute*10), digest.To)
+	s.Equal([]*domain.ProfitableChain{high.Chain}, digest.Chains)
+	s.Equal([]*domain.Spread{spread}, digest.Spreads)
+}
+
+func (s *policyTestSuite) Test_Due_PostponedByQuietHours() {
+	p := newDeliveryPolicies(&mocks.DeliveryPolicyStorage{})
+	now := time.Date(2022, 10, 24, 22, 0, 0, 0, time.UTC)
+	subs := &domain.Subscription{Id: kit.NewId(), Policy: &domain.SubscriptionDeliveryPolicy{
+		QuietHours: &domain.SubscriptionQuietHours{From: "22:30", To: "07:00"},
+		Digest:     &domain.SubscriptionDigest{PeriodMin: 60},
+	}}
+	pending := []*domain.PendingDigest{{Id: kit.NewId(), SubscriptionId: subs.Id, Matched: 1, From: now}}
+	due, dropped := p.due(pending, []*domain.Subscription{subs}, now.Add(time.Hour))
+	s.Empty(due)
+	s.Empty(dropped)
+	due, _ = p.due(pending, []*domain.Subscription{subs}, now.Add(time.Hour*9))
+	s.Len(due, 1)
+}
+
+func (s *policyTestSuite) Test_Due_DroppedWhenSubscriptionInactive() {
+	p := newDeliveryPolicies(&mocks.DeliveryPolicyStorage{})
+	now := kit.Now()
+	noDigest := &domain.Subscription{Id: kit.NewId(), Policy: &domain.SubscriptionDeliveryPolicy{MaxMessages: 1, WindowSec: 60}}
+	pending := []*domain.PendingDigest{
+		{Id: kit.NewId(), SubscriptionId: kit.NewId(), Matched: 1, From: now},
+		{Id: kit.NewId(), SubscriptionId: noDigest.Id, Matched: 1, From: now},
+	}
+	due, dropped := p.due(pending, []*domain.Subscription{noDigest}, now.Add(time.Hour))
+	s.Empty(due)
+	s.Equal(pending, dropped)
+}
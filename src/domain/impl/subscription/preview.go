@@ -0,0 +1,158 @@
+package subscription
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	defaultPreviewHours   = 24
+	maxPreviewHours       = 168
+	defaultPreviewSamples = 5
+	maxPreviewSamples     = 50
+	// maxPreviewChains max number of chains taken from each storage, so that preview doesn't load the whole history
+	maxPreviewChains = 5000
+)
+
+func (s *subscriptionSvcImpl) Preview(ctx context.Context, rq *domain.SubscriptionPreviewRequest) (*domain.SubscriptionPreview, error) {
+	l := s.l().C(ctx).Mth("preview").F(log.FF{"userId": rq.UserId}).Trc()
+
+	hours := rq.Hours
+	if hours == 0 {
+		hours = defaultPreviewHours
+	}
+	if hours < 0 || hours > maxPreviewHours {
+		return nil, errors.ErrSubscriptionPreviewHoursInvalid(ctx, maxPreviewHours)
+	}
+	samples := rq.Samples
+	if samples == 0 {
+		samples = defaultPreviewSamples
+	}
+	if samples < 0 || samples > maxPreviewSamples {
+		return nil, errors.ErrSubscriptionPreviewSamplesInvalid(ctx, maxPreviewSamples)
+	}
+	filter := rq.Filter
+	if filter == nil {
+		filter = &domain.SubscriptionChainFilter{}
+	}
+	if err := validateFilter(ctx, filter, filterBaseCurrency(s.cfg)); err != nil {
+		return nil, err
+	}
+
+	// the current hour is the last one
+	to := kit.Now()
+	from := to.Truncate(time.Hour).Add(-time.Duration(hours-1) * time.Hour)
+	chains, truncated, err := s.chainHistory(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &domain.SubscriptionPreview{
+		From:      from,
+		To:        to,
+		Scanned:   len(chains),
+		Truncated: truncated,
+	}
+	for i := 0; i < hours; i++ {
+		r.Hours = append(r.Hours, &domain.SubscriptionPreviewHour{Hour: from.Add(time.Duration(i) * time.Hour)})
+	}
+	var matched []*domain.ProfitableChain
+	for _, chain := range chains {
+		if r.HistoryFrom == nil || chain.CreatedAt.Before(*r.HistoryFrom) {
+			createdAt := chain.CreatedAt
+			r.HistoryFrom = &createdAt
+		}
+		if !matchChain(filter, chain) {
+			continue
+		}
+		matched = append(matched, chain)
+		r.Hours[int(chain.CreatedAt.Sub(from)/time.Hour)].Matches++
+	}
+	r.Matches = len(matched)
+
+	// if the history is shorter than the period, matches are extrapolated from the history
+	covered := to.Sub(from)
+	if r.HistoryFrom != nil && r.HistoryFrom.After(from) {
+		covered = to.Sub(*r.HistoryFrom)
+	}
+	if covered < time.Hour {
+		covered = time.Hour
+	}
+	r.EstimatedDaily = math.Round(float64(r.Matches)*float64(24*time.Hour)/float64(covered)*10) / 10
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].ProfitShare > matched[j].ProfitShare
+	})
+	if len(matched) > samples {
+		matched = matched[:samples]
+	}
+	r.Samples = matched
+
+	l.F(log.FF{"scanned": r.Scanned, "matches": r.Matches}).Dbg()
+	return r, nil
+}
+
+// chainHistory retrieves chains created within [from, to) from the hot storage and the archive
+// chains are archived in advance, so the same chain might be found in both storages
+func (s *subscriptionSvcImpl) chainHistory(ctx context.Context, from, to time.Time) ([]*domain.ProfitableChain, bool, error) {
+	hot, truncated, err := s.hotChainHistory(ctx, from)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var archived []*domain.ProfitableChain
+	if s.archiveEnabled() {
+		archived, err = s.archive.GetArchivedChains(ctx, from, to, maxPreviewChains)
+		if err != nil {
+			return nil, false, err
+		}
+		truncated = truncated || len(archived) >= maxPreviewChains
+	}
+
+	var r []*domain.ProfitableChain
+	found := make(map[string]struct{}, len(hot)+len(archived))
+	for _, chains := range [][]*domain.ProfitableChain{hot, archived} {
+		for _, chain := range chains {
+			if _, ok := found[chain.Id]; ok || chain.CreatedAt.Before(from) || !chain.CreatedAt.Before(to) {
+				continue
+			}
+			found[chain.Id] = struct{}{}
+			r = append(r, chain)
+		}
+	}
+	return r, truncated, nil
+}
+
+// hotChainHistory retrieves chains created since from the hot storage page by page up to maxPreviewChains
+func (s *subscriptionSvcImpl) hotChainHistory(ctx context.Context, from time.Time) ([]*domain.ProfitableChain, bool, error) {
+	createdAfter := from.Add(-time.Nanosecond)
+	rq := &domain.GetProfitableChainsRequest{
+		PagingRequest: kit.PagingRequest{Size: domain.MaxChainsPageSize},
+		CreatedAfter:  &createdAfter,
+	}
+	var r []*domain.ProfitableChain
+	for {
+		rs, err := s.chains.GetProfitableChains(ctx, rq)
+		if err != nil {
+			return nil, false, err
+		}
+		r = append(r, rs.Chains...)
+		if rs.NextCursor == "" {
+			return r, false, nil
+		}
+		if len(r) >= maxPreviewChains {
+			return r, true, nil
+		}
+		rq.Cursor = rs.NextCursor
+	}
+}
+
+func (s *subscriptionSvcImpl) archiveEnabled() bool {
+	return s.cfg != nil && s.cfg.Retention != nil && s.cfg.Retention.Archive != nil && s.cfg.Retention.Archive.Enabled
+}
@@ -5,21 +5,47 @@ import (
 	"github.com/mikhailbolshakov/cryptocare/src/domain"
 	"github.com/mikhailbolshakov/cryptocare/src/errors"
 	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/expr"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/goroutine"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
 	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"go.uber.org/atomic"
+	"math"
 	"strings"
+	"time"
 )
 
 type subscriptionSvcImpl struct {
-	storage          domain.SubscriptionStorage
-	telegramNotifier domain.TelegramNotifier
-	cfg              *service.Config
+	storage    domain.SubscriptionStorage
+	channels   domain.NotificationChannelRegistry
+	outbox     domain.NotificationOutbox
+	renderer   domain.NotificationRenderer
+	verifier   domain.TelegramChannelVerifier
+	emails     domain.EmailVerifier
+	bots       domain.TelegramBotRegistry
+	chains     domain.ChainStorage
+	archive    domain.ChainArchiveStorage
+	policies   *deliveryPolicies
+	cfg        *service.Config
+	cancelFunc context.CancelFunc
+	running    *atomic.Bool
 }
 
-func NewSubscriptionService(storage domain.SubscriptionStorage, telegramNotifier domain.TelegramNotifier) domain.SubscriptionService {
+func NewSubscriptionService(storage domain.SubscriptionStorage, channels domain.NotificationChannelRegistry, outbox domain.NotificationOutbox, renderer domain.NotificationRenderer,
+	verifier domain.TelegramChannelVerifier, emails domain.EmailVerifier, bots domain.TelegramBotRegistry, chains domain.ChainStorage, archive domain.ChainArchiveStorage,
+	policies domain.DeliveryPolicyStorage) domain.SubscriptionService {
 	return &subscriptionSvcImpl{
-		storage:          storage,
-		telegramNotifier: telegramNotifier,
+		storage:  storage,
+		channels: channels,
+		outbox:   outbox,
+		renderer: renderer,
+		verifier: verifier,
+		emails:   emails,
+		bots:     bots,
+		chains:   chains,
+		archive:  archive,
+		policies: newDeliveryPolicies(policies),
+		running:  atomic.NewBool(false),
 	}
 }
 
@@ -31,43 +57,131 @@ func (s *subscriptionSvcImpl) Init(cfg *service.Config) {
 	s.cfg = cfg
 }
 
-func (s *subscriptionSvcImpl) validateAndPopulate(ctx context.Context, subscription *domain.Subscription) error {
-
-	// validate and populate filters
-	if subscription.Filter == nil {
-		subscription.Filter = &domain.SubscriptionChainFilter{}
+// validateFilter validates and normalizes the filter
+func validateFilter(ctx context.Context, filter *domain.SubscriptionChainFilter, baseCurrency string) error {
+	for i, exchange := range filter.Exchanges {
+		filter.Exchanges[i] = strings.ToLower(strings.TrimSpace(exchange))
 	}
-
-	for i, exchange := range subscription.Filter.Exchanges {
-		subscription.Filter.Exchanges[i] = strings.ToLower(strings.TrimSpace(exchange))
-	}
-	for i, m := range subscription.Filter.Methods {
-		subscription.Filter.Methods[i] = strings.TrimSpace(m)
+	for i, m := range filter.Methods {
+		filter.Methods[i] = strings.TrimSpace(m)
 	}
-	for i, a := range subscription.Filter.Assets {
-		subscription.Filter.Assets[i] = strings.ToUpper(strings.TrimSpace(a))
+	for i, a := range filter.Assets {
+		filter.Assets[i] = strings.ToUpper(strings.TrimSpace(a))
 	}
 
-	if subscription.Filter.MinProfit != 0.0 && (subscription.Filter.MinProfit < 0.0001 || subscription.Filter.MinProfit > 99.9999) {
+	if filter.MinProfit != 0.0 && (filter.MinProfit < 0.0001 || filter.MinProfit > 99.9999) {
 		return errors.ErrSubscriptionMinProfitInvalid(ctx)
 	}
-	if subscription.Filter.MaxDepth != 0 && subscription.Filter.MaxDepth < 2 {
+	if filter.MaxDepth != 0 && filter.MaxDepth < 2 {
 		return errors.ErrSubscriptionMaxDepthInvalid(ctx)
 	}
+	for i, o := range filter.Opportunities {
+		o = strings.ToLower(strings.TrimSpace(o))
+		if o != domain.OpportunityTypeChain && o != domain.OpportunityTypeSpread {
+			return errors.ErrSubscriptionOpportunityInvalid(ctx, o)
+		}
+		filter.Opportunities[i] = o
+	}
+	filter.Opportunities = kit.Strings(filter.Opportunities).Distinct()
+	return validateFilterExpression(ctx, filter, baseCurrency)
+}
+
+func (s *subscriptionSvcImpl) validateAndPopulate(ctx context.Context, subscription *domain.Subscription) error {
+
+	// validate and populate filters
+	if subscription.Filter == nil {
+		subscription.Filter = &domain.SubscriptionChainFilter{}
+	}
+	if err := validateFilter(ctx, subscription.Filter, filterBaseCurrency(s.cfg)); err != nil {
+		return err
+	}
 
 	for _, notify := range subscription.Notifications {
-		if notify.Channel != domain.SubscriptionNotificationChannelTelegram {
+		channel, ok := s.channels.Get(notify.Channel)
+		if !ok {
 			return errors.ErrSubscriptionNotificationChannelNotSupported(ctx, notify.Channel)
 		}
 		if notify.Id == "" {
 			notify.Id = kit.NewRandString()
 		}
-		if notify.Telegram == nil || notify.Telegram.Channel == 0 {
-			return errors.ErrSubscriptionNotificationTelegramInvalid(ctx)
+		if err := channel.Validate(ctx, notify); err != nil {
+			return err
+		}
+		if err := s.renderer.Validate(ctx, notify.Channel, notify.Templates); err != nil {
+			return err
+		}
+		// telegram channels must be verified by the user, otherwise notification stays inactive
+		if notify.Channel == domain.SubscriptionNotificationChannelTelegram {
+			// the user is allowed to select shared bots and bots assigned to the user
+			if notify.Telegram.BotId != "" {
+				if err := s.bots.Validate(ctx, subscription.UserId, notify.Telegram.BotId); err != nil {
+					return err
+				}
+			}
+			verified, err := s.verifier.IsVerified(ctx, subscription.UserId, int64(notify.Telegram.Channel))
+			if err != nil {
+				return err
+			}
+			notify.Telegram.Verified = verified
+			notify.Telegram.AwaitingVerification = !verified && notify.IsActive
+			if !verified {
+				notify.IsActive = false
+			}
+		}
+		// email recipients must be confirmed, otherwise the service could be used to spam arbitrary addresses
+		if notify.Channel == domain.SubscriptionNotificationChannelEmail {
+			verified, err := s.emails.IsVerified(ctx, subscription.UserId, notify.Email.To)
+			if err != nil {
+				return err
+			}
+			notify.Email.Verified = verified
+			notify.Email.AwaitingVerification = !verified && notify.IsActive
+			if !verified {
+				notify.IsActive = false
+			}
 		}
 	}
 
-	return nil
+	return validatePolicy(ctx, subscription.Policy)
+}
+
+// matchOpportunity checks if the filter accepts opportunities of the given type
+// chains are accepted if opportunities aren't specified
+func matchOpportunity(filter *domain.SubscriptionChainFilter, opportunity string) bool {
+	if len(filter.Opportunities) == 0 {
+		return opportunity == domain.OpportunityTypeChain
+	}
+	return kit.Strings(filter.Opportunities).Contains(opportunity)
+}
+
+// matchChain checks if chain satisfies the filter
+func matchChain(filter *domain.SubscriptionChainFilter, chain *domain.ProfitableChain) bool {
+	if filter == nil {
+		return true
+	}
+	filterMethods := kit.Strings(filter.Methods).Sanitize()
+	return matchOpportunity(filter, domain.OpportunityTypeChain) &&
+		(len(filter.Exchanges) == 0 || kit.Strings(chain.ExchangeCodes).Subset(filter.Exchanges)) &&
+		(len(filter.Assets) == 0 || kit.Strings(filter.Assets).Contains(chain.Asset)) &&
+		(len(filterMethods) == 0 || kit.Strings(chain.Methods).Sanitize().Subset(filterMethods)) &&
+		(filter.MaxDepth == 0 || chain.Depth <= filter.MaxDepth) &&
+		(filter.MinProfit == 0.0 || chain.ProfitShare >= 1+filter.MinProfit*0.01) &&
+		matchExpression(filter, func() expr.Values { return chainValues(chain) })
+}
+
+// matchSpread checks if spread satisfies the filter
+// spread matches assets if either base or quote asset is among filter assets, max depth isn't applicable to spreads
+func matchSpread(filter *domain.SubscriptionChainFilter, spread *domain.Spread) bool {
+	if filter == nil {
+		return false
+	}
+	filterMethods := kit.Strings(filter.Methods).Sanitize()
+	return matchOpportunity(filter, domain.OpportunityTypeSpread) &&
+		(len(filter.Exchanges) == 0 || kit.Strings(spread.ExchangeCodes).Subset(filter.Exchanges)) &&
+		(len(filter.Assets) == 0 || kit.Strings(filter.Assets).Contains(spread.BaseAsset) || kit.Strings(filter.Assets).Contains(spread.QuoteAsset)) &&
+		(len(filterMethods) == 0 || kit.Strings(spread.Methods).Sanitize().Subset(filterMethods)) &&
+		(filter.MinProfit == 0.0 || spread.SpreadShare >= 1+filter.MinProfit*0.01) &&
+		matchExpression(filter, func() expr.Values { return spreadValues(spread) })
 }
 
 func (s *subscriptionSvcImpl) Create(ctx context.Context, subscription *domain.Subscription) (*domain.Subscription, error) {
@@ -106,12 +220,13 @@ func (s *subscriptionSvcImpl) Update(ctx context.Context, subscription *domain.S
 		return nil, errors.ErrSubscriptionNotActive(ctx)
 	}
 
+	subscription.UserId = stored.UserId
+
 	err = s.validateAndPopulate(ctx, subscription)
 	if err != nil {
 		return nil, err
 	}
 
-	subscription.UserId = stored.UserId
 	subscription.IsActive = true
 
 	err = s.storage.SaveSubscription(ctx, subscription)
@@ -174,46 +289,284 @@ func (s *subscriptionSvcImpl) Deactivate(ctx context.Context, subscriptionId str
 	return subscription, nil
 }
 
+func (s *subscriptionSvcImpl) Activate(ctx context.Context, subscriptionId string) (*domain.Subscription, error) {
+	s.l().C(ctx).Mth("activate").F(log.FF{"subscriptionId": subscriptionId}).Trc()
+
+	if subscriptionId == "" {
+		return nil, errors.ErrSubscriptionIdEmpty(ctx)
+	}
+
+	subscription, err := s.storage.GetSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, errors.ErrSubscriptionNotFound(ctx)
+	}
+	if subscription.IsActive {
+		return nil, errors.ErrSubscriptionAlreadyActive(ctx)
+	}
+
+	subscription.IsActive = true
+
+	err = s.storage.SaveSubscription(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
 func (s *subscriptionSvcImpl) Search(ctx context.Context, rq *domain.SearchSubscriptionsRequest) ([]*domain.Subscription, error) {
 	s.l().C(ctx).Mth("search").Trc()
 	return s.storage.SearchSubscriptions(ctx, rq)
 }
 
+func (s *subscriptionSvcImpl) PreviewTemplate(ctx context.Context, rq *domain.TemplatePreviewRequest) (*domain.RenderedMessage, error) {
+	s.l().C(ctx).Mth("preview-template").Trc()
+	if _, ok := s.channels.Get(rq.Channel); !ok {
+		return nil, errors.ErrSubscriptionNotificationChannelNotSupported(ctx, rq.Channel)
+	}
+	return s.renderer.Preview(ctx, rq)
+}
+
+// deliverable checks the notification is active and its destination is confirmed by the user
+// notifications saved before verification of telegram channels and email recipients was introduced might be active, but they aren't delivered until verified
+func deliverable(notification *domain.SubscriptionNotification) bool {
+	if !notification.IsActive {
+		return false
+	}
+	switch notification.Channel {
+	case domain.SubscriptionNotificationChannelTelegram:
+		return notification.Telegram != nil && notification.Telegram.Verified
+	case domain.SubscriptionNotificationChannelEmail:
+		return notification.Email != nil && notification.Email.Verified
+	}
+	return true
+}
+
+// newDeliveries builds deliveries of the opportunities to deliverable notifications of the subscription
+func newDeliveries(subs *domain.Subscription, opportunities []*domain.OutboxDelivery) []*domain.OutboxDelivery {
+	var r []*domain.OutboxDelivery
+	for _, proto := range opportunities {
+		for _, notification := range subs.Notifications {
+			if !deliverable(notification) {
+				continue
+			}
+			d := *proto
+			d.SubscriptionId = subs.Id
+			d.UserId = subs.UserId
+			d.Notification = notification
+			r = append(r, &d)
+		}
+	}
+	return r
+}
+
+// enqueue matches opportunities with subscriptions, applies delivery policies of subscriptions and enqueues deliveries
+func (s *subscriptionSvcImpl) enqueue(ctx context.Context, subs []*domain.Subscription, opportunities []*domain.OutboxDelivery,
+	match func(subs *domain.Subscription, opportunity *domain.OutboxDelivery) bool) error {
+	l := s.l().C(ctx).Mth("enqueue")
+
+	now := kit.Now()
+	var deliveries []*domain.OutboxDelivery
+	for _, sub := range subs {
+		var matched []*domain.OutboxDelivery
+		for _, o := range opportunities {
+			if match(sub, o) {
+				matched = append(matched, o)
+			}
+		}
+		sent, err := s.policies.apply(ctx, sub, matched, now)
+		if err != nil {
+			return err
+		}
+		deliveries = append(deliveries, newDeliveries(sub, sent)...)
+	}
+	l.DbgF("deliveries: %d", len(deliveries))
+	return s.outbox.Enqueue(ctx, deliveries)
+}
+
+// chainsSearchRequest requests active subscriptions which might match any of the chains, so that others aren't loaded
+func chainsSearchRequest(chains []*domain.ProfitableChain) *domain.SearchSubscriptionsRequest {
+	rq := &domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeChain}
+	assets := make(kit.Strings, 0, len(chains))
+	for _, chain := range chains {
+		assets = append(assets, chain.Asset)
+		rq.ProfitShare = math.Max(rq.ProfitShare, chain.ProfitShare)
+	}
+	rq.Assets = assets.Distinct()
+	return rq
+}
+
+// spreadsSearchRequest requests active subscriptions which might match any of the spreads, so that others aren't loaded
+func spreadsSearchRequest(spreads []*domain.Spread) *domain.SearchSubscriptionsRequest {
+	rq := &domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeSpread}
+	assets := make(kit.Strings, 0, len(spreads)*2)
+	for _, spread := range spreads {
+		assets = append(assets, spread.BaseAsset, spread.QuoteAsset)
+		rq.ProfitShare = math.Max(rq.ProfitShare, spread.SpreadShare)
+	}
+	rq.Assets = assets.Distinct()
+	return rq
+}
+
 func (s *subscriptionSvcImpl) Notify(ctx context.Context, chains []*domain.ProfitableChain) error {
-	l := s.l().C(ctx).Mth("notify").Trc()
+	s.l().C(ctx).Mth("notify").Trc()
 
-	// get active subscriptions
-	subs, err := s.Search(ctx, &domain.SearchSubscriptionsRequest{WithInActive: false})
+	// get active subscriptions which might match the chains
+	subs, err := s.Search(ctx, chainsSearchRequest(chains))
+	if err != nil {
+		return err
+	}
+
+	opportunities := make([]*domain.OutboxDelivery, 0, len(chains))
+	for _, chain := range chains {
+		opportunities = append(opportunities, &domain.OutboxDelivery{OpportunityType: domain.OpportunityTypeChain, OpportunityId: chain.Id, Chain: chain})
+	}
+	return s.enqueue(ctx, subs, opportunities, func(subs *domain.Subscription, o *domain.OutboxDelivery) bool {
+		return matchChain(subs.Filter, o.Chain)
+	})
+}
+
+// NotifyPrivate notifies the owner about chains with their private bids
+// only subscriptions of the owner are matched, so private chains never reach channels of other users
+func (s *subscriptionSvcImpl) NotifyPrivate(ctx context.Context, userId string, chains []*domain.ProfitableChain) error {
+	s.l().C(ctx).Mth("notify-private").F(log.FF{"userId": userId}).Trc()
+
+	// get active subscriptions of the owner which might match the chains
+	rq := chainsSearchRequest(chains)
+	rq.UserId = userId
+	subs, err := s.Search(ctx, rq)
 	if err != nil {
 		return err
 	}
 
-	// go through chains
+	opportunities := make([]*domain.OutboxDelivery, 0, len(chains))
 	for _, chain := range chains {
-		chainMethods := kit.Strings(chain.Methods).Sanitize()
-		// for each subscription
-		var channels []int
-		for _, subs := range subs {
-			subFilterMethods := kit.Strings(subs.Filter.Methods).Sanitize()
-			if (len(subs.Filter.Exchanges) == 0 || kit.Strings(chain.ExchangeCodes).Subset(subs.Filter.Exchanges)) &&
-				(len(subs.Filter.Assets) == 0 || kit.Strings(subs.Filter.Assets).Contains(chain.Asset)) &&
-				(len(subFilterMethods) == 0 || chainMethods.Subset(subFilterMethods)) &&
-				(subs.Filter.MaxDepth == 0 || chain.Depth <= subs.Filter.MaxDepth) &&
-				(subs.Filter.MinProfit == 0.0 || chain.ProfitShare >= 1+subs.Filter.MinProfit*0.01) {
-				// for all notifications
-				for _, notifier := range subs.Notifications {
-					if notifier.IsActive && notifier.Channel == domain.SubscriptionNotificationChannelTelegram {
-						channels = append(channels, notifier.Telegram.Channel)
+		opportunities = append(opportunities, &domain.OutboxDelivery{OpportunityType: domain.OpportunityTypeChain, OpportunityId: chain.Id, Chain: chain})
+	}
+	return s.enqueue(ctx, subs, opportunities, func(subs *domain.Subscription, o *domain.OutboxDelivery) bool {
+		return subs.UserId == userId && matchChain(subs.Filter, o.Chain)
+	})
+}
+
+func (s *subscriptionSvcImpl) NotifySpreads(ctx context.Context, spreads []*domain.Spread) error {
+	s.l().C(ctx).Mth("notify-spreads").Trc()
+
+	// get active subscriptions which might match the spreads
+	subs, err := s.Search(ctx, spreadsSearchRequest(spreads))
+	if err != nil {
+		return err
+	}
+
+	opportunities := make([]*domain.OutboxDelivery, 0, len(spreads))
+	for _, spread := range spreads {
+		opportunities = append(opportunities, &domain.OutboxDelivery{OpportunityType: domain.OpportunityTypeSpread, OpportunityId: spread.Id, Spread: spread})
+	}
+	return s.enqueue(ctx, subs, opportunities, func(subs *domain.Subscription, o *domain.OutboxDelivery) bool {
+		return matchSpread(subs.Filter, o.Spread)
+	})
+}
+
+// sendDigests enqueues digests which are due
+func (s *subscriptionSvcImpl) sendDigests(ctx context.Context, now time.Time) error {
+	l := s.l().C(ctx).Mth("send-digests")
+
+	pending, err := s.policies.storage.GetPendingDigests(ctx)
+	if err != nil {
+		return err
+	}
+	// nothing is collected
+	if len(pending) == 0 {
+		return nil
+	}
+
+	subs, err := s.Search(ctx, &domain.SearchSubscriptionsRequest{WithInActive: false})
+	if err != nil {
+		return err
+	}
+	due, dropped := s.policies.due(pending, subs, now)
+	for _, digest := range dropped {
+		if err := s.policies.storage.DeletePendingDigest(ctx, digest.SubscriptionId, digest.Id); err != nil {
+			return err
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	bySubs := make(map[string]*domain.Subscription, len(subs))
+	for _, sub := range subs {
+		bySubs[sub.Id] = sub
+	}
+	var deliveries []*domain.OutboxDelivery
+	for _, digest := range due {
+		// digest id is the opportunity id, so the digest is delivered once if another instance enqueues it as well
+		proto := &domain.OutboxDelivery{OpportunityType: domain.DeliveryTypeDigest, OpportunityId: digest.Id, Digest: notificationDigest(digest, now)}
+		deliveries = append(deliveries, newDeliveries(bySubs[digest.SubscriptionId], []*domain.OutboxDelivery{proto})...)
+	}
+	l.DbgF("digests: %d, deliveries: %d", len(due), len(deliveries))
+	if err := s.outbox.Enqueue(ctx, deliveries); err != nil {
+		return err
+	}
+
+	// digests are deleted once they are enqueued, so they aren't lost if enqueuing fails
+	for _, digest := range due {
+		if err := s.policies.storage.DeletePendingDigest(ctx, digest.SubscriptionId, digest.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *subscriptionSvcImpl) Run(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("run").Trc()
+
+	// check running
+	if s.running.Load() {
+		return errors.ErrSubscriptionDigestAlreadyRun(ctx)
+	}
+
+	ctx, s.cancelFunc = context.WithCancel(ctx)
+	s.running.Store(true)
+
+	goroutine.New().
+		WithLogger(s.l().C(ctx).Mth("digests")).
+		WithRetry(goroutine.Unrestricted).
+		WithRetryDelay(time.Second*10).
+		Go(ctx, func() {
+			ticker := time.NewTicker(digestCheckPeriod)
+			defer ticker.Stop()
+			cleanup := time.NewTicker(throttleCleanupPeriod)
+			defer cleanup.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := s.sendDigests(ctx, kit.Now()); err != nil {
+						s.l().C(ctx).Mth("digests").E(err).Err()
+					}
+				case <-cleanup.C:
+					if err := s.policies.forget(ctx, kit.Now()); err != nil {
+						s.l().C(ctx).Mth("throttling").E(err).Err()
 					}
+				case <-ctx.Done():
+					return
 				}
 			}
-		}
-		if len(channels) > 0 {
-			l.DbgF("channels: %s", channels)
-			if err := s.telegramNotifier.Notify(ctx, s.cfg.Arbitrage.Notification.Telegram.Bot, channels, []*domain.ProfitableChain{chain}); err != nil {
-				s.l().C(ctx).Mth("notify").E(err).St().Err()
-			}
-		}
+		})
+
+	l.Inf("ok")
+	return nil
+}
+
+func (s *subscriptionSvcImpl) Stop(ctx context.Context) error {
+	l := s.l().C(ctx).Mth("stop").Trc()
+	// cancel if running
+	if s.cancelFunc != nil && s.running.Load() {
+		s.cancelFunc()
+		s.running.Store(false)
+		s.cancelFunc = nil
+		l.Inf("ok")
 	}
 	return nil
 }
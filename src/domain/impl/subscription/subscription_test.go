@@ -1,14 +1,19 @@
 package subscription
 
 import (
+	"context"
+	"fmt"
 	"github.com/mikhailbolshakov/cryptocare/src/domain"
 	"github.com/mikhailbolshakov/cryptocare/src/errors"
 	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
 	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
 	"github.com/mikhailbolshakov/cryptocare/src/mocks"
 	"github.com/mikhailbolshakov/cryptocare/src/service"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -17,6 +22,13 @@ type subscriptionTestSuite struct {
 	kitTestSuite.Suite
 	storage  *mocks.SubscriptionStorage
 	notifier *mocks.TelegramNotifier
+	outbox   *mocks.NotificationOutbox
+	verifier *mocks.TelegramChannelVerifier
+	emails   *mocks.EmailVerifier
+	bots     *mocks.TelegramBotRegistry
+	chains   *mocks.ChainStorage
+	archive  *mocks.ChainArchiveStorage
+	policies *mocks.DeliveryPolicyStorage
 	svc      domain.SubscriptionService
 }
 
@@ -31,10 +43,34 @@ func TestSubscriptionTestSuiteSuite(t *testing.T) {
 func (s *subscriptionTestSuite) SetupTest() {
 	s.storage = &mocks.SubscriptionStorage{}
 	s.notifier = &mocks.TelegramNotifier{}
-	s.svc = NewSubscriptionService(s.storage, s.notifier)
+	s.outbox = &mocks.NotificationOutbox{}
+	s.verifier = &mocks.TelegramChannelVerifier{}
+	s.verifier.On("IsVerified", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(true, nil)
+	s.emails = &mocks.EmailVerifier{}
+	s.bots = &mocks.TelegramBotRegistry{}
+	s.chains = &mocks.ChainStorage{}
+	s.archive = &mocks.ChainArchiveStorage{}
+	s.policies = &mocks.DeliveryPolicyStorage{}
+	renderer := NewNotificationRenderer()
+	s.svc = NewSubscriptionService(s.storage, NewNotificationChannelRegistry(
+		NewTelegramChannel(s.notifier, &mocks.TelegramAlerts{}, s.bots, renderer),
+		NewEmailChannel(&mocks.Email{}, renderer, &EmailOptions{From: "noreply@cryptocare.ai"}),
+		NewWebhookChannel(renderer, &WebhookOptions{}),
+	), s.outbox, renderer, s.verifier, s.emails, s.bots, s.chains, s.archive, s.policies)
 	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Depth: 5, MinProfit: 1.0005}})
 }
 
+// expectDeliveries collects deliveries enqueued to the outbox
+func (s *subscriptionTestSuite) expectDeliveries() *[]*domain.OutboxDelivery {
+	deliveries := &[]*domain.OutboxDelivery{}
+	s.outbox.On("Enqueue", s.Ctx, mock.AnythingOfType("[]*domain.OutboxDelivery")).
+		Run(func(args mock.Arguments) {
+			*deliveries = append(*deliveries, args.Get(1).([]*domain.OutboxDelivery)...)
+		}).
+		Return(nil)
+	return deliveries
+}
+
 func (s *subscriptionTestSuite) getSubscription() *domain.Subscription {
 	return &domain.Subscription{
 		Id:       kit.NewId(),
@@ -53,7 +89,8 @@ func (s *subscriptionTestSuite) getSubscription() *domain.Subscription {
 				Channel:  domain.SubscriptionNotificationChannelTelegram,
 				IsActive: true,
 				Telegram: &domain.SubscriptionTelegramNotificationDetails{
-					Channel: -124125123515,
+					Channel:  -124125123515,
+					Verified: true,
 				},
 			},
 		},
@@ -86,6 +123,39 @@ func (s *subscriptionTestSuite) Test_ValidateAndPopulate_WhenMinProfitInvalid_Fa
 	s.Nil(err)
 }
 
+func (s *subscriptionTestSuite) Test_ValidateAndPopulate_Expression() {
+	subs := s.getSubscription()
+	subs.Filter.Expression = "  profit >= 1.5 && !(\"bybit\" in exchanges) && volumeUsd > 500 "
+	s.Nil(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs))
+	s.Equal("profit >= 1.5 && !(\"bybit\" in exchanges) && volumeUsd > 500", subs.Filter.Expression)
+
+	subs.Filter.Expression = "profit >= 1.5 && volumeRub > 500"
+	err := s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs)
+	s.AssertAppErr(err, errors.ErrCodeSubscriptionFilterExpressionInvalid)
+	appErr, _ := er.Is(err)
+	s.Equal("filter expression invalid at position 18: unknown variable 'volumeRub'", appErr.Message())
+	s.Equal(18, appErr.Fields()["position"])
+
+	subs.Filter.Expression = strings.Repeat("x", maxFilterExpressionLength+1)
+	s.AssertAppErr(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs), errors.ErrCodeSubscriptionFilterExpressionInvalid)
+}
+
+func (s *subscriptionTestSuite) Test_ValidateAndPopulate_Expression_WhenBaseCurrencyNotUsd_UsdAmountsRejected() {
+	s.svc.Init(&service.Config{
+		Arbitrage: &service.Arbitrage{Depth: 5, MinProfit: 1.0005},
+		Market:    &service.Market{ReferenceRates: &service.ReferenceRates{Base: "eur"}},
+	})
+	subs := s.getSubscription()
+	subs.Filter.Expression = "profit >= 1.5 && volumeUsd > 500"
+	err := s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs)
+	s.AssertAppErr(err, errors.ErrCodeSubscriptionFilterExpressionInvalid)
+	appErr, _ := er.Is(err)
+	s.Equal("filter expression invalid at position 18: unknown variable 'volumeUsd'", appErr.Message())
+
+	subs.Filter.Expression = "profit >= 1.5 && baseVolume > 500"
+	s.Nil(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs))
+}
+
 func (s *subscriptionTestSuite) Test_ValidateAndPopulate_WhenMaxDepthInvalid_Fail() {
 	subs := s.getSubscription()
 	subs.Filter.MaxDepth = 1
@@ -99,6 +169,17 @@ func (s *subscriptionTestSuite) Test_ValidateAndPopulate_WhenMaxDepthInvalid_Fai
 	s.Nil(err)
 }
 
+func (s *subscriptionTestSuite) Test_ValidateAndPopulate_Opportunities() {
+	subs := s.getSubscription()
+	subs.Filter.Opportunities = []string{" Spread ", "chain", "spread"}
+	err := s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs)
+	s.Nil(err)
+	s.Equal([]string{domain.OpportunityTypeSpread, domain.OpportunityTypeChain}, subs.Filter.Opportunities)
+	subs.Filter.Opportunities = []string{"unknown"}
+	err = s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs)
+	s.AssertAppErr(err, errors.ErrCodeSubscriptionOpportunityInvalid)
+}
+
 func (s *subscriptionTestSuite) Test_ValidateAndPopulate_WhenNotificationInvalid_Fail() {
 	subs := s.getSubscription()
 	subs.Notifications[0].Channel = "unknown"
@@ -110,6 +191,131 @@ func (s *subscriptionTestSuite) Test_ValidateAndPopulate_WhenNotificationInvalid
 	s.AssertAppErr(err, errors.ErrCodeSubscriptionNotificationTelegramInvalid)
 }
 
+func (s *subscriptionTestSuite) Test_ValidateAndPopulate_TelegramChannelVerification() {
+	subs := s.getSubscription()
+	err := s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs)
+	s.Nil(err)
+	s.True(subs.Notifications[0].Telegram.Verified)
+	s.True(subs.Notifications[0].IsActive)
+
+	// notification to unverified channel is inactive
+	s.verifier.ExpectedCalls = nil
+	s.verifier.On("IsVerified", s.Ctx, subs.UserId, int64(-124125123515)).Return(false, nil)
+	subs.Notifications[0].Telegram.Verified = true
+	err = s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs)
+	s.Nil(err)
+	s.False(subs.Notifications[0].Telegram.Verified)
+	s.False(subs.Notifications[0].IsActive)
+	s.True(subs.Notifications[0].Telegram.AwaitingVerification)
+
+	// notification deactivated by the user isn't activated on verification
+	err = s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs)
+	s.Nil(err)
+	s.False(subs.Notifications[0].IsActive)
+	s.False(subs.Notifications[0].Telegram.AwaitingVerification)
+}
+
+func (s *subscriptionTestSuite) Test_ValidateAndPopulate_TelegramBot() {
+	subs := s.getSubscription()
+	subs.Notifications[0].Telegram.BotId = "partner"
+	s.bots.On("Validate", s.Ctx, subs.UserId, "partner").Return(nil).Once()
+	s.Nil(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs))
+	s.Equal("partner", subs.Notifications[0].Telegram.BotId)
+
+	// the user isn't allowed to select the bot
+	s.bots.On("Validate", s.Ctx, subs.UserId, "partner").Return(errors.ErrTelegramBotNotAllowed(s.Ctx, "partner"))
+	s.AssertAppErr(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs), errors.ErrCodeTelegramBotNotAllowed)
+}
+
+func (s *subscriptionTestSuite) Test_ValidateAndPopulate_Email() {
+	subs := s.getSubscription()
+	subs.Notifications[0] = &domain.SubscriptionNotification{
+		Channel:  domain.SubscriptionNotificationChannelEmail,
+		IsActive: true,
+		Email:    &domain.SubscriptionEmailNotificationDetails{To: []string{" A@Example.com ", "John <a@example.com>", "b@example.com"}},
+	}
+	s.emails.On("IsVerified", s.Ctx, subs.UserId, []string{"a@example.com", "b@example.com"}).Return(false, nil).Once()
+	s.Nil(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs))
+	s.NotEmpty(subs.Notifications[0].Id)
+	s.Equal([]string{"a@example.com", "b@example.com"}, subs.Notifications[0].Email.To)
+	// recipients aren't confirmed, the notification is inactive until they are
+	s.False(subs.Notifications[0].IsActive)
+	s.False(subs.Notifications[0].Email.Verified)
+	s.True(subs.Notifications[0].Email.AwaitingVerification)
+
+	// all recipients confirmed
+	s.emails.On("IsVerified", s.Ctx, subs.UserId, []string{"a@example.com", "b@example.com"}).Return(true, nil)
+	subs.Notifications[0].IsActive = true
+	s.Nil(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs))
+	s.True(subs.Notifications[0].IsActive)
+	s.True(subs.Notifications[0].Email.Verified)
+	s.False(subs.Notifications[0].Email.AwaitingVerification)
+
+	subs.Notifications[0].Email.To = nil
+	s.AssertAppErr(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs), errors.ErrCodeSubscriptionNotificationEmailInvalid)
+	subs.Notifications[0].Email.To = []string{"not an address"}
+	s.AssertAppErr(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs), errors.ErrCodeSubscriptionNotificationEmailInvalid)
+	subs.Notifications[0].Email = nil
+	s.AssertAppErr(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs), errors.ErrCodeSubscriptionNotificationEmailInvalid)
+}
+
+func (s *subscriptionTestSuite) Test_ValidateAndPopulate_Webhook() {
+	subs := s.getSubscription()
+	subs.Notifications[0] = &domain.SubscriptionNotification{
+		Channel:  domain.SubscriptionNotificationChannelWebhook,
+		IsActive: true,
+		Webhook:  &domain.SubscriptionWebhookNotificationDetails{Url: "https://example.com/hook"},
+	}
+	s.Nil(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs))
+	// secret is generated
+	s.NotEmpty(subs.Notifications[0].Webhook.Secret)
+
+	tests := []*domain.SubscriptionWebhookNotificationDetails{
+		{Url: ""},
+		{Url: "http://example.com/hook"},
+		{Url: "https:///hook"},
+		{Url: "https://example.com/hook", Secret: "short"},
+		{Url: "https://127.0.0.1/hook"},
+		{Url: "https://localhost:8443/hook"},
+		{Url: "https://10.1.2.3/hook"},
+		{Url: "https://169.254.169.254/latest/meta-data"},
+		{Url: "https://[::1]/hook"},
+		{Url: "https://[::ffff:192.168.0.1]/hook"},
+	}
+	for _, tt := range tests {
+		subs.Notifications[0].Webhook = tt
+		s.AssertAppErr(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs), errors.ErrCodeSubscriptionNotificationWebhookInvalid)
+	}
+}
+
+func (s *subscriptionTestSuite) Test_Notify_DeliveryPerActiveNotification() {
+	chain := &domain.ProfitableChain{Id: kit.NewId(), Asset: "RUB", ProfitShare: 1.2, Methods: []string{"M1"}, Depth: 2, ExchangeCodes: []string{"binance"}}
+	sub := s.getSubscription()
+	sub.Notifications = []*domain.SubscriptionNotification{
+		{Id: "e1", Channel: domain.SubscriptionNotificationChannelEmail, IsActive: true, Email: &domain.SubscriptionEmailNotificationDetails{To: []string{"a@example.com"}, Verified: true}},
+		{Id: "e2", Channel: domain.SubscriptionNotificationChannelEmail, IsActive: false, Email: &domain.SubscriptionEmailNotificationDetails{To: []string{"b@example.com"}, Verified: true}},
+		{Id: "w1", Channel: domain.SubscriptionNotificationChannelWebhook, IsActive: true, Webhook: &domain.SubscriptionWebhookNotificationDetails{Url: "https://example.com"}},
+		// active, but the channel isn't verified (e.g. saved before verification was introduced)
+		{Id: "t1", Channel: domain.SubscriptionNotificationChannelTelegram, IsActive: true, Telegram: &domain.SubscriptionTelegramNotificationDetails{Channel: -100}},
+		// active, but recipients aren't confirmed
+		{Id: "e3", Channel: domain.SubscriptionNotificationChannelEmail, IsActive: true, Email: &domain.SubscriptionEmailNotificationDetails{To: []string{"c@example.com"}}},
+	}
+	s.storage.On("SearchSubscriptions", s.Ctx, mock.AnythingOfType("*domain.SearchSubscriptionsRequest")).Return([]*domain.Subscription{sub}, nil)
+	deliveries := s.expectDeliveries()
+
+	s.Nil(s.svc.Notify(s.Ctx, []*domain.ProfitableChain{chain}))
+	s.Len(*deliveries, 2)
+	for i, n := range []*domain.SubscriptionNotification{sub.Notifications[0], sub.Notifications[2]} {
+		d := (*deliveries)[i]
+		s.Equal(n, d.Notification)
+		s.Equal(sub.Id, d.SubscriptionId)
+		s.Equal(sub.UserId, d.UserId)
+		s.Equal(domain.OpportunityTypeChain, d.OpportunityType)
+		s.Equal(chain.Id, d.OpportunityId)
+		s.Equal(chain, d.Chain)
+	}
+}
+
 func (s *subscriptionTestSuite) Test_Create_Ok() {
 	subs := s.getSubscription()
 	var actual *domain.Subscription
@@ -164,19 +370,13 @@ func (s *subscriptionTestSuite) Test_Notify_OneChainOneSubscriptionMatch_Ok() {
 	sub1.Filter.MaxDepth = 5
 	sub1.Filter.MinProfit = 1
 	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{Telegram: &service.ArbitrageNotificationTelegram{Bot: "bot"}}}})
-	var actualChannels []int
-	var actualChains []*domain.ProfitableChain
-	s.notifier.On("Notify", s.Ctx, mock.AnythingOfType("string"), mock.AnythingOfType("[]int"), mock.AnythingOfType("[]*domain.ProfitableChain")).
-		Run(func(args mock.Arguments) {
-			actualChannels = append(actualChannels, args.Get(2).([]int)...)
-			actualChains = append(actualChains, args.Get(3).([]*domain.ProfitableChain)...)
-		}).
-		Return(nil)
+	deliveries := s.expectDeliveries()
 	s.storage.On("SearchSubscriptions", s.Ctx, mock.AnythingOfType("*domain.SearchSubscriptionsRequest")).Return([]*domain.Subscription{sub1}, nil)
 	err := s.svc.Notify(s.Ctx, chains)
 	s.Nil(err)
-	s.Equal(len(actualChannels), 1)
-	s.Equal(len(actualChains), 1)
+	s.Len(*deliveries, 1)
+	s.Equal(chains[0], (*deliveries)[0].Chain)
+	s.Equal(sub1.Notifications[0], (*deliveries)[0].Notification)
 }
 
 func (s *subscriptionTestSuite) Test_Notify_OneChainOneSubscriptionDoesntMatchByAsset_Ok() {
@@ -198,19 +398,11 @@ func (s *subscriptionTestSuite) Test_Notify_OneChainOneSubscriptionDoesntMatchBy
 	sub1.Filter.MaxDepth = 5
 	sub1.Filter.MinProfit = 1
 	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{Telegram: &service.ArbitrageNotificationTelegram{Bot: "bot"}}}})
-	var actualChannels []int
-	var actualChains []*domain.ProfitableChain
-	s.notifier.On("Notify", s.Ctx, mock.AnythingOfType("string"), mock.AnythingOfType("[]int"), mock.AnythingOfType("[]*domain.ProfitableChain")).
-		Run(func(args mock.Arguments) {
-			actualChannels = append(actualChannels, args.Get(2).([]int)...)
-			actualChains = append(actualChains, args.Get(3).([]*domain.ProfitableChain)...)
-		}).
-		Return(nil)
+	deliveries := s.expectDeliveries()
 	s.storage.On("SearchSubscriptions", s.Ctx, mock.AnythingOfType("*domain.SearchSubscriptionsRequest")).Return([]*domain.Subscription{sub1}, nil)
 	err := s.svc.Notify(s.Ctx, chains)
 	s.Nil(err)
-	s.Empty(actualChannels)
-	s.Empty(actualChains)
+	s.Empty(*deliveries)
 }
 
 func (s *subscriptionTestSuite) Test_Notify_OneChainOneSubscriptionDoesntMatchByMethods_Ok() {
@@ -232,19 +424,11 @@ func (s *subscriptionTestSuite) Test_Notify_OneChainOneSubscriptionDoesntMatchBy
 	sub1.Filter.MaxDepth = 5
 	sub1.Filter.MinProfit = 1
 	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{Telegram: &service.ArbitrageNotificationTelegram{Bot: "bot"}}}})
-	var actualChannels []int
-	var actualChains []*domain.ProfitableChain
-	s.notifier.On("Notify", s.Ctx, mock.AnythingOfType("string"), mock.AnythingOfType("[]int"), mock.AnythingOfType("[]*domain.ProfitableChain")).
-		Run(func(args mock.Arguments) {
-			actualChannels = append(actualChannels, args.Get(2).([]int)...)
-			actualChains = append(actualChains, args.Get(3).([]*domain.ProfitableChain)...)
-		}).
-		Return(nil)
+	deliveries := s.expectDeliveries()
 	s.storage.On("SearchSubscriptions", s.Ctx, mock.AnythingOfType("*domain.SearchSubscriptionsRequest")).Return([]*domain.Subscription{sub1}, nil)
 	err := s.svc.Notify(s.Ctx, chains)
 	s.Nil(err)
-	s.Empty(actualChannels)
-	s.Empty(actualChains)
+	s.Empty(*deliveries)
 }
 
 func (s *subscriptionTestSuite) Test_Notify_OneChainOneSubscriptionDoesntMatchByMinProfit_Ok() {
@@ -266,19 +450,11 @@ func (s *subscriptionTestSuite) Test_Notify_OneChainOneSubscriptionDoesntMatchBy
 	sub1.Filter.MaxDepth = 5
 	sub1.Filter.MinProfit = 10
 	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{Telegram: &service.ArbitrageNotificationTelegram{Bot: "bot"}}}})
-	var actualChannels []int
-	var actualChains []*domain.ProfitableChain
-	s.notifier.On("Notify", s.Ctx, mock.AnythingOfType("string"), mock.AnythingOfType("[]int"), mock.AnythingOfType("[]*domain.ProfitableChain")).
-		Run(func(args mock.Arguments) {
-			actualChannels = append(actualChannels, args.Get(2).([]int)...)
-			actualChains = append(actualChains, args.Get(3).([]*domain.ProfitableChain)...)
-		}).
-		Return(nil)
+	deliveries := s.expectDeliveries()
 	s.storage.On("SearchSubscriptions", s.Ctx, mock.AnythingOfType("*domain.SearchSubscriptionsRequest")).Return([]*domain.Subscription{sub1}, nil)
 	err := s.svc.Notify(s.Ctx, chains)
 	s.Nil(err)
-	s.Empty(actualChannels)
-	s.Empty(actualChains)
+	s.Empty(*deliveries)
 }
 
 func (s *subscriptionTestSuite) Test_Notify_TwoChainTwoSubscriptionMatch_Ok() {
@@ -313,19 +489,12 @@ func (s *subscriptionTestSuite) Test_Notify_TwoChainTwoSubscriptionMatch_Ok() {
 	sub2.Filter.MaxDepth = 5
 	sub2.Filter.MinProfit = 10
 	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{Telegram: &service.ArbitrageNotificationTelegram{Bot: "bot"}}}})
-	var actualChannels []int
-	var actualChains []*domain.ProfitableChain
-	s.notifier.On("Notify", s.Ctx, mock.AnythingOfType("string"), mock.AnythingOfType("[]int"), mock.AnythingOfType("[]*domain.ProfitableChain")).
-		Run(func(args mock.Arguments) {
-			actualChannels = append(actualChannels, args.Get(2).([]int)...)
-			actualChains = append(actualChains, args.Get(3).([]*domain.ProfitableChain)...)
-		}).
-		Return(nil)
+	deliveries := s.expectDeliveries()
 	s.storage.On("SearchSubscriptions", s.Ctx, mock.AnythingOfType("*domain.SearchSubscriptionsRequest")).Return([]*domain.Subscription{sub1, sub2}, nil)
 	err := s.svc.Notify(s.Ctx, chains)
 	s.Nil(err)
-	s.Equal(len(actualChannels), 4)
-	s.Equal(len(actualChains), 2)
+	// a delivery per chain and subscription
+	s.Len(*deliveries, 4)
 }
 
 func (s *subscriptionTestSuite) Test_Notify_OneChainOneSubscription_Match_MethodsSanitized_Ok() {
@@ -346,17 +515,326 @@ func (s *subscriptionTestSuite) Test_Notify_OneChainOneSubscription_Match_Method
 	sub1.Filter.MaxDepth = 5
 	sub1.Filter.MinProfit = 10
 	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{Telegram: &service.ArbitrageNotificationTelegram{Bot: "bot"}}}})
-	var actualChannels []int
-	var actualChains []*domain.ProfitableChain
-	s.notifier.On("Notify", s.Ctx, mock.AnythingOfType("string"), mock.AnythingOfType("[]int"), mock.AnythingOfType("[]*domain.ProfitableChain")).
-		Run(func(args mock.Arguments) {
-			actualChannels = append(actualChannels, args.Get(2).([]int)...)
-			actualChains = append(actualChains, args.Get(3).([]*domain.ProfitableChain)...)
-		}).
-		Return(nil)
+	deliveries := s.expectDeliveries()
 	s.storage.On("SearchSubscriptions", s.Ctx, mock.AnythingOfType("*domain.SearchSubscriptionsRequest")).Return([]*domain.Subscription{sub1}, nil)
 	err := s.svc.Notify(s.Ctx, chains)
 	s.Nil(err)
-	s.Equal(len(actualChannels), 1)
-	s.Equal(len(actualChains), 1)
+	s.Len(*deliveries, 1)
+	s.Equal(chains[0], (*deliveries)[0].Chain)
+	s.Equal(sub1.Notifications[0], (*deliveries)[0].Notification)
+}
+
+func (s *subscriptionTestSuite) Test_MatchSpread() {
+	spread := &domain.Spread{
+		BaseAsset:     "USDT",
+		QuoteAsset:    "RUB",
+		SpreadShare:   1.02,
+		Methods:       []string{"M1", "M2"},
+		ExchangeCodes: []string{"exch1", "exch2"},
+	}
+	filter := &domain.SubscriptionChainFilter{
+		Assets:        []string{"RUB"},
+		Methods:       []string{"M1", "M2", "M3"},
+		Exchanges:     []string{"exch1", "exch2"},
+		MinProfit:     1,
+		Opportunities: []string{domain.OpportunityTypeSpread},
+	}
+	s.True(matchSpread(filter, spread))
+	// chains aren't matched by the spread only filter
+	s.False(matchChain(filter, &domain.ProfitableChain{Asset: "RUB", ProfitShare: 1.02}))
+
+	tests := []func(f *domain.SubscriptionChainFilter){
+		func(f *domain.SubscriptionChainFilter) { f.Opportunities = nil },
+		func(f *domain.SubscriptionChainFilter) { f.Assets = []string{"EUR"} },
+		func(f *domain.SubscriptionChainFilter) { f.Methods = []string{"M1"} },
+		func(f *domain.SubscriptionChainFilter) { f.Exchanges = []string{"exch1"} },
+		func(f *domain.SubscriptionChainFilter) { f.MinProfit = 3 },
+	}
+	for _, tt := range tests {
+		f := *filter
+		tt(&f)
+		s.False(matchSpread(&f, spread))
+	}
+}
+
+func (s *subscriptionTestSuite) Test_MatchChain_Expression() {
+	chain := &domain.ProfitableChain{
+		Asset:         "USDT",
+		ProfitShare:   1.02,
+		Depth:         3,
+		ExchangeCodes: []string{"binance", "garantex"},
+		Methods:       []string{"M1"},
+		BaseCurrency:  "USD",
+		BaseVolume:    1000,
+	}
+	for expression, res := range map[string]bool{
+		`profit >= 1.5 && !("bybit" in exchanges) && volumeUsd > 500`: true,
+		`profit >= 2.5`: false,
+		`"garantex" in exchanges && size(exchanges) == 2`:         true,
+		`opportunity == "chain" && depth == 3 && asset == "USDT"`: true,
+		`"M2" in methods`:      false,
+		`bidTypes == bidTypes`: false, // invalid expressions never match
+	} {
+		s.Equal(res, matchChain(&domain.SubscriptionChainFilter{Expression: expression}, chain), expression)
+	}
+	// volume in USD is unknown if the base currency differs
+	chain.BaseCurrency = "EUR"
+	s.False(matchChain(&domain.SubscriptionChainFilter{Expression: "volumeUsd > 500"}, chain))
+	s.True(matchChain(&domain.SubscriptionChainFilter{Expression: "baseVolume > 500 && baseCurrency == 'EUR'"}, chain))
+}
+
+func (s *subscriptionTestSuite) Test_MatchSpread_Expression() {
+	spread := &domain.Spread{
+		BaseAsset:     "USDT",
+		QuoteAsset:    "RUB",
+		SpreadShare:   1.02,
+		ExchangeCodes: []string{"exch1", "exch2"},
+		Buy:           &domain.Bid{Type: "p2p"},
+		Sell:          &domain.Bid{Type: "p2p"},
+	}
+	filter := &domain.SubscriptionChainFilter{Opportunities: []string{domain.OpportunityTypeSpread}}
+	filter.Expression = `profit > 2.5`
+	s.False(matchSpread(filter, spread))
+	filter.Expression = `opportunity == "spread" && profit > 1.9 && "RUB" in assets && size(bidTypes) == 1`
+	s.True(matchSpread(filter, spread))
+	filter.Expression = `depth > 2`
+	s.False(matchSpread(filter, spread))
+}
+
+func (s *subscriptionTestSuite) Test_NotifySpreads_OneSpreadOneSubscriptionMatch_Ok() {
+	spreads := []*domain.Spread{{Id: kit.NewId(), BaseAsset: "USDT", QuoteAsset: "RUB", SpreadShare: 1.02, ExchangeCodes: []string{"binance"}}}
+	sub1 := s.getSubscription()
+	sub1.Filter.Opportunities = []string{domain.OpportunityTypeChain, domain.OpportunityTypeSpread}
+	sub2 := s.getSubscription()
+	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{Telegram: &service.ArbitrageNotificationTelegram{Bot: "bot"}}}})
+	deliveries := s.expectDeliveries()
+	s.storage.On("SearchSubscriptions", s.Ctx, mock.AnythingOfType("*domain.SearchSubscriptionsRequest")).Return([]*domain.Subscription{sub1, sub2}, nil)
+	s.Nil(s.svc.NotifySpreads(s.Ctx, spreads))
+	s.Len(*deliveries, 1)
+	s.Equal(sub1.Id, (*deliveries)[0].SubscriptionId)
+	s.Equal(sub1.UserId, (*deliveries)[0].UserId)
+	s.Equal(domain.OpportunityTypeSpread, (*deliveries)[0].OpportunityType)
+	s.Equal(spreads[0].Id, (*deliveries)[0].OpportunityId)
+	s.Equal(spreads[0], (*deliveries)[0].Spread)
+}
+
+func (s *subscriptionTestSuite) Test_NotifyPrivate_OnlyOwnerSubscriptions() {
+	chain := &domain.ProfitableChain{
+		Id:            kit.NewId(),
+		Asset:         "RUB",
+		ProfitShare:   1.2,
+		Methods:       []string{"M1"},
+		Depth:         2,
+		ExchangeCodes: []string{"binance"},
+	}
+	owner := s.getSubscription()
+	other := s.getSubscription()
+	other.Notifications[0].Telegram.Channel = -1
+	chain.OwnerId = owner.UserId
+	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{Telegram: &service.ArbitrageNotificationTelegram{Bot: "bot"}}}})
+	deliveries := s.expectDeliveries()
+	s.storage.On("SearchSubscriptions", s.Ctx, &domain.SearchSubscriptionsRequest{
+		UserId:      owner.UserId,
+		Opportunity: domain.OpportunityTypeChain,
+		Assets:      []string{"RUB"},
+		ProfitShare: 1.2,
+	}).Return([]*domain.Subscription{owner, other}, nil)
+	s.Nil(s.svc.NotifyPrivate(s.Ctx, owner.UserId, []*domain.ProfitableChain{chain}))
+	s.Len(*deliveries, 1)
+	s.Equal(owner.Notifications[0], (*deliveries)[0].Notification)
+}
+
+func (s *subscriptionTestSuite) Test_SearchRequests() {
+	rq := chainsSearchRequest([]*domain.ProfitableChain{{Asset: "RUB", ProfitShare: 1.02}, {Asset: "USDT", ProfitShare: 1.05}, {Asset: "RUB", ProfitShare: 1.01}})
+	s.Equal(&domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeChain, Assets: []string{"RUB", "USDT"}, ProfitShare: 1.05}, rq)
+	rq = spreadsSearchRequest([]*domain.Spread{{BaseAsset: "USDT", QuoteAsset: "RUB", SpreadShare: 1.03}, {BaseAsset: "BTC", QuoteAsset: "RUB", SpreadShare: 1.01}})
+	s.Equal(&domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeSpread, Assets: []string{"USDT", "RUB", "BTC"}, ProfitShare: 1.03}, rq)
+}
+
+func (s *subscriptionTestSuite) Test_ValidateAndPopulate_WhenTemplateInvalid_Fail() {
+	subs := s.getSubscription()
+	subs.Notifications[0].Templates = &domain.NotificationTemplates{
+		Chain: &domain.NotificationTemplate{Body: "{{ .Chain.Unknown }}"},
+	}
+	s.AssertAppErr(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs), errors.ErrCodeNotificationTemplateInvalid)
+}
+
+func (s *subscriptionTestSuite) Test_PreviewTemplate_WhenChannelNotSupported_Fail() {
+	_, err := s.svc.PreviewTemplate(s.Ctx, &domain.TemplatePreviewRequest{Channel: "sms", Type: domain.OpportunityTypeChain})
+	s.AssertAppErr(err, errors.ErrCodeSubscriptionNotificationChannelNotSupported)
+}
+
+func (s *subscriptionTestSuite) Test_ValidateAndPopulate_WhenPolicyInvalid_Fail() {
+	subs := s.getSubscription()
+	subs.Policy = &domain.SubscriptionDeliveryPolicy{MaxMessages: 5}
+	s.AssertAppErr(s.svc.(*subscriptionSvcImpl).validateAndPopulate(s.Ctx, subs), errors.ErrCodeSubscriptionPolicyInvalid)
+}
+
+func (s *subscriptionTestSuite) Test_Notify_Throttled() {
+	sub := s.getSubscription()
+	sub.Filter = &domain.SubscriptionChainFilter{}
+	sub.Policy = &domain.SubscriptionDeliveryPolicy{MaxMessages: 1, WindowSec: 3600}
+	chains := []*domain.ProfitableChain{
+		{Id: kit.NewId(), Asset: "RUB", ProfitShare: 1.01},
+		{Id: kit.NewId(), Asset: "RUB", ProfitShare: 1.03},
+	}
+	s.storage.On("SearchSubscriptions", s.Ctx, mock.AnythingOfType("*domain.SearchSubscriptionsRequest")).Return([]*domain.Subscription{sub}, nil)
+	s.policies.On("ReserveMessages", s.Ctx, sub.Id, 2, 1, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(1, nil)
+	deliveries := s.expectDeliveries()
+
+	s.Nil(s.svc.Notify(s.Ctx, chains))
+	s.Len(*deliveries, 1)
+	s.Equal(chains[1], (*deliveries)[0].Chain)
+}
+
+func (s *subscriptionTestSuite) Test_Notify_Digest() {
+	sub := s.getSubscription()
+	sub.Filter = &domain.SubscriptionChainFilter{}
+	sub.Policy = &domain.SubscriptionDeliveryPolicy{Digest: &domain.SubscriptionDigest{PeriodMin: 5, MaxItems: 10}}
+	chains := []*domain.ProfitableChain{
+		{Id: kit.NewId(), Asset: "RUB", ProfitShare: 1.01},
+		{Id: kit.NewId(), Asset: "RUB", ProfitShare: 1.03},
+	}
+	s.storage.On("SearchSubscriptions", s.Ctx, mock.AnythingOfType("*domain.SearchSubscriptionsRequest")).Return([]*domain.Subscription{sub}, nil)
+	s.policies.On("AddDigestItems", s.Ctx, sub.Id, mock.AnythingOfType("[]*domain.DigestItem"), 10, mock.AnythingOfType("time.Time")).Return(nil)
+	deliveries := s.expectDeliveries()
+
+	s.Nil(s.svc.Notify(s.Ctx, chains))
+	s.Empty(*deliveries)
+	s.policies.AssertNumberOfCalls(s.T(), "AddDigestItems", 1)
+
+	now := kit.Now()
+	pending := &domain.PendingDigest{
+		Id:             kit.NewId(),
+		SubscriptionId: sub.Id,
+		Items: []*domain.DigestItem{
+			{OpportunityType: domain.OpportunityTypeChain, OpportunityId: chains[1].Id, Profit: 1.03, Chain: chains[1]},
+			{OpportunityType: domain.OpportunityTypeChain, OpportunityId: chains[0].Id, Profit: 1.01, Chain: chains[0]},
+		},
+		Matched: 2,
+		From:    now,
+	}
+	// the digest of the removed subscription is dropped
+	orphan := &domain.PendingDigest{Id: kit.NewId(), SubscriptionId: kit.NewId(), Matched: 1, From: now}
+	s.policies.On("GetPendingDigests", s.Ctx).Return([]*domain.PendingDigest{pending, orphan}, nil)
+	s.policies.On("DeletePendingDigest", s.Ctx, orphan.SubscriptionId, orphan.Id).Return(nil)
+	s.policies.On("DeletePendingDigest", s.Ctx, sub.Id, pending.Id).Return(nil)
+
+	svc := s.svc.(*subscriptionSvcImpl)
+	s.Nil(svc.sendDigests(s.Ctx, now))
+	s.Empty(*deliveries)
+	s.policies.AssertCalled(s.T(), "DeletePendingDigest", s.Ctx, orphan.SubscriptionId, orphan.Id)
+	s.policies.AssertNotCalled(s.T(), "DeletePendingDigest", s.Ctx, sub.Id, pending.Id)
+
+	s.Nil(svc.sendDigests(s.Ctx, now.Add(time.Minute*5)))
+	s.Len(*deliveries, 1)
+	d := (*deliveries)[0]
+	s.Equal(domain.DeliveryTypeDigest, d.OpportunityType)
+	s.Equal(pending.Id, d.OpportunityId)
+	s.Equal(sub.Notifications[0], d.Notification)
+	s.Equal(2, d.Digest.Matched)
+	s.Equal([]*domain.ProfitableChain{chains[1], chains[0]}, d.Digest.Chains)
+	s.policies.AssertCalled(s.T(), "DeletePendingDigest", s.Ctx, sub.Id, pending.Id)
+}
+
+func (s *subscriptionTestSuite) Test_SendDigests_WhenEnqueueFails_DigestKept() {
+	sub := s.getSubscription()
+	sub.Policy = &domain.SubscriptionDeliveryPolicy{Digest: &domain.SubscriptionDigest{PeriodMin: 5}}
+	pending := &domain.PendingDigest{Id: kit.NewId(), SubscriptionId: sub.Id, Matched: 1, From: kit.Now().Add(-time.Hour)}
+	s.storage.On("SearchSubscriptions", s.Ctx, mock.AnythingOfType("*domain.SearchSubscriptionsRequest")).Return([]*domain.Subscription{sub}, nil)
+	s.policies.On("GetPendingDigests", s.Ctx).Return([]*domain.PendingDigest{pending}, nil)
+	s.outbox.On("Enqueue", s.Ctx, mock.AnythingOfType("[]*domain.OutboxDelivery")).Return(errors.ErrOutboxStoragePut(fmt.Errorf("error"), s.Ctx))
+
+	s.AssertAppErr(s.svc.(*subscriptionSvcImpl).sendDigests(s.Ctx, kit.Now()), errors.ErrCodeOutboxStoragePut)
+	s.policies.AssertNotCalled(s.T(), "DeletePendingDigest", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *subscriptionTestSuite) Test_Preview() {
+	now := kit.Now()
+	hour := now.Truncate(time.Hour)
+	hot := []*domain.ProfitableChain{
+		{Id: "1", Asset: "USDT", ProfitShare: 1.02, ExchangeCodes: []string{"binance"}, CreatedAt: hour},
+		{Id: "2", Asset: "USDT", ProfitShare: 1.03, ExchangeCodes: []string{"bybit"}, CreatedAt: hour},
+		{Id: "3", Asset: "USDT", ProfitShare: 1.01, ExchangeCodes: []string{"binance"}, CreatedAt: hour.Add(-time.Minute)},
+	}
+	archived := []*domain.ProfitableChain{
+		// the chain is still in the hot storage
+		{Id: "3", Asset: "USDT", ProfitShare: 1.01, ExchangeCodes: []string{"binance"}, CreatedAt: hour.Add(-time.Minute)},
+		{Id: "4", Asset: "USDT", ProfitShare: 1.05, ExchangeCodes: []string{"binance"}, CreatedAt: hour.Add(-3*time.Hour + time.Minute)},
+		{Id: "5", Asset: "USDT", ProfitShare: 1.05, ExchangeCodes: []string{"binance"}, CreatedAt: hour.Add(-6 * time.Hour)},
+	}
+	s.chains.On("GetProfitableChains", s.Ctx, mock.AnythingOfType("*domain.GetProfitableChainsRequest")).
+		Return(&domain.GetProfitableChainsResponse{PagingResponse: kit.PagingResponse{Total: len(hot)}, Chains: hot}, nil)
+	s.archive.On("GetArchivedChains", s.Ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), maxPreviewChains).Return(archived, nil)
+	s.svc.Init(&service.Config{Retention: &service.Retention{Archive: &service.ChainArchive{Enabled: true}}})
+
+	rs, err := s.svc.Preview(s.Ctx, &domain.SubscriptionPreviewRequest{
+		Filter:  &domain.SubscriptionChainFilter{Expression: `profit > 1.5 && !("bybit" in exchanges)`},
+		Hours:   4,
+		Samples: 1,
+	})
+	s.NoError(err)
+	s.Equal(hour.Add(-3*time.Hour), rs.From)
+	s.Equal(4, rs.Scanned)
+	s.Equal(2, rs.Matches)
+	s.Len(rs.Hours, 4)
+	s.Equal(1, rs.Hours[0].Matches)
+	s.Equal(0, rs.Hours[1].Matches)
+	s.Equal(0, rs.Hours[2].Matches)
+	s.Equal(1, rs.Hours[3].Matches)
+	s.Len(rs.Samples, 1)
+	s.Equal("4", rs.Samples[0].Id)
+	s.False(rs.Truncated)
+	s.NotNil(rs.HistoryFrom)
+	s.InDelta(2*24*float64(time.Hour)/float64(now.Sub(*rs.HistoryFrom)), rs.EstimatedDaily, 0.1)
+}
+
+func (s *subscriptionTestSuite) Test_Preview_WhenArchiveDisabled_HotOnly() {
+	now := kit.Now()
+	s.chains.On("GetProfitableChains", s.Ctx, mock.AnythingOfType("*domain.GetProfitableChainsRequest")).
+		Return(&domain.GetProfitableChainsResponse{PagingResponse: kit.PagingResponse{Total: 1}, Chains: []*domain.ProfitableChain{
+			{Id: "1", Asset: "USDT", ProfitShare: 1.02, CreatedAt: now.Add(-10 * time.Minute)},
+		}}, nil)
+
+	rs, err := s.svc.Preview(s.Ctx, &domain.SubscriptionPreviewRequest{Filter: &domain.SubscriptionChainFilter{Assets: []string{" usdt"}}})
+	s.NoError(err)
+	s.Len(rs.Hours, defaultPreviewHours)
+	s.Equal(1, rs.Matches)
+	s.Len(rs.Samples, 1)
+	s.False(rs.Truncated)
+	// history is shorter than an hour, so it's extrapolated from an hour
+	s.Equal(24.0, rs.EstimatedDaily)
+	s.archive.AssertNotCalled(s.T(), "GetArchivedChains", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *subscriptionTestSuite) Test_Preview_HotChainsPaged_TruncatedWhenLimitReached() {
+	now := kit.Now()
+	var cursors []string
+	s.chains.On("GetProfitableChains", s.Ctx, mock.AnythingOfType("*domain.GetProfitableChainsRequest")).
+		Return(func(ctx context.Context, rq *domain.GetProfitableChainsRequest) *domain.GetProfitableChainsResponse {
+			s.Equal(domain.MaxChainsPageSize, rq.Size)
+			from := len(cursors) * domain.MaxChainsPageSize
+			cursors = append(cursors, rq.Cursor)
+			rs := &domain.GetProfitableChainsResponse{NextCursor: strconv.Itoa(len(cursors))}
+			for i := from; i < from+domain.MaxChainsPageSize; i++ {
+				rs.Chains = append(rs.Chains, &domain.ProfitableChain{Id: strconv.Itoa(i), Asset: "USDT", ProfitShare: 1.02, CreatedAt: now.Add(-10 * time.Minute)})
+			}
+			return rs
+		}, nil)
+
+	rs, err := s.svc.Preview(s.Ctx, &domain.SubscriptionPreviewRequest{Filter: &domain.SubscriptionChainFilter{Assets: []string{"USDT"}}})
+	s.NoError(err)
+	s.True(rs.Truncated)
+	s.Equal(maxPreviewChains, rs.Matches)
+	// each page follows the cursor of the previous one
+	s.Equal([]string{"", "1", "2", "3", "4"}, cursors)
+}
+
+func (s *subscriptionTestSuite) Test_Preview_WhenInvalid_Fail() {
+	_, err := s.svc.Preview(s.Ctx, &domain.SubscriptionPreviewRequest{Hours: maxPreviewHours + 1})
+	s.AssertAppErr(err, errors.ErrCodeSubscriptionPreviewHoursInvalid)
+	_, err = s.svc.Preview(s.Ctx, &domain.SubscriptionPreviewRequest{Samples: -1})
+	s.AssertAppErr(err, errors.ErrCodeSubscriptionPreviewSamplesInvalid)
+	_, err = s.svc.Preview(s.Ctx, &domain.SubscriptionPreviewRequest{Filter: &domain.SubscriptionChainFilter{Expression: "profit >"}})
+	s.AssertAppErr(err, errors.ErrCodeSubscriptionFilterExpressionInvalid)
 }
@@ -0,0 +1,376 @@
+package subscription
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/goroutine"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"go.uber.org/atomic"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAlertsPeriodSec       = 10
+	defaultAlertsMinProfitChange = 0.1
+	defaultAlertsRetentionHours  = 24
+	alertsCleanupPeriod          = time.Hour
+	// expiredAlertBanner is put on top of the alert when the chain dies, so nobody acts on it
+	expiredAlertBanner = "⛔ <b>EXPIRED</b>: the chain is no longer available"
+)
+
+type telegramAlertsImpl struct {
+	notifier        domain.TelegramNotifier
+	bots            domain.TelegramBotRegistry
+	renderer        domain.NotificationRenderer
+	storage         domain.TelegramAlertStorage
+	bids            domain.BidProvider
+	enabled         bool
+	period          time.Duration
+	minProfitChange float64
+	retention       time.Duration
+	cancelFunc      context.CancelFunc
+	running         *atomic.Bool
+}
+
+func NewTelegramAlerts(notifier domain.TelegramNotifier, bots domain.TelegramBotRegistry, renderer domain.NotificationRenderer,
+	storage domain.TelegramAlertStorage, bids domain.BidProvider) domain.TelegramAlerts {
+	return &telegramAlertsImpl{
+		notifier:        notifier,
+		bots:            bots,
+		renderer:        renderer,
+		storage:         storage,
+		bids:            bids,
+		period:          defaultAlertsPeriodSec * time.Second,
+		minProfitChange: defaultAlertsMinProfitChange,
+		retention:       defaultAlertsRetentionHours * time.Hour,
+		running:         atomic.NewBool(false),
+	}
+}
+
+func (t *telegramAlertsImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-alerts")
+}
+
+func (t *telegramAlertsImpl) Init(cfg *service.Config) {
+	if cfg.Arbitrage == nil || cfg.Arbitrage.Notification == nil || cfg.Arbitrage.Notification.Telegram == nil {
+		return
+	}
+	tgCfg := cfg.Arbitrage.Notification.Telegram
+	if tgCfg.LiveAlerts == nil {
+		return
+	}
+	t.enabled = tgCfg.LiveAlerts.Enabled
+	if tgCfg.LiveAlerts.PeriodSec > 0 {
+		t.period = time.Duration(tgCfg.LiveAlerts.PeriodSec) * time.Second
+	}
+	if tgCfg.LiveAlerts.MinProfitChange > 0 {
+		t.minProfitChange = tgCfg.LiveAlerts.MinProfitChange
+	}
+	if tgCfg.LiveAlerts.RetentionHours > 0 {
+		t.retention = time.Duration(tgCfg.LiveAlerts.RetentionHours) * time.Hour
+	}
+}
+
+func (t *telegramAlertsImpl) Send(ctx context.Context, delivery *domain.OutboxDelivery, text string) error {
+	l := t.l().C(ctx).Mth("send").F(log.FF{"deliveryId": delivery.Id})
+
+	if delivery.Notification == nil || delivery.Notification.Telegram == nil || delivery.Chain == nil {
+		return errors.ErrOutboxDeliveryInvalid(ctx, delivery.Id)
+	}
+	channel, botId := delivery.Notification.Telegram.Channel, delivery.Notification.Telegram.BotId
+
+	// a message too long to be sent at once can't be edited
+	if !t.enabled || telegram.MessageLength(text) > telegram.MaxMessageLength {
+		return sendParts(ctx, t.bots, t.notifier, delivery.UserId, botId, channel, text)
+	}
+
+	// the delivery has been waiting in the outbox for longer than the chain lives
+	now := kit.Now()
+	if !delivery.Chain.ExpiresAt.IsZero() && delivery.Chain.ExpiresAt.Before(now) {
+		l.F(log.FF{"chainId": delivery.Chain.Id}).Dbg("chain expired, not sent")
+		return nil
+	}
+
+	var messageId int64
+	sentBy, err := t.bots.Do(ctx, delivery.UserId, botId, func(bot string) error {
+		var err error
+		messageId, err = t.notifier.SendMessage(ctx, bot, channel, text)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	alert := &domain.TelegramAlert{
+		ChatId:         int64(channel),
+		MessageId:      messageId,
+		ChainId:        delivery.Chain.Id,
+		DeliveryId:     delivery.Id,
+		BotId:          sentBy,
+		SubscriptionId: delivery.SubscriptionId,
+		UserId:         delivery.UserId,
+		Notification:   delivery.Notification,
+		Chain:          delivery.Chain,
+		Profit:         delivery.Chain.ProfitShare,
+		Status:         domain.TelegramAlertActive,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	// the message is sent, so the delivery succeeds even if the alert isn't saved, otherwise the message would be duplicated by retries
+	if err := t.storage.SaveAlert(ctx, alert); err != nil {
+		l.E(err).Err("save alert")
+	}
+	return nil
+}
+
+func (t *telegramAlertsImpl) NotifyUpdated(ctx context.Context, chains []*domain.ProfitableChain) error {
+	l := t.l().C(ctx).Mth("notify-updated")
+
+	if !t.enabled || len(chains) == 0 {
+		return nil
+	}
+
+	byId := make(map[string]*domain.ProfitableChain, len(chains))
+	ids := make([]string, 0, len(chains))
+	for _, c := range chains {
+		byId[c.Id] = c
+		ids = append(ids, c.Id)
+	}
+	alerts, err := t.storage.GetAlertsByChains(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	now := kit.Now()
+	for _, a := range alerts {
+		chain, ok := byId[a.ChainId]
+		if !ok {
+			continue
+		}
+		// the chain is kept anyway, so the alert lives as long as the chain is recalculated
+		a.Chain = chain
+		if math.Abs(chain.ProfitShare-a.Profit)*100.0 >= t.minProfitChange {
+			a.Pending = true
+		}
+		a.UpdatedAt = now
+		if err := t.storage.SaveAlert(ctx, a); err != nil {
+			return err
+		}
+	}
+	l.DbgF("alerts: %d", len(alerts))
+	return nil
+}
+
+// deadChains returns ids of chains of the alerts which have expired or lost a bid
+func (t *telegramAlertsImpl) deadChains(ctx context.Context, alerts []*domain.TelegramAlert, now time.Time) (map[string]bool, error) {
+	dead := make(map[string]bool)
+	chains := make(map[string]*domain.ProfitableChain)
+	var bidIds, privateBidIds []string
+	for _, a := range alerts {
+		if a.Chain == nil || a.Chain.ExpiresAt.Before(now) {
+			dead[a.ChainId] = true
+			continue
+		}
+		if _, ok := chains[a.ChainId]; ok {
+			continue
+		}
+		chains[a.ChainId] = a.Chain
+		for _, b := range a.Chain.Bids {
+			if b.Private {
+				privateBidIds = append(privateBidIds, b.Id)
+			} else {
+				bidIds = append(bidIds, b.Id)
+			}
+		}
+	}
+
+	alive := make(map[string]bool)
+	if len(bidIds) > 0 {
+		bids, err := t.bids.GetBidsByIds(ctx, kit.Strings(bidIds).Distinct())
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bids {
+			alive[b.Id] = true
+		}
+	}
+	if len(privateBidIds) > 0 {
+		bids, err := t.bids.GetPrivateBidsByIds(ctx, kit.Strings(privateBidIds).Distinct())
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bids {
+			alive[b.Id] = true
+		}
+	}
+
+	for id, chain := range chains {
+		for _, b := range chain.Bids {
+			if !alive[b.Id] {
+				dead[id] = true
+				break
+			}
+		}
+	}
+	return dead, nil
+}
+
+// hasErrCode checks if the error is an app error with one of the codes
+func hasErrCode(err error, codes ...string) bool {
+	appErr, ok := er.Is(err)
+	return ok && kit.Strings(codes).Contains(appErr.Code())
+}
+
+// render renders the alert, expired alerts are struck through under the banner
+func (t *telegramAlertsImpl) render(ctx context.Context, a *domain.TelegramAlert, expired bool) (string, error) {
+	// alerts without a chain are considered dead
+	if a.Chain == nil {
+		return expiredAlertBanner, nil
+	}
+	msg, err := t.renderer.Render(ctx, &domain.OutboxDelivery{
+		Id:              a.DeliveryId,
+		SubscriptionId:  a.SubscriptionId,
+		UserId:          a.UserId,
+		Notification:    a.Notification,
+		OpportunityType: domain.OpportunityTypeChain,
+		OpportunityId:   a.ChainId,
+		Chain:           a.Chain,
+	})
+	if err != nil {
+		return "", err
+	}
+	if !expired {
+		return msg.Body, nil
+	}
+	text := expiredAlertBanner + "\n<s>" + strings.TrimSpace(msg.Body) + "</s>"
+	if telegram.MessageLength(text) > telegram.MaxMessageLength {
+		return expiredAlertBanner, nil
+	}
+	return text, nil
+}
+
+// edit edits the message of the alert by the bot which has sent it
+// if the message can't be edited anymore (e.g. it's deleted or the bot is gone), the alert isn't tracked further
+func (t *telegramAlertsImpl) edit(ctx context.Context, a *domain.TelegramAlert, expired bool, now time.Time) error {
+	l := t.l().C(ctx).Mth("edit").F(log.FF{"chainId": a.ChainId, "chatId": a.ChatId, "messageId": a.MessageId})
+
+	text, err := t.render(ctx, a, expired)
+	if err == nil {
+		var bot string
+		if bot, err = t.bots.Token(ctx, a.BotId); err == nil {
+			err = t.notifier.EditMessage(ctx, bot, int(a.ChatId), a.MessageId, text)
+		}
+	}
+	if err != nil {
+		if !telegram.IsRejected(err) && !hasErrCode(err, errors.ErrCodeNotificationTemplateRender, errors.ErrCodeTelegramBotNotFound) {
+			return err
+		}
+		l.E(err).Warn("alert can't be edited")
+		expired = true
+	}
+
+	a.Pending = false
+	if a.Chain != nil {
+		a.Profit = a.Chain.ProfitShare
+	}
+	if expired {
+		a.Status = domain.TelegramAlertExpired
+	}
+	a.UpdatedAt = now
+	return t.storage.SaveAlert(ctx, a)
+}
+
+// refresh marks alerts of dead chains expired and edits alerts of changed chains
+func (t *telegramAlertsImpl) refresh(ctx context.Context, now time.Time) error {
+	l := t.l().C(ctx).Mth("refresh")
+
+	alerts, err := t.storage.GetActiveAlerts(ctx)
+	if err != nil {
+		return err
+	}
+	if len(alerts) == 0 {
+		return nil
+	}
+	dead, err := t.deadChains(ctx, alerts, now)
+	if err != nil {
+		return err
+	}
+
+	edited := 0
+	for _, a := range alerts {
+		if !dead[a.ChainId] && !a.Pending {
+			continue
+		}
+		if err := t.edit(ctx, a, dead[a.ChainId], now); err != nil {
+			l.F(log.FF{"chainId": a.ChainId, "chatId": a.ChatId}).E(err).Err()
+			continue
+		}
+		edited++
+	}
+	l.DbgF("alerts: %d, dead chains: %d, edited: %d", len(alerts), len(dead), edited)
+	return nil
+}
+
+func (t *telegramAlertsImpl) Run(ctx context.Context) error {
+	l := t.l().C(ctx).Mth("run").Trc()
+
+	if !t.enabled {
+		l.Inf("disabled")
+		return nil
+	}
+
+	// check running
+	if t.running.Load() {
+		return errors.ErrTelegramAlertsAlreadyRun(ctx)
+	}
+
+	ctx, t.cancelFunc = context.WithCancel(ctx)
+	t.running.Store(true)
+
+	goroutine.New().
+		WithLogger(t.l().C(ctx).Mth("alerts-worker")).
+		WithRetry(goroutine.Unrestricted).
+		WithRetryDelay(time.Second*10).
+		Go(ctx, func() {
+			ticker := time.NewTicker(t.period)
+			defer ticker.Stop()
+			cleanup := time.NewTicker(alertsCleanupPeriod)
+			defer cleanup.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := t.refresh(ctx, kit.Now()); err != nil {
+						t.l().C(ctx).Mth("alerts-worker").E(err).Err()
+					}
+				case <-cleanup.C:
+					if err := t.storage.DeleteAlerts(ctx, kit.Now().Add(-t.retention)); err != nil {
+						t.l().C(ctx).Mth("alerts-cleanup").E(err).Err()
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+
+	l.Inf("ok")
+	return nil
+}
+
+func (t *telegramAlertsImpl) Stop(ctx context.Context) error {
+	l := t.l().C(ctx).Mth("stop").Trc()
+	// cancel if running
+	if t.cancelFunc != nil && t.running.Load() {
+		t.cancelFunc()
+		t.running.Store(false)
+		t.cancelFunc = nil
+		l.Inf("ok")
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package subscription
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/domain/impl/arbitrage"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type telegramAlertsTestSuite struct {
+	kitTestSuite.Suite
+	api     *telegram.TestBotApiServer
+	storage *mocks.TelegramAlertStorage
+	bids    *mocks.BidProvider
+	svc     domain.TelegramAlerts
+	mu      sync.Mutex
+	saved   []*domain.TelegramAlert
+}
+
+func (s *telegramAlertsTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestTelegramAlertsSuite(t *testing.T) {
+	suite.Run(t, new(telegramAlertsTestSuite))
+}
+
+func (s *telegramAlertsTestSuite) SetupTest() {
+	s.api = telegram.NewTestBotApiServer()
+	s.storage = &mocks.TelegramAlertStorage{}
+	s.bids = &mocks.BidProvider{}
+	s.saved = nil
+	s.storage.On("SaveAlert", mock.Anything, mock.AnythingOfType("*domain.TelegramAlert")).
+		Run(func(args mock.Arguments) {
+			a := *args.Get(1).(*domain.TelegramAlert)
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.saved = append(s.saved, &a)
+		}).
+		Return(nil)
+	client := telegram.NewTelegram(service.LF(), s.api.Config())
+	s.svc = NewTelegramAlerts(NewTelegramNotifier(client, &TelegramOptions{Bot: testBotToken}), newDefaultBotRegistry(client, testBotToken),
+		NewNotificationRenderer(), s.storage, s.bids)
+	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{
+		Telegram: &service.ArbitrageNotificationTelegram{Bot: testBotToken, LiveAlerts: &service.TelegramLiveAlerts{Enabled: true}},
+	}}})
+}
+
+func (s *telegramAlertsTestSuite) TearDownTest() {
+	s.api.Close()
+}
+
+func (s *telegramAlertsTestSuite) chain(profitShare float64) *domain.ProfitableChain {
+	return &domain.ProfitableChain{
+		Id:            "chain-id",
+		Asset:         "USDT",
+		ProfitShare:   profitShare,
+		ExchangeCodes: []string{"binance"},
+		Bids: []*domain.Bid{
+			{Id: "bid-1", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 60, ExchangeCode: "binance"},
+			{Id: "bid-2", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 0.0175, ExchangeCode: "binance", Private: true},
+		},
+		ExpiresAt: kit.Now().Add(time.Hour),
+	}
+}
+
+func (s *telegramAlertsTestSuite) delivery(chain *domain.ProfitableChain) *domain.OutboxDelivery {
+	return &domain.OutboxDelivery{
+		Id:              "delivery-id",
+		SubscriptionId:  "subs-id",
+		UserId:          "user-id",
+		OpportunityType: domain.OpportunityTypeChain,
+		OpportunityId:   chain.Id,
+		Notification: &domain.SubscriptionNotification{
+			Id:       "n",
+			Channel:  domain.SubscriptionNotificationChannelTelegram,
+			Telegram: &domain.SubscriptionTelegramNotificationDetails{Channel: int(testChannelId)},
+		},
+		Chain: chain,
+	}
+}
+
+// sent sends the chain and returns the alert remembered
+func (s *telegramAlertsTestSuite) sent(chain *domain.ProfitableChain) *domain.TelegramAlert {
+	s.NoError(s.svc.Send(s.Ctx, s.delivery(chain), "profit <b>5.00%</b>"))
+	s.Len(s.saved, 1)
+	alert := s.saved[0]
+	s.saved = nil
+	return alert
+}
+
+func (s *telegramAlertsTestSuite) expectBids(bidIds, privateBidIds []string) {
+	var bids, privateBids []*domain.Bid
+	for _, id := range bidIds {
+		bids = append(bids, &domain.Bid{Id: id})
+	}
+	for _, id := range privateBidIds {
+		privateBids = append(privateBids, &domain.Bid{Id: id, Private: true})
+	}
+	s.bids.On("GetBidsByIds", s.Ctx, []string{"bid-1"}).Return(bids, nil)
+	s.bids.On("GetPrivateBidsByIds", s.Ctx, []string{"bid-2"}).Return(privateBids, nil)
+}
+
+func (s *telegramAlertsTestSuite) Test_Send() {
+	alert := s.sent(s.chain(1.05))
+	msgs := s.api.Messages()
+	s.Len(msgs, 1)
+	s.Equal(msgs[0].MessageId, alert.MessageId)
+	s.Equal(testChannelId, alert.ChatId)
+	s.Equal("chain-id", alert.ChainId)
+	s.Equal("delivery-id", alert.DeliveryId)
+	// the message is edited by the bot which has sent it
+	s.Equal(domain.TelegramBotDefaultId, alert.BotId)
+	s.Equal(1.05, alert.Profit)
+	s.Equal(domain.TelegramAlertActive, alert.Status)
+}
+
+func (s *telegramAlertsTestSuite) Test_Send_WhenChainExpired_NotSent() {
+	chain := s.chain(1.05)
+	chain.ExpiresAt = kit.Now().Add(-time.Second)
+	s.NoError(s.svc.Send(s.Ctx, s.delivery(chain), "text"))
+	s.Empty(s.api.Messages())
+	s.Empty(s.saved)
+}
+
+func (s *telegramAlertsTestSuite) Test_Send_WhenTooLong_NotTracked() {
+	s.NoError(s.svc.Send(s.Ctx, s.delivery(s.chain(1.05)), strings.Repeat("x\n", telegram.MaxMessageLength)))
+	s.Len(s.api.Messages(), 2)
+	s.Empty(s.saved)
+}
+
+func (s *telegramAlertsTestSuite) Test_NotifyUpdated() {
+	alert := s.sent(s.chain(1.05))
+	s.storage.On("GetAlertsByChains", s.Ctx, []string{"chain-id", "other"}).Return([]*domain.TelegramAlert{alert}, nil)
+
+	// the change is too small
+	s.NoError(s.svc.NotifyUpdated(s.Ctx, []*domain.ProfitableChain{s.chain(1.0505), {Id: "other"}}))
+	s.Len(s.saved, 1)
+	s.False(s.saved[0].Pending)
+	s.Equal(1.0505, s.saved[0].Chain.ProfitShare)
+	s.Equal(1.05, s.saved[0].Profit)
+
+	s.NoError(s.svc.NotifyUpdated(s.Ctx, []*domain.ProfitableChain{s.chain(1.03), {Id: "other"}}))
+	s.Len(s.saved, 2)
+	s.True(s.saved[1].Pending)
+}
+
+func (s *telegramAlertsTestSuite) Test_Refresh_Edit() {
+	alert := s.sent(s.chain(1.05))
+	alert.Chain, alert.Pending = s.chain(1.03), true
+	s.storage.On("GetActiveAlerts", s.Ctx).Return([]*domain.TelegramAlert{alert}, nil)
+	s.expectBids([]string{"bid-1"}, []string{"bid-2"})
+
+	s.NoError(s.svc.(*telegramAlertsImpl).refresh(s.Ctx, kit.Now()))
+	msgs := s.api.Messages()
+	s.Len(msgs, 1)
+	s.Equal(1, msgs[0].Edits)
+	s.Contains(msgs[0].Text, "profit: <b>3.00%</b>")
+	s.NotContains(msgs[0].Text, "EXPIRED")
+	s.Len(s.saved, 1)
+	s.False(s.saved[0].Pending)
+	s.Equal(1.03, s.saved[0].Profit)
+	s.Equal(domain.TelegramAlertActive, s.saved[0].Status)
+}
+
+func (s *telegramAlertsTestSuite) lastSaved() *domain.TelegramAlert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.saved) == 0 {
+		return nil
+	}
+	return s.saved[len(s.saved)-1]
+}
+
+func (s *telegramAlertsTestSuite) Test_Engine_WhenStoredChainProfitChanged_Edited() {
+	alert := s.sent(s.chain(1.05))
+
+	// the market has the same chain, but its profit has dropped to 3.2%
+	s.bids.On("Run", mock.Anything).Return(nil)
+	s.bids.On("Stop", mock.Anything).Return(nil)
+	s.bids.On("GetAssets", mock.Anything).Return([]string{"USDT"}, nil)
+	s.bids.On("GetBidLightsBySourceAsset", mock.Anything, "USDT").
+		Return([]*domain.BidLight{{Id: "bid-1", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 60, ExchangeCode: "binance"}}, nil)
+	s.bids.On("GetBidLightsBySourceAsset", mock.Anything, "RUB").
+		Return([]*domain.BidLight{{Id: "bid-2", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 0.0172, ExchangeCode: "binance"}}, nil)
+	s.bids.On("GetBidsByIds", mock.Anything, []string{"bid-1", "bid-2"}).Return([]*domain.Bid{
+		{Id: "bid-1", SrcAsset: "USDT", TrgAsset: "RUB", Rate: 60, ExchangeCode: "binance"},
+		{Id: "bid-2", SrcAsset: "RUB", TrgAsset: "USDT", Rate: 0.0172, ExchangeCode: "binance"},
+	}, nil)
+	// the chain is stored already, so it's only recalculated
+	chains := &mocks.ChainStorage{}
+	chains.On("ProfitableChainExists", mock.Anything, mock.Anything).Return(true, nil)
+	rates := &mocks.ReferenceRateProvider{}
+	rates.On("ToBase", mock.Anything, mock.Anything, mock.Anything).Return(0.0, false)
+	s.storage.On("GetAlertsByChains", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, ids []string) []*domain.TelegramAlert {
+			a := *alert
+			a.ChainId = ids[0]
+			return []*domain.TelegramAlert{&a}
+		}, nil)
+
+	engine := arbitrage.NewArbitrageService(chains, nil, s.bids, rates, []domain.ChainUpdateNotifier{s.svc})
+	engine.Init(&service.Config{Arbitrage: &service.Arbitrage{Depth: 2, MinProfit: 1.0, ProcessAssetsPeriodSec: 1}})
+	s.NoError(engine.RunCalculationBackground(s.Ctx))
+	s.Eventually(func() bool {
+		a := s.lastSaved()
+		return a != nil && a.Pending
+	}, time.Second*5, time.Millisecond*50)
+	s.NoError(engine.StopCalculation(s.Ctx))
+
+	pending := s.lastSaved()
+	s.InDelta(1.032, pending.Chain.ProfitShare, 1e-9)
+	s.storage.On("GetActiveAlerts", s.Ctx).Return([]*domain.TelegramAlert{pending}, nil)
+	s.NoError(s.svc.(*telegramAlertsImpl).refresh(s.Ctx, kit.Now()))
+	msgs := s.api.Messages()
+	s.Len(msgs, 1)
+	s.Equal(1, msgs[0].Edits)
+	s.Contains(msgs[0].Text, "profit: <b>3.20%</b>")
+}
+
+func (s *telegramAlertsTestSuite) Test_Refresh_WhenNotChanged_NotEdited() {
+	alert := s.sent(s.chain(1.05))
+	s.storage.On("GetActiveAlerts", s.Ctx).Return([]*domain.TelegramAlert{alert}, nil)
+	s.expectBids([]string{"bid-1"}, []string{"bid-2"})
+
+	s.NoError(s.svc.(*telegramAlertsImpl).refresh(s.Ctx, kit.Now()))
+	s.Equal(0, s.api.Messages()[0].Edits)
+	s.Empty(s.saved)
+}
+
+func (s *telegramAlertsTestSuite) Test_Refresh_WhenBidGone_Expired() {
+	alert := s.sent(s.chain(1.05))
+	s.storage.On("GetActiveAlerts", s.Ctx).Return([]*domain.TelegramAlert{alert}, nil)
+	// private bid is withdrawn
+	s.expectBids([]string{"bid-1"}, nil)
+
+	s.NoError(s.svc.(*telegramAlertsImpl).refresh(s.Ctx, kit.Now()))
+	msgs := s.api.Messages()
+	s.True(strings.HasPrefix(msgs[0].Text, expiredAlertBanner+"\n<s>"))
+	s.Contains(msgs[0].Text, "profit: <b>5.00%</b>")
+	s.Len(s.saved, 1)
+	s.Equal(domain.TelegramAlertExpired, s.saved[0].Status)
+}
+
+func (s *telegramAlertsTestSuite) Test_Refresh_WhenChainExpired_Expired() {
+	alert := s.sent(s.chain(1.05))
+	alert.Chain.ExpiresAt = kit.Now().Add(-time.Second)
+	s.storage.On("GetActiveAlerts", s.Ctx).Return([]*domain.TelegramAlert{alert}, nil)
+
+	s.NoError(s.svc.(*telegramAlertsImpl).refresh(s.Ctx, kit.Now()))
+	s.Contains(s.api.Messages()[0].Text, expiredAlertBanner)
+	s.Equal(domain.TelegramAlertExpired, s.saved[0].Status)
+	s.bids.AssertNotCalled(s.T(), "GetBidsByIds", mock.Anything, mock.Anything)
+}
+
+func (s *telegramAlertsTestSuite) Test_Refresh_WhenMessageDeleted_NotTracked() {
+	alert := s.sent(s.chain(1.05))
+	alert.Chain, alert.Pending = s.chain(1.03), true
+	alert.MessageId++
+	s.storage.On("GetActiveAlerts", s.Ctx).Return([]*domain.TelegramAlert{alert}, nil)
+	s.expectBids([]string{"bid-1"}, []string{"bid-2"})
+
+	s.NoError(s.svc.(*telegramAlertsImpl).refresh(s.Ctx, kit.Now()))
+	s.Len(s.saved, 1)
+	s.Equal(domain.TelegramAlertExpired, s.saved[0].Status)
+}
@@ -0,0 +1,451 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/goroutine"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"go.uber.org/atomic"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLinkCodeTtl = time.Minute * 10
+	defaultTopChains   = 5
+	maxTopChains       = 10
+)
+
+const telegramBotHelp = `<b>cryptocare bot</b>
+/link &lt;code&gt; - link your account, request the code in the panel
+/subscribe [minProfit] [assets...] - subscribe on chains, e.g. /subscribe 1.5 USDT BTC
+/verify &lt;code&gt; - post to a channel or group to verify it, request the code in the panel
+/filters - list your subscriptions
+/pause [n] - pause all subscriptions or the n-th one
+/resume [n] - resume all subscriptions or the n-th one
+/top [n] - current best chains`
+
+type telegramBotImpl struct {
+	telegram      telegram.Telegram
+	notifier      domain.TelegramNotifier
+	subscriptions domain.SubscriptionService
+	arbitrage     domain.ArbitrageService
+	storage       domain.TelegramLinkStorage
+	verifier      domain.TelegramChannelVerifier
+	bot           string
+	panelUrl      string
+	cfg           *service.TelegramCommands
+	cancelFunc    context.CancelFunc
+	running       *atomic.Bool
+}
+
+func NewTelegramBot(telegram telegram.Telegram, notifier domain.TelegramNotifier, subscriptions domain.SubscriptionService,
+	arbitrage domain.ArbitrageService, storage domain.TelegramLinkStorage, verifier domain.TelegramChannelVerifier) domain.TelegramBot {
+	return &telegramBotImpl{
+		telegram:      telegram,
+		notifier:      notifier,
+		subscriptions: subscriptions,
+		arbitrage:     arbitrage,
+		storage:       storage,
+		verifier:      verifier,
+		panelUrl:      defaultPanelUrl,
+		cfg:           &service.TelegramCommands{},
+		running:       atomic.NewBool(false),
+	}
+}
+
+func (t *telegramBotImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-bot")
+}
+
+func (t *telegramBotImpl) Init(cfg *service.Config) {
+	if cfg.Arbitrage == nil || cfg.Arbitrage.Notification == nil {
+		return
+	}
+	if tgCfg := cfg.Arbitrage.Notification.Telegram; tgCfg != nil {
+		t.bot = tgCfg.Bot
+		if tgCfg.Commands != nil {
+			t.cfg = tgCfg.Commands
+		}
+	}
+	if templatesCfg := cfg.Arbitrage.Notification.Templates; templatesCfg != nil && templatesCfg.PanelUrl != "" {
+		t.panelUrl = strings.TrimRight(templatesCfg.PanelUrl, "/")
+	}
+}
+
+func (t *telegramBotImpl) Run(ctx context.Context) error {
+	l := t.l().C(ctx).Mth("run").Trc()
+
+	if !t.cfg.Enabled {
+		return nil
+	}
+
+	// check running
+	if t.running.Load() {
+		return errors.ErrTelegramBotAlreadyRun(ctx)
+	}
+
+	// webhook mode, Bot API posts updates to the webhook endpoint
+	if t.cfg.WebhookUrl != "" {
+		if err := t.telegram.SetWebhook(ctx, t.bot, t.cfg.WebhookUrl, t.cfg.WebhookSecret); err != nil {
+			return err
+		}
+		t.running.Store(true)
+		l.Inf("ok, webhook")
+		return nil
+	}
+
+	// long polling mode, getUpdates isn't allowed while a webhook is set
+	if err := t.telegram.DeleteWebhook(ctx, t.bot); err != nil {
+		return err
+	}
+
+	ctx, t.cancelFunc = context.WithCancel(ctx)
+	t.running.Store(true)
+
+	goroutine.New().
+		WithLogger(t.l().C(ctx).Mth("telegram-poll")).
+		WithRetry(goroutine.Unrestricted).
+		WithRetryDelay(time.Second*10).
+		Go(ctx, func() {
+			t.telegram.Poll(ctx, t.bot, t.cfg.PollTimeoutSec, func(ctx context.Context, update *telegram.Update) {
+				if err := t.HandleUpdate(ctx, update); err != nil {
+					t.l().C(ctx).Mth("telegram-poll").E(err).Err()
+				}
+			})
+		})
+
+	l.Inf("ok, polling")
+	return nil
+}
+
+func (t *telegramBotImpl) Stop(ctx context.Context) error {
+	l := t.l().C(ctx).Mth("stop").Trc()
+	// cancel if running
+	if t.running.Load() {
+		if t.cancelFunc != nil {
+			t.cancelFunc()
+			t.cancelFunc = nil
+		}
+		t.running.Store(false)
+		l.Inf("ok")
+	}
+	return nil
+}
+
+func (t *telegramBotImpl) HandleWebhook(ctx context.Context, secret string, update *telegram.Update) error {
+	t.l().C(ctx).Mth("webhook").Trc()
+	if !telegram.CheckWebhookSecret(t.cfg.WebhookSecret, secret) {
+		return errors.ErrTelegramWebhookSecretInvalid(ctx)
+	}
+	return t.HandleUpdate(ctx, update)
+}
+
+func (t *telegramBotImpl) HandleUpdate(ctx context.Context, update *telegram.Update) error {
+	l := t.l().C(ctx).Mth("handle").F(log.FF{"updateId": update.UpdateId}).Trc()
+
+	// posts in channels and messages in groups might only verify the channel
+	if update.ChannelPost != nil {
+		return t.verifyChannel(ctx, update.ChannelPost)
+	}
+	msg := update.Message
+	if msg != nil && msg.Chat != nil && msg.Chat.Type != telegram.ChatTypePrivate {
+		return t.verifyChannel(ctx, msg)
+	}
+
+	// only messages of users in private chats with the bot are handled
+	if msg == nil || msg.From == nil || msg.Chat == nil {
+		return nil
+	}
+
+	cmd, args := msg.Command()
+	l.F(log.FF{"cmd": cmd}).Dbg()
+
+	var reply string
+	var err error
+	switch cmd {
+	case "start", "link":
+		if len(args) == 0 {
+			reply = telegramBotHelp
+			break
+		}
+		reply, err = t.link(ctx, msg, args[0])
+	case "subscribe", "filters", "pause", "resume":
+		var link *domain.TelegramLink
+		link, err = t.storage.GetLinkByTelegramUser(ctx, msg.From.Id)
+		if err != nil {
+			break
+		}
+		if link == nil {
+			reply = "Your account isn't linked. Request a link code in the panel and send /link &lt;code&gt;"
+			break
+		}
+		switch cmd {
+		case "subscribe":
+			reply, err = t.subscribe(ctx, link, args)
+		case "filters":
+			reply, err = t.filters(ctx, link)
+		case "pause":
+			reply, err = t.setActive(ctx, link, args, false)
+		case "resume":
+			reply, err = t.setActive(ctx, link, args, true)
+		}
+	case "top":
+		reply, err = t.top(ctx, args)
+	default:
+		reply = telegramBotHelp
+	}
+
+	// business errors are replied to the user
+	if appErr, ok := er.Is(err); ok && appErr.Type() == er.ErrTypeBusiness {
+		reply, err = html.EscapeString(appErr.Message()), nil
+	}
+	if err != nil {
+		return err
+	}
+	return t.notifier.Send(ctx, t.bot, int(msg.Chat.Id), reply)
+}
+
+func (t *telegramBotImpl) verifyChannel(ctx context.Context, msg *telegram.Message) error {
+	verified, err := t.verifier.HandleMessage(ctx, msg)
+	if err != nil {
+		return err
+	}
+	if verified {
+		t.l().C(ctx).Mth("verify-channel").F(log.FF{"chatId": msg.Chat.Id}).Inf("verified")
+	}
+	return nil
+}
+
+func (t *telegramBotImpl) link(ctx context.Context, msg *telegram.Message, code string) (string, error) {
+	linkCode, err := t.storage.TakeLinkCode(ctx, code)
+	if err != nil {
+		return "", err
+	}
+	if linkCode == nil || linkCode.ExpiresAt.Before(kit.Now()) {
+		return "", errors.ErrTelegramLinkCodeInvalid(ctx)
+	}
+	err = t.storage.SaveLink(ctx, &domain.TelegramLink{
+		UserId:         linkCode.UserId,
+		TelegramUserId: msg.From.Id,
+		ChatId:         msg.Chat.Id,
+		Username:       msg.From.Username,
+		CreatedAt:      kit.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return "Your account is linked\n\n" + telegramBotHelp, nil
+}
+
+// subscribe creates a subscription notifying the private chat with the bot
+// the first argument is a min profit if it's a number, others are assets
+func (t *telegramBotImpl) subscribe(ctx context.Context, link *domain.TelegramLink, args []string) (string, error) {
+	filter := &domain.SubscriptionChainFilter{}
+	if len(args) > 0 {
+		if minProfit, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "%"), 64); err == nil {
+			filter.MinProfit = minProfit
+			args = args[1:]
+		}
+	}
+	filter.Assets = args
+	subs, err := t.subscriptions.Create(ctx, &domain.Subscription{
+		UserId: link.UserId,
+		Filter: filter,
+		Notifications: []*domain.SubscriptionNotification{
+			{
+				Channel:  domain.SubscriptionNotificationChannelTelegram,
+				IsActive: true,
+				Telegram: &domain.SubscriptionTelegramNotificationDetails{Channel: int(link.ChatId)},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return "Subscribed: " + t.formatFilter(subs.Filter), nil
+}
+
+// userSubscriptions retrieves subscriptions of the user in a stable order, so they can be referred by number
+func (t *telegramBotImpl) userSubscriptions(ctx context.Context, link *domain.TelegramLink) ([]*domain.Subscription, error) {
+	subs, err := t.subscriptions.Search(ctx, &domain.SearchSubscriptionsRequest{UserId: link.UserId, WithInActive: true})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Id < subs[j].Id })
+	return subs, nil
+}
+
+func (t *telegramBotImpl) filters(ctx context.Context, link *domain.TelegramLink) (string, error) {
+	subs, err := t.userSubscriptions(ctx, link)
+	if err != nil {
+		return "", err
+	}
+	if len(subs) == 0 {
+		return "No subscriptions, use /subscribe", nil
+	}
+	var sb strings.Builder
+	sb.WriteString("<b>Your subscriptions</b>\n")
+	for i, s := range subs {
+		status := "active"
+		if !s.IsActive {
+			status = "paused"
+		}
+		channels := make([]string, 0, len(s.Notifications))
+		for _, n := range s.Notifications {
+			channels = append(channels, n.Channel)
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s, %s, notify: %s\n", i+1, status, t.formatFilter(s.Filter), strings.Join(channels, ", ")))
+	}
+	return sb.String(), nil
+}
+
+// setActive pauses or resumes all subscriptions of the user or the n-th one
+func (t *telegramBotImpl) setActive(ctx context.Context, link *domain.TelegramLink, args []string, active bool) (string, error) {
+	subs, err := t.userSubscriptions(ctx, link)
+	if err != nil {
+		return "", err
+	}
+	action := "Paused"
+	if active {
+		action = "Resumed"
+	}
+
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > len(subs) {
+			return fmt.Sprintf("Subscription number must be from 1 to %d, see /filters", len(subs)), nil
+		}
+		if active {
+			_, err = t.subscriptions.Activate(ctx, subs[n-1].Id)
+		} else {
+			_, err = t.subscriptions.Deactivate(ctx, subs[n-1].Id)
+		}
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s subscription %d", action, n), nil
+	}
+
+	count := 0
+	for _, s := range subs {
+		if s.IsActive == active {
+			continue
+		}
+		if active {
+			_, err = t.subscriptions.Activate(ctx, s.Id)
+		} else {
+			_, err = t.subscriptions.Deactivate(ctx, s.Id)
+		}
+		if err != nil {
+			return "", err
+		}
+		count++
+	}
+	return fmt.Sprintf("%s subscriptions: %d", action, count), nil
+}
+
+func (t *telegramBotImpl) top(ctx context.Context, args []string) (string, error) {
+	size := defaultTopChains
+	if len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil && n > 0 {
+			size = n
+		}
+	}
+	if size > maxTopChains {
+		size = maxTopChains
+	}
+	rs, err := t.arbitrage.GetProfitableChains(ctx, &domain.GetProfitableChainsRequest{
+		PagingRequest: kit.PagingRequest{
+			Size:   size,
+			SortBy: []*kit.SortRequest{{Field: "profit", Asc: false}},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(rs.Chains) == 0 {
+		return "No profitable chains found", nil
+	}
+	var sb strings.Builder
+	sb.WriteString("<b>Top chains</b>\n")
+	for i, c := range rs.Chains {
+		sb.WriteString(fmt.Sprintf("%d. %s<b>%s %.2f%%</b> %s <a href='%s/trading/details/%s'>details</a>\n",
+			i+1, profitClassEmoji(profitClass(c.ProfitShare)), html.EscapeString(c.Asset), (c.ProfitShare-1)*100,
+			html.EscapeString(strings.Join(c.ExchangeCodes, ", ")), t.panelUrl, c.Id))
+	}
+	return sb.String(), nil
+}
+
+func (t *telegramBotImpl) formatFilter(filter *domain.SubscriptionChainFilter) string {
+	var parts []string
+	if filter == nil || filter.MinProfit == 0 {
+		parts = append(parts, "any profit")
+	} else {
+		parts = append(parts, fmt.Sprintf("profit &gt;= %.2f%%", filter.MinProfit))
+	}
+	if filter != nil && len(filter.Assets) > 0 {
+		parts = append(parts, "assets: "+html.EscapeString(strings.Join(filter.Assets, ", ")))
+	}
+	if filter != nil && len(filter.Exchanges) > 0 {
+		parts = append(parts, "exchanges: "+html.EscapeString(strings.Join(filter.Exchanges, ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (t *telegramBotImpl) LinkCode(ctx context.Context, userId string) (*domain.TelegramLinkCode, error) {
+	t.l().C(ctx).Mth("link-code").F(log.FF{"userId": userId}).Trc()
+
+	if userId == "" {
+		return nil, errors.ErrTelegramUserIdEmpty(ctx)
+	}
+
+	ttl := defaultLinkCodeTtl
+	if t.cfg.LinkCodeTtlSec > 0 {
+		ttl = time.Duration(t.cfg.LinkCodeTtlSec) * time.Second
+	}
+	code := &domain.TelegramLinkCode{
+		Code:      kit.NewRandString(),
+		UserId:    userId,
+		ExpiresAt: kit.Now().Add(ttl),
+	}
+	if t.cfg.Username != "" {
+		code.Url = fmt.Sprintf("https://t.me/%s?start=%s", t.cfg.Username, code.Code)
+	}
+	if err := t.storage.SaveLinkCode(ctx, code); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+func (t *telegramBotImpl) GetLink(ctx context.Context, userId string) (*domain.TelegramLink, error) {
+	t.l().C(ctx).Mth("get-link").F(log.FF{"userId": userId}).Trc()
+	if userId == "" {
+		return nil, errors.ErrTelegramUserIdEmpty(ctx)
+	}
+	link, err := t.storage.GetLinkByUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, errors.ErrTelegramLinkNotFound(ctx)
+	}
+	return link, nil
+}
+
+func (t *telegramBotImpl) Unlink(ctx context.Context, userId string) error {
+	t.l().C(ctx).Mth("unlink").F(log.FF{"userId": userId}).Trc()
+	if _, err := t.GetLink(ctx, userId); err != nil {
+		return err
+	}
+	return t.storage.DeleteLink(ctx, userId)
+}
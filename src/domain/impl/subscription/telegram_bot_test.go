@@ -0,0 +1,231 @@
+package subscription
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+const (
+	testBotToken       = "bot-token"
+	testTelegramUserId = int64(1001)
+)
+
+type telegramBotTestSuite struct {
+	kitTestSuite.Suite
+	api           *telegram.TestBotApiServer
+	storage       *mocks.TelegramLinkStorage
+	subscriptions *mocks.SubscriptionService
+	arbitrage     *mocks.ArbitrageService
+	verifier      *mocks.TelegramChannelVerifier
+	cfg           *service.Config
+	bot           domain.TelegramBot
+}
+
+func (s *telegramBotTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestTelegramBotSuite(t *testing.T) {
+	suite.Run(t, new(telegramBotTestSuite))
+}
+
+func (s *telegramBotTestSuite) SetupTest() {
+	s.api = telegram.NewTestBotApiServer()
+	s.storage = &mocks.TelegramLinkStorage{}
+	s.subscriptions = &mocks.SubscriptionService{}
+	s.arbitrage = &mocks.ArbitrageService{}
+	s.verifier = &mocks.TelegramChannelVerifier{}
+	client := telegram.NewTelegram(service.LF(), s.api.Config())
+	s.bot = NewTelegramBot(client, NewTelegramNotifier(client, &TelegramOptions{Bot: testBotToken}), s.subscriptions, s.arbitrage, s.storage, s.verifier)
+	s.cfg = &service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{
+		Telegram: &service.ArbitrageNotificationTelegram{
+			Bot: testBotToken,
+			Commands: &service.TelegramCommands{
+				Enabled:        true,
+				Username:       "cryptocare_bot",
+				WebhookSecret:  "secret",
+				PollTimeoutSec: 1,
+			},
+		},
+	}}}
+	s.bot.Init(s.cfg)
+}
+
+func (s *telegramBotTestSuite) TearDownTest() {
+	_ = s.bot.Stop(s.Ctx)
+	s.api.Close()
+}
+
+// send handles the text as if the user sent it and returns the reply of the bot
+func (s *telegramBotTestSuite) send(text string) string {
+	before := len(s.api.Messages())
+	update := s.api.PushText(testTelegramUserId, "trader", text)
+	s.NoError(s.bot.HandleUpdate(s.Ctx, update))
+	msgs := s.api.Messages()
+	s.Require().Len(msgs, before+1)
+	s.Equal(testTelegramUserId, msgs[before].ChatId)
+	return msgs[before].Text
+}
+
+func (s *telegramBotTestSuite) linked() *domain.TelegramLink {
+	link := &domain.TelegramLink{UserId: kit.NewId(), TelegramUserId: testTelegramUserId, ChatId: testTelegramUserId}
+	s.storage.On("GetLinkByTelegramUser", s.Ctx, testTelegramUserId).Return(link, nil)
+	return link
+}
+
+func (s *telegramBotTestSuite) Test_LinkCode() {
+	s.storage.On("SaveLinkCode", s.Ctx, mock.AnythingOfType("*domain.TelegramLinkCode")).Return(nil)
+	userId := kit.NewId()
+	code, err := s.bot.LinkCode(s.Ctx, userId)
+	s.NoError(err)
+	s.NotEmpty(code.Code)
+	s.Equal(userId, code.UserId)
+	s.Equal("https://t.me/cryptocare_bot?start="+code.Code, code.Url)
+	s.True(code.ExpiresAt.After(kit.Now().Add(time.Minute * 9)))
+
+	_, err = s.bot.LinkCode(s.Ctx, "")
+	s.AssertAppErr(err, errors.ErrCodeTelegramUserIdEmpty)
+}
+
+func (s *telegramBotTestSuite) Test_Link() {
+	userId := kit.NewId()
+	s.storage.On("TakeLinkCode", s.Ctx, "code").Return(&domain.TelegramLinkCode{Code: "code", UserId: userId, ExpiresAt: kit.Now().Add(time.Minute)}, nil)
+	s.storage.On("SaveLink", s.Ctx, mock.MatchedBy(func(link *domain.TelegramLink) bool {
+		return link.UserId == userId && link.TelegramUserId == testTelegramUserId && link.ChatId == testTelegramUserId && link.Username == "trader"
+	})).Return(nil)
+	s.Contains(s.send("/start code"), "Your account is linked")
+	s.storage.AssertExpectations(s.T())
+}
+
+func (s *telegramBotTestSuite) Test_Link_WhenCodeExpired_Fail() {
+	s.storage.On("TakeLinkCode", s.Ctx, "code").Return(&domain.TelegramLinkCode{Code: "code", UserId: kit.NewId(), ExpiresAt: kit.Now().Add(-time.Second)}, nil)
+	s.storage.On("TakeLinkCode", s.Ctx, "unknown").Return(nil, nil)
+	s.Equal("link code invalid or expired", s.send("/link code"))
+	s.Equal("link code invalid or expired", s.send("/link unknown"))
+	s.storage.AssertNotCalled(s.T(), "SaveLink", mock.Anything, mock.Anything)
+}
+
+func (s *telegramBotTestSuite) Test_Command_WhenNotLinked() {
+	s.storage.On("GetLinkByTelegramUser", s.Ctx, testTelegramUserId).Return(nil, nil)
+	s.Contains(s.send("/subscribe 1.5"), "isn't linked")
+	s.subscriptions.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *telegramBotTestSuite) Test_Subscribe() {
+	link := s.linked()
+	s.subscriptions.On("Create", s.Ctx, mock.AnythingOfType("*domain.Subscription")).
+		Return(func(_ context.Context, subs *domain.Subscription) *domain.Subscription { return subs }, nil)
+
+	s.Equal("Subscribed: profit &gt;= 1.50%, assets: USDT, BTC", s.send("/subscribe 1.5% USDT BTC"))
+	subs := s.subscriptions.Calls[0].Arguments.Get(1).(*domain.Subscription)
+	s.Equal(link.UserId, subs.UserId)
+	s.Equal(domain.SubscriptionNotificationChannelTelegram, subs.Notifications[0].Channel)
+	s.Equal(int(testTelegramUserId), subs.Notifications[0].Telegram.Channel)
+	s.True(subs.Notifications[0].IsActive)
+
+	// without min profit
+	s.Equal("Subscribed: any profit, assets: ETH", s.send("/subscribe ETH"))
+}
+
+func (s *telegramBotTestSuite) Test_Subscribe_WhenInvalid_ReplyError() {
+	s.linked()
+	s.subscriptions.On("Create", s.Ctx, mock.AnythingOfType("*domain.Subscription")).Return(nil, errors.ErrSubscriptionMinProfitInvalid(s.Ctx))
+	s.NotEmpty(s.send("/subscribe 100"))
+}
+
+func (s *telegramBotTestSuite) Test_Filters_PauseResume() {
+	link := s.linked()
+	first := &domain.Subscription{Id: "1", UserId: link.UserId, IsActive: true, Filter: &domain.SubscriptionChainFilter{MinProfit: 1}}
+	second := &domain.Subscription{Id: "2", UserId: link.UserId, IsActive: false, Filter: &domain.SubscriptionChainFilter{Assets: []string{"USDT"}},
+		Notifications: []*domain.SubscriptionNotification{{Channel: domain.SubscriptionNotificationChannelTelegram}}}
+	s.subscriptions.On("Search", s.Ctx, &domain.SearchSubscriptionsRequest{UserId: link.UserId, WithInActive: true}).
+		Return([]*domain.Subscription{second, first}, nil)
+
+	reply := s.send("/filters")
+	s.Contains(reply, "1. active, profit &gt;= 1.00%, notify: \n")
+	s.Contains(reply, "2. paused, any profit, assets: USDT, notify: telegram\n")
+
+	s.subscriptions.On("Activate", s.Ctx, "2").Return(second, nil)
+	s.Equal("Resumed subscription 2", s.send("/resume 2"))
+
+	// only active subscriptions are paused
+	s.subscriptions.On("Deactivate", s.Ctx, "1").Return(first, nil)
+	s.Equal("Paused subscriptions: 1", s.send("/pause"))
+	s.subscriptions.AssertNumberOfCalls(s.T(), "Deactivate", 1)
+
+	s.Equal("Subscription number must be from 1 to 2, see /filters", s.send("/pause 3"))
+}
+
+func (s *telegramBotTestSuite) Test_Top() {
+	s.arbitrage.On("GetProfitableChains", s.Ctx, mock.MatchedBy(func(rq *domain.GetProfitableChainsRequest) bool {
+		return rq.Size == maxTopChains && rq.SortBy[0].Field == "profit" && !rq.SortBy[0].Asc
+	})).Return(&domain.GetProfitableChainsResponse{Chains: []*domain.ProfitableChain{
+		{Id: "chain-id", Asset: "USDT", ProfitShare: 1.0235, ExchangeCodes: []string{"binance", "garantex"}},
+	}}, nil)
+	reply := s.send("/top 50")
+	s.Contains(reply, "<b>USDT 2.35%</b> binance, garantex <a href='https://panel.cryptocare.ai/trading/details/chain-id'>details</a>")
+}
+
+func (s *telegramBotTestSuite) Test_Help_And_GroupChats() {
+	s.Contains(s.send("hello"), "/subscribe")
+
+	// messages in groups only verify the group, commands aren't replied
+	update := s.api.PushUpdate(&telegram.Update{Message: &telegram.Message{
+		From: &telegram.User{Id: testTelegramUserId},
+		Chat: &telegram.Chat{Id: -100, Type: "group"},
+		Text: "/top",
+	}})
+	s.verifier.On("HandleMessage", s.Ctx, update.Message).Return(false, nil)
+	s.NoError(s.bot.HandleUpdate(s.Ctx, update))
+	s.Len(s.api.Messages(), 1)
+	s.verifier.AssertExpectations(s.T())
+}
+
+func (s *telegramBotTestSuite) Test_ChannelPost_Verify() {
+	update := s.api.PushChannelPost(-100, "/verify code")
+	s.verifier.On("HandleMessage", s.Ctx, update.ChannelPost).Return(true, nil)
+	s.NoError(s.bot.HandleUpdate(s.Ctx, update))
+	s.verifier.AssertExpectations(s.T())
+	s.Empty(s.api.Messages())
+}
+
+func (s *telegramBotTestSuite) Test_HandleWebhook_WhenSecretInvalid_Fail() {
+	update := &telegram.Update{Message: &telegram.Message{From: &telegram.User{Id: 1}, Chat: &telegram.Chat{Id: 1, Type: telegram.ChatTypePrivate}, Text: "/help"}}
+	s.AssertAppErr(s.bot.HandleWebhook(s.Ctx, "wrong", update), errors.ErrCodeTelegramWebhookSecretInvalid)
+	s.NoError(s.bot.HandleWebhook(s.Ctx, "secret", update))
+	s.Len(s.api.Messages(), 1)
+}
+
+func (s *telegramBotTestSuite) Test_Run_Polling() {
+	s.NoError(s.bot.Run(s.Ctx))
+	s.AssertAppErr(s.bot.Run(s.Ctx), errors.ErrCodeTelegramBotAlreadyRun)
+
+	s.api.PushText(testTelegramUserId, "trader", "/help")
+	s.Eventually(func() bool { return len(s.api.Messages()) == 1 }, time.Second*3, time.Millisecond*50)
+}
+
+func (s *telegramBotTestSuite) Test_Run_Webhook() {
+	s.cfg.Arbitrage.Notification.Telegram.Commands.WebhookUrl = "https://api.cryptocare.ai/api/telegram/webhook"
+	s.bot.Init(s.cfg)
+	s.NoError(s.bot.Run(s.Ctx))
+	url, secret := s.api.Webhook()
+	s.Equal("https://api.cryptocare.ai/api/telegram/webhook", url)
+	s.Equal("secret", secret)
+}
+
+func (s *telegramBotTestSuite) Test_Run_WhenDisabled() {
+	s.cfg.Arbitrage.Notification.Telegram.Commands.Enabled = false
+	s.bot.Init(s.cfg)
+	s.NoError(s.bot.Run(s.Ctx))
+	s.NoError(s.bot.Run(s.Ctx))
+}
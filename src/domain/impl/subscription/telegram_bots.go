@@ -0,0 +1,507 @@
+package subscription
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBotsCooldownSec = 60
+	defaultBotsReloadSec   = 30
+)
+
+// telegramBotEntry bot with decrypted token
+type telegramBotEntry struct {
+	bot   *domain.TelegramBotAccount
+	token string
+}
+
+// telegramBotCandidate bot a message is tried to be sent by
+type telegramBotCandidate struct {
+	entry *telegramBotEntry
+	id    string
+	token string
+}
+
+type telegramBotRegistryImpl struct {
+	sync.Mutex
+	telegram   telegram.Telegram
+	storage    domain.TelegramBotAccountStorage
+	tokenKey   string
+	rateLimit  *telegram.RateLimit
+	cooldown   time.Duration
+	reload     time.Duration
+	defaultBot *telegramBotEntry
+	bots       map[string]*telegramBotEntry
+	loadedAt   time.Time
+}
+
+func NewTelegramBotRegistry(telegram telegram.Telegram, storage domain.TelegramBotAccountStorage) domain.TelegramBotRegistry {
+	return &telegramBotRegistryImpl{
+		telegram: telegram,
+		storage:  storage,
+		cooldown: defaultBotsCooldownSec * time.Second,
+		reload:   defaultBotsReloadSec * time.Second,
+		defaultBot: &telegramBotEntry{bot: &domain.TelegramBotAccount{
+			Id:     domain.TelegramBotDefaultId,
+			Name:   domain.TelegramBotDefaultId,
+			Health: domain.TelegramBotHealthy,
+		}},
+		bots: make(map[string]*telegramBotEntry),
+	}
+}
+
+func (r *telegramBotRegistryImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-bots")
+}
+
+func (r *telegramBotRegistryImpl) Init(cfg *service.Config) {
+	if cfg.Arbitrage == nil || cfg.Arbitrage.Notification == nil || cfg.Arbitrage.Notification.Telegram == nil {
+		return
+	}
+	tgCfg := cfg.Arbitrage.Notification.Telegram
+	r.defaultBot.token = tgCfg.Bot
+	r.defaultBot.bot.IsActive = tgCfg.Bot != ""
+	if tgCfg.Commands != nil {
+		r.defaultBot.bot.Username = tgCfg.Commands.Username
+	}
+	r.rateLimit = tgCfg.RateLimit
+	if tgCfg.Bots == nil {
+		return
+	}
+	r.tokenKey = tgCfg.Bots.TokenKey
+	if tgCfg.Bots.CooldownSec > 0 {
+		r.cooldown = time.Duration(tgCfg.Bots.CooldownSec) * time.Second
+	}
+	if tgCfg.Bots.ReloadSec > 0 {
+		r.reload = time.Duration(tgCfg.Bots.ReloadSec) * time.Second
+	}
+}
+
+// botRateLimit returns rate limit of the bot, nil if the configured one is used
+func (r *telegramBotRegistryImpl) botRateLimit(bot *domain.TelegramBotAccount) *telegram.RateLimit {
+	if bot.RatePerSec <= 0 {
+		return nil
+	}
+	rateLimit := &telegram.RateLimit{}
+	if r.rateLimit != nil {
+		*rateLimit = *r.rateLimit
+	}
+	rateLimit.GlobalPerSec = bot.RatePerSec
+	return rateLimit
+}
+
+// put puts the bot to the cache, the client is given the rate limit of the bot if it's changed
+// must be called under lock
+func (r *telegramBotRegistryImpl) put(bot *domain.TelegramBotAccount, token string) {
+	prev, ok := r.bots[bot.Id]
+	if !ok || prev.token != token || prev.bot.RatePerSec != bot.RatePerSec {
+		r.telegram.SetRateLimit(token, r.botRateLimit(bot))
+	}
+	r.bots[bot.Id] = &telegramBotEntry{bot: bot, token: token}
+}
+
+// load reloads bots from storage if the cache is outdated
+// must be called under lock
+func (r *telegramBotRegistryImpl) load(ctx context.Context) error {
+	now := kit.Now()
+	if now.Sub(r.loadedAt) < r.reload {
+		return nil
+	}
+	bots, err := r.storage.GetBots(ctx)
+	if err != nil {
+		return err
+	}
+	prev := r.bots
+	r.bots = make(map[string]*telegramBotEntry, len(bots))
+	for _, bot := range bots {
+		token, err := kit.Decrypt(r.tokenKey, bot.EncryptedToken)
+		if err != nil {
+			// the key has been changed, the bot is skipped until it's updated with a new token
+			r.l().C(ctx).Mth("load").F(log.FF{"botId": bot.Id}).E(errors.ErrTelegramBotTokenEncrypt(err, ctx)).Err()
+			continue
+		}
+		if p, ok := prev[bot.Id]; ok {
+			r.bots[bot.Id] = p
+		}
+		r.put(bot, token)
+	}
+	r.loadedAt = now
+	return nil
+}
+
+// isDefaultBot checks if the default bot is meant, it's taken when no bot is selected
+func isDefaultBot(botId string) bool {
+	return botId == "" || botId == domain.TelegramBotDefaultId
+}
+
+// entry retrieves the bot from the cache, nil if not found
+// must be called under lock
+func (r *telegramBotRegistryImpl) entry(ctx context.Context, botId string) (*telegramBotEntry, error) {
+	if isDefaultBot(botId) {
+		return r.defaultBot, nil
+	}
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+	return r.bots[botId], nil
+}
+
+func copyBot(bot *domain.TelegramBotAccount) *domain.TelegramBotAccount {
+	c := *bot
+	return &c
+}
+
+// checkToken checks the token by Bot API and encrypts it
+func (r *telegramBotRegistryImpl) checkToken(ctx context.Context, token string) (*telegram.User, string, error) {
+	if r.tokenKey == "" {
+		return nil, "", errors.ErrTelegramBotTokenKeyEmpty(ctx)
+	}
+	me, err := r.telegram.GetMe(ctx, token)
+	if err != nil {
+		return nil, "", errors.ErrTelegramBotTokenInvalid(err, ctx)
+	}
+	encrypted, err := kit.Encrypt(r.tokenKey, token)
+	if err != nil {
+		return nil, "", errors.ErrTelegramBotTokenEncrypt(err, ctx)
+	}
+	return me, encrypted, nil
+}
+
+// validateRequest validates the request of the bot
+// must be called under lock
+func (r *telegramBotRegistryImpl) validateRequest(ctx context.Context, botId string, rq *domain.TelegramBotAccountRequest) error {
+	if rq.Name == "" {
+		return errors.ErrTelegramBotNameEmpty(ctx)
+	}
+	if rq.SecondaryId == "" {
+		return nil
+	}
+	secondary, err := r.entry(ctx, rq.SecondaryId)
+	if err != nil {
+		return err
+	}
+	if secondary == nil || rq.SecondaryId == botId {
+		return errors.ErrTelegramBotSecondaryInvalid(ctx, rq.SecondaryId)
+	}
+	return nil
+}
+
+func (r *telegramBotRegistryImpl) Create(ctx context.Context, rq *domain.TelegramBotAccountRequest) (*domain.TelegramBotAccount, error) {
+	r.l().C(ctx).Mth("create").Dbg()
+
+	r.Lock()
+	defer r.Unlock()
+
+	// the cache is loaded before the bot is put there, otherwise it's reloaded without the bot at once
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.validateRequest(ctx, "", rq); err != nil {
+		return nil, err
+	}
+	me, encrypted, err := r.checkToken(ctx, rq.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := kit.Now()
+	bot := &domain.TelegramBotAccount{
+		Id:             kit.NewId(),
+		Name:           rq.Name,
+		Username:       me.Username,
+		EncryptedToken: encrypted,
+		UserIds:        kit.Strings(rq.UserIds).Distinct(),
+		SecondaryId:    rq.SecondaryId,
+		RatePerSec:     rq.RatePerSec,
+		IsActive:       rq.IsActive,
+		Health:         domain.TelegramBotHealthy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := r.storage.SaveBot(ctx, bot); err != nil {
+		return nil, err
+	}
+	r.put(bot, rq.Token)
+	return copyBot(bot), nil
+}
+
+func (r *telegramBotRegistryImpl) Update(ctx context.Context, botId string, rq *domain.TelegramBotAccountRequest) (*domain.TelegramBotAccount, error) {
+	r.l().C(ctx).Mth("update").F(log.FF{"botId": botId}).Dbg()
+
+	if isDefaultBot(botId) {
+		return nil, errors.ErrTelegramBotDefaultReadOnly(ctx)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	e, err := r.entry(ctx, botId)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, errors.ErrTelegramBotNotFound(ctx, botId)
+	}
+	if err := r.validateRequest(ctx, botId, rq); err != nil {
+		return nil, err
+	}
+
+	bot, token := copyBot(e.bot), e.token
+	if rq.Token != "" && rq.Token != token {
+		me, encrypted, err := r.checkToken(ctx, rq.Token)
+		if err != nil {
+			return nil, err
+		}
+		bot.Username, bot.EncryptedToken, token = me.Username, encrypted, rq.Token
+		// a new token gives the bot a fresh start
+		bot.Health, bot.LastError, bot.FailedAt = domain.TelegramBotHealthy, "", nil
+	}
+	bot.Name = rq.Name
+	bot.UserIds = kit.Strings(rq.UserIds).Distinct()
+	bot.SecondaryId = rq.SecondaryId
+	bot.RatePerSec = rq.RatePerSec
+	bot.IsActive = rq.IsActive
+	bot.UpdatedAt = kit.Now()
+
+	if err := r.storage.SaveBot(ctx, bot); err != nil {
+		return nil, err
+	}
+	r.put(bot, token)
+	return copyBot(bot), nil
+}
+
+func (r *telegramBotRegistryImpl) Delete(ctx context.Context, botId string) error {
+	r.l().C(ctx).Mth("delete").F(log.FF{"botId": botId}).Dbg()
+
+	if isDefaultBot(botId) {
+		return errors.ErrTelegramBotDefaultReadOnly(ctx)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	e, err := r.entry(ctx, botId)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return errors.ErrTelegramBotNotFound(ctx, botId)
+	}
+	if err := r.storage.DeleteBot(ctx, botId); err != nil {
+		return err
+	}
+	delete(r.bots, botId)
+	return nil
+}
+
+func (r *telegramBotRegistryImpl) Get(ctx context.Context, botId string) (*domain.TelegramBotAccount, error) {
+	r.l().C(ctx).Mth("get").F(log.FF{"botId": botId}).Trc()
+
+	r.Lock()
+	defer r.Unlock()
+
+	e, err := r.entry(ctx, botId)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, errors.ErrTelegramBotNotFound(ctx, botId)
+	}
+	return copyBot(e.bot), nil
+}
+
+func (r *telegramBotRegistryImpl) GetAll(ctx context.Context) ([]*domain.TelegramBotAccount, error) {
+	r.l().C(ctx).Mth("get-all").Trc()
+
+	r.Lock()
+	defer r.Unlock()
+
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+	bots := make([]*domain.TelegramBotAccount, 0, len(r.bots))
+	for _, e := range r.bots {
+		bots = append(bots, copyBot(e.bot))
+	}
+	sort.Slice(bots, func(i, j int) bool { return bots[i].CreatedAt.Before(bots[j].CreatedAt) })
+	return append([]*domain.TelegramBotAccount{copyBot(r.defaultBot.bot)}, bots...), nil
+}
+
+// allowed checks if the user is allowed to select the bot, bots without users are shared
+func allowed(bot *domain.TelegramBotAccount, userId string) bool {
+	return len(bot.UserIds) == 0 || kit.Strings(bot.UserIds).Contains(userId)
+}
+
+func (r *telegramBotRegistryImpl) Validate(ctx context.Context, userId, botId string) error {
+	r.l().C(ctx).Mth("validate").F(log.FF{"botId": botId}).Trc()
+
+	r.Lock()
+	defer r.Unlock()
+
+	e, err := r.entry(ctx, botId)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return errors.ErrTelegramBotNotFound(ctx, botId)
+	}
+	if !e.bot.IsActive || !allowed(e.bot, userId) {
+		return errors.ErrTelegramBotNotAllowed(ctx, botId)
+	}
+	return nil
+}
+
+// primary returns the bot selected by the subscription, otherwise the bot assigned to the user, otherwise the default one
+// must be called under lock
+func (r *telegramBotRegistryImpl) primary(userId, botId string) *telegramBotEntry {
+	if e, ok := r.bots[botId]; ok && e.bot.IsActive {
+		return e
+	}
+	var userBot *telegramBotEntry
+	for _, e := range r.bots {
+		if !e.bot.IsActive || !kit.Strings(e.bot.UserIds).Contains(userId) {
+			continue
+		}
+		// the oldest bot of the user is taken, so the choice is stable
+		if userBot == nil || e.bot.CreatedAt.Before(userBot.bot.CreatedAt) {
+			userBot = e
+		}
+	}
+	if userBot != nil {
+		return userBot
+	}
+	return r.defaultBot
+}
+
+// candidates returns bots the message is sent by, the primary bot and its secondary ones
+// bots failed within cooldown are tried last
+// must be called under lock
+func (r *telegramBotRegistryImpl) candidates(ctx context.Context, userId, botId string) []*telegramBotEntry {
+	// messages keep going by the cached bots if storage isn't available
+	if err := r.load(ctx); err != nil {
+		r.l().C(ctx).Mth("candidates").E(err).Err("bots aren't reloaded")
+	}
+	var healthy, failed []*telegramBotEntry
+	visited := make(map[string]bool)
+	now := kit.Now()
+	for e := r.primary(userId, botId); e != nil && !visited[e.bot.Id]; {
+		visited[e.bot.Id] = true
+		if e.bot.IsActive && e.token != "" {
+			if e.bot.Health == domain.TelegramBotUnhealthy && e.bot.FailedAt != nil && now.Sub(*e.bot.FailedAt) < r.cooldown {
+				failed = append(failed, e)
+			} else {
+				healthy = append(healthy, e)
+			}
+		}
+		switch {
+		case e.bot.SecondaryId == "":
+			e = nil
+		case isDefaultBot(e.bot.SecondaryId):
+			e = r.defaultBot
+		default:
+			e = r.bots[e.bot.SecondaryId]
+		}
+	}
+	return append(healthy, failed...)
+}
+
+// failover checks if the message is to be sent by the next bot and if the error means the bot is unhealthy
+func failover(err error) (next, unhealthy bool) {
+	// the chat might be reachable by another bot, but the bot is fine
+	if telegram.IsRejected(err) {
+		return true, false
+	}
+	if _, ok := telegram.RetryAfter(err); ok {
+		return true, false
+	}
+	// the message is the problem, not the bot
+	if appErr, ok := er.Is(err); ok && appErr.Code() == telegram.ErrCodeTelegramMessageTooLong {
+		return false, false
+	}
+	return true, true
+}
+
+// setHealth updates health of the bot, the change is saved, so other instances learn it on reload
+func (r *telegramBotRegistryImpl) setHealth(ctx context.Context, e *telegramBotEntry, cause error) {
+	r.Lock()
+	defer r.Unlock()
+
+	bot := copyBot(e.bot)
+	if cause == nil {
+		if bot.Health == domain.TelegramBotHealthy {
+			return
+		}
+		bot.Health = domain.TelegramBotHealthy
+	} else {
+		now := kit.Now()
+		bot.Health, bot.LastError, bot.FailedAt = domain.TelegramBotUnhealthy, cause.Error(), &now
+	}
+	e.bot = bot
+	if bot.Id == domain.TelegramBotDefaultId {
+		return
+	}
+	if err := r.storage.SaveBot(ctx, bot); err != nil {
+		r.l().C(ctx).Mth("set-health").F(log.FF{"botId": bot.Id}).E(err).Err()
+	}
+}
+
+func (r *telegramBotRegistryImpl) Do(ctx context.Context, userId, botId string, send func(token string) error) (string, error) {
+	l := r.l().C(ctx).Mth("do").F(log.FF{"userId": userId, "botId": botId})
+
+	// ids and tokens are copied under lock, health of the bots might be changed by other goroutines while sending
+	r.Lock()
+	entries := r.candidates(ctx, userId, botId)
+	candidates := make([]telegramBotCandidate, 0, len(entries))
+	for _, e := range entries {
+		candidates = append(candidates, telegramBotCandidate{entry: e, id: e.bot.Id, token: e.token})
+	}
+	r.Unlock()
+	if len(candidates) == 0 {
+		return "", telegram.ErrTelegramBotEmpty(ctx)
+	}
+
+	var err error
+	for i, c := range candidates {
+		// sending might take a while because of rate limits, so it's done without lock
+		err = send(c.token)
+		if err == nil {
+			r.setHealth(ctx, c.entry, nil)
+			return c.id, nil
+		}
+		next, unhealthy := failover(err)
+		if unhealthy {
+			r.setHealth(ctx, c.entry, err)
+		}
+		if !next {
+			return "", err
+		}
+		if i < len(candidates)-1 {
+			l.F(log.FF{"failedBotId": c.id}).E(err).Warn("failover to secondary bot")
+		}
+	}
+	return "", err
+}
+
+func (r *telegramBotRegistryImpl) Token(ctx context.Context, botId string) (string, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	e, err := r.entry(ctx, botId)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", errors.ErrTelegramBotNotFound(ctx, botId)
+	}
+	return e.token, nil
+}
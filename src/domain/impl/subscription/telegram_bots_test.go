@@ -0,0 +1,351 @@
+package subscription
+
+import (
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testTokenKey = "token-key"
+
+// newDefaultBotRegistry creates registry with the default bot only
+func newDefaultBotRegistry(client telegram.Telegram, bot string) domain.TelegramBotRegistry {
+	storage := &mocks.TelegramBotAccountStorage{}
+	storage.On("GetBots", mock.Anything).Return(nil, nil)
+	r := NewTelegramBotRegistry(client, storage)
+	r.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{
+		Telegram: &service.ArbitrageNotificationTelegram{Bot: bot},
+	}}})
+	return r
+}
+
+type telegramBotsTestSuite struct {
+	kitTestSuite.Suite
+	api      *telegram.TestBotApiServer
+	client   *mocks.Telegram
+	storage  *mocks.TelegramBotAccountStorage
+	registry domain.TelegramBotRegistry
+	saved    []*domain.TelegramBotAccount
+}
+
+func (s *telegramBotsTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestTelegramBotsSuite(t *testing.T) {
+	suite.Run(t, new(telegramBotsTestSuite))
+}
+
+func (s *telegramBotsTestSuite) SetupTest() {
+	s.client = &mocks.Telegram{}
+	s.client.On("SetRateLimit", mock.AnythingOfType("string"), mock.Anything).Return()
+	s.storage = &mocks.TelegramBotAccountStorage{}
+	s.saved = nil
+	s.storage.On("SaveBot", mock.Anything, mock.AnythingOfType("*domain.TelegramBotAccount")).
+		Run(func(args mock.Arguments) {
+			b := *args.Get(1).(*domain.TelegramBotAccount)
+			s.saved = append(s.saved, &b)
+		}).
+		Return(nil)
+	s.registry = NewTelegramBotRegistry(s.client, s.storage)
+	s.registry.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{
+		Telegram: &service.ArbitrageNotificationTelegram{
+			Bot:       "default-token",
+			RateLimit: &telegram.RateLimit{ChatPerSec: 2},
+			Bots:      &service.TelegramBots{TokenKey: testTokenKey},
+		},
+	}}})
+}
+
+// stored creates a stored bot with the encrypted token
+func (s *telegramBotsTestSuite) stored(id, token string, userIds ...string) *domain.TelegramBotAccount {
+	encrypted, err := kit.Encrypt(testTokenKey, token)
+	s.NoError(err)
+	return &domain.TelegramBotAccount{
+		Id:             id,
+		Name:           id,
+		EncryptedToken: encrypted,
+		UserIds:        userIds,
+		IsActive:       true,
+		Health:         domain.TelegramBotHealthy,
+		CreatedAt:      kit.Now(),
+	}
+}
+
+func (s *telegramBotsTestSuite) expectBots(bots ...*domain.TelegramBotAccount) {
+	s.storage.On("GetBots", mock.Anything).Return(bots, nil)
+}
+
+// do sends by the registry and returns tokens the message has been tried with
+func (s *telegramBotsTestSuite) do(userId, botId string, errs map[string]error) (string, []string, error) {
+	var tried []string
+	sentBy, err := s.registry.Do(s.Ctx, userId, botId, func(token string) error {
+		tried = append(tried, token)
+		return errs[token]
+	})
+	return sentBy, tried, err
+}
+
+func (s *telegramBotsTestSuite) Test_Create() {
+	s.expectBots()
+	s.client.On("GetMe", s.Ctx, "partner-token").Return(&telegram.User{Id: 1, IsBot: true, Username: "partner_bot"}, nil)
+
+	bot, err := s.registry.Create(s.Ctx, &domain.TelegramBotAccountRequest{
+		Name:        "partner",
+		Token:       "partner-token",
+		UserIds:     []string{"user", "user"},
+		SecondaryId: domain.TelegramBotDefaultId,
+		RatePerSec:  5,
+		IsActive:    true,
+	})
+	s.NoError(err)
+	s.Equal("partner_bot", bot.Username)
+	s.Equal([]string{"user"}, bot.UserIds)
+	s.Equal(domain.TelegramBotHealthy, bot.Health)
+	// the token is stored encrypted
+	s.Len(s.saved, 1)
+	s.NotContains(s.saved[0].EncryptedToken, "partner-token")
+	token, err := kit.Decrypt(testTokenKey, s.saved[0].EncryptedToken)
+	s.NoError(err)
+	s.Equal("partner-token", token)
+	// the bot has its own rate budget
+	s.client.AssertCalled(s.T(), "SetRateLimit", "partner-token", &telegram.RateLimit{GlobalPerSec: 5, ChatPerSec: 2})
+
+	token, err = s.registry.Token(s.Ctx, bot.Id)
+	s.NoError(err)
+	s.Equal("partner-token", token)
+}
+
+func (s *telegramBotsTestSuite) Test_Create_Fail() {
+	s.expectBots()
+	s.client.On("GetMe", s.Ctx, "revoked").Return(nil, fmt.Errorf("unauthorized"))
+
+	_, err := s.registry.Create(s.Ctx, &domain.TelegramBotAccountRequest{Token: "token"})
+	s.AssertAppErr(err, errors.ErrCodeTelegramBotNameEmpty)
+	_, err = s.registry.Create(s.Ctx, &domain.TelegramBotAccountRequest{Name: "partner", Token: "revoked"})
+	s.AssertAppErr(err, errors.ErrCodeTelegramBotTokenInvalid)
+	_, err = s.registry.Create(s.Ctx, &domain.TelegramBotAccountRequest{Name: "partner", Token: "token", SecondaryId: "unknown"})
+	s.AssertAppErr(err, errors.ErrCodeTelegramBotSecondaryInvalid)
+
+	// tokens can't be stored without key
+	s.registry.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{
+		Telegram: &service.ArbitrageNotificationTelegram{Bot: "default-token", Bots: &service.TelegramBots{}},
+	}}})
+	_, err = s.registry.Create(s.Ctx, &domain.TelegramBotAccountRequest{Name: "partner", Token: "token"})
+	s.AssertAppErr(err, errors.ErrCodeTelegramBotTokenKeyEmpty)
+	s.Empty(s.saved)
+}
+
+func (s *telegramBotsTestSuite) Test_Update_KeepsToken() {
+	s.expectBots(s.stored("partner", "partner-token"))
+
+	bot, err := s.registry.Update(s.Ctx, "partner", &domain.TelegramBotAccountRequest{Name: "renamed", UserIds: []string{"user"}, IsActive: true})
+	s.NoError(err)
+	s.Equal("renamed", bot.Name)
+	s.Equal([]string{"user"}, bot.UserIds)
+	s.client.AssertNotCalled(s.T(), "GetMe", mock.Anything, mock.Anything)
+	token, err := s.registry.Token(s.Ctx, "partner")
+	s.NoError(err)
+	s.Equal("partner-token", token)
+
+	_, err = s.registry.Update(s.Ctx, "partner", &domain.TelegramBotAccountRequest{Name: "renamed", SecondaryId: "partner"})
+	s.AssertAppErr(err, errors.ErrCodeTelegramBotSecondaryInvalid)
+	_, err = s.registry.Update(s.Ctx, domain.TelegramBotDefaultId, &domain.TelegramBotAccountRequest{Name: "default"})
+	s.AssertAppErr(err, errors.ErrCodeTelegramBotDefaultReadOnly)
+	_, err = s.registry.Update(s.Ctx, "unknown", &domain.TelegramBotAccountRequest{Name: "unknown"})
+	s.AssertAppErr(err, errors.ErrCodeTelegramBotNotFound)
+}
+
+func (s *telegramBotsTestSuite) Test_Delete() {
+	s.expectBots(s.stored("partner", "partner-token"))
+	s.storage.On("DeleteBot", s.Ctx, "partner").Return(nil)
+
+	s.NoError(s.registry.Delete(s.Ctx, "partner"))
+	_, err := s.registry.Get(s.Ctx, "partner")
+	s.AssertAppErr(err, errors.ErrCodeTelegramBotNotFound)
+	s.AssertAppErr(s.registry.Delete(s.Ctx, domain.TelegramBotDefaultId), errors.ErrCodeTelegramBotDefaultReadOnly)
+}
+
+func (s *telegramBotsTestSuite) Test_GetAll_DefaultFirst() {
+	s.expectBots(s.stored("partner", "partner-token"))
+	bots, err := s.registry.GetAll(s.Ctx)
+	s.NoError(err)
+	s.Len(bots, 2)
+	s.Equal(domain.TelegramBotDefaultId, bots[0].Id)
+	s.True(bots[0].IsActive)
+	s.Equal("partner", bots[1].Id)
+}
+
+func (s *telegramBotsTestSuite) Test_Validate() {
+	inactive := s.stored("inactive", "inactive-token")
+	inactive.IsActive = false
+	s.expectBots(s.stored("partner", "partner-token", "partner-user"), s.stored("shared", "shared-token"), inactive)
+
+	s.NoError(s.registry.Validate(s.Ctx, "user", domain.TelegramBotDefaultId))
+	s.NoError(s.registry.Validate(s.Ctx, "user", "shared"))
+	s.NoError(s.registry.Validate(s.Ctx, "partner-user", "partner"))
+	// branded bots are only for their users
+	s.AssertAppErr(s.registry.Validate(s.Ctx, "user", "partner"), errors.ErrCodeTelegramBotNotAllowed)
+	s.AssertAppErr(s.registry.Validate(s.Ctx, "user", "inactive"), errors.ErrCodeTelegramBotNotAllowed)
+	s.AssertAppErr(s.registry.Validate(s.Ctx, "user", "unknown"), errors.ErrCodeTelegramBotNotFound)
+}
+
+func (s *telegramBotsTestSuite) Test_Do_Selection() {
+	s.expectBots(s.stored("partner", "partner-token", "partner-user"), s.stored("shared", "shared-token"))
+
+	// the bot of the user is taken by default
+	sentBy, tried, err := s.do("partner-user", "", nil)
+	s.NoError(err)
+	s.Equal("partner", sentBy)
+	s.Equal([]string{"partner-token"}, tried)
+
+	// the bot selected by the subscription goes first
+	sentBy, _, err = s.do("partner-user", "shared", nil)
+	s.NoError(err)
+	s.Equal("shared", sentBy)
+
+	// other users are notified by the default bot
+	sentBy, tried, err = s.do("user", "", nil)
+	s.NoError(err)
+	s.Equal(domain.TelegramBotDefaultId, sentBy)
+	s.Equal([]string{"default-token"}, tried)
+
+	// the selected bot is gone
+	sentBy, _, err = s.do("user", "deleted", nil)
+	s.NoError(err)
+	s.Equal(domain.TelegramBotDefaultId, sentBy)
+}
+
+func (s *telegramBotsTestSuite) Test_Do_Failover() {
+	partner := s.stored("partner", "partner-token", "partner-user")
+	partner.SecondaryId = "reserve"
+	reserve := s.stored("reserve", "reserve-token")
+	// cycles are broken
+	reserve.SecondaryId = "partner"
+	s.expectBots(partner, reserve)
+
+	sentBy, tried, err := s.do("partner-user", "", map[string]error{"partner-token": fmt.Errorf("timeout")})
+	s.NoError(err)
+	s.Equal("reserve", sentBy)
+	s.Equal([]string{"partner-token", "reserve-token"}, tried)
+	// the failed bot is marked unhealthy
+	s.Len(s.saved, 1)
+	s.Equal("partner", s.saved[0].Id)
+	s.Equal(domain.TelegramBotUnhealthy, s.saved[0].Health)
+	s.Equal("timeout", s.saved[0].LastError)
+	s.NotNil(s.saved[0].FailedAt)
+
+	// the failed bot is tried last within cooldown
+	sentBy, tried, err = s.do("partner-user", "", nil)
+	s.NoError(err)
+	s.Equal("reserve", sentBy)
+	s.Equal([]string{"reserve-token"}, tried)
+
+	// all bots fail
+	_, tried, err = s.do("partner-user", "", map[string]error{"partner-token": fmt.Errorf("timeout"), "reserve-token": fmt.Errorf("unavailable")})
+	s.Error(err)
+	s.Equal([]string{"reserve-token", "partner-token"}, tried)
+}
+
+func (s *telegramBotsTestSuite) Test_Do_Concurrently() {
+	partner := s.stored("partner", "partner-token", "partner-user")
+	partner.SecondaryId = domain.TelegramBotDefaultId
+	s.expectBots(partner)
+
+	// health of the bots is changed by some senders while others are sending
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(fail bool) {
+			defer wg.Done()
+			_, err := s.registry.Do(s.Ctx, "partner-user", "", func(token string) error {
+				if fail && token == "partner-token" {
+					return fmt.Errorf("timeout")
+				}
+				return nil
+			})
+			s.NoError(err)
+		}(i%2 == 0)
+	}
+	wg.Wait()
+}
+
+func (s *telegramBotsTestSuite) Test_Do_WhenRecovered_Healthy() {
+	partner := s.stored("partner", "partner-token", "partner-user")
+	failedAt := kit.Now().Add(-time.Hour)
+	partner.Health, partner.FailedAt = domain.TelegramBotUnhealthy, &failedAt
+	s.expectBots(partner)
+
+	// cooldown has passed, so the bot is tried first again
+	sentBy, _, err := s.do("partner-user", "", nil)
+	s.NoError(err)
+	s.Equal("partner", sentBy)
+	s.Len(s.saved, 1)
+	s.Equal(domain.TelegramBotHealthy, s.saved[0].Health)
+}
+
+func (s *telegramBotsTestSuite) Test_Do_WhenRejected_HealthyFailover() {
+	partner := s.stored("partner", "partner-token", "partner-user")
+	partner.SecondaryId = domain.TelegramBotDefaultId
+	s.expectBots(partner)
+
+	// the bot isn't a member of the chat, but the secondary one might be
+	rejected := telegram.ErrTelegramRequestRejected(s.Ctx, "403 Forbidden", "Forbidden: bot was kicked")
+	sentBy, tried, err := s.do("partner-user", "", map[string]error{"partner-token": rejected})
+	s.NoError(err)
+	s.Equal(domain.TelegramBotDefaultId, sentBy)
+	s.Equal([]string{"partner-token", "default-token"}, tried)
+	s.Empty(s.saved)
+
+	// the message is the problem, so it isn't sent by another bot
+	tooLong := telegram.ErrTelegramMessageTooLong(s.Ctx, telegram.MaxMessageLength)
+	_, tried, err = s.do("partner-user", "", map[string]error{"partner-token": tooLong})
+	s.AssertAppErr(err, telegram.ErrCodeTelegramMessageTooLong)
+	s.Equal([]string{"partner-token"}, tried)
+}
+
+func (s *telegramBotsTestSuite) Test_SendParts_FailoverPerPart() {
+	partner := s.stored("partner", "partner-token", "partner-user")
+	partner.SecondaryId = "reserve"
+	s.expectBots(partner, s.stored("reserve", "reserve-token"))
+
+	text := strings.Repeat("x\n", telegram.MaxMessageLength/2) + strings.Repeat("y\n", telegram.MaxMessageLength/4)
+	parts := telegram.SplitMessage(text, telegram.MaxMessageLength)
+	s.Len(parts, 2)
+	s.NotEqual(parts[0], parts[1])
+	notifier := &mocks.TelegramNotifier{}
+	notifier.On("SendMessage", s.Ctx, "partner-token", -100, parts[0]).Return(int64(1), nil)
+	notifier.On("SendMessage", s.Ctx, "partner-token", -100, parts[1]).Return(int64(0), fmt.Errorf("timeout"))
+	notifier.On("SendMessage", s.Ctx, "reserve-token", -100, parts[1]).Return(int64(2), nil)
+
+	s.NoError(sendParts(s.Ctx, s.registry, notifier, "partner-user", "", -100, text))
+	// the part sent by the failed bot isn't sent again
+	notifier.AssertNumberOfCalls(s.T(), "SendMessage", 3)
+	notifier.AssertNotCalled(s.T(), "SendMessage", s.Ctx, "reserve-token", -100, parts[0])
+}
+
+func (s *telegramBotsTestSuite) Test_Do_WhenStorageFails_Cached() {
+	s.storage.On("GetBots", mock.Anything).Return([]*domain.TelegramBotAccount{s.stored("partner", "partner-token", "partner-user")}, nil).Once()
+	s.storage.On("GetBots", mock.Anything).Return(nil, fmt.Errorf("unavailable"))
+
+	sentBy, _, err := s.do("partner-user", "", nil)
+	s.NoError(err)
+	s.Equal("partner", sentBy)
+
+	// cache is outdated, but bots aren't reloaded
+	s.registry.(*telegramBotRegistryImpl).loadedAt = time.Time{}
+	sentBy, _, err = s.do("partner-user", "", nil)
+	s.NoError(err)
+	s.Equal("partner", sentBy)
+}
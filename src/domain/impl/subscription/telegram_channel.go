@@ -0,0 +1,268 @@
+package subscription
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"strings"
+	"time"
+)
+
+const defaultChannelCodeTtl = time.Hour
+
+type telegramChannelVerifierImpl struct {
+	telegram      telegram.Telegram
+	storage       domain.TelegramChannelStorage
+	links         domain.TelegramLinkStorage
+	subscriptions domain.SubscriptionStorage
+	bot           string
+	codeTtl       time.Duration
+}
+
+func NewTelegramChannelVerifier(telegram telegram.Telegram, storage domain.TelegramChannelStorage, links domain.TelegramLinkStorage,
+	subscriptions domain.SubscriptionStorage) domain.TelegramChannelVerifier {
+	return &telegramChannelVerifierImpl{
+		telegram:      telegram,
+		storage:       storage,
+		links:         links,
+		subscriptions: subscriptions,
+		codeTtl:       defaultChannelCodeTtl,
+	}
+}
+
+func (t *telegramChannelVerifierImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-channel-verifier")
+}
+
+func (t *telegramChannelVerifierImpl) Init(cfg *service.Config) {
+	if cfg.Arbitrage == nil || cfg.Arbitrage.Notification == nil || cfg.Arbitrage.Notification.Telegram == nil {
+		return
+	}
+	tgCfg := cfg.Arbitrage.Notification.Telegram
+	t.bot = tgCfg.Bot
+	if tgCfg.ChannelCodeTtlSec > 0 {
+		t.codeTtl = time.Duration(tgCfg.ChannelCodeTtlSec) * time.Second
+	}
+}
+
+func (t *telegramChannelVerifierImpl) validate(ctx context.Context, userId string, chatId int64) error {
+	if userId == "" {
+		return errors.ErrTelegramUserIdEmpty(ctx)
+	}
+	if chatId == 0 {
+		return errors.ErrTelegramChannelInvalid(ctx)
+	}
+	return nil
+}
+
+func (t *telegramChannelVerifierImpl) Request(ctx context.Context, userId string, chatId int64) (*domain.TelegramChannelVerification, error) {
+	t.l().C(ctx).Mth("request").F(log.FF{"userId": userId, "chatId": chatId}).Trc()
+
+	if err := t.validate(ctx, userId, chatId); err != nil {
+		return nil, err
+	}
+
+	stored, err := t.storage.GetChannelVerification(ctx, userId, chatId)
+	if err != nil {
+		return nil, err
+	}
+	// already verified, nothing to confirm
+	if stored != nil && stored.Status == domain.TelegramChannelVerificationVerified {
+		return stored, nil
+	}
+
+	now := kit.Now()
+	v := &domain.TelegramChannelVerification{
+		UserId:    userId,
+		ChatId:    chatId,
+		Code:      kit.NewRandString(),
+		Status:    domain.TelegramChannelVerificationPending,
+		ExpiresAt: now.Add(t.codeTtl),
+		CreatedAt: now,
+	}
+	if err := t.storage.SaveChannelVerification(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// isAdmin checks the user is an admin of the chat
+// Bot API fails if the bot isn't a member of the chat, it's considered as not admin
+func (t *telegramChannelVerifierImpl) isAdmin(ctx context.Context, chatId, userId int64) bool {
+	member, err := t.telegram.GetChatMember(ctx, t.bot, chatId, userId)
+	if err != nil {
+		t.l().C(ctx).Mth("is-admin").F(log.FF{"chatId": chatId}).E(err).Warn()
+		return false
+	}
+	return member.IsAdmin()
+}
+
+// adminsVerified checks both the bot and the linked telegram account of the user are admins of the channel
+func (t *telegramChannelVerifierImpl) adminsVerified(ctx context.Context, link *domain.TelegramLink, chatId int64) (bool, error) {
+	if link == nil {
+		return false, nil
+	}
+	me, err := t.telegram.GetMe(ctx, t.bot)
+	if err != nil {
+		return false, err
+	}
+	return t.isAdmin(ctx, chatId, me.Id) && t.isAdmin(ctx, chatId, link.TelegramUserId), nil
+}
+
+func (t *telegramChannelVerifierImpl) Confirm(ctx context.Context, userId string, chatId int64) (*domain.TelegramChannelVerification, error) {
+	t.l().C(ctx).Mth("confirm").F(log.FF{"userId": userId, "chatId": chatId}).Trc()
+
+	if err := t.validate(ctx, userId, chatId); err != nil {
+		return nil, err
+	}
+
+	stored, err := t.storage.GetChannelVerification(ctx, userId, chatId)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil && stored.Status == domain.TelegramChannelVerificationVerified {
+		return stored, nil
+	}
+	if stored == nil {
+		stored = &domain.TelegramChannelVerification{UserId: userId, ChatId: chatId, CreatedAt: kit.Now()}
+	}
+
+	link, err := t.links.GetLinkByUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	// private chat with the bot is the user's own
+	if link != nil && link.ChatId == chatId {
+		return t.verify(ctx, stored, domain.TelegramChannelVerifiedByLink)
+	}
+
+	verified, err := t.adminsVerified(ctx, link, chatId)
+	if err != nil {
+		return nil, err
+	}
+	if verified {
+		return t.verify(ctx, stored, domain.TelegramChannelVerifiedByAdmin)
+	}
+
+	if stored.Code == "" {
+		return nil, errors.ErrTelegramChannelVerificationNotFound(ctx)
+	}
+	if stored.ExpiresAt.Before(kit.Now()) {
+		return nil, errors.ErrTelegramChannelVerificationExpired(ctx)
+	}
+	return nil, errors.ErrTelegramChannelNotVerified(ctx)
+}
+
+func (t *telegramChannelVerifierImpl) GetVerifications(ctx context.Context, userId string) ([]*domain.TelegramChannelVerification, error) {
+	t.l().C(ctx).Mth("get").F(log.FF{"userId": userId}).Trc()
+	if userId == "" {
+		return nil, errors.ErrTelegramUserIdEmpty(ctx)
+	}
+	return t.storage.GetChannelVerifications(ctx, userId)
+}
+
+func (t *telegramChannelVerifierImpl) IsVerified(ctx context.Context, userId string, chatId int64) (bool, error) {
+	t.l().C(ctx).Mth("is-verified").F(log.FF{"userId": userId, "chatId": chatId}).Trc()
+
+	if userId == "" || chatId == 0 {
+		return false, nil
+	}
+	link, err := t.links.GetLinkByUser(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+	if link != nil && link.ChatId == chatId {
+		return true, nil
+	}
+	v, err := t.storage.GetChannelVerification(ctx, userId, chatId)
+	if err != nil {
+		return false, err
+	}
+	return v != nil && v.Status == domain.TelegramChannelVerificationVerified, nil
+}
+
+func (t *telegramChannelVerifierImpl) HandleMessage(ctx context.Context, msg *telegram.Message) (bool, error) {
+	l := t.l().C(ctx).Mth("handle-message")
+
+	if msg == nil || msg.Chat == nil || msg.Chat.Type == telegram.ChatTypePrivate {
+		return false, nil
+	}
+
+	// either "/verify <code>" or the code itself
+	code := strings.TrimSpace(msg.Text)
+	if cmd, args := msg.Command(); cmd != "" {
+		if cmd != "verify" || len(args) == 0 {
+			return false, nil
+		}
+		code = args[0]
+	}
+	if code == "" {
+		return false, nil
+	}
+
+	v, err := t.storage.GetChannelVerificationByCode(ctx, msg.Chat.Id, code)
+	if err != nil {
+		return false, err
+	}
+	if v == nil || v.Status != domain.TelegramChannelVerificationPending || v.ExpiresAt.Before(kit.Now()) {
+		return false, nil
+	}
+
+	// only admins post to channels, but any member posts to groups
+	if msg.Chat.Type != telegram.ChatTypeChannel && (msg.From == nil || !t.isAdmin(ctx, msg.Chat.Id, msg.From.Id)) {
+		l.F(log.FF{"chatId": msg.Chat.Id}).Dbg("code posted by not admin")
+		return false, nil
+	}
+
+	if _, err := t.verify(ctx, v, domain.TelegramChannelVerifiedByCode); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// verify marks the channel verified and activates telegram notifications of the user waiting for verification of the channel
+// notifications deactivated by the user stay inactive
+func (t *telegramChannelVerifierImpl) verify(ctx context.Context, v *domain.TelegramChannelVerification, method string) (*domain.TelegramChannelVerification, error) {
+	l := t.l().C(ctx).Mth("verify").F(log.FF{"userId": v.UserId, "chatId": v.ChatId, "method": method})
+
+	now := kit.Now()
+	v.Status = domain.TelegramChannelVerificationVerified
+	v.Method = method
+	v.VerifiedAt = &now
+	// code can't be reused
+	v.Code = ""
+	if err := t.storage.SaveChannelVerification(ctx, v); err != nil {
+		return nil, err
+	}
+
+	subs, err := t.subscriptions.SearchSubscriptions(ctx, &domain.SearchSubscriptionsRequest{UserId: v.UserId, WithInActive: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		changed := false
+		for _, n := range sub.Notifications {
+			if n.Channel == domain.SubscriptionNotificationChannelTelegram && n.Telegram != nil &&
+				int64(n.Telegram.Channel) == v.ChatId && !n.Telegram.Verified {
+				n.Telegram.Verified = true
+				if n.Telegram.AwaitingVerification {
+					n.IsActive = true
+					n.Telegram.AwaitingVerification = false
+				}
+				changed = true
+			}
+		}
+		if changed {
+			if err := t.subscriptions.SaveSubscription(ctx, sub); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	l.Inf("ok")
+	return v, nil
+}
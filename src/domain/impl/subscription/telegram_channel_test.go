@@ -0,0 +1,181 @@
+package subscription
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+const testChannelId = int64(-100)
+
+type telegramChannelTestSuite struct {
+	kitTestSuite.Suite
+	api           *telegram.TestBotApiServer
+	storage       *mocks.TelegramChannelStorage
+	links         *mocks.TelegramLinkStorage
+	subscriptions *mocks.SubscriptionStorage
+	verifier      domain.TelegramChannelVerifier
+	userId        string
+}
+
+func (s *telegramChannelTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestTelegramChannelSuite(t *testing.T) {
+	suite.Run(t, new(telegramChannelTestSuite))
+}
+
+func (s *telegramChannelTestSuite) SetupTest() {
+	s.api = telegram.NewTestBotApiServer()
+	s.storage = &mocks.TelegramChannelStorage{}
+	s.links = &mocks.TelegramLinkStorage{}
+	s.subscriptions = &mocks.SubscriptionStorage{}
+	s.userId = kit.NewId()
+	s.verifier = NewTelegramChannelVerifier(telegram.NewTelegram(service.LF(), s.api.Config()), s.storage, s.links, s.subscriptions)
+	s.verifier.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{
+		Telegram: &service.ArbitrageNotificationTelegram{Bot: testBotToken},
+	}}})
+}
+
+func (s *telegramChannelTestSuite) TearDownTest() {
+	s.api.Close()
+}
+
+func (s *telegramChannelTestSuite) pending(expiresAt time.Time) *domain.TelegramChannelVerification {
+	return &domain.TelegramChannelVerification{
+		UserId:    s.userId,
+		ChatId:    testChannelId,
+		Code:      "code",
+		Status:    domain.TelegramChannelVerificationPending,
+		ExpiresAt: expiresAt,
+		CreatedAt: kit.Now(),
+	}
+}
+
+// expectActivation expects the notification to the channel awaiting verification is activated
+func (s *telegramChannelTestSuite) expectActivation() *domain.Subscription {
+	subs := &domain.Subscription{Id: kit.NewId(), UserId: s.userId, Notifications: []*domain.SubscriptionNotification{
+		{Channel: domain.SubscriptionNotificationChannelTelegram, Telegram: &domain.SubscriptionTelegramNotificationDetails{Channel: int(testChannelId), AwaitingVerification: true}},
+		{Channel: domain.SubscriptionNotificationChannelTelegram, Telegram: &domain.SubscriptionTelegramNotificationDetails{Channel: -200, AwaitingVerification: true}},
+		{Channel: domain.SubscriptionNotificationChannelTelegram, Telegram: &domain.SubscriptionTelegramNotificationDetails{Channel: int(testChannelId)}},
+	}}
+	s.subscriptions.On("SearchSubscriptions", s.Ctx, &domain.SearchSubscriptionsRequest{UserId: s.userId, WithInActive: true}).
+		Return([]*domain.Subscription{subs}, nil)
+	s.subscriptions.On("SaveSubscription", s.Ctx, subs).Return(nil)
+	return subs
+}
+
+func (s *telegramChannelTestSuite) Test_Request() {
+	s.storage.On("GetChannelVerification", s.Ctx, s.userId, testChannelId).Return(nil, nil)
+	s.storage.On("SaveChannelVerification", s.Ctx, mock.AnythingOfType("*domain.TelegramChannelVerification")).Return(nil)
+	v, err := s.verifier.Request(s.Ctx, s.userId, testChannelId)
+	s.NoError(err)
+	s.NotEmpty(v.Code)
+	s.Equal(domain.TelegramChannelVerificationPending, v.Status)
+	s.True(v.ExpiresAt.After(kit.Now().Add(time.Minute * 59)))
+
+	_, err = s.verifier.Request(s.Ctx, s.userId, 0)
+	s.AssertAppErr(err, errors.ErrCodeTelegramChannelInvalid)
+	_, err = s.verifier.Request(s.Ctx, "", testChannelId)
+	s.AssertAppErr(err, errors.ErrCodeTelegramUserIdEmpty)
+}
+
+func (s *telegramChannelTestSuite) Test_HandleMessage_ChannelPost() {
+	s.storage.On("GetChannelVerificationByCode", s.Ctx, testChannelId, "code").Return(s.pending(kit.Now().Add(time.Minute)), nil)
+	s.storage.On("GetChannelVerificationByCode", s.Ctx, testChannelId, "unknown").Return(nil, nil)
+	s.storage.On("SaveChannelVerification", s.Ctx, mock.MatchedBy(func(v *domain.TelegramChannelVerification) bool {
+		return v.Status == domain.TelegramChannelVerificationVerified && v.Method == domain.TelegramChannelVerifiedByCode && v.Code == ""
+	})).Return(nil)
+	subs := s.expectActivation()
+
+	verified, err := s.verifier.HandleMessage(s.Ctx, s.api.PushChannelPost(testChannelId, "/verify unknown").ChannelPost)
+	s.NoError(err)
+	s.False(verified)
+
+	verified, err = s.verifier.HandleMessage(s.Ctx, s.api.PushChannelPost(testChannelId, "/verify code").ChannelPost)
+	s.NoError(err)
+	s.True(verified)
+	s.True(subs.Notifications[0].IsActive)
+	s.True(subs.Notifications[0].Telegram.Verified)
+	s.False(subs.Notifications[0].Telegram.AwaitingVerification)
+	// notifications to other channels are untouched
+	s.False(subs.Notifications[1].IsActive)
+	s.True(subs.Notifications[1].Telegram.AwaitingVerification)
+	// notification deactivated by the user stays inactive
+	s.False(subs.Notifications[2].IsActive)
+	s.True(subs.Notifications[2].Telegram.Verified)
+	s.storage.AssertExpectations(s.T())
+}
+
+func (s *telegramChannelTestSuite) Test_HandleMessage_WhenExpiredOrNotAdmin_NotVerified() {
+	s.storage.On("GetChannelVerificationByCode", s.Ctx, testChannelId, "expired").Return(s.pending(kit.Now().Add(-time.Second)), nil)
+	s.storage.On("GetChannelVerificationByCode", s.Ctx, testChannelId, "code").Return(s.pending(kit.Now().Add(time.Minute)), nil)
+
+	verified, err := s.verifier.HandleMessage(s.Ctx, s.api.PushChannelPost(testChannelId, "expired").ChannelPost)
+	s.NoError(err)
+	s.False(verified)
+
+	// any member posts to groups, so the code must be posted by an admin
+	group := s.api.PushUpdate(&telegram.Update{Message: &telegram.Message{
+		From: &telegram.User{Id: testTelegramUserId},
+		Chat: &telegram.Chat{Id: testChannelId, Type: "supergroup"},
+		Text: "code",
+	}})
+	verified, err = s.verifier.HandleMessage(s.Ctx, group.Message)
+	s.NoError(err)
+	s.False(verified)
+	s.storage.AssertNotCalled(s.T(), "SaveChannelVerification", mock.Anything, mock.Anything)
+}
+
+func (s *telegramChannelTestSuite) Test_Confirm_Admins() {
+	s.storage.On("GetChannelVerification", s.Ctx, s.userId, testChannelId).Return(nil, nil)
+	s.links.On("GetLinkByUser", s.Ctx, s.userId).Return(&domain.TelegramLink{UserId: s.userId, TelegramUserId: testTelegramUserId, ChatId: testTelegramUserId}, nil)
+
+	// the bot isn't an admin
+	s.api.SetChatMember(testChannelId, testTelegramUserId, telegram.ChatMemberStatusCreator)
+	_, err := s.verifier.Confirm(s.Ctx, s.userId, testChannelId)
+	s.AssertAppErr(err, errors.ErrCodeTelegramChannelVerificationNotFound)
+
+	s.api.SetChatMember(testChannelId, telegram.TestBotUserId, telegram.ChatMemberStatusAdministrator)
+	s.storage.On("SaveChannelVerification", s.Ctx, mock.AnythingOfType("*domain.TelegramChannelVerification")).Return(nil)
+	subs := s.expectActivation()
+	v, err := s.verifier.Confirm(s.Ctx, s.userId, testChannelId)
+	s.NoError(err)
+	s.Equal(domain.TelegramChannelVerificationVerified, v.Status)
+	s.Equal(domain.TelegramChannelVerifiedByAdmin, v.Method)
+	s.True(subs.Notifications[0].IsActive)
+}
+
+func (s *telegramChannelTestSuite) Test_Confirm_WhenNotVerified_Fail() {
+	s.links.On("GetLinkByUser", s.Ctx, s.userId).Return(nil, nil)
+	s.storage.On("GetChannelVerification", s.Ctx, s.userId, testChannelId).Return(s.pending(kit.Now().Add(time.Minute)), nil).Once()
+	_, err := s.verifier.Confirm(s.Ctx, s.userId, testChannelId)
+	s.AssertAppErr(err, errors.ErrCodeTelegramChannelNotVerified)
+
+	s.storage.On("GetChannelVerification", s.Ctx, s.userId, testChannelId).Return(s.pending(kit.Now().Add(-time.Second)), nil).Once()
+	_, err = s.verifier.Confirm(s.Ctx, s.userId, testChannelId)
+	s.AssertAppErr(err, errors.ErrCodeTelegramChannelVerificationExpired)
+}
+
+func (s *telegramChannelTestSuite) Test_IsVerified() {
+	s.links.On("GetLinkByUser", s.Ctx, s.userId).Return(&domain.TelegramLink{UserId: s.userId, TelegramUserId: testTelegramUserId, ChatId: testTelegramUserId}, nil)
+	s.storage.On("GetChannelVerification", s.Ctx, s.userId, testChannelId).Return(s.pending(kit.Now().Add(time.Minute)), nil)
+
+	// private chat of the linked account
+	verified, err := s.verifier.IsVerified(s.Ctx, s.userId, testTelegramUserId)
+	s.NoError(err)
+	s.True(verified)
+
+	verified, err = s.verifier.IsVerified(s.Ctx, s.userId, testChannelId)
+	s.NoError(err)
+	s.False(verified)
+}
@@ -2,16 +2,20 @@ package subscription
 
 import (
 	"github.com/mikhailbolshakov/cryptocare/src/domain"
-	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
 	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/mocks"
 	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 type telegramNotifierTestSuite struct {
 	kitTestSuite.Suite
-	svc domain.TelegramNotifier
+	notifier *mocks.TelegramNotifier
+	svc      domain.NotificationChannel
 }
 
 func (s *telegramNotifierTestSuite) SetupSuite() {
@@ -23,13 +27,14 @@ func TestTelegramNotifierTestSuiteSuite(t *testing.T) {
 }
 
 func (s *telegramNotifierTestSuite) SetupTest() {
-	s.svc = NewTelegramNotifier(telegram.NewTelegram(s.L), &TelegramOptions{
-		Bot: "5751469157:AAGXGOcqaENcw7uL8HoI5wS_dEX_y6Hlk88",
-	})
+	s.notifier = &mocks.TelegramNotifier{}
+	renderer := NewNotificationRenderer()
+	// live alerts are disabled until initialized, so chains are just sent
+	bots := newDefaultBotRegistry(&mocks.Telegram{}, "bot")
+	s.svc = NewTelegramChannel(s.notifier, NewTelegramAlerts(s.notifier, bots, renderer, &mocks.TelegramAlertStorage{}, &mocks.BidProvider{}), bots, renderer)
 }
 
 func (s *telegramNotifierTestSuite) Test() {
-	svc := s.svc.(*telegramNotifier)
 	chain := &domain.ProfitableChain{
 		Id:          "2345325325235",
 		Asset:       "USD",
@@ -45,15 +50,38 @@ func (s *telegramNotifierTestSuite) Test() {
 				Rate:         0.97,
 				ExchangeCode: "binance",
 				Methods:      []string{"M1", "M2"},
+				ObservedAt:   time.Now().Add(-time.Second * 90),
 			},
 		},
 		ExchangeCodes: []string{"binance", "huobi"},
+		ObservedAt:    time.Now().Add(-time.Second * 90),
 	}
-	rq := svc.getRequest(chain)
+	var rq string
+	s.notifier.On("SendMessage", s.Ctx, "bot", -100, mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { rq = args.String(3) }).
+		Return(int64(1), nil)
+	s.NoError(s.svc.Send(s.Ctx, &domain.OutboxDelivery{
+		Id: "delivery-id",
+		Notification: &domain.SubscriptionNotification{
+			Channel:  domain.SubscriptionNotificationChannelTelegram,
+			Telegram: &domain.SubscriptionTelegramNotificationDetails{Channel: -100},
+		},
+		Chain: chain,
+	}))
 	s.NotEmpty(rq)
-	//err := svc.Notify(s.Ctx, []*domain.ProfitableChain{chain})
-	//if err != nil {
-	//	s.L().E(err).Err()
-	//	s.Fatal(err)
-	//}
+	s.Contains(rq, "#USD #binance #huobi #P5")
+	s.Contains(rq, "<b>25.00%</b>")
+	s.Contains(rq, "(binance, 0.97000, 1m)")
+	s.Contains(rq, "quotes age: 1m")
+	s.Contains(rq, "<a href='https://panel.cryptocare.ai/trading/details/2345325325235'>")
+}
+
+func (s *telegramNotifierTestSuite) Test_WhenNoTelegramDetails_Fail() {
+	err := s.svc.Send(s.Ctx, &domain.OutboxDelivery{
+		Id:           "delivery-id",
+		Notification: &domain.SubscriptionNotification{Channel: domain.SubscriptionNotificationChannelTelegram},
+		Chain:        &domain.ProfitableChain{Id: "chain-id"},
+	})
+	s.AssertAppErr(err, errors.ErrCodeOutboxDeliveryInvalid)
+	s.notifier.AssertNotCalled(s.T(), "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
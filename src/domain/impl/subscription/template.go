@@ -0,0 +1,662 @@
+package subscription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	memcache "github.com/mikhailbolshakov/cryptocare/src/kit/cache"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"html"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultPanelUrl     = "https://panel.cryptocare.ai"
+	maxTemplateLength   = 8192  // maxTemplateLength max length of a template text
+	maxRenderedLength   = 32768 // maxRenderedLength max length of a rendered message
+	maxParsedTemplates  = 1024  // maxParsedTemplates max number of parsed templates kept in cache
+	templateSampleChain = "sample-chain"
+)
+
+// TemplateBid bid available to templates
+type TemplateBid struct {
+	SrcAsset   string
+	TrgAsset   string
+	Exchange   string
+	Rate       float64
+	Methods    []string
+	ObservedAt time.Time // ObservedAt zero if unknown
+}
+
+// TemplateChain chain available to templates
+type TemplateChain struct {
+	Id           string
+	Asset        string
+	Profit       float64 // Profit in percents
+	ProfitClass  int     // ProfitClass from 1 (less than 2%) to 5 (20% and more)
+	Depth        int
+	Methods      []string
+	Exchanges    []string
+	Volume       float64
+	BaseCurrency string
+	BaseVolume   float64
+	BaseProfit   float64
+	Bids         []*TemplateBid
+	CreatedAt    time.Time
+	ObservedAt   time.Time // ObservedAt when the oldest bid has been observed, zero if unknown
+	DetailsUrl   string    // DetailsUrl link to chain details in the panel
+}
+
+// TemplateSpread spread available to templates
+type TemplateSpread struct {
+	Id         string
+	Type       string
+	BaseAsset  string
+	QuoteAsset string
+	Spread     float64 // Spread in percents
+	BuyPrice   float64
+	SellPrice  float64
+	Volume     float64
+	Profit     float64
+	Exchanges  []string
+	Methods    []string
+	Buy        *TemplateBid
+	Sell       *TemplateBid
+	CreatedAt  time.Time
+}
+
+// TemplateDigest digest available to templates
+type TemplateDigest struct {
+	Chains  []*TemplateChain
+	Spreads []*TemplateSpread
+	Matched int // Matched number of opportunities matched within the period
+	From    time.Time
+	To      time.Time
+}
+
+// TemplateData is passed to templates, only one of Chain, Spread and Digest is set according to the delivery type
+// templates get plain data only, so they can't call methods of domain objects
+type TemplateData struct {
+	DeliveryId     string
+	NotificationId string
+	Type           string // Type delivery type (chain, spread, digest)
+	Chain          *TemplateChain
+	Spread         *TemplateSpread
+	Digest         *TemplateDigest
+	Now            time.Time
+}
+
+var templateEmojis = map[string]string{
+	"flame":       "\U0001F525",
+	"rocket":      "\U0001F680",
+	"exclamation": "❗",
+	"arrow":       "▶",
+	"chart":       "\U0001F4C8",
+	"bell":        "\U0001F514",
+}
+
+// profitClass splits profits into classes from 1 (less than 2%) to 5 (20% and more)
+func profitClass(profitShare float64) int {
+	switch {
+	case profitShare < 1.02:
+		return 1
+	case profitShare < 1.05:
+		return 2
+	case profitShare < 1.1:
+		return 3
+	case profitShare < 1.2:
+		return 4
+	}
+	return 5
+}
+
+func profitClassEmoji(class int) string {
+	flame, rocket, exclamation := templateEmojis["flame"], templateEmojis["rocket"], templateEmojis["exclamation"]
+	switch class {
+	case 2:
+		return flame
+	case 3:
+		return flame + flame + flame
+	case 4:
+		return exclamation + exclamation + flame
+	case 5:
+		return rocket + rocket + exclamation + flame
+	}
+	return ""
+}
+
+// quoteAge returns how old the quote is in a short human-readable form
+func quoteAge(now, observedAt time.Time) string {
+	age := now.Sub(observedAt)
+	if age < 0 {
+		age = 0
+	}
+	if age < time.Minute {
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	}
+	if age < time.Hour {
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	}
+	return fmt.Sprintf("%dh", int(age.Hours()))
+}
+
+// templateFuncs safe set of functions available to templates in addition to text/template builtins
+// none of them does I/O or allocates output depending on arguments only (e.g. string repetition)
+var templateFuncs = template.FuncMap{
+	"join":  func(items []string, sep string) string { return strings.Join(items, sep) },
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// tag makes a hashtag of the value
+	"tag": func(s string) string { return "#" + strings.NewReplacer(" ", "_", "-", "_", ".", "_").Replace(s) },
+	// time formats the time with Go layout, e.g. {{ time "15:04" .Now }}
+	"time": func(layout string, t time.Time) string { return t.Format(layout) },
+	// age returns how old the time is relative to now, e.g. {{ age $.Now .Chain.ObservedAt }}
+	"age":         quoteAge,
+	"emoji":       func(name string) string { return templateEmojis[name] },
+	"profitEmoji": profitClassEmoji,
+	// inc is handy for numbering items of ranges starting from 1
+	"inc": func(i int) int { return i + 1 },
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// limitedBuffer fails writes exceeding the limit, so a template can't produce a huge message
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("rendered message exceeds %d bytes", b.limit)
+	}
+	return b.Buffer.Write(p)
+}
+
+// deliveryType returns type of the delivery (chain, spread, digest), empty if the delivery carries nothing
+func deliveryType(delivery *domain.OutboxDelivery) string {
+	switch {
+	case delivery.Digest != nil:
+		return domain.DeliveryTypeDigest
+	case delivery.Spread != nil:
+		return domain.OpportunityTypeSpread
+	case delivery.Chain != nil:
+		return domain.OpportunityTypeChain
+	}
+	return ""
+}
+
+func templateOf(templates *domain.NotificationTemplates, deliveryType string) *domain.NotificationTemplate {
+	if templates == nil {
+		return nil
+	}
+	switch deliveryType {
+	case domain.OpportunityTypeChain:
+		return templates.Chain
+	case domain.OpportunityTypeSpread:
+		return templates.Spread
+	case domain.DeliveryTypeDigest:
+		return templates.Digest
+	}
+	return nil
+}
+
+func toTemplatesDomain(templates *service.NotificationTemplates) *domain.NotificationTemplates {
+	if templates == nil {
+		return nil
+	}
+	toDomain := func(t *service.NotificationTemplate) *domain.NotificationTemplate {
+		if t == nil {
+			return nil
+		}
+		return &domain.NotificationTemplate{Subject: t.Subject, Body: t.Body}
+	}
+	return &domain.NotificationTemplates{
+		Chain:  toDomain(templates.Chain),
+		Spread: toDomain(templates.Spread),
+		Digest: toDomain(templates.Digest),
+	}
+}
+
+type rendererImpl struct {
+	sync.RWMutex
+	panelUrl string
+	global   map[string]*domain.NotificationTemplates // global templates by channel
+	parsed   memcache.LRU                             // parsed templates of delivered messages by text
+}
+
+func NewNotificationRenderer() domain.NotificationRenderer {
+	return &rendererImpl{
+		panelUrl: defaultPanelUrl,
+		global:   make(map[string]*domain.NotificationTemplates),
+		parsed:   memcache.NewLRU(maxParsedTemplates),
+	}
+}
+
+func (r *rendererImpl) l() log.CLogger {
+	return service.L().Cmp("notification-renderer")
+}
+
+func (r *rendererImpl) Init(cfg *service.Config) {
+	if cfg.Arbitrage == nil || cfg.Arbitrage.Notification == nil || cfg.Arbitrage.Notification.Templates == nil {
+		return
+	}
+	templatesCfg := cfg.Arbitrage.Notification.Templates
+	global := map[string]*domain.NotificationTemplates{
+		domain.SubscriptionNotificationChannelTelegram: toTemplatesDomain(templatesCfg.Telegram),
+		domain.SubscriptionNotificationChannelEmail:    toTemplatesDomain(templatesCfg.Email),
+		domain.SubscriptionNotificationChannelWebhook:  toTemplatesDomain(templatesCfg.Webhook),
+	}
+
+	if templatesCfg.PanelUrl != "" {
+		r.Lock()
+		r.panelUrl = strings.TrimRight(templatesCfg.PanelUrl, "/")
+		r.Unlock()
+	}
+	for channel, templates := range global {
+		if templates == nil {
+			continue
+		}
+		// invalid global templates are ignored, so the channel falls back to its default templates
+		if err := r.validate(context.Background(), channel, templates); err != nil {
+			r.l().Mth("init").E(err).F(log.FF{"channel": channel}).Err("global templates ignored")
+			continue
+		}
+		r.Lock()
+		r.global[channel] = templates
+		r.Unlock()
+	}
+}
+
+// parse parses the template
+// templates of delivered messages are cached by text, previews and validation aren't cached, so arbitrary texts don't flush the cache
+func (r *rendererImpl) parse(text string, cached bool) (*template.Template, error) {
+	if t, ok := r.parsed.Get(text); ok {
+		return t.(*template.Template), nil
+	}
+	t, err := template.New("").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if cached {
+		r.parsed.Set(text, t)
+	}
+	return t, nil
+}
+
+func (r *rendererImpl) execute(text string, data *TemplateData, cached bool) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	t, err := r.parse(text, cached)
+	if err != nil {
+		return "", err
+	}
+	buf := &limitedBuffer{limit: maxRenderedLength}
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// resolve returns subject and body templates of the delivery type
+// each of them is taken from the notification templates first, then from the global ones of the channel, then from defaults of the channel
+func (r *rendererImpl) resolve(channel, deliveryType string, custom *domain.NotificationTemplates) (string, string) {
+	r.RLock()
+	global := r.global[channel]
+	r.RUnlock()
+
+	var subject, body string
+	for _, t := range []*domain.NotificationTemplate{
+		templateOf(custom, deliveryType),
+		templateOf(global, deliveryType),
+		templateOf(defaultTemplates[channel], deliveryType),
+	} {
+		if t == nil {
+			continue
+		}
+		if subject == "" {
+			subject = t.Subject
+		}
+		if body == "" {
+			body = t.Body
+		}
+	}
+	return subject, body
+}
+
+// render renders message of the delivery
+func (r *rendererImpl) render(channel string, custom *domain.NotificationTemplates, delivery *domain.OutboxDelivery, now time.Time, cached bool) (*domain.RenderedMessage, error) {
+	typ := deliveryType(delivery)
+	subjectTmpl, bodyTmpl := r.resolve(channel, typ, custom)
+
+	// webhook sends JSON payload if there is no template
+	if bodyTmpl == "" && channel == domain.SubscriptionNotificationChannelWebhook {
+		body, err := json.Marshal(newWebhookPayload(delivery))
+		if err != nil {
+			return nil, err
+		}
+		return &domain.RenderedMessage{Body: string(body)}, nil
+	}
+
+	data := r.toTemplateData(delivery, now)
+	// telegram messages are HTML, so data mustn't break markup of templates
+	if channel == domain.SubscriptionNotificationChannelTelegram {
+		escapeTemplateData(data)
+	}
+	subject, err := r.execute(subjectTmpl, data, cached)
+	if err != nil {
+		return nil, err
+	}
+	body, err := r.execute(bodyTmpl, data, cached)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RenderedMessage{Subject: strings.TrimSpace(subject), Body: body}, nil
+}
+
+func (r *rendererImpl) Render(ctx context.Context, delivery *domain.OutboxDelivery) (*domain.RenderedMessage, error) {
+	if delivery.Notification == nil || deliveryType(delivery) == "" {
+		return nil, errors.ErrOutboxDeliveryInvalid(ctx, delivery.Id)
+	}
+	msg, err := r.render(delivery.Notification.Channel, delivery.Notification.Templates, delivery, time.Now(), true)
+	if err != nil {
+		return nil, errors.ErrNotificationTemplateRender(err, ctx)
+	}
+	return msg, nil
+}
+
+// validate checks templates are parsed and rendered against a sample opportunity of each type
+// so references to unknown fields or to data of another delivery type are found when a subscription is saved
+func (r *rendererImpl) validate(ctx context.Context, channel string, templates *domain.NotificationTemplates) error {
+	if _, ok := defaultTemplates[channel]; !ok {
+		return errors.ErrSubscriptionNotificationChannelNotSupported(ctx, channel)
+	}
+	now := time.Now()
+	for _, typ := range []string{domain.OpportunityTypeChain, domain.OpportunityTypeSpread, domain.DeliveryTypeDigest} {
+		t := templateOf(templates, typ)
+		if t == nil {
+			continue
+		}
+		if t.Subject != "" && channel != domain.SubscriptionNotificationChannelEmail {
+			return errors.ErrNotificationTemplateInvalid(ctx, fmt.Sprintf("%s: subject is supported by email only", typ))
+		}
+		if len(t.Subject) > maxTemplateLength || len(t.Body) > maxTemplateLength {
+			return errors.ErrNotificationTemplateInvalid(ctx, fmt.Sprintf("%s: max template length is %d", typ, maxTemplateLength))
+		}
+		data := r.toTemplateData(sampleDelivery(typ, nil, now), now)
+		for _, text := range []string{t.Subject, t.Body} {
+			if _, err := r.execute(text, data, false); err != nil {
+				return errors.ErrNotificationTemplateInvalid(ctx, fmt.Sprintf("%s: %s", typ, err.Error()))
+			}
+		}
+	}
+	return nil
+}
+
+func (r *rendererImpl) Validate(ctx context.Context, channel string, templates *domain.NotificationTemplates) error {
+	if templates == nil {
+		return nil
+	}
+	return r.validate(ctx, channel, templates)
+}
+
+func (r *rendererImpl) Preview(ctx context.Context, rq *domain.TemplatePreviewRequest) (*domain.RenderedMessage, error) {
+	r.l().C(ctx).Mth("preview").F(log.FF{"channel": rq.Channel, "type": rq.Type}).Trc()
+
+	var custom *domain.NotificationTemplates
+	switch rq.Type {
+	case domain.OpportunityTypeChain:
+		custom = &domain.NotificationTemplates{Chain: rq.Template}
+	case domain.OpportunityTypeSpread:
+		custom = &domain.NotificationTemplates{Spread: rq.Template}
+	case domain.DeliveryTypeDigest:
+		custom = &domain.NotificationTemplates{Digest: rq.Template}
+	default:
+		return nil, errors.ErrNotificationTemplateInvalid(ctx, fmt.Sprintf("unknown type %s", rq.Type))
+	}
+	if err := r.validate(ctx, rq.Channel, custom); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	msg, err := r.render(rq.Channel, custom, sampleDelivery(rq.Type, rq.Chain, now), now, false)
+	if err != nil {
+		return nil, errors.ErrNotificationTemplateRender(err, ctx)
+	}
+	return msg, nil
+}
+
+func (r *rendererImpl) toTemplateBid(bid *domain.Bid, rate float64) *TemplateBid {
+	if bid == nil {
+		return nil
+	}
+	return &TemplateBid{
+		SrcAsset:   bid.SrcAsset,
+		TrgAsset:   bid.TrgAsset,
+		Exchange:   bid.ExchangeCode,
+		Rate:       rate,
+		Methods:    bid.Methods,
+		ObservedAt: bid.ObservedAt,
+	}
+}
+
+func (r *rendererImpl) toTemplateChain(chain *domain.ProfitableChain) *TemplateChain {
+	r.RLock()
+	panelUrl := r.panelUrl
+	r.RUnlock()
+	tc := &TemplateChain{
+		Id:           chain.Id,
+		Asset:        chain.Asset,
+		Profit:       (chain.ProfitShare - 1) * 100,
+		ProfitClass:  profitClass(chain.ProfitShare),
+		Depth:        chain.Depth,
+		Methods:      chain.Methods,
+		Exchanges:    chain.ExchangeCodes,
+		Volume:       chain.Volume,
+		BaseCurrency: chain.BaseCurrency,
+		BaseVolume:   chain.BaseVolume,
+		BaseProfit:   chain.BaseProfit,
+		CreatedAt:    chain.CreatedAt,
+		ObservedAt:   chain.ObservedAt,
+		DetailsUrl:   fmt.Sprintf("%s/trading/details/%s", panelUrl, chain.Id),
+	}
+	for _, bid := range chain.Bids {
+		tc.Bids = append(tc.Bids, r.toTemplateBid(bid, bid.Rate))
+	}
+	return tc
+}
+
+func (r *rendererImpl) toTemplateSpread(spread *domain.Spread) *TemplateSpread {
+	return &TemplateSpread{
+		Id:         spread.Id,
+		Type:       spread.Type,
+		BaseAsset:  spread.BaseAsset,
+		QuoteAsset: spread.QuoteAsset,
+		Spread:     (spread.SpreadShare - 1) * 100,
+		BuyPrice:   spread.BuyPrice,
+		SellPrice:  spread.SellPrice,
+		Volume:     spread.Volume,
+		Profit:     spread.Profit,
+		Exchanges:  spread.ExchangeCodes,
+		Methods:    spread.Methods,
+		Buy:        r.toTemplateBid(spread.Buy, spread.BuyPrice),
+		Sell:       r.toTemplateBid(spread.Sell, spread.SellPrice),
+		CreatedAt:  spread.CreatedAt,
+	}
+}
+
+func (r *rendererImpl) toTemplateData(delivery *domain.OutboxDelivery, now time.Time) *TemplateData {
+	data := &TemplateData{
+		DeliveryId: delivery.Id,
+		Type:       deliveryType(delivery),
+		Now:        now,
+	}
+	if delivery.Notification != nil {
+		data.NotificationId = delivery.Notification.Id
+	}
+	switch {
+	case delivery.Digest != nil:
+		data.Digest = &TemplateDigest{
+			Matched: delivery.Digest.Matched,
+			From:    delivery.Digest.From,
+			To:      delivery.Digest.To,
+		}
+		for _, chain := range delivery.Digest.Chains {
+			data.Digest.Chains = append(data.Digest.Chains, r.toTemplateChain(chain))
+		}
+		for _, spread := range delivery.Digest.Spreads {
+			data.Digest.Spreads = append(data.Digest.Spreads, r.toTemplateSpread(spread))
+		}
+	case delivery.Spread != nil:
+		data.Spread = r.toTemplateSpread(delivery.Spread)
+	case delivery.Chain != nil:
+		data.Chain = r.toTemplateChain(delivery.Chain)
+	}
+	return data
+}
+
+func escapeStrings(items []string) []string {
+	if items == nil {
+		return nil
+	}
+	r := make([]string, 0, len(items))
+	for _, item := range items {
+		r = append(r, html.EscapeString(item))
+	}
+	return r
+}
+
+func escapeTemplateBid(bid *TemplateBid) {
+	if bid == nil {
+		return
+	}
+	bid.SrcAsset = html.EscapeString(bid.SrcAsset)
+	bid.TrgAsset = html.EscapeString(bid.TrgAsset)
+	bid.Exchange = html.EscapeString(bid.Exchange)
+	bid.Methods = escapeStrings(bid.Methods)
+}
+
+func escapeTemplateChain(chain *TemplateChain) {
+	if chain == nil {
+		return
+	}
+	chain.Asset = html.EscapeString(chain.Asset)
+	chain.Methods = escapeStrings(chain.Methods)
+	chain.Exchanges = escapeStrings(chain.Exchanges)
+	chain.BaseCurrency = html.EscapeString(chain.BaseCurrency)
+	chain.DetailsUrl = html.EscapeString(chain.DetailsUrl)
+	for _, bid := range chain.Bids {
+		escapeTemplateBid(bid)
+	}
+}
+
+func escapeTemplateSpread(spread *TemplateSpread) {
+	if spread == nil {
+		return
+	}
+	spread.Type = html.EscapeString(spread.Type)
+	spread.BaseAsset = html.EscapeString(spread.BaseAsset)
+	spread.QuoteAsset = html.EscapeString(spread.QuoteAsset)
+	spread.Exchanges = escapeStrings(spread.Exchanges)
+	spread.Methods = escapeStrings(spread.Methods)
+	escapeTemplateBid(spread.Buy)
+	escapeTemplateBid(spread.Sell)
+}
+
+// escapeTemplateData escapes strings of the data for HTML, slices are copied, so domain objects aren't changed
+func escapeTemplateData(data *TemplateData) {
+	escapeTemplateChain(data.Chain)
+	escapeTemplateSpread(data.Spread)
+	if data.Digest != nil {
+		for _, chain := range data.Digest.Chains {
+			escapeTemplateChain(chain)
+		}
+		for _, spread := range data.Digest.Spreads {
+			escapeTemplateSpread(spread)
+		}
+	}
+}
+
+// sampleChain chain templates are previewed and validated against
+func sampleChain(now time.Time) *domain.ProfitableChain {
+	observedAt := now.Add(-time.Second * 40)
+	return &domain.ProfitableChain{
+		Id:            templateSampleChain,
+		Asset:         "USDT",
+		ProfitShare:   1.032,
+		Methods:       []string{"TinkoffNew", "RosBankNew"},
+		BidAssets:     []string{"USDT", "RUB", "USDT"},
+		Depth:         2,
+		ExchangeCodes: []string{"binance", "bybit"},
+		Volume:        1000,
+		BaseCurrency:  "USD",
+		BaseVolume:    1000,
+		BaseProfit:    32,
+		Bids: []*domain.Bid{
+			{Id: "sample-bid-1", Type: domain.BidTypeP2P, SrcAsset: "USDT", TrgAsset: "RUB", Rate: 62.5, ExchangeCode: "binance", Methods: []string{"TinkoffNew"}, ObservedAt: observedAt},
+			{Id: "sample-bid-2", Type: domain.BidTypeP2P, SrcAsset: "RUB", TrgAsset: "USDT", Rate: 1 / 60.56, ExchangeCode: "bybit", Methods: []string{"RosBankNew"}, ObservedAt: now},
+		},
+		CreatedAt:  now,
+		ObservedAt: observedAt,
+	}
+}
+
+// sampleSpread spread templates are previewed and validated against, it's built of bids of the chain
+func sampleSpread(chain *domain.ProfitableChain) *domain.Spread {
+	spread := &domain.Spread{
+		Id:            chain.Id,
+		Type:          domain.SpreadTypeCrossExchange,
+		BaseAsset:     chain.Asset,
+		Volume:        chain.Volume,
+		SpreadShare:   chain.ProfitShare,
+		ExchangeCodes: chain.ExchangeCodes,
+		Methods:       chain.Methods,
+		CreatedAt:     chain.CreatedAt,
+		ObservedAt:    chain.ObservedAt,
+	}
+	if len(chain.Bids) > 0 {
+		spread.Buy, spread.Sell = chain.Bids[len(chain.Bids)-1], chain.Bids[0]
+		spread.QuoteAsset = spread.Sell.TrgAsset
+		spread.SellPrice = spread.Sell.Rate
+		spread.BuyPrice = spread.SellPrice / chain.ProfitShare
+		spread.Profit = (spread.SellPrice - spread.BuyPrice) * spread.Volume
+	}
+	return spread
+}
+
+// sampleDelivery delivery of the given type templates are previewed and validated against
+func sampleDelivery(typ string, chain *domain.ProfitableChain, now time.Time) *domain.OutboxDelivery {
+	if chain == nil {
+		chain = sampleChain(now)
+	}
+	delivery := &domain.OutboxDelivery{
+		Id:              "sample-delivery",
+		Notification:    &domain.SubscriptionNotification{Id: "sample-notification"},
+		OpportunityType: typ,
+	}
+	switch typ {
+	case domain.OpportunityTypeSpread:
+		delivery.Spread = sampleSpread(chain)
+	case domain.DeliveryTypeDigest:
+		delivery.Digest = &domain.NotificationDigest{
+			Chains:  []*domain.ProfitableChain{chain},
+			Spreads: []*domain.Spread{sampleSpread(chain)},
+			Matched: 5,
+			From:    now.Add(-time.Hour),
+			To:      now,
+		}
+	default:
+		delivery.Chain = chain
+	}
+	return delivery
+}
@@ -0,0 +1,78 @@
+package subscription
+
+import "github.com/mikhailbolshakov/cryptocare/src/domain"
+
+// default templates of channels, they are used if neither a notification nor the global config specifies a template
+// webhook has no default templates, it sends JSON payload built by newWebhookPayload
+
+const telegramChainTemplate = `{{ tag .Chain.Asset }}{{ range .Chain.Exchanges }} {{ tag . }}{{ end }} #P{{ .Chain.ProfitClass }}
+asset: <b>{{ .Chain.Asset }}</b>
+{{ profitEmoji .Chain.ProfitClass }}profit: <b>{{ printf "%.2f" .Chain.Profit }}%</b>
+chain: {{ range $i, $bid := .Chain.Bids }}{{ if $i }} -> {{ end }}{{ $bid.SrcAsset }}:{{ $bid.TrgAsset }}({{ $bid.Exchange }}, {{ printf "%.5f" $bid.Rate }}{{ if not $bid.ObservedAt.IsZero }}, {{ age $.Now $bid.ObservedAt }}{{ end }}){{ end }}
+time: {{ time "15:04:05" .Now }}
+{{ if not .Chain.ObservedAt.IsZero }}quotes age: {{ age .Now .Chain.ObservedAt }}
+{{ end }}{{ emoji "arrow" }}<a href='{{ .Chain.DetailsUrl }}'>link to details</a>
+`
+
+const telegramSpreadTemplate = `#spread {{ tag .Spread.BaseAsset }} {{ tag .Spread.QuoteAsset }}{{ range .Spread.Exchanges }} {{ tag . }}{{ end }}
+pair: <b>{{ .Spread.BaseAsset }}:{{ .Spread.QuoteAsset }}</b> ({{ .Spread.Type }})
+spread: <b>{{ printf "%.2f" .Spread.Spread }}%</b>
+{{ with .Spread.Buy }}buy: {{ .Exchange }}, {{ printf "%.5f" .Rate }}{{ if .Methods }}, {{ join .Methods "/" }}{{ end }}{{ if not .ObservedAt.IsZero }}, {{ age $.Now .ObservedAt }}{{ end }}
+{{ end }}{{ with .Spread.Sell }}sell: {{ .Exchange }}, {{ printf "%.5f" .Rate }}{{ if .Methods }}, {{ join .Methods "/" }}{{ end }}{{ if not .ObservedAt.IsZero }}, {{ age $.Now .ObservedAt }}{{ end }}
+{{ end }}volume: {{ printf "%.5f" .Spread.Volume }} {{ .Spread.BaseAsset }}, profit: {{ printf "%.2f" .Spread.Profit }} {{ .Spread.QuoteAsset }}
+time: {{ time "15:04:05" .Now }}
+`
+
+const telegramDigestTemplate = `#digest
+<b>{{ .Digest.Matched }}</b> opportunities from {{ time "15:04" .Digest.From }} to {{ time "15:04" .Digest.To }}
+{{ range $i, $c := .Digest.Chains }}{{ inc $i }}. {{ profitEmoji $c.ProfitClass }}<b>{{ $c.Asset }} {{ printf "%.2f" $c.Profit }}%</b> {{ join $c.Exchanges ", " }} <a href='{{ $c.DetailsUrl }}'>details</a>
+{{ end }}{{ range $i, $s := .Digest.Spreads }}{{ inc $i }}. spread <b>{{ $s.BaseAsset }}:{{ $s.QuoteAsset }} {{ printf "%.2f" $s.Spread }}%</b> {{ join $s.Exchanges ", " }}
+{{ end }}`
+
+const emailChainTemplate = `asset: {{ .Chain.Asset }}
+profit: {{ printf "%.2f" .Chain.Profit }}%
+{{ if .Chain.BaseCurrency }}volume: {{ printf "%.2f" .Chain.BaseVolume }} {{ .Chain.BaseCurrency }}, profit: {{ printf "%.2f" .Chain.BaseProfit }} {{ .Chain.BaseCurrency }}
+{{ end }}chain:
+{{ range .Chain.Bids }}  {{ .SrcAsset }}:{{ .TrgAsset }} ({{ .Exchange }}, {{ printf "%.5f" .Rate }}{{ if .Methods }}, {{ join .Methods "/" }}{{ end }})
+{{ end }}time: {{ time "2006-01-02 15:04:05" .Now }}
+details: {{ .Chain.DetailsUrl }}
+`
+
+const emailSpreadTemplate = `pair: {{ .Spread.BaseAsset }}:{{ .Spread.QuoteAsset }} ({{ .Spread.Type }})
+spread: {{ printf "%.2f" .Spread.Spread }}%
+{{ with .Spread.Buy }}buy: {{ .Exchange }}, {{ printf "%.5f" .Rate }}
+{{ end }}{{ with .Spread.Sell }}sell: {{ .Exchange }}, {{ printf "%.5f" .Rate }}
+{{ end }}volume: {{ printf "%.5f" .Spread.Volume }} {{ .Spread.BaseAsset }}, profit: {{ printf "%.2f" .Spread.Profit }} {{ .Spread.QuoteAsset }}
+time: {{ time "2006-01-02 15:04:05" .Now }}
+`
+
+const emailDigestTemplate = `{{ .Digest.Matched }} opportunities from {{ time "2006-01-02 15:04" .Digest.From }} to {{ time "2006-01-02 15:04" .Digest.To }}
+{{ if .Digest.Chains }}chains:
+{{ range $i, $c := .Digest.Chains }}  {{ inc $i }}. {{ $c.Asset }}, profit {{ printf "%.2f" $c.Profit }}%, {{ join $c.Exchanges "/" }}, {{ $c.DetailsUrl }}
+{{ end }}{{ end }}{{ if .Digest.Spreads }}spreads:
+{{ range $i, $s := .Digest.Spreads }}  {{ inc $i }}. {{ $s.BaseAsset }}:{{ $s.QuoteAsset }}, spread {{ printf "%.2f" $s.Spread }}%, {{ join $s.Exchanges "/" }}
+{{ end }}{{ end }}`
+
+// defaultTemplates default templates by channel, only channels listed here can be rendered
+var defaultTemplates = map[string]*domain.NotificationTemplates{
+	domain.SubscriptionNotificationChannelTelegram: {
+		Chain:  &domain.NotificationTemplate{Body: telegramChainTemplate},
+		Spread: &domain.NotificationTemplate{Body: telegramSpreadTemplate},
+		Digest: &domain.NotificationTemplate{Body: telegramDigestTemplate},
+	},
+	domain.SubscriptionNotificationChannelEmail: {
+		Chain: &domain.NotificationTemplate{
+			Subject: `{{ .Chain.Asset }} chain, profit {{ printf "%.2f" .Chain.Profit }}%`,
+			Body:    emailChainTemplate,
+		},
+		Spread: &domain.NotificationTemplate{
+			Subject: `{{ .Spread.BaseAsset }}:{{ .Spread.QuoteAsset }} spread {{ printf "%.2f" .Spread.Spread }}%`,
+			Body:    emailSpreadTemplate,
+		},
+		Digest: &domain.NotificationTemplate{
+			Subject: `Digest: {{ .Digest.Matched }} opportunities`,
+			Body:    emailDigestTemplate,
+		},
+	},
+	domain.SubscriptionNotificationChannelWebhook: {},
+}
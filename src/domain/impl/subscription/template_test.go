@@ -0,0 +1,213 @@
+package subscription
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+	"time"
+)
+
+type templateTestSuite struct {
+	kitTestSuite.Suite
+	svc domain.NotificationRenderer
+}
+
+func (s *templateTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestTemplateSuite(t *testing.T) {
+	suite.Run(t, new(templateTestSuite))
+}
+
+func (s *templateTestSuite) SetupTest() {
+	s.svc = NewNotificationRenderer()
+	s.svc.Init(&service.Config{Arbitrage: &service.Arbitrage{Notification: &service.ArbitrageNotification{
+		Templates: &service.ArbitrageNotificationTemplates{
+			PanelUrl: "https://panel.example.com/",
+			Email: &service.NotificationTemplates{
+				Chain: &service.NotificationTemplate{Subject: "global {{ .Chain.Asset }}", Body: "global body {{ .Chain.Asset }}"},
+			},
+			// invalid global template is ignored
+			Telegram: &service.NotificationTemplates{
+				Chain: &service.NotificationTemplate{Body: "{{ .Chain.Unknown }}"},
+			},
+		},
+	}}})
+}
+
+func (s *templateTestSuite) delivery(channel string, templates *domain.NotificationTemplates) *domain.OutboxDelivery {
+	return &domain.OutboxDelivery{
+		Id:           "delivery-id",
+		Notification: &domain.SubscriptionNotification{Id: "n", Channel: channel, Templates: templates},
+		Chain:        &domain.ProfitableChain{Id: "chain-id", Asset: "USDT", ProfitShare: 1.05, ExchangeCodes: []string{"binance"}},
+	}
+}
+
+func (s *templateTestSuite) Test_Render_Default() {
+	msg, err := s.svc.Render(s.Ctx, s.delivery(domain.SubscriptionNotificationChannelTelegram, nil))
+	s.NoError(err)
+	s.Empty(msg.Subject)
+	s.Contains(msg.Body, "#USDT #binance #P3")
+	s.Contains(msg.Body, "profit: <b>5.00%</b>")
+	s.Contains(msg.Body, "https://panel.example.com/trading/details/chain-id")
+}
+
+func (s *templateTestSuite) Test_Render_Precedence() {
+	// global template of the channel overrides the default one
+	msg, err := s.svc.Render(s.Ctx, s.delivery(domain.SubscriptionNotificationChannelEmail, nil))
+	s.NoError(err)
+	s.Equal("global USDT", msg.Subject)
+	s.Equal("global body USDT", msg.Body)
+
+	// notification template overrides the global one, subject is taken from the global template if not specified
+	msg, err = s.svc.Render(s.Ctx, s.delivery(domain.SubscriptionNotificationChannelEmail, &domain.NotificationTemplates{
+		Chain: &domain.NotificationTemplate{Body: "{{ .Chain.Asset }} {{ printf \"%.1f\" .Chain.Profit }} {{ upper (join .Chain.Exchanges \",\") }}"},
+	}))
+	s.NoError(err)
+	s.Equal("global USDT", msg.Subject)
+	s.Equal("USDT 5.0 BINANCE", msg.Body)
+}
+
+func (s *templateTestSuite) Test_Render_Telegram_EscapesData() {
+	d := s.delivery(domain.SubscriptionNotificationChannelTelegram, &domain.NotificationTemplates{
+		Chain: &domain.NotificationTemplate{Body: "<b>{{ .Chain.Asset }}</b> {{ join .Chain.Exchanges \",\" }}"},
+	})
+	d.Chain.Asset = "A&B"
+	d.Chain.ExchangeCodes = []string{"<i>"}
+	msg, err := s.svc.Render(s.Ctx, d)
+	s.NoError(err)
+	s.Equal("<b>A&amp;B</b> &lt;i&gt;", msg.Body)
+	// data of the delivery is untouched
+	s.Equal("A&B", d.Chain.Asset)
+	s.Equal("<i>", d.Chain.ExchangeCodes[0])
+
+	// other channels aren't escaped
+	d.Notification.Channel = domain.SubscriptionNotificationChannelEmail
+	msg, err = s.svc.Render(s.Ctx, d)
+	s.NoError(err)
+	s.Equal("<b>A&B</b> <i>", msg.Body)
+}
+
+func (s *templateTestSuite) Test_Render_Webhook_DefaultPayload() {
+	msg, err := s.svc.Render(s.Ctx, s.delivery(domain.SubscriptionNotificationChannelWebhook, nil))
+	s.NoError(err)
+	s.True(strings.HasPrefix(msg.Body, `{"id":"delivery-id","event":"chain","notificationId":"n","chain":{"id":"chain-id"`))
+}
+
+func (s *templateTestSuite) Test_Render_WhenOutputTooLong_Fail() {
+	chain := s.delivery(domain.SubscriptionNotificationChannelTelegram, &domain.NotificationTemplates{
+		Chain: &domain.NotificationTemplate{Body: "{{ range .Chain.Methods }}{{ . }}{{ end }}"},
+	})
+	chain.Chain.Methods = []string{strings.Repeat("x", maxRenderedLength/2), strings.Repeat("x", maxRenderedLength/2+1)}
+	_, err := s.svc.Render(s.Ctx, chain)
+	s.AssertAppErr(err, errors.ErrCodeNotificationTemplateRender)
+}
+
+func (s *templateTestSuite) Test_Render_WhenNoOpportunity_Fail() {
+	_, err := s.svc.Render(s.Ctx, &domain.OutboxDelivery{Notification: &domain.SubscriptionNotification{Channel: domain.SubscriptionNotificationChannelEmail}})
+	s.AssertAppErr(err, errors.ErrCodeOutboxDeliveryInvalid)
+}
+
+func (s *templateTestSuite) Test_Validate_Ok() {
+	s.NoError(s.svc.Validate(s.Ctx, domain.SubscriptionNotificationChannelTelegram, nil))
+	s.NoError(s.svc.Validate(s.Ctx, domain.SubscriptionNotificationChannelTelegram, &domain.NotificationTemplates{
+		Chain:  &domain.NotificationTemplate{Body: "{{ emoji \"rocket\" }} {{ .Chain.Asset }} {{ age .Now .Chain.ObservedAt }}"},
+		Spread: &domain.NotificationTemplate{Body: "{{ .Spread.BaseAsset }}{{ with .Spread.Buy }} {{ .Exchange }}{{ end }}"},
+		Digest: &domain.NotificationTemplate{Body: "{{ range $i, $c := .Digest.Chains }}{{ inc $i }}. {{ $c.DetailsUrl }}{{ end }}"},
+	}))
+	s.NoError(s.svc.Validate(s.Ctx, domain.SubscriptionNotificationChannelEmail, &domain.NotificationTemplates{
+		Digest: &domain.NotificationTemplate{Subject: "{{ .Digest.Matched }} new", Body: "{{ time \"15:04\" .Digest.From }}"},
+	}))
+}
+
+func (s *templateTestSuite) Test_Validate_Fail() {
+	tests := []struct {
+		channel   string
+		templates *domain.NotificationTemplates
+	}{
+		// syntax
+		{domain.SubscriptionNotificationChannelTelegram, &domain.NotificationTemplates{Chain: &domain.NotificationTemplate{Body: "{{ .Chain.Asset "}}},
+		// unknown field
+		{domain.SubscriptionNotificationChannelTelegram, &domain.NotificationTemplates{Chain: &domain.NotificationTemplate{Body: "{{ .Chain.Secret }}"}}},
+		// unknown function
+		{domain.SubscriptionNotificationChannelTelegram, &domain.NotificationTemplates{Chain: &domain.NotificationTemplate{Body: "{{ exec \"ls\" }}"}}},
+		// data of another delivery type
+		{domain.SubscriptionNotificationChannelTelegram, &domain.NotificationTemplates{Chain: &domain.NotificationTemplate{Body: "{{ .Spread.BaseAsset }}"}}},
+		// subject isn't supported
+		{domain.SubscriptionNotificationChannelWebhook, &domain.NotificationTemplates{Chain: &domain.NotificationTemplate{Subject: "s", Body: "{}"}}},
+		// too long
+		{domain.SubscriptionNotificationChannelEmail, &domain.NotificationTemplates{Spread: &domain.NotificationTemplate{Body: strings.Repeat("x", maxTemplateLength+1)}}},
+	}
+	for _, tt := range tests {
+		s.AssertAppErr(s.svc.Validate(s.Ctx, tt.channel, tt.templates), errors.ErrCodeNotificationTemplateInvalid)
+	}
+	s.AssertAppErr(s.svc.Validate(s.Ctx, "sms", &domain.NotificationTemplates{}), errors.ErrCodeSubscriptionNotificationChannelNotSupported)
+}
+
+func (s *templateTestSuite) Test_Preview() {
+	// default template against a sample chain
+	msg, err := s.svc.Preview(s.Ctx, &domain.TemplatePreviewRequest{
+		Channel: domain.SubscriptionNotificationChannelTelegram,
+		Type:    domain.OpportunityTypeChain,
+	})
+	s.NoError(err)
+	s.Contains(msg.Body, "asset: <b>USDT</b>")
+	s.Contains(msg.Body, "/trading/details/"+templateSampleChain)
+
+	// custom template against the given chain
+	msg, err = s.svc.Preview(s.Ctx, &domain.TemplatePreviewRequest{
+		Channel:  domain.SubscriptionNotificationChannelEmail,
+		Type:     domain.DeliveryTypeDigest,
+		Template: &domain.NotificationTemplate{Subject: "{{ len .Digest.Chains }} chains", Body: "{{ range .Digest.Chains }}{{ .Id }}{{ end }}"},
+		Chain:    &domain.ProfitableChain{Id: "chain-id", Asset: "BTC", ProfitShare: 1.01, CreatedAt: time.Now()},
+	})
+	s.NoError(err)
+	s.Equal("1 chains", msg.Subject)
+	s.Equal("chain-id", msg.Body)
+
+	// spread preview is built from the sample chain
+	msg, err = s.svc.Preview(s.Ctx, &domain.TemplatePreviewRequest{
+		Channel: domain.SubscriptionNotificationChannelEmail,
+		Type:    domain.OpportunityTypeSpread,
+	})
+	s.NoError(err)
+	s.Equal("USDT:RUB spread 3.20%", msg.Subject)
+}
+
+func (s *templateTestSuite) Test_Preview_Fail() {
+	_, err := s.svc.Preview(s.Ctx, &domain.TemplatePreviewRequest{Channel: domain.SubscriptionNotificationChannelEmail, Type: "unknown"})
+	s.AssertAppErr(err, errors.ErrCodeNotificationTemplateInvalid)
+	_, err = s.svc.Preview(s.Ctx, &domain.TemplatePreviewRequest{
+		Channel:  domain.SubscriptionNotificationChannelEmail,
+		Type:     domain.OpportunityTypeChain,
+		Template: &domain.NotificationTemplate{Body: "{{ .Digest.Matched }}"},
+	})
+	s.AssertAppErr(err, errors.ErrCodeNotificationTemplateInvalid)
+}
+
+func (s *templateTestSuite) Test_ParsedCache_PreviewsNotCached() {
+	parsed := s.svc.(*rendererImpl).parsed
+	cachedBefore := parsed.Len()
+	_, err := s.svc.Preview(s.Ctx, &domain.TemplatePreviewRequest{
+		Channel:  domain.SubscriptionNotificationChannelEmail,
+		Type:     domain.OpportunityTypeChain,
+		Template: &domain.NotificationTemplate{Body: "preview {{ .Chain.Asset }}"},
+	})
+	s.NoError(err)
+	s.Equal(cachedBefore, parsed.Len())
+	_, ok := parsed.Get("preview {{ .Chain.Asset }}")
+	s.False(ok)
+
+	// templates of delivered messages are cached
+	_, err = s.svc.Render(s.Ctx, s.delivery(domain.SubscriptionNotificationChannelEmail, &domain.NotificationTemplates{
+		Chain: &domain.NotificationTemplate{Body: "delivered {{ .Chain.Asset }}"},
+	}))
+	s.NoError(err)
+	_, ok = parsed.Get("delivered {{ .Chain.Asset }}")
+	s.True(ok)
+}
@@ -0,0 +1,310 @@
+package subscription
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	WebhookHeaderEvent     = "X-Cryptocare-Event"
+	WebhookHeaderTimestamp = "X-Cryptocare-Timestamp"
+	WebhookHeaderSignature = "X-Cryptocare-Signature"
+
+	defaultWebhookTimeout  = time.Second * 10
+	minWebhookSecretLength = 16
+)
+
+// privateNetworks address ranges webhooks must not target, so that endpoints of the internal network
+// (including cloud metadata at 169.254.169.254) can't be reached through the service
+var privateNetworks = func() []*net.IPNet {
+	var r []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+		"192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "::/128", "::1/128", "fc00::/7", "fe80::/10",
+	} {
+		_, n, _ := net.ParseCIDR(cidr)
+		r = append(r, n)
+	}
+	return r
+}()
+
+// webhookAddressAllowed checks the address is a public unicast one
+func webhookAddressAllowed(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// webhookDialControl checks the address right before connecting, as the host might resolve to another address
+// by the time of delivery than on validation (DNS rebinding), every redirect is checked as well
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !webhookAddressAllowed(ip) {
+		return fmt.Errorf("address %s isn't allowed", host)
+	}
+	return nil
+}
+
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: webhookDialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// requests go directly, otherwise the address of a proxy would be checked instead of the webhook one
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+type WebhookOptions struct {
+	Timeout time.Duration // Timeout of webhook request
+}
+
+// WebhookChain chain payload of the webhook
+type WebhookChain struct {
+	Id            string        `json:"id"`
+	Asset         string        `json:"asset"`
+	Profit        float64       `json:"profit"` // Profit in percents
+	Depth         int           `json:"depth"`
+	Methods       []string      `json:"methods,omitempty"`
+	ExchangeCodes []string      `json:"exchangeCodes,omitempty"`
+	Volume        float64       `json:"volume,omitempty"`
+	BaseCurrency  string        `json:"baseCurrency,omitempty"`
+	BaseVolume    float64       `json:"baseVolume,omitempty"`
+	BaseProfit    float64       `json:"baseProfit,omitempty"`
+	Bids          []*domain.Bid `json:"bids"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	ObservedAt    time.Time     `json:"observedAt,omitempty"`
+}
+
+// WebhookSpread spread payload of the webhook
+type WebhookSpread struct {
+	Id            string      `json:"id"`
+	Type          string      `json:"type"`
+	BaseAsset     string      `json:"baseAsset"`
+	QuoteAsset    string      `json:"quoteAsset"`
+	Spread        float64     `json:"spread"` // Spread in percents
+	BuyPrice      float64     `json:"buyPrice"`
+	SellPrice     float64     `json:"sellPrice"`
+	Volume        float64     `json:"volume"`
+	Profit        float64     `json:"profit"`
+	ExchangeCodes []string    `json:"exchangeCodes,omitempty"`
+	Buy           *domain.Bid `json:"buy"`
+	Sell          *domain.Bid `json:"sell"`
+	CreatedAt     time.Time   `json:"createdAt"`
+}
+
+// WebhookDigest digest payload of the webhook
+type WebhookDigest struct {
+	Chains  []*WebhookChain  `json:"chains,omitempty"`
+	Spreads []*WebhookSpread `json:"spreads,omitempty"`
+	Matched int              `json:"matched"` // Matched number of opportunities matched within the period
+	From    time.Time        `json:"from"`
+	To      time.Time        `json:"to"`
+}
+
+// WebhookPayload is posted to the webhook url as JSON
+type WebhookPayload struct {
+	Id             string         `json:"id"`             // Id unique delivery id
+	Event          string         `json:"event"`          // Event opportunity type (chain, spread, digest)
+	NotificationId string         `json:"notificationId"` // NotificationId subscription notification
+	Chain          *WebhookChain  `json:"chain,omitempty"`
+	Spread         *WebhookSpread `json:"spread,omitempty"`
+	Digest         *WebhookDigest `json:"digest,omitempty"`
+}
+
+// SignWebhookPayload calculates signature of the payload which is sent in X-Cryptocare-Signature header
+// the signature is a hex HMAC-SHA256 of "<timestamp>.<body>", so a receiver can reject replayed payloads by the timestamp
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func toWebhookChain(chain *domain.ProfitableChain) *WebhookChain {
+	return &WebhookChain{
+		Id:            chain.Id,
+		Asset:         chain.Asset,
+		Profit:        (chain.ProfitShare - 1) * 100,
+		Depth:         chain.Depth,
+		Methods:       chain.Methods,
+		ExchangeCodes: chain.ExchangeCodes,
+		Volume:        chain.Volume,
+		BaseCurrency:  chain.BaseCurrency,
+		BaseVolume:    chain.BaseVolume,
+		BaseProfit:    chain.BaseProfit,
+		Bids:          chain.Bids,
+		CreatedAt:     chain.CreatedAt,
+		ObservedAt:    chain.ObservedAt,
+	}
+}
+
+func toWebhookSpread(spread *domain.Spread) *WebhookSpread {
+	return &WebhookSpread{
+		Id:            spread.Id,
+		Type:          spread.Type,
+		BaseAsset:     spread.BaseAsset,
+		QuoteAsset:    spread.QuoteAsset,
+		Spread:        (spread.SpreadShare - 1) * 100,
+		BuyPrice:      spread.BuyPrice,
+		SellPrice:     spread.SellPrice,
+		Volume:        spread.Volume,
+		Profit:        spread.Profit,
+		ExchangeCodes: spread.ExchangeCodes,
+		Buy:           spread.Buy,
+		Sell:          spread.Sell,
+		CreatedAt:     spread.CreatedAt,
+	}
+}
+
+func toWebhookDigest(digest *domain.NotificationDigest) *WebhookDigest {
+	r := &WebhookDigest{
+		Matched: digest.Matched,
+		From:    digest.From,
+		To:      digest.To,
+	}
+	for _, chain := range digest.Chains {
+		r.Chains = append(r.Chains, toWebhookChain(chain))
+	}
+	for _, spread := range digest.Spreads {
+		r.Spreads = append(r.Spreads, toWebhookSpread(spread))
+	}
+	return r
+}
+
+// newWebhookPayload builds the default JSON payload of the delivery
+// delivery id is passed as payload id, so receivers can detect duplicates when a delivery is retried
+func newWebhookPayload(delivery *domain.OutboxDelivery) *WebhookPayload {
+	payload := &WebhookPayload{
+		Id:    delivery.Id,
+		Event: deliveryType(delivery),
+	}
+	if delivery.Notification != nil {
+		payload.NotificationId = delivery.Notification.Id
+	}
+	switch {
+	case delivery.Digest != nil:
+		payload.Digest = toWebhookDigest(delivery.Digest)
+	case delivery.Spread != nil:
+		payload.Spread = toWebhookSpread(delivery.Spread)
+	case delivery.Chain != nil:
+		payload.Chain = toWebhookChain(delivery.Chain)
+	}
+	return payload
+}
+
+// webhookChannel posts HMAC-signed payloads to https endpoints
+// the payload is JSON built by newWebhookPayload unless a template of the notification or a global one renders the body
+type webhookChannel struct {
+	client   *http.Client
+	renderer domain.NotificationRenderer
+}
+
+func NewWebhookChannel(renderer domain.NotificationRenderer, opt *WebhookOptions) domain.NotificationChannel {
+	timeout := defaultWebhookTimeout
+	if opt != nil && opt.Timeout > 0 {
+		timeout = opt.Timeout
+	}
+	return &webhookChannel{
+		client:   newWebhookClient(timeout),
+		renderer: renderer,
+	}
+}
+
+func (w *webhookChannel) l() log.CLogger {
+	return service.L().Cmp("webhook-channel")
+}
+
+func (w *webhookChannel) Type() string {
+	return domain.SubscriptionNotificationChannelWebhook
+}
+
+func (w *webhookChannel) Validate(ctx context.Context, notification *domain.SubscriptionNotification) error {
+	if notification.Webhook == nil || notification.Webhook.Url == "" {
+		return errors.ErrSubscriptionNotificationWebhookInvalid(ctx, "url empty")
+	}
+	u, err := url.Parse(notification.Webhook.Url)
+	if err != nil || u.Host == "" {
+		return errors.ErrSubscriptionNotificationWebhookInvalid(ctx, "url invalid")
+	}
+	if u.Scheme != "https" {
+		return errors.ErrSubscriptionNotificationWebhookInvalid(ctx, "only https url allowed")
+	}
+	// hosts resolving to private addresses are rejected on delivery
+	host := strings.ToLower(u.Hostname())
+	if ip := net.ParseIP(host); (ip != nil && !webhookAddressAllowed(ip)) || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.ErrSubscriptionNotificationWebhookInvalid(ctx, "private network addresses aren't allowed")
+	}
+	if notification.Webhook.Secret == "" {
+		notification.Webhook.Secret = kit.NewRandString()
+	}
+	if len(notification.Webhook.Secret) < minWebhookSecretLength {
+		return errors.ErrSubscriptionNotificationWebhookInvalid(ctx, fmt.Sprintf("secret must be at least %d chars", minWebhookSecretLength))
+	}
+	return nil
+}
+
+// post posts signed body, any non 2xx response is considered as failure
+func (w *webhookChannel) post(ctx context.Context, webhook *domain.SubscriptionWebhookNotificationDetails, id, event string, body []byte, now time.Time) error {
+	l := w.l().C(ctx).Mth("post").F(log.FF{"url": webhook.Url, "id": id}).Trc()
+
+	httpRq, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return errors.ErrWebhookRequestFailed(err, ctx)
+	}
+	timestamp := now.Unix()
+	httpRq.Header.Set("Content-Type", "application/json")
+	httpRq.Header.Set(WebhookHeaderEvent, event)
+	httpRq.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpRq.Header.Set(WebhookHeaderSignature, SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	rs, err := w.client.Do(httpRq)
+	if err != nil {
+		return errors.ErrWebhookRequestFailed(err, ctx)
+	}
+	defer func() { _ = rs.Body.Close() }()
+	_, _ = io.Copy(ioutil.Discard, rs.Body)
+	if rs.StatusCode < 200 || rs.StatusCode >= 300 {
+		return errors.ErrWebhookResponseError(ctx, rs.Status)
+	}
+	l.Dbg("ok")
+	return nil
+}
+
+func (w *webhookChannel) Send(ctx context.Context, delivery *domain.OutboxDelivery) error {
+	if delivery.Notification == nil || delivery.Notification.Webhook == nil || delivery.Notification.Webhook.Url == "" {
+		return errors.ErrOutboxDeliveryInvalid(ctx, delivery.Id)
+	}
+	msg, err := w.renderer.Render(ctx, delivery)
+	if err != nil {
+		return err
+	}
+	return w.post(ctx, delivery.Notification.Webhook, delivery.Id, deliveryType(delivery), []byte(msg.Body), time.Now())
+}
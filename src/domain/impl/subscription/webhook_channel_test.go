@@ -0,0 +1,158 @@
+package subscription
+
+import (
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type webhookChannelTestSuite struct {
+	kitTestSuite.Suite
+}
+
+func (s *webhookChannelTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestWebhookChannelSuite(t *testing.T) {
+	suite.Run(t, new(webhookChannelTestSuite))
+}
+
+func (s *webhookChannelTestSuite) Test_Send_Signed() {
+	secret := "0123456789abcdef"
+	var received *WebhookPayload
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(WebhookHeaderTimestamp), 10, 64)
+		if r.Header.Get(WebhookHeaderSignature) != SignWebhookPayload(secret, timestamp, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received = &WebhookPayload{}
+		_ = json.Unmarshal(body, received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	svc := NewWebhookChannel(NewNotificationRenderer(), nil).(*webhookChannel)
+	svc.client = server.Client()
+	delivery := &domain.OutboxDelivery{
+		Id: "delivery-id",
+		Notification: &domain.SubscriptionNotification{
+			Id:      "n",
+			Channel: domain.SubscriptionNotificationChannelWebhook,
+			Webhook: &domain.SubscriptionWebhookNotificationDetails{Url: server.URL, Secret: secret},
+		},
+		Chain: &domain.ProfitableChain{Id: "chain-id", Asset: "RUB", ProfitShare: 1.02, Bids: []*domain.Bid{{Id: "bid"}}},
+	}
+
+	err := svc.Send(s.Ctx, delivery)
+	s.NoError(err)
+	s.NotEmpty(received)
+	s.Equal("delivery-id", received.Id)
+	s.Equal(domain.OpportunityTypeChain, received.Event)
+	s.Equal("n", received.NotificationId)
+	s.Equal("chain-id", received.Chain.Id)
+	s.InDelta(2.0, received.Chain.Profit, 1e-9)
+	s.Equal("bid", received.Chain.Bids[0].Id)
+
+	// wrong secret isn't accepted by the receiver
+	delivery.Notification.Webhook.Secret = "another-secret-value"
+	err = svc.Send(s.Ctx, delivery)
+	s.AssertAppErr(err, errors.ErrCodeWebhookResponseError)
+}
+
+func (s *webhookChannelTestSuite) Test_Send_Template() {
+	var received string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	svc := NewWebhookChannel(NewNotificationRenderer(), nil).(*webhookChannel)
+	svc.client = server.Client()
+	err := svc.Send(s.Ctx, &domain.OutboxDelivery{
+		Id: "delivery-id",
+		Notification: &domain.SubscriptionNotification{
+			Id:      "n",
+			Channel: domain.SubscriptionNotificationChannelWebhook,
+			Webhook: &domain.SubscriptionWebhookNotificationDetails{Url: server.URL, Secret: "0123456789abcdef"},
+			Templates: &domain.NotificationTemplates{
+				Chain: &domain.NotificationTemplate{Body: `{"text":{{ json (printf "%s %.1f%%" .Chain.Asset .Chain.Profit) }}}`},
+			},
+		},
+		Chain: &domain.ProfitableChain{Id: "chain-id", Asset: "RUB", ProfitShare: 1.02},
+	})
+	s.NoError(err)
+	s.Equal(`{"text":"RUB 2.0%"}`, received)
+}
+
+func (s *webhookChannelTestSuite) Test_Send_Unreachable() {
+	svc := NewWebhookChannel(NewNotificationRenderer(), &WebhookOptions{Timeout: time.Second}).(*webhookChannel)
+	err := svc.post(s.Ctx, &domain.SubscriptionWebhookNotificationDetails{Url: "https://127.0.0.1:1", Secret: "0123456789abcdef"}, "id", domain.OpportunityTypeChain, []byte("{}"), time.Now())
+	s.AssertAppErr(err, errors.ErrCodeWebhookRequestFailed)
+}
+
+func (s *webhookChannelTestSuite) Test_Send_WhenPrivateAddress_Blocked() {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// the check is done on dial, so a public host resolving to the private address is blocked as well
+	svc := NewWebhookChannel(NewNotificationRenderer(), nil).(*webhookChannel)
+	err := svc.post(s.Ctx, &domain.SubscriptionWebhookNotificationDetails{Url: server.URL, Secret: "0123456789abcdef"}, "id", domain.OpportunityTypeChain, []byte("{}"), time.Now())
+	s.AssertAppErr(err, errors.ErrCodeWebhookRequestFailed)
+	s.False(called)
+}
+
+func (s *webhookChannelTestSuite) Test_WebhookAddressAllowed() {
+	for addr, allowed := range map[string]bool{
+		"93.184.216.34":      true,
+		"2606:2800:220:1::1": true,
+		"127.0.0.1":          false,
+		"10.0.0.1":           false,
+		"172.16.5.4":         false,
+		"192.168.1.1":        false,
+		"100.64.0.1":         false,
+		"169.254.169.254":    false,
+		"0.0.0.0":            false,
+		"::1":                false,
+		"fd00:ec2::254":      false,
+		"fe80::1":            false,
+		"::ffff:10.0.0.1":    false,
+	} {
+		s.Equal(allowed, webhookAddressAllowed(net.ParseIP(addr)), addr)
+	}
+}
+
+func (s *webhookChannelTestSuite) Test_Send_WhenNoOpportunity_Fail() {
+	svc := NewWebhookChannel(NewNotificationRenderer(), nil)
+	err := svc.Send(s.Ctx, &domain.OutboxDelivery{
+		Id: "delivery-id",
+		Notification: &domain.SubscriptionNotification{
+			Channel: domain.SubscriptionNotificationChannelWebhook,
+			Webhook: &domain.SubscriptionWebhookNotificationDetails{Url: "https://example.com"},
+		},
+	})
+	s.AssertAppErr(err, errors.ErrCodeOutboxDeliveryInvalid)
+}
+
+func (s *webhookChannelTestSuite) Test_SignWebhookPayload() {
+	// stable signature, so receivers can verify it in any language
+	s.Equal("sha256=1122767b193110cfec322b6f199b599edbf608ed087f2d27afb0b97d99523908", SignWebhookPayload("secret", 1, []byte("{}")))
+}
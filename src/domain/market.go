@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
+)
+
+// RatePointAll exchange code or method of the series aggregated over all exchanges or methods
+const RatePointAll = "*"
+
+// RatePoint aggregated bids of the pair on the exchange by the method at the moment
+type RatePoint struct {
+	SrcAsset     string    // SrcAsset - source asset
+	TrgAsset     string    // TrgAsset - target asset
+	ExchangeCode string    // ExchangeCode - exchange code, RatePointAll if the point aggregates all exchanges
+	Method       string    // Method - payment method, RatePointAll if the point aggregates all methods
+	Time         time.Time // Time - time of the point, truncated to the history resolution
+	BestRate     float64   // BestRate - the best (max) rate among bids
+	MedianRate   float64   // MedianRate - median rate among bids
+	Volume       float64   // Volume - total available volume of bids
+	Bids         int       // Bids - number of bids
+}
+
+// GetRatePointsRequest request to retrieve rate points
+type GetRatePointsRequest struct {
+	SrcAsset     string    // SrcAsset - source asset
+	TrgAsset     string    // TrgAsset - target asset
+	ExchangeCode string    // ExchangeCode - filters by exchange if specified
+	Method       string    // Method - filters by method if specified
+	From         time.Time // From - points from the time (inclusive)
+	To           time.Time // To - points till the time (exclusive)
+}
+
+// GetRateHistoryRequest request to retrieve rate history candles
+type GetRateHistoryRequest struct {
+	GetRatePointsRequest
+	Resolution time.Duration // Resolution - candle duration
+}
+
+// RateCandle OHLC candle of the best rate
+type RateCandle struct {
+	Time       time.Time // Time - candle start
+	Open       float64   // Open - the best rate at the beginning of the candle
+	High       float64   // High - max of the best rate
+	Low        float64   // Low - min of the best rate
+	Close      float64   // Close - the best rate at the end of the candle
+	MedianRate float64   // MedianRate - median of median rates within the candle
+	Volume     float64   // Volume - average available volume within the candle
+}
+
+// MarketOverviewRequest request to retrieve market overview
+type MarketOverviewRequest struct {
+	Method string // Method - takes into account bids with the payment method only
+}
+
+// PairRate aggregated bids of the pair
+type PairRate struct {
+	ExchangeCode string  // ExchangeCode - exchange code, for the overall rate it's the exchange with the best rate
+	BestRate     float64 // BestRate - the best (max) rate
+	Volume       float64 // Volume - total available volume
+	Bids         int     // Bids - number of bids
+}
+
+// PairOverview best rates of the pair per exchange and overall
+type PairOverview struct {
+	SrcAsset  string      // SrcAsset - source asset
+	TrgAsset  string      // TrgAsset - target asset
+	Overall   *PairRate   // Overall - aggregated across all exchanges
+	Exchanges []*PairRate // Exchanges - aggregated per exchange, ordered by exchange code
+}
+
+// MarketOverview matrix of best rates of all pairs
+type MarketOverview struct {
+	Assets    []string        // Assets - all assets, ordered
+	Exchanges []string        // Exchanges - all exchanges, ordered
+	Pairs     []*PairOverview // Pairs - pairs ordered by source and target assets
+}
+
+// ReferenceRates mid-market rates of assets in the base currency
+type ReferenceRates struct {
+	Base      string             // Base - base currency
+	Rates     map[string]float64 // Rates - how many units of the base currency one unit of the asset costs
+	UpdatedAt time.Time          // UpdatedAt - when rates have been retrieved from the source
+}
+
+// ReferenceRateSource is an external source of mid-market rates
+type ReferenceRateSource interface {
+	// GetRates retrieves rates quoted as units of the asset per one unit of the base currency (e.g. USD: 1, RUB: 61.5)
+	GetRates(ctx context.Context, base string) (map[string]float64, error)
+}
+
+// ReferenceRateProvider keeps up-to-date reference rates and normalizes amounts to the base currency
+type ReferenceRateProvider interface {
+	// Init initializes provider
+	Init(cfg *service.Config)
+	// Run loads rates and runs worker which refreshes them
+	Run(ctx context.Context) error
+	// Stop stops worker
+	Stop(ctx context.Context) error
+	// BaseCurrency returns the base currency
+	BaseCurrency() string
+	// GetRates returns the current rates
+	GetRates(ctx context.Context) *ReferenceRates
+	// ToBase converts amount of the asset to the base currency. Returns false if rate of the asset is unknown
+	ToBase(ctx context.Context, asset string, amount float64) (float64, bool)
+}
+
+// MarketService provides market data
+type MarketService interface {
+	// Init initializes service
+	Init(cfg *service.Config)
+	// GetRateHistory retrieves rate history of the pair as OHLC candles
+	GetRateHistory(ctx context.Context, rq *GetRateHistoryRequest) ([]*RateCandle, error)
+	// GetMarketOverview retrieves best rates of all pairs from the current bids snapshot
+	GetMarketOverview(ctx context.Context, rq *MarketOverviewRequest) (*MarketOverview, error)
+	// GetReferenceRates retrieves the current reference rates
+	GetReferenceRates(ctx context.Context) (*ReferenceRates, error)
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
+)
+
+const (
+	OutboxStatusPending   = "pending"   // OutboxStatusPending delivery waits for the first attempt or retry
+	OutboxStatusSending   = "sending"   // OutboxStatusSending delivery is claimed by a worker
+	OutboxStatusDelivered = "delivered" // OutboxStatusDelivered delivery succeeded
+	OutboxStatusDead      = "dead"      // OutboxStatusDead delivery permanently failed, it's retried only if replayed
+)
+
+// DeliveryTypeDigest type of deliveries with digests, it isn't an opportunity type subscriptions can filter by
+const DeliveryTypeDigest = "digest"
+
+// NotificationDigest is a summary of opportunities matched by a subscription within a period, the most profitable go first
+type NotificationDigest struct {
+	Chains  []*ProfitableChain // Chains the most profitable chains
+	Spreads []*Spread          // Spreads the most profitable spreads
+	Matched int                // Matched number of opportunities matched within the period, including ones which aren't in the digest
+	From    time.Time          // From when the first opportunity has been matched
+	To      time.Time          // To when the digest is built
+}
+
+// DigestItem opportunity collected for a digest
+type DigestItem struct {
+	OpportunityType string           // OpportunityType type of the opportunity (chain, spread)
+	OpportunityId   string           // OpportunityId chain or spread id
+	Profit          float64          // Profit profit share of the opportunity
+	Chain           *ProfitableChain // Chain collected chain
+	Spread          *Spread          // Spread collected spread
+}
+
+// PendingDigest digest of a subscription which is being collected and isn't sent yet
+type PendingDigest struct {
+	Id             string        // Id digest id, deliveries of the digest use it as an opportunity id
+	SubscriptionId string        // SubscriptionId subscription the digest is collected for
+	Items          []*DigestItem // Items the most profitable opportunities first, no more than max items of the digest
+	Matched        int           // Matched number of opportunities matched since the digest is started
+	From           time.Time     // From when the first opportunity has been collected
+}
+
+// RenderedMessage message rendered with templates
+type RenderedMessage struct {
+	Subject string // Subject message subject, empty if the channel doesn't support subjects
+	Body    string // Body message body
+}
+
+// TemplatePreviewRequest request to render a template against a sample opportunity
+type TemplatePreviewRequest struct {
+	Channel  string                // Channel notification channel the template is rendered for
+	Type     string                // Type delivery type (chain, spread, digest)
+	Template *NotificationTemplate // Template template to render, if empty, the template the channel uses by default is rendered
+	Chain    *ProfitableChain      // Chain chain to render, if empty, a sample chain is taken
+}
+
+// NotificationRenderer renders notification messages with text/template templates
+type NotificationRenderer interface {
+	// Init initializes renderer
+	Init(cfg *service.Config)
+	// Validate checks if templates of the channel can be parsed and rendered
+	Validate(ctx context.Context, channel string, templates *NotificationTemplates) error
+	// Render renders message of the delivery
+	// it returns nil if there is no template for the delivery, so the channel sends its own default payload (e.g. webhook JSON)
+	Render(ctx context.Context, delivery *OutboxDelivery) (*RenderedMessage, error)
+	// Preview renders a template against a sample opportunity
+	Preview(ctx context.Context, rq *TemplatePreviewRequest) (*RenderedMessage, error)
+}
+
+// OutboxDelivery is a delivery of an opportunity to one notification of a subscription
+type OutboxDelivery struct {
+	Id              string                    // Id delivery id
+	SubscriptionId  string                    // SubscriptionId subscription the delivery is made for
+	UserId          string                    // UserId owner of the subscription
+	Notification    *SubscriptionNotification // Notification snapshot of the notification at the moment the delivery is recorded
+	OpportunityType string                    // OpportunityType type of the opportunity (chain, spread, digest)
+	OpportunityId   string                    // OpportunityId chain, spread or digest id
+	Chain           *ProfitableChain          // Chain delivered chain
+	Spread          *Spread                   // Spread delivered spread
+	Digest          *NotificationDigest       // Digest delivered digest
+	Status          string                    // Status delivery status
+	Attempts        int                       // Attempts number of delivery attempts made
+	NextAttemptAt   time.Time                 // NextAttemptAt when the delivery is attempted next, for claimed deliveries it's when the claim expires
+	LastError       string                    // LastError error of the last failed attempt
+	DeliveredAt     *time.Time                // DeliveredAt when the delivery succeeded
+	CreatedAt       time.Time                 // CreatedAt when the delivery is recorded
+	UpdatedAt       time.Time                 // UpdatedAt when the delivery is updated last time
+}
+
+// SearchOutboxRequest request to search deliveries. The latest deliveries go first
+type SearchOutboxRequest struct {
+	kit.PagingRequest
+	Statuses       []string // Statuses filters by statuses
+	Channel        string   // Channel filters by notification channel
+	SubscriptionId string   // SubscriptionId filters by subscription
+}
+
+// SearchOutboxResponse response on search deliveries
+type SearchOutboxResponse struct {
+	kit.PagingResponse
+	Deliveries []*OutboxDelivery
+}
+
+// NotificationOutbox durably records deliveries and delivers them through notification channels with retries
+type NotificationOutbox interface {
+	// Init initializes service
+	Init(cfg *service.Config)
+	// Enqueue records deliveries, they are delivered by workers
+	Enqueue(ctx context.Context, deliveries []*OutboxDelivery) error
+	// Run runs delivery workers
+	Run(ctx context.Context) error
+	// Stop stops workers
+	Stop(ctx context.Context) error
+	// Search searches deliveries
+	Search(ctx context.Context, rq *SearchOutboxRequest) (*SearchOutboxResponse, error)
+	// Replay moves dead deliveries back to the queue, attempts start over
+	Replay(ctx context.Context, deliveryIds []string) ([]*OutboxDelivery, error)
+}
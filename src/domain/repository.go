@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/mikhailbolshakov/cryptocare/src/kit"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/auth"
+	"time"
 )
 
 type GetBidsRequest struct {
@@ -18,24 +19,46 @@ type GetBidsResponse struct {
 
 // BidStorage provides an access to bids storage
 type BidStorage interface {
-	// GetBidLightsBySourceAsset returns bods by the source asset
+	// GetBidsLightAll returns all public bids
 	GetBidsLightAll(ctx context.Context) ([]*BidLight, error)
-	// GetBidsByIds retrieves full bids by Ids
+	// GetBidsByIds retrieves full public bids by Ids
 	GetBidsByIds(ctx context.Context, ids []string) ([]*Bid, error)
-	// PutBids puts bids
+	// PutBids puts bids. Private bids are kept apart from the public market. If ttl isn't specified, DefaultBidTtlSec is taken
 	PutBids(ctx context.Context, bids []*Bid, ttlSec uint32) error
+	// GetBidsByOwner retrieves manual bids of the owner (both public and private)
+	GetBidsByOwner(ctx context.Context, ownerId string) ([]*Bid, error)
+	// DeleteBid deletes bid by id (either public or private)
+	DeleteBid(ctx context.Context, bidId string) error
+	// GetPrivateBidsLightByOwner retrieves private bids of the owner
+	GetPrivateBidsLightByOwner(ctx context.Context, ownerId string) ([]*BidLight, error)
+	// GetPrivateBidsByIds retrieves full private bids by Ids
+	GetPrivateBidsByIds(ctx context.Context, ids []string) ([]*Bid, error)
+	// GetPrivateBidOwners retrieves users having private bids
+	GetPrivateBidOwners(ctx context.Context) ([]string, error)
 }
 
 // BidStorage provides an access to order storage
 type ChainStorage interface {
 	// SaveProfitableChains save profitable chains to store
 	SaveProfitableChains(ctx context.Context, chains []*ProfitableChain) error
-	// GetProfitableChains retrieves stored profitable chains
+	// GetProfitableChains retrieves stored profitable chains, page size follows the same rules as for the service
 	GetProfitableChains(ctx context.Context, rq *GetProfitableChainsRequest) (*GetProfitableChainsResponse, error)
 	// GetProfitableChain retrieves stored profitable chain by id
 	GetProfitableChain(ctx context.Context, chainId string) (*ProfitableChain, error)
 	// ProfitableChainExists checks if profitable chain exists
 	ProfitableChainExists(ctx context.Context, chainId string) (bool, error)
+	// GetExpiringChains retrieves chains (with bids) which expire within the given period (from, to]
+	GetExpiringChains(ctx context.Context, from, to time.Time) ([]*ProfitableChain, error)
+}
+
+// ChainArchiveStorage provides an access to the cold storage of expired chains
+type ChainArchiveStorage interface {
+	// ArchiveChains puts chains to the archive. If a chain is already archived, it's overwritten
+	ArchiveChains(ctx context.Context, chains []*ProfitableChain) error
+	// GetArchivedChain retrieves archived chain by id
+	GetArchivedChain(ctx context.Context, chainId string) (*ProfitableChain, error)
+	// GetArchivedChains retrieves chains created within [from, to), the latest chains go first, at most limit chains
+	GetArchivedChains(ctx context.Context, from, to time.Time, limit int) ([]*ProfitableChain, error)
 }
 
 // UserStorage manages user storage
@@ -65,3 +88,59 @@ type SubscriptionStorage interface {
 	// SearchSubscriptions searches subscriptions
 	SearchSubscriptions(ctx context.Context, rq *SearchSubscriptionsRequest) ([]*Subscription, error)
 }
+
+// RateHistoryStorage provides an access to rate history time series
+type RateHistoryStorage interface {
+	// SaveRatePoints saves rate points. Points with the same key and time are overwritten
+	SaveRatePoints(ctx context.Context, points []*RatePoint) error
+	// GetRatePoints retrieves rate points ordered by time
+	GetRatePoints(ctx context.Context, rq *GetRatePointsRequest) ([]*RatePoint, error)
+	// DeleteRatePoints deletes points older than the given time
+	DeleteRatePoints(ctx context.Context, before time.Time) error
+}
+
+// SpreadStorage provides an access to spreads storage
+type SpreadStorage interface {
+	// SaveSpreads saves spreads
+	SaveSpreads(ctx context.Context, spreads []*Spread) error
+	// GetSpreads retrieves stored spreads
+	GetSpreads(ctx context.Context, rq *GetSpreadsRequest) (*GetSpreadsResponse, error)
+	// GetSpread retrieves stored spread by id
+	GetSpread(ctx context.Context, spreadId string) (*Spread, error)
+	// SpreadExists checks if spread exists
+	SpreadExists(ctx context.Context, spreadId string) (bool, error)
+}
+
+// NotificationOutboxStorage provides an access to the notification outbox
+type NotificationOutboxStorage interface {
+	// AddDeliveries records new deliveries
+	// delivery of an opportunity already recorded for the same notification of the subscription is skipped
+	AddDeliveries(ctx context.Context, deliveries []*OutboxDelivery) error
+	// ClaimDeliveries claims pending deliveries which are due and sending deliveries which claim has expired
+	// claimed deliveries are moved to the sending status till leaseUntil, so other workers skip them
+	ClaimDeliveries(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]*OutboxDelivery, error)
+	// UpdateDelivery updates status, attempts and errors of the delivery
+	UpdateDelivery(ctx context.Context, delivery *OutboxDelivery) error
+	// GetDeliveries retrieves deliveries by ids
+	GetDeliveries(ctx context.Context, ids []string) ([]*OutboxDelivery, error)
+	// SearchDeliveries searches deliveries
+	SearchDeliveries(ctx context.Context, rq *SearchOutboxRequest) (*SearchOutboxResponse, error)
+	// DeleteDeliveries deletes deliveries with the given status (delivered or dead) updated before the given time
+	DeleteDeliveries(ctx context.Context, status string, before time.Time) error
+}
+
+// DeliveryPolicyStorage keeps state of delivery policies, so that it's shared by instances and survives restarts
+type DeliveryPolicyStorage interface {
+	// ReserveMessages reserves up to count messages of the subscription within the throttling window started since
+	// no more than maxMessages are sent within the window, returns number of reserved messages
+	ReserveMessages(ctx context.Context, subscriptionId string, count, maxMessages int, since, now time.Time) (int, error)
+	// DeleteMessages deletes messages sent before the given time
+	DeleteMessages(ctx context.Context, before time.Time) error
+	// AddDigestItems adds items to the pending digest of the subscription, the digest is started if there is no pending one
+	// the most profitable items are kept, no more than maxItems
+	AddDigestItems(ctx context.Context, subscriptionId string, items []*DigestItem, maxItems int, now time.Time) error
+	// GetPendingDigests retrieves all pending digests
+	GetPendingDigests(ctx context.Context) ([]*PendingDigest, error)
+	// DeletePendingDigest deletes the pending digest, a digest started again after it has been retrieved isn't deleted
+	DeletePendingDigest(ctx context.Context, subscriptionId, digestId string) error
+}
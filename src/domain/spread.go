@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
+)
+
+const (
+	SpreadTypeCrossExchange = "cross-exchange" // SpreadTypeCrossExchange the asset is bought on one exchange and sold on another
+	SpreadTypeCrossMethod   = "cross-method"   // SpreadTypeCrossMethod the asset is bought and sold on the same exchange with different payment methods
+)
+
+// Spread is a two-leg opportunity: buy the base asset for the quote asset and sell it back for more
+// the base asset is the one which costs more than one unit of the quote asset (e.g. USDT for RUB)
+type Spread struct {
+	Id            string    // Id - spread Id, calculated as hash from bidIds
+	Type          string    // Type - spread type (cross-exchange, cross-method)
+	BaseAsset     string    // BaseAsset - asset which is bought and sold
+	QuoteAsset    string    // QuoteAsset - asset the base asset is paid with, profit is in this asset
+	Buy           *Bid      // Buy - bid converting the quote asset to the base asset
+	Sell          *Bid      // Sell - bid converting the base asset to the quote asset
+	BuyPrice      float64   // BuyPrice - price of the base asset in the quote asset when buying
+	SellPrice     float64   // SellPrice - price of the base asset in the quote asset when selling
+	SpreadShare   float64   // SpreadShare - sell price to buy price ratio
+	Volume        float64   // Volume - amount of the base asset which can be bought and sold with both bids
+	Profit        float64   // Profit - profit in the quote asset if the whole volume is traded
+	ExchangeCodes []string  // ExchangeCodes - exchanges of both legs
+	Methods       []string  // Methods - union methods of both legs
+	CreatedAt     time.Time // CreatedAt - when the spread has been detected
+	ObservedAt    time.Time // ObservedAt - when the oldest bid of the spread has been observed
+	ExpiresAt     time.Time // ExpiresAt - when the spread expires in the storage
+}
+
+// GetSpreadsRequest request to retrieve spreads. Spreads with the highest spread go first
+type GetSpreadsRequest struct {
+	kit.PagingRequest
+	Assets        []string // Assets - retrieves spreads which base or quote asset is among the given ones
+	Types         []string // Types - retrieves spreads of the given types
+	ExchangeCodes []string // ExchangeCodes - retrieves spreads which exchanges are among the given ones
+	Methods       []string // Methods - retrieves spreads which methods are among the given ones
+	MinSpread     float64  // MinSpread - min spread in percents
+}
+
+type GetSpreadsResponse struct {
+	kit.PagingResponse
+	Spreads []*Spread
+}
+
+// SpreadNotifier responsible for notification users about spreads
+type SpreadNotifier interface {
+	// NotifySpreads notifies
+	NotifySpreads(ctx context.Context, spreads []*Spread) error
+}
+
+// SpreadDetector continuously computes spreads from the current bids
+type SpreadDetector interface {
+	// Init initializes detector
+	Init(cfg *service.Config)
+	// Run runs detector worker
+	Run(ctx context.Context) error
+	// Stop stops detector worker
+	Stop(ctx context.Context) error
+	// GetSpreads retrieves spreads by criteria
+	GetSpreads(ctx context.Context, rq *GetSpreadsRequest) (*GetSpreadsResponse, error)
+	// GetSpread retrieves spread by id
+	GetSpread(ctx context.Context, spreadId string) (*Spread, error)
+}
@@ -3,10 +3,18 @@ package domain
 import (
 	"context"
 	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
 )
 
 const (
 	SubscriptionNotificationChannelTelegram = "telegram"
+	SubscriptionNotificationChannelEmail    = "email"
+	SubscriptionNotificationChannelWebhook  = "webhook"
+)
+
+const (
+	OpportunityTypeChain  = "chain"  // OpportunityTypeChain profitable chains
+	OpportunityTypeSpread = "spread" // OpportunityTypeSpread two-leg spreads
 )
 
 // SubscriptionChainFilter allows conditional subscription
@@ -15,20 +23,99 @@ type SubscriptionChainFilter struct {
 	Methods   []string `json:"methods,omitempty"`   // Methods filters by methods
 	Exchanges []string `json:"exchanges,omitempty"` // Exchanges filters by exchange codes
 	MaxDepth  int      `json:"maxDepth,omitempty"`  // MaxDepth max depth of chains
-	MinProfit float64  `json:"minProfit,omitempty"` // MinProfit min profit of chains
+	MinProfit float64  `json:"minProfit,omitempty"` // MinProfit min profit of chains, for spreads it's a min spread
+	// Opportunities types of opportunities subscription is notified about. If empty, only chains are notified
+	Opportunities []string `json:"opportunities,omitempty"`
+	// Expression boolean expression an opportunity must satisfy in addition to the other criteria
+	// for example: profit >= 1.5 && !("bybit" in exchanges) && volumeUsd > 500
+	//
+	// variables of the opportunity model (spreads are evaluated as chains of two bids, the base asset is the asset):
+	//   opportunity  string  - opportunity type: chain, spread
+	//   profit       number  - profit in percents, for spreads it's a spread
+	//   asset        string  - target asset of the chain, base asset of the spread
+	//   assets       list    - assets of the chain bids, base and quote assets of the spread
+	//   exchanges    list    - exchange codes
+	//   methods      list    - payment methods
+	//   bidTypes     list    - types of bids: p2p, spot
+	//   depth        number  - number of bids
+	//   score        number  - profit in percents per one conversion
+	//   volume       number  - executable volume in the asset, 0 if unknown
+	//   baseCurrency string  - currency baseVolume and baseProfit are normalized to, empty if unknown
+	//   baseVolume   number  - executable volume in the base currency, 0 if unknown
+	//   baseProfit   number  - absolute profit in the base currency, 0 if unknown
+	//   volumeUsd    number  - executable volume in USD, available only if the base currency is USD, opportunities without it don't match
+	//   profitUsd    number  - absolute profit in USD, available only if the base currency is USD, opportunities without it don't match
+	//   ageSec       number  - seconds passed since the oldest bid has been observed
+	Expression string `json:"expression,omitempty"`
 }
 
 // SubscriptionTelegramNotificationDetails details of telegram notification
 type SubscriptionTelegramNotificationDetails struct {
-	Channel int `json:"channel"` // Channel telegram channel
+	Channel  int    `json:"channel"`            // Channel telegram channel
+	Verified bool   `json:"verified,omitempty"` // Verified if the owner of the subscription has confirmed the channel is theirs, notifications to unverified channels stay inactive
+	BotId    string `json:"botId,omitempty"`    // BotId bot notifications are sent from, if empty, the bot of the user or the default one
+	// AwaitingVerification if the notification has been deactivated because the channel isn't verified, it's activated on verification
+	AwaitingVerification bool `json:"awaitingVerification,omitempty"`
+}
+
+// SubscriptionEmailNotificationDetails details of email notification
+type SubscriptionEmailNotificationDetails struct {
+	To       []string `json:"to"`                 // To recipients addresses
+	Verified bool     `json:"verified,omitempty"` // Verified if all the recipients are confirmed by the owner of the subscription, notifications to unverified addresses stay inactive
+	// AwaitingVerification if the notification has been deactivated because recipients aren't verified, it's activated on verification
+	AwaitingVerification bool `json:"awaitingVerification,omitempty"`
+}
+
+// SubscriptionWebhookNotificationDetails details of webhook notification
+type SubscriptionWebhookNotificationDetails struct {
+	Url    string `json:"url"`    // Url https endpoint payloads are posted to
+	Secret string `json:"secret"` // Secret key payloads are signed with (HMAC-SHA256), generated if empty
+}
+
+// NotificationTemplate Go text/template templates of a message
+type NotificationTemplate struct {
+	Subject string `json:"subject,omitempty"` // Subject template of the message subject, supported by email only
+	Body    string `json:"body,omitempty"`    // Body template of the message body
+}
+
+// NotificationTemplates templates of a notification by delivery type
+// if a template isn't specified, the global template configured for the channel is used, then the default one of the channel
+type NotificationTemplates struct {
+	Chain  *NotificationTemplate `json:"chain,omitempty"`  // Chain template of chain messages
+	Spread *NotificationTemplate `json:"spread,omitempty"` // Spread template of spread messages
+	Digest *NotificationTemplate `json:"digest,omitempty"` // Digest template of digest messages
 }
 
 // SubscriptionNotification notification details
 type SubscriptionNotification struct {
-	Id       string                                   `json:"id"`                 // Id notification id
-	Channel  string                                   `json:"channel"`            // Channel notification channel
-	IsActive bool                                     `json:"isActive"`           // IsActive if notification active
-	Telegram *SubscriptionTelegramNotificationDetails `json:"telegram,omitempty"` // Telegram telegram details
+	Id        string                                   `json:"id"`                  // Id notification id
+	Channel   string                                   `json:"channel"`             // Channel notification channel
+	IsActive  bool                                     `json:"isActive"`            // IsActive if notification active
+	Telegram  *SubscriptionTelegramNotificationDetails `json:"telegram,omitempty"`  // Telegram telegram details
+	Email     *SubscriptionEmailNotificationDetails    `json:"email,omitempty"`     // Email email details
+	Webhook   *SubscriptionWebhookNotificationDetails  `json:"webhook,omitempty"`   // Webhook webhook details
+	Templates *NotificationTemplates                   `json:"templates,omitempty"` // Templates custom message templates of the notification
+}
+
+// SubscriptionQuietHours period of the day when notifications aren't sent
+type SubscriptionQuietHours struct {
+	From     string `json:"from"`               // From start of quiet hours (HH:MM)
+	To       string `json:"to"`                 // To end of quiet hours (HH:MM), if less than From, quiet hours cross midnight
+	Timezone string `json:"timezone,omitempty"` // Timezone IANA timezone of the subscriber (e.g. Europe/Moscow), UTC if empty
+}
+
+// SubscriptionDigest batches matched opportunities into a periodic summary
+type SubscriptionDigest struct {
+	PeriodMin int `json:"periodMin"`          // PeriodMin how often the digest is sent in minutes
+	MaxItems  int `json:"maxItems,omitempty"` // MaxItems max number of the most profitable opportunities in the digest
+}
+
+// SubscriptionDeliveryPolicy rules applied to notifications of the subscription
+type SubscriptionDeliveryPolicy struct {
+	MaxMessages int                     `json:"maxMessages,omitempty"` // MaxMessages max number of messages per window, unlimited if empty
+	WindowSec   int                     `json:"windowSec,omitempty"`   // WindowSec throttling window in seconds
+	QuietHours  *SubscriptionQuietHours `json:"quietHours,omitempty"`  // QuietHours opportunities matched during quiet hours are dropped, digests are postponed
+	Digest      *SubscriptionDigest     `json:"digest,omitempty"`      // Digest if specified, opportunities are sent as periodic digests instead of a message per opportunity
 }
 
 // Subscription subscription
@@ -38,20 +125,33 @@ type Subscription struct {
 	IsActive      bool                        // IsActive if subscription active
 	Filter        *SubscriptionChainFilter    // Filter subscription filter
 	Notifications []*SubscriptionNotification // Notifications notifications
+	Policy        *SubscriptionDeliveryPolicy // Policy delivery policy, all matched opportunities are sent immediately if empty
 }
 
 // SearchSubscriptionsRequest request to retrieve subscription
+// criteria of the opportunities narrow subscriptions down to ones which might match, the filters are to be checked after
 type SearchSubscriptionsRequest struct {
-	WithInActive bool   // WithInActive if true, inactive subscription are also retrieved
-	UserId       string // UserId filter by user
+	WithInActive bool     // WithInActive if true, inactive subscription are also retrieved
+	UserId       string   // UserId filter by user
+	Opportunity  string   // Opportunity filter by subscriptions notified about opportunities of the type
+	Assets       []string // Assets filter by subscriptions with any of the assets or without assets
+	ProfitShare  float64  // ProfitShare filter by subscriptions with min profit not greater than the profit share
 }
 
 // SubscriptionService subscription service
 type SubscriptionService interface {
 	// Notifier implements notifier
 	Notifier
+	// SpreadNotifier implements spread notifier
+	SpreadNotifier
+	// PrivateChainNotifier implements notifier of chains with private bids
+	PrivateChainNotifier
 	// Init initializes service
 	Init(cfg *service.Config)
+	// Run runs sending digests
+	Run(ctx context.Context) error
+	// Stop stops sending digests
+	Stop(ctx context.Context) error
 	// Create creates a new subscription
 	Create(ctx context.Context, subscription *Subscription) (*Subscription, error)
 	// Update updates a subscription
@@ -62,14 +162,85 @@ type SubscriptionService interface {
 	Get(ctx context.Context, subscriptionId string) (*Subscription, error)
 	// Deactivate deactivates an active subscription
 	Deactivate(ctx context.Context, subscriptionId string) (*Subscription, error)
+	// Activate activates an inactive subscription
+	Activate(ctx context.Context, subscriptionId string) (*Subscription, error)
 	// Search searches subscriptions
 	Search(ctx context.Context, rq *SearchSubscriptionsRequest) ([]*Subscription, error)
+	// PreviewTemplate renders a template against a sample opportunity
+	PreviewTemplate(ctx context.Context, rq *TemplatePreviewRequest) (*RenderedMessage, error)
+	// Preview backtests the filter against the chain history, so the user sees how many alerts the filter gives
+	Preview(ctx context.Context, rq *SubscriptionPreviewRequest) (*SubscriptionPreview, error)
+}
+
+// SubscriptionPreviewRequest request to backtest a filter against the chain history
+type SubscriptionPreviewRequest struct {
+	UserId  string                   // UserId - user who previews the filter
+	Filter  *SubscriptionChainFilter // Filter - candidate filter
+	Hours   int                      // Hours - how many last hours of the history are evaluated
+	Samples int                      // Samples - max number of sample chains
+}
+
+// SubscriptionPreviewHour matches within an hour
+type SubscriptionPreviewHour struct {
+	Hour    time.Time // Hour - start of the hour
+	Matches int       // Matches - number of chains matching the filter found within the hour
+}
+
+// SubscriptionPreview result of the filter backtest
+// only chains are kept in the history, so spreads are never matched
+type SubscriptionPreview struct {
+	From        time.Time                  // From - start of the evaluated period
+	To          time.Time                  // To - end of the evaluated period
+	HistoryFrom *time.Time                 // HistoryFrom - when the oldest chain of the history has been found, nil if the history is empty
+	Scanned     int                        // Scanned - number of chains evaluated
+	Matches     int                        // Matches - number of chains matching the filter
+	Hours       []*SubscriptionPreviewHour // Hours - matches by hours, the oldest hour goes first
+	Samples     []*ProfitableChain         // Samples - the most profitable matching chains
+	// EstimatedDaily estimated number of alerts per day, matches are extrapolated from the period covered by the history
+	// delivery policies aren't taken into account
+	EstimatedDaily float64
+	// Truncated if the history has more chains than evaluated, so matches are lower bound
+	Truncated bool
 }
 
 // TelegramNotifier implements telegram notification
 type TelegramNotifier interface {
-	// Init initializes
-	Init(ctx context.Context) error
-	// Notify builds and sends notification
-	Notify(ctx context.Context, bot string, channels []int, chains []*ProfitableChain) error
+	// Send sends a rendered HTML message to the channel
+	Send(ctx context.Context, bot string, channel int, text string) error
+	// SendMessage sends a rendered HTML message as a single message and returns its id
+	SendMessage(ctx context.Context, bot string, channel int, text string) (int64, error)
+	// EditMessage replaces text of the message sent to the channel
+	EditMessage(ctx context.Context, bot string, channel int, messageId int64, text string) error
+}
+
+// NotificationChannel delivers notifications of one channel type
+type NotificationChannel interface {
+	// Type returns type of notifications the channel delivers
+	Type() string
+	// Validate validates and populates channel details of the notification
+	Validate(ctx context.Context, notification *SubscriptionNotification) error
+	// Send synchronously delivers the chain, the spread or the digest of the delivery to its notification
+	Send(ctx context.Context, delivery *OutboxDelivery) error
+}
+
+// NotificationChannelRegistry keeps notification channels by type
+type NotificationChannelRegistry interface {
+	// Register registers channel, channel of the same type is replaced
+	Register(channel NotificationChannel)
+	// Get retrieves channel by type
+	Get(channelType string) (NotificationChannel, bool)
+	// Types returns sorted types of registered channels
+	Types() []string
+}
+
+// ChainFeed broadcasts found profitable chains to live subscribers (e.g. streaming API)
+type ChainFeed interface {
+	// Notifier implements notifier
+	Notifier
+	// Init initializes feed
+	Init(cfg *service.Config)
+	// Subscribe subscribes on chains matching the filter
+	// returned func must be called to unsubscribe, channel is closed after unsubscribing
+	// an error is returned if the filter expression is invalid
+	Subscribe(ctx context.Context, filter *SubscriptionChainFilter) (<-chan *ProfitableChain, func(), error)
 }
@@ -0,0 +1,235 @@
+package domain
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
+)
+
+// TelegramLink links a telegram account to a user
+type TelegramLink struct {
+	UserId         string    // UserId linked user
+	TelegramUserId int64     // TelegramUserId telegram user id
+	ChatId         int64     // ChatId private chat of the user with the bot, notifications of subscriptions created by the bot are sent to it
+	Username       string    // Username telegram username, might be empty
+	CreatedAt      time.Time // CreatedAt when linked
+}
+
+// TelegramLinkCode one-time code the user sends to the bot to link the telegram account
+type TelegramLinkCode struct {
+	Code      string    // Code one-time code
+	UserId    string    // UserId user requested the code
+	Url       string    // Url deep link opening the bot with the code, empty if bot username isn't configured
+	ExpiresAt time.Time // ExpiresAt code expiration time
+}
+
+const (
+	TelegramChannelVerificationPending  = "pending"  // TelegramChannelVerificationPending code is issued, waiting for confirmation
+	TelegramChannelVerificationVerified = "verified" // TelegramChannelVerificationVerified ownership is confirmed
+
+	TelegramChannelVerifiedByCode  = "code"  // TelegramChannelVerifiedByCode the code was posted to the channel
+	TelegramChannelVerifiedByAdmin = "admin" // TelegramChannelVerifiedByAdmin the bot and the linked telegram account of the user are admins of the channel
+	TelegramChannelVerifiedByLink  = "link"  // TelegramChannelVerifiedByLink the channel is the private chat of the linked telegram account
+)
+
+// TelegramChannelVerification confirmation the telegram channel (chat) belongs to the user
+type TelegramChannelVerification struct {
+	UserId     string     // UserId user verifying the channel
+	ChatId     int64      // ChatId telegram chat id of the channel
+	Code       string     // Code one-time code to post to the channel
+	Status     string     // Status verification status
+	Method     string     // Method how the channel has been verified
+	ExpiresAt  time.Time  // ExpiresAt the code expiration time
+	VerifiedAt *time.Time // VerifiedAt when verified
+	CreatedAt  time.Time  // CreatedAt when the code was issued
+}
+
+const (
+	TelegramAlertActive  = "active"  // TelegramAlertActive the chain is alive, the message is edited as the chain changes
+	TelegramAlertExpired = "expired" // TelegramAlertExpired the chain is dead, the message is marked expired and isn't edited anymore
+)
+
+// TelegramAlert telegram message with a chain, it's edited while the chain changes and marked expired when the chain dies
+type TelegramAlert struct {
+	ChatId         int64                     // ChatId chat the message is sent to
+	MessageId      int64                     // MessageId message id in the chat
+	ChainId        string                    // ChainId chain the message shows
+	DeliveryId     string                    // DeliveryId delivery the message is sent by
+	BotId          string                    // BotId bot the message is sent from, only this bot is allowed to edit the message
+	SubscriptionId string                    // SubscriptionId subscription the message is sent for
+	UserId         string                    // UserId owner of the subscription
+	Notification   *SubscriptionNotification // Notification snapshot of the notification, the message is rendered with its templates
+	Chain          *ProfitableChain          // Chain the latest state of the chain
+	Profit         float64                   // Profit profit share the message shows
+	Status         string                    // Status alert status
+	Pending        bool                      // Pending the chain has changed, so the message is to be edited
+	CreatedAt      time.Time                 // CreatedAt when the message is sent
+	UpdatedAt      time.Time                 // UpdatedAt when the alert is updated last time
+}
+
+const (
+	TelegramBotDefaultId = "default" // TelegramBotDefaultId id of the bot configured globally, it's used unless another bot is selected
+
+	TelegramBotHealthy   = "healthy"   // TelegramBotHealthy the bot delivers messages
+	TelegramBotUnhealthy = "unhealthy" // TelegramBotUnhealthy the last request of the bot failed, messages go through its secondary bot until cooldown passes
+)
+
+// TelegramBotAccount telegram bot notifications are sent from, e.g. a branded bot of a partner
+type TelegramBotAccount struct {
+	Id             string     // Id bot id
+	Name           string     // Name bot name
+	Username       string     // Username telegram username of the bot, it's retrieved from Bot API
+	EncryptedToken string     // EncryptedToken bot token encrypted with the configured key, it's never exposed
+	UserIds        []string   // UserIds users whose subscriptions are notified by the bot by default, if empty, the bot is shared and selected explicitly only
+	SecondaryId    string     // SecondaryId bot messages fail over to if the bot fails
+	RatePerSec     float64    // RatePerSec messages per second the bot sends to all chats, Bot API limit if empty
+	IsActive       bool       // IsActive inactive bots aren't selected
+	Health         string     // Health health status
+	LastError      string     // LastError the last error of the bot
+	FailedAt       *time.Time // FailedAt when the bot failed last time
+	CreatedAt      time.Time  // CreatedAt when created
+	UpdatedAt      time.Time  // UpdatedAt when updated
+}
+
+// TelegramBotAccountRequest request to register or update a bot
+type TelegramBotAccountRequest struct {
+	Name        string   // Name bot name
+	Token       string   // Token bot token, it's checked by Bot API. On update empty token keeps the current one
+	UserIds     []string // UserIds users notified by the bot by default
+	SecondaryId string   // SecondaryId bot messages fail over to
+	RatePerSec  float64  // RatePerSec messages per second the bot sends to all chats
+	IsActive    bool     // IsActive if the bot is active
+}
+
+// TelegramLinkStorage provides an access to telegram links
+type TelegramLinkStorage interface {
+	// SaveLinkCode saves link code
+	SaveLinkCode(ctx context.Context, code *TelegramLinkCode) error
+	// TakeLinkCode retrieves and deletes link code, nil if not found
+	TakeLinkCode(ctx context.Context, code string) (*TelegramLinkCode, error)
+	// SaveLink creates or updates link of the user, a link of the same telegram user to another user is replaced
+	SaveLink(ctx context.Context, link *TelegramLink) error
+	// GetLinkByUser retrieves link by user, nil if not found
+	GetLinkByUser(ctx context.Context, userId string) (*TelegramLink, error)
+	// GetLinkByTelegramUser retrieves link by telegram user, nil if not found
+	GetLinkByTelegramUser(ctx context.Context, telegramUserId int64) (*TelegramLink, error)
+	// DeleteLink deletes link of the user
+	DeleteLink(ctx context.Context, userId string) error
+}
+
+// TelegramChannelStorage provides an access to telegram channel verifications
+type TelegramChannelStorage interface {
+	// SaveChannelVerification creates or updates verification of the channel by the user
+	SaveChannelVerification(ctx context.Context, v *TelegramChannelVerification) error
+	// GetChannelVerification retrieves verification of the channel by the user, nil if not found
+	GetChannelVerification(ctx context.Context, userId string, chatId int64) (*TelegramChannelVerification, error)
+	// GetChannelVerificationByCode retrieves verification of the channel by code, nil if not found
+	GetChannelVerificationByCode(ctx context.Context, chatId int64, code string) (*TelegramChannelVerification, error)
+	// GetChannelVerifications retrieves verifications of the user
+	GetChannelVerifications(ctx context.Context, userId string) ([]*TelegramChannelVerification, error)
+}
+
+// TelegramAlertStorage provides an access to telegram alerts
+type TelegramAlertStorage interface {
+	// SaveAlert creates or updates alert
+	SaveAlert(ctx context.Context, alert *TelegramAlert) error
+	// GetAlertsByChains retrieves active alerts of the chains
+	GetAlertsByChains(ctx context.Context, chainIds []string) ([]*TelegramAlert, error)
+	// GetActiveAlerts retrieves all active alerts
+	GetActiveAlerts(ctx context.Context) ([]*TelegramAlert, error)
+	// DeleteAlerts deletes alerts updated before the given time
+	DeleteAlerts(ctx context.Context, updatedBefore time.Time) error
+}
+
+// TelegramBotAccountStorage provides an access to telegram bots
+type TelegramBotAccountStorage interface {
+	// SaveBot creates or updates bot
+	SaveBot(ctx context.Context, bot *TelegramBotAccount) error
+	// GetBot retrieves bot by id, nil if not found
+	GetBot(ctx context.Context, botId string) (*TelegramBotAccount, error)
+	// GetBots retrieves all bots
+	GetBots(ctx context.Context) ([]*TelegramBotAccount, error)
+	// DeleteBot deletes bot
+	DeleteBot(ctx context.Context, botId string) error
+}
+
+// TelegramBotRegistry keeps telegram bots notifications are sent from
+// a notification is sent by the bot selected by the subscription, otherwise by the bot assigned to the user, otherwise by the default bot
+// if the bot fails, the message goes through its secondary bot
+type TelegramBotRegistry interface {
+	// Init initializes registry
+	Init(cfg *service.Config)
+	// Create registers a bot, the token is checked by Bot API and stored encrypted
+	Create(ctx context.Context, rq *TelegramBotAccountRequest) (*TelegramBotAccount, error)
+	// Update updates a bot
+	Update(ctx context.Context, botId string, rq *TelegramBotAccountRequest) (*TelegramBotAccount, error)
+	// Delete deletes a bot
+	Delete(ctx context.Context, botId string) error
+	// Get retrieves a bot by id
+	Get(ctx context.Context, botId string) (*TelegramBotAccount, error)
+	// GetAll retrieves all bots including the default one
+	GetAll(ctx context.Context) ([]*TelegramBotAccount, error)
+	// Validate checks the user is allowed to select the bot for notifications
+	Validate(ctx context.Context, userId, botId string) error
+	// Do calls send with tokens of bots selected for notifications of the user one by one until it succeeds
+	// bots failed recently are tried last, it returns id of the bot the message has been sent by
+	// send is repeated by the next bot as a whole, so it must send a single message, long messages are to be split beforehand
+	Do(ctx context.Context, userId, botId string, send func(token string) error) (string, error)
+	// Token retrieves token of the bot, e.g. to edit a message the bot sent
+	Token(ctx context.Context, botId string) (string, error)
+}
+
+// TelegramAlerts sends chain alerts and keeps them live
+// messages are edited as profit of chains changes and marked expired when chains die (expire or lose a bid)
+type TelegramAlerts interface {
+	// ChainUpdateNotifier receives recalculated chains, alerts of changed chains are edited by the worker
+	ChainUpdateNotifier
+	// Init initializes service
+	Init(cfg *service.Config)
+	// Run runs worker refreshing alerts
+	Run(ctx context.Context) error
+	// Stop stops worker
+	Stop(ctx context.Context) error
+	// Send sends the rendered chain delivery by the selected bot and remembers the message, so it's kept live
+	// if live alerts are disabled or the message is too long to be edited, it's just sent
+	Send(ctx context.Context, delivery *OutboxDelivery, text string) error
+}
+
+// TelegramChannelVerifier verifies users own telegram channels notifications are sent to
+// the user either posts the issued code to the channel or adds the bot as an admin of the channel the linked telegram account administers
+type TelegramChannelVerifier interface {
+	// Init initializes verifier
+	Init(cfg *service.Config)
+	// Request issues a one-time code verifying the channel
+	Request(ctx context.Context, userId string, chatId int64) (*TelegramChannelVerification, error)
+	// Confirm confirms the channel through Bot API and activates notifications of the user waiting for verification
+	Confirm(ctx context.Context, userId string, chatId int64) (*TelegramChannelVerification, error)
+	// GetVerifications retrieves verifications of the user
+	GetVerifications(ctx context.Context, userId string) ([]*TelegramChannelVerification, error)
+	// IsVerified checks if the channel is verified by the user
+	IsVerified(ctx context.Context, userId string, chatId int64) (bool, error)
+	// HandleMessage verifies the channel if the message posted to it contains a pending code, it returns true if verified
+	HandleMessage(ctx context.Context, msg *telegram.Message) (bool, error)
+}
+
+// TelegramBot handles commands users send to the telegram bot
+// updates are received either by long polling or by webhook depending on config
+type TelegramBot interface {
+	// Init initializes bot
+	Init(cfg *service.Config)
+	// Run starts receiving updates, it does nothing if commands are disabled
+	Run(ctx context.Context) error
+	// Stop stops receiving updates
+	Stop(ctx context.Context) error
+	// HandleUpdate handles an update
+	HandleUpdate(ctx context.Context, update *telegram.Update) error
+	// HandleWebhook checks the secret and handles an update posted to the webhook
+	HandleWebhook(ctx context.Context, secret string, update *telegram.Update) error
+	// LinkCode issues a one-time code linking a telegram account to the user
+	LinkCode(ctx context.Context, userId string) (*TelegramLinkCode, error)
+	// GetLink retrieves telegram link of the user
+	GetLink(ctx context.Context, userId string) (*TelegramLink, error)
+	// Unlink unlinks telegram account of the user
+	Unlink(ctx context.Context, userId string) error
+}
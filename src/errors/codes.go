@@ -61,4 +61,112 @@ const (
 	ErrCodeSubscriptionStorageGet                      = "TRD-058"
 	ErrCodeSubscriptionStorageDel                      = "TRD-059"
 	ErrCodeNotAllowed                                  = "TRD-060"
+	ErrCodeBidInvalid                                  = "TRD-061"
+	ErrCodeBidTypeInvalid                              = "TRD-062"
+	ErrCodeChainSortFieldInvalid                       = "TRD-063"
+	ErrCodeChainProfitRangeInvalid                     = "TRD-064"
+	ErrCodeChainDepthRangeInvalid                      = "TRD-065"
+	ErrCodeChainCursorInvalid                          = "TRD-066"
+	ErrCodeChainsPageSizeExceeded                      = "TRD-067"
+	ErrCodeChainArchiveStoragePut                      = "TRD-068"
+	ErrCodeChainArchiveStorageGet                      = "TRD-069"
+	ErrCodeChainArchiverAlreadyRun                     = "TRD-070"
+	ErrCodeChainNotFound                               = "TRD-071"
+	ErrCodeChainArchiveStorageTypeInvalid              = "TRD-072"
+	ErrCodeRateHistoryStoragePut                       = "TRD-073"
+	ErrCodeRateHistoryStorageGet                       = "TRD-074"
+	ErrCodeRateHistoryStorageDel                       = "TRD-075"
+	ErrCodeRateHistoryPairInvalid                      = "TRD-076"
+	ErrCodeRateHistoryPeriodInvalid                    = "TRD-077"
+	ErrCodeRateHistoryResolutionInvalid                = "TRD-078"
+	ErrCodeRateHistoryTooManyCandles                   = "TRD-079"
+	ErrCodeSpreadStoragePut                            = "TRD-080"
+	ErrCodeSpreadStorageGet                            = "TRD-081"
+	ErrCodeSpreadStorageScan                           = "TRD-082"
+	ErrCodeSpreadDetectorAlreadyRun                    = "TRD-083"
+	ErrCodeSpreadNotFound                              = "TRD-084"
+	ErrCodeSpreadsPageSizeExceeded                     = "TRD-085"
+	ErrCodeSubscriptionOpportunityInvalid              = "TRD-086"
+	ErrCodeReferenceRatesSourceGet                     = "TRD-087"
+	ErrCodeReferenceRatesSourceStatus                  = "TRD-088"
+	ErrCodeReferenceRatesBaseMismatch                  = "TRD-089"
+	ErrCodeReferenceRatesSourceTypeInvalid             = "TRD-090"
+	ErrCodeReferenceRatesProviderAlreadyRun            = "TRD-091"
+	ErrCodeChainBaseAmountInvalid                      = "TRD-092"
+	ErrCodeBidStorageGetBidsByOwner                    = "TRD-093"
+	ErrCodeBidStorageDeleteBid                         = "TRD-094"
+	ErrCodeManualBidNotFound                           = "TRD-095"
+	ErrCodeManualBidTtlInvalid                         = "TRD-096"
+	ErrCodeManualBidsEmpty                             = "TRD-097"
+	ErrCodeManualBidsTooMany                           = "TRD-098"
+	ErrCodeManualBidOwnerEmpty                         = "TRD-099"
+	ErrCodePrivateChainsAlreadyRun                     = "TRD-100"
+	ErrCodeChainsSearchUserEmpty                       = "TRD-101"
+	ErrCodeChainsSearchLimitExceeded                   = "TRD-102"
+	ErrCodeSimulatorScenarioLoad                       = "TRD-103"
+	ErrCodeSimulatorScenarioInvalid                    = "TRD-104"
+	ErrCodeSubscriptionNotificationEmailInvalid        = "TRD-105"
+	ErrCodeSubscriptionNotificationWebhookInvalid      = "TRD-106"
+	ErrCodeWebhookRequestFailed                        = "TRD-107"
+	ErrCodeWebhookResponseError                        = "TRD-108"
+	ErrCodeOutboxDeliveryInvalid                       = "TRD-109"
+	ErrCodeOutboxAlreadyRun                            = "TRD-110"
+	ErrCodeOutboxDeliveryNotFound                      = "TRD-111"
+	ErrCodeOutboxDeliveryNotDead                       = "TRD-112"
+	ErrCodeOutboxReplayEmpty                           = "TRD-113"
+	ErrCodeOutboxStatusInvalid                         = "TRD-114"
+	ErrCodeOutboxStoragePut                            = "TRD-115"
+	ErrCodeOutboxStorageGet                            = "TRD-116"
+	ErrCodeOutboxStorageSearch                         = "TRD-117"
+	ErrCodeOutboxStorageDel                            = "TRD-118"
+	ErrCodeSubscriptionPolicyInvalid                   = "TRD-119"
+	ErrCodeSubscriptionDigestAlreadyRun                = "TRD-120"
+	ErrCodeNotificationTemplateInvalid                 = "TRD-121"
+	ErrCodeNotificationTemplateRender                  = "TRD-122"
+	ErrCodeSubscriptionAlreadyActive                   = "TRD-123"
+	ErrCodeTelegramLinkStoragePut                      = "TRD-124"
+	ErrCodeTelegramLinkStorageGet                      = "TRD-125"
+	ErrCodeTelegramLinkStorageDel                      = "TRD-126"
+	ErrCodeTelegramBotAlreadyRun                       = "TRD-127"
+	ErrCodeTelegramWebhookSecretInvalid                = "TRD-128"
+	ErrCodeTelegramLinkNotFound                        = "TRD-129"
+	ErrCodeTelegramLinkCodeInvalid                     = "TRD-130"
+	ErrCodeTelegramUserIdEmpty                         = "TRD-131"
+	ErrCodeTelegramChannelInvalid                      = "TRD-132"
+	ErrCodeTelegramChannelVerificationNotFound         = "TRD-133"
+	ErrCodeTelegramChannelVerificationExpired          = "TRD-134"
+	ErrCodeTelegramChannelNotVerified                  = "TRD-135"
+	ErrCodeTelegramChannelStoragePut                   = "TRD-136"
+	ErrCodeTelegramChannelStorageGet                   = "TRD-137"
+	ErrCodeTelegramAlertsAlreadyRun                    = "TRD-138"
+	ErrCodeTelegramAlertStoragePut                     = "TRD-139"
+	ErrCodeTelegramAlertStorageGet                     = "TRD-140"
+	ErrCodeTelegramAlertStorageDel                     = "TRD-141"
+	ErrCodeTelegramBotNotFound                         = "TRD-142"
+	ErrCodeTelegramBotNameEmpty                        = "TRD-143"
+	ErrCodeTelegramBotTokenInvalid                     = "TRD-144"
+	ErrCodeTelegramBotTokenKeyEmpty                    = "TRD-145"
+	ErrCodeTelegramBotTokenEncrypt                     = "TRD-146"
+	ErrCodeTelegramBotDefaultReadOnly                  = "TRD-147"
+	ErrCodeTelegramBotSecondaryInvalid                 = "TRD-148"
+	ErrCodeTelegramBotNotAllowed                       = "TRD-149"
+	ErrCodeTelegramBotStoragePut                       = "TRD-150"
+	ErrCodeTelegramBotStorageGet                       = "TRD-151"
+	ErrCodeTelegramBotStorageDel                       = "TRD-152"
+	ErrCodeSubscriptionFilterExpressionInvalid         = "TRD-153"
+	ErrCodeSubscriptionPreviewHoursInvalid             = "TRD-154"
+	ErrCodeSubscriptionPreviewSamplesInvalid           = "TRD-155"
+	ErrCodeEmailVerificationUserIdEmpty                = "TRD-156"
+	ErrCodeEmailVerificationAddressInvalid             = "TRD-157"
+	ErrCodeEmailVerificationNotConfigured              = "TRD-158"
+	ErrCodeEmailVerificationTooFrequent                = "TRD-159"
+	ErrCodeEmailVerificationNotFound                   = "TRD-160"
+	ErrCodeEmailVerificationExpired                    = "TRD-161"
+	ErrCodeEmailVerificationCodeInvalid                = "TRD-162"
+	ErrCodeEmailVerificationStoragePut                 = "TRD-163"
+	ErrCodeEmailVerificationStorageGet                 = "TRD-164"
+	ErrCodeOutboxStorageClaim                          = "TRD-165"
+	ErrCodeDeliveryPolicyStoragePut                    = "TRD-166"
+	ErrCodeDeliveryPolicyStorageGet                    = "TRD-167"
+	ErrCodeDeliveryPolicyStorageDel                    = "TRD-168"
 )
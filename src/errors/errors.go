@@ -2,6 +2,7 @@ package errors
 
 import (
 	"context"
+	"fmt"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
 	"net/http"
 )
@@ -187,4 +188,328 @@ var (
 	ErrNotAllowed = func(ctx context.Context) error {
 		return er.WithBuilder(ErrCodeNotAllowed, "operation isn't allowed").Business().C(ctx).HttpSt(http.StatusForbidden).Err()
 	}
+	ErrBidInvalid = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeBidInvalid, "bid invalid: assets and rate must be specified").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrBidTypeInvalid = func(ctx context.Context, t string) error {
+		return er.WithBuilder(ErrCodeBidTypeInvalid, "bid type invalid").Business().F(er.FF{"type": t}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrChainSortFieldInvalid = func(ctx context.Context, field string) error {
+		return er.WithBuilder(ErrCodeChainSortFieldInvalid, "sort field invalid").Business().F(er.FF{"field": field}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrChainProfitRangeInvalid = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeChainProfitRangeInvalid, "profit range invalid").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrChainDepthRangeInvalid = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeChainDepthRangeInvalid, "depth range invalid").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrChainCursorInvalid = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeChainCursorInvalid, "cursor invalid").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrChainsPageSizeExceeded = func(ctx context.Context, max int) error {
+		return er.WithBuilder(ErrCodeChainsPageSizeExceeded, "page size exceeded").Business().F(er.FF{"max": max}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrChainArchiveStoragePut = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeChainArchiveStoragePut, "").C(ctx).Err()
+	}
+	ErrChainArchiveStorageGet = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeChainArchiveStorageGet, "").C(ctx).Err()
+	}
+	ErrChainArchiverAlreadyRun = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeChainArchiverAlreadyRun, "chain archiver already run").Business().C(ctx).Err()
+	}
+	ErrChainNotFound = func(ctx context.Context, chainId string) error {
+		return er.WithBuilder(ErrCodeChainNotFound, "chain not found").Business().F(er.FF{"chainId": chainId}).C(ctx).HttpSt(http.StatusNotFound).Err()
+	}
+	ErrChainArchiveStorageTypeInvalid = func(ctx context.Context, t string) error {
+		return er.WithBuilder(ErrCodeChainArchiveStorageTypeInvalid, "chain archive storage type invalid").F(er.FF{"type": t}).C(ctx).Err()
+	}
+	ErrRateHistoryStoragePut = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeRateHistoryStoragePut, "").C(ctx).Err()
+	}
+	ErrRateHistoryStorageGet = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeRateHistoryStorageGet, "").C(ctx).Err()
+	}
+	ErrRateHistoryStorageDel = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeRateHistoryStorageDel, "").C(ctx).Err()
+	}
+	ErrRateHistoryPairInvalid = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeRateHistoryPairInvalid, "pair invalid: source and target assets must be specified").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrRateHistoryPeriodInvalid = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeRateHistoryPeriodInvalid, "period invalid").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrRateHistoryResolutionInvalid = func(ctx context.Context, min int) error {
+		return er.WithBuilder(ErrCodeRateHistoryResolutionInvalid, "resolution invalid").Business().F(er.FF{"minSec": min}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrRateHistoryTooManyCandles = func(ctx context.Context, max int) error {
+		return er.WithBuilder(ErrCodeRateHistoryTooManyCandles, "too many candles requested, increase resolution or reduce period").Business().F(er.FF{"max": max}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrSpreadStoragePut = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeSpreadStoragePut, "").C(ctx).Err()
+	}
+	ErrSpreadStorageGet = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeSpreadStorageGet, "").C(ctx).Err()
+	}
+	ErrSpreadStorageScan = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeSpreadStorageScan, "").C(ctx).Err()
+	}
+	ErrSpreadDetectorAlreadyRun = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeSpreadDetectorAlreadyRun, "already run").Business().C(ctx).Err()
+	}
+	ErrSpreadNotFound = func(ctx context.Context, spreadId string) error {
+		return er.WithBuilder(ErrCodeSpreadNotFound, "spread not found").Business().F(er.FF{"spreadId": spreadId}).C(ctx).HttpSt(http.StatusNotFound).Err()
+	}
+	ErrSpreadsPageSizeExceeded = func(ctx context.Context, max int) error {
+		return er.WithBuilder(ErrCodeSpreadsPageSizeExceeded, "page size exceeded").Business().F(er.FF{"max": max}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrSubscriptionOpportunityInvalid = func(ctx context.Context, opportunity string) error {
+		return er.WithBuilder(ErrCodeSubscriptionOpportunityInvalid, "opportunity type invalid").Business().F(er.FF{"opportunity": opportunity}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrReferenceRatesSourceGet = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeReferenceRatesSourceGet, "").C(ctx).Err()
+	}
+	ErrReferenceRatesSourceStatus = func(ctx context.Context, status int) error {
+		return er.WithBuilder(ErrCodeReferenceRatesSourceStatus, "reference rates source responded with error").F(er.FF{"status": status}).C(ctx).Err()
+	}
+	ErrReferenceRatesBaseMismatch = func(ctx context.Context, base, expected string) error {
+		return er.WithBuilder(ErrCodeReferenceRatesBaseMismatch, "reference rates base currency mismatch").F(er.FF{"base": base, "expected": expected}).C(ctx).Err()
+	}
+	ErrReferenceRatesSourceTypeInvalid = func(ctx context.Context, t string) error {
+		return er.WithBuilder(ErrCodeReferenceRatesSourceTypeInvalid, "reference rates source type invalid").F(er.FF{"type": t}).C(ctx).Err()
+	}
+	ErrReferenceRatesProviderAlreadyRun = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeReferenceRatesProviderAlreadyRun, "already run").Business().C(ctx).Err()
+	}
+	ErrChainBaseAmountInvalid = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeChainBaseAmountInvalid, "min base profit and volume must not be negative").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrBidStorageGetBidsByOwner = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeBidStorageGetBidsByOwner, "").C(ctx).Err()
+	}
+	ErrBidStorageDeleteBid = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeBidStorageDeleteBid, "").C(ctx).Err()
+	}
+	ErrManualBidNotFound = func(ctx context.Context, bidId string) error {
+		return er.WithBuilder(ErrCodeManualBidNotFound, "manual bid not found").Business().F(er.FF{"bidId": bidId}).C(ctx).HttpSt(http.StatusNotFound).Err()
+	}
+	ErrManualBidTtlInvalid = func(ctx context.Context, min, max int) error {
+		return er.WithBuilder(ErrCodeManualBidTtlInvalid, "manual bid ttl is out of bounds").Business().F(er.FF{"min": min, "max": max}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrManualBidsEmpty = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeManualBidsEmpty, "no bids specified").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrManualBidsTooMany = func(ctx context.Context, max int) error {
+		return er.WithBuilder(ErrCodeManualBidsTooMany, "too many bids in bulk").Business().F(er.FF{"max": max}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrManualBidOwnerEmpty = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeManualBidOwnerEmpty, "bid owner must be specified").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrPrivateChainsAlreadyRun = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodePrivateChainsAlreadyRun, "already run").Business().C(ctx).Err()
+	}
+	ErrChainsSearchUserEmpty = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeChainsSearchUserEmpty, "user empty").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrChainsSearchLimitExceeded = func(ctx context.Context, max int) error {
+		return er.WithBuilder(ErrCodeChainsSearchLimitExceeded, "limit exceeded").Business().F(er.FF{"max": max}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrSimulatorScenarioLoad = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeSimulatorScenarioLoad, "").C(ctx).Err()
+	}
+	ErrSimulatorScenarioInvalid = func(ctx context.Context, event, reason string) error {
+		return er.WithBuilder(ErrCodeSimulatorScenarioInvalid, "scenario invalid").Business().F(er.FF{"event": event, "reason": reason}).C(ctx).Err()
+	}
+	ErrSubscriptionNotificationEmailInvalid = func(ctx context.Context, reason string) error {
+		return er.WithBuilder(ErrCodeSubscriptionNotificationEmailInvalid, "email params invalid").Business().F(er.FF{"reason": reason}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrSubscriptionNotificationWebhookInvalid = func(ctx context.Context, reason string) error {
+		return er.WithBuilder(ErrCodeSubscriptionNotificationWebhookInvalid, "webhook params invalid").Business().F(er.FF{"reason": reason}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrWebhookRequestFailed = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeWebhookRequestFailed, "").C(ctx).Err()
+	}
+	ErrWebhookResponseError = func(ctx context.Context, status string) error {
+		return er.WithBuilder(ErrCodeWebhookResponseError, "webhook responded with error").F(er.FF{"status": status}).C(ctx).Err()
+	}
+	ErrOutboxDeliveryInvalid = func(ctx context.Context, deliveryId string) error {
+		return er.WithBuilder(ErrCodeOutboxDeliveryInvalid, "delivery invalid").Business().F(er.FF{"deliveryId": deliveryId}).C(ctx).Err()
+	}
+	ErrOutboxAlreadyRun = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeOutboxAlreadyRun, "already run").Business().C(ctx).Err()
+	}
+	ErrOutboxDeliveryNotFound = func(ctx context.Context, deliveryId string) error {
+		return er.WithBuilder(ErrCodeOutboxDeliveryNotFound, "delivery not found").Business().F(er.FF{"deliveryId": deliveryId}).C(ctx).HttpSt(http.StatusNotFound).Err()
+	}
+	ErrOutboxDeliveryNotDead = func(ctx context.Context, deliveryId, status string) error {
+		return er.WithBuilder(ErrCodeOutboxDeliveryNotDead, "only dead deliveries can be replayed").Business().F(er.FF{"deliveryId": deliveryId, "status": status}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrOutboxReplayEmpty = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeOutboxReplayEmpty, "no deliveries to replay").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrOutboxStatusInvalid = func(ctx context.Context, status string) error {
+		return er.WithBuilder(ErrCodeOutboxStatusInvalid, "delivery status invalid").Business().F(er.FF{"status": status}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrOutboxStoragePut = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeOutboxStoragePut, "").C(ctx).Err()
+	}
+	ErrOutboxStorageGet = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeOutboxStorageGet, "").C(ctx).Err()
+	}
+	ErrOutboxStorageSearch = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeOutboxStorageSearch, "").C(ctx).Err()
+	}
+	ErrOutboxStorageDel = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeOutboxStorageDel, "").C(ctx).Err()
+	}
+	ErrSubscriptionPolicyInvalid = func(ctx context.Context, reason string) error {
+		return er.WithBuilder(ErrCodeSubscriptionPolicyInvalid, "delivery policy invalid").Business().F(er.FF{"reason": reason}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrSubscriptionDigestAlreadyRun = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeSubscriptionDigestAlreadyRun, "already run").Business().C(ctx).Err()
+	}
+	ErrNotificationTemplateInvalid = func(ctx context.Context, reason string) error {
+		return er.WithBuilder(ErrCodeNotificationTemplateInvalid, "notification template invalid").Business().F(er.FF{"reason": reason}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrNotificationTemplateRender = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeNotificationTemplateRender, "notification template rendering failed").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrSubscriptionAlreadyActive = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeSubscriptionAlreadyActive, "subscription already active").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrTelegramLinkStoragePut = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramLinkStoragePut, "").C(ctx).Err()
+	}
+	ErrTelegramLinkStorageGet = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramLinkStorageGet, "").C(ctx).Err()
+	}
+	ErrTelegramLinkStorageDel = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramLinkStorageDel, "").C(ctx).Err()
+	}
+	ErrTelegramBotAlreadyRun = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramBotAlreadyRun, "already run").Business().C(ctx).Err()
+	}
+	ErrTelegramWebhookSecretInvalid = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramWebhookSecretInvalid, "webhook secret invalid").Business().C(ctx).HttpSt(http.StatusUnauthorized).Err()
+	}
+	ErrTelegramLinkNotFound = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramLinkNotFound, "telegram account not linked").Business().C(ctx).HttpSt(http.StatusNotFound).Err()
+	}
+	ErrTelegramLinkCodeInvalid = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramLinkCodeInvalid, "link code invalid or expired").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrTelegramUserIdEmpty = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramUserIdEmpty, "user id empty").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrTelegramChannelInvalid = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramChannelInvalid, "telegram channel invalid").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrTelegramChannelVerificationNotFound = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramChannelVerificationNotFound, "channel verification not requested").Business().C(ctx).HttpSt(http.StatusNotFound).Err()
+	}
+	ErrTelegramChannelVerificationExpired = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramChannelVerificationExpired, "channel verification code expired").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrTelegramChannelNotVerified = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramChannelNotVerified, "channel not verified, post the code to the channel or add the bot as an admin").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrTelegramChannelStoragePut = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramChannelStoragePut, "").C(ctx).Err()
+	}
+	ErrTelegramChannelStorageGet = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramChannelStorageGet, "").C(ctx).Err()
+	}
+	ErrTelegramAlertsAlreadyRun = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramAlertsAlreadyRun, "already run").Business().C(ctx).Err()
+	}
+	ErrTelegramAlertStoragePut = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramAlertStoragePut, "").C(ctx).Err()
+	}
+	ErrTelegramAlertStorageGet = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramAlertStorageGet, "").C(ctx).Err()
+	}
+	ErrTelegramAlertStorageDel = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramAlertStorageDel, "").C(ctx).Err()
+	}
+	ErrTelegramBotNotFound = func(ctx context.Context, botId string) error {
+		return er.WithBuilder(ErrCodeTelegramBotNotFound, "telegram bot not found").Business().F(er.FF{"botId": botId}).C(ctx).HttpSt(http.StatusNotFound).Err()
+	}
+	ErrTelegramBotNameEmpty = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramBotNameEmpty, "bot name empty").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrTelegramBotTokenInvalid = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramBotTokenInvalid, "bot token invalid").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrTelegramBotTokenKeyEmpty = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramBotTokenKeyEmpty, "token key isn't configured, bots can't be registered").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrTelegramBotTokenEncrypt = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramBotTokenEncrypt, "bot token can't be encrypted or decrypted").C(ctx).Err()
+	}
+	ErrTelegramBotDefaultReadOnly = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeTelegramBotDefaultReadOnly, "default bot is configured, it can't be changed").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrTelegramBotSecondaryInvalid = func(ctx context.Context, secondaryId string) error {
+		return er.WithBuilder(ErrCodeTelegramBotSecondaryInvalid, "secondary bot invalid").Business().F(er.FF{"secondaryId": secondaryId}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrTelegramBotNotAllowed = func(ctx context.Context, botId string) error {
+		return er.WithBuilder(ErrCodeTelegramBotNotAllowed, "telegram bot not allowed").Business().F(er.FF{"botId": botId}).C(ctx).HttpSt(http.StatusForbidden).Err()
+	}
+	ErrTelegramBotStoragePut = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramBotStoragePut, "").C(ctx).Err()
+	}
+	ErrTelegramBotStorageGet = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramBotStorageGet, "").C(ctx).Err()
+	}
+	ErrTelegramBotStorageDel = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeTelegramBotStorageDel, "").C(ctx).Err()
+	}
+	ErrSubscriptionFilterExpressionInvalid = func(ctx context.Context, reason string, position int) error {
+		return er.WithBuilder(ErrCodeSubscriptionFilterExpressionInvalid, fmt.Sprintf("filter expression invalid at position %d: %s", position, reason)).Business().F(er.FF{"reason": reason, "position": position}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrSubscriptionPreviewHoursInvalid = func(ctx context.Context, maxHours int) error {
+		return er.WithBuilder(ErrCodeSubscriptionPreviewHoursInvalid, fmt.Sprintf("preview hours must be within 1..%d", maxHours)).Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrSubscriptionPreviewSamplesInvalid = func(ctx context.Context, maxSamples int) error {
+		return er.WithBuilder(ErrCodeSubscriptionPreviewSamplesInvalid, fmt.Sprintf("preview samples must be within 1..%d", maxSamples)).Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrEmailVerificationUserIdEmpty = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeEmailVerificationUserIdEmpty, "user id empty").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrEmailVerificationAddressInvalid = func(ctx context.Context, email string) error {
+		return er.WithBuilder(ErrCodeEmailVerificationAddressInvalid, "email address invalid").Business().F(er.FF{"email": email}).C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrEmailVerificationNotConfigured = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeEmailVerificationNotConfigured, "email isn't configured").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrEmailVerificationTooFrequent = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeEmailVerificationTooFrequent, "verification code has been sent recently, try later").Business().C(ctx).HttpSt(http.StatusTooManyRequests).Err()
+	}
+	ErrEmailVerificationNotFound = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeEmailVerificationNotFound, "email verification not requested").Business().C(ctx).HttpSt(http.StatusNotFound).Err()
+	}
+	ErrEmailVerificationExpired = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeEmailVerificationExpired, "email verification code expired").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrEmailVerificationCodeInvalid = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeEmailVerificationCodeInvalid, "email verification code invalid").Business().C(ctx).HttpSt(http.StatusBadRequest).Err()
+	}
+	ErrEmailVerificationStoragePut = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeEmailVerificationStoragePut, "").C(ctx).Err()
+	}
+	ErrEmailVerificationStorageGet = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeEmailVerificationStorageGet, "").C(ctx).Err()
+	}
+	ErrOutboxStorageClaim = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeOutboxStorageClaim, "").C(ctx).Err()
+	}
+	ErrDeliveryPolicyStoragePut = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeDeliveryPolicyStoragePut, "").C(ctx).Err()
+	}
+	ErrDeliveryPolicyStorageGet = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeDeliveryPolicyStorageGet, "").C(ctx).Err()
+	}
+	ErrDeliveryPolicyStorageDel = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeDeliveryPolicyStorageDel, "").C(ctx).Err()
+	}
 )
@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/grpc/pb"
+	kitGrpc "github.com/mikhailbolshakov/cryptocare/src/kit/grpc"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"io"
+)
+
+const (
+	// uploadBidsBatchSize number of bids stored at once while uploading
+	uploadBidsBatchSize = 100
+)
+
+type bidServerImpl struct {
+	pb.UnimplementedBidServiceServer
+	bidProvider domain.BidProvider
+}
+
+func newBidServer(bidProvider domain.BidProvider) *bidServerImpl {
+	return &bidServerImpl{
+		bidProvider: bidProvider,
+	}
+}
+
+func (c *bidServerImpl) l() log.CLogger {
+	return service.L().Cmp("grpc-bids")
+}
+
+func (c *bidServerImpl) UploadBids(stream pb.BidService_UploadBidsServer) error {
+	ctx := stream.Context()
+	l := c.l().C(ctx).Mth("upload-bids").Trc()
+
+	rs := &pb.UploadBidsResponse{}
+	batch := make([]*domain.Bid, 0, uploadBidsBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		bids, err := c.bidProvider.PutBids(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for _, b := range bids {
+			rs.Ids = append(rs.Ids, b.Id)
+		}
+		rs.Accepted += int32(len(bids))
+		batch = make([]*domain.Bid, 0, uploadBidsBatchSize)
+		return nil
+	}
+
+	for {
+		bid, err := stream.Recv()
+		if err == io.EOF {
+			if err := flush(); err != nil {
+				return err
+			}
+			l.F(log.FF{"accepted": rs.Accepted}).Dbg("uploaded")
+			return stream.SendAndClose(rs)
+		}
+		if err != nil {
+			return kitGrpc.ErrGrpcRecvStream(err, ctx)
+		}
+		batch = append(batch, toBidDomain(bid))
+		if len(batch) >= uploadBidsBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package grpc
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/grpc/pb"
+	kitGrpc "github.com/mikhailbolshakov/cryptocare/src/kit/grpc"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+)
+
+type chainServerImpl struct {
+	pb.UnimplementedChainServiceServer
+	arbitrageService domain.ArbitrageService
+	chainFeed        domain.ChainFeed
+}
+
+func newChainServer(arbitrageService domain.ArbitrageService, chainFeed domain.ChainFeed) *chainServerImpl {
+	return &chainServerImpl{
+		arbitrageService: arbitrageService,
+		chainFeed:        chainFeed,
+	}
+}
+
+func (c *chainServerImpl) l() log.CLogger {
+	return service.L().Cmp("grpc-chains")
+}
+
+func (c *chainServerImpl) GetChains(ctx context.Context, rq *pb.GetChainsRequest) (*pb.GetChainsResponse, error) {
+	c.l().C(ctx).Mth("get-chains").Trc()
+
+	chainsRs, err := c.arbitrageService.GetProfitableChains(ctx, toGetChainsRequestDomain(rq))
+	if err != nil {
+		return nil, err
+	}
+	return toChainsPb(chainsRs.Chains, rq.WithBids), nil
+}
+
+func (c *chainServerImpl) GetChain(ctx context.Context, rq *pb.GetChainRequest) (*pb.ProfitableChain, error) {
+	c.l().C(ctx).Mth("get-chain").Trc()
+
+	chain, err := c.arbitrageService.GetProfitableChain(ctx, rq.Id)
+	if err != nil {
+		return nil, err
+	}
+	if chain == nil {
+		return nil, errors.ErrChainNotFound(ctx, rq.Id)
+	}
+	return toChainPb(chain, true), nil
+}
+
+func (c *chainServerImpl) Feed(rq *pb.ChainFeedRequest, stream pb.ChainService_FeedServer) error {
+	ctx := stream.Context()
+	l := c.l().C(ctx).Mth("feed").Trc("subscribed")
+
+	chains, unsubscribe, err := c.chainFeed.Subscribe(ctx, toFilterDomain(rq.Filter))
+	if err != nil {
+		return err
+	}
+	defer unsubscribe()
+
+	for {
+		select {
+		case chain, ok := <-chains:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(toChainPb(chain, rq.WithBids)); err != nil {
+				return kitGrpc.ErrGrpcSendStream(err, ctx)
+			}
+		case <-ctx.Done():
+			l.Trc("unsubscribed")
+			return nil
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package grpc
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/grpc/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toBidPb(b *domain.Bid) *pb.Bid {
+	if b == nil {
+		return nil
+	}
+	r := &pb.Bid{
+		Id:           b.Id,
+		Type:         b.Type,
+		Src:          b.SrcAsset,
+		Trg:          b.TrgAsset,
+		Rate:         b.Rate,
+		ExchangeCode: b.ExchangeCode,
+		Available:    b.Available,
+		MinLimit:     b.MinLimit,
+		MaxLimit:     b.MaxLimit,
+		Methods:      b.Methods,
+		UserId:       b.UserId,
+		Link:         b.Link,
+	}
+	if !b.ObservedAt.IsZero() {
+		r.ObservedAt = timestamppb.New(b.ObservedAt)
+	}
+	if !b.IngestedAt.IsZero() {
+		r.IngestedAt = timestamppb.New(b.IngestedAt)
+	}
+	return r
+}
+
+func toBidDomain(b *pb.Bid) *domain.Bid {
+	if b == nil {
+		return nil
+	}
+	r := &domain.Bid{
+		Id:           b.Id,
+		Type:         b.Type,
+		SrcAsset:     b.Src,
+		TrgAsset:     b.Trg,
+		Rate:         b.Rate,
+		ExchangeCode: b.ExchangeCode,
+		Available:    b.Available,
+		MinLimit:     b.MinLimit,
+		MaxLimit:     b.MaxLimit,
+		Methods:      b.Methods,
+		UserId:       b.UserId,
+		Link:         b.Link,
+	}
+	// ingestion time is always set by the service
+	if b.ObservedAt != nil {
+		r.ObservedAt = b.ObservedAt.AsTime()
+	}
+	return r
+}
+
+func toChainPb(ch *domain.ProfitableChain, withBids bool) *pb.ProfitableChain {
+	if ch == nil {
+		return nil
+	}
+	r := &pb.ProfitableChain{
+		Id:            ch.Id,
+		Asset:         ch.Asset,
+		ProfitShare:   ch.ProfitShare,
+		Methods:       ch.Methods,
+		BidAssets:     ch.BidAssets,
+		Depth:         int32(ch.Depth),
+		ExchangeCodes: ch.ExchangeCodes,
+		CreatedAt:     timestamppb.New(ch.CreatedAt),
+	}
+	if !ch.ObservedAt.IsZero() {
+		r.ObservedAt = timestamppb.New(ch.ObservedAt)
+	}
+	if withBids {
+		for _, b := range ch.Bids {
+			r.Bids = append(r.Bids, toBidPb(b))
+		}
+	}
+	return r
+}
+
+func toChainsPb(chains []*domain.ProfitableChain, withBids bool) *pb.GetChainsResponse {
+	r := &pb.GetChainsResponse{}
+	for _, ch := range chains {
+		r.Chains = append(r.Chains, toChainPb(ch, withBids))
+	}
+	return r
+}
+
+func toGetChainsRequestDomain(rq *pb.GetChainsRequest) *domain.GetProfitableChainsRequest {
+	r := &domain.GetProfitableChainsRequest{
+		Assets:        rq.Assets,
+		WithBids:      rq.WithBids,
+		Methods:       rq.Methods,
+		ExchangeCodes: rq.ExchangeCodes,
+	}
+	r.Size = int(rq.Size)
+	return r
+}
+
+func toFilterDomain(f *pb.ChainFilter) *domain.SubscriptionChainFilter {
+	if f == nil {
+		return nil
+	}
+	return &domain.SubscriptionChainFilter{
+		Assets:        f.Assets,
+		Methods:       f.Methods,
+		Exchanges:     f.Exchanges,
+		MaxDepth:      int(f.MaxDepth),
+		MinProfit:     f.MinProfit,
+		Opportunities: f.Opportunities,
+		Expression:    f.Expression,
+	}
+}
+
+func toFilterPb(f *domain.SubscriptionChainFilter) *pb.ChainFilter {
+	if f == nil {
+		return nil
+	}
+	return &pb.ChainFilter{
+		Assets:        f.Assets,
+		Methods:       f.Methods,
+		Exchanges:     f.Exchanges,
+		MaxDepth:      int32(f.MaxDepth),
+		MinProfit:     f.MinProfit,
+		Opportunities: f.Opportunities,
+		Expression:    f.Expression,
+	}
+}
+
+func toNotificationsDomain(nn []*pb.SubscriptionNotification) []*domain.SubscriptionNotification {
+	var r []*domain.SubscriptionNotification
+	for _, n := range nn {
+		notify := &domain.SubscriptionNotification{
+			Id:       n.Id,
+			Channel:  n.Channel,
+			IsActive: n.IsActive,
+		}
+		if notify.Channel == "" {
+			notify.Channel = domain.SubscriptionNotificationChannelTelegram
+		}
+		if n.Telegram != nil {
+			notify.Telegram = &domain.SubscriptionTelegramNotificationDetails{
+				Channel: int(n.Telegram.Channel),
+				BotId:   n.Telegram.BotId,
+			}
+		}
+		if n.Email != nil {
+			notify.Email = &domain.SubscriptionEmailNotificationDetails{
+				To: n.Email.To,
+			}
+		}
+		if n.Webhook != nil {
+			notify.Webhook = &domain.SubscriptionWebhookNotificationDetails{
+				Url:    n.Webhook.Url,
+				Secret: n.Webhook.Secret,
+			}
+		}
+		notify.Templates = toTemplatesDomain(n.Templates)
+		r = append(r, notify)
+	}
+	return r
+}
+
+func toNotificationsPb(nn []*domain.SubscriptionNotification) []*pb.SubscriptionNotification {
+	var r []*pb.SubscriptionNotification
+	for _, n := range nn {
+		notify := &pb.SubscriptionNotification{
+			Id:       n.Id,
+			Channel:  n.Channel,
+			IsActive: n.IsActive,
+		}
+		if n.Telegram != nil {
+			notify.Telegram = &pb.TelegramNotification{
+				Channel:  int64(n.Telegram.Channel),
+				Verified: n.Telegram.Verified,
+				BotId:    n.Telegram.BotId,
+			}
+		}
+		if n.Email != nil {
+			notify.Email = &pb.EmailNotification{
+				To:       n.Email.To,
+				Verified: n.Email.Verified,
+			}
+		}
+		if n.Webhook != nil {
+			notify.Webhook = &pb.WebhookNotification{
+				Url:    n.Webhook.Url,
+				Secret: n.Webhook.Secret,
+			}
+		}
+		notify.Templates = toTemplatesPb(n.Templates)
+		r = append(r, notify)
+	}
+	return r
+}
+
+func toTemplateDomain(t *pb.NotificationTemplate) *domain.NotificationTemplate {
+	if t == nil {
+		return nil
+	}
+	return &domain.NotificationTemplate{
+		Subject: t.Subject,
+		Body:    t.Body,
+	}
+}
+
+func toTemplatesDomain(t *pb.NotificationTemplates) *domain.NotificationTemplates {
+	if t == nil {
+		return nil
+	}
+	return &domain.NotificationTemplates{
+		Chain:  toTemplateDomain(t.Chain),
+		Spread: toTemplateDomain(t.Spread),
+		Digest: toTemplateDomain(t.Digest),
+	}
+}
+
+func toTemplatePb(t *domain.NotificationTemplate) *pb.NotificationTemplate {
+	if t == nil {
+		return nil
+	}
+	return &pb.NotificationTemplate{
+		Subject: t.Subject,
+		Body:    t.Body,
+	}
+}
+
+func toTemplatesPb(t *domain.NotificationTemplates) *pb.NotificationTemplates {
+	if t == nil {
+		return nil
+	}
+	return &pb.NotificationTemplates{
+		Chain:  toTemplatePb(t.Chain),
+		Spread: toTemplatePb(t.Spread),
+		Digest: toTemplatePb(t.Digest),
+	}
+}
+
+func toPolicyDomain(p *pb.DeliveryPolicy) *domain.SubscriptionDeliveryPolicy {
+	if p == nil {
+		return nil
+	}
+	r := &domain.SubscriptionDeliveryPolicy{
+		MaxMessages: int(p.MaxMessages),
+		WindowSec:   int(p.WindowSec),
+	}
+	if p.QuietHours != nil {
+		r.QuietHours = &domain.SubscriptionQuietHours{
+			From:     p.QuietHours.From,
+			To:       p.QuietHours.To,
+			Timezone: p.QuietHours.Timezone,
+		}
+	}
+	if p.Digest != nil {
+		r.Digest = &domain.SubscriptionDigest{
+			PeriodMin: int(p.Digest.PeriodMin),
+			MaxItems:  int(p.Digest.MaxItems),
+		}
+	}
+	return r
+}
+
+func toPolicyPb(p *domain.SubscriptionDeliveryPolicy) *pb.DeliveryPolicy {
+	if p == nil {
+		return nil
+	}
+	r := &pb.DeliveryPolicy{
+		MaxMessages: int32(p.MaxMessages),
+		WindowSec:   int32(p.WindowSec),
+	}
+	if p.QuietHours != nil {
+		r.QuietHours = &pb.QuietHours{
+			From:     p.QuietHours.From,
+			To:       p.QuietHours.To,
+			Timezone: p.QuietHours.Timezone,
+		}
+	}
+	if p.Digest != nil {
+		r.Digest = &pb.Digest{
+			PeriodMin: int32(p.Digest.PeriodMin),
+			MaxItems:  int32(p.Digest.MaxItems),
+		}
+	}
+	return r
+}
+
+func toSubscriptionPb(s *domain.Subscription) *pb.Subscription {
+	if s == nil {
+		return nil
+	}
+	return &pb.Subscription{
+		Id:            s.Id,
+		UserId:        s.UserId,
+		IsActive:      s.IsActive,
+		Filter:        toFilterPb(s.Filter),
+		Notifications: toNotificationsPb(s.Notifications),
+		Policy:        toPolicyPb(s.Policy),
+	}
+}
+
+func toSubscriptionsPb(ss []*domain.Subscription) *pb.Subscriptions {
+	r := &pb.Subscriptions{}
+	for _, s := range ss {
+		r.Subscriptions = append(r.Subscriptions, toSubscriptionPb(s))
+	}
+	return r
+}
@@ -0,0 +1,576 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.5
+// source: cryptocare.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ChainServiceClient is the client API for ChainService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ChainServiceClient interface {
+	// GetChains retrieves stored chains by criteria
+	GetChains(ctx context.Context, in *GetChainsRequest, opts ...grpc.CallOption) (*GetChainsResponse, error)
+	// GetChain retrieves chain by id
+	GetChain(ctx context.Context, in *GetChainRequest, opts ...grpc.CallOption) (*ProfitableChain, error)
+	// Feed streams newly found chains matching the filter
+	Feed(ctx context.Context, in *ChainFeedRequest, opts ...grpc.CallOption) (ChainService_FeedClient, error)
+}
+
+type chainServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChainServiceClient(cc grpc.ClientConnInterface) ChainServiceClient {
+	return &chainServiceClient{cc}
+}
+
+func (c *chainServiceClient) GetChains(ctx context.Context, in *GetChainsRequest, opts ...grpc.CallOption) (*GetChainsResponse, error) {
+	out := new(GetChainsResponse)
+	err := c.cc.Invoke(ctx, "/cryptocare.ChainService/GetChains", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainServiceClient) GetChain(ctx context.Context, in *GetChainRequest, opts ...grpc.CallOption) (*ProfitableChain, error) {
+	out := new(ProfitableChain)
+	err := c.cc.Invoke(ctx, "/cryptocare.ChainService/GetChain", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainServiceClient) Feed(ctx context.Context, in *ChainFeedRequest, opts ...grpc.CallOption) (ChainService_FeedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ChainService_ServiceDesc.Streams[0], "/cryptocare.ChainService/Feed", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chainServiceFeedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ChainService_FeedClient interface {
+	Recv() (*ProfitableChain, error)
+	grpc.ClientStream
+}
+
+type chainServiceFeedClient struct {
+	grpc.ClientStream
+}
+
+func (x *chainServiceFeedClient) Recv() (*ProfitableChain, error) {
+	m := new(ProfitableChain)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ChainServiceServer is the server API for ChainService service.
+// All implementations must embed UnimplementedChainServiceServer
+// for forward compatibility
+type ChainServiceServer interface {
+	// GetChains retrieves stored chains by criteria
+	GetChains(context.Context, *GetChainsRequest) (*GetChainsResponse, error)
+	// GetChain retrieves chain by id
+	GetChain(context.Context, *GetChainRequest) (*ProfitableChain, error)
+	// Feed streams newly found chains matching the filter
+	Feed(*ChainFeedRequest, ChainService_FeedServer) error
+	mustEmbedUnimplementedChainServiceServer()
+}
+
+// UnimplementedChainServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedChainServiceServer struct {
+}
+
+func (UnimplementedChainServiceServer) GetChains(context.Context, *GetChainsRequest) (*GetChainsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChains not implemented")
+}
+func (UnimplementedChainServiceServer) GetChain(context.Context, *GetChainRequest) (*ProfitableChain, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChain not implemented")
+}
+func (UnimplementedChainServiceServer) Feed(*ChainFeedRequest, ChainService_FeedServer) error {
+	return status.Errorf(codes.Unimplemented, "method Feed not implemented")
+}
+func (UnimplementedChainServiceServer) mustEmbedUnimplementedChainServiceServer() {}
+
+// UnsafeChainServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChainServiceServer will
+// result in compilation errors.
+type UnsafeChainServiceServer interface {
+	mustEmbedUnimplementedChainServiceServer()
+}
+
+func RegisterChainServiceServer(s grpc.ServiceRegistrar, srv ChainServiceServer) {
+	s.RegisterService(&ChainService_ServiceDesc, srv)
+}
+
+func _ChainService_GetChains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainServiceServer).GetChains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cryptocare.ChainService/GetChains",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainServiceServer).GetChains(ctx, req.(*GetChainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChainService_GetChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainServiceServer).GetChain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cryptocare.ChainService/GetChain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainServiceServer).GetChain(ctx, req.(*GetChainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChainService_Feed_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ChainFeedRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChainServiceServer).Feed(m, &chainServiceFeedServer{stream})
+}
+
+type ChainService_FeedServer interface {
+	Send(*ProfitableChain) error
+	grpc.ServerStream
+}
+
+type chainServiceFeedServer struct {
+	grpc.ServerStream
+}
+
+func (x *chainServiceFeedServer) Send(m *ProfitableChain) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ChainService_ServiceDesc is the grpc.ServiceDesc for ChainService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChainService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cryptocare.ChainService",
+	HandlerType: (*ChainServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetChains",
+			Handler:    _ChainService_GetChains_Handler,
+		},
+		{
+			MethodName: "GetChain",
+			Handler:    _ChainService_GetChain_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Feed",
+			Handler:       _ChainService_Feed_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "cryptocare.proto",
+}
+
+// SubscriptionServiceClient is the client API for SubscriptionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SubscriptionServiceClient interface {
+	// Create creates a new subscription
+	Create(ctx context.Context, in *CreateSubscriptionRequest, opts ...grpc.CallOption) (*Subscription, error)
+	// Update updates a subscription
+	Update(ctx context.Context, in *UpdateSubscriptionRequest, opts ...grpc.CallOption) (*Subscription, error)
+	// Get retrieves subscription by id
+	Get(ctx context.Context, in *SubscriptionIdRequest, opts ...grpc.CallOption) (*Subscription, error)
+	// Delete deletes subscription
+	Delete(ctx context.Context, in *SubscriptionIdRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Search searches user's subscriptions
+	Search(ctx context.Context, in *SearchSubscriptionsRequest, opts ...grpc.CallOption) (*Subscriptions, error)
+}
+
+type subscriptionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSubscriptionServiceClient(cc grpc.ClientConnInterface) SubscriptionServiceClient {
+	return &subscriptionServiceClient{cc}
+}
+
+func (c *subscriptionServiceClient) Create(ctx context.Context, in *CreateSubscriptionRequest, opts ...grpc.CallOption) (*Subscription, error) {
+	out := new(Subscription)
+	err := c.cc.Invoke(ctx, "/cryptocare.SubscriptionService/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *subscriptionServiceClient) Update(ctx context.Context, in *UpdateSubscriptionRequest, opts ...grpc.CallOption) (*Subscription, error) {
+	out := new(Subscription)
+	err := c.cc.Invoke(ctx, "/cryptocare.SubscriptionService/Update", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *subscriptionServiceClient) Get(ctx context.Context, in *SubscriptionIdRequest, opts ...grpc.CallOption) (*Subscription, error) {
+	out := new(Subscription)
+	err := c.cc.Invoke(ctx, "/cryptocare.SubscriptionService/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *subscriptionServiceClient) Delete(ctx context.Context, in *SubscriptionIdRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/cryptocare.SubscriptionService/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *subscriptionServiceClient) Search(ctx context.Context, in *SearchSubscriptionsRequest, opts ...grpc.CallOption) (*Subscriptions, error) {
+	out := new(Subscriptions)
+	err := c.cc.Invoke(ctx, "/cryptocare.SubscriptionService/Search", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SubscriptionServiceServer is the server API for SubscriptionService service.
+// All implementations must embed UnimplementedSubscriptionServiceServer
+// for forward compatibility
+type SubscriptionServiceServer interface {
+	// Create creates a new subscription
+	Create(context.Context, *CreateSubscriptionRequest) (*Subscription, error)
+	// Update updates a subscription
+	Update(context.Context, *UpdateSubscriptionRequest) (*Subscription, error)
+	// Get retrieves subscription by id
+	Get(context.Context, *SubscriptionIdRequest) (*Subscription, error)
+	// Delete deletes subscription
+	Delete(context.Context, *SubscriptionIdRequest) (*emptypb.Empty, error)
+	// Search searches user's subscriptions
+	Search(context.Context, *SearchSubscriptionsRequest) (*Subscriptions, error)
+	mustEmbedUnimplementedSubscriptionServiceServer()
+}
+
+// UnimplementedSubscriptionServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSubscriptionServiceServer struct {
+}
+
+func (UnimplementedSubscriptionServiceServer) Create(context.Context, *CreateSubscriptionRequest) (*Subscription, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedSubscriptionServiceServer) Update(context.Context, *UpdateSubscriptionRequest) (*Subscription, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedSubscriptionServiceServer) Get(context.Context, *SubscriptionIdRequest) (*Subscription, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedSubscriptionServiceServer) Delete(context.Context, *SubscriptionIdRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedSubscriptionServiceServer) Search(context.Context, *SearchSubscriptionsRequest) (*Subscriptions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedSubscriptionServiceServer) mustEmbedUnimplementedSubscriptionServiceServer() {}
+
+// UnsafeSubscriptionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SubscriptionServiceServer will
+// result in compilation errors.
+type UnsafeSubscriptionServiceServer interface {
+	mustEmbedUnimplementedSubscriptionServiceServer()
+}
+
+func RegisterSubscriptionServiceServer(s grpc.ServiceRegistrar, srv SubscriptionServiceServer) {
+	s.RegisterService(&SubscriptionService_ServiceDesc, srv)
+}
+
+func _SubscriptionService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubscriptionServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cryptocare.SubscriptionService/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubscriptionServiceServer).Create(ctx, req.(*CreateSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubscriptionService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubscriptionServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cryptocare.SubscriptionService/Update",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubscriptionServiceServer).Update(ctx, req.(*UpdateSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubscriptionService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscriptionIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubscriptionServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cryptocare.SubscriptionService/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubscriptionServiceServer).Get(ctx, req.(*SubscriptionIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubscriptionService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscriptionIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubscriptionServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cryptocare.SubscriptionService/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubscriptionServiceServer).Delete(ctx, req.(*SubscriptionIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubscriptionService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchSubscriptionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubscriptionServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cryptocare.SubscriptionService/Search",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubscriptionServiceServer).Search(ctx, req.(*SearchSubscriptionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SubscriptionService_ServiceDesc is the grpc.ServiceDesc for SubscriptionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SubscriptionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cryptocare.SubscriptionService",
+	HandlerType: (*SubscriptionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _SubscriptionService_Create_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _SubscriptionService_Update_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _SubscriptionService_Get_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _SubscriptionService_Delete_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _SubscriptionService_Search_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cryptocare.proto",
+}
+
+// BidServiceClient is the client API for BidService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BidServiceClient interface {
+	// UploadBids uploads bids of exchanges as a stream, bids are stored in batches
+	// ids of bids are scoped with the exchange, so returned ids differ from the uploaded ones; manual bids aren't accepted
+	UploadBids(ctx context.Context, opts ...grpc.CallOption) (BidService_UploadBidsClient, error)
+}
+
+type bidServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBidServiceClient(cc grpc.ClientConnInterface) BidServiceClient {
+	return &bidServiceClient{cc}
+}
+
+func (c *bidServiceClient) UploadBids(ctx context.Context, opts ...grpc.CallOption) (BidService_UploadBidsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &BidService_ServiceDesc.Streams[0], "/cryptocare.BidService/UploadBids", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &bidServiceUploadBidsClient{stream}
+	return x, nil
+}
+
+type BidService_UploadBidsClient interface {
+	Send(*Bid) error
+	CloseAndRecv() (*UploadBidsResponse, error)
+	grpc.ClientStream
+}
+
+type bidServiceUploadBidsClient struct {
+	grpc.ClientStream
+}
+
+func (x *bidServiceUploadBidsClient) Send(m *Bid) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *bidServiceUploadBidsClient) CloseAndRecv() (*UploadBidsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(UploadBidsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// BidServiceServer is the server API for BidService service.
+// All implementations must embed UnimplementedBidServiceServer
+// for forward compatibility
+type BidServiceServer interface {
+	// UploadBids uploads bids of exchanges as a stream, bids are stored in batches
+	// ids of bids are scoped with the exchange, so returned ids differ from the uploaded ones; manual bids aren't accepted
+	UploadBids(BidService_UploadBidsServer) error
+	mustEmbedUnimplementedBidServiceServer()
+}
+
+// UnimplementedBidServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedBidServiceServer struct {
+}
+
+func (UnimplementedBidServiceServer) UploadBids(BidService_UploadBidsServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadBids not implemented")
+}
+func (UnimplementedBidServiceServer) mustEmbedUnimplementedBidServiceServer() {}
+
+// UnsafeBidServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BidServiceServer will
+// result in compilation errors.
+type UnsafeBidServiceServer interface {
+	mustEmbedUnimplementedBidServiceServer()
+}
+
+func RegisterBidServiceServer(s grpc.ServiceRegistrar, srv BidServiceServer) {
+	s.RegisterService(&BidService_ServiceDesc, srv)
+}
+
+func _BidService_UploadBids_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BidServiceServer).UploadBids(&bidServiceUploadBidsServer{stream})
+}
+
+type BidService_UploadBidsServer interface {
+	SendAndClose(*UploadBidsResponse) error
+	Recv() (*Bid, error)
+	grpc.ServerStream
+}
+
+type bidServiceUploadBidsServer struct {
+	grpc.ServerStream
+}
+
+func (x *bidServiceUploadBidsServer) SendAndClose(m *UploadBidsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *bidServiceUploadBidsServer) Recv() (*Bid, error) {
+	m := new(Bid)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// BidService_ServiceDesc is the grpc.ServiceDesc for BidService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BidService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cryptocare.BidService",
+	HandlerType: (*BidServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadBids",
+			Handler:       _BidService_UploadBids_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "cryptocare.proto",
+}
@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/grpc/pb"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/auth/impl"
+	kitGrpc "github.com/mikhailbolshakov/cryptocare/src/kit/grpc"
+)
+
+type Router struct {
+	server              *kitGrpc.Server
+	arbitrageService    domain.ArbitrageService
+	subscriptionService domain.SubscriptionService
+	bidProvider         domain.BidProvider
+	chainFeed           domain.ChainFeed
+}
+
+func NewRouter(server *kitGrpc.Server, arbitrageService domain.ArbitrageService, subscriptionService domain.SubscriptionService,
+	bidProvider domain.BidProvider, chainFeed domain.ChainFeed) kitGrpc.ServiceSetter {
+	return &Router{
+		server:              server,
+		arbitrageService:    arbitrageService,
+		subscriptionService: subscriptionService,
+		bidProvider:         bidProvider,
+		chainFeed:           chainFeed,
+	}
+}
+
+func (r *Router) Set() error {
+
+	pb.RegisterChainServiceServer(r.server.Srv, newChainServer(r.arbitrageService, r.chainFeed))
+	pb.RegisterSubscriptionServiceServer(r.server.Srv, newSubscriptionServer(r.subscriptionService))
+	pb.RegisterBidServiceServer(r.server.Srv, newBidServer(r.bidProvider))
+
+	return r.server.Register(
+		// chains
+		kitGrpc.M("/cryptocare.ChainService/GetChains").Authorize(impl.Resource(domain.AuthResArbitrageChainsAll, "r")),
+		kitGrpc.M("/cryptocare.ChainService/GetChain").Authorize(impl.Resource(domain.AuthResArbitrageChainsAll, "r")),
+		kitGrpc.M("/cryptocare.ChainService/Feed").Authorize(impl.Resource(domain.AuthResArbitrageChainsAll, "r")),
+
+		// subscriptions
+		kitGrpc.M("/cryptocare.SubscriptionService/Create"),
+		kitGrpc.M("/cryptocare.SubscriptionService/Update"),
+		kitGrpc.M("/cryptocare.SubscriptionService/Get"),
+		kitGrpc.M("/cryptocare.SubscriptionService/Delete"),
+		kitGrpc.M("/cryptocare.SubscriptionService/Search"),
+
+		// bids
+		kitGrpc.M("/cryptocare.BidService/UploadBids").Authorize(impl.Resource(domain.AuthResArbitrageBidsAll, "w")),
+	)
+}
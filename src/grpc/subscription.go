@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/grpc/pb"
+	kitContext "github.com/mikhailbolshakov/cryptocare/src/kit/context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type subscriptionServerImpl struct {
+	pb.UnimplementedSubscriptionServiceServer
+	subscriptionService domain.SubscriptionService
+}
+
+func newSubscriptionServer(subscriptionService domain.SubscriptionService) *subscriptionServerImpl {
+	return &subscriptionServerImpl{
+		subscriptionService: subscriptionService,
+	}
+}
+
+func (c *subscriptionServerImpl) l() log.CLogger {
+	return service.L().Cmp("grpc-subscriptions")
+}
+
+// checkUser checks the current user is the given user
+func (c *subscriptionServerImpl) checkUser(ctx context.Context, userId string) error {
+	if appCtx, ok := kitContext.Request(ctx); ok && appCtx.GetUserId() != userId {
+		return errors.ErrNotAllowed(ctx)
+	}
+	return nil
+}
+
+// getOwned retrieves a subscription and checks it's owned by the current user
+func (c *subscriptionServerImpl) getOwned(ctx context.Context, rq *pb.SubscriptionIdRequest) (*domain.Subscription, error) {
+	if err := c.checkUser(ctx, rq.UserId); err != nil {
+		return nil, err
+	}
+	subscription, err := c.subscriptionService.Get(ctx, rq.Id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, errors.ErrSubscriptionNotFound(ctx)
+	}
+	if err := c.checkUser(ctx, subscription.UserId); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (c *subscriptionServerImpl) Create(ctx context.Context, rq *pb.CreateSubscriptionRequest) (*pb.Subscription, error) {
+	c.l().C(ctx).Mth("create").Trc()
+
+	if err := c.checkUser(ctx, rq.UserId); err != nil {
+		return nil, err
+	}
+
+	subscription, err := c.subscriptionService.Create(ctx, &domain.Subscription{
+		UserId:        rq.UserId,
+		Filter:        toFilterDomain(rq.Filter),
+		Notifications: toNotificationsDomain(rq.Notifications),
+		Policy:        toPolicyDomain(rq.Policy),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSubscriptionPb(subscription), nil
+}
+
+func (c *subscriptionServerImpl) Update(ctx context.Context, rq *pb.UpdateSubscriptionRequest) (*pb.Subscription, error) {
+	c.l().C(ctx).Mth("update").F(log.FF{"subscriptionId": rq.Id}).Trc()
+
+	if _, err := c.getOwned(ctx, &pb.SubscriptionIdRequest{UserId: rq.UserId, Id: rq.Id}); err != nil {
+		return nil, err
+	}
+
+	subscription, err := c.subscriptionService.Update(ctx, &domain.Subscription{
+		Id:            rq.Id,
+		UserId:        rq.UserId,
+		Filter:        toFilterDomain(rq.Filter),
+		Notifications: toNotificationsDomain(rq.Notifications),
+		Policy:        toPolicyDomain(rq.Policy),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSubscriptionPb(subscription), nil
+}
+
+func (c *subscriptionServerImpl) Get(ctx context.Context, rq *pb.SubscriptionIdRequest) (*pb.Subscription, error) {
+	c.l().C(ctx).Mth("get").F(log.FF{"subscriptionId": rq.Id}).Trc()
+
+	subscription, err := c.getOwned(ctx, rq)
+	if err != nil {
+		return nil, err
+	}
+	return toSubscriptionPb(subscription), nil
+}
+
+func (c *subscriptionServerImpl) Delete(ctx context.Context, rq *pb.SubscriptionIdRequest) (*emptypb.Empty, error) {
+	c.l().C(ctx).Mth("delete").F(log.FF{"subscriptionId": rq.Id}).Trc()
+
+	if _, err := c.getOwned(ctx, rq); err != nil {
+		return nil, err
+	}
+	if err := c.subscriptionService.Delete(ctx, rq.Id); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (c *subscriptionServerImpl) Search(ctx context.Context, rq *pb.SearchSubscriptionsRequest) (*pb.Subscriptions, error) {
+	c.l().C(ctx).Mth("search").Trc()
+
+	if err := c.checkUser(ctx, rq.UserId); err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := c.subscriptionService.Search(ctx, &domain.SearchSubscriptionsRequest{
+		UserId:       rq.UserId,
+		WithInActive: rq.WithInactive,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSubscriptionsPb(subscriptions), nil
+}
@@ -5,11 +5,15 @@ import (
 	"github.com/mikhailbolshakov/cryptocare/src/errors"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/auth"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
 	kitHttp "github.com/mikhailbolshakov/cryptocare/src/kit/http"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
 	"github.com/mikhailbolshakov/cryptocare/src/service"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Controller interface {
@@ -26,8 +30,16 @@ type Controller interface {
 
 	// GetProfitableChains retrieves stored profit chains
 	GetProfitableChains(http.ResponseWriter, *http.Request)
+	// SearchChains finds chains on demand combining the public market with private bids of the caller
+	SearchChains(http.ResponseWriter, *http.Request)
 	// GetProfitableChainDetails retrieves details of the chain
 	GetProfitableChainDetails(http.ResponseWriter, *http.Request)
+	// GetArchivedChain retrieves archived chain
+	GetArchivedChain(http.ResponseWriter, *http.Request)
+	// GetSpreads retrieves detected spreads
+	GetSpreads(http.ResponseWriter, *http.Request)
+	// GetSpread retrieves spread by id
+	GetSpread(http.ResponseWriter, *http.Request)
 
 	// subscriptions
 	CreateSubscription(http.ResponseWriter, *http.Request)
@@ -35,9 +47,71 @@ type Controller interface {
 	GetSubscription(http.ResponseWriter, *http.Request)
 	DeleteSubscription(http.ResponseWriter, *http.Request)
 	GetUserSubscriptions(http.ResponseWriter, *http.Request)
+	// PreviewSubscription backtests a subscription filter against the chain history
+	PreviewSubscription(http.ResponseWriter, *http.Request)
 
 	// bids
-	PutBid(http.ResponseWriter, *http.Request)
+	// CreateManualBid creates a manual bid owned by the caller
+	CreateManualBid(http.ResponseWriter, *http.Request)
+	// CreateManualBids creates manual bids in bulk
+	CreateManualBids(http.ResponseWriter, *http.Request)
+	// UpdateManualBid updates a manual bid
+	UpdateManualBid(http.ResponseWriter, *http.Request)
+	// DeleteManualBid withdraws a manual bid
+	DeleteManualBid(http.ResponseWriter, *http.Request)
+	// GetManualBid retrieves a manual bid
+	GetManualBid(http.ResponseWriter, *http.Request)
+	// GetManualBids retrieves manual bids of the owner
+	GetManualBids(http.ResponseWriter, *http.Request)
+	// GetBidsStaleness retrieves bids freshness by exchanges
+	GetBidsStaleness(http.ResponseWriter, *http.Request)
+
+	// market
+	GetRateHistory(http.ResponseWriter, *http.Request)
+	GetMarketOverview(http.ResponseWriter, *http.Request)
+	GetReferenceRates(http.ResponseWriter, *http.Request)
+
+	// notifications
+	// GetOutboxDeliveries retrieves notification deliveries
+	GetOutboxDeliveries(http.ResponseWriter, *http.Request)
+	// ReplayOutboxDeliveries moves dead deliveries back to the queue
+	ReplayOutboxDeliveries(http.ResponseWriter, *http.Request)
+	// PreviewNotificationTemplate renders a notification template against a sample opportunity
+	PreviewNotificationTemplate(http.ResponseWriter, *http.Request)
+	// CreateTelegramBot registers a telegram bot notifications are sent from
+	CreateTelegramBot(http.ResponseWriter, *http.Request)
+	// UpdateTelegramBot updates a telegram bot
+	UpdateTelegramBot(http.ResponseWriter, *http.Request)
+	// DeleteTelegramBot deletes a telegram bot
+	DeleteTelegramBot(http.ResponseWriter, *http.Request)
+	// GetTelegramBot retrieves a telegram bot
+	GetTelegramBot(http.ResponseWriter, *http.Request)
+	// GetTelegramBots retrieves all telegram bots
+	GetTelegramBots(http.ResponseWriter, *http.Request)
+
+	// telegram
+	// CreateTelegramLinkCode issues a one-time code linking a telegram account to the user
+	CreateTelegramLinkCode(http.ResponseWriter, *http.Request)
+	// GetTelegramLink retrieves linked telegram account of the user
+	GetTelegramLink(http.ResponseWriter, *http.Request)
+	// DeleteTelegramLink unlinks telegram account of the user
+	DeleteTelegramLink(http.ResponseWriter, *http.Request)
+	// TelegramWebhook receives bot updates from Bot API
+	TelegramWebhook(http.ResponseWriter, *http.Request)
+	// RequestTelegramChannelVerification issues a one-time code verifying the telegram channel
+	RequestTelegramChannelVerification(http.ResponseWriter, *http.Request)
+	// ConfirmTelegramChannelVerification confirms the telegram channel through Bot API
+	ConfirmTelegramChannelVerification(http.ResponseWriter, *http.Request)
+	// GetTelegramChannels retrieves telegram channel verifications of the user
+	GetTelegramChannels(http.ResponseWriter, *http.Request)
+
+	// email
+	// RequestEmailVerification sends a code confirming the recipient address
+	RequestEmailVerification(http.ResponseWriter, *http.Request)
+	// ConfirmEmailVerification confirms the recipient address with the code
+	ConfirmEmailVerification(http.ResponseWriter, *http.Request)
+	// GetEmailAddresses retrieves email verifications of the user
+	GetEmailAddresses(http.ResponseWriter, *http.Request)
 }
 
 type controllerIml struct {
@@ -47,10 +121,22 @@ type controllerIml struct {
 	sessionService      auth.SessionsService
 	subscriptionService domain.SubscriptionService
 	bidProvider         domain.BidProvider
+	marketService       domain.MarketService
+	spreadDetector      domain.SpreadDetector
+	manualBidService    domain.ManualBidService
+	privateChainService domain.PrivateChainService
+	notificationOutbox  domain.NotificationOutbox
+	telegramBot         domain.TelegramBot
+	telegramChannels    domain.TelegramChannelVerifier
+	telegramBots        domain.TelegramBotRegistry
+	emailVerifier       domain.EmailVerifier
 }
 
 func NewController(arbitrageService domain.ArbitrageService, sessionService auth.SessionsService,
-	userService domain.UserService, subscriptionService domain.SubscriptionService, bidProvider domain.BidProvider) Controller {
+	userService domain.UserService, subscriptionService domain.SubscriptionService, bidProvider domain.BidProvider,
+	marketService domain.MarketService, spreadDetector domain.SpreadDetector, manualBidService domain.ManualBidService,
+	privateChainService domain.PrivateChainService, notificationOutbox domain.NotificationOutbox, telegramBot domain.TelegramBot,
+	telegramChannels domain.TelegramChannelVerifier, telegramBots domain.TelegramBotRegistry, emailVerifier domain.EmailVerifier) Controller {
 	return &controllerIml{
 		BaseController: kitHttp.BaseController{
 			Logger: service.LF(),
@@ -60,6 +146,15 @@ func NewController(arbitrageService domain.ArbitrageService, sessionService auth
 		userService:         userService,
 		subscriptionService: subscriptionService,
 		bidProvider:         bidProvider,
+		marketService:       marketService,
+		spreadDetector:      spreadDetector,
+		manualBidService:    manualBidService,
+		privateChainService: privateChainService,
+		notificationOutbox:  notificationOutbox,
+		telegramBot:         telegramBot,
+		telegramChannels:    telegramChannels,
+		telegramBots:        telegramBots,
+		emailVerifier:       emailVerifier,
 	}
 }
 
@@ -78,12 +173,27 @@ func (c *controllerIml) Ready(w http.ResponseWriter, r *http.Request) {
 
 // GetProfitableChains godoc
 // @Summary retrieves profitable deal chains by criteria
+// @Description chains are sorted by creation time (the latest first) unless sortBy is specified
+// @Description to page through the result pass nextCursor from the previous response as cursor
 // @Accept json
 // @Produce json
 // @Router /arbitrage/chains [get]
 // @Param assets query string false "comma separated list of assets"
+// @Param methods query string false "comma separated list of methods"
+// @Param exchanges query string false "comma separated list of exchange codes"
+// @Param bidTypes query string false "comma separated list of bid types"
+// @Param minProfit query number false "min profit in percents"
+// @Param maxProfit query number false "max profit in percents"
+// @Param minDepth query int false "min chain depth"
+// @Param maxDepth query int false "max chain depth"
+// @Param minBaseProfit query number false "min absolute profit in the base currency"
+// @Param minBaseVolume query number false "min volume in the base currency"
+// @Param createdAfter query string false "chains created after the time (RFC3339)"
 // @Param withBids query bool false "if chains are retrieved with bid info"
+// @Param sortBy query string false "comma separated sort fields (profit, createdAt, score, baseProfit, baseVolume) with direction, e.g. 'profit desc,createdAt desc'"
+// @Param cursor query string false "cursor returned with the previous page"
 // @Param size query int false "page size"
+// @Param index query int false "page index (ignored if cursor is specified)"
 // @Success 200 {object} ProfitableChains
 // @Failure 500 {object} http.Error
 // @tags arbitrage
@@ -93,22 +203,82 @@ func (c *controllerIml) GetProfitableChains(w http.ResponseWriter, r *http.Reque
 
 	rq := &domain.GetProfitableChainsRequest{}
 
-	assetsStr, err := c.FormVal(r, ctx, "assets", true)
+	var err error
+	if rq.Assets, err = c.FormValStrings(r, ctx, "assets", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if rq.Methods, err = c.FormValStrings(r, ctx, "methods", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if rq.ExchangeCodes, err = c.FormValStrings(r, ctx, "exchanges", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if rq.BidTypes, err = c.FormValStrings(r, ctx, "bidTypes", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	minProfit, err := c.FormValFloat(r, ctx, "minProfit", true)
 	if err != nil {
 		c.RespondError(w, err)
 		return
 	}
-	if assetsStr != "" {
-		rq.Assets = strings.Split(assetsStr, ",")
+	if minProfit != nil {
+		rq.MinProfit = *minProfit
 	}
 
-	size, err := c.FormValInt(r, ctx, "size", true)
+	maxProfit, err := c.FormValFloat(r, ctx, "maxProfit", true)
 	if err != nil {
 		c.RespondError(w, err)
 		return
 	}
-	if size != nil {
-		rq.PagingRequest.Size = *size
+	if maxProfit != nil {
+		rq.MaxProfit = *maxProfit
+	}
+
+	minDepth, err := c.FormValInt(r, ctx, "minDepth", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if minDepth != nil {
+		rq.MinDepth = *minDepth
+	}
+
+	maxDepth, err := c.FormValInt(r, ctx, "maxDepth", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if maxDepth != nil {
+		rq.MaxDepth = *maxDepth
+	}
+
+	minBaseProfit, err := c.FormValFloat(r, ctx, "minBaseProfit", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if minBaseProfit != nil {
+		rq.MinBaseProfit = *minBaseProfit
+	}
+
+	minBaseVolume, err := c.FormValFloat(r, ctx, "minBaseVolume", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if minBaseVolume != nil {
+		rq.MinBaseVolume = *minBaseVolume
+	}
+
+	rq.CreatedAfter, err = c.FormValTime(r, ctx, "createdAfter", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
 	}
 
 	withBids, err := c.FormValBool(r, ctx, "withBids", true)
@@ -120,21 +290,102 @@ func (c *controllerIml) GetProfitableChains(w http.ResponseWriter, r *http.Reque
 		rq.WithBids = *withBids
 	}
 
+	sortBy, err := c.FormSort(r, ctx, "sortBy", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	rq.SortBy = c.toSortRequestDomain(sortBy)
+
+	if rq.Cursor, err = c.FormVal(r, ctx, "cursor", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	size, index, err := c.FormPaging(r, ctx, nil)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if size != nil {
+		rq.PagingRequest.Size = *size
+	}
+	if index != nil {
+		rq.PagingRequest.Index = *index
+	}
+
 	chainsRs, err := c.arbitrageService.GetProfitableChains(ctx, rq)
 	if err != nil {
 		c.RespondError(w, err)
 		return
 	}
-	c.RespondOK(w, c.toProfitableChainsApi(chainsRs.Chains))
+	c.RespondOK(w, c.toProfitableChainsPageApi(chainsRs))
+}
+
+// SearchChains godoc
+// @Summary finds chains on demand combining the public market with private bids of the caller
+// @Description chains containing private bids are visible to the caller only, the most profitable go first
+// @Accept json
+// @Produce json
+// @Router /arbitrage/chains/search [get]
+// @Param assets query string false "comma separated list of assets chains start and end with, assets of the caller's private bids by default"
+// @Param onlyPrivate query bool false "if true, only chains containing private bids are retrieved"
+// @Param limit query int false "max number of chains"
+// @Success 200 {object} ProfitableChains
+// @Failure 400 {object} http.Error
+// @Failure 500 {object} http.Error
+// @tags arbitrage
+func (c *controllerIml) SearchChains(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("search-chains").Trc()
+
+	userId, err := c.callerId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	rq := &domain.SearchChainsRequest{UserId: userId}
+	if rq.Assets, err = c.FormValStrings(r, ctx, "assets", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	onlyPrivate, err := c.FormValBool(r, ctx, "onlyPrivate", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if onlyPrivate != nil {
+		rq.OnlyPrivate = *onlyPrivate
+	}
+
+	limit, err := c.FormValInt(r, ctx, "limit", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if limit != nil {
+		rq.Limit = *limit
+	}
+
+	chains, err := c.privateChainService.SearchChains(ctx, rq)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toProfitableChainsApi(chains))
 }
 
 // GetProfitableChainDetails godoc
 // @Summary retrieves profitable deal chain details by id
+// @Description if the chain has expired, it's retrieved from the archive
 // @Accept json
 // @Produce json
 // @Router /arbitrage/chains/{chainId}/details [get]
 // @Param chainId path string true "chain id"
 // @Success 200 {object} ProfitableChain
+// @Failure 404 {object} http.Error
 // @Failure 500 {object} http.Error
 // @tags arbitrage
 func (c *controllerIml) GetProfitableChainDetails(w http.ResponseWriter, r *http.Request) {
@@ -152,9 +403,142 @@ func (c *controllerIml) GetProfitableChainDetails(w http.ResponseWriter, r *http
 		c.RespondError(w, err)
 		return
 	}
+	if chain == nil {
+		c.RespondError(w, errors.ErrChainNotFound(ctx, chainId))
+		return
+	}
+	c.RespondOK(w, c.toProfitableChainApi(chain))
+}
+
+// GetArchivedChain godoc
+// @Summary retrieves archived chain by id
+// @Accept json
+// @Produce json
+// @Router /arbitrage/archive/chains/{chainId} [get]
+// @Param chainId path string true "chain id"
+// @Success 200 {object} ProfitableChain
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @tags arbitrage
+func (c *controllerIml) GetArchivedChain(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-archived-chain").Trc()
+
+	chainId, err := c.Var(r, ctx, "chainId", false)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	chain, err := c.arbitrageService.GetArchivedChain(ctx, chainId)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if chain == nil {
+		c.RespondError(w, errors.ErrChainNotFound(ctx, chainId))
+		return
+	}
 	c.RespondOK(w, c.toProfitableChainApi(chain))
 }
 
+// GetSpreads godoc
+// @Summary retrieves detected cross-exchange and cross-method spreads by criteria
+// @Description spreads are sorted by spread (the highest first)
+// @Accept json
+// @Produce json
+// @Router /arbitrage/spreads [get]
+// @Param assets query string false "comma separated list of assets (either base or quote)"
+// @Param types query string false "comma separated list of spread types (cross-exchange, cross-method)"
+// @Param exchanges query string false "comma separated list of exchange codes"
+// @Param methods query string false "comma separated list of methods"
+// @Param minSpread query number false "min spread in percents"
+// @Param size query int false "page size"
+// @Param index query int false "page index"
+// @Success 200 {object} Spreads
+// @Failure 500 {object} http.Error
+// @tags arbitrage
+func (c *controllerIml) GetSpreads(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-spreads").Trc()
+
+	rq := &domain.GetSpreadsRequest{}
+
+	var err error
+	if rq.Assets, err = c.FormValStrings(r, ctx, "assets", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if rq.Types, err = c.FormValStrings(r, ctx, "types", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if rq.ExchangeCodes, err = c.FormValStrings(r, ctx, "exchanges", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if rq.Methods, err = c.FormValStrings(r, ctx, "methods", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	minSpread, err := c.FormValFloat(r, ctx, "minSpread", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if minSpread != nil {
+		rq.MinSpread = *minSpread
+	}
+
+	size, index, err := c.FormPaging(r, ctx, nil)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if size != nil {
+		rq.PagingRequest.Size = *size
+	}
+	if index != nil {
+		rq.PagingRequest.Index = *index
+	}
+
+	spreadsRs, err := c.spreadDetector.GetSpreads(ctx, rq)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toSpreadsPageApi(spreadsRs))
+}
+
+// GetSpread godoc
+// @Summary retrieves spread by id
+// @Accept json
+// @Produce json
+// @Router /arbitrage/spreads/{spreadId} [get]
+// @Param spreadId path string true "spread id"
+// @Success 200 {object} Spread
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @tags arbitrage
+func (c *controllerIml) GetSpread(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-spread").Trc()
+
+	spreadId, err := c.Var(r, ctx, "spreadId", false)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	spread, err := c.spreadDetector.GetSpread(ctx, spreadId)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toSpreadApi(spread))
+}
+
 // Registration godoc
 // @Summary registers a new client
 // @Accept json
@@ -440,34 +824,1035 @@ func (c *controllerIml) GetUserSubscriptions(w http.ResponseWriter, r *http.Requ
 	c.RespondOK(w, c.toSubscriptionsApi(subscriptions))
 }
 
+// PreviewSubscription godoc
+// @Summary backtests a subscription filter against the chain history
+// @Description evaluates the filter against chains found within the last hours and estimates how many alerts the filter gives per day
+// @Accept json
+// @produce json
+// @Param userId path string true "user id"
+// @Param request body SubscriptionPreviewRequest true "preview request"
+// @Success 200 {object} SubscriptionPreview
+// @Failure 400 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /users/{userId}/subscriptions/preview [post]
+// @tags subscription
+func (c *controllerIml) PreviewSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userId, err := c.VarUUID(r, ctx, "userId", false)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	if appCtx, ok := context.Request(ctx); ok && appCtx.GetUserId() != userId {
+		c.RespondError(w, errors.ErrNotAllowed(ctx))
+		return
+	}
+
+	rq := &SubscriptionPreviewRequest{}
+	if err := c.DecodeRequest(r, ctx, rq); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	preview, err := c.subscriptionService.Preview(ctx, c.toSubscriptionPreviewRequestDomain(rq, userId))
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	c.RespondOK(w, c.toSubscriptionPreviewApi(preview))
+}
+
 func (c *controllerIml) GetSubscription(writer http.ResponseWriter, request *http.Request) {
 	panic("implement me")
 }
 
-// PutBid godoc
-// @Summary allows creation or updating an exchange bid
+// callerId returns id of the user who calls the API
+func (c *controllerIml) callerId(r *http.Request) (string, error) {
+	ctx := r.Context()
+	rq, err := context.MustRequest(ctx)
+	if err != nil {
+		return "", err
+	}
+	if rq.GetUserId() == "" {
+		return "", errors.ErrNotAllowed(ctx)
+	}
+	return rq.GetUserId(), nil
+}
+
+// CreateManualBid godoc
+// @Summary creates a manual bid (e.g. OTC quote) owned by the caller
 // @Accept json
 // @produce json
-// @Param request body BidRequest true "bid request"
+// @Param request body ManualBidRequest true "bid request"
 // @Success 200 {object} Bid
+// @Failure 400 {object} http.Error
 // @Failure 500 {object} http.Error
 // @Router /arbitrage/bids [post]
-// @tags subscription
-func (c *controllerIml) PutBid(w http.ResponseWriter, r *http.Request) {
+// @tags bids
+func (c *controllerIml) CreateManualBid(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("create-manual-bid").Trc()
+
+	ownerId, err := c.callerId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	rq := &ManualBidRequest{}
+	if err := c.DecodeRequest(r, ctx, rq); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	bids, err := c.manualBidService.CreateBids(ctx, ownerId, []*domain.ManualBidRequest{c.toManualBidRequestDomain(rq)})
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	c.RespondOK(w, c.toBidApi(bids[0]))
+}
+
+// CreateManualBids godoc
+// @Summary creates manual bids owned by the caller in bulk
+// @Description bids are validated before storing, so either all bids are created or none
+// @Accept json
+// @produce json
+// @Param request body ManualBidsRequest true "bids request"
+// @Success 200 {object} Bids
+// @Failure 400 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /arbitrage/bids/bulk [post]
+// @tags bids
+func (c *controllerIml) CreateManualBids(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	c.l().C(ctx).Mth("create-manual-bids").Trc()
+
+	ownerId, err := c.callerId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
 
-	rq := &BidRequest{}
+	rq := &ManualBidsRequest{}
 	if err := c.DecodeRequest(r, ctx, rq); err != nil {
 		c.RespondError(w, err)
 		return
 	}
 
-	bidRq := c.toBidRequestDomain(rq)
-	bid, err := c.bidProvider.PutBid(ctx, bidRq)
+	bids, err := c.manualBidService.CreateBids(ctx, ownerId, c.toManualBidsRequestDomain(rq))
 	if err != nil {
 		c.RespondError(w, err)
 		return
 	}
 
-	c.RespondOK(w, c.toBidApi(bid))
+	c.RespondOK(w, c.toBidsListApi(bids))
+}
+
+// UpdateManualBid godoc
+// @Summary updates a manual bid
+// @Description only the owner or admin is allowed to update the bid, ttl is counted from the update time
+// @Accept json
+// @produce json
+// @Param bidId path string true "bid id"
+// @Param request body ManualBidRequest true "bid request"
+// @Success 200 {object} Bid
+// @Failure 403 {object} http.Error
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /arbitrage/bids/{bidId} [put]
+// @tags bids
+func (c *controllerIml) UpdateManualBid(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("update-manual-bid").Trc()
+
+	bidId, err := c.Var(r, ctx, "bidId", false)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	rq := &ManualBidRequest{}
+	if err := c.DecodeRequest(r, ctx, rq); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	bid, err := c.manualBidService.UpdateBid(ctx, bidId, c.toManualBidRequestDomain(rq))
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	c.RespondOK(w, c.toBidApi(bid))
+}
+
+// DeleteManualBid godoc
+// @Summary withdraws a manual bid
+// @Description only the owner or admin is allowed to withdraw the bid
+// @Accept json
+// @produce json
+// @Param bidId path string true "bid id"
+// @Success 200
+// @Failure 403 {object} http.Error
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /arbitrage/bids/{bidId} [delete]
+// @tags bids
+func (c *controllerIml) DeleteManualBid(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("delete-manual-bid").Trc()
+
+	bidId, err := c.Var(r, ctx, "bidId", false)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	if err := c.manualBidService.DeleteBid(ctx, bidId); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	c.RespondOK(w, kitHttp.EmptyOkResponse)
+}
+
+// GetManualBid godoc
+// @Summary retrieves a manual bid
+// @Accept json
+// @produce json
+// @Param bidId path string true "bid id"
+// @Success 200 {object} Bid
+// @Failure 403 {object} http.Error
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /arbitrage/bids/{bidId} [get]
+// @tags bids
+func (c *controllerIml) GetManualBid(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-manual-bid").Trc()
+
+	bidId, err := c.Var(r, ctx, "bidId", false)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	bid, err := c.manualBidService.GetBid(ctx, bidId)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	c.RespondOK(w, c.toBidApi(bid))
+}
+
+// GetManualBids godoc
+// @Summary retrieves manual bids of the owner
+// @Accept json
+// @produce json
+// @Param ownerId query string false "owner id, the caller by default. Only admin is allowed to request bids of other users"
+// @Success 200 {object} Bids
+// @Failure 403 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /arbitrage/bids [get]
+// @tags bids
+func (c *controllerIml) GetManualBids(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-manual-bids").Trc()
+
+	ownerId, err := c.FormVal(r, ctx, "ownerId", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if ownerId == "" {
+		if ownerId, err = c.callerId(r); err != nil {
+			c.RespondError(w, err)
+			return
+		}
+	}
+
+	bids, err := c.manualBidService.GetBids(ctx, ownerId)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	c.RespondOK(w, c.toBidsListApi(bids))
+}
+
+// GetBidsStaleness godoc
+// @Summary retrieves bids freshness by exchanges
+// @Description bids observed earlier than the configured max age are stale and ignored when finding chains
+// @Accept json
+// @produce json
+// @Success 200 {object} ExchangesStaleness
+// @Failure 500 {object} http.Error
+// @Router /arbitrage/bids/staleness [get]
+// @tags arbitrage
+func (c *controllerIml) GetBidsStaleness(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-bids-staleness").Trc()
+
+	staleness, err := c.bidProvider.GetExchangesStaleness(ctx)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toExchangesStalenessApi(staleness))
+}
+
+// GetRateHistory godoc
+// @Summary retrieves rate history of the pair as OHLC candles of the best rate
+// @Accept json
+// @produce json
+// @Param src path string true "source asset"
+// @Param trg path string true "target asset"
+// @Param exchange query string false "exchange code"
+// @Param method query string false "payment method"
+// @Param from query string false "period start (RFC3339), by default 24h before the end"
+// @Param to query string false "period end (RFC3339), by default now"
+// @Param resolution query int false "candle duration in seconds, by default 3600"
+// @Success 200 {object} RateHistory
+// @Failure 400 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /market/pairs/{src}/{trg}/history [get]
+// @tags market
+func (c *controllerIml) GetRateHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-rate-history").Trc()
+
+	rq := &domain.GetRateHistoryRequest{}
+
+	var err error
+	rq.SrcAsset, err = c.Var(r, ctx, "src", false)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	rq.TrgAsset, err = c.Var(r, ctx, "trg", false)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	rq.ExchangeCode, err = c.FormVal(r, ctx, "exchange", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	rq.Method, err = c.FormVal(r, ctx, "method", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	from, err := c.FormValTime(r, ctx, "from", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if from != nil {
+		rq.From = *from
+	}
+	to, err := c.FormValTime(r, ctx, "to", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if to != nil {
+		rq.To = *to
+	}
+
+	resolution, err := c.FormValInt(r, ctx, "resolution", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if resolution != nil {
+		rq.Resolution = time.Duration(*resolution) * time.Second
+	}
+
+	candles, err := c.marketService.GetRateHistory(ctx, rq)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toRateHistoryApi(rq, candles))
+}
+
+// GetMarketOverview godoc
+// @Summary retrieves matrix of the best rates for all pairs per exchange and overall from the current bids
+// @Accept json
+// @produce json
+// @Param method query string false "takes into account bids with the payment method only"
+// @Success 200 {object} MarketOverview
+// @Failure 500 {object} http.Error
+// @Router /market/overview [get]
+// @tags market
+func (c *controllerIml) GetMarketOverview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-market-overview").Trc()
+
+	method, err := c.FormVal(r, ctx, "method", true)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	overview, err := c.marketService.GetMarketOverview(ctx, &domain.MarketOverviewRequest{Method: method})
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toMarketOverviewApi(overview))
+}
+
+// GetReferenceRates godoc
+// @Summary retrieves reference FX rates used to normalize chains to the base currency
+// @Accept json
+// @produce json
+// @Success 200 {object} ReferenceRates
+// @Failure 500 {object} http.Error
+// @Router /market/reference-rates [get]
+// @tags market
+func (c *controllerIml) GetReferenceRates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-reference-rates").Trc()
+
+	rates, err := c.marketService.GetReferenceRates(ctx)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toReferenceRatesApi(rates))
+}
+
+// GetOutboxDeliveries godoc
+// @Summary retrieves notification deliveries recorded in the outbox
+// @Description the latest deliveries go first. Only admin is allowed
+// @Accept json
+// @produce json
+// @Param statuses query string false "comma separated list of statuses (pending, sending, delivered, dead)"
+// @Param channel query string false "notification channel"
+// @Param subscriptionId query string false "subscription id"
+// @Param size query int false "page size"
+// @Param index query int false "page index"
+// @Success 200 {object} OutboxDeliveries
+// @Failure 400 {object} http.Error
+// @Failure 403 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /notifications/outbox [get]
+// @tags notifications
+func (c *controllerIml) GetOutboxDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-outbox-deliveries").Trc()
+
+	rq := &domain.SearchOutboxRequest{}
+
+	var err error
+	if rq.Statuses, err = c.FormValStrings(r, ctx, "statuses", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if rq.Channel, err = c.FormVal(r, ctx, "channel", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if rq.SubscriptionId, err = c.FormVal(r, ctx, "subscriptionId", true); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	size, index, err := c.FormPaging(r, ctx, nil)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	if size != nil {
+		rq.PagingRequest.Size = *size
+	}
+	if index != nil {
+		rq.PagingRequest.Index = *index
+	}
+
+	rs, err := c.notificationOutbox.Search(ctx, rq)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toOutboxDeliveriesPageApi(rs))
+}
+
+// ReplayOutboxDeliveries godoc
+// @Summary moves dead deliveries back to the outbox queue
+// @Description attempts start over. Only dead deliveries are allowed to be replayed. Only admin is allowed
+// @Accept json
+// @produce json
+// @Param request body OutboxReplayRequest true "replay request"
+// @Success 200 {object} OutboxDeliveriesList
+// @Failure 400 {object} http.Error
+// @Failure 403 {object} http.Error
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /notifications/outbox/replay [post]
+// @tags notifications
+func (c *controllerIml) ReplayOutboxDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("replay-outbox-deliveries").Trc()
+
+	rq := &OutboxReplayRequest{}
+	if err := c.DecodeRequest(r, ctx, rq); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	deliveries, err := c.notificationOutbox.Replay(ctx, rq.Ids)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toOutboxDeliveriesListApi(deliveries))
+}
+
+// PreviewNotificationTemplate godoc
+// @Summary renders a notification template against a sample opportunity
+// @Description if template isn't specified, the template the channel uses by default is rendered. If chainId isn't specified, a sample chain is taken
+// @Accept json
+// @produce json
+// @Param request body TemplatePreviewRequest true "preview request"
+// @Success 200 {object} TemplatePreview
+// @Failure 400 {object} http.Error
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /notifications/templates/preview [post]
+// @tags notifications
+func (c *controllerIml) PreviewNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("preview-template").Trc()
+
+	rq := &TemplatePreviewRequest{}
+	if err := c.DecodeRequest(r, ctx, rq); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	previewRq := &domain.TemplatePreviewRequest{
+		Channel:  rq.Channel,
+		Type:     rq.Type,
+		Template: c.toNotificationTemplateDomain(rq.Template),
+	}
+	if rq.ChainId != "" {
+		chain, err := c.arbitrageService.GetProfitableChain(ctx, rq.ChainId)
+		if err != nil {
+			c.RespondError(w, err)
+			return
+		}
+		if chain == nil {
+			c.RespondError(w, errors.ErrChainNotFound(ctx, rq.ChainId))
+			return
+		}
+		previewRq.Chain = chain
+	}
+
+	msg, err := c.subscriptionService.PreviewTemplate(ctx, previewRq)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toTemplatePreviewApi(msg))
+}
+
+// ownUserId retrieves user id from the path, only the user is allowed to manage own telegram link, channels and email addresses
+func (c *controllerIml) ownUserId(r *http.Request) (string, error) {
+	ctx := r.Context()
+	userId, err := c.VarUUID(r, ctx, "userId", false)
+	if err != nil {
+		return "", err
+	}
+	if appCtx, ok := context.Request(ctx); ok && appCtx.GetUserId() != userId {
+		return "", errors.ErrNotAllowed(ctx)
+	}
+	return userId, nil
+}
+
+// CreateTelegramLinkCode godoc
+// @Summary issues a one-time code linking a telegram account to the user
+// @Description the user sends "/link code" to the bot or opens the returned url, the code can be used once
+// @Accept json
+// @produce json
+// @Param userId path string true "user id"
+// @Success 200 {object} TelegramLinkCode
+// @Failure 400 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /users/{userId}/telegram/link [post]
+// @tags telegram
+func (c *controllerIml) CreateTelegramLinkCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("create-telegram-link-code").Trc()
+
+	userId, err := c.ownUserId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	code, err := c.telegramBot.LinkCode(ctx, userId)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toTelegramLinkCodeApi(code))
+}
+
+// GetTelegramLink godoc
+// @Summary retrieves linked telegram account of the user
+// @Accept json
+// @produce json
+// @Param userId path string true "user id"
+// @Success 200 {object} TelegramLink
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /users/{userId}/telegram [get]
+// @tags telegram
+func (c *controllerIml) GetTelegramLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-telegram-link").Trc()
+
+	userId, err := c.ownUserId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	link, err := c.telegramBot.GetLink(ctx, userId)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toTelegramLinkApi(link))
+}
+
+// DeleteTelegramLink godoc
+// @Summary unlinks telegram account of the user
+// @Accept json
+// @produce json
+// @Param userId path string true "user id"
+// @Success 200
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /users/{userId}/telegram [delete]
+// @tags telegram
+func (c *controllerIml) DeleteTelegramLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("delete-telegram-link").Trc()
+
+	userId, err := c.ownUserId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	if err := c.telegramBot.Unlink(ctx, userId); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, kitHttp.EmptyOkResponse)
+}
+
+// TelegramWebhook godoc
+// @Summary receives bot updates from Bot API
+// @Description Bot API passes the configured secret in X-Telegram-Bot-Api-Secret-Token header
+// @Accept json
+// @produce json
+// @Success 200
+// @Failure 401 {object} http.Error
+// @Router /telegram/webhook [post]
+// @tags telegram
+func (c *controllerIml) TelegramWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := c.l().C(ctx).Mth("telegram-webhook").Trc()
+
+	update := &telegram.Update{}
+	if err := c.DecodeRequest(r, ctx, update); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	if err := c.telegramBot.HandleWebhook(ctx, r.Header.Get(telegram.WebhookSecretHeader), update); err != nil {
+		if appErr, ok := er.Is(err); ok && appErr.Code() == errors.ErrCodeTelegramWebhookSecretInvalid {
+			c.RespondError(w, err)
+			return
+		}
+		// Bot API redelivers updates until it gets OK, so failed updates are logged only
+		l.E(err).Err()
+	}
+	c.RespondOK(w, kitHttp.EmptyOkResponse)
+}
+
+// telegramChannelId retrieves telegram channel id from the path
+func (c *controllerIml) telegramChannelId(r *http.Request) (int64, error) {
+	ctx := r.Context()
+	v, err := c.Var(r, ctx, "channelId", false)
+	if err != nil {
+		return 0, err
+	}
+	channelId, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || channelId == 0 {
+		return 0, errors.ErrTelegramChannelInvalid(ctx)
+	}
+	return channelId, nil
+}
+
+// RequestTelegramChannelVerification godoc
+// @Summary issues a one-time code verifying the telegram channel
+// @Description the user posts "/verify code" to the channel, notifications to the channel stay inactive until it's verified
+// @Accept json
+// @produce json
+// @Param userId path string true "user id"
+// @Param channelId path int true "telegram channel id"
+// @Success 200 {object} TelegramChannelVerification
+// @Failure 400 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /users/{userId}/telegram/channels/{channelId}/verification [post]
+// @tags telegram
+func (c *controllerIml) RequestTelegramChannelVerification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("request-telegram-channel-verification").Trc()
+
+	userId, err := c.ownUserId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	channelId, err := c.telegramChannelId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	v, err := c.telegramChannels.Request(ctx, userId, channelId)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toTelegramChannelVerificationApi(v))
+}
+
+// ConfirmTelegramChannelVerification godoc
+// @Summary confirms the telegram channel through Bot API
+// @Description the channel is verified if the code has been posted or both the bot and the linked telegram account are admins of the channel
+// @Accept json
+// @produce json
+// @Param userId path string true "user id"
+// @Param channelId path int true "telegram channel id"
+// @Success 200 {object} TelegramChannelVerification
+// @Failure 400 {object} http.Error
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /users/{userId}/telegram/channels/{channelId}/verification/confirm [post]
+// @tags telegram
+func (c *controllerIml) ConfirmTelegramChannelVerification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("confirm-telegram-channel-verification").Trc()
+
+	userId, err := c.ownUserId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	channelId, err := c.telegramChannelId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	v, err := c.telegramChannels.Confirm(ctx, userId, channelId)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toTelegramChannelVerificationApi(v))
+}
+
+// GetTelegramChannels godoc
+// @Summary retrieves telegram channel verifications of the user
+// @Accept json
+// @produce json
+// @Param userId path string true "user id"
+// @Success 200 {array} TelegramChannelVerification
+// @Failure 400 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /users/{userId}/telegram/channels [get]
+// @tags telegram
+func (c *controllerIml) GetTelegramChannels(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-telegram-channels").Trc()
+
+	userId, err := c.ownUserId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	vv, err := c.telegramChannels.GetVerifications(ctx, userId)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toTelegramChannelVerificationsApi(vv))
+}
+
+// RequestEmailVerification godoc
+// @Summary sends a code confirming the recipient address
+// @Description email notifications stay inactive until all the recipients are confirmed. The code isn't returned, it's sent to the address only
+// @Accept json
+// @produce json
+// @Param userId path string true "user id"
+// @Param request body EmailVerificationRequest true "address"
+// @Success 200 {object} EmailVerification
+// @Failure 400 {object} http.Error
+// @Failure 429 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /users/{userId}/email/addresses/verification [post]
+// @tags email
+func (c *controllerIml) RequestEmailVerification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("request-email-verification").Trc()
+
+	userId, err := c.ownUserId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	rq := &EmailVerificationRequest{}
+	if err := c.DecodeRequest(r, ctx, rq); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	v, err := c.emailVerifier.Request(ctx, userId, rq.Email)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toEmailVerificationApi(v))
+}
+
+// ConfirmEmailVerification godoc
+// @Summary confirms the recipient address with the code
+// @Description email notifications of the user waiting for confirmation of the address are activated
+// @Accept json
+// @produce json
+// @Param userId path string true "user id"
+// @Param request body EmailVerificationConfirmRequest true "address and code"
+// @Success 200 {object} EmailVerification
+// @Failure 400 {object} http.Error
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /users/{userId}/email/addresses/verification/confirm [post]
+// @tags email
+func (c *controllerIml) ConfirmEmailVerification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("confirm-email-verification").Trc()
+
+	userId, err := c.ownUserId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	rq := &EmailVerificationConfirmRequest{}
+	if err := c.DecodeRequest(r, ctx, rq); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	v, err := c.emailVerifier.Confirm(ctx, userId, rq.Email, rq.Code)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toEmailVerificationApi(v))
+}
+
+// GetEmailAddresses godoc
+// @Summary retrieves email verifications of the user
+// @Accept json
+// @produce json
+// @Param userId path string true "user id"
+// @Success 200 {array} EmailVerification
+// @Failure 400 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /users/{userId}/email/addresses [get]
+// @tags email
+func (c *controllerIml) GetEmailAddresses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-email-addresses").Trc()
+
+	userId, err := c.ownUserId(r)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	vv, err := c.emailVerifier.GetVerifications(ctx, userId)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toEmailVerificationsApi(vv))
+}
+
+// CreateTelegramBot godoc
+// @Summary registers a telegram bot notifications are sent from
+// @Description the token is checked by Bot API and stored encrypted. Subscriptions of the users of the bot are notified by it unless they select another bot. Only admin is allowed
+// @Accept json
+// @produce json
+// @Param request body TelegramBotRequest true "bot"
+// @Success 200 {object} TelegramBot
+// @Failure 400 {object} http.Error
+// @Failure 403 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /notifications/telegram/bots [post]
+// @tags notifications
+func (c *controllerIml) CreateTelegramBot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("create-telegram-bot").Trc()
+
+	rq := &TelegramBotRequest{}
+	if err := c.DecodeRequest(r, ctx, rq); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	bot, err := c.telegramBots.Create(ctx, c.toTelegramBotRequestDomain(rq))
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toTelegramBotApi(bot))
+}
+
+// UpdateTelegramBot godoc
+// @Summary updates a telegram bot
+// @Description empty token keeps the current one. The default bot is configured and can't be updated. Only admin is allowed
+// @Accept json
+// @produce json
+// @Param botId path string true "bot id"
+// @Param request body TelegramBotRequest true "bot"
+// @Success 200 {object} TelegramBot
+// @Failure 400 {object} http.Error
+// @Failure 403 {object} http.Error
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /notifications/telegram/bots/{botId} [put]
+// @tags notifications
+func (c *controllerIml) UpdateTelegramBot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("update-telegram-bot").Trc()
+
+	botId, err := c.Var(r, ctx, "botId", false)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	rq := &TelegramBotRequest{}
+	if err := c.DecodeRequest(r, ctx, rq); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	bot, err := c.telegramBots.Update(ctx, botId, c.toTelegramBotRequestDomain(rq))
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toTelegramBotApi(bot))
+}
+
+// DeleteTelegramBot godoc
+// @Summary deletes a telegram bot
+// @Description notifications selecting the bot are sent by the bot of the user or the default one. Only admin is allowed
+// @Accept json
+// @produce json
+// @Param botId path string true "bot id"
+// @Success 200
+// @Failure 400 {object} http.Error
+// @Failure 403 {object} http.Error
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /notifications/telegram/bots/{botId} [delete]
+// @tags notifications
+func (c *controllerIml) DeleteTelegramBot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("delete-telegram-bot").Trc()
+
+	botId, err := c.Var(r, ctx, "botId", false)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	if err := c.telegramBots.Delete(ctx, botId); err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, kitHttp.EmptyOkResponse)
+}
+
+// GetTelegramBot godoc
+// @Summary retrieves a telegram bot
+// @Description Only admin is allowed
+// @Accept json
+// @produce json
+// @Param botId path string true "bot id"
+// @Success 200 {object} TelegramBot
+// @Failure 403 {object} http.Error
+// @Failure 404 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /notifications/telegram/bots/{botId} [get]
+// @tags notifications
+func (c *controllerIml) GetTelegramBot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-telegram-bot").Trc()
+
+	botId, err := c.Var(r, ctx, "botId", false)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+
+	bot, err := c.telegramBots.Get(ctx, botId)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toTelegramBotApi(bot))
+}
+
+// GetTelegramBots godoc
+// @Summary retrieves all telegram bots with their health
+// @Description the default bot goes first. Only admin is allowed
+// @Accept json
+// @produce json
+// @Success 200 {array} TelegramBot
+// @Failure 403 {object} http.Error
+// @Failure 500 {object} http.Error
+// @Router /notifications/telegram/bots [get]
+// @tags notifications
+func (c *controllerIml) GetTelegramBots(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c.l().C(ctx).Mth("get-telegram-bots").Trc()
+
+	bots, err := c.telegramBots.GetAll(ctx)
+	if err != nil {
+		c.RespondError(w, err)
+		return
+	}
+	c.RespondOK(w, c.toTelegramBotsApi(bots))
 }
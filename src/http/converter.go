@@ -2,13 +2,17 @@ package http
 
 import (
 	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/auth"
+	kitHttp "github.com/mikhailbolshakov/cryptocare/src/kit/http"
+	"time"
 )
 
 func (c *controllerIml) toBidsApi(bids []*domain.Bid) []*Bid {
 	var r []*Bid
+	now := kit.Now()
 	for _, b := range bids {
-		r = append(r, &Bid{
+		bid := &Bid{
 			Id:           b.Id,
 			Type:         b.Type,
 			SrcAsset:     b.SrcAsset,
@@ -21,7 +25,51 @@ func (c *controllerIml) toBidsApi(bids []*domain.Bid) []*Bid {
 			Methods:      b.Methods,
 			UserId:       b.UserId,
 			Link:         b.Link,
-		})
+			ObservedAt:   c.timeToApi(b.ObservedAt),
+			IngestedAt:   c.timeToApi(b.IngestedAt),
+			OwnerId:      b.OwnerId,
+			ExpiresAt:    c.timeToApi(b.ExpiresAt),
+			Private:      b.Private,
+		}
+		if !b.ObservedAt.IsZero() {
+			bid.AgeSec = c.ageSecToApi(now, b.ObservedAt)
+		}
+		r = append(r, bid)
+	}
+	return r
+}
+
+// timeToApi converts time to api, zero time means not specified
+func (c *controllerIml) timeToApi(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func (c *controllerIml) ageSecToApi(now, t time.Time) *int64 {
+	age := int64(now.Sub(t).Seconds())
+	if age < 0 {
+		age = 0
+	}
+	return &age
+}
+
+func (c *controllerIml) toExchangesStalenessApi(ss []*domain.ExchangeStaleness) *ExchangesStaleness {
+	r := &ExchangesStaleness{Exchanges: []*ExchangeStaleness{}}
+	now := kit.Now()
+	for _, st := range ss {
+		item := &ExchangeStaleness{
+			ExchangeCode:     st.ExchangeCode,
+			Bids:             st.Bids,
+			StaleBids:        st.StaleBids,
+			OldestObservedAt: c.timeToApi(st.OldestObservedAt),
+			LatestObservedAt: c.timeToApi(st.LatestObservedAt),
+		}
+		if !st.LatestObservedAt.IsZero() {
+			item.LatestAgeSec = c.ageSecToApi(now, st.LatestObservedAt)
+		}
+		r.Exchanges = append(r.Exchanges, item)
 	}
 	return r
 }
@@ -30,7 +78,7 @@ func (c *controllerIml) toProfitableChainApi(ch *domain.ProfitableChain) *Profit
 	if ch == nil {
 		return nil
 	}
-	return &ProfitableChain{
+	r := &ProfitableChain{
 		Id:            ch.Id,
 		Asset:         ch.Asset,
 		ProfitShare:   ch.ProfitShare,
@@ -38,9 +86,22 @@ func (c *controllerIml) toProfitableChainApi(ch *domain.ProfitableChain) *Profit
 		BidAssets:     ch.BidAssets,
 		Depth:         ch.Depth,
 		ExchangeCodes: ch.ExchangeCodes,
+		BidTypes:      ch.BidTypes,
+		Score:         ch.Score,
+		Volume:        ch.Volume,
+		BaseCurrency:  ch.BaseCurrency,
+		BaseVolume:    ch.BaseVolume,
+		BaseProfit:    ch.BaseProfit,
 		Bids:          c.toBidsApi(ch.Bids),
+		ObservedAt:    c.timeToApi(ch.ObservedAt),
 		CreatedAt:     ch.CreatedAt,
+		Archived:      ch.Archived,
+		OwnerId:       ch.OwnerId,
+	}
+	if !ch.ExpiresAt.IsZero() {
+		r.ExpiresAt = &ch.ExpiresAt
 	}
+	return r
 }
 
 func (c *controllerIml) toProfitableChainsApi(chains []*domain.ProfitableChain) *ProfitableChains {
@@ -51,6 +112,26 @@ func (c *controllerIml) toProfitableChainsApi(chains []*domain.ProfitableChain)
 	return r
 }
 
+func (c *controllerIml) toProfitableChainsPageApi(rs *domain.GetProfitableChainsResponse) *ProfitableChains {
+	r := c.toProfitableChainsApi(rs.Chains)
+	r.Total = rs.Total
+	r.Index = rs.Index
+	r.NextCursor = rs.NextCursor
+	return r
+}
+
+func (c *controllerIml) toSortRequestDomain(sortBy []*kitHttp.SortRequest) []*kit.SortRequest {
+	var r []*kit.SortRequest
+	for _, s := range sortBy {
+		r = append(r, &kit.SortRequest{
+			Field:   s.Field,
+			Asc:     s.Asc,
+			Missing: s.Missing,
+		})
+	}
+	return r
+}
+
 func (c *controllerIml) toLoginRequest(rq *LoginRequest) *auth.LoginRequest {
 	if rq == nil {
 		return nil
@@ -106,24 +187,182 @@ func (c *controllerIml) toSubscriptionFilterDomain(f *SubscriptionChainFilter) *
 		return nil
 	}
 	return &domain.SubscriptionChainFilter{
-		Assets:    f.Assets,
-		Methods:   f.Methods,
-		Exchanges: f.Exchanges,
-		MaxDepth:  f.MaxDepth,
-		MinProfit: f.MinProfit,
+		Assets:        f.Assets,
+		Methods:       f.Methods,
+		Exchanges:     f.Exchanges,
+		MaxDepth:      f.MaxDepth,
+		MinProfit:     f.MinProfit,
+		Opportunities: f.Opportunities,
+		Expression:    f.Expression,
 	}
 }
 
 func (c *controllerIml) toSubscriptionNotificationsRequestDomain(n []*SubscriptionNotificationRequest) []*domain.SubscriptionNotification {
 	var r []*domain.SubscriptionNotification
 	for _, nn := range n {
-		r = append(r, &domain.SubscriptionNotification{
-			Channel:  domain.SubscriptionNotificationChannelTelegram,
+		notify := &domain.SubscriptionNotification{
+			Channel:  nn.Channel,
 			IsActive: nn.IsActive,
-			Telegram: &domain.SubscriptionTelegramNotificationDetails{
+		}
+		if notify.Channel == "" {
+			notify.Channel = domain.SubscriptionNotificationChannelTelegram
+		}
+		if nn.TelegramChannel != 0 {
+			notify.Telegram = &domain.SubscriptionTelegramNotificationDetails{
 				Channel: nn.TelegramChannel,
-			},
-		})
+				BotId:   nn.TelegramBotId,
+			}
+		}
+		if nn.Email != nil {
+			notify.Email = &domain.SubscriptionEmailNotificationDetails{
+				To: nn.Email.To,
+			}
+		}
+		if nn.Webhook != nil {
+			notify.Webhook = &domain.SubscriptionWebhookNotificationDetails{
+				Url:    nn.Webhook.Url,
+				Secret: nn.Webhook.Secret,
+			}
+		}
+		notify.Templates = c.toNotificationTemplatesDomain(nn.Templates)
+		r = append(r, notify)
+	}
+	return r
+}
+
+func (c *controllerIml) toNotificationTemplateDomain(t *NotificationTemplate) *domain.NotificationTemplate {
+	if t == nil {
+		return nil
+	}
+	return &domain.NotificationTemplate{
+		Subject: t.Subject,
+		Body:    t.Body,
+	}
+}
+
+func (c *controllerIml) toNotificationTemplatesDomain(t *NotificationTemplates) *domain.NotificationTemplates {
+	if t == nil {
+		return nil
+	}
+	return &domain.NotificationTemplates{
+		Chain:  c.toNotificationTemplateDomain(t.Chain),
+		Spread: c.toNotificationTemplateDomain(t.Spread),
+		Digest: c.toNotificationTemplateDomain(t.Digest),
+	}
+}
+
+func (c *controllerIml) toNotificationTemplateApi(t *domain.NotificationTemplate) *NotificationTemplate {
+	if t == nil {
+		return nil
+	}
+	return &NotificationTemplate{
+		Subject: t.Subject,
+		Body:    t.Body,
+	}
+}
+
+func (c *controllerIml) toNotificationTemplatesApi(t *domain.NotificationTemplates) *NotificationTemplates {
+	if t == nil {
+		return nil
+	}
+	return &NotificationTemplates{
+		Chain:  c.toNotificationTemplateApi(t.Chain),
+		Spread: c.toNotificationTemplateApi(t.Spread),
+		Digest: c.toNotificationTemplateApi(t.Digest),
+	}
+}
+
+func (c *controllerIml) toTemplatePreviewApi(msg *domain.RenderedMessage) *TemplatePreview {
+	return &TemplatePreview{
+		Subject: msg.Subject,
+		Body:    msg.Body,
+	}
+}
+
+func (c *controllerIml) toTelegramLinkCodeApi(code *domain.TelegramLinkCode) *TelegramLinkCode {
+	return &TelegramLinkCode{
+		Code:      code.Code,
+		Url:       code.Url,
+		ExpiresAt: code.ExpiresAt,
+	}
+}
+
+func (c *controllerIml) toTelegramLinkApi(link *domain.TelegramLink) *TelegramLink {
+	return &TelegramLink{
+		TelegramUserId: link.TelegramUserId,
+		Username:       link.Username,
+		CreatedAt:      link.CreatedAt,
+	}
+}
+
+func (c *controllerIml) toTelegramChannelVerificationApi(v *domain.TelegramChannelVerification) *TelegramChannelVerification {
+	return &TelegramChannelVerification{
+		ChannelId:  v.ChatId,
+		Code:       v.Code,
+		Status:     v.Status,
+		Method:     v.Method,
+		ExpiresAt:  v.ExpiresAt,
+		VerifiedAt: v.VerifiedAt,
+	}
+}
+
+func (c *controllerIml) toTelegramChannelVerificationsApi(vv []*domain.TelegramChannelVerification) []*TelegramChannelVerification {
+	r := make([]*TelegramChannelVerification, 0, len(vv))
+	for _, v := range vv {
+		r = append(r, c.toTelegramChannelVerificationApi(v))
+	}
+	return r
+}
+
+func (c *controllerIml) toEmailVerificationApi(v *domain.EmailVerification) *EmailVerification {
+	return &EmailVerification{
+		Email:      v.Email,
+		Status:     v.Status,
+		ExpiresAt:  v.ExpiresAt,
+		VerifiedAt: v.VerifiedAt,
+	}
+}
+
+func (c *controllerIml) toEmailVerificationsApi(vv []*domain.EmailVerification) []*EmailVerification {
+	r := make([]*EmailVerification, 0, len(vv))
+	for _, v := range vv {
+		r = append(r, c.toEmailVerificationApi(v))
+	}
+	return r
+}
+
+func (c *controllerIml) toTelegramBotRequestDomain(rq *TelegramBotRequest) *domain.TelegramBotAccountRequest {
+	return &domain.TelegramBotAccountRequest{
+		Name:        rq.Name,
+		Token:       rq.Token,
+		UserIds:     rq.UserIds,
+		SecondaryId: rq.SecondaryId,
+		RatePerSec:  rq.RatePerSec,
+		IsActive:    rq.IsActive,
+	}
+}
+
+func (c *controllerIml) toTelegramBotApi(b *domain.TelegramBotAccount) *TelegramBot {
+	return &TelegramBot{
+		Id:          b.Id,
+		Name:        b.Name,
+		Username:    b.Username,
+		UserIds:     b.UserIds,
+		SecondaryId: b.SecondaryId,
+		RatePerSec:  b.RatePerSec,
+		IsActive:    b.IsActive,
+		Health:      b.Health,
+		LastError:   b.LastError,
+		FailedAt:    b.FailedAt,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+	}
+}
+
+func (c *controllerIml) toTelegramBotsApi(bots []*domain.TelegramBotAccount) []*TelegramBot {
+	r := make([]*TelegramBot, 0, len(bots))
+	for _, b := range bots {
+		r = append(r, c.toTelegramBotApi(b))
 	}
 	return r
 }
@@ -139,7 +378,56 @@ func (c *controllerIml) toCreateSubscriptionRequestDomain(rq *SubscriptionReques
 		UserId:        userId,
 		Filter:        c.toSubscriptionFilterDomain(rq.Filter),
 		Notifications: c.toSubscriptionNotificationsRequestDomain(rq.Notifications),
+		Policy:        c.toSubscriptionPolicyDomain(rq.Policy),
+	}
+}
+
+func (c *controllerIml) toSubscriptionPolicyDomain(p *SubscriptionDeliveryPolicy) *domain.SubscriptionDeliveryPolicy {
+	if p == nil {
+		return nil
 	}
+	r := &domain.SubscriptionDeliveryPolicy{
+		MaxMessages: p.MaxMessages,
+		WindowSec:   p.WindowSec,
+	}
+	if p.QuietHours != nil {
+		r.QuietHours = &domain.SubscriptionQuietHours{
+			From:     p.QuietHours.From,
+			To:       p.QuietHours.To,
+			Timezone: p.QuietHours.Timezone,
+		}
+	}
+	if p.Digest != nil {
+		r.Digest = &domain.SubscriptionDigest{
+			PeriodMin: p.Digest.PeriodMin,
+			MaxItems:  p.Digest.MaxItems,
+		}
+	}
+	return r
+}
+
+func (c *controllerIml) toSubscriptionPolicyApi(p *domain.SubscriptionDeliveryPolicy) *SubscriptionDeliveryPolicy {
+	if p == nil {
+		return nil
+	}
+	r := &SubscriptionDeliveryPolicy{
+		MaxMessages: p.MaxMessages,
+		WindowSec:   p.WindowSec,
+	}
+	if p.QuietHours != nil {
+		r.QuietHours = &SubscriptionQuietHours{
+			From:     p.QuietHours.From,
+			To:       p.QuietHours.To,
+			Timezone: p.QuietHours.Timezone,
+		}
+	}
+	if p.Digest != nil {
+		r.Digest = &SubscriptionDigest{
+			PeriodMin: p.Digest.PeriodMin,
+			MaxItems:  p.Digest.MaxItems,
+		}
+	}
+	return r
 }
 
 func (c *controllerIml) toSubscriptionFilterApi(f *domain.SubscriptionChainFilter) *SubscriptionChainFilter {
@@ -147,11 +435,13 @@ func (c *controllerIml) toSubscriptionFilterApi(f *domain.SubscriptionChainFilte
 		return nil
 	}
 	return &SubscriptionChainFilter{
-		Assets:    f.Assets,
-		Methods:   f.Methods,
-		Exchanges: f.Exchanges,
-		MaxDepth:  f.MaxDepth,
-		MinProfit: f.MinProfit,
+		Assets:        f.Assets,
+		Methods:       f.Methods,
+		Exchanges:     f.Exchanges,
+		MaxDepth:      f.MaxDepth,
+		MinProfit:     f.MinProfit,
+		Opportunities: f.Opportunities,
+		Expression:    f.Expression,
 	}
 }
 
@@ -163,11 +453,26 @@ func (c *controllerIml) toSubscriptionNotificationsApi(nn []*domain.Subscription
 			Channel:  n.Channel,
 			IsActive: n.IsActive,
 		}
-		if n.Channel == domain.SubscriptionNotificationChannelTelegram {
+		if n.Telegram != nil {
 			notify.Telegram = &SubscriptionTelegramNotificationDetails{
-				Channel: n.Telegram.Channel,
+				Channel:  n.Telegram.Channel,
+				Verified: n.Telegram.Verified,
+				BotId:    n.Telegram.BotId,
+			}
+		}
+		if n.Email != nil {
+			notify.Email = &SubscriptionEmailNotificationDetails{
+				To:       n.Email.To,
+				Verified: n.Email.Verified,
+			}
+		}
+		if n.Webhook != nil {
+			notify.Webhook = &SubscriptionWebhookNotificationDetails{
+				Url:    n.Webhook.Url,
+				Secret: n.Webhook.Secret,
 			}
 		}
+		notify.Templates = c.toNotificationTemplatesApi(n.Templates)
 		r = append(r, notify)
 	}
 	return r
@@ -183,6 +488,7 @@ func (c *controllerIml) toSubscriptionApi(subs *domain.Subscription) *Subscripti
 		IsActive:      subs.IsActive,
 		Filter:        c.toSubscriptionFilterApi(subs.Filter),
 		Notifications: c.toSubscriptionNotificationsApi(subs.Notifications),
+		Policy:        c.toSubscriptionPolicyApi(subs.Policy),
 	}
 }
 
@@ -194,12 +500,41 @@ func (c *controllerIml) toSubscriptionsApi(subs []*domain.Subscription) []*Subsc
 	return r
 }
 
-func (c *controllerIml) toBidRequestDomain(rq *BidRequest) *domain.Bid {
+func (c *controllerIml) toSubscriptionPreviewRequestDomain(rq *SubscriptionPreviewRequest, userId string) *domain.SubscriptionPreviewRequest {
+	return &domain.SubscriptionPreviewRequest{
+		UserId:  userId,
+		Filter:  c.toSubscriptionFilterDomain(rq.Filter),
+		Hours:   rq.Hours,
+		Samples: rq.Samples,
+	}
+}
+
+func (c *controllerIml) toSubscriptionPreviewApi(p *domain.SubscriptionPreview) *SubscriptionPreview {
+	r := &SubscriptionPreview{
+		From:           p.From,
+		To:             p.To,
+		HistoryFrom:    p.HistoryFrom,
+		Scanned:        p.Scanned,
+		Matches:        p.Matches,
+		Hours:          make([]*SubscriptionPreviewHour, 0, len(p.Hours)),
+		Samples:        make([]*ProfitableChain, 0, len(p.Samples)),
+		EstimatedDaily: p.EstimatedDaily,
+		Truncated:      p.Truncated,
+	}
+	for _, h := range p.Hours {
+		r.Hours = append(r.Hours, &SubscriptionPreviewHour{Hour: h.Hour, Matches: h.Matches})
+	}
+	for _, ch := range p.Samples {
+		r.Samples = append(r.Samples, c.toProfitableChainApi(ch))
+	}
+	return r
+}
+
+func (c *controllerIml) toManualBidRequestDomain(rq *ManualBidRequest) *domain.ManualBidRequest {
 	if rq == nil {
-		return nil
+		return &domain.ManualBidRequest{}
 	}
-	return &domain.Bid{
-		Id:           rq.Id,
+	r := &domain.ManualBidRequest{
 		SrcAsset:     rq.SrcAsset,
 		TrgAsset:     rq.TrgAsset,
 		Rate:         rq.Rate,
@@ -208,27 +543,175 @@ func (c *controllerIml) toBidRequestDomain(rq *BidRequest) *domain.Bid {
 		MinLimit:     rq.MinLimit,
 		MaxLimit:     rq.MaxLimit,
 		Methods:      rq.Methods,
-		UserId:       rq.UserId,
 		Link:         rq.Link,
+		TtlSec:       rq.TtlSec,
+		Private:      rq.Private,
+	}
+	if rq.ObservedAt != nil {
+		r.ObservedAt = *rq.ObservedAt
 	}
+	return r
+}
+
+func (c *controllerIml) toManualBidsRequestDomain(rq *ManualBidsRequest) []*domain.ManualBidRequest {
+	var r []*domain.ManualBidRequest
+	for _, b := range rq.Bids {
+		r = append(r, c.toManualBidRequestDomain(b))
+	}
+	return r
 }
 
 func (c *controllerIml) toBidApi(bid *domain.Bid) *Bid {
 	if bid == nil {
 		return nil
 	}
-	return &Bid{
-		Id:           bid.Id,
-		Type:         bid.Type,
-		SrcAsset:     bid.SrcAsset,
-		TrgAsset:     bid.TrgAsset,
-		Rate:         bid.Rate,
-		ExchangeCode: bid.ExchangeCode,
-		Available:    bid.Available,
-		MinLimit:     bid.MinLimit,
-		MaxLimit:     bid.MaxLimit,
-		Methods:      bid.Methods,
-		UserId:       bid.UserId,
-		Link:         bid.Link,
+	return c.toBidsApi([]*domain.Bid{bid})[0]
+}
+
+func (c *controllerIml) toBidsListApi(bids []*domain.Bid) *Bids {
+	r := &Bids{Bids: []*Bid{}}
+	r.Bids = append(r.Bids, c.toBidsApi(bids)...)
+	return r
+}
+
+func (c *controllerIml) toRateHistoryApi(rq *domain.GetRateHistoryRequest, candles []*domain.RateCandle) *RateHistory {
+	r := &RateHistory{
+		Src:           rq.SrcAsset,
+		Trg:           rq.TrgAsset,
+		ResolutionSec: int(rq.Resolution.Seconds()),
+		Candles:       []*RateCandle{},
+	}
+	for _, cd := range candles {
+		r.Candles = append(r.Candles, &RateCandle{
+			Time:       cd.Time,
+			Open:       cd.Open,
+			High:       cd.High,
+			Low:        cd.Low,
+			Close:      cd.Close,
+			MedianRate: cd.MedianRate,
+			Volume:     cd.Volume,
+		})
+	}
+	return r
+}
+
+func (c *controllerIml) toPairRateApi(r *domain.PairRate) *PairRate {
+	return &PairRate{
+		ExchangeCode: r.ExchangeCode,
+		BestRate:     r.BestRate,
+		Volume:       r.Volume,
+		Bids:         r.Bids,
+	}
+}
+
+func (c *controllerIml) toMarketOverviewApi(o *domain.MarketOverview) *MarketOverview {
+	r := &MarketOverview{
+		Assets:    o.Assets,
+		Exchanges: o.Exchanges,
+		Pairs:     []*PairOverview{},
 	}
+	for _, p := range o.Pairs {
+		pair := &PairOverview{
+			Src:     p.SrcAsset,
+			Trg:     p.TrgAsset,
+			Overall: c.toPairRateApi(p.Overall),
+		}
+		for _, e := range p.Exchanges {
+			pair.Exchanges = append(pair.Exchanges, c.toPairRateApi(e))
+		}
+		r.Pairs = append(r.Pairs, pair)
+	}
+	return r
+}
+
+func (c *controllerIml) toSpreadApi(sp *domain.Spread) *Spread {
+	if sp == nil {
+		return nil
+	}
+	r := &Spread{
+		Id:            sp.Id,
+		Type:          sp.Type,
+		BaseAsset:     sp.BaseAsset,
+		QuoteAsset:    sp.QuoteAsset,
+		BuyPrice:      sp.BuyPrice,
+		SellPrice:     sp.SellPrice,
+		Spread:        (sp.SpreadShare - 1) * 100,
+		Volume:        sp.Volume,
+		Profit:        sp.Profit,
+		ExchangeCodes: sp.ExchangeCodes,
+		Methods:       sp.Methods,
+		CreatedAt:     sp.CreatedAt,
+		ObservedAt:    c.timeToApi(sp.ObservedAt),
+		ExpiresAt:     c.timeToApi(sp.ExpiresAt),
+	}
+	if sp.Buy != nil && sp.Sell != nil {
+		legs := c.toBidsApi([]*domain.Bid{sp.Buy, sp.Sell})
+		r.Buy, r.Sell = legs[0], legs[1]
+	}
+	return r
+}
+
+func (c *controllerIml) toSpreadsPageApi(rs *domain.GetSpreadsResponse) *Spreads {
+	r := &Spreads{
+		Spreads: []*Spread{},
+		Total:   rs.Total,
+		Index:   rs.Index,
+	}
+	for _, sp := range rs.Spreads {
+		r.Spreads = append(r.Spreads, c.toSpreadApi(sp))
+	}
+	return r
+}
+
+func (c *controllerIml) toReferenceRatesApi(rates *domain.ReferenceRates) *ReferenceRates {
+	return &ReferenceRates{
+		Base:      rates.Base,
+		Rates:     rates.Rates,
+		UpdatedAt: c.timeToApi(rates.UpdatedAt),
+	}
+}
+
+func (c *controllerIml) toOutboxDeliveryApi(d *domain.OutboxDelivery) *OutboxDelivery {
+	r := &OutboxDelivery{
+		Id:              d.Id,
+		SubscriptionId:  d.SubscriptionId,
+		UserId:          d.UserId,
+		OpportunityType: d.OpportunityType,
+		OpportunityId:   d.OpportunityId,
+		Status:          d.Status,
+		Attempts:        d.Attempts,
+		NextAttemptAt:   c.timeToApi(d.NextAttemptAt),
+		LastError:       d.LastError,
+		DeliveredAt:     d.DeliveredAt,
+		CreatedAt:       d.CreatedAt,
+		UpdatedAt:       d.UpdatedAt,
+	}
+	if d.Notification != nil {
+		if nn := c.toSubscriptionNotificationsApi([]*domain.SubscriptionNotification{d.Notification}); len(nn) > 0 {
+			r.Notification = nn[0]
+		}
+	}
+	return r
+}
+
+func (c *controllerIml) toOutboxDeliveriesPageApi(rs *domain.SearchOutboxResponse) *OutboxDeliveries {
+	r := &OutboxDeliveries{
+		Deliveries: []*OutboxDelivery{},
+		Total:      rs.Total,
+		Index:      rs.Index,
+	}
+	for _, d := range rs.Deliveries {
+		r.Deliveries = append(r.Deliveries, c.toOutboxDeliveryApi(d))
+	}
+	return r
+}
+
+func (c *controllerIml) toOutboxDeliveriesListApi(dd []*domain.OutboxDelivery) *OutboxDeliveriesList {
+	r := &OutboxDeliveriesList{
+		Deliveries: []*OutboxDelivery{},
+	}
+	for _, d := range dd {
+		r.Deliveries = append(r.Deliveries, c.toOutboxDeliveryApi(d))
+	}
+	return r
 }
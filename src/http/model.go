@@ -6,35 +6,54 @@ import (
 
 // Bid is a bid exposed on the exchange
 type Bid struct {
-	Id           string   `json:"id"`           // Id
-	Type         string   `json:"type"`         // Type
-	SrcAsset     string   `json:"src"`          // SrcAsset - source asset
-	TrgAsset     string   `json:"trg"`          // TrgAsset - target asset
-	Rate         float64  `json:"rate"`         // Rate - conversion rate
-	ExchangeCode string   `json:"exchangeCode"` // ExchangeCode - exchange code
-	Available    float64  `json:"available"`    // Available - available volume
-	MinLimit     float64  `json:"minLimit"`     // MinLimit - min limit
-	MaxLimit     float64  `json:"maxLimit"`     // MaxLimit - max limit
-	Methods      []string `json:"methods"`      // Methods - methods
-	UserId       string   `json:"userId"`       // UserId - user who exposes the bid
-	Link         string   `json:"link"`         // Link - link to the bid on the exchange
+	Id           string     `json:"id"`                   // Id
+	Type         string     `json:"type"`                 // Type
+	SrcAsset     string     `json:"src"`                  // SrcAsset - source asset
+	TrgAsset     string     `json:"trg"`                  // TrgAsset - target asset
+	Rate         float64    `json:"rate"`                 // Rate - conversion rate
+	ExchangeCode string     `json:"exchangeCode"`         // ExchangeCode - exchange code
+	Available    float64    `json:"available"`            // Available - available volume
+	MinLimit     float64    `json:"minLimit"`             // MinLimit - min limit
+	MaxLimit     float64    `json:"maxLimit"`             // MaxLimit - max limit
+	Methods      []string   `json:"methods"`              // Methods - methods
+	UserId       string     `json:"userId"`               // UserId - user who exposes the bid
+	Link         string     `json:"link"`                 // Link - link to the bid on the exchange
+	ObservedAt   *time.Time `json:"observedAt,omitempty"` // ObservedAt - when the bid has been observed on the source
+	IngestedAt   *time.Time `json:"ingestedAt,omitempty"` // IngestedAt - when the bid has been put to the storage
+	AgeSec       *int64     `json:"ageSec,omitempty"`     // AgeSec - how old the bid quote is (in seconds) by the moment of response
+	OwnerId      string     `json:"ownerId,omitempty"`    // OwnerId - user who manages the manual bid
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`  // ExpiresAt - when the bid expires
+	Private      bool       `json:"private,omitempty"`    // Private - if true, the bid is taken into account only in calculations of the owner
 }
 
 // ProfitableChain is a sequence of orders to be exposed to achieve calculated profit
 type ProfitableChain struct {
-	Id            string    `json:"id"`             // Id - chain Id, calculated as hash from bidIds
-	Asset         string    `json:"asset"`          // Asset - the target asset
-	ProfitShare   float64   `json:"profitShare"`    // ProfitShare profit share
-	Methods       []string  `json:"methods"`        // Methods list of methods (union methods from all bids)
-	BidAssets     []string  `json:"bidAssets"`      // BidAssets sequence of asset for each bids like [RUB, USD, USDT]
-	Depth         int       `json:"depth"`          // Depth chain depth
-	ExchangeCodes []string  `json:"exchangeCodes"`  // ExchangeCodes through all bids
-	Bids          []*Bid    `json:"bids,omitempty"` // Bids sequence of bids
-	CreatedAt     time.Time `json:"createdAt"`      // CreatedAt - when this chain has been created
+	Id            string     `json:"id"`                     // Id - chain Id, calculated as hash from bidIds
+	Asset         string     `json:"asset"`                  // Asset - the target asset
+	ProfitShare   float64    `json:"profitShare"`            // ProfitShare profit share
+	Methods       []string   `json:"methods"`                // Methods list of methods (union methods from all bids)
+	BidAssets     []string   `json:"bidAssets"`              // BidAssets sequence of asset for each bids like [RUB, USD, USDT]
+	Depth         int        `json:"depth"`                  // Depth chain depth
+	ExchangeCodes []string   `json:"exchangeCodes"`          // ExchangeCodes through all bids
+	BidTypes      []string   `json:"bidTypes"`               // BidTypes distinct types of bids
+	Score         float64    `json:"score"`                  // Score profit (in percents) per one conversion
+	Volume        float64    `json:"volume"`                 // Volume - executable volume of the chain in the chain asset
+	BaseCurrency  string     `json:"baseCurrency,omitempty"` // BaseCurrency - currency the chain is normalized to, empty if reference rate is unknown
+	BaseVolume    float64    `json:"baseVolume,omitempty"`   // BaseVolume - volume in the base currency
+	BaseProfit    float64    `json:"baseProfit,omitempty"`   // BaseProfit - absolute profit in the base currency if the whole volume is traded
+	Bids          []*Bid     `json:"bids,omitempty"`         // Bids sequence of bids
+	ObservedAt    *time.Time `json:"observedAt,omitempty"`   // ObservedAt - when the oldest bid of the chain has been observed
+	CreatedAt     time.Time  `json:"createdAt"`              // CreatedAt - when this chain has been created
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`    // ExpiresAt - when this chain expires
+	Archived      bool       `json:"archived,omitempty"`     // Archived - if the chain has expired and retrieved from the archive
+	OwnerId       string     `json:"ownerId,omitempty"`      // OwnerId - user whose private bids the chain contains
 }
 
 type ProfitableChains struct {
-	Chains []*ProfitableChain `json:"chains"` // Chains
+	Chains     []*ProfitableChain `json:"chains"`               // Chains
+	Total      int                `json:"total"`                // Total number of chains satisfying criteria
+	Index      int                `json:"index"`                // Index page index
+	NextCursor string             `json:"nextCursor,omitempty"` // NextCursor cursor to retrieve the next page, empty if it's the last page
 }
 
 type LoginRequest struct {
@@ -81,26 +100,174 @@ type SubscriptionChainFilter struct {
 	Methods   []string `json:"methods,omitempty"`   // Methods filters by methods
 	Exchanges []string `json:"exchanges,omitempty"` // Exchanges filters by exchange codes
 	MaxDepth  int      `json:"maxDepth,omitempty"`  // MaxDepth max depth of chains
-	MinProfit float64  `json:"minProfit,omitempty"` // MinProfit min profit of chains
+	MinProfit float64  `json:"minProfit,omitempty"` // MinProfit min profit of chains, for spreads it's a min spread
+	// Opportunities types of opportunities (chain, spread) subscription is notified about. If empty, only chains are notified
+	Opportunities []string `json:"opportunities,omitempty"`
+	// Expression boolean expression an opportunity must satisfy in addition to the other criteria, e.g. profit >= 1.5 && !("bybit" in exchanges) && volumeUsd > 500
+	// variables: opportunity, asset, baseCurrency (strings); profit (percents), depth, score, volume, baseVolume, baseProfit, volumeUsd, profitUsd, ageSec (numbers);
+	// volumeUsd, profitUsd are available only if the base currency is USD;
+	// assets, exchanges, methods, bidTypes (lists of strings). Operators: && || ! == != < <= > >= + - * / in, function size()
+	Expression string `json:"expression,omitempty"`
 }
 
 // SubscriptionTelegramNotificationDetails details of telegram notification
 type SubscriptionTelegramNotificationDetails struct {
-	Channel int `json:"channel"` // Channel telegram channel
+	Channel  int    `json:"channel"`         // Channel telegram channel
+	Verified bool   `json:"verified"`        // Verified if the channel is verified, notifications to unverified channels are inactive
+	BotId    string `json:"botId,omitempty"` // BotId bot notifications are sent from, if empty, the bot of the user or the default one
+}
+
+// SubscriptionEmailNotificationDetails details of email notification
+type SubscriptionEmailNotificationDetails struct {
+	To       []string `json:"to"`                 // To recipients addresses
+	Verified bool     `json:"verified,omitempty"` // Verified if all the recipients are confirmed, notifications to unconfirmed addresses stay inactive (read only)
+}
+
+// SubscriptionWebhookNotificationDetails details of webhook notification
+type SubscriptionWebhookNotificationDetails struct {
+	Url    string `json:"url"`              // Url https endpoint payloads are posted to
+	Secret string `json:"secret,omitempty"` // Secret key payloads are signed with (HMAC-SHA256), generated if empty
+}
+
+// NotificationTemplate Go text/template templates of a message
+type NotificationTemplate struct {
+	Subject string `json:"subject,omitempty"` // Subject template of the message subject, supported by email only
+	Body    string `json:"body,omitempty"`    // Body template of the message body
+}
+
+// NotificationTemplates templates of a notification by delivery type, global or default templates of the channel are used if empty
+type NotificationTemplates struct {
+	Chain  *NotificationTemplate `json:"chain,omitempty"`  // Chain template of chain messages
+	Spread *NotificationTemplate `json:"spread,omitempty"` // Spread template of spread messages
+	Digest *NotificationTemplate `json:"digest,omitempty"` // Digest template of digest messages
 }
 
 // SubscriptionNotification notification details
 type SubscriptionNotification struct {
-	Id       string                                   `json:"id"`                 // Id notification id
-	Channel  string                                   `json:"channel"`            // Channel notification channel
-	IsActive bool                                     `json:"isActive"`           // IsActive if notification active
-	Telegram *SubscriptionTelegramNotificationDetails `json:"telegram,omitempty"` // Telegram telegram details
+	Id        string                                   `json:"id"`                  // Id notification id
+	Channel   string                                   `json:"channel"`             // Channel notification channel
+	IsActive  bool                                     `json:"isActive"`            // IsActive if notification active
+	Telegram  *SubscriptionTelegramNotificationDetails `json:"telegram,omitempty"`  // Telegram telegram details
+	Email     *SubscriptionEmailNotificationDetails    `json:"email,omitempty"`     // Email email details
+	Webhook   *SubscriptionWebhookNotificationDetails  `json:"webhook,omitempty"`   // Webhook webhook details
+	Templates *NotificationTemplates                   `json:"templates,omitempty"` // Templates custom message templates
 }
 
-// SubscriptionNotification notification details
+// SubscriptionNotificationRequest notification details
 type SubscriptionNotificationRequest struct {
-	TelegramChannel int  `json:"tgChannel"`
-	IsActive        bool `json:"isActive"`
+	Channel         string                                  `json:"channel,omitempty"`   // Channel notification channel (telegram, email, webhook), telegram if empty
+	TelegramChannel int                                     `json:"tgChannel,omitempty"` // TelegramChannel telegram channel
+	TelegramBotId   string                                  `json:"tgBotId,omitempty"`   // TelegramBotId bot telegram notifications are sent from, if empty, the bot of the user or the default one
+	Email           *SubscriptionEmailNotificationDetails   `json:"email,omitempty"`     // Email email details
+	Webhook         *SubscriptionWebhookNotificationDetails `json:"webhook,omitempty"`   // Webhook webhook details
+	Templates       *NotificationTemplates                  `json:"templates,omitempty"` // Templates custom message templates
+	IsActive        bool                                    `json:"isActive"`
+}
+
+// TemplatePreviewRequest request to render a template against a sample opportunity
+type TemplatePreviewRequest struct {
+	Channel  string                `json:"channel"`            // Channel notification channel (telegram, email, webhook)
+	Type     string                `json:"type"`               // Type delivery type (chain, spread, digest)
+	Template *NotificationTemplate `json:"template,omitempty"` // Template template to render, if empty, the template the channel uses by default is rendered
+	ChainId  string                `json:"chainId,omitempty"`  // ChainId chain to render, if empty, a sample chain is taken
+}
+
+// TemplatePreview rendered message
+type TemplatePreview struct {
+	Subject string `json:"subject,omitempty"` // Subject message subject
+	Body    string `json:"body"`              // Body message body
+}
+
+// TelegramLinkCode one-time code linking a telegram account, the user sends "/link <code>" to the bot or opens the url
+type TelegramLinkCode struct {
+	Code      string    `json:"code"`          // Code one-time code
+	Url       string    `json:"url,omitempty"` // Url deep link opening the bot with the code
+	ExpiresAt time.Time `json:"expiresAt"`     // ExpiresAt code expiration time
+}
+
+// TelegramLink linked telegram account
+type TelegramLink struct {
+	TelegramUserId int64     `json:"telegramUserId"`     // TelegramUserId telegram user id
+	Username       string    `json:"username,omitempty"` // Username telegram username
+	CreatedAt      time.Time `json:"createdAt"`          // CreatedAt when linked
+}
+
+// TelegramChannelVerification verification of the telegram channel
+// the user posts "/verify <code>" to the channel or adds the bot as an admin and confirms the verification
+type TelegramChannelVerification struct {
+	ChannelId  int64      `json:"channelId"`            // ChannelId telegram chat id of the channel
+	Code       string     `json:"code,omitempty"`       // Code one-time code to post to the channel, empty if verified
+	Status     string     `json:"status"`               // Status verification status (pending, verified)
+	Method     string     `json:"method,omitempty"`     // Method how the channel has been verified (code, admin, link)
+	ExpiresAt  time.Time  `json:"expiresAt"`            // ExpiresAt code expiration time
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty"` // VerifiedAt when verified
+}
+
+// EmailVerificationRequest request to send a confirmation code to the address
+type EmailVerificationRequest struct {
+	Email string `json:"email"` // Email recipient address
+}
+
+// EmailVerificationConfirmRequest request to confirm the address
+type EmailVerificationConfirmRequest struct {
+	Email string `json:"email"` // Email recipient address
+	Code  string `json:"code"`  // Code code sent to the address
+}
+
+// EmailVerification verification of the email recipient address
+// the code is sent to the address and never returned by API, the user confirms the address with the code
+type EmailVerification struct {
+	Email      string     `json:"email"`                // Email recipient address
+	Status     string     `json:"status"`               // Status verification status (pending, verified)
+	ExpiresAt  time.Time  `json:"expiresAt"`            // ExpiresAt code expiration time
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty"` // VerifiedAt when verified
+}
+
+// TelegramBotRequest request to register or update a telegram bot
+type TelegramBotRequest struct {
+	Name        string   `json:"name"`                  // Name bot name
+	Token       string   `json:"token,omitempty"`       // Token bot token, it's checked by Bot API. On update empty token keeps the current one
+	UserIds     []string `json:"userIds,omitempty"`     // UserIds users notified by the bot by default, if empty, the bot is shared and selected by subscriptions explicitly
+	SecondaryId string   `json:"secondaryId,omitempty"` // SecondaryId bot messages fail over to if the bot fails
+	RatePerSec  float64  `json:"ratePerSec,omitempty"`  // RatePerSec messages per second the bot sends to all chats, Bot API limit if empty
+	IsActive    bool     `json:"isActive"`              // IsActive if the bot is active
+}
+
+// TelegramBot telegram bot notifications are sent from, the token is never returned
+type TelegramBot struct {
+	Id          string     `json:"id"`                    // Id bot id, the default bot has id "default"
+	Name        string     `json:"name"`                  // Name bot name
+	Username    string     `json:"username,omitempty"`    // Username telegram username of the bot
+	UserIds     []string   `json:"userIds,omitempty"`     // UserIds users notified by the bot by default
+	SecondaryId string     `json:"secondaryId,omitempty"` // SecondaryId bot messages fail over to
+	RatePerSec  float64    `json:"ratePerSec,omitempty"`  // RatePerSec messages per second the bot sends to all chats
+	IsActive    bool       `json:"isActive"`              // IsActive if the bot is active
+	Health      string     `json:"health"`                // Health health status (healthy, unhealthy)
+	LastError   string     `json:"lastError,omitempty"`   // LastError the last error of the bot
+	FailedAt    *time.Time `json:"failedAt,omitempty"`    // FailedAt when the bot failed last time
+	CreatedAt   time.Time  `json:"createdAt"`             // CreatedAt when registered
+	UpdatedAt   time.Time  `json:"updatedAt"`             // UpdatedAt when updated
+}
+
+// SubscriptionQuietHours period of the day when notifications aren't sent
+type SubscriptionQuietHours struct {
+	From     string `json:"from"`               // From start of quiet hours (HH:MM)
+	To       string `json:"to"`                 // To end of quiet hours (HH:MM), if less than From, quiet hours cross midnight
+	Timezone string `json:"timezone,omitempty"` // Timezone IANA timezone of the subscriber (e.g. Europe/Moscow), UTC if empty
+}
+
+// SubscriptionDigest batches matched opportunities into a periodic summary
+type SubscriptionDigest struct {
+	PeriodMin int `json:"periodMin"`          // PeriodMin how often the digest is sent in minutes (5 - 1440)
+	MaxItems  int `json:"maxItems,omitempty"` // MaxItems max number of the most profitable opportunities in the digest, 10 if empty
+}
+
+// SubscriptionDeliveryPolicy rules applied to notifications of the subscription
+type SubscriptionDeliveryPolicy struct {
+	MaxMessages int                     `json:"maxMessages,omitempty"` // MaxMessages max number of messages per window, unlimited if empty
+	WindowSec   int                     `json:"windowSec,omitempty"`   // WindowSec throttling window in seconds (60 - 86400)
+	QuietHours  *SubscriptionQuietHours `json:"quietHours,omitempty"`  // QuietHours opportunities matched during quiet hours are dropped, digests are postponed
+	Digest      *SubscriptionDigest     `json:"digest,omitempty"`      // Digest if specified, opportunities are sent as periodic digests instead of a message per opportunity
 }
 
 // Subscription subscription
@@ -110,6 +277,7 @@ type Subscription struct {
 	IsActive      bool                        `json:"isActive"`                // IsActive if subscription active
 	Filter        *SubscriptionChainFilter    `json:"filter,omitempty"`        // Filter subscription filter
 	Notifications []*SubscriptionNotification `json:"notifications,omitempty"` // Notifications notifications
+	Policy        *SubscriptionDeliveryPolicy `json:"policy,omitempty"`        // Policy delivery policy
 }
 
 type Subscriptions struct {
@@ -119,19 +287,176 @@ type Subscriptions struct {
 type SubscriptionRequest struct {
 	Filter        *SubscriptionChainFilter           `json:"filter,omitempty"`        // Filter subscription filter
 	Notifications []*SubscriptionNotificationRequest `json:"notifications,omitempty"` // Notifications notifications
+	Policy        *SubscriptionDeliveryPolicy        `json:"policy,omitempty"`        // Policy delivery policy, all matched opportunities are sent immediately if empty
 }
 
-// Bid is a bid exposed on the exchange
-type BidRequest struct {
-	Id           string   `json:"id"`           // Id
-	SrcAsset     string   `json:"src"`          // SrcAsset - source asset
-	TrgAsset     string   `json:"trg"`          // TrgAsset - target asset
-	Rate         float64  `json:"rate"`         // Rate - conversion rate
-	ExchangeCode string   `json:"exchangeCode"` // ExchangeCode - exchange code
-	Available    float64  `json:"available"`    // Available available volume
-	MinLimit     float64  `json:"minLimit"`     // MinLimit - minimum limit
-	MaxLimit     float64  `json:"maxLimit"`     // MaxLimit - max limit
-	Methods      []string `json:"methods"`      // Methods - methods
-	UserId       string   `json:"userId"`       // UserId - user who expose the bid
-	Link         string   `json:"link"`         // Link - link to the bid
+// SubscriptionPreviewRequest request to backtest a subscription filter
+type SubscriptionPreviewRequest struct {
+	Filter  *SubscriptionChainFilter `json:"filter,omitempty"`  // Filter candidate filter
+	Hours   int                      `json:"hours,omitempty"`   // Hours how many last hours of the history are evaluated (1..168), 24 if empty
+	Samples int                      `json:"samples,omitempty"` // Samples max number of sample chains (1..50), 5 if empty
+}
+
+// SubscriptionPreviewHour matches within an hour
+type SubscriptionPreviewHour struct {
+	Hour    time.Time `json:"hour"`    // Hour start of the hour
+	Matches int       `json:"matches"` // Matches number of chains matching the filter found within the hour
+}
+
+// SubscriptionPreview result of the filter backtest, only chains are kept in the history, so spreads are never matched
+type SubscriptionPreview struct {
+	From           time.Time                  `json:"from"`                  // From start of the evaluated period
+	To             time.Time                  `json:"to"`                    // To end of the evaluated period
+	HistoryFrom    *time.Time                 `json:"historyFrom,omitempty"` // HistoryFrom when the oldest chain of the history has been found
+	Scanned        int                        `json:"scanned"`               // Scanned number of chains evaluated
+	Matches        int                        `json:"matches"`               // Matches number of chains matching the filter
+	Hours          []*SubscriptionPreviewHour `json:"hours"`                 // Hours matches by hours, the oldest hour goes first
+	Samples        []*ProfitableChain         `json:"samples"`               // Samples the most profitable matching chains
+	EstimatedDaily float64                    `json:"estimatedDaily"`        // EstimatedDaily estimated number of alerts per day, delivery policies aren't taken into account
+	Truncated      bool                       `json:"truncated,omitempty"`   // Truncated if the history has more chains than evaluated, so matches are lower bound
+}
+
+// ManualBidRequest request to create or update a manual bid
+type ManualBidRequest struct {
+	SrcAsset     string     `json:"src"`                  // SrcAsset - source asset
+	TrgAsset     string     `json:"trg"`                  // TrgAsset - target asset
+	Rate         float64    `json:"rate"`                 // Rate - conversion rate
+	ExchangeCode string     `json:"exchangeCode"`         // ExchangeCode - exchange code, might be a name of the OTC desk
+	Available    float64    `json:"available"`            // Available available volume
+	MinLimit     float64    `json:"minLimit"`             // MinLimit - minimum limit
+	MaxLimit     float64    `json:"maxLimit"`             // MaxLimit - max limit
+	Methods      []string   `json:"methods"`              // Methods - methods
+	Link         string     `json:"link"`                 // Link - link to the quote
+	ObservedAt   *time.Time `json:"observedAt,omitempty"` // ObservedAt - when the quote has been observed, if empty, the current time is taken
+	TtlSec       int        `json:"ttlSec,omitempty"`     // TtlSec - how long the bid lives (in seconds), if empty, the default ttl is taken
+	Private      bool       `json:"private,omitempty"`    // Private - if true, the bid is combined with the public market only in calculations of the owner
+}
+
+// ManualBidsRequest request to create manual bids in bulk
+type ManualBidsRequest struct {
+	Bids []*ManualBidRequest `json:"bids"` // Bids - bids
+}
+
+type Bids struct {
+	Bids []*Bid `json:"bids"` // Bids - bids
+}
+
+// ExchangeStaleness shows how fresh bids of the exchange are
+type ExchangeStaleness struct {
+	ExchangeCode     string     `json:"exchangeCode"`               // ExchangeCode - exchange code
+	Bids             int        `json:"bids"`                       // Bids - number of bids
+	StaleBids        int        `json:"staleBids"`                  // StaleBids - number of bids older than max age, they are ignored when finding chains
+	OldestObservedAt *time.Time `json:"oldestObservedAt,omitempty"` // OldestObservedAt - when the oldest bid has been observed
+	LatestObservedAt *time.Time `json:"latestObservedAt,omitempty"` // LatestObservedAt - when the latest bid has been observed
+	LatestAgeSec     *int64     `json:"latestAgeSec,omitempty"`     // LatestAgeSec - age of the latest bid in seconds
+}
+
+type ExchangesStaleness struct {
+	Exchanges []*ExchangeStaleness `json:"exchanges"` // Exchanges - staleness by exchanges
+}
+
+// RateCandle OHLC candle of the best rate
+type RateCandle struct {
+	Time       time.Time `json:"time"`       // Time - candle start
+	Open       float64   `json:"open"`       // Open - the best rate at the beginning of the candle
+	High       float64   `json:"high"`       // High - max of the best rate
+	Low        float64   `json:"low"`        // Low - min of the best rate
+	Close      float64   `json:"close"`      // Close - the best rate at the end of the candle
+	MedianRate float64   `json:"medianRate"` // MedianRate - median rate within the candle
+	Volume     float64   `json:"volume"`     // Volume - average available volume within the candle
+}
+
+// RateHistory rate history of the pair
+type RateHistory struct {
+	Src           string        `json:"src"`           // Src - source asset
+	Trg           string        `json:"trg"`           // Trg - target asset
+	ResolutionSec int           `json:"resolutionSec"` // ResolutionSec - candle duration in seconds
+	Candles       []*RateCandle `json:"candles"`       // Candles - candles ordered by time
+}
+
+// PairRate aggregated bids of the pair
+type PairRate struct {
+	ExchangeCode string  `json:"exchangeCode"` // ExchangeCode - exchange code, for the overall rate it's the exchange with the best rate
+	BestRate     float64 `json:"bestRate"`     // BestRate - the best rate
+	Volume       float64 `json:"volume"`       // Volume - total available volume
+	Bids         int     `json:"bids"`         // Bids - number of bids
+}
+
+// PairOverview best rates of the pair
+type PairOverview struct {
+	Src       string      `json:"src"`       // Src - source asset
+	Trg       string      `json:"trg"`       // Trg - target asset
+	Overall   *PairRate   `json:"overall"`   // Overall - aggregated across all exchanges
+	Exchanges []*PairRate `json:"exchanges"` // Exchanges - aggregated per exchange
+}
+
+// MarketOverview matrix of best rates of all pairs
+type MarketOverview struct {
+	Assets    []string        `json:"assets"`    // Assets - all assets
+	Exchanges []string        `json:"exchanges"` // Exchanges - all exchanges
+	Pairs     []*PairOverview `json:"pairs"`     // Pairs - pairs ordered by source and target assets
+}
+
+type ReferenceRates struct {
+	Base      string             `json:"base"`                // Base - base currency
+	Rates     map[string]float64 `json:"rates"`               // Rates - price of one unit of the asset in the base currency
+	UpdatedAt *time.Time         `json:"updatedAt,omitempty"` // UpdatedAt - when rates have been loaded, empty if rates aren't loaded yet
+}
+
+// Spread is a two-leg opportunity: buy the base asset for the quote asset and sell it back for more
+type Spread struct {
+	Id            string     `json:"id"`                   // Id - spread Id
+	Type          string     `json:"type"`                 // Type - spread type (cross-exchange, cross-method)
+	BaseAsset     string     `json:"baseAsset"`            // BaseAsset - asset which is bought and sold
+	QuoteAsset    string     `json:"quoteAsset"`           // QuoteAsset - asset the base asset is paid with
+	Buy           *Bid       `json:"buy"`                  // Buy - bid converting the quote asset to the base asset
+	Sell          *Bid       `json:"sell"`                 // Sell - bid converting the base asset to the quote asset
+	BuyPrice      float64    `json:"buyPrice"`             // BuyPrice - price of the base asset when buying
+	SellPrice     float64    `json:"sellPrice"`            // SellPrice - price of the base asset when selling
+	Spread        float64    `json:"spread"`               // Spread - spread in percents
+	Volume        float64    `json:"volume"`               // Volume - amount of the base asset which can be traded
+	Profit        float64    `json:"profit"`               // Profit - profit in the quote asset if the whole volume is traded
+	ExchangeCodes []string   `json:"exchangeCodes"`        // ExchangeCodes - exchanges of both legs
+	Methods       []string   `json:"methods"`              // Methods - methods of both legs
+	CreatedAt     time.Time  `json:"createdAt"`            // CreatedAt - when the spread has been detected
+	ObservedAt    *time.Time `json:"observedAt,omitempty"` // ObservedAt - when the oldest bid has been observed
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`  // ExpiresAt - when the spread expires
+}
+
+type Spreads struct {
+	Spreads []*Spread `json:"spreads"` // Spreads
+	Total   int       `json:"total"`   // Total number of spreads satisfying criteria
+	Index   int       `json:"index"`   // Index page index
+}
+
+// OutboxDelivery is a delivery of an opportunity to a notification channel
+type OutboxDelivery struct {
+	Id              string                    `json:"id"`                    // Id - delivery id
+	SubscriptionId  string                    `json:"subscriptionId"`        // SubscriptionId - subscription the delivery is made for
+	UserId          string                    `json:"userId"`                // UserId - owner of the subscription
+	Notification    *SubscriptionNotification `json:"notification"`          // Notification - notification the opportunity is delivered to
+	OpportunityType string                    `json:"opportunityType"`       // OpportunityType - type of the opportunity (chain, spread)
+	OpportunityId   string                    `json:"opportunityId"`         // OpportunityId - chain or spread id
+	Status          string                    `json:"status"`                // Status - delivery status (pending, sending, delivered, dead)
+	Attempts        int                       `json:"attempts"`              // Attempts - number of attempts made
+	NextAttemptAt   *time.Time                `json:"nextAttemptAt"`         // NextAttemptAt - when the delivery is attempted next
+	LastError       string                    `json:"lastError,omitempty"`   // LastError - error of the last failed attempt
+	DeliveredAt     *time.Time                `json:"deliveredAt,omitempty"` // DeliveredAt - when the delivery succeeded
+	CreatedAt       time.Time                 `json:"createdAt"`             // CreatedAt - when the delivery is recorded
+	UpdatedAt       time.Time                 `json:"updatedAt"`             // UpdatedAt - when the delivery is updated last time
+}
+
+type OutboxDeliveries struct {
+	Deliveries []*OutboxDelivery `json:"deliveries"` // Deliveries
+	Total      int               `json:"total"`      // Total number of deliveries satisfying criteria
+	Index      int               `json:"index"`      // Index page index
+}
+
+// OutboxReplayRequest request to replay dead deliveries
+type OutboxReplayRequest struct {
+	Ids []string `json:"ids"` // Ids - ids of dead deliveries
+}
+
+type OutboxDeliveriesList struct {
+	Deliveries []*OutboxDelivery `json:"deliveries"` // Deliveries
 }
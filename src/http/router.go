@@ -34,6 +34,7 @@ func (r *Router) Set() error {
 
 		// subscriptions
 		http.R("/api/users/{userId}/subscriptions", r.ctrl.CreateSubscription).POST(),
+		http.R("/api/users/{userId}/subscriptions/preview", r.ctrl.PreviewSubscription).POST(),
 		http.R("/api/users/{userId}/subscriptions/{subscriptionId}", r.ctrl.UpdateSubscription).PUT(),
 		http.R("/api/users/{userId}/subscriptions/{subscriptionId}", r.ctrl.DeleteSubscription).DELETE(),
 		http.R("/api/users/{userId}/subscriptions/{subscriptionId}", r.ctrl.GetSubscription).GET(),
@@ -41,10 +42,47 @@ func (r *Router) Set() error {
 
 		// arbitrage
 		http.R("/api/arbitrage/chains", r.ctrl.GetProfitableChains).GET().Authorize(impl.Resource(domain.AuthResArbitrageChainsAll, "r")),
+		http.R("/api/arbitrage/chains/search", r.ctrl.SearchChains).GET().Authorize(impl.Resource(domain.AuthResArbitrageChainsAll, "r")),
 		http.R("/api/arbitrage/chains/{chainId}/details", r.ctrl.GetProfitableChainDetails).GET().Authorize(impl.Resource(domain.AuthResArbitrageChainsAll, "r")),
+		http.R("/api/arbitrage/archive/chains/{chainId}", r.ctrl.GetArchivedChain).GET().Authorize(impl.Resource(domain.AuthResArbitrageChainsAll, "r")),
+		http.R("/api/arbitrage/spreads", r.ctrl.GetSpreads).GET().Authorize(impl.Resource(domain.AuthResArbitrageChainsAll, "r")),
+		http.R("/api/arbitrage/spreads/{spreadId}", r.ctrl.GetSpread).GET().Authorize(impl.Resource(domain.AuthResArbitrageChainsAll, "r")),
 
 		// bids
-		http.R("/api/arbitrage/bids", r.ctrl.PutBid).POST(),
+		http.R("/api/arbitrage/bids/staleness", r.ctrl.GetBidsStaleness).GET().Authorize(impl.Resource(domain.AuthResArbitrageChainsAll, "r")),
+		http.R("/api/arbitrage/bids", r.ctrl.CreateManualBid).POST().Authorize(impl.Resource(domain.AuthResArbitrageBidsMy, "w")),
+		http.R("/api/arbitrage/bids/bulk", r.ctrl.CreateManualBids).POST().Authorize(impl.Resource(domain.AuthResArbitrageBidsMy, "w")),
+		http.R("/api/arbitrage/bids", r.ctrl.GetManualBids).GET().Authorize(impl.Resource(domain.AuthResArbitrageBidsMy, "r")),
+		http.R("/api/arbitrage/bids/{bidId}", r.ctrl.GetManualBid).GET().Authorize(impl.Resource(domain.AuthResArbitrageBidsMy, "r")),
+		http.R("/api/arbitrage/bids/{bidId}", r.ctrl.UpdateManualBid).PUT().Authorize(impl.Resource(domain.AuthResArbitrageBidsMy, "w")),
+		http.R("/api/arbitrage/bids/{bidId}", r.ctrl.DeleteManualBid).DELETE().Authorize(impl.Resource(domain.AuthResArbitrageBidsMy, "d")),
+
+		// market
+		http.R("/api/market/overview", r.ctrl.GetMarketOverview).GET().Authorize(impl.Resource(domain.AuthResMarketAll, "r")),
+		http.R("/api/market/reference-rates", r.ctrl.GetReferenceRates).GET().Authorize(impl.Resource(domain.AuthResMarketAll, "r")),
+		http.R("/api/market/pairs/{src}/{trg}/history", r.ctrl.GetRateHistory).GET().Authorize(impl.Resource(domain.AuthResMarketAll, "r")),
+
+		// notifications
+		http.R("/api/notifications/outbox", r.ctrl.GetOutboxDeliveries).GET().Authorize(impl.Resource(domain.AuthResNotificationsAll, "r")),
+		http.R("/api/notifications/outbox/replay", r.ctrl.ReplayOutboxDeliveries).POST().Authorize(impl.Resource(domain.AuthResNotificationsAll, "w")),
+		http.R("/api/notifications/templates/preview", r.ctrl.PreviewNotificationTemplate).POST().Authorize(impl.Resource(domain.AuthResNotificationTpls, "r")),
+		http.R("/api/notifications/telegram/bots", r.ctrl.GetTelegramBots).GET().Authorize(impl.Resource(domain.AuthResNotificationsAll, "r")),
+		http.R("/api/notifications/telegram/bots", r.ctrl.CreateTelegramBot).POST().Authorize(impl.Resource(domain.AuthResNotificationsAll, "w")),
+		http.R("/api/notifications/telegram/bots/{botId}", r.ctrl.GetTelegramBot).GET().Authorize(impl.Resource(domain.AuthResNotificationsAll, "r")),
+		http.R("/api/notifications/telegram/bots/{botId}", r.ctrl.UpdateTelegramBot).PUT().Authorize(impl.Resource(domain.AuthResNotificationsAll, "w")),
+		http.R("/api/notifications/telegram/bots/{botId}", r.ctrl.DeleteTelegramBot).DELETE().Authorize(impl.Resource(domain.AuthResNotificationsAll, "d")),
+
+		// telegram
+		http.R("/api/users/{userId}/telegram/link", r.ctrl.CreateTelegramLinkCode).POST(),
+		http.R("/api/users/{userId}/telegram", r.ctrl.GetTelegramLink).GET(),
+		http.R("/api/users/{userId}/telegram", r.ctrl.DeleteTelegramLink).DELETE(),
+		http.R("/api/users/{userId}/telegram/channels", r.ctrl.GetTelegramChannels).GET(),
+		http.R("/api/users/{userId}/telegram/channels/{channelId}/verification", r.ctrl.RequestTelegramChannelVerification).POST(),
+		http.R("/api/users/{userId}/telegram/channels/{channelId}/verification/confirm", r.ctrl.ConfirmTelegramChannelVerification).POST(),
+		http.R("/api/telegram/webhook", r.ctrl.TelegramWebhook).POST().NoAuth(),
+		http.R("/api/users/{userId}/email/addresses", r.ctrl.GetEmailAddresses).GET(),
+		http.R("/api/users/{userId}/email/addresses/verification", r.ctrl.RequestEmailVerification).POST(),
+		http.R("/api/users/{userId}/email/addresses/verification/confirm", r.ctrl.ConfirmEmailVerification).POST(),
 
 		// swagger
 		http.R("", nil).PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler),
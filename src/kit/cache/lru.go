@@ -0,0 +1,72 @@
+package memcache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU is a memory cache keeping a limited number of items, the least recently used item is evicted when the limit is reached
+type LRU interface {
+	// Get retrieves item by key
+	Get(key string) (interface{}, bool)
+	// Set sets item with key
+	Set(key string, v interface{})
+	// Len returns number of items
+	Len() int
+}
+
+type lruItem struct {
+	key string
+	v   interface{}
+}
+
+type lruImpl struct {
+	sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+// NewLRU creates LRU cache keeping up to size items
+func NewLRU(size int) LRU {
+	if size <= 0 {
+		size = 1
+	}
+	return &lruImpl{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *lruImpl) Get(key string) (interface{}, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*lruItem).v, true
+	}
+	return nil, false
+}
+
+func (c *lruImpl) Set(key string, v interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if e, ok := c.items[key]; ok {
+		e.Value.(*lruItem).v = v
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruItem{key: key, v: v})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+}
+
+func (c *lruImpl) Len() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,30 @@
+package memcache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_LRU_Evicted(t *testing.T) {
+	c := NewLRU(2)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	// a is used recently, so b is evicted
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	c.Set("c", 3)
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+
+	// update doesn't grow the cache
+	c.Set("c", 4)
+	v, _ = c.Get("c")
+	assert.Equal(t, 4, v)
+	assert.Equal(t, 2, c.Len())
+}
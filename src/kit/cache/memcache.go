@@ -23,6 +23,8 @@ type MemCache interface {
 	Set(key string, v interface{}, ttl time.Duration)
 	// Delete deletes key
 	Delete(key string)
+	// Items retrieves all not expired items
+	Items() map[string]interface{}
 }
 
 func NewMemCache() MemCache {
@@ -44,3 +46,12 @@ func (c *cacheImpl) Set(key string, v interface{}, ttl time.Duration) {
 func (c *cacheImpl) Get(key string) (interface{}, bool) {
 	return c.cache.Get(key)
 }
+
+func (c *cacheImpl) Items() map[string]interface{} {
+	items := c.cache.Items()
+	r := make(map[string]interface{}, len(items))
+	for k, v := range items {
+		r[k] = v.Object
+	}
+	return r
+}
@@ -16,6 +16,7 @@ const (
 	CallerTypeQueue  = "queue"
 	CallerTypeWs     = "ws"
 	CallerTypeWebRtc = "webrtc"
+	CallerTypeGrpc   = "grpc"
 )
 
 type requestContextKey struct{}
@@ -112,6 +113,11 @@ func (r *RequestContext) Ws() *RequestContext {
 	return r
 }
 
+func (r *RequestContext) Grpc() *RequestContext {
+	r.Caller = CallerTypeGrpc
+	return r
+}
+
 func (r *RequestContext) WithCaller(caller string) *RequestContext {
 	r.Caller = caller
 	return r
@@ -0,0 +1,31 @@
+package kit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Encrypt_Decrypt(t *testing.T) {
+	encrypted, err := Encrypt("key", "123456:secret")
+	assert.NoError(t, err)
+	assert.NotContains(t, encrypted, "secret")
+	// nonce is random, so the same text is encrypted differently
+	other, err := Encrypt("key", "123456:secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, encrypted, other)
+
+	text, err := Decrypt("key", encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456:secret", text)
+}
+
+func Test_Decrypt_Fail(t *testing.T) {
+	encrypted, err := Encrypt("key", "text")
+	assert.NoError(t, err)
+	_, err = Decrypt("wrong", encrypted)
+	assert.Error(t, err)
+	_, err = Decrypt("key", "not base64")
+	assert.Error(t, err)
+	_, err = Decrypt("key", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Config smtp server
+type Config struct {
+	Host     string // Host smtp host
+	Port     string // Port smtp port
+	User     string // User if empty, no authentication
+	Password string // Password smtp password
+}
+
+// Message plain text email message
+type Message struct {
+	From    string   // From sender address
+	To      []string // To recipients addresses
+	Subject string   // Subject message subject
+	Body    string   // Body plain text body
+}
+
+// Email sends emails through smtp server
+type Email interface {
+	// Send sends a message
+	Send(ctx context.Context, msg *Message) error
+}
+
+type emailImpl struct {
+	logger log.CLoggerFunc
+	cfg    *Config
+}
+
+func NewEmail(logger log.CLoggerFunc, cfg *Config) Email {
+	return &emailImpl{
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+func (e *emailImpl) l() log.CLogger {
+	return e.logger().Cmp("email")
+}
+
+// build builds RFC 5322 message, lines are terminated with CRLF
+func (e *emailImpl) build(msg *Message, now time.Time) []byte {
+	b := bytes.Buffer{}
+	b.WriteString(fmt.Sprintf("From: %s\r\n", msg.From))
+	b.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(msg.To, ", ")))
+	b.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject)))
+	b.WriteString(fmt.Sprintf("Date: %s\r\n", now.Format(time.RFC1123Z)))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return b.Bytes()
+}
+
+func (e *emailImpl) Send(ctx context.Context, msg *Message) error {
+	l := e.l().C(ctx).Mth("send").F(log.FF{"to": msg.To}).Trc(msg.Subject)
+
+	// check config
+	if e.cfg == nil || e.cfg.Host == "" {
+		return ErrEmailHostEmpty(ctx)
+	}
+	if len(msg.To) == 0 {
+		return ErrEmailRecipientEmpty(ctx)
+	}
+
+	var auth smtp.Auth
+	if e.cfg.User != "" {
+		auth = smtp.PlainAuth("", e.cfg.User, e.cfg.Password, e.cfg.Host)
+	}
+
+	if err := smtp.SendMail(net.JoinHostPort(e.cfg.Host, e.cfg.Port), auth, msg.From, msg.To, e.build(msg, time.Now())); err != nil {
+		return ErrEmailSendFailed(ctx, err)
+	}
+	l.Dbg("ok")
+	return nil
+}
@@ -0,0 +1,73 @@
+package email
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+var logger = log.Init(&log.Config{Level: log.InfoLevel})
+var logf = func() log.CLogger {
+	return log.L(logger)
+}
+
+type emailTestSuite struct {
+	kitTestSuite.Suite
+	smtp *TestSmtpServer
+}
+
+func (s *emailTestSuite) SetupSuite() {
+	s.Suite.Init(logf)
+}
+
+func TestEmailSuite(t *testing.T) {
+	suite.Run(t, new(emailTestSuite))
+}
+
+func (s *emailTestSuite) SetupTest() {
+	var err error
+	s.smtp, err = NewTestSmtpServer()
+	s.NoError(err)
+}
+
+func (s *emailTestSuite) TearDownTest() {
+	s.smtp.Close()
+}
+
+func (s *emailTestSuite) Test_Send() {
+	cfg := s.smtp.Config()
+	cfg.User, cfg.Password = "user", "password"
+	svc := NewEmail(logf, cfg)
+	err := svc.Send(s.Ctx, &Message{
+		From:    "noreply@cryptocare.ai",
+		To:      []string{"a@example.com", "b@example.com"},
+		Subject: "Profit 25% на RUB",
+		Body:    "line1\nline2",
+	})
+	s.NoError(err)
+	msgs := s.smtp.Messages()
+	s.Len(msgs, 1)
+	s.Equal("user", msgs[0].User)
+	s.Equal("noreply@cryptocare.ai", msgs[0].From)
+	s.Equal([]string{"a@example.com", "b@example.com"}, msgs[0].To)
+	s.Contains(msgs[0].Data, "To: a@example.com, b@example.com\n")
+	s.Contains(msgs[0].Data, "Subject: =?utf-8?q?")
+	s.Contains(msgs[0].Data, "\nline1\nline2")
+}
+
+func (s *emailTestSuite) Test_Send_Invalid() {
+	err := NewEmail(logf, &Config{}).Send(s.Ctx, &Message{To: []string{"a@example.com"}})
+	s.AssertAppErr(err, ErrCodeEmailHostEmpty)
+	err = NewEmail(logf, s.smtp.Config()).Send(s.Ctx, &Message{})
+	s.AssertAppErr(err, ErrCodeEmailRecipientEmpty)
+}
+
+func (s *emailTestSuite) Test_Send_ServerDown() {
+	cfg := s.smtp.Config()
+	s.smtp.Close()
+	err := NewEmail(logf, cfg).Send(s.Ctx, &Message{From: "a@example.com", To: []string{"b@example.com"}})
+	s.AssertAppErr(err, ErrCodeEmailSendFailed)
+	// reopen, so tear down doesn't fail
+	s.smtp, _ = NewTestSmtpServer()
+}
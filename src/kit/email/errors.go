@@ -0,0 +1,24 @@
+package email
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
+)
+
+var (
+	ErrCodeEmailHostEmpty      = "EML-001"
+	ErrCodeEmailRecipientEmpty = "EML-002"
+	ErrCodeEmailSendFailed     = "EML-003"
+)
+
+var (
+	ErrEmailHostEmpty = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeEmailHostEmpty, "smtp host empty").Business().C(ctx).Err()
+	}
+	ErrEmailRecipientEmpty = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeEmailRecipientEmpty, "recipient empty").Business().C(ctx).Err()
+	}
+	ErrEmailSendFailed = func(ctx context.Context, cause error) error {
+		return er.WrapWithBuilder(cause, ErrCodeEmailSendFailed, "").C(ctx).Err()
+	}
+)
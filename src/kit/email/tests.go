@@ -0,0 +1,128 @@
+package email
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// TestSmtpMessage message received by the test smtp server
+type TestSmtpMessage struct {
+	User string   // User authenticated user, empty if no authentication
+	From string   // From envelope sender
+	To   []string // To envelope recipients
+	Data string   // Data raw message with headers
+}
+
+// TestSmtpServer is a local SMTP stand-in capturing received messages
+// it supports the minimal set of commands net/smtp client sends, no TLS
+type TestSmtpServer struct {
+	sync.Mutex
+	listener net.Listener
+	messages []*TestSmtpMessage
+	wg       sync.WaitGroup
+}
+
+// NewTestSmtpServer starts server on a random local port
+func NewTestSmtpServer() (*TestSmtpServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &TestSmtpServer{listener: listener}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Config returns config to connect to the server
+func (s *TestSmtpServer) Config() *Config {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return &Config{Host: host, Port: port}
+}
+
+// Messages returns received messages
+func (s *TestSmtpServer) Messages() []*TestSmtpMessage {
+	s.Lock()
+	defer s.Unlock()
+	return append([]*TestSmtpMessage{}, s.messages...)
+}
+
+// Close stops server
+func (s *TestSmtpServer) Close() {
+	_ = s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *TestSmtpServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() { _ = conn.Close() }()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *TestSmtpServer) handle(conn net.Conn) {
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP test")
+	msg := &TestSmtpMessage{}
+	var user string
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			_ = tp.PrintfLine("250-localhost")
+			_ = tp.PrintfLine("250 AUTH PLAIN")
+		case strings.HasPrefix(cmd, "HELO"):
+			_ = tp.PrintfLine("250 localhost")
+		case strings.HasPrefix(cmd, "AUTH PLAIN"):
+			// \x00user\x00password
+			if fields := strings.Fields(line); len(fields) == 3 {
+				if creds, err := base64.StdEncoding.DecodeString(fields[2]); err == nil {
+					if parts := strings.Split(string(creds), "\x00"); len(parts) == 3 {
+						user = parts[1]
+					}
+				}
+			}
+			_ = tp.PrintfLine("235 authenticated")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg = &TestSmtpMessage{User: user, From: strings.Trim(line[len("MAIL FROM:"):], "<> ")}
+			_ = tp.PrintfLine("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			msg.To = append(msg.To, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			_ = tp.PrintfLine("250 ok")
+		case cmd == "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := ioutil.ReadAll(bufio.NewReader(tp.DotReader()))
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+			s.Lock()
+			s.messages = append(s.messages, msg)
+			s.Unlock()
+			_ = tp.PrintfLine("250 accepted")
+		case cmd == "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 ok")
+		}
+	}
+}
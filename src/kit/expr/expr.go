@@ -0,0 +1,114 @@
+// Package expr implements a small CEL-like language of boolean expressions over typed variables
+//
+// Supported syntax:
+//
+//	literals:    1.5, 1e3, "text", 'text', true, false, ["a", "b"], [1, 2]
+//	logical:     &&, ||, !
+//	comparison:  ==, !=, <, <=, >, >= (numbers and strings, equality also for booleans)
+//	arithmetic:  +, -, *, / (numbers)
+//	membership:  x in list (string in list of strings, number in list of numbers)
+//	functions:   size(list or string)
+//	grouping:    ( )
+//
+// Expressions are type checked on compilation against declared variables, so a compiled program fails at runtime
+// only if the values don't correspond to the declarations
+package expr
+
+import (
+	"fmt"
+)
+
+// Type is a type of value
+type Type int
+
+const (
+	Bool Type = iota + 1
+	Number
+	String
+	StringList
+	NumberList
+)
+
+func (t Type) String() string {
+	switch t {
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case StringList:
+		return "list of strings"
+	case NumberList:
+		return "list of numbers"
+	}
+	return "unknown"
+}
+
+// Vars declares variables available in expressions
+type Vars map[string]Type
+
+// Values values of variables
+// Go types of values: bool for Bool, float64 for Number, string for String, []string for StringList, []float64 for NumberList
+type Values map[string]interface{}
+
+// Error is a compilation or evaluation error
+type Error struct {
+	Pos int    // Pos - 1-based position in the expression, 0 if not applicable
+	Msg string // Msg - description of the error
+}
+
+func (e *Error) Error() string {
+	if e.Pos == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Program is a compiled expression, it's safe for concurrent use
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile parses the expression and checks it against the declared variables
+// the expression must evaluate to a boolean, returned error is *Error
+func Compile(source string, vars Vars) (*Program, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, vars: vars}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if root.typ() != Bool {
+		return nil, errorf(1, "expression must evaluate to a bool, got %s", root.typ())
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+// Source returns the source expression
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Eval evaluates the program with the given values
+func (p *Program) Eval(values Values) (res bool, err error) {
+	// evaluation panics only if values don't correspond to declarations
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(*Error); ok {
+				res, err = false, e
+				return
+			}
+			panic(r)
+		}
+	}()
+	return p.root.eval(values).(bool), nil
+}
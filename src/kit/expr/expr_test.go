@@ -0,0 +1,105 @@
+package expr
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var testVars = Vars{
+	"profit":    Number,
+	"asset":     String,
+	"exchanges": StringList,
+	"depths":    NumberList,
+	"private":   Bool,
+}
+
+var testValues = Values{
+	"profit":    1.7,
+	"asset":     "USDT",
+	"exchanges": []string{"binance", "garantex"},
+	"depths":    []float64{2, 3},
+	"private":   false,
+}
+
+func Test_Eval(t *testing.T) {
+	for _, c := range []struct {
+		Expr string
+		Res  bool
+	}{
+		{`profit >= 1.5 && !("bybit" in exchanges)`, true},
+		{`profit >= 1.5 && "binance" in exchanges`, true},
+		{`profit > 2 || asset == "USDT"`, true},
+		{`profit > 2 || asset != 'USDT'`, false},
+		{`asset in ["BTC", "ETH"]`, false},
+		{`3 in depths && !private`, true},
+		{`size(exchanges) <= 1`, false},
+		{`size(asset) == 4`, true},
+		{`profit * 100 - 20 == 150`, true},
+		{`-profit < 0 && 1e3 > 999.5`, true},
+		{`(profit > 1 || private) && private == false`, true},
+		{`true && !true`, false},
+		{`asset >= "BTC"`, true},
+		{`1 + 2 * 3 == 7`, true},
+		{`"a\"b" == 'a"b'`, true},
+	} {
+		p, err := Compile(c.Expr, testVars)
+		if !assert.NoError(t, err, c.Expr) {
+			continue
+		}
+		res, err := p.Eval(testValues)
+		assert.NoError(t, err, c.Expr)
+		assert.Equal(t, c.Res, res, c.Expr)
+	}
+}
+
+func Test_Compile_Errors(t *testing.T) {
+	for _, c := range []struct {
+		Expr string
+		Err  string
+	}{
+		{``, "expression is empty at position 1"},
+		{`profit >= 1.5 && volume > 500`, "unknown variable 'volume' at position 18"},
+		{`profit >= "1.5"`, "operator '>=' can't compare number and string at position 8"},
+		{`profit + 1`, "expression must evaluate to a bool, got number at position 1"},
+		{`profit > 1 &&`, "unexpected end of expression at position 14"},
+		{`(profit > 1`, "expected ')', got end of expression at position 12"},
+		{`profit > 1)`, "unexpected ')' at position 11"},
+		{`asset == "USDT`, "unterminated string at position 10"},
+		{`profit > 1 # 2`, "unexpected character '#' at position 12"},
+		{`"bybit" in asset`, "operator 'in' expects a string in a list of strings or a number in a list of numbers, got string in string at position 9"},
+		{`asset in [1, "a"]`, "list items must be of the same type, got number and string at position 14"},
+		{`asset in []`, "list is empty at position 10"},
+		{`!profit`, "operator '!' expects a bool operand, got number at position 1"},
+		{`private && profit`, "operator '&&' expects bool operands, got bool and number at position 9"},
+		{`max(profit) > 1`, "unknown function 'max' at position 1"},
+		{`size(profit) > 1`, "function 'size' expects a string or a list, got number at position 6"},
+		{`1 < profit < 2`, "unexpected '<', use parentheses to combine comparisons at position 12"},
+		{`profit > 1.2.3`, "invalid number '1.2.3' at position 10"},
+		{`exchanges == exchanges`, "operator '==' can't compare list of strings and list of strings at position 11"},
+	} {
+		_, err := Compile(c.Expr, testVars)
+		if assert.Error(t, err, c.Expr) {
+			assert.Equal(t, c.Err, err.Error(), c.Expr)
+			_, ok := err.(*Error)
+			assert.True(t, ok)
+		}
+	}
+}
+
+func Test_Compile_TooDeep(t *testing.T) {
+	src := ""
+	for i := 0; i < maxNesting+1; i++ {
+		src += "!"
+	}
+	_, err := Compile(src+"private", testVars)
+	assert.EqualError(t, err, "expression is nested too deeply at position 65")
+}
+
+func Test_Eval_WhenValueInvalid_Err(t *testing.T) {
+	p, err := Compile(`profit > 1`, testVars)
+	assert.NoError(t, err)
+	_, err = p.Eval(Values{})
+	assert.EqualError(t, err, "variable 'profit' isn't set at position 1")
+	_, err = p.Eval(Values{"profit": 1})
+	assert.EqualError(t, err, "variable 'profit' must be number, got int at position 1")
+}
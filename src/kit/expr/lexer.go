@@ -0,0 +1,134 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+// token is a lexeme of the expression, pos is 1-based position of its first character
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+func (t *token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("'%s'", t.text)
+}
+
+// operators sorted so that longer ones are matched first
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "!", "<", ">", "+", "-", "*", "/", "(", ")", "[", "]", ","}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func tokenize(src string) ([]*token, error) {
+	var tokens []*token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			start := i
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			// exponent
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				j := i + 1
+				if j < len(src) && (src[j] == '+' || src[j] == '-') {
+					j++
+				}
+				if j < len(src) && isDigit(src[j]) {
+					for i = j; i < len(src) && isDigit(src[i]); i++ {
+					}
+				}
+			}
+			if i < len(src) && isIdentStart(src[i]) {
+				return nil, errorf(start+1, "invalid number '%s'", src[start:i+1])
+			}
+			n, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, errorf(start+1, "invalid number '%s'", src[start:i])
+			}
+			tokens = append(tokens, &token{kind: tokNumber, text: src[start:i], num: n, pos: start + 1})
+		case c == '"' || c == '\'':
+			start := i
+			var sb strings.Builder
+			closed := false
+			for i++; i < len(src); i++ {
+				if src[i] == c {
+					closed = true
+					i++
+					break
+				}
+				if src[i] == '\\' {
+					if i+1 >= len(src) {
+						break
+					}
+					i++
+					switch src[i] {
+					case 'n':
+						sb.WriteByte('\n')
+					case 't':
+						sb.WriteByte('\t')
+					case '\\', '"', '\'':
+						sb.WriteByte(src[i])
+					default:
+						return nil, errorf(i, "unknown escape sequence '\\%c'", src[i])
+					}
+					continue
+				}
+				sb.WriteByte(src[i])
+			}
+			if !closed {
+				return nil, errorf(start+1, "unterminated string")
+			}
+			tokens = append(tokens, &token{kind: tokString, text: sb.String(), pos: start + 1})
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && (isIdentStart(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, &token{kind: tokIdent, text: src[start:i], pos: start + 1})
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, errorf(i+1, "unexpected character '%c'", c)
+			}
+			tokens = append(tokens, &token{kind: tokOp, text: op, pos: i + 1})
+			i += len(op)
+		}
+	}
+	return append(tokens, &token{kind: tokEOF, pos: len(src) + 1}), nil
+}
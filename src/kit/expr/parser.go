@@ -0,0 +1,466 @@
+package expr
+
+// maxNesting limits nesting of expressions, so that parsing doesn't exhaust the stack
+const maxNesting = 64
+
+type node interface {
+	typ() Type
+	eval(values Values) interface{}
+}
+
+type literal struct {
+	t Type
+	v interface{}
+}
+
+func (n *literal) typ() Type               { return n.t }
+func (n *literal) eval(Values) interface{} { return n.v }
+
+type variable struct {
+	name string
+	t    Type
+	pos  int
+}
+
+func (n *variable) typ() Type { return n.t }
+
+func (n *variable) eval(values Values) interface{} {
+	v, ok := values[n.name]
+	if !ok {
+		panic(errorf(n.pos, "variable '%s' isn't set", n.name))
+	}
+	if typeOf(v) != n.t {
+		panic(errorf(n.pos, "variable '%s' must be %s, got %T", n.name, n.t, v))
+	}
+	return v
+}
+
+func typeOf(v interface{}) Type {
+	switch v.(type) {
+	case bool:
+		return Bool
+	case float64:
+		return Number
+	case string:
+		return String
+	case []string:
+		return StringList
+	case []float64:
+		return NumberList
+	}
+	return 0
+}
+
+type list struct {
+	t     Type
+	items []node
+}
+
+func (n *list) typ() Type { return n.t }
+
+func (n *list) eval(values Values) interface{} {
+	if n.t == StringList {
+		res := make([]string, len(n.items))
+		for i, it := range n.items {
+			res[i] = it.eval(values).(string)
+		}
+		return res
+	}
+	res := make([]float64, len(n.items))
+	for i, it := range n.items {
+		res[i] = it.eval(values).(float64)
+	}
+	return res
+}
+
+type unary struct {
+	op string
+	x  node
+}
+
+func (n *unary) typ() Type { return n.x.typ() }
+
+func (n *unary) eval(values Values) interface{} {
+	if n.op == "!" {
+		return !n.x.eval(values).(bool)
+	}
+	return -n.x.eval(values).(float64)
+}
+
+type size struct {
+	x node
+}
+
+func (n *size) typ() Type { return Number }
+
+func (n *size) eval(values Values) interface{} {
+	switch v := n.x.eval(values).(type) {
+	case string:
+		return float64(len([]rune(v)))
+	case []string:
+		return float64(len(v))
+	case []float64:
+		return float64(len(v))
+	}
+	return 0.0
+}
+
+type binary struct {
+	op   string
+	l, r node
+}
+
+func (n *binary) typ() Type {
+	switch n.op {
+	case "+", "-", "*", "/":
+		return Number
+	}
+	return Bool
+}
+
+func (n *binary) eval(values Values) interface{} {
+	// logical operators are short-circuit
+	switch n.op {
+	case "&&":
+		return n.l.eval(values).(bool) && n.r.eval(values).(bool)
+	case "||":
+		return n.l.eval(values).(bool) || n.r.eval(values).(bool)
+	}
+	l, r := n.l.eval(values), n.r.eval(values)
+	switch n.op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	case "in":
+		switch list := r.(type) {
+		case []string:
+			for _, it := range list {
+				if it == l.(string) {
+					return true
+				}
+			}
+		case []float64:
+			for _, it := range list {
+				if it == l.(float64) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if ls, ok := l.(string); ok {
+		rs := r.(string)
+		switch n.op {
+		case "<":
+			return ls < rs
+		case "<=":
+			return ls <= rs
+		case ">":
+			return ls > rs
+		case ">=":
+			return ls >= rs
+		}
+	}
+	lf, rf := l.(float64), r.(float64)
+	switch n.op {
+	case "<":
+		return lf < rf
+	case "<=":
+		return lf <= rf
+	case ">":
+		return lf > rf
+	case ">=":
+		return lf >= rf
+	case "+":
+		return lf + rf
+	case "-":
+		return lf - rf
+	case "*":
+		return lf * rf
+	case "/":
+		return lf / rf
+	}
+	panic(errorf(0, "unknown operator '%s'", n.op))
+}
+
+// parser is a recursive descent parser, which checks types while building the tree
+// precedence from the lowest: ||, &&, relations (== != < <= > >= in), + -, * /, unary ! -
+type parser struct {
+	tokens  []*token
+	cur     int
+	vars    Vars
+	nesting int
+}
+
+func (p *parser) peek() *token {
+	return p.tokens[p.cur]
+}
+
+func (p *parser) next() *token {
+	t := p.tokens[p.cur]
+	if t.kind != tokEOF {
+		p.cur++
+	}
+	return t
+}
+
+// isOp checks if the current token is one of the operators, "in" is a keyword operator
+func (p *parser) isOp(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tokOp && !(t.kind == tokIdent && t.text == "in") {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if t := p.next(); t.kind != tokOp || t.text != op {
+		return errorf(t.pos, "expected '%s', got %s", op, t)
+	}
+	return nil
+}
+
+func (p *parser) parse() (node, error) {
+	if p.peek().kind == tokEOF {
+		return nil, errorf(1, "expression is empty")
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, errorf(t.pos, "unexpected %s", t)
+	}
+	return n, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseLogical("||", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseLogical("&&", p.parseRelation)
+}
+
+func (p *parser) parseLogical(op string, operand func() (node, error)) (node, error) {
+	l, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp(op) {
+		t := p.next()
+		r, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if l.typ() != Bool || r.typ() != Bool {
+			return nil, errorf(t.pos, "operator '%s' expects bool operands, got %s and %s", op, l.typ(), r.typ())
+		}
+		l = &binary{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseRelation() (node, error) {
+	l, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp("==", "!=", "<", "<=", ">", ">=", "in") {
+		return l, nil
+	}
+	t := p.next()
+	r, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	lt, rt := l.typ(), r.typ()
+	switch t.text {
+	case "==", "!=":
+		if lt != rt || lt == StringList || lt == NumberList {
+			return nil, errorf(t.pos, "operator '%s' can't compare %s and %s", t.text, lt, rt)
+		}
+	case "in":
+		if !(lt == String && rt == StringList) && !(lt == Number && rt == NumberList) {
+			return nil, errorf(t.pos, "operator 'in' expects a string in a list of strings or a number in a list of numbers, got %s in %s", lt, rt)
+		}
+	default:
+		if lt != rt || (lt != Number && lt != String) {
+			return nil, errorf(t.pos, "operator '%s' can't compare %s and %s", t.text, lt, rt)
+		}
+	}
+	// relations aren't associative, a < b < c is an error
+	if p.isOp("==", "!=", "<", "<=", ">", ">=", "in") {
+		return nil, errorf(p.peek().pos, "unexpected %s, use parentheses to combine comparisons", p.peek())
+	}
+	return &binary{op: t.text, l: l, r: r}, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	return p.parseArithmetic([]string{"+", "-"}, p.parseMultiplicative)
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	return p.parseArithmetic([]string{"*", "/"}, p.parseUnary)
+}
+
+func (p *parser) parseArithmetic(ops []string, operand func() (node, error)) (node, error) {
+	l, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp(ops...) {
+		t := p.next()
+		r, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if l.typ() != Number || r.typ() != Number {
+			return nil, errorf(t.pos, "operator '%s' expects number operands, got %s and %s", t.text, l.typ(), r.typ())
+		}
+		l = &binary{op: t.text, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if !p.isOp("!", "-") {
+		return p.parsePrimary()
+	}
+	t := p.next()
+	if err := p.enter(t); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if t.text == "!" && x.typ() != Bool {
+		return nil, errorf(t.pos, "operator '!' expects a bool operand, got %s", x.typ())
+	}
+	if t.text == "-" && x.typ() != Number {
+		return nil, errorf(t.pos, "operator '-' expects a number operand, got %s", x.typ())
+	}
+	return &unary{op: t.text, x: x}, nil
+}
+
+func (p *parser) enter(t *token) error {
+	p.nesting++
+	if p.nesting > maxNesting {
+		return errorf(t.pos, "expression is nested too deeply")
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.nesting--
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return &literal{t: Number, v: t.num}, nil
+	case tokString:
+		return &literal{t: String, v: t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			return &literal{t: Bool, v: t.text == "true"}, nil
+		case "in":
+			return nil, errorf(t.pos, "unexpected %s", t)
+		}
+		if p.isOp("(") {
+			return p.parseCall(t)
+		}
+		typ, ok := p.vars[t.text]
+		if !ok {
+			return nil, errorf(t.pos, "unknown variable '%s'", t.text)
+		}
+		return &variable{name: t.text, t: typ, pos: t.pos}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			if err := p.enter(t); err != nil {
+				return nil, err
+			}
+			defer p.leave()
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		case "[":
+			return p.parseList(t)
+		}
+	}
+	return nil, errorf(t.pos, "unexpected %s", t)
+}
+
+func (p *parser) parseList(start *token) (node, error) {
+	if err := p.enter(start); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	var items []node
+	for !p.isOp("]") {
+		if len(items) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		itemPos := p.peek().pos
+		it, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if it.typ() != String && it.typ() != Number {
+			return nil, errorf(itemPos, "list items must be strings or numbers, got %s", it.typ())
+		}
+		if len(items) > 0 && it.typ() != items[0].typ() {
+			return nil, errorf(itemPos, "list items must be of the same type, got %s and %s", items[0].typ(), it.typ())
+		}
+		items = append(items, it)
+	}
+	p.next()
+	if len(items) == 0 {
+		return nil, errorf(start.pos, "list is empty")
+	}
+	if items[0].typ() == String {
+		return &list{t: StringList, items: items}, nil
+	}
+	return &list{t: NumberList, items: items}, nil
+}
+
+func (p *parser) parseCall(name *token) (node, error) {
+	if name.text != "size" {
+		return nil, errorf(name.pos, "unknown function '%s'", name.text)
+	}
+	p.next()
+	if err := p.enter(name); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	argPos := p.peek().pos
+	arg, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if t := arg.typ(); t != String && t != StringList && t != NumberList {
+		return nil, errorf(argPos, "function 'size' expects a string or a list, got %s", t)
+	}
+	return &size{x: arg}, nil
+}
@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	ErrCodeGrpcSrvListen                                  = "GRPC-001"
+	ErrCodeGrpcSecurityLoginFailed                        = "GRPC-002"
+	ErrCodeGrpcSecurityPermissionsDenied                  = "GRPC-003"
+	ErrCodeGrpcMethodEmpty                                = "GRPC-004"
+	ErrCodeGrpcAuthorizationPoliciesWithoutAuthentication = "GRPC-005"
+	ErrCodeGrpcRecvStream                                 = "GRPC-006"
+	ErrCodeGrpcSendStream                                 = "GRPC-007"
+)
+
+var (
+	ErrGrpcSrvListen = func(cause error) error {
+		return er.WrapWithBuilder(cause, ErrCodeGrpcSrvListen, "").Err()
+	}
+	ErrGrpcSecurityLoginFailed = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeGrpcSecurityLoginFailed, "login failed").Business().C(ctx).GrpcSt(uint32(codes.Unauthenticated)).Err()
+	}
+	ErrGrpcSecurityPermissionsDenied = func(ctx context.Context) error {
+		return er.WithBuilder(ErrCodeGrpcSecurityPermissionsDenied, "permissions denied").Business().C(ctx).GrpcSt(uint32(codes.PermissionDenied)).Err()
+	}
+	ErrGrpcMethodEmpty = func() error {
+		return er.WithBuilder(ErrCodeGrpcMethodEmpty, "method empty").Err()
+	}
+	ErrGrpcAuthorizationPoliciesWithoutAuthentication = func(method string) error {
+		return er.WithBuilder(ErrCodeGrpcAuthorizationPoliciesWithoutAuthentication, "authorization requires authentication configured").F(er.FF{"method": method}).Err()
+	}
+	ErrGrpcRecvStream = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeGrpcRecvStream, "").C(ctx).Err()
+	}
+	ErrGrpcSendStream = func(cause error, ctx context.Context) error {
+		return er.WrapWithBuilder(cause, ErrCodeGrpcSendStream, "").C(ctx).Err()
+	}
+)
@@ -0,0 +1,181 @@
+package grpc
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/auth"
+	kitContext "github.com/mikhailbolshakov/cryptocare/src/kit/context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strings"
+)
+
+const (
+	MdAuthorization = "authorization" // MdAuthorization metadata key with "Bearer <token>" value
+	MdRequestId     = "requestid"     // MdRequestId metadata key with request id
+)
+
+// httpToGrpcCodes maps http statuses of app errors to gRPC codes
+var httpToGrpcCodes = map[uint32]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusInternalServerError: codes.Internal,
+}
+
+// serverStream overrides context of the stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.auth(s.setContext(ctx), info.FullMethod)
+	if err != nil {
+		return nil, s.toStatusErr(ctx, err)
+	}
+	if s.cfg.Trace {
+		s.logger().C(ctx).F(log.FF{"method": info.FullMethod}).TrcObj("request: %v", req)
+	}
+	rs, err := handler(ctx, req)
+	if err != nil {
+		return nil, s.toStatusErr(ctx, err)
+	}
+	if s.cfg.Trace {
+		s.logger().C(ctx).F(log.FF{"method": info.FullMethod}).TrcObj("response: %v", rs)
+	}
+	return rs, nil
+}
+
+func (s *Server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.auth(s.setContext(ss.Context()), info.FullMethod)
+	if err != nil {
+		return s.toStatusErr(ctx, err)
+	}
+	if s.cfg.Trace {
+		s.logger().C(ctx).F(log.FF{"method": info.FullMethod}).Trc("stream")
+	}
+	if err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx}); err != nil {
+		return s.toStatusErr(ctx, err)
+	}
+	return nil
+}
+
+// setContext initializes request context
+// request id is taken from the request context passed by FromContextToGrpcMD or from "requestid" metadata
+func (s *Server) setContext(ctx context.Context) context.Context {
+
+	ctxRq := kitContext.NewRequestCtx().Grpc()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if rq, ok := kitContext.Request(kitContext.FromGrpcMD(ctx, md)); ok && rq.GetRequestId() != "" {
+		ctxRq = ctxRq.WithRequestId(rq.GetRequestId())
+	} else if rid := md.Get(MdRequestId); len(rid) > 0 && rid[0] != "" {
+		ctxRq = ctxRq.WithRequestId(rid[0])
+	} else {
+		ctxRq = ctxRq.WithNewRequestId()
+	}
+
+	return ctxRq.ToContext(ctx)
+}
+
+// auth authenticates session by the access token and authorizes it against resource policies registered for the method
+func (s *Server) auth(ctx context.Context, fullMethod string) (context.Context, error) {
+
+	method, registered := s.methods[fullMethod]
+	if registered && !method.auth {
+		return ctx, nil
+	}
+
+	ctxRq, err := kitContext.MustRequest(ctx)
+	if err != nil {
+		return ctx, err
+	}
+
+	// check and extract authorization data
+	md, _ := metadata.FromIncomingContext(ctx)
+	authMd := md.Get(MdAuthorization)
+	if len(authMd) == 0 || authMd[0] == "" {
+		return ctx, ErrGrpcSecurityLoginFailed(ctx)
+	}
+	splitToken := strings.Split(authMd[0], "Bearer ")
+	if len(splitToken) < 2 {
+		return ctx, ErrGrpcSecurityLoginFailed(ctx)
+	}
+
+	// authenticate session
+	session, err := s.authSessionRepository.AuthSession(ctx, splitToken[1])
+	if err != nil || session == nil {
+		return ctx, ErrGrpcSecurityLoginFailed(ctx)
+	}
+
+	// populate context based on session
+	ctx = ctxRq.
+		WithUser(session.UserId, session.Username).
+		WithSessionId(session.Id).
+		WithRoles(session.Roles...).
+		ToContext(ctx)
+
+	if !registered || len(method.resourcePolicies) == 0 {
+		return ctx, nil
+	}
+
+	// build authorization request for the resources and permissions
+	authorizationResources, err := s.resourcePolicyManager.GetRequestedResources(ctx, fullMethod, nil)
+	if err != nil {
+		return ctx, err
+	}
+	if len(authorizationResources) == 0 {
+		return ctx, ErrGrpcSecurityPermissionsDenied(ctx)
+	}
+
+	// authorize session
+	allowed, err := s.authorizeSessionRepository.AuthorizeSession(ctx, &auth.AuthorizationRequest{
+		SessionId:              session.Id,
+		AuthorizationResources: authorizationResources,
+	})
+	if err != nil {
+		return ctx, err
+	}
+	if !allowed {
+		return ctx, ErrGrpcSecurityPermissionsDenied(ctx)
+	}
+
+	return ctx, nil
+}
+
+// toStatusErr converts error to gRPC status error
+func (s *Server) toStatusErr(ctx context.Context, err error) error {
+
+	// already a status error (e.g. context cancellation on the stream)
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	s.logger().C(ctx).Cmp("api").Pr("grpc").E(err).St().Err()
+
+	code := codes.Internal
+	if appErr, ok := er.Is(err); ok {
+		if grpcSt := appErr.GrpcStatus(); grpcSt != nil {
+			code = codes.Code(*grpcSt)
+		} else if httpSt := appErr.HttpStatus(); httpSt != nil {
+			if c, ok := httpToGrpcCodes[*httpSt]; ok {
+				code = c
+			}
+		} else if appErr.Type() == er.ErrTypeBusiness {
+			code = codes.FailedPrecondition
+		}
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,43 @@
+package grpc
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/kit/auth"
+)
+
+// Method specifies authentication and authorization settings of gRPC method
+type Method struct {
+	fullName         string
+	auth             bool
+	resourcePolicies []auth.ResourcePolicy
+}
+
+// M starts building a method by its full name (like "/package.Service/Method")
+func M(fullName string) *Method {
+	return &Method{
+		fullName: fullName,
+		auth:     true,
+	}
+}
+
+// NoAuth marks method as not required authentication
+func (m *Method) NoAuth() *Method {
+	m.auth = false
+	return m
+}
+
+// Authorize allows specifying authorization policy
+// Note! gRPC calls don't have http request, so policy conditions are resolved with nil request
+func (m *Method) Authorize(policies ...auth.ResourcePolicy) *Method {
+	m.resourcePolicies = append(m.resourcePolicies, policies...)
+	return m
+}
+
+func (m *Method) validate() error {
+	if m.fullName == "" {
+		return ErrGrpcMethodEmpty()
+	}
+	if len(m.resourcePolicies) > 0 && !m.auth {
+		return ErrGrpcAuthorizationPoliciesWithoutAuthentication(m.fullName)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/auth"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/goroutine"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"google.golang.org/grpc"
+	"net"
+	"time"
+)
+
+type Config struct {
+	Port  string
+	Trace bool
+}
+
+// Server represents gRPC server
+type Server struct {
+	Srv                        *grpc.Server    // Srv - internal server
+	cfg                        *Config         // cfg - config
+	logger                     log.CLoggerFunc // logger
+	authSessionRepository      auth.AuthenticateSession
+	authorizeSessionRepository auth.AuthorizeSession
+	resourcePolicyManager      auth.ResourcePolicyManager
+	methods                    map[string]*Method
+}
+
+// ServiceSetter registers gRPC services on the server
+type ServiceSetter interface {
+	Set() error
+}
+
+func NewGrpcServer(cfg *Config, logger log.CLoggerFunc, authSessionRepository auth.AuthenticateSession,
+	authorizeSessionRepository auth.AuthorizeSession, resourcePolicyManager auth.ResourcePolicyManager) *Server {
+	s := &Server{
+		cfg:                        cfg,
+		logger:                     logger,
+		authSessionRepository:      authSessionRepository,
+		authorizeSessionRepository: authorizeSessionRepository,
+		resourcePolicyManager:      resourcePolicyManager,
+		methods:                    map[string]*Method{},
+	}
+	s.Srv = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+	return s
+}
+
+// Register registers authentication and authorization settings of methods
+// methods which aren't registered require authentication without authorization
+func (s *Server) Register(methods ...*Method) error {
+	for _, m := range methods {
+		if err := m.validate(); err != nil {
+			return err
+		}
+		if len(m.resourcePolicies) > 0 {
+			s.resourcePolicyManager.RegisterResourceMapping(m.fullName, m.resourcePolicies...)
+		}
+		s.methods[m.fullName] = m
+	}
+	return nil
+}
+
+func (s *Server) Listen() {
+
+	goroutine.New().
+		WithLoggerFn(s.logger).
+		WithRetry(goroutine.Unrestricted).
+		Cmp("grpc-server").
+		Mth("listen").
+		Go(context.Background(),
+			func() {
+				addr := fmt.Sprintf(":%s", s.cfg.Port)
+				l := s.logger().Pr("grpc").Cmp("server").Mth("listen").F(log.FF{"url": addr})
+				l.Inf("start listening")
+			start:
+				lis, err := net.Listen("tcp", addr)
+				if err != nil {
+					l.E(ErrGrpcSrvListen(err)).St().Err()
+					time.Sleep(time.Second * 5)
+					goto start
+				}
+				if err := s.Srv.Serve(lis); err != nil {
+					if err != grpc.ErrServerStopped {
+						l.E(ErrGrpcSrvListen(err)).St().Err()
+						time.Sleep(time.Second * 5)
+						goto start
+					} else {
+						l.Dbg("server closed")
+					}
+				}
+			})
+}
+
+func (s *Server) Close() {
+	s.Srv.Stop()
+}
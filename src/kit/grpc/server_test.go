@@ -0,0 +1,149 @@
+package grpc
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/auth"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/auth/impl"
+	kitContext "github.com/mikhailbolshakov/cryptocare/src/kit/context"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"testing"
+)
+
+var logger = log.Init(&log.Config{Level: log.InfoLevel})
+var logf = func() log.CLogger {
+	return log.L(logger)
+}
+
+const (
+	testMethod         = "/test.Service/Method"
+	testMethodNoAuth   = "/test.Service/NoAuth"
+	testMethodResource = "/test.Service/Resource"
+)
+
+// authSessionStub authenticates the only token
+type authSessionStub struct {
+	token   string
+	session *auth.Session
+}
+
+func (a *authSessionStub) AuthSession(ctx context.Context, token string) (*auth.Session, error) {
+	if token == a.token {
+		return a.session, nil
+	}
+	return nil, nil
+}
+
+// authorizeSessionStub authorizes session with the given result and remembers the last request
+type authorizeSessionStub struct {
+	allowed bool
+	rq      *auth.AuthorizationRequest
+}
+
+func (a *authorizeSessionStub) AuthorizeSession(ctx context.Context, rq *auth.AuthorizationRequest) (bool, error) {
+	a.rq = rq
+	return a.allowed, nil
+}
+
+func (a *authorizeSessionStub) GetRolesForGroups(ctx context.Context, groups []string) ([]string, error) {
+	return nil, nil
+}
+
+type grpcServerTestSuite struct {
+	kitTestSuite.Suite
+	authSession      *authSessionStub
+	authorizeSession *authorizeSessionStub
+	srv              *Server
+}
+
+func (s *grpcServerTestSuite) SetupSuite() {
+	s.Suite.Init(logf)
+}
+
+func TestGrpcServerSuite(t *testing.T) {
+	suite.Run(t, new(grpcServerTestSuite))
+}
+
+func (s *grpcServerTestSuite) SetupTest() {
+	s.authSession = &authSessionStub{token: "token", session: &auth.Session{Id: "sid", UserId: "uid", Username: "un"}}
+	s.authorizeSession = &authorizeSessionStub{}
+	s.srv = NewGrpcServer(&Config{}, logf, s.authSession, s.authorizeSession, impl.NewResourcePolicyManager(logf))
+	s.NoError(s.srv.Register(
+		M(testMethod),
+		M(testMethodNoAuth).NoAuth(),
+		M(testMethodResource).Authorize(impl.Resource("resource", "r")),
+	))
+}
+
+func (s *grpcServerTestSuite) call(ctx context.Context, method string) (context.Context, error) {
+	var handlerCtx context.Context
+	_, err := s.srv.unaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerCtx = ctx
+		return nil, nil
+	})
+	return handlerCtx, err
+}
+
+func (s *grpcServerTestSuite) withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(MdAuthorization, "Bearer "+token, MdRequestId, "rid"))
+}
+
+func (s *grpcServerTestSuite) Test_NoToken_Unauthenticated() {
+	_, err := s.call(context.Background(), testMethod)
+	s.Error(err)
+	s.Equal(codes.Unauthenticated, status.Code(err))
+}
+
+func (s *grpcServerTestSuite) Test_InvalidToken_Unauthenticated() {
+	_, err := s.call(s.withToken("invalid"), testMethod)
+	s.Error(err)
+	s.Equal(codes.Unauthenticated, status.Code(err))
+}
+
+func (s *grpcServerTestSuite) Test_NoAuth_Ok() {
+	ctx, err := s.call(context.Background(), testMethodNoAuth)
+	s.NoError(err)
+	rq, ok := kitContext.Request(ctx)
+	s.True(ok)
+	s.Equal(kitContext.CallerTypeGrpc, rq.GetCaller())
+	s.NotEmpty(rq.GetRequestId())
+}
+
+func (s *grpcServerTestSuite) Test_Authenticated_ContextPopulated() {
+	ctx, err := s.call(s.withToken("token"), testMethod)
+	s.NoError(err)
+	rq, ok := kitContext.Request(ctx)
+	s.True(ok)
+	s.Equal("uid", rq.GetUserId())
+	s.Equal("sid", rq.GetSessionId())
+	s.Equal("rid", rq.GetRequestId())
+}
+
+func (s *grpcServerTestSuite) Test_Authorization() {
+	_, err := s.call(s.withToken("token"), testMethodResource)
+	s.Error(err)
+	s.Equal(codes.PermissionDenied, status.Code(err))
+	s.Equal("sid", s.authorizeSession.rq.SessionId)
+	s.Len(s.authorizeSession.rq.AuthorizationResources, 1)
+	s.Equal("resource", s.authorizeSession.rq.AuthorizationResources[0].Resource)
+
+	s.authorizeSession.allowed = true
+	_, err = s.call(s.withToken("token"), testMethodResource)
+	s.NoError(err)
+}
+
+func (s *grpcServerTestSuite) Test_ToStatusErr() {
+	err := s.srv.toStatusErr(s.Ctx, er.WithBuilder("TST-001", "not found").Business().HttpSt(http.StatusNotFound).Err())
+	s.Equal(codes.NotFound, status.Code(err))
+	err = s.srv.toStatusErr(s.Ctx, er.WithBuilder("TST-001", "business").Business().Err())
+	s.Equal(codes.FailedPrecondition, status.Code(err))
+	err = s.srv.toStatusErr(s.Ctx, er.WithBuilder("TST-001", "system").Err())
+	s.Equal(codes.Internal, status.Code(err))
+}
@@ -69,6 +69,7 @@ func (m *Middleware) AuthAccessTokenMiddleware(next http.HandlerFunc) http.Handl
 		ctx = ctxRq.
 			WithUser(session.UserId, session.Username).
 			WithSessionId(session.Id).
+			WithRoles(session.Roles...).
 			ToContext(r.Context())
 
 		r = r.WithContext(ctx)
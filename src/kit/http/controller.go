@@ -395,6 +395,18 @@ func (c *BaseController) FormValBool(r *http.Request, ctx context.Context, name
 	return &b, nil
 }
 
+// FormValStrings parses URL form value with comma separated list of strings
+func (c *BaseController) FormValStrings(r *http.Request, ctx context.Context, name string, allowEmpty bool) ([]string, error) {
+	valStr, err := c.FormVal(r, ctx, name, allowEmpty)
+	if err != nil {
+		return nil, err
+	}
+	if valStr == "" {
+		return nil, nil
+	}
+	return strings.Split(valStr, ","), nil
+}
+
 // FormValTime parses URL form value and checks for time in RFC3339 format(UTC)
 func (c *BaseController) FormValTime(r *http.Request, ctx context.Context, name string, allowEmpty bool) (*time.Time, error) {
 	valStr, err := c.FormVal(r, ctx, name, allowEmpty)
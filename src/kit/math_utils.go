@@ -0,0 +1,16 @@
+package kit
+
+import "sort"
+
+// Median calculates median of values. Values are sorted in place
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
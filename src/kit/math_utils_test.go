@@ -0,0 +1,40 @@
+package kit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Median(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		expected float64
+	}{
+		{
+			name:     "Empty",
+			values:   nil,
+			expected: 0,
+		},
+		{
+			name:     "Single",
+			values:   []float64{1.5},
+			expected: 1.5,
+		},
+		{
+			name:     "Odd",
+			values:   []float64{3, 1, 2},
+			expected: 2,
+		},
+		{
+			name:     "Even",
+			values:   []float64{4, 1, 3, 2},
+			expected: 2.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Median(tt.values))
+		})
+	}
+}
@@ -3,12 +3,16 @@ package telegram
 import (
 	"context"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/er"
+	"time"
 )
 
 var (
-	ErrCodeTelegramBotEmpty      = "TLG-001"
-	ErrCodeTelegramRequestFailed = "TLG-002"
-	ErrCodeTelegramResponseError = "TLG-003"
+	ErrCodeTelegramBotEmpty        = "TLG-001"
+	ErrCodeTelegramRequestFailed   = "TLG-002"
+	ErrCodeTelegramResponseError   = "TLG-003"
+	ErrCodeTelegramTooManyRequests = "TLG-004"
+	ErrCodeTelegramRequestRejected = "TLG-005"
+	ErrCodeTelegramMessageTooLong  = "TLG-006"
 )
 
 var (
@@ -21,4 +25,44 @@ var (
 	ErrTelegramResponseError = func(ctx context.Context, status, body string) error {
 		return er.WithBuilder(ErrCodeTelegramResponseError, "telegram error").F(er.FF{"status": status, "body": body}).C(ctx).Err()
 	}
+	ErrTelegramTooManyRequests = func(ctx context.Context, retryAfterSec int) error {
+		return er.WithBuilder(ErrCodeTelegramTooManyRequests, "telegram too many requests").F(er.FF{retryAfterField: retryAfterSec}).C(ctx).Err()
+	}
+	ErrTelegramRequestRejected = func(ctx context.Context, status, description string) error {
+		return er.WithBuilder(ErrCodeTelegramRequestRejected, "telegram rejected request").F(er.FF{"status": status, descriptionField: description}).C(ctx).Err()
+	}
+	ErrTelegramMessageTooLong = func(ctx context.Context, limit int) error {
+		return er.WithBuilder(ErrCodeTelegramMessageTooLong, "telegram message too long").Business().F(er.FF{"limit": limit}).C(ctx).Err()
+	}
+)
+
+const (
+	retryAfterField  = "retryAfter"
+	descriptionField = "description"
 )
+
+// RetryAfter returns how long Bot API asked to wait if the error is caused by exceeded rate limits
+func RetryAfter(err error) (time.Duration, bool) {
+	appErr, ok := er.Is(err)
+	if !ok || appErr.Code() != ErrCodeTelegramTooManyRequests {
+		return 0, false
+	}
+	sec, ok := appErr.Fields()[retryAfterField].(int)
+	return time.Duration(sec) * time.Second, ok
+}
+
+// IsRejected checks if Bot API rejected the request, e.g. the chat isn't found or the bot is blocked, so the request never succeeds
+func IsRejected(err error) bool {
+	appErr, ok := er.Is(err)
+	return ok && appErr.Code() == ErrCodeTelegramRequestRejected
+}
+
+// rejectDescription returns description Bot API rejected the request with
+func rejectDescription(err error) string {
+	appErr, ok := er.Is(err)
+	if !ok {
+		return ""
+	}
+	description, _ := appErr.Fields()[descriptionField].(string)
+	return description
+}
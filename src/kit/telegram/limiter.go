@@ -0,0 +1,155 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultGlobalPerSec = 30.0 // Bot API allows about 30 messages per second to all chats
+	defaultChatPerSec   = 1.0  // Bot API allows about one message per second to a private chat
+	defaultGroupPerMin  = 20.0 // Bot API allows 20 messages per minute to a group or channel
+	defaultRetries      = 3
+	defaultMaxRetryWait = time.Second * 30
+	maxIdleChatBuckets  = 10000
+	idleChatBucketTtl   = time.Minute * 5
+)
+
+// RateLimit limits of sending messages, Bot API rejects requests exceeding them with 429
+type RateLimit struct {
+	GlobalPerSec     float64 `config:"global-per-sec"`      // GlobalPerSec messages per second to all chats
+	ChatPerSec       float64 `config:"chat-per-sec"`        // ChatPerSec messages per second to a private chat
+	GroupPerMin      float64 `config:"group-per-min"`       // GroupPerMin messages per minute to a group or channel
+	Retries          int     `config:"retries"`             // Retries how many times a request rejected with 429 is retried
+	MaxRetryAfterSec int     `config:"max-retry-after-sec"` // MaxRetryAfterSec longest retry_after waited for, the request fails if Bot API asks to wait longer
+}
+
+// tokenBucket allows rate events per second on average with bursts up to burst events
+type tokenBucket struct {
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time // blockedUntil no events are allowed until the time, it's set by retry_after
+}
+
+func newTokenBucket(rate, burst float64) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst}
+}
+
+// reserve takes a token and returns how long to wait until the event is allowed
+// tokens might go negative, so concurrent reservations are queued one after another
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	if now.After(b.last) {
+		b.last = now
+	}
+	b.tokens--
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	if blocked := b.blockedUntil.Sub(now); blocked > wait {
+		wait = blocked
+	}
+	return wait
+}
+
+// idle checks if the bucket is full and not blocked, so it can be dropped and created again without changing behavior
+func (b *tokenBucket) idle(now time.Time) bool {
+	return now.After(b.blockedUntil) && now.Sub(b.last) > idleChatBucketTtl
+}
+
+// scheduler spaces requests to respect global and per chat rate limits
+type scheduler struct {
+	sync.Mutex
+	cfg    *RateLimit
+	global *tokenBucket
+	chats  map[int64]*tokenBucket
+}
+
+func newScheduler(cfg *RateLimit) *scheduler {
+	c := &RateLimit{}
+	if cfg != nil {
+		*c = *cfg
+	}
+	if c.GlobalPerSec <= 0 {
+		c.GlobalPerSec = defaultGlobalPerSec
+	}
+	if c.ChatPerSec <= 0 {
+		c.ChatPerSec = defaultChatPerSec
+	}
+	if c.GroupPerMin <= 0 {
+		c.GroupPerMin = defaultGroupPerMin
+	}
+	if c.Retries <= 0 {
+		c.Retries = defaultRetries
+	}
+	if c.MaxRetryAfterSec <= 0 {
+		c.MaxRetryAfterSec = int(defaultMaxRetryWait.Seconds())
+	}
+	return &scheduler{
+		cfg:    c,
+		global: newTokenBucket(c.GlobalPerSec, c.GlobalPerSec),
+		chats:  make(map[int64]*tokenBucket),
+	}
+}
+
+// chat returns bucket of the chat, groups and channels have negative ids
+func (s *scheduler) chat(chatId int64, now time.Time) *tokenBucket {
+	if b, ok := s.chats[chatId]; ok {
+		return b
+	}
+	if len(s.chats) >= maxIdleChatBuckets {
+		for id, b := range s.chats {
+			if b.idle(now) {
+				delete(s.chats, id)
+			}
+		}
+	}
+	b := newTokenBucket(s.cfg.ChatPerSec, 1)
+	if chatId < 0 {
+		b = newTokenBucket(s.cfg.GroupPerMin/60, 1)
+	}
+	s.chats[chatId] = b
+	return b
+}
+
+// wait blocks until a message to the chat is allowed or the context is done
+func (s *scheduler) wait(ctx context.Context, chatId int64) error {
+	s.Lock()
+	now := time.Now()
+	d := s.global.reserve(now)
+	if chatD := s.chat(chatId, now).reserve(now); chatD > d {
+		d = chatD
+	}
+	s.Unlock()
+
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// block holds messages to the chat back for the period Bot API asked to wait
+func (s *scheduler) block(chatId int64, d time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	if b := s.chat(chatId, now); now.Add(d).After(b.blockedUntil) {
+		b.blockedUntil = now.Add(d)
+	}
+}
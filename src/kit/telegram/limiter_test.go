@@ -0,0 +1,47 @@
+package telegram
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_TokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(2, 2)
+	// burst is allowed at once
+	assert.Zero(t, b.reserve(now))
+	assert.Zero(t, b.reserve(now))
+	// then events are spaced by 1/rate
+	assert.Equal(t, time.Millisecond*500, b.reserve(now))
+	assert.Equal(t, time.Second, b.reserve(now))
+	// tokens are refilled with time
+	assert.Zero(t, b.reserve(now.Add(time.Second*3)))
+}
+
+func Test_TokenBucket_Blocked(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(1, 1)
+	b.blockedUntil = now.Add(time.Second * 5)
+	assert.Equal(t, time.Second*5, b.reserve(now))
+	assert.Zero(t, b.reserve(now.Add(time.Second*7)))
+}
+
+func Test_Scheduler_Defaults(t *testing.T) {
+	s := newScheduler(nil)
+	assert.Equal(t, defaultGlobalPerSec, s.cfg.GlobalPerSec)
+	assert.Equal(t, defaultRetries, s.cfg.Retries)
+	now := time.Now()
+	// groups are limited per minute
+	assert.Equal(t, defaultGroupPerMin/60, s.chat(-100, now).rate)
+	assert.Equal(t, defaultChatPerSec, s.chat(100, now).rate)
+}
+
+func Test_Scheduler_Wait_WhenContextDone(t *testing.T) {
+	s := newScheduler(&RateLimit{ChatPerSec: 0.1})
+	assert.NoError(t, s.wait(context.Background(), 100))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	assert.Error(t, s.wait(ctx, 100))
+}
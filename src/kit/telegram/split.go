@@ -0,0 +1,145 @@
+package telegram
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMessageLength max length of a message text in UTF-16 code units
+const MaxMessageLength = 4096
+
+// htmlToken is a tag, an entity or a single character of HTML text
+type htmlToken struct {
+	text    string
+	tag     string // tag name if the token is a tag
+	closing bool   // closing if the token is a closing tag
+	length  int    // length in UTF-16 code units
+}
+
+type openTag struct {
+	name    string
+	opening string
+}
+
+// MessageLength returns length of the text as Bot API counts it, in UTF-16 code units
+func MessageLength(text string) int {
+	return utf16Len(text)
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func tokenizeHtml(text string) []*htmlToken {
+	var tokens []*htmlToken
+	for i := 0; i < len(text); {
+		switch text[i] {
+		case '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				tagText := text[i : i+end+1]
+				name := strings.TrimPrefix(strings.Trim(tagText, "<>"), "/")
+				if sp := strings.IndexAny(name, " \t\n"); sp >= 0 {
+					name = name[:sp]
+				}
+				tokens = append(tokens, &htmlToken{text: tagText, tag: strings.ToLower(name), closing: strings.HasPrefix(tagText, "</"), length: utf16Len(tagText)})
+				i += end + 1
+				continue
+			}
+		case '&':
+			// entities are short, a lone ampersand is a character
+			if end := strings.IndexByte(text[i:], ';'); end > 0 && end <= 10 {
+				tokens = append(tokens, &htmlToken{text: text[i : i+end+1], length: end + 1})
+				i += end + 1
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		tokens = append(tokens, &htmlToken{text: text[i : i+size], length: utf16Len(text[i : i+size])})
+		i += size
+	}
+	return tokens
+}
+
+// apply returns open tags after the token
+func (t *htmlToken) apply(open []*openTag) []*openTag {
+	if t.tag == "" {
+		return open
+	}
+	if !t.closing {
+		return append(append([]*openTag{}, open...), &openTag{name: t.tag, opening: t.text})
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		if open[i].name == t.tag {
+			return append([]*openTag{}, open[:i]...)
+		}
+	}
+	return open
+}
+
+func closingTags(open []*openTag) string {
+	var sb strings.Builder
+	for i := len(open) - 1; i >= 0; i-- {
+		sb.WriteString("</" + open[i].name + ">")
+	}
+	return sb.String()
+}
+
+// SplitMessage splits HTML text into parts not longer than the limit
+// parts are split by lines if possible, tags open at a split are closed at the end of the part and reopened in the next one
+// tags and entities are never split
+func SplitMessage(text string, limit int) []string {
+	if utf16Len(text) <= limit {
+		return []string{text}
+	}
+	tokens := tokenizeHtml(text)
+
+	var parts []string
+	var open []*openTag
+	for start := 0; start < len(tokens); {
+		var prefix strings.Builder
+		for _, t := range open {
+			prefix.WriteString(t.opening)
+		}
+		length := utf16Len(prefix.String())
+		stack := open
+		end, lineEnd := start, -1
+		var lineStack []*openTag
+		for end < len(tokens) {
+			t := tokens[end]
+			next := t.apply(stack)
+			// at least one token is taken, so the loop always advances
+			if end > start && length+t.length+utf16Len(closingTags(next)) > limit {
+				break
+			}
+			length += t.length
+			stack = next
+			end++
+			if t.text == "\n" {
+				lineEnd, lineStack = end, stack
+			}
+		}
+		if end < len(tokens) && lineEnd > start {
+			end, stack = lineEnd, lineStack
+		}
+
+		var sb strings.Builder
+		sb.WriteString(prefix.String())
+		for _, t := range tokens[start:end] {
+			sb.WriteString(t.text)
+		}
+		sb.WriteString(closingTags(stack))
+		if part := sb.String(); strings.TrimSpace(part) != "" {
+			parts = append(parts, part)
+		}
+		start, open = end, stack
+	}
+	return parts
+}
@@ -0,0 +1,45 @@
+package telegram
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_SplitMessage_WhenShort(t *testing.T) {
+	assert.Equal(t, []string{"<b>short</b>"}, SplitMessage("<b>short</b>", 100))
+}
+
+func Test_SplitMessage_ByLines(t *testing.T) {
+	text := "line one\nline two\nline three\n"
+	parts := SplitMessage(text, 20)
+	assert.Equal(t, []string{"line one\nline two\n", "line three\n"}, parts)
+}
+
+func Test_SplitMessage_ReopenTags(t *testing.T) {
+	text := "<b><a href='https://x.io'>" + strings.Repeat("a", 30) + "</a></b>"
+	parts := SplitMessage(text, 40)
+	assert.True(t, len(parts) > 1)
+	for _, p := range parts {
+		assert.True(t, utf16Len(p) <= 40, p)
+		assert.True(t, strings.HasPrefix(p, "<b><a href='https://x.io'>"), p)
+		assert.True(t, strings.HasSuffix(p, "</a></b>"), p)
+	}
+	// text isn't lost
+	var joined string
+	for _, p := range parts {
+		joined += strings.TrimSuffix(strings.TrimPrefix(p, "<b><a href='https://x.io'>"), "</a></b>")
+	}
+	assert.Equal(t, strings.Repeat("a", 30), joined)
+}
+
+func Test_SplitMessage_EntitiesAndEmoji(t *testing.T) {
+	text := strings.Repeat("&amp;\U0001F525", 4)
+	parts := SplitMessage(text, 8)
+	for _, p := range parts {
+		assert.True(t, utf16Len(p) <= 8, p)
+		// entity isn't split
+		assert.Equal(t, strings.Count(p, "&"), strings.Count(p, "&amp;"))
+	}
+	assert.Equal(t, text, strings.Join(parts, ""))
+}
@@ -1,34 +1,295 @@
 package telegram
 
 import (
+	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
 	"io/ioutil"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
-// Telegram sends message to telegram channel
+const (
+	// DefaultBaseUrl public Bot API
+	DefaultBaseUrl = "https://api.telegram.org"
+	// WebhookSecretHeader header Bot API passes the webhook secret in
+	WebhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	ChatTypePrivate = "private"
+	ChatTypeChannel = "channel"
+
+	ChatMemberStatusCreator       = "creator"
+	ChatMemberStatusAdministrator = "administrator"
+	ChatMemberStatusLeft          = "left"
+
+	defaultRequestTimeout = time.Second * 30
+	defaultPollTimeoutSec = 30
+	pollRetryDelay        = time.Second * 5
+)
+
+// allowedUpdates types of updates the bot receives
+var allowedUpdates = []string{"message", "channel_post"}
+
+// Config of Bot API client
+type Config struct {
+	BaseUrl   string     // BaseUrl Bot API url, DefaultBaseUrl if empty. It allows pointing the client to a local Bot API server or a fake one in tests
+	RateLimit *RateLimit // RateLimit limits of sending messages, Bot API limits if empty
+}
+
+// User telegram user
+type User struct {
+	Id        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+// Chat telegram chat
+type Chat struct {
+	Id       int64  `json:"id"`
+	Type     string `json:"type"` // Type private, group, supergroup, channel
+	Title    string `json:"title,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// Message telegram message
+type Message struct {
+	MessageId int64  `json:"message_id"`
+	From      *User  `json:"from,omitempty"`
+	Chat      *Chat  `json:"chat"`
+	Date      int64  `json:"date"`
+	Text      string `json:"text,omitempty"`
+}
+
+// ChatMember member of a chat
+type ChatMember struct {
+	User   *User  `json:"user"`
+	Status string `json:"status"` // Status creator, administrator, member, restricted, left, kicked
+}
+
+// IsAdmin checks if the member is the creator or an administrator of the chat
+func (m *ChatMember) IsAdmin() bool {
+	return m.Status == ChatMemberStatusCreator || m.Status == ChatMemberStatusAdministrator
+}
+
+// Update incoming update, only messages and channel posts are supported
+type Update struct {
+	UpdateId    int64    `json:"update_id"`
+	Message     *Message `json:"message,omitempty"`
+	ChannelPost *Message `json:"channel_post,omitempty"` // ChannelPost post in a channel the bot is an administrator of
+}
+
+// Command parses bot command of the message text, e.g. "/subscribe@bot 1.5 USDT" gives "subscribe" and ["1.5", "USDT"]
+// it returns empty command if the text isn't a command
+func (m *Message) Command() (string, []string) {
+	fields := strings.Fields(m.Text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil
+	}
+	cmd := strings.ToLower(strings.TrimPrefix(fields[0], "/"))
+	// commands in groups are addressed to the bot as /command@bot
+	if i := strings.Index(cmd, "@"); i >= 0 {
+		cmd = cmd[:i]
+	}
+	return cmd, fields[1:]
+}
+
+// UpdateHandler handles a received update
+type UpdateHandler func(ctx context.Context, update *Update)
+
+// Telegram Bot API client
 type Telegram interface {
-	// Send sends a text message
+	// Send sends HTML text message, the text must be valid Telegram HTML with escaped data
+	// messages are spaced to respect rate limits, long messages are split into several ones
 	Send(ctx context.Context, bot, text string, channel int) error
+	// SendMessage sends HTML text as a single message and returns it, so the message can be edited later
+	// it fails if the text is longer than MaxMessageLength
+	SendMessage(ctx context.Context, bot, text string, chatId int64) (*Message, error)
+	// EditMessageText replaces HTML text of the message sent by the bot, it succeeds if the text isn't modified
+	EditMessageText(ctx context.Context, bot string, chatId, messageId int64, text string) error
+	// GetUpdates long polls updates with ids starting from the offset, it waits for updates up to timeout
+	GetUpdates(ctx context.Context, bot string, offset int64, timeoutSec int) ([]*Update, error)
+	// Poll long polls updates and passes them to the handler one by one until the context is cancelled
+	// failed requests are retried, so it returns when the context is cancelled only
+	Poll(ctx context.Context, bot string, timeoutSec int, handler UpdateHandler)
+	// SetWebhook makes Bot API post updates to the url, the secret is passed in WebhookSecretHeader
+	SetWebhook(ctx context.Context, bot, url, secret string) error
+	// DeleteWebhook switches the bot back to getUpdates
+	DeleteWebhook(ctx context.Context, bot string) error
+	// GetMe retrieves the bot user
+	GetMe(ctx context.Context, bot string) (*User, error)
+	// GetChatMember retrieves membership of the user in the chat
+	GetChatMember(ctx context.Context, bot string, chatId, userId int64) (*ChatMember, error)
+	// SetRateLimit sets limits of sending messages by the bot, nil resets them to the configured ones
+	// Bot API counts limits per bot, so every bot has its own budget
+	SetRateLimit(bot string, rateLimit *RateLimit)
+}
+
+// CheckWebhookSecret checks the secret passed in WebhookSecretHeader in constant time
+func CheckWebhookSecret(expected, actual string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) == 1
+}
+
+// params of Bot API method, they are posted as JSON
+type params map[string]interface{}
+
+// apiResponse envelope of Bot API responses
+type apiResponse struct {
+	Ok          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	ErrorCode   int             `json:"error_code,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Parameters  *struct {
+		RetryAfter int `json:"retry_after,omitempty"`
+	} `json:"parameters,omitempty"`
 }
 
 type telegramImpl struct {
-	logger log.CLoggerFunc
+	sync.Mutex
+	logger     log.CLoggerFunc
+	baseUrl    string
+	client     *http.Client
+	rateLimit  *RateLimit
+	schedulers map[string]*scheduler // schedulers by bot
+}
+
+func NewTelegram(logger log.CLoggerFunc, cfg *Config) Telegram {
+	t := &telegramImpl{
+		logger:     logger,
+		baseUrl:    DefaultBaseUrl,
+		client:     &http.Client{},
+		schedulers: make(map[string]*scheduler),
+	}
+	if cfg != nil {
+		if cfg.BaseUrl != "" {
+			t.baseUrl = strings.TrimRight(cfg.BaseUrl, "/")
+		}
+		t.rateLimit = cfg.RateLimit
+	}
+	return t
 }
 
-func NewTelegram(logger log.CLoggerFunc) Telegram {
-	return &telegramImpl{
-		logger: logger,
+func (t *telegramImpl) SetRateLimit(bot string, rateLimit *RateLimit) {
+	t.Lock()
+	defer t.Unlock()
+	if rateLimit == nil {
+		rateLimit = t.rateLimit
 	}
+	t.schedulers[bot] = newScheduler(rateLimit)
+}
+
+// scheduler returns scheduler of the bot, it's created with the configured limits
+func (t *telegramImpl) scheduler(bot string) *scheduler {
+	t.Lock()
+	defer t.Unlock()
+	s, ok := t.schedulers[bot]
+	if !ok {
+		s = newScheduler(t.rateLimit)
+		t.schedulers[bot] = s
+	}
+	return s
 }
 
 func (t *telegramImpl) l() log.CLogger {
 	return t.logger().Cmp("telegram")
 }
 
+// call posts params of the Bot API method as JSON and unmarshal result
+func (t *telegramImpl) call(ctx context.Context, bot, method string, params params, timeout time.Duration, result interface{}) error {
+	if bot == "" {
+		return ErrTelegramBotEmpty(ctx)
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return ErrTelegramRequestFailed(ctx, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	rq, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/%s", t.baseUrl, bot, method), bytes.NewReader(body))
+	if err != nil {
+		return ErrTelegramRequestFailed(ctx, err)
+	}
+	rq.Header.Set("Content-Type", "application/json")
+	rs, err := t.client.Do(rq)
+	if err != nil {
+		return ErrTelegramRequestFailed(ctx, err)
+	}
+	defer func() { _ = rs.Body.Close() }()
+	rsBody, err := ioutil.ReadAll(rs.Body)
+	if err != nil {
+		return ErrTelegramRequestFailed(ctx, err)
+	}
+	apiRs := &apiResponse{}
+	if err := json.Unmarshal(rsBody, apiRs); err != nil {
+		return ErrTelegramResponseError(ctx, rs.Status, string(rsBody))
+	}
+	switch {
+	case rs.StatusCode == http.StatusTooManyRequests:
+		retryAfter := 1
+		if apiRs.Parameters != nil && apiRs.Parameters.RetryAfter > 0 {
+			retryAfter = apiRs.Parameters.RetryAfter
+		}
+		return ErrTelegramTooManyRequests(ctx, retryAfter)
+	// chat not found, bot blocked or kicked, malformed text, such requests never succeed
+	case rs.StatusCode == http.StatusBadRequest || rs.StatusCode == http.StatusForbidden:
+		return ErrTelegramRequestRejected(ctx, rs.Status, apiRs.Description)
+	case !apiRs.Ok || rs.StatusCode >= 300:
+		return ErrTelegramResponseError(ctx, rs.Status, string(rsBody))
+	}
+	if result != nil && len(apiRs.Result) > 0 {
+		if err := json.Unmarshal(apiRs.Result, result); err != nil {
+			return ErrTelegramResponseError(ctx, rs.Status, string(rsBody))
+		}
+	}
+	return nil
+}
+
+// callWithinLimits calls the method sending to the chat within rate limits
+// requests rejected with 429 are retried after the period Bot API asked to wait
+func (t *telegramImpl) callWithinLimits(ctx context.Context, bot, method string, chatId int64, params params, result interface{}) error {
+	l := t.l().C(ctx).Mth(method).F(log.FF{"chatId": chatId})
+	scheduler := t.scheduler(bot)
+	cfg := scheduler.cfg
+	for attempt := 0; ; attempt++ {
+		if err := scheduler.wait(ctx, chatId); err != nil {
+			return ErrTelegramRequestFailed(ctx, err)
+		}
+		err := t.call(ctx, bot, method, params, defaultRequestTimeout, result)
+		if err == nil {
+			return nil
+		}
+		retryAfter, ok := RetryAfter(err)
+		if !ok {
+			return err
+		}
+		// messages to the chat are held back for everyone, not only for this request
+		scheduler.block(chatId, retryAfter)
+		if attempt >= cfg.Retries || retryAfter > time.Duration(cfg.MaxRetryAfterSec)*time.Second {
+			return err
+		}
+		l.F(log.FF{"retryAfter": retryAfter.String()}).Warn("too many requests")
+	}
+}
+
+func (t *telegramImpl) sendMessage(ctx context.Context, bot, text string, chatId int64) (*Message, error) {
+	msg := &Message{}
+	err := t.callWithinLimits(ctx, bot, "sendMessage", chatId, params{
+		"chat_id":                  chatId,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}, msg)
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
 func (t *telegramImpl) Send(ctx context.Context, bot, text string, channel int) error {
 	l := t.l().C(ctx).Mth("send").F(log.FF{"channel": channel}).Trc(text)
 
@@ -37,29 +298,123 @@ func (t *telegramImpl) Send(ctx context.Context, bot, text string, channel int)
 		return ErrTelegramBotEmpty(ctx)
 	}
 
-	// send request
-	rs, err := http.Get(fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?&parse_mode=html&chat_id=%d&text=%s&disable_web_page_preview=True", bot, channel, text))
-	if err != nil {
-		return ErrTelegramRequestFailed(ctx, err)
+	for _, part := range SplitMessage(text, MaxMessageLength) {
+		if _, err := t.sendMessage(ctx, bot, part, int64(channel)); err != nil {
+			return err
+		}
 	}
-	defer func() { _ = rs.Body.Close() }()
+	l.Trc("ok")
+	return nil
+}
 
-	// read and unmarshal response
-	body, err := ioutil.ReadAll(rs.Body)
-	if err != nil {
-		return ErrTelegramRequestFailed(ctx, err)
+func (t *telegramImpl) SendMessage(ctx context.Context, bot, text string, chatId int64) (*Message, error) {
+	t.l().C(ctx).Mth("send-message").F(log.FF{"chatId": chatId}).Trc(text)
+
+	if bot == "" {
+		return nil, ErrTelegramBotEmpty(ctx)
+	}
+	if MessageLength(text) > MaxMessageLength {
+		return nil, ErrTelegramMessageTooLong(ctx, MaxMessageLength)
 	}
+	return t.sendMessage(ctx, bot, text, chatId)
+}
 
-	// check response
-	rsBody := map[string]interface{}{}
-	_ = json.Unmarshal(body, &rsBody)
-	if rs.StatusCode < 300 && rsBody != nil {
-		if okRes, ok := rsBody["ok"]; ok {
-			if r, ok := okRes.(bool); ok && r {
-				l.Trc("ok")
-				return nil
+func (t *telegramImpl) EditMessageText(ctx context.Context, bot string, chatId, messageId int64, text string) error {
+	t.l().C(ctx).Mth("edit-message-text").F(log.FF{"chatId": chatId, "messageId": messageId}).Trc(text)
+
+	if bot == "" {
+		return ErrTelegramBotEmpty(ctx)
+	}
+	if MessageLength(text) > MaxMessageLength {
+		return ErrTelegramMessageTooLong(ctx, MaxMessageLength)
+	}
+	err := t.callWithinLimits(ctx, bot, "editMessageText", chatId, params{
+		"chat_id":                  chatId,
+		"message_id":               messageId,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}, nil)
+	// Bot API rejects edits which don't change the message
+	if err != nil && IsRejected(err) && strings.Contains(rejectDescription(err), "message is not modified") {
+		return nil
+	}
+	return err
+}
+
+func (t *telegramImpl) GetUpdates(ctx context.Context, bot string, offset int64, timeoutSec int) ([]*Update, error) {
+	t.l().C(ctx).Mth("get-updates").F(log.FF{"offset": offset}).Trc()
+	var updates []*Update
+	// request lasts longer than long polling
+	if err := t.call(ctx, bot, "getUpdates", params{"offset": offset, "timeout": timeoutSec, "allowed_updates": allowedUpdates}, time.Duration(timeoutSec)*time.Second+defaultRequestTimeout, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+func (t *telegramImpl) Poll(ctx context.Context, bot string, timeoutSec int, handler UpdateHandler) {
+	l := t.l().C(ctx).Mth("poll")
+	if timeoutSec <= 0 {
+		timeoutSec = defaultPollTimeoutSec
+	}
+	var offset int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		updates, err := t.GetUpdates(ctx, bot, offset, timeoutSec)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			l.E(err).Err("get updates")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pollRetryDelay):
 			}
+			continue
 		}
+		for _, u := range updates {
+			// updates are confirmed by the next request with the greater offset, so a failed update isn't received again
+			if u.UpdateId >= offset {
+				offset = u.UpdateId + 1
+			}
+			handler(ctx, u)
+		}
+	}
+}
+
+func (t *telegramImpl) SetWebhook(ctx context.Context, bot, webhookUrl, secret string) error {
+	t.l().C(ctx).Mth("set-webhook").F(log.FF{"url": webhookUrl}).Dbg()
+	p := params{"url": webhookUrl, "allowed_updates": allowedUpdates}
+	if secret != "" {
+		p["secret_token"] = secret
+	}
+	return t.call(ctx, bot, "setWebhook", p, defaultRequestTimeout, nil)
+}
+
+func (t *telegramImpl) DeleteWebhook(ctx context.Context, bot string) error {
+	t.l().C(ctx).Mth("delete-webhook").Dbg()
+	return t.call(ctx, bot, "deleteWebhook", params{}, defaultRequestTimeout, nil)
+}
+
+func (t *telegramImpl) GetMe(ctx context.Context, bot string) (*User, error) {
+	t.l().C(ctx).Mth("get-me").Trc()
+	user := &User{}
+	if err := t.call(ctx, bot, "getMe", params{}, defaultRequestTimeout, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (t *telegramImpl) GetChatMember(ctx context.Context, bot string, chatId, userId int64) (*ChatMember, error) {
+	t.l().C(ctx).Mth("get-chat-member").F(log.FF{"chatId": chatId, "userId": userId}).Trc()
+	member := &ChatMember{}
+	if err := t.call(ctx, bot, "getChatMember", params{"chat_id": chatId, "user_id": userId}, defaultRequestTimeout, member); err != nil {
+		return nil, err
 	}
-	return ErrTelegramResponseError(ctx, rs.Status, string(body))
+	return member, nil
 }
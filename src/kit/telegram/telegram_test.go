@@ -0,0 +1,253 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var logger = log.Init(&log.Config{Level: log.InfoLevel})
+var logf = func() log.CLogger {
+	return log.L(logger)
+}
+
+type telegramTestSuite struct {
+	kitTestSuite.Suite
+	api *TestBotApiServer
+	svc Telegram
+}
+
+func (s *telegramTestSuite) SetupSuite() {
+	s.Suite.Init(logf)
+}
+
+func TestTelegramSuite(t *testing.T) {
+	suite.Run(t, new(telegramTestSuite))
+}
+
+func (s *telegramTestSuite) SetupTest() {
+	s.api = NewTestBotApiServer()
+	s.svc = NewTelegram(logf, s.api.Config())
+}
+
+func (s *telegramTestSuite) TearDownTest() {
+	s.api.Close()
+}
+
+func (s *telegramTestSuite) Test_Send() {
+	// text is passed as is, no url encoding
+	s.NoError(s.svc.Send(s.Ctx, "token", "profit <b>5%</b> &amp; more #USDT\n\U0001F525", 100))
+	msgs := s.api.Messages()
+	s.Len(msgs, 1)
+	s.Equal(&TestBotMessage{Bot: "token", ChatId: 100, MessageId: 1, Text: "profit <b>5%</b> &amp; more #USDT\n\U0001F525"}, msgs[0])
+}
+
+func (s *telegramTestSuite) Test_Send_Fail() {
+	s.AssertAppErr(s.svc.Send(s.Ctx, "", "text", 100), ErrCodeTelegramBotEmpty)
+	err := s.svc.Send(s.Ctx, "token", "", 100)
+	s.AssertAppErr(err, ErrCodeTelegramRequestRejected)
+	s.True(IsRejected(err))
+}
+
+func (s *telegramTestSuite) Test_Send_WhenLong_Split() {
+	line := "<b>" + strings.Repeat("x", 1000) + "</b>\n"
+	s.NoError(s.svc.Send(s.Ctx, "token", strings.Repeat(line, 5), 100))
+	msgs := s.api.Messages()
+	s.Len(msgs, 2)
+	s.Equal(strings.Repeat(line, 4), msgs[0].Text)
+	s.Equal(line, msgs[1].Text)
+}
+
+func (s *telegramTestSuite) Test_Send_WhenTooManyRequests_Retry() {
+	s.api.Throttle(1, 1)
+	start := time.Now()
+	s.NoError(s.svc.Send(s.Ctx, "token", "text", 100))
+	s.True(time.Since(start) >= time.Second)
+	s.Len(s.api.Messages(), 1)
+}
+
+func (s *telegramTestSuite) Test_Send_WhenRetryAfterTooLong_Fail() {
+	s.svc = NewTelegram(logf, &Config{BaseUrl: s.api.Config().BaseUrl, RateLimit: &RateLimit{ChatPerSec: 1000, MaxRetryAfterSec: 5}})
+	s.api.Throttle(1, 60)
+	err := s.svc.Send(s.Ctx, "token", "text", 100)
+	s.AssertAppErr(err, ErrCodeTelegramTooManyRequests)
+	retryAfter, ok := RetryAfter(err)
+	s.True(ok)
+	s.Equal(time.Minute, retryAfter)
+	s.Empty(s.api.Messages())
+}
+
+func (s *telegramTestSuite) Test_Send_RateLimit() {
+	s.svc = NewTelegram(logf, &Config{BaseUrl: s.api.Config().BaseUrl, RateLimit: &RateLimit{ChatPerSec: 5}})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		s.NoError(s.svc.Send(s.Ctx, "token", "text", 100))
+	}
+	// the first message is sent immediately, others are spaced by 200ms
+	s.True(time.Since(start) >= time.Millisecond*400)
+	// other chats aren't affected
+	start = time.Now()
+	s.NoError(s.svc.Send(s.Ctx, "token", "text", 200))
+	s.True(time.Since(start) < time.Millisecond*200)
+}
+
+func (s *telegramTestSuite) Test_SendMessage_Edit() {
+	msg, err := s.svc.SendMessage(s.Ctx, "token", "profit <b>5%</b>", -100)
+	s.NoError(err)
+	s.NotEmpty(msg.MessageId)
+
+	s.NoError(s.svc.EditMessageText(s.Ctx, "token", -100, msg.MessageId, "profit <b>3%</b>"))
+	// not modified text isn't an error
+	s.NoError(s.svc.EditMessageText(s.Ctx, "token", -100, msg.MessageId, "profit <b>3%</b>"))
+	msgs := s.api.Messages()
+	s.Len(msgs, 1)
+	s.Equal("profit <b>3%</b>", msgs[0].Text)
+	s.Equal(1, msgs[0].Edits)
+
+	// the message is deleted from the chat
+	err = s.svc.EditMessageText(s.Ctx, "token", -100, msg.MessageId+1, "text")
+	s.AssertAppErr(err, ErrCodeTelegramRequestRejected)
+}
+
+func (s *telegramTestSuite) Test_SendMessage_WhenTooLong_Fail() {
+	_, err := s.svc.SendMessage(s.Ctx, "token", strings.Repeat("x", MaxMessageLength+1), 100)
+	s.AssertAppErr(err, ErrCodeTelegramMessageTooLong)
+	s.AssertAppErr(s.svc.EditMessageText(s.Ctx, "token", 100, 1, strings.Repeat("x", MaxMessageLength+1)), ErrCodeTelegramMessageTooLong)
+	_, err = s.svc.SendMessage(s.Ctx, "", "text", 100)
+	s.AssertAppErr(err, ErrCodeTelegramBotEmpty)
+	s.Empty(s.api.Messages())
+}
+
+func (s *telegramTestSuite) Test_GetUpdates() {
+	first := s.api.PushText(1, "user", "/start")
+	second := s.api.PushText(1, "user", "/top 3")
+
+	updates, err := s.svc.GetUpdates(s.Ctx, "token", 0, 0)
+	s.NoError(err)
+	s.Len(updates, 2)
+	s.Equal(first.UpdateId, updates[0].UpdateId)
+	s.Equal(ChatTypePrivate, updates[0].Message.Chat.Type)
+	cmd, args := updates[1].Message.Command()
+	s.Equal("top", cmd)
+	s.Equal([]string{"3"}, args)
+
+	// confirmed updates aren't received again
+	updates, err = s.svc.GetUpdates(s.Ctx, "token", second.UpdateId+1, 0)
+	s.NoError(err)
+	s.Empty(updates)
+}
+
+func (s *telegramTestSuite) Test_Poll() {
+	ctx, cancel := context.WithCancel(s.Ctx)
+	defer cancel()
+	received := make(chan *Update, 10)
+	go s.svc.Poll(ctx, "token", 1, func(ctx context.Context, update *Update) {
+		received <- update
+	})
+
+	s.api.PushText(1, "user", "one")
+	s.api.PushText(1, "user", "two")
+	for _, expected := range []string{"one", "two"} {
+		select {
+		case u := <-received:
+			s.Equal(expected, u.Message.Text)
+		case <-time.After(time.Second * 3):
+			s.Fail("update not received")
+		}
+	}
+}
+
+func (s *telegramTestSuite) Test_Webhook() {
+	received := make(chan *Update, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !CheckWebhookSecret("secret", r.Header.Get(WebhookSecretHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		u := &Update{}
+		_ = json.NewDecoder(r.Body).Decode(u)
+		received <- u
+	}))
+	defer hook.Close()
+
+	s.NoError(s.svc.SetWebhook(s.Ctx, "token", hook.URL, "secret"))
+	// getUpdates isn't allowed while webhook is set
+	_, err := s.svc.GetUpdates(s.Ctx, "token", 0, 0)
+	s.AssertAppErr(err, ErrCodeTelegramResponseError)
+
+	s.api.PushText(1, "user", "hello")
+	select {
+	case u := <-received:
+		s.Equal("hello", u.Message.Text)
+	default:
+		s.Fail("update not posted")
+	}
+
+	s.NoError(s.svc.DeleteWebhook(s.Ctx, "token"))
+	webhookUrl, _ := s.api.Webhook()
+	s.Empty(webhookUrl)
+}
+
+func (s *telegramTestSuite) Test_Command() {
+	tests := []struct {
+		text string
+		cmd  string
+		args []string
+	}{
+		{"/Subscribe@cryptocare_bot 1.5 USDT", "subscribe", []string{"1.5", "USDT"}},
+		{"  /pause  ", "pause", []string{}},
+		{"hello", "", nil},
+		{"", "", nil},
+	}
+	for _, tt := range tests {
+		cmd, args := (&Message{Text: tt.text}).Command()
+		s.Equal(tt.cmd, cmd)
+		s.Equal(tt.args, args)
+	}
+}
+
+func (s *telegramTestSuite) Test_GetMe_GetChatMember() {
+	me, err := s.svc.GetMe(s.Ctx, "token")
+	s.NoError(err)
+	s.Equal(TestBotUserId, me.Id)
+	s.True(me.IsBot)
+
+	s.api.SetChatMember(-100, TestBotUserId, ChatMemberStatusAdministrator)
+	member, err := s.svc.GetChatMember(s.Ctx, "token", -100, TestBotUserId)
+	s.NoError(err)
+	s.True(member.IsAdmin())
+	member, err = s.svc.GetChatMember(s.Ctx, "token", -100, 1)
+	s.NoError(err)
+	s.Equal(ChatMemberStatusLeft, member.Status)
+	s.False(member.IsAdmin())
+}
+
+func (s *telegramTestSuite) Test_GetUpdates_ChannelPost() {
+	s.api.PushChannelPost(-100, "code")
+	updates, err := s.svc.GetUpdates(s.Ctx, "token", 0, 0)
+	s.NoError(err)
+	s.Len(updates, 1)
+	s.Nil(updates[0].Message)
+	s.Equal(int64(-100), updates[0].ChannelPost.Chat.Id)
+	s.Equal("code", updates[0].ChannelPost.Text)
+}
+
+func (s *telegramTestSuite) Test_SetRateLimit_PerBot() {
+	impl := s.svc.(*telegramImpl)
+	impl.SetRateLimit("branded", &RateLimit{GlobalPerSec: 5})
+	s.Equal(5.0, impl.scheduler("branded").cfg.GlobalPerSec)
+	// other bots keep their own budget with the configured limits
+	configured := s.api.Config().RateLimit.GlobalPerSec
+	s.Equal(configured, impl.scheduler("token").cfg.GlobalPerSec)
+	s.NotSame(impl.scheduler("branded"), impl.scheduler("token"))
+
+	impl.SetRateLimit("branded", nil)
+	s.Equal(configured, impl.scheduler("branded").cfg.GlobalPerSec)
+}
@@ -0,0 +1,339 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TestBotMessage message sent to the test Bot API server
+type TestBotMessage struct {
+	Bot       string // Bot token
+	ChatId    int64  // ChatId target chat
+	MessageId int64  // MessageId id of the message in the chat
+	Text      string // Text message text, it's replaced by editMessageText
+	Edits     int    // Edits number of times the message has been edited
+}
+
+// TestBotUserId id of the bot user of the test Bot API server
+const TestBotUserId = int64(777)
+
+// TestBotApiServer is a local Bot API stand-in
+// it captures sent messages, serves queued updates by getUpdates and posts them to the registered webhook
+type TestBotApiServer struct {
+	sync.Mutex
+	server        *httptest.Server
+	members       map[int64]map[int64]string // members statuses by chat and user
+	messages      []*TestBotMessage
+	updates       []*Update
+	nextUpdateId  int64
+	nextMessageId int64
+	webhookUrl    string
+	webhookSecret string
+	notify        chan struct{}
+	throttled     int // throttled number of next sendMessage requests rejected with 429
+	retryAfter    int
+}
+
+// NewTestBotApiServer starts server on a random local port
+func NewTestBotApiServer() *TestBotApiServer {
+	s := &TestBotApiServer{nextUpdateId: 1, nextMessageId: 1, notify: make(chan struct{}), members: make(map[int64]map[int64]string)}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Config returns config to connect to the server, rate limits are relaxed so tests aren't slowed down
+func (s *TestBotApiServer) Config() *Config {
+	return &Config{BaseUrl: s.server.URL, RateLimit: &RateLimit{GlobalPerSec: 1000, ChatPerSec: 1000, GroupPerMin: 60000}}
+}
+
+// Close stops server
+func (s *TestBotApiServer) Close() {
+	s.server.Close()
+}
+
+// Messages returns sent messages
+func (s *TestBotApiServer) Messages() []*TestBotMessage {
+	s.Lock()
+	defer s.Unlock()
+	r := make([]*TestBotMessage, 0, len(s.messages))
+	for _, m := range s.messages {
+		c := *m
+		r = append(r, &c)
+	}
+	return r
+}
+
+// Webhook returns registered webhook url and secret
+func (s *TestBotApiServer) Webhook() (string, string) {
+	s.Lock()
+	defer s.Unlock()
+	return s.webhookUrl, s.webhookSecret
+}
+
+// Throttle makes the server reject next n sendMessage requests with 429 asking to retry after the given seconds
+func (s *TestBotApiServer) Throttle(n, retryAfterSec int) {
+	s.Lock()
+	defer s.Unlock()
+	s.throttled, s.retryAfter = n, retryAfterSec
+}
+
+// PushText queues a text message of the user sent to the private chat with the bot
+func (s *TestBotApiServer) PushText(userId int64, username, text string) *Update {
+	return s.PushUpdate(&Update{Message: &Message{
+		From: &User{Id: userId, Username: username},
+		Chat: &Chat{Id: userId, Type: ChatTypePrivate, Username: username},
+		Text: text,
+	}})
+}
+
+// SetChatMember sets status of the user in the chat, use TestBotUserId for the bot itself
+// users not set are reported as left
+func (s *TestBotApiServer) SetChatMember(chatId, userId int64, status string) {
+	s.Lock()
+	defer s.Unlock()
+	if s.members[chatId] == nil {
+		s.members[chatId] = make(map[int64]string)
+	}
+	s.members[chatId][userId] = status
+}
+
+// PushChannelPost queues a post in the channel
+func (s *TestBotApiServer) PushChannelPost(chatId int64, text string) *Update {
+	return s.PushUpdate(&Update{ChannelPost: &Message{
+		Chat: &Chat{Id: chatId, Type: ChatTypeChannel},
+		Text: text,
+	}})
+}
+
+// PushUpdate queues the update, ids are assigned if empty
+// if webhook is registered, the update is posted to the webhook instead
+func (s *TestBotApiServer) PushUpdate(update *Update) *Update {
+	s.Lock()
+	if update.UpdateId == 0 {
+		update.UpdateId = s.nextUpdateId
+	}
+	s.nextUpdateId = update.UpdateId + 1
+	for _, msg := range []*Message{update.Message, update.ChannelPost} {
+		if msg == nil {
+			continue
+		}
+		if msg.MessageId == 0 {
+			msg.MessageId = s.nextMessageId
+			s.nextMessageId++
+		}
+		if msg.Date == 0 {
+			msg.Date = time.Now().Unix()
+		}
+	}
+	webhookUrl, secret := s.webhookUrl, s.webhookSecret
+	if webhookUrl == "" {
+		s.updates = append(s.updates, update)
+		// wake up pending long polling requests
+		close(s.notify)
+		s.notify = make(chan struct{})
+	}
+	s.Unlock()
+
+	if webhookUrl != "" {
+		body, _ := json.Marshal(update)
+		rq, _ := http.NewRequest(http.MethodPost, webhookUrl, bytes.NewReader(body))
+		rq.Header.Set("Content-Type", "application/json")
+		if secret != "" {
+			rq.Header.Set(WebhookSecretHeader, secret)
+		}
+		if rs, err := http.DefaultClient.Do(rq); err == nil {
+			_ = rs.Body.Close()
+		}
+	}
+	return update
+}
+
+func (s *TestBotApiServer) handle(w http.ResponseWriter, r *http.Request) {
+	// /bot<token>/<method>
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "bot") || parts[0] == "bot" {
+		s.reply(w, http.StatusNotFound, nil, "Not Found")
+		return
+	}
+	bot := strings.TrimPrefix(parts[0], "bot")
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+		s.reply(w, http.StatusBadRequest, nil, "Bad Request: JSON body expected")
+		return
+	}
+	p := map[string]interface{}{}
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&p); err != nil {
+		s.reply(w, http.StatusBadRequest, nil, "Bad Request: invalid JSON")
+		return
+	}
+	param := func(name string) string {
+		switch v := p[name].(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		case json.Number:
+			return v.String()
+		default:
+			b, _ := json.Marshal(v)
+			return string(b)
+		}
+	}
+	r.Form = url.Values{}
+	for name := range p {
+		r.Form.Set(name, param(name))
+	}
+
+	switch parts[1] {
+	case "sendMessage":
+		chatId, err := strconv.ParseInt(r.Form.Get("chat_id"), 10, 64)
+		text := r.Form.Get("text")
+		if err != nil || text == "" {
+			s.reply(w, http.StatusBadRequest, nil, "Bad Request: chat_id and text are required")
+			return
+		}
+		if len([]rune(text)) > MaxMessageLength {
+			s.reply(w, http.StatusBadRequest, nil, "Bad Request: message is too long")
+			return
+		}
+		s.Lock()
+		if s.throttled > 0 {
+			s.throttled--
+			retryAfter := s.retryAfter
+			s.Unlock()
+			s.replyTooManyRequests(w, retryAfter)
+			return
+		}
+		s.messages = append(s.messages, &TestBotMessage{Bot: bot, ChatId: chatId, MessageId: s.nextMessageId, Text: text})
+		msg := &Message{MessageId: s.nextMessageId, Chat: &Chat{Id: chatId}, Date: time.Now().Unix(), Text: text}
+		s.nextMessageId++
+		s.Unlock()
+		s.reply(w, http.StatusOK, msg, "")
+	case "editMessageText":
+		s.editMessageText(w, r)
+	case "getUpdates":
+		s.getUpdates(w, r)
+	case "getMe":
+		s.reply(w, http.StatusOK, &User{Id: TestBotUserId, IsBot: true, Username: "test_bot"}, "")
+	case "getChatMember":
+		chatId, _ := strconv.ParseInt(r.Form.Get("chat_id"), 10, 64)
+		userId, _ := strconv.ParseInt(r.Form.Get("user_id"), 10, 64)
+		s.Lock()
+		status, ok := s.members[chatId][userId]
+		s.Unlock()
+		if !ok {
+			status = ChatMemberStatusLeft
+		}
+		s.reply(w, http.StatusOK, &ChatMember{User: &User{Id: userId}, Status: status}, "")
+	case "setWebhook":
+		s.Lock()
+		s.webhookUrl, s.webhookSecret = r.Form.Get("url"), r.Form.Get("secret_token")
+		s.Unlock()
+		s.reply(w, http.StatusOK, true, "")
+	case "deleteWebhook":
+		s.Lock()
+		s.webhookUrl, s.webhookSecret = "", ""
+		s.Unlock()
+		s.reply(w, http.StatusOK, true, "")
+	default:
+		s.reply(w, http.StatusNotFound, nil, "Not Found: method not found")
+	}
+}
+
+func (s *TestBotApiServer) editMessageText(w http.ResponseWriter, r *http.Request) {
+	chatId, _ := strconv.ParseInt(r.Form.Get("chat_id"), 10, 64)
+	messageId, _ := strconv.ParseInt(r.Form.Get("message_id"), 10, 64)
+	text := r.Form.Get("text")
+	if text == "" || len([]rune(text)) > MaxMessageLength {
+		s.reply(w, http.StatusBadRequest, nil, "Bad Request: text is empty or too long")
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	for _, m := range s.messages {
+		if m.ChatId != chatId || m.MessageId != messageId {
+			continue
+		}
+		if m.Text == text {
+			s.reply(w, http.StatusBadRequest, nil, "Bad Request: message is not modified: specified new message content and reply markup are exactly the same as a current content and reply markup of the message")
+			return
+		}
+		m.Text = text
+		m.Edits++
+		s.reply(w, http.StatusOK, &Message{MessageId: messageId, Chat: &Chat{Id: chatId}, Date: time.Now().Unix(), Text: text}, "")
+		return
+	}
+	s.reply(w, http.StatusBadRequest, nil, "Bad Request: message to edit not found")
+}
+
+func (s *TestBotApiServer) getUpdates(w http.ResponseWriter, r *http.Request) {
+	offset, _ := strconv.ParseInt(r.Form.Get("offset"), 10, 64)
+	timeoutSec, _ := strconv.Atoi(r.Form.Get("timeout"))
+	deadline := time.After(time.Duration(timeoutSec) * time.Second)
+	for {
+		s.Lock()
+		if s.webhookUrl != "" {
+			s.Unlock()
+			s.reply(w, http.StatusConflict, nil, "Conflict: can't use getUpdates method while webhook is active")
+			return
+		}
+		// updates with ids less than the offset are confirmed
+		var updates []*Update
+		var rest []*Update
+		for _, u := range s.updates {
+			if u.UpdateId >= offset {
+				updates = append(updates, u)
+				rest = append(rest, u)
+			}
+		}
+		s.updates = rest
+		notify := s.notify
+		s.Unlock()
+
+		if len(updates) > 0 {
+			s.reply(w, http.StatusOK, updates, "")
+			return
+		}
+		select {
+		case <-notify:
+		case <-deadline:
+			s.reply(w, http.StatusOK, []*Update{}, "")
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *TestBotApiServer) replyTooManyRequests(w http.ResponseWriter, retryAfterSec int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":          false,
+		"error_code":  http.StatusTooManyRequests,
+		"description": fmt.Sprintf("Too Many Requests: retry after %d", retryAfterSec),
+		"parameters":  map[string]interface{}{"retry_after": retryAfterSec},
+	})
+}
+
+func (s *TestBotApiServer) reply(w http.ResponseWriter, status int, result interface{}, description string) {
+	rs := map[string]interface{}{"ok": status == http.StatusOK}
+	if status == http.StatusOK {
+		rs["result"] = result
+	} else {
+		rs["error_code"] = status
+		rs["description"] = description
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(rs)
+}
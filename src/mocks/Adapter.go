@@ -19,6 +19,57 @@ type Adapter struct {
 	mock.Mock
 }
 
+// AddDeliveries provides a mock function with given fields: ctx, deliveries
+func (_m *Adapter) AddDeliveries(ctx context.Context, deliveries []*domain.OutboxDelivery) error {
+	ret := _m.Called(ctx, deliveries)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.OutboxDelivery) error); ok {
+		r0 = rf(ctx, deliveries)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ArchiveChains provides a mock function with given fields: ctx, chains
+func (_m *Adapter) ArchiveChains(ctx context.Context, chains []*domain.ProfitableChain) error {
+	ret := _m.Called(ctx, chains)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.ProfitableChain) error); ok {
+		r0 = rf(ctx, chains)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ClaimDeliveries provides a mock function with given fields: ctx, now, limit, leaseUntil
+func (_m *Adapter) ClaimDeliveries(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]*domain.OutboxDelivery, error) {
+	ret := _m.Called(ctx, now, limit, leaseUntil)
+
+	var r0 []*domain.OutboxDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, time.Time) []*domain.OutboxDelivery); ok {
+		r0 = rf(ctx, now, limit, leaseUntil)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.OutboxDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int, time.Time) error); ok {
+		r1 = rf(ctx, now, limit, leaseUntil)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Close provides a mock function with given fields: ctx
 func (_m *Adapter) Close(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -61,6 +112,62 @@ func (_m *Adapter) CreateUser(ctx context.Context, u *auth.User) error {
 	return r0
 }
 
+// DeleteBid provides a mock function with given fields: ctx, bidId
+func (_m *Adapter) DeleteBid(ctx context.Context, bidId string) error {
+	ret := _m.Called(ctx, bidId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, bidId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDeliveries provides a mock function with given fields: ctx, before
+func (_m *Adapter) DeleteDeliveries(ctx context.Context, before time.Time) error {
+	ret := _m.Called(ctx, before)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteLink provides a mock function with given fields: ctx, userId
+func (_m *Adapter) DeleteLink(ctx context.Context, userId string) error {
+	ret := _m.Called(ctx, userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRatePoints provides a mock function with given fields: ctx, before
+func (_m *Adapter) DeleteRatePoints(ctx context.Context, before time.Time) error {
+	ret := _m.Called(ctx, before)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteSubscription provides a mock function with given fields: ctx, subsId
 func (_m *Adapter) DeleteSubscription(ctx context.Context, subsId string) error {
 	ret := _m.Called(ctx, subsId)
@@ -112,6 +219,29 @@ func (_m *Adapter) Get(ctx context.Context, sid string) (*auth.Session, error) {
 	return r0, r1
 }
 
+// GetArchivedChain provides a mock function with given fields: ctx, chainId
+func (_m *Adapter) GetArchivedChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
+	ret := _m.Called(ctx, chainId)
+
+	var r0 *domain.ProfitableChain
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.ProfitableChain); ok {
+		r0 = rf(ctx, chainId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ProfitableChain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, chainId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBidsByIds provides a mock function with given fields: ctx, ids
 func (_m *Adapter) GetBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
 	ret := _m.Called(ctx, ids)
@@ -135,6 +265,29 @@ func (_m *Adapter) GetBidsByIds(ctx context.Context, ids []string) ([]*domain.Bi
 	return r0, r1
 }
 
+// GetBidsByOwner provides a mock function with given fields: ctx, ownerId
+func (_m *Adapter) GetBidsByOwner(ctx context.Context, ownerId string) ([]*domain.Bid, error) {
+	ret := _m.Called(ctx, ownerId)
+
+	var r0 []*domain.Bid
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.Bid); ok {
+		r0 = rf(ctx, ownerId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Bid)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ownerId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBidsLightAll provides a mock function with given fields: ctx
 func (_m *Adapter) GetBidsLightAll(ctx context.Context) ([]*domain.BidLight, error) {
 	ret := _m.Called(ctx)
@@ -204,6 +357,236 @@ func (_m *Adapter) GetByUsername(ctx context.Context, email string) (*auth.User,
 	return r0, r1
 }
 
+// GetChannelVerification provides a mock function with given fields: ctx, userId, chatId
+func (_m *Adapter) GetChannelVerification(ctx context.Context, userId string, chatId int64) (*domain.TelegramChannelVerification, error) {
+	ret := _m.Called(ctx, userId, chatId)
+
+	var r0 *domain.TelegramChannelVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) *domain.TelegramChannelVerification); ok {
+		r0 = rf(ctx, userId, chatId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramChannelVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, userId, chatId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChannelVerificationByCode provides a mock function with given fields: ctx, chatId, code
+func (_m *Adapter) GetChannelVerificationByCode(ctx context.Context, chatId int64, code string) (*domain.TelegramChannelVerification, error) {
+	ret := _m.Called(ctx, chatId, code)
+
+	var r0 *domain.TelegramChannelVerification
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *domain.TelegramChannelVerification); ok {
+		r0 = rf(ctx, chatId, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramChannelVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, chatId, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChannelVerifications provides a mock function with given fields: ctx, userId
+func (_m *Adapter) GetChannelVerifications(ctx context.Context, userId string) ([]*domain.TelegramChannelVerification, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 []*domain.TelegramChannelVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.TelegramChannelVerification); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.TelegramChannelVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeliveries provides a mock function with given fields: ctx, ids
+func (_m *Adapter) GetDeliveries(ctx context.Context, ids []string) ([]*domain.OutboxDelivery, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []*domain.OutboxDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*domain.OutboxDelivery); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.OutboxDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExpiringChains provides a mock function with given fields: ctx, from, to
+func (_m *Adapter) GetExpiringChains(ctx context.Context, from time.Time, to time.Time) ([]*domain.ProfitableChain, error) {
+	ret := _m.Called(ctx, from, to)
+
+	var r0 []*domain.ProfitableChain
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) []*domain.ProfitableChain); ok {
+		r0 = rf(ctx, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ProfitableChain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLinkByTelegramUser provides a mock function with given fields: ctx, telegramUserId
+func (_m *Adapter) GetLinkByTelegramUser(ctx context.Context, telegramUserId int64) (*domain.TelegramLink, error) {
+	ret := _m.Called(ctx, telegramUserId)
+
+	var r0 *domain.TelegramLink
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *domain.TelegramLink); ok {
+		r0 = rf(ctx, telegramUserId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramLink)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, telegramUserId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLinkByUser provides a mock function with given fields: ctx, userId
+func (_m *Adapter) GetLinkByUser(ctx context.Context, userId string) (*domain.TelegramLink, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 *domain.TelegramLink
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TelegramLink); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramLink)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrivateBidOwners provides a mock function with given fields: ctx
+func (_m *Adapter) GetPrivateBidOwners(ctx context.Context) ([]string, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrivateBidsByIds provides a mock function with given fields: ctx, ids
+func (_m *Adapter) GetPrivateBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []*domain.Bid
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*domain.Bid); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Bid)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrivateBidsLightByOwner provides a mock function with given fields: ctx, ownerId
+func (_m *Adapter) GetPrivateBidsLightByOwner(ctx context.Context, ownerId string) ([]*domain.BidLight, error) {
+	ret := _m.Called(ctx, ownerId)
+
+	var r0 []*domain.BidLight
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.BidLight); ok {
+		r0 = rf(ctx, ownerId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.BidLight)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ownerId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProfitableChain provides a mock function with given fields: ctx, chainId
 func (_m *Adapter) GetProfitableChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
 	ret := _m.Called(ctx, chainId)
@@ -250,6 +633,75 @@ func (_m *Adapter) GetProfitableChains(ctx context.Context, rq *domain.GetProfit
 	return r0, r1
 }
 
+// GetRatePoints provides a mock function with given fields: ctx, rq
+func (_m *Adapter) GetRatePoints(ctx context.Context, rq *domain.GetRatePointsRequest) ([]*domain.RatePoint, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 []*domain.RatePoint
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.GetRatePointsRequest) []*domain.RatePoint); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.RatePoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.GetRatePointsRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSpread provides a mock function with given fields: ctx, spreadId
+func (_m *Adapter) GetSpread(ctx context.Context, spreadId string) (*domain.Spread, error) {
+	ret := _m.Called(ctx, spreadId)
+
+	var r0 *domain.Spread
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Spread); ok {
+		r0 = rf(ctx, spreadId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Spread)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, spreadId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSpreads provides a mock function with given fields: ctx, rq
+func (_m *Adapter) GetSpreads(ctx context.Context, rq *domain.GetSpreadsRequest) (*domain.GetSpreadsResponse, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 *domain.GetSpreadsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.GetSpreadsRequest) *domain.GetSpreadsResponse); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.GetSpreadsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.GetSpreadsRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSubscription provides a mock function with given fields: ctx, subsId
 func (_m *Adapter) GetSubscription(ctx context.Context, subsId string) (*domain.Subscription, error) {
 	ret := _m.Called(ctx, subsId)
@@ -382,6 +834,48 @@ func (_m *Adapter) PutBids(ctx context.Context, bids []*domain.Bid, ttlSec uint3
 	return r0
 }
 
+// SaveChannelVerification provides a mock function with given fields: ctx, v
+func (_m *Adapter) SaveChannelVerification(ctx context.Context, v *domain.TelegramChannelVerification) error {
+	ret := _m.Called(ctx, v)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TelegramChannelVerification) error); ok {
+		r0 = rf(ctx, v)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveLink provides a mock function with given fields: ctx, link
+func (_m *Adapter) SaveLink(ctx context.Context, link *domain.TelegramLink) error {
+	ret := _m.Called(ctx, link)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TelegramLink) error); ok {
+		r0 = rf(ctx, link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveLinkCode provides a mock function with given fields: ctx, code
+func (_m *Adapter) SaveLinkCode(ctx context.Context, code *domain.TelegramLinkCode) error {
+	ret := _m.Called(ctx, code)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TelegramLinkCode) error); ok {
+		r0 = rf(ctx, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveProfitableChains provides a mock function with given fields: ctx, chains
 func (_m *Adapter) SaveProfitableChains(ctx context.Context, chains []*domain.ProfitableChain) error {
 	ret := _m.Called(ctx, chains)
@@ -396,6 +890,34 @@ func (_m *Adapter) SaveProfitableChains(ctx context.Context, chains []*domain.Pr
 	return r0
 }
 
+// SaveRatePoints provides a mock function with given fields: ctx, points
+func (_m *Adapter) SaveRatePoints(ctx context.Context, points []*domain.RatePoint) error {
+	ret := _m.Called(ctx, points)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.RatePoint) error); ok {
+		r0 = rf(ctx, points)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveSpreads provides a mock function with given fields: ctx, spreads
+func (_m *Adapter) SaveSpreads(ctx context.Context, spreads []*domain.Spread) error {
+	ret := _m.Called(ctx, spreads)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.Spread) error); ok {
+		r0 = rf(ctx, spreads)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveSubscription provides a mock function with given fields: ctx, subs
 func (_m *Adapter) SaveSubscription(ctx context.Context, subs *domain.Subscription) error {
 	ret := _m.Called(ctx, subs)
@@ -410,6 +932,29 @@ func (_m *Adapter) SaveSubscription(ctx context.Context, subs *domain.Subscripti
 	return r0
 }
 
+// SearchDeliveries provides a mock function with given fields: ctx, rq
+func (_m *Adapter) SearchDeliveries(ctx context.Context, rq *domain.SearchOutboxRequest) (*domain.SearchOutboxResponse, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 *domain.SearchOutboxResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.SearchOutboxRequest) *domain.SearchOutboxResponse); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SearchOutboxResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.SearchOutboxRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SearchSubscriptions provides a mock function with given fields: ctx, rq
 func (_m *Adapter) SearchSubscriptions(ctx context.Context, rq *domain.SearchSubscriptionsRequest) ([]*domain.Subscription, error) {
 	ret := _m.Called(ctx, rq)
@@ -433,6 +978,64 @@ func (_m *Adapter) SearchSubscriptions(ctx context.Context, rq *domain.SearchSub
 	return r0, r1
 }
 
+// SpreadExists provides a mock function with given fields: ctx, spreadId
+func (_m *Adapter) SpreadExists(ctx context.Context, spreadId string) (bool, error) {
+	ret := _m.Called(ctx, spreadId)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, spreadId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, spreadId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TakeLinkCode provides a mock function with given fields: ctx, code
+func (_m *Adapter) TakeLinkCode(ctx context.Context, code string) (*domain.TelegramLinkCode, error) {
+	ret := _m.Called(ctx, code)
+
+	var r0 *domain.TelegramLinkCode
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TelegramLinkCode); ok {
+		r0 = rf(ctx, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramLinkCode)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateDelivery provides a mock function with given fields: ctx, delivery
+func (_m *Adapter) UpdateDelivery(ctx context.Context, delivery *domain.OutboxDelivery) error {
+	ret := _m.Called(ctx, delivery)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.OutboxDelivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateLastActivity provides a mock function with given fields: ctx, sid, lastActivity
 func (_m *Adapter) UpdateLastActivity(ctx context.Context, sid string, lastActivity time.Time) error {
 	ret := _m.Called(ctx, sid, lastActivity)
@@ -16,6 +16,29 @@ type ArbitrageService struct {
 	mock.Mock
 }
 
+// GetArchivedChain provides a mock function with given fields: ctx, chainId
+func (_m *ArbitrageService) GetArchivedChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
+	ret := _m.Called(ctx, chainId)
+
+	var r0 *domain.ProfitableChain
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.ProfitableChain); ok {
+		r0 = rf(ctx, chainId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ProfitableChain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, chainId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProfitableChain provides a mock function with given fields: ctx, chainId
 func (_m *ArbitrageService) GetProfitableChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
 	ret := _m.Called(ctx, chainId)
@@ -5,9 +5,8 @@ package mocks
 import (
 	context "context"
 
-	mock "github.com/stretchr/testify/mock"
-
 	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
 )
 
 // BidGenerator is an autogenerated mock type for the BidGenerator type
@@ -21,8 +20,17 @@ func (_m *BidGenerator) Init(cfg *service.Config) {
 }
 
 // Run provides a mock function with given fields: ctx
-func (_m *BidGenerator) Run(ctx context.Context) {
-	_m.Called(ctx)
+func (_m *BidGenerator) Run(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
 // Stop provides a mock function with given fields: ctx
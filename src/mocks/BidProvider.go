@@ -5,10 +5,9 @@ package mocks
 import (
 	context "context"
 
-	mock "github.com/stretchr/testify/mock"
 	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
-
 	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
 )
 
 // BidProvider is an autogenerated mock type for the BidProvider type
@@ -39,6 +38,29 @@ func (_m *BidProvider) GetAssets(ctx context.Context) ([]string, error) {
 	return r0, r1
 }
 
+// GetBidLights provides a mock function with given fields: ctx
+func (_m *BidProvider) GetBidLights(ctx context.Context) ([]*domain.BidLight, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*domain.BidLight
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.BidLight); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.BidLight)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBidLightsBySourceAsset provides a mock function with given fields: ctx, srcAsset
 func (_m *BidProvider) GetBidLightsBySourceAsset(ctx context.Context, srcAsset string) ([]*domain.BidLight, error) {
 	ret := _m.Called(ctx, srcAsset)
@@ -85,27 +107,119 @@ func (_m *BidProvider) GetBidsByIds(ctx context.Context, ids []string) ([]*domai
 	return r0, r1
 }
 
+// GetExchangesStaleness provides a mock function with given fields: ctx
+func (_m *BidProvider) GetExchangesStaleness(ctx context.Context) ([]*domain.ExchangeStaleness, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*domain.ExchangeStaleness
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.ExchangeStaleness); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ExchangeStaleness)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrivateBidLights provides a mock function with given fields: ctx, ownerId
+func (_m *BidProvider) GetPrivateBidLights(ctx context.Context, ownerId string) ([]*domain.BidLight, error) {
+	ret := _m.Called(ctx, ownerId)
+
+	var r0 []*domain.BidLight
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.BidLight); ok {
+		r0 = rf(ctx, ownerId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.BidLight)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ownerId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrivateBidOwners provides a mock function with given fields: ctx
+func (_m *BidProvider) GetPrivateBidOwners(ctx context.Context) ([]string, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrivateBidsByIds provides a mock function with given fields: ctx, ids
+func (_m *BidProvider) GetPrivateBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []*domain.Bid
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*domain.Bid); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Bid)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Init provides a mock function with given fields: cfg
 func (_m *BidProvider) Init(cfg *service.Config) {
 	_m.Called(cfg)
 }
 
-// PutBid provides a mock function with given fields: ctx, bid
-func (_m *BidProvider) PutBid(ctx context.Context, bid *domain.Bid) (*domain.Bid, error) {
-	ret := _m.Called(ctx, bid)
+// PutBids provides a mock function with given fields: ctx, bids
+func (_m *BidProvider) PutBids(ctx context.Context, bids []*domain.Bid) ([]*domain.Bid, error) {
+	ret := _m.Called(ctx, bids)
 
-	var r0 *domain.Bid
-	if rf, ok := ret.Get(0).(func(context.Context, *domain.Bid) *domain.Bid); ok {
-		r0 = rf(ctx, bid)
+	var r0 []*domain.Bid
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.Bid) []*domain.Bid); ok {
+		r0 = rf(ctx, bids)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*domain.Bid)
+			r0 = ret.Get(0).([]*domain.Bid)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *domain.Bid) error); ok {
-		r1 = rf(ctx, bid)
+	if rf, ok := ret.Get(1).(func(context.Context, []*domain.Bid) error); ok {
+		r1 = rf(ctx, bids)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,61 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	pb "github.com/mikhailbolshakov/cryptocare/src/grpc/pb"
+	mock "github.com/stretchr/testify/mock"
+	grpc "google.golang.org/grpc"
+)
+
+// BidServiceClient is an autogenerated mock type for the BidServiceClient type
+type BidServiceClient struct {
+	mock.Mock
+}
+
+// UploadBids provides a mock function with given fields: ctx, opts
+func (_m *BidServiceClient) UploadBids(ctx context.Context, opts ...grpc.CallOption) (pb.BidService_UploadBidsClient, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 pb.BidService_UploadBidsClient
+	if rf, ok := ret.Get(0).(func(context.Context, ...grpc.CallOption) pb.BidService_UploadBidsClient); ok {
+		r0 = rf(ctx, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(pb.BidService_UploadBidsClient)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewBidServiceClient interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewBidServiceClient creates a new instance of BidServiceClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewBidServiceClient(t mockConstructorTestingTNewBidServiceClient) *BidServiceClient {
+	mock := &BidServiceClient{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,47 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	pb "github.com/mikhailbolshakov/cryptocare/src/grpc/pb"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// BidServiceServer is an autogenerated mock type for the BidServiceServer type
+type BidServiceServer struct {
+	mock.Mock
+}
+
+// UploadBids provides a mock function with given fields: _a0
+func (_m *BidServiceServer) UploadBids(_a0 pb.BidService_UploadBidsServer) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(pb.BidService_UploadBidsServer) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// mustEmbedUnimplementedBidServiceServer provides a mock function with given fields:
+func (_m *BidServiceServer) mustEmbedUnimplementedBidServiceServer() {
+	_m.Called()
+}
+
+type mockConstructorTestingTNewBidServiceServer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewBidServiceServer creates a new instance of BidServiceServer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewBidServiceServer(t mockConstructorTestingTNewBidServiceServer) *BidServiceServer {
+	mock := &BidServiceServer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,145 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	pb "github.com/mikhailbolshakov/cryptocare/src/grpc/pb"
+	mock "github.com/stretchr/testify/mock"
+	metadata "google.golang.org/grpc/metadata"
+)
+
+// BidService_UploadBidsServer is an autogenerated mock type for the BidService_UploadBidsServer type
+type BidService_UploadBidsServer struct {
+	mock.Mock
+}
+
+// Context provides a mock function with given fields:
+func (_m *BidService_UploadBidsServer) Context() context.Context {
+	ret := _m.Called()
+
+	var r0 context.Context
+	if rf, ok := ret.Get(0).(func() context.Context); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(context.Context)
+		}
+	}
+
+	return r0
+}
+
+// Recv provides a mock function with given fields:
+func (_m *BidService_UploadBidsServer) Recv() (*pb.Bid, error) {
+	ret := _m.Called()
+
+	var r0 *pb.Bid
+	if rf, ok := ret.Get(0).(func() *pb.Bid); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pb.Bid)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecvMsg provides a mock function with given fields: m
+func (_m *BidService_UploadBidsServer) RecvMsg(m interface{}) error {
+	ret := _m.Called(m)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(interface{}) error); ok {
+		r0 = rf(m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendAndClose provides a mock function with given fields: _a0
+func (_m *BidService_UploadBidsServer) SendAndClose(_a0 *pb.UploadBidsResponse) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*pb.UploadBidsResponse) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendHeader provides a mock function with given fields: _a0
+func (_m *BidService_UploadBidsServer) SendHeader(_a0 metadata.MD) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(metadata.MD) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendMsg provides a mock function with given fields: m
+func (_m *BidService_UploadBidsServer) SendMsg(m interface{}) error {
+	ret := _m.Called(m)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(interface{}) error); ok {
+		r0 = rf(m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetHeader provides a mock function with given fields: _a0
+func (_m *BidService_UploadBidsServer) SetHeader(_a0 metadata.MD) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(metadata.MD) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetTrailer provides a mock function with given fields: _a0
+func (_m *BidService_UploadBidsServer) SetTrailer(_a0 metadata.MD) {
+	_m.Called(_a0)
+}
+
+type mockConstructorTestingTNewBidService_UploadBidsServer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewBidService_UploadBidsServer creates a new instance of BidService_UploadBidsServer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewBidService_UploadBidsServer(t mockConstructorTestingTNewBidService_UploadBidsServer) *BidService_UploadBidsServer {
+	mock := &BidService_UploadBidsServer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -5,8 +5,8 @@ package mocks
 import (
 	context "context"
 
-	mock "github.com/stretchr/testify/mock"
 	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
 )
 
 // BidStorage is an autogenerated mock type for the BidStorage type
@@ -14,6 +14,20 @@ type BidStorage struct {
 	mock.Mock
 }
 
+// DeleteBid provides a mock function with given fields: ctx, bidId
+func (_m *BidStorage) DeleteBid(ctx context.Context, bidId string) error {
+	ret := _m.Called(ctx, bidId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, bidId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetBidsByIds provides a mock function with given fields: ctx, ids
 func (_m *BidStorage) GetBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
 	ret := _m.Called(ctx, ids)
@@ -37,6 +51,29 @@ func (_m *BidStorage) GetBidsByIds(ctx context.Context, ids []string) ([]*domain
 	return r0, r1
 }
 
+// GetBidsByOwner provides a mock function with given fields: ctx, ownerId
+func (_m *BidStorage) GetBidsByOwner(ctx context.Context, ownerId string) ([]*domain.Bid, error) {
+	ret := _m.Called(ctx, ownerId)
+
+	var r0 []*domain.Bid
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.Bid); ok {
+		r0 = rf(ctx, ownerId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Bid)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ownerId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBidsLightAll provides a mock function with given fields: ctx
 func (_m *BidStorage) GetBidsLightAll(ctx context.Context) ([]*domain.BidLight, error) {
 	ret := _m.Called(ctx)
@@ -60,6 +97,75 @@ func (_m *BidStorage) GetBidsLightAll(ctx context.Context) ([]*domain.BidLight,
 	return r0, r1
 }
 
+// GetPrivateBidOwners provides a mock function with given fields: ctx
+func (_m *BidStorage) GetPrivateBidOwners(ctx context.Context) ([]string, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrivateBidsByIds provides a mock function with given fields: ctx, ids
+func (_m *BidStorage) GetPrivateBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []*domain.Bid
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*domain.Bid); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Bid)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrivateBidsLightByOwner provides a mock function with given fields: ctx, ownerId
+func (_m *BidStorage) GetPrivateBidsLightByOwner(ctx context.Context, ownerId string) ([]*domain.BidLight, error) {
+	ret := _m.Called(ctx, ownerId)
+
+	var r0 []*domain.BidLight
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.BidLight); ok {
+		r0 = rf(ctx, ownerId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.BidLight)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ownerId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PutBids provides a mock function with given fields: ctx, bids, ttlSec
 func (_m *BidStorage) PutBids(ctx context.Context, bids []*domain.Bid, ttlSec uint32) error {
 	ret := _m.Called(ctx, bids, ttlSec)
@@ -0,0 +1,91 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ChainArchiveStorage is an autogenerated mock type for the ChainArchiveStorage type
+type ChainArchiveStorage struct {
+	mock.Mock
+}
+
+// ArchiveChains provides a mock function with given fields: ctx, chains
+func (_m *ChainArchiveStorage) ArchiveChains(ctx context.Context, chains []*domain.ProfitableChain) error {
+	ret := _m.Called(ctx, chains)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.ProfitableChain) error); ok {
+		r0 = rf(ctx, chains)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetArchivedChain provides a mock function with given fields: ctx, chainId
+func (_m *ChainArchiveStorage) GetArchivedChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
+	ret := _m.Called(ctx, chainId)
+
+	var r0 *domain.ProfitableChain
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.ProfitableChain); ok {
+		r0 = rf(ctx, chainId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ProfitableChain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, chainId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetArchivedChains provides a mock function with given fields: ctx, from, to, limit
+func (_m *ChainArchiveStorage) GetArchivedChains(ctx context.Context, from time.Time, to time.Time, limit int) ([]*domain.ProfitableChain, error) {
+	ret := _m.Called(ctx, from, to, limit)
+
+	var r0 []*domain.ProfitableChain
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, int) []*domain.ProfitableChain); ok {
+		r0 = rf(ctx, from, to, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ProfitableChain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time, int) error); ok {
+		r1 = rf(ctx, from, to, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewChainArchiveStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewChainArchiveStorage creates a new instance of ChainArchiveStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewChainArchiveStorage(t mockConstructorTestingTNewChainArchiveStorage) *ChainArchiveStorage {
+	mock := &ChainArchiveStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,63 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ChainArchiver is an autogenerated mock type for the ChainArchiver type
+type ChainArchiver struct {
+	mock.Mock
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *ChainArchiver) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// Run provides a mock function with given fields: ctx
+func (_m *ChainArchiver) Run(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stop provides a mock function with given fields: ctx
+func (_m *ChainArchiver) Stop(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewChainArchiver interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewChainArchiver creates a new instance of ChainArchiver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewChainArchiver(t mockConstructorTestingTNewChainArchiver) *ChainArchiver {
+	mock := &ChainArchiver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,82 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ChainFeed is an autogenerated mock type for the ChainFeed type
+type ChainFeed struct {
+	mock.Mock
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *ChainFeed) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// Notify provides a mock function with given fields: ctx, chains
+func (_m *ChainFeed) Notify(ctx context.Context, chains []*domain.ProfitableChain) error {
+	ret := _m.Called(ctx, chains)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.ProfitableChain) error); ok {
+		r0 = rf(ctx, chains)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Subscribe provides a mock function with given fields: ctx, filter
+func (_m *ChainFeed) Subscribe(ctx context.Context, filter *domain.SubscriptionChainFilter) (<-chan *domain.ProfitableChain, func(), error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 <-chan *domain.ProfitableChain
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.SubscriptionChainFilter) <-chan *domain.ProfitableChain); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan *domain.ProfitableChain)
+		}
+	}
+
+	var r1 func()
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.SubscriptionChainFilter) func()); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *domain.SubscriptionChainFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+type mockConstructorTestingTNewChainFeed interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewChainFeed creates a new instance of ChainFeed. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewChainFeed(t mockConstructorTestingTNewChainFeed) *ChainFeed {
+	mock := &ChainFeed{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
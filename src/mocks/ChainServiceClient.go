@@ -0,0 +1,121 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	pb "github.com/mikhailbolshakov/cryptocare/src/grpc/pb"
+	mock "github.com/stretchr/testify/mock"
+	grpc "google.golang.org/grpc"
+)
+
+// ChainServiceClient is an autogenerated mock type for the ChainServiceClient type
+type ChainServiceClient struct {
+	mock.Mock
+}
+
+// Feed provides a mock function with given fields: ctx, in, opts
+func (_m *ChainServiceClient) Feed(ctx context.Context, in *pb.ChainFeedRequest, opts ...grpc.CallOption) (pb.ChainService_FeedClient, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 pb.ChainService_FeedClient
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.ChainFeedRequest, ...grpc.CallOption) pb.ChainService_FeedClient); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(pb.ChainService_FeedClient)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *pb.ChainFeedRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChain provides a mock function with given fields: ctx, in, opts
+func (_m *ChainServiceClient) GetChain(ctx context.Context, in *pb.GetChainRequest, opts ...grpc.CallOption) (*pb.ProfitableChain, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *pb.ProfitableChain
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.GetChainRequest, ...grpc.CallOption) *pb.ProfitableChain); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pb.ProfitableChain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *pb.GetChainRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChains provides a mock function with given fields: ctx, in, opts
+func (_m *ChainServiceClient) GetChains(ctx context.Context, in *pb.GetChainsRequest, opts ...grpc.CallOption) (*pb.GetChainsResponse, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *pb.GetChainsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.GetChainsRequest, ...grpc.CallOption) *pb.GetChainsResponse); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pb.GetChainsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *pb.GetChainsRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewChainServiceClient interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewChainServiceClient creates a new instance of ChainServiceClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewChainServiceClient(t mockConstructorTestingTNewChainServiceClient) *ChainServiceClient {
+	mock := &ChainServiceClient{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,95 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	pb "github.com/mikhailbolshakov/cryptocare/src/grpc/pb"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ChainServiceServer is an autogenerated mock type for the ChainServiceServer type
+type ChainServiceServer struct {
+	mock.Mock
+}
+
+// Feed provides a mock function with given fields: _a0, _a1
+func (_m *ChainServiceServer) Feed(_a0 *pb.ChainFeedRequest, _a1 pb.ChainService_FeedServer) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*pb.ChainFeedRequest, pb.ChainService_FeedServer) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetChain provides a mock function with given fields: _a0, _a1
+func (_m *ChainServiceServer) GetChain(_a0 context.Context, _a1 *pb.GetChainRequest) (*pb.ProfitableChain, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *pb.ProfitableChain
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.GetChainRequest) *pb.ProfitableChain); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pb.ProfitableChain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *pb.GetChainRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChains provides a mock function with given fields: _a0, _a1
+func (_m *ChainServiceServer) GetChains(_a0 context.Context, _a1 *pb.GetChainsRequest) (*pb.GetChainsResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *pb.GetChainsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.GetChainsRequest) *pb.GetChainsResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pb.GetChainsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *pb.GetChainsRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mustEmbedUnimplementedChainServiceServer provides a mock function with given fields:
+func (_m *ChainServiceServer) mustEmbedUnimplementedChainServiceServer() {
+	_m.Called()
+}
+
+type mockConstructorTestingTNewChainServiceServer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewChainServiceServer creates a new instance of ChainServiceServer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewChainServiceServer(t mockConstructorTestingTNewChainServiceServer) *ChainServiceServer {
+	mock := &ChainServiceServer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -4,9 +4,10 @@ package mocks
 
 import (
 	context "context"
+	time "time"
 
-	mock "github.com/stretchr/testify/mock"
 	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
 )
 
 // ChainStorage is an autogenerated mock type for the ChainStorage type
@@ -14,6 +15,29 @@ type ChainStorage struct {
 	mock.Mock
 }
 
+// GetExpiringChains provides a mock function with given fields: ctx, from, to
+func (_m *ChainStorage) GetExpiringChains(ctx context.Context, from time.Time, to time.Time) ([]*domain.ProfitableChain, error) {
+	ret := _m.Called(ctx, from, to)
+
+	var r0 []*domain.ProfitableChain
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) []*domain.ProfitableChain); ok {
+		r0 = rf(ctx, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ProfitableChain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProfitableChain provides a mock function with given fields: ctx, chainId
 func (_m *ChainStorage) GetProfitableChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
 	ret := _m.Called(ctx, chainId)
@@ -0,0 +1,44 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ChainUpdateNotifier is an autogenerated mock type for the ChainUpdateNotifier type
+type ChainUpdateNotifier struct {
+	mock.Mock
+}
+
+// NotifyUpdated provides a mock function with given fields: ctx, chains
+func (_m *ChainUpdateNotifier) NotifyUpdated(ctx context.Context, chains []*domain.ProfitableChain) error {
+	ret := _m.Called(ctx, chains)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.ProfitableChain) error); ok {
+		r0 = rf(ctx, chains)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewChainUpdateNotifier interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewChainUpdateNotifier creates a new instance of ChainUpdateNotifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewChainUpdateNotifier(t mockConstructorTestingTNewChainUpdateNotifier) *ChainUpdateNotifier {
+	mock := &ChainUpdateNotifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
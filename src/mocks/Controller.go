@@ -13,16 +13,76 @@ type Controller struct {
 	mock.Mock
 }
 
+// ConfirmTelegramChannelVerification provides a mock function with given fields: _a0, _a1
+func (_m *Controller) ConfirmTelegramChannelVerification(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// CreateManualBid provides a mock function with given fields: _a0, _a1
+func (_m *Controller) CreateManualBid(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// CreateManualBids provides a mock function with given fields: _a0, _a1
+func (_m *Controller) CreateManualBids(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
 // CreateSubscription provides a mock function with given fields: _a0, _a1
 func (_m *Controller) CreateSubscription(_a0 http.ResponseWriter, _a1 *http.Request) {
 	_m.Called(_a0, _a1)
 }
 
+// CreateTelegramLinkCode provides a mock function with given fields: _a0, _a1
+func (_m *Controller) CreateTelegramLinkCode(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// DeleteManualBid provides a mock function with given fields: _a0, _a1
+func (_m *Controller) DeleteManualBid(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
 // DeleteSubscription provides a mock function with given fields: _a0, _a1
 func (_m *Controller) DeleteSubscription(_a0 http.ResponseWriter, _a1 *http.Request) {
 	_m.Called(_a0, _a1)
 }
 
+// DeleteTelegramLink provides a mock function with given fields: _a0, _a1
+func (_m *Controller) DeleteTelegramLink(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// GetArchivedChain provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetArchivedChain(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// GetBidsStaleness provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetBidsStaleness(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// GetManualBid provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetManualBid(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// GetManualBids provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetManualBids(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// GetMarketOverview provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetMarketOverview(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// GetOutboxDeliveries provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetOutboxDeliveries(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
 // GetProfitableChainDetails provides a mock function with given fields: _a0, _a1
 func (_m *Controller) GetProfitableChainDetails(_a0 http.ResponseWriter, _a1 *http.Request) {
 	_m.Called(_a0, _a1)
@@ -33,11 +93,41 @@ func (_m *Controller) GetProfitableChains(_a0 http.ResponseWriter, _a1 *http.Req
 	_m.Called(_a0, _a1)
 }
 
+// GetRateHistory provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetRateHistory(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// GetReferenceRates provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetReferenceRates(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// GetSpread provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetSpread(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// GetSpreads provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetSpreads(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
 // GetSubscription provides a mock function with given fields: _a0, _a1
 func (_m *Controller) GetSubscription(_a0 http.ResponseWriter, _a1 *http.Request) {
 	_m.Called(_a0, _a1)
 }
 
+// GetTelegramChannels provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetTelegramChannels(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// GetTelegramLink provides a mock function with given fields: _a0, _a1
+func (_m *Controller) GetTelegramLink(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
 // GetUserSubscriptions provides a mock function with given fields: _a0, _a1
 func (_m *Controller) GetUserSubscriptions(_a0 http.ResponseWriter, _a1 *http.Request) {
 	_m.Called(_a0, _a1)
@@ -53,8 +143,8 @@ func (_m *Controller) Logout(_a0 http.ResponseWriter, _a1 *http.Request) {
 	_m.Called(_a0, _a1)
 }
 
-// PutBid provides a mock function with given fields: _a0, _a1
-func (_m *Controller) PutBid(_a0 http.ResponseWriter, _a1 *http.Request) {
+// PreviewNotificationTemplate provides a mock function with given fields: _a0, _a1
+func (_m *Controller) PreviewNotificationTemplate(_a0 http.ResponseWriter, _a1 *http.Request) {
 	_m.Called(_a0, _a1)
 }
 
@@ -68,16 +158,41 @@ func (_m *Controller) Registration(_a0 http.ResponseWriter, _a1 *http.Request) {
 	_m.Called(_a0, _a1)
 }
 
+// ReplayOutboxDeliveries provides a mock function with given fields: _a0, _a1
+func (_m *Controller) ReplayOutboxDeliveries(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// RequestTelegramChannelVerification provides a mock function with given fields: _a0, _a1
+func (_m *Controller) RequestTelegramChannelVerification(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
+// SearchChains provides a mock function with given fields: _a0, _a1
+func (_m *Controller) SearchChains(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
 // SetPassword provides a mock function with given fields: _a0, _a1
 func (_m *Controller) SetPassword(_a0 http.ResponseWriter, _a1 *http.Request) {
 	_m.Called(_a0, _a1)
 }
 
+// TelegramWebhook provides a mock function with given fields: _a0, _a1
+func (_m *Controller) TelegramWebhook(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
 // TokenRefresh provides a mock function with given fields: _a0, _a1
 func (_m *Controller) TokenRefresh(_a0 http.ResponseWriter, _a1 *http.Request) {
 	_m.Called(_a0, _a1)
 }
 
+// UpdateManualBid provides a mock function with given fields: _a0, _a1
+func (_m *Controller) UpdateManualBid(_a0 http.ResponseWriter, _a1 *http.Request) {
+	_m.Called(_a0, _a1)
+}
+
 // UpdateSubscription provides a mock function with given fields: _a0, _a1
 func (_m *Controller) UpdateSubscription(_a0 http.ResponseWriter, _a1 *http.Request) {
 	_m.Called(_a0, _a1)
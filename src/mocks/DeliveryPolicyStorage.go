@@ -0,0 +1,117 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DeliveryPolicyStorage is an autogenerated mock type for the DeliveryPolicyStorage type
+type DeliveryPolicyStorage struct {
+	mock.Mock
+}
+
+// AddDigestItems provides a mock function with given fields: ctx, subscriptionId, items, maxItems, now
+func (_m *DeliveryPolicyStorage) AddDigestItems(ctx context.Context, subscriptionId string, items []*domain.DigestItem, maxItems int, now time.Time) error {
+	ret := _m.Called(ctx, subscriptionId, items, maxItems, now)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*domain.DigestItem, int, time.Time) error); ok {
+		r0 = rf(ctx, subscriptionId, items, maxItems, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteMessages provides a mock function with given fields: ctx, before
+func (_m *DeliveryPolicyStorage) DeleteMessages(ctx context.Context, before time.Time) error {
+	ret := _m.Called(ctx, before)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePendingDigest provides a mock function with given fields: ctx, subscriptionId, digestId
+func (_m *DeliveryPolicyStorage) DeletePendingDigest(ctx context.Context, subscriptionId string, digestId string) error {
+	ret := _m.Called(ctx, subscriptionId, digestId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, subscriptionId, digestId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPendingDigests provides a mock function with given fields: ctx
+func (_m *DeliveryPolicyStorage) GetPendingDigests(ctx context.Context) ([]*domain.PendingDigest, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*domain.PendingDigest
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.PendingDigest); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.PendingDigest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReserveMessages provides a mock function with given fields: ctx, subscriptionId, count, maxMessages, since, now
+func (_m *DeliveryPolicyStorage) ReserveMessages(ctx context.Context, subscriptionId string, count int, maxMessages int, since time.Time, now time.Time) (int, error) {
+	ret := _m.Called(ctx, subscriptionId, count, maxMessages, since, now)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int, time.Time, time.Time) int); ok {
+		r0 = rf(ctx, subscriptionId, count, maxMessages, since, now)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, subscriptionId, count, maxMessages, since, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewDeliveryPolicyStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewDeliveryPolicyStorage creates a new instance of DeliveryPolicyStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewDeliveryPolicyStorage(t mockConstructorTestingTNewDeliveryPolicyStorage) *DeliveryPolicyStorage {
+	mock := &DeliveryPolicyStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,44 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	email "github.com/mikhailbolshakov/cryptocare/src/kit/email"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Email is an autogenerated mock type for the Email type
+type Email struct {
+	mock.Mock
+}
+
+// Send provides a mock function with given fields: ctx, msg
+func (_m *Email) Send(ctx context.Context, msg *email.Message) error {
+	ret := _m.Called(ctx, msg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *email.Message) error); ok {
+		r0 = rf(ctx, msg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewEmail interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewEmail creates a new instance of Email. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewEmail(t mockConstructorTestingTNewEmail) *Email {
+	mock := &Email{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,90 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EmailVerificationStorage is an autogenerated mock type for the EmailVerificationStorage type
+type EmailVerificationStorage struct {
+	mock.Mock
+}
+
+// GetEmailVerification provides a mock function with given fields: ctx, userId, email
+func (_m *EmailVerificationStorage) GetEmailVerification(ctx context.Context, userId string, email string) (*domain.EmailVerification, error) {
+	ret := _m.Called(ctx, userId, email)
+
+	var r0 *domain.EmailVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.EmailVerification); ok {
+		r0 = rf(ctx, userId, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.EmailVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userId, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEmailVerifications provides a mock function with given fields: ctx, userId
+func (_m *EmailVerificationStorage) GetEmailVerifications(ctx context.Context, userId string) ([]*domain.EmailVerification, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 []*domain.EmailVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.EmailVerification); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.EmailVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveEmailVerification provides a mock function with given fields: ctx, v
+func (_m *EmailVerificationStorage) SaveEmailVerification(ctx context.Context, v *domain.EmailVerification) error {
+	ret := _m.Called(ctx, v)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.EmailVerification) error); ok {
+		r0 = rf(ctx, v)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewEmailVerificationStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewEmailVerificationStorage creates a new instance of EmailVerificationStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewEmailVerificationStorage(t mockConstructorTestingTNewEmailVerificationStorage) *EmailVerificationStorage {
+	mock := &EmailVerificationStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
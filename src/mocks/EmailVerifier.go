@@ -0,0 +1,126 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EmailVerifier is an autogenerated mock type for the EmailVerifier type
+type EmailVerifier struct {
+	mock.Mock
+}
+
+// Confirm provides a mock function with given fields: ctx, userId, email, code
+func (_m *EmailVerifier) Confirm(ctx context.Context, userId string, email string, code string) (*domain.EmailVerification, error) {
+	ret := _m.Called(ctx, userId, email, code)
+
+	var r0 *domain.EmailVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *domain.EmailVerification); ok {
+		r0 = rf(ctx, userId, email, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.EmailVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, userId, email, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVerifications provides a mock function with given fields: ctx, userId
+func (_m *EmailVerifier) GetVerifications(ctx context.Context, userId string) ([]*domain.EmailVerification, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 []*domain.EmailVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.EmailVerification); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.EmailVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *EmailVerifier) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// IsVerified provides a mock function with given fields: ctx, userId, emails
+func (_m *EmailVerifier) IsVerified(ctx context.Context, userId string, emails []string) (bool, error) {
+	ret := _m.Called(ctx, userId, emails)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) bool); ok {
+		r0 = rf(ctx, userId, emails)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, userId, emails)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Request provides a mock function with given fields: ctx, userId, email
+func (_m *EmailVerifier) Request(ctx context.Context, userId string, email string) (*domain.EmailVerification, error) {
+	ret := _m.Called(ctx, userId, email)
+
+	var r0 *domain.EmailVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.EmailVerification); ok {
+		r0 = rf(ctx, userId, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.EmailVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userId, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewEmailVerifier interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewEmailVerifier creates a new instance of EmailVerifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewEmailVerifier(t mockConstructorTestingTNewEmailVerifier) *EmailVerifier {
+	mock := &EmailVerifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
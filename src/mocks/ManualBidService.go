@@ -0,0 +1,142 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ManualBidService is an autogenerated mock type for the ManualBidService type
+type ManualBidService struct {
+	mock.Mock
+}
+
+// CreateBids provides a mock function with given fields: ctx, ownerId, rqs
+func (_m *ManualBidService) CreateBids(ctx context.Context, ownerId string, rqs []*domain.ManualBidRequest) ([]*domain.Bid, error) {
+	ret := _m.Called(ctx, ownerId, rqs)
+
+	var r0 []*domain.Bid
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*domain.ManualBidRequest) []*domain.Bid); ok {
+		r0 = rf(ctx, ownerId, rqs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Bid)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []*domain.ManualBidRequest) error); ok {
+		r1 = rf(ctx, ownerId, rqs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteBid provides a mock function with given fields: ctx, bidId
+func (_m *ManualBidService) DeleteBid(ctx context.Context, bidId string) error {
+	ret := _m.Called(ctx, bidId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, bidId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetBid provides a mock function with given fields: ctx, bidId
+func (_m *ManualBidService) GetBid(ctx context.Context, bidId string) (*domain.Bid, error) {
+	ret := _m.Called(ctx, bidId)
+
+	var r0 *domain.Bid
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Bid); ok {
+		r0 = rf(ctx, bidId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Bid)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, bidId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBids provides a mock function with given fields: ctx, ownerId
+func (_m *ManualBidService) GetBids(ctx context.Context, ownerId string) ([]*domain.Bid, error) {
+	ret := _m.Called(ctx, ownerId)
+
+	var r0 []*domain.Bid
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.Bid); ok {
+		r0 = rf(ctx, ownerId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Bid)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ownerId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *ManualBidService) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// UpdateBid provides a mock function with given fields: ctx, bidId, rq
+func (_m *ManualBidService) UpdateBid(ctx context.Context, bidId string, rq *domain.ManualBidRequest) (*domain.Bid, error) {
+	ret := _m.Called(ctx, bidId, rq)
+
+	var r0 *domain.Bid
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.ManualBidRequest) *domain.Bid); ok {
+		r0 = rf(ctx, bidId, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Bid)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *domain.ManualBidRequest) error); ok {
+		r1 = rf(ctx, bidId, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewManualBidService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewManualBidService creates a new instance of ManualBidService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewManualBidService(t mockConstructorTestingTNewManualBidService) *ManualBidService {
+	mock := &ManualBidService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
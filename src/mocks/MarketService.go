@@ -0,0 +1,105 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MarketService is an autogenerated mock type for the MarketService type
+type MarketService struct {
+	mock.Mock
+}
+
+// GetMarketOverview provides a mock function with given fields: ctx, rq
+func (_m *MarketService) GetMarketOverview(ctx context.Context, rq *domain.MarketOverviewRequest) (*domain.MarketOverview, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 *domain.MarketOverview
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.MarketOverviewRequest) *domain.MarketOverview); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.MarketOverview)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.MarketOverviewRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRateHistory provides a mock function with given fields: ctx, rq
+func (_m *MarketService) GetRateHistory(ctx context.Context, rq *domain.GetRateHistoryRequest) ([]*domain.RateCandle, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 []*domain.RateCandle
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.GetRateHistoryRequest) []*domain.RateCandle); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.RateCandle)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.GetRateHistoryRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReferenceRates provides a mock function with given fields: ctx
+func (_m *MarketService) GetReferenceRates(ctx context.Context) (*domain.ReferenceRates, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *domain.ReferenceRates
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.ReferenceRates); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ReferenceRates)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *MarketService) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+type mockConstructorTestingTNewMarketService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewMarketService creates a new instance of MarketService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewMarketService(t mockConstructorTestingTNewMarketService) *MarketService {
+	mock := &MarketService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -41,6 +41,22 @@ func (_m *MemCache) Get(key string) (interface{}, bool) {
 	return r0, r1
 }
 
+// Items provides a mock function with given fields:
+func (_m *MemCache) Items() map[string]interface{} {
+	ret := _m.Called()
+
+	var r0 map[string]interface{}
+	if rf, ok := ret.Get(0).(func() map[string]interface{}); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]interface{})
+		}
+	}
+
+	return r0
+}
+
 // Set provides a mock function with given fields: key, v, ttl
 func (_m *MemCache) Set(key string, v interface{}, ttl time.Duration) {
 	_m.Called(key, v, ttl)
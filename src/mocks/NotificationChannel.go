@@ -0,0 +1,72 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NotificationChannel is an autogenerated mock type for the NotificationChannel type
+type NotificationChannel struct {
+	mock.Mock
+}
+
+// Send provides a mock function with given fields: ctx, delivery
+func (_m *NotificationChannel) Send(ctx context.Context, delivery *domain.OutboxDelivery) error {
+	ret := _m.Called(ctx, delivery)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.OutboxDelivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Type provides a mock function with given fields:
+func (_m *NotificationChannel) Type() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Validate provides a mock function with given fields: ctx, notification
+func (_m *NotificationChannel) Validate(ctx context.Context, notification *domain.SubscriptionNotification) error {
+	ret := _m.Called(ctx, notification)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.SubscriptionNotification) error); ok {
+		r0 = rf(ctx, notification)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewNotificationChannel interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewNotificationChannel creates a new instance of NotificationChannel. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewNotificationChannel(t mockConstructorTestingTNewNotificationChannel) *NotificationChannel {
+	mock := &NotificationChannel{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
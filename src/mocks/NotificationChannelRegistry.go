@@ -0,0 +1,72 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NotificationChannelRegistry is an autogenerated mock type for the NotificationChannelRegistry type
+type NotificationChannelRegistry struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: channelType
+func (_m *NotificationChannelRegistry) Get(channelType string) (domain.NotificationChannel, bool) {
+	ret := _m.Called(channelType)
+
+	var r0 domain.NotificationChannel
+	if rf, ok := ret.Get(0).(func(string) domain.NotificationChannel); ok {
+		r0 = rf(channelType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.NotificationChannel)
+		}
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(channelType)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// Register provides a mock function with given fields: channel
+func (_m *NotificationChannelRegistry) Register(channel domain.NotificationChannel) {
+	_m.Called(channel)
+}
+
+// Types provides a mock function with given fields:
+func (_m *NotificationChannelRegistry) Types() []string {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewNotificationChannelRegistry interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewNotificationChannelRegistry creates a new instance of NotificationChannelRegistry. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewNotificationChannelRegistry(t mockConstructorTestingTNewNotificationChannelRegistry) *NotificationChannelRegistry {
+	mock := &NotificationChannelRegistry{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,124 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NotificationOutbox is an autogenerated mock type for the NotificationOutbox type
+type NotificationOutbox struct {
+	mock.Mock
+}
+
+// Enqueue provides a mock function with given fields: ctx, deliveries
+func (_m *NotificationOutbox) Enqueue(ctx context.Context, deliveries []*domain.OutboxDelivery) error {
+	ret := _m.Called(ctx, deliveries)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.OutboxDelivery) error); ok {
+		r0 = rf(ctx, deliveries)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *NotificationOutbox) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// Replay provides a mock function with given fields: ctx, deliveryIds
+func (_m *NotificationOutbox) Replay(ctx context.Context, deliveryIds []string) ([]*domain.OutboxDelivery, error) {
+	ret := _m.Called(ctx, deliveryIds)
+
+	var r0 []*domain.OutboxDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*domain.OutboxDelivery); ok {
+		r0 = rf(ctx, deliveryIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.OutboxDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, deliveryIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Run provides a mock function with given fields: ctx
+func (_m *NotificationOutbox) Run(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Search provides a mock function with given fields: ctx, rq
+func (_m *NotificationOutbox) Search(ctx context.Context, rq *domain.SearchOutboxRequest) (*domain.SearchOutboxResponse, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 *domain.SearchOutboxResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.SearchOutboxRequest) *domain.SearchOutboxResponse); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SearchOutboxResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.SearchOutboxRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Stop provides a mock function with given fields: ctx
+func (_m *NotificationOutbox) Stop(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewNotificationOutbox interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewNotificationOutbox creates a new instance of NotificationOutbox. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewNotificationOutbox(t mockConstructorTestingTNewNotificationOutbox) *NotificationOutbox {
+	mock := &NotificationOutbox{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
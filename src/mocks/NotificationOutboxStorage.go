@@ -0,0 +1,142 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NotificationOutboxStorage is an autogenerated mock type for the NotificationOutboxStorage type
+type NotificationOutboxStorage struct {
+	mock.Mock
+}
+
+// AddDeliveries provides a mock function with given fields: ctx, deliveries
+func (_m *NotificationOutboxStorage) AddDeliveries(ctx context.Context, deliveries []*domain.OutboxDelivery) error {
+	ret := _m.Called(ctx, deliveries)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.OutboxDelivery) error); ok {
+		r0 = rf(ctx, deliveries)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ClaimDeliveries provides a mock function with given fields: ctx, now, limit, leaseUntil
+func (_m *NotificationOutboxStorage) ClaimDeliveries(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]*domain.OutboxDelivery, error) {
+	ret := _m.Called(ctx, now, limit, leaseUntil)
+
+	var r0 []*domain.OutboxDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, time.Time) []*domain.OutboxDelivery); ok {
+		r0 = rf(ctx, now, limit, leaseUntil)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.OutboxDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int, time.Time) error); ok {
+		r1 = rf(ctx, now, limit, leaseUntil)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteDeliveries provides a mock function with given fields: ctx, status, before
+func (_m *NotificationOutboxStorage) DeleteDeliveries(ctx context.Context, status string, before time.Time) error {
+	ret := _m.Called(ctx, status, before)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, status, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDeliveries provides a mock function with given fields: ctx, ids
+func (_m *NotificationOutboxStorage) GetDeliveries(ctx context.Context, ids []string) ([]*domain.OutboxDelivery, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []*domain.OutboxDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*domain.OutboxDelivery); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.OutboxDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchDeliveries provides a mock function with given fields: ctx, rq
+func (_m *NotificationOutboxStorage) SearchDeliveries(ctx context.Context, rq *domain.SearchOutboxRequest) (*domain.SearchOutboxResponse, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 *domain.SearchOutboxResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.SearchOutboxRequest) *domain.SearchOutboxResponse); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SearchOutboxResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.SearchOutboxRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateDelivery provides a mock function with given fields: ctx, delivery
+func (_m *NotificationOutboxStorage) UpdateDelivery(ctx context.Context, delivery *domain.OutboxDelivery) error {
+	ret := _m.Called(ctx, delivery)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.OutboxDelivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewNotificationOutboxStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewNotificationOutboxStorage creates a new instance of NotificationOutboxStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewNotificationOutboxStorage(t mockConstructorTestingTNewNotificationOutboxStorage) *NotificationOutboxStorage {
+	mock := &NotificationOutboxStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,96 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NotificationRenderer is an autogenerated mock type for the NotificationRenderer type
+type NotificationRenderer struct {
+	mock.Mock
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *NotificationRenderer) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// Preview provides a mock function with given fields: ctx, rq
+func (_m *NotificationRenderer) Preview(ctx context.Context, rq *domain.TemplatePreviewRequest) (*domain.RenderedMessage, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 *domain.RenderedMessage
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TemplatePreviewRequest) *domain.RenderedMessage); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.RenderedMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.TemplatePreviewRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Render provides a mock function with given fields: ctx, delivery
+func (_m *NotificationRenderer) Render(ctx context.Context, delivery *domain.OutboxDelivery) (*domain.RenderedMessage, error) {
+	ret := _m.Called(ctx, delivery)
+
+	var r0 *domain.RenderedMessage
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.OutboxDelivery) *domain.RenderedMessage); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.RenderedMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.OutboxDelivery) error); ok {
+		r1 = rf(ctx, delivery)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Validate provides a mock function with given fields: ctx, channel, templates
+func (_m *NotificationRenderer) Validate(ctx context.Context, channel string, templates *domain.NotificationTemplates) error {
+	ret := _m.Called(ctx, channel, templates)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.NotificationTemplates) error); ok {
+		r0 = rf(ctx, channel, templates)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewNotificationRenderer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewNotificationRenderer creates a new instance of NotificationRenderer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewNotificationRenderer(t mockConstructorTestingTNewNotificationRenderer) *NotificationRenderer {
+	mock := &NotificationRenderer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
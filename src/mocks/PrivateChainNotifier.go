@@ -0,0 +1,44 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PrivateChainNotifier is an autogenerated mock type for the PrivateChainNotifier type
+type PrivateChainNotifier struct {
+	mock.Mock
+}
+
+// NotifyPrivate provides a mock function with given fields: ctx, userId, chains
+func (_m *PrivateChainNotifier) NotifyPrivate(ctx context.Context, userId string, chains []*domain.ProfitableChain) error {
+	ret := _m.Called(ctx, userId, chains)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*domain.ProfitableChain) error); ok {
+		r0 = rf(ctx, userId, chains)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewPrivateChainNotifier interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewPrivateChainNotifier creates a new instance of PrivateChainNotifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewPrivateChainNotifier(t mockConstructorTestingTNewPrivateChainNotifier) *PrivateChainNotifier {
+	mock := &PrivateChainNotifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,87 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PrivateChainService is an autogenerated mock type for the PrivateChainService type
+type PrivateChainService struct {
+	mock.Mock
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *PrivateChainService) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// Run provides a mock function with given fields: ctx
+func (_m *PrivateChainService) Run(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SearchChains provides a mock function with given fields: ctx, rq
+func (_m *PrivateChainService) SearchChains(ctx context.Context, rq *domain.SearchChainsRequest) ([]*domain.ProfitableChain, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 []*domain.ProfitableChain
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.SearchChainsRequest) []*domain.ProfitableChain); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ProfitableChain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.SearchChainsRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Stop provides a mock function with given fields: ctx
+func (_m *PrivateChainService) Stop(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewPrivateChainService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewPrivateChainService creates a new instance of PrivateChainService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewPrivateChainService(t mockConstructorTestingTNewPrivateChainService) *PrivateChainService {
+	mock := &PrivateChainService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
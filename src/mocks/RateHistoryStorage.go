@@ -0,0 +1,82 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RateHistoryStorage is an autogenerated mock type for the RateHistoryStorage type
+type RateHistoryStorage struct {
+	mock.Mock
+}
+
+// DeleteRatePoints provides a mock function with given fields: ctx, before
+func (_m *RateHistoryStorage) DeleteRatePoints(ctx context.Context, before time.Time) error {
+	ret := _m.Called(ctx, before)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetRatePoints provides a mock function with given fields: ctx, rq
+func (_m *RateHistoryStorage) GetRatePoints(ctx context.Context, rq *domain.GetRatePointsRequest) ([]*domain.RatePoint, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 []*domain.RatePoint
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.GetRatePointsRequest) []*domain.RatePoint); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.RatePoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.GetRatePointsRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveRatePoints provides a mock function with given fields: ctx, points
+func (_m *RateHistoryStorage) SaveRatePoints(ctx context.Context, points []*domain.RatePoint) error {
+	ret := _m.Called(ctx, points)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.RatePoint) error); ok {
+		r0 = rf(ctx, points)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewRateHistoryStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewRateHistoryStorage creates a new instance of RateHistoryStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewRateHistoryStorage(t mockConstructorTestingTNewRateHistoryStorage) *RateHistoryStorage {
+	mock := &RateHistoryStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,115 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReferenceRateProvider is an autogenerated mock type for the ReferenceRateProvider type
+type ReferenceRateProvider struct {
+	mock.Mock
+}
+
+// BaseCurrency provides a mock function with given fields:
+func (_m *ReferenceRateProvider) BaseCurrency() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetRates provides a mock function with given fields: ctx
+func (_m *ReferenceRateProvider) GetRates(ctx context.Context) *domain.ReferenceRates {
+	ret := _m.Called(ctx)
+
+	var r0 *domain.ReferenceRates
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.ReferenceRates); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ReferenceRates)
+		}
+	}
+
+	return r0
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *ReferenceRateProvider) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// Run provides a mock function with given fields: ctx
+func (_m *ReferenceRateProvider) Run(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stop provides a mock function with given fields: ctx
+func (_m *ReferenceRateProvider) Stop(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ToBase provides a mock function with given fields: ctx, asset, amount
+func (_m *ReferenceRateProvider) ToBase(ctx context.Context, asset string, amount float64) (float64, bool) {
+	ret := _m.Called(ctx, asset, amount)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) float64); ok {
+		r0 = rf(ctx, asset, amount)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(context.Context, string, float64) bool); ok {
+		r1 = rf(ctx, asset, amount)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewReferenceRateProvider interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewReferenceRateProvider creates a new instance of ReferenceRateProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewReferenceRateProvider(t mockConstructorTestingTNewReferenceRateProvider) *ReferenceRateProvider {
+	mock := &ReferenceRateProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,52 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReferenceRateSource is an autogenerated mock type for the ReferenceRateSource type
+type ReferenceRateSource struct {
+	mock.Mock
+}
+
+// GetRates provides a mock function with given fields: ctx, base
+func (_m *ReferenceRateSource) GetRates(ctx context.Context, base string) (map[string]float64, error) {
+	ret := _m.Called(ctx, base)
+
+	var r0 map[string]float64
+	if rf, ok := ret.Get(0).(func(context.Context, string) map[string]float64); ok {
+		r0 = rf(ctx, base)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]float64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, base)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewReferenceRateSource interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewReferenceRateSource creates a new instance of ReferenceRateSource. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewReferenceRateSource(t mockConstructorTestingTNewReferenceRateSource) *ReferenceRateSource {
+	mock := &ReferenceRateSource{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
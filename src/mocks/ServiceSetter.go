@@ -0,0 +1,39 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// ServiceSetter is an autogenerated mock type for the ServiceSetter type
+type ServiceSetter struct {
+	mock.Mock
+}
+
+// Set provides a mock function with given fields:
+func (_m *ServiceSetter) Set() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewServiceSetter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewServiceSetter creates a new instance of ServiceSetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewServiceSetter(t mockConstructorTestingTNewServiceSetter) *ServiceSetter {
+	mock := &ServiceSetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,110 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SpreadDetector is an autogenerated mock type for the SpreadDetector type
+type SpreadDetector struct {
+	mock.Mock
+}
+
+// GetSpread provides a mock function with given fields: ctx, spreadId
+func (_m *SpreadDetector) GetSpread(ctx context.Context, spreadId string) (*domain.Spread, error) {
+	ret := _m.Called(ctx, spreadId)
+
+	var r0 *domain.Spread
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Spread); ok {
+		r0 = rf(ctx, spreadId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Spread)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, spreadId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSpreads provides a mock function with given fields: ctx, rq
+func (_m *SpreadDetector) GetSpreads(ctx context.Context, rq *domain.GetSpreadsRequest) (*domain.GetSpreadsResponse, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 *domain.GetSpreadsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.GetSpreadsRequest) *domain.GetSpreadsResponse); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.GetSpreadsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.GetSpreadsRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *SpreadDetector) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// Run provides a mock function with given fields: ctx
+func (_m *SpreadDetector) Run(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stop provides a mock function with given fields: ctx
+func (_m *SpreadDetector) Stop(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewSpreadDetector interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSpreadDetector creates a new instance of SpreadDetector. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSpreadDetector(t mockConstructorTestingTNewSpreadDetector) *SpreadDetector {
+	mock := &SpreadDetector{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
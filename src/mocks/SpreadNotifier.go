@@ -0,0 +1,44 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SpreadNotifier is an autogenerated mock type for the SpreadNotifier type
+type SpreadNotifier struct {
+	mock.Mock
+}
+
+// NotifySpreads provides a mock function with given fields: ctx, spreads
+func (_m *SpreadNotifier) NotifySpreads(ctx context.Context, spreads []*domain.Spread) error {
+	ret := _m.Called(ctx, spreads)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.Spread) error); ok {
+		r0 = rf(ctx, spreads)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewSpreadNotifier interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSpreadNotifier creates a new instance of SpreadNotifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSpreadNotifier(t mockConstructorTestingTNewSpreadNotifier) *SpreadNotifier {
+	mock := &SpreadNotifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
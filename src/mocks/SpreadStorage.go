@@ -0,0 +1,111 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SpreadStorage is an autogenerated mock type for the SpreadStorage type
+type SpreadStorage struct {
+	mock.Mock
+}
+
+// GetSpread provides a mock function with given fields: ctx, spreadId
+func (_m *SpreadStorage) GetSpread(ctx context.Context, spreadId string) (*domain.Spread, error) {
+	ret := _m.Called(ctx, spreadId)
+
+	var r0 *domain.Spread
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Spread); ok {
+		r0 = rf(ctx, spreadId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Spread)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, spreadId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSpreads provides a mock function with given fields: ctx, rq
+func (_m *SpreadStorage) GetSpreads(ctx context.Context, rq *domain.GetSpreadsRequest) (*domain.GetSpreadsResponse, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 *domain.GetSpreadsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.GetSpreadsRequest) *domain.GetSpreadsResponse); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.GetSpreadsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.GetSpreadsRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveSpreads provides a mock function with given fields: ctx, spreads
+func (_m *SpreadStorage) SaveSpreads(ctx context.Context, spreads []*domain.Spread) error {
+	ret := _m.Called(ctx, spreads)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.Spread) error); ok {
+		r0 = rf(ctx, spreads)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SpreadExists provides a mock function with given fields: ctx, spreadId
+func (_m *SpreadStorage) SpreadExists(ctx context.Context, spreadId string) (bool, error) {
+	ret := _m.Called(ctx, spreadId)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, spreadId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, spreadId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewSpreadStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSpreadStorage creates a new instance of SpreadStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSpreadStorage(t mockConstructorTestingTNewSpreadStorage) *SpreadStorage {
+	mock := &SpreadStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -5,10 +5,9 @@ package mocks
 import (
 	context "context"
 
-	mock "github.com/stretchr/testify/mock"
 	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
-
 	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
 )
 
 // SubscriptionService is an autogenerated mock type for the SubscriptionService type
@@ -16,6 +15,29 @@ type SubscriptionService struct {
 	mock.Mock
 }
 
+// Activate provides a mock function with given fields: ctx, subscriptionId
+func (_m *SubscriptionService) Activate(ctx context.Context, subscriptionId string) (*domain.Subscription, error) {
+	ret := _m.Called(ctx, subscriptionId)
+
+	var r0 *domain.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Subscription); ok {
+		r0 = rf(ctx, subscriptionId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, subscriptionId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, subscription
 func (_m *SubscriptionService) Create(ctx context.Context, subscription *domain.Subscription) (*domain.Subscription, error) {
 	ret := _m.Called(ctx, subscription)
@@ -118,6 +140,94 @@ func (_m *SubscriptionService) Notify(ctx context.Context, chains []*domain.Prof
 	return r0
 }
 
+// NotifyPrivate provides a mock function with given fields: ctx, userId, chains
+func (_m *SubscriptionService) NotifyPrivate(ctx context.Context, userId string, chains []*domain.ProfitableChain) error {
+	ret := _m.Called(ctx, userId, chains)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*domain.ProfitableChain) error); ok {
+		r0 = rf(ctx, userId, chains)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NotifySpreads provides a mock function with given fields: ctx, spreads
+func (_m *SubscriptionService) NotifySpreads(ctx context.Context, spreads []*domain.Spread) error {
+	ret := _m.Called(ctx, spreads)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.Spread) error); ok {
+		r0 = rf(ctx, spreads)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Preview provides a mock function with given fields: ctx, rq
+func (_m *SubscriptionService) Preview(ctx context.Context, rq *domain.SubscriptionPreviewRequest) (*domain.SubscriptionPreview, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 *domain.SubscriptionPreview
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.SubscriptionPreviewRequest) *domain.SubscriptionPreview); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SubscriptionPreview)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.SubscriptionPreviewRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PreviewTemplate provides a mock function with given fields: ctx, rq
+func (_m *SubscriptionService) PreviewTemplate(ctx context.Context, rq *domain.TemplatePreviewRequest) (*domain.RenderedMessage, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 *domain.RenderedMessage
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TemplatePreviewRequest) *domain.RenderedMessage); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.RenderedMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.TemplatePreviewRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Run provides a mock function with given fields: ctx
+func (_m *SubscriptionService) Run(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Search provides a mock function with given fields: ctx, rq
 func (_m *SubscriptionService) Search(ctx context.Context, rq *domain.SearchSubscriptionsRequest) ([]*domain.Subscription, error) {
 	ret := _m.Called(ctx, rq)
@@ -141,6 +251,20 @@ func (_m *SubscriptionService) Search(ctx context.Context, rq *domain.SearchSubs
 	return r0, r1
 }
 
+// Stop provides a mock function with given fields: ctx
+func (_m *SubscriptionService) Stop(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, subscription
 func (_m *SubscriptionService) Update(ctx context.Context, subscription *domain.Subscription) (*domain.Subscription, error) {
 	ret := _m.Called(ctx, subscription)
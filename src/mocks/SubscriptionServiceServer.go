@@ -0,0 +1,151 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	pb "github.com/mikhailbolshakov/cryptocare/src/grpc/pb"
+	mock "github.com/stretchr/testify/mock"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// SubscriptionServiceServer is an autogenerated mock type for the SubscriptionServiceServer type
+type SubscriptionServiceServer struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: _a0, _a1
+func (_m *SubscriptionServiceServer) Create(_a0 context.Context, _a1 *pb.CreateSubscriptionRequest) (*pb.Subscription, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *pb.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.CreateSubscriptionRequest) *pb.Subscription); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pb.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *pb.CreateSubscriptionRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: _a0, _a1
+func (_m *SubscriptionServiceServer) Delete(_a0 context.Context, _a1 *pb.SubscriptionIdRequest) (*emptypb.Empty, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *emptypb.Empty
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.SubscriptionIdRequest) *emptypb.Empty); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*emptypb.Empty)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *pb.SubscriptionIdRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: _a0, _a1
+func (_m *SubscriptionServiceServer) Get(_a0 context.Context, _a1 *pb.SubscriptionIdRequest) (*pb.Subscription, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *pb.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.SubscriptionIdRequest) *pb.Subscription); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pb.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *pb.SubscriptionIdRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: _a0, _a1
+func (_m *SubscriptionServiceServer) Search(_a0 context.Context, _a1 *pb.SearchSubscriptionsRequest) (*pb.Subscriptions, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *pb.Subscriptions
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.SearchSubscriptionsRequest) *pb.Subscriptions); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pb.Subscriptions)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *pb.SearchSubscriptionsRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: _a0, _a1
+func (_m *SubscriptionServiceServer) Update(_a0 context.Context, _a1 *pb.UpdateSubscriptionRequest) (*pb.Subscription, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *pb.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.UpdateSubscriptionRequest) *pb.Subscription); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pb.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *pb.UpdateSubscriptionRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mustEmbedUnimplementedSubscriptionServiceServer provides a mock function with given fields:
+func (_m *SubscriptionServiceServer) mustEmbedUnimplementedSubscriptionServiceServer() {
+	_m.Called()
+}
+
+type mockConstructorTestingTNewSubscriptionServiceServer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSubscriptionServiceServer creates a new instance of SubscriptionServiceServer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSubscriptionServiceServer(t mockConstructorTestingTNewSubscriptionServiceServer) *SubscriptionServiceServer {
+	mock := &SubscriptionServiceServer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 
+	telegram "github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -13,6 +14,108 @@ type Telegram struct {
 	mock.Mock
 }
 
+// DeleteWebhook provides a mock function with given fields: ctx, bot
+func (_m *Telegram) DeleteWebhook(ctx context.Context, bot string) error {
+	ret := _m.Called(ctx, bot)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, bot)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EditMessageText provides a mock function with given fields: ctx, bot, chatId, messageId, text
+func (_m *Telegram) EditMessageText(ctx context.Context, bot string, chatId int64, messageId int64, text string) error {
+	ret := _m.Called(ctx, bot, chatId, messageId, text)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64, string) error); ok {
+		r0 = rf(ctx, bot, chatId, messageId, text)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetChatMember provides a mock function with given fields: ctx, bot, chatId, userId
+func (_m *Telegram) GetChatMember(ctx context.Context, bot string, chatId int64, userId int64) (*telegram.ChatMember, error) {
+	ret := _m.Called(ctx, bot, chatId, userId)
+
+	var r0 *telegram.ChatMember
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64) *telegram.ChatMember); ok {
+		r0 = rf(ctx, bot, chatId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*telegram.ChatMember)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int64) error); ok {
+		r1 = rf(ctx, bot, chatId, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMe provides a mock function with given fields: ctx, bot
+func (_m *Telegram) GetMe(ctx context.Context, bot string) (*telegram.User, error) {
+	ret := _m.Called(ctx, bot)
+
+	var r0 *telegram.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) *telegram.User); ok {
+		r0 = rf(ctx, bot)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*telegram.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, bot)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUpdates provides a mock function with given fields: ctx, bot, offset, timeoutSec
+func (_m *Telegram) GetUpdates(ctx context.Context, bot string, offset int64, timeoutSec int) ([]*telegram.Update, error) {
+	ret := _m.Called(ctx, bot, offset, timeoutSec)
+
+	var r0 []*telegram.Update
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) []*telegram.Update); ok {
+		r0 = rf(ctx, bot, offset, timeoutSec)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*telegram.Update)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int) error); ok {
+		r1 = rf(ctx, bot, offset, timeoutSec)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Poll provides a mock function with given fields: ctx, bot, timeoutSec, handler
+func (_m *Telegram) Poll(ctx context.Context, bot string, timeoutSec int, handler telegram.UpdateHandler) {
+	_m.Called(ctx, bot, timeoutSec, handler)
+}
+
 // Send provides a mock function with given fields: ctx, bot, text, channel
 func (_m *Telegram) Send(ctx context.Context, bot string, text string, channel int) error {
 	ret := _m.Called(ctx, bot, text, channel)
@@ -27,6 +130,48 @@ func (_m *Telegram) Send(ctx context.Context, bot string, text string, channel i
 	return r0
 }
 
+// SendMessage provides a mock function with given fields: ctx, bot, text, chatId
+func (_m *Telegram) SendMessage(ctx context.Context, bot string, text string, chatId int64) (*telegram.Message, error) {
+	ret := _m.Called(ctx, bot, text, chatId)
+
+	var r0 *telegram.Message
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64) *telegram.Message); ok {
+		r0 = rf(ctx, bot, text, chatId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*telegram.Message)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64) error); ok {
+		r1 = rf(ctx, bot, text, chatId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetRateLimit provides a mock function with given fields: bot, rateLimit
+func (_m *Telegram) SetRateLimit(bot string, rateLimit *telegram.RateLimit) {
+	_m.Called(bot, rateLimit)
+}
+
+// SetWebhook provides a mock function with given fields: ctx, bot, url, secret
+func (_m *Telegram) SetWebhook(ctx context.Context, bot string, url string, secret string) error {
+	ret := _m.Called(ctx, bot, url, secret)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, bot, url, secret)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewTelegram interface {
 	mock.TestingT
 	Cleanup(func())
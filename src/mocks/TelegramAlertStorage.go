@@ -0,0 +1,105 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TelegramAlertStorage is an autogenerated mock type for the TelegramAlertStorage type
+type TelegramAlertStorage struct {
+	mock.Mock
+}
+
+// DeleteAlerts provides a mock function with given fields: ctx, updatedBefore
+func (_m *TelegramAlertStorage) DeleteAlerts(ctx context.Context, updatedBefore time.Time) error {
+	ret := _m.Called(ctx, updatedBefore)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, updatedBefore)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetActiveAlerts provides a mock function with given fields: ctx
+func (_m *TelegramAlertStorage) GetActiveAlerts(ctx context.Context) ([]*domain.TelegramAlert, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*domain.TelegramAlert
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.TelegramAlert); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.TelegramAlert)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAlertsByChains provides a mock function with given fields: ctx, chainIds
+func (_m *TelegramAlertStorage) GetAlertsByChains(ctx context.Context, chainIds []string) ([]*domain.TelegramAlert, error) {
+	ret := _m.Called(ctx, chainIds)
+
+	var r0 []*domain.TelegramAlert
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*domain.TelegramAlert); ok {
+		r0 = rf(ctx, chainIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.TelegramAlert)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, chainIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveAlert provides a mock function with given fields: ctx, alert
+func (_m *TelegramAlertStorage) SaveAlert(ctx context.Context, alert *domain.TelegramAlert) error {
+	ret := _m.Called(ctx, alert)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TelegramAlert) error); ok {
+		r0 = rf(ctx, alert)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewTelegramAlertStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTelegramAlertStorage creates a new instance of TelegramAlertStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTelegramAlertStorage(t mockConstructorTestingTNewTelegramAlertStorage) *TelegramAlertStorage {
+	mock := &TelegramAlertStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
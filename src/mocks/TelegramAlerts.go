@@ -0,0 +1,92 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TelegramAlerts is an autogenerated mock type for the TelegramAlerts type
+type TelegramAlerts struct {
+	mock.Mock
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *TelegramAlerts) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// NotifyUpdated provides a mock function with given fields: ctx, chains
+func (_m *TelegramAlerts) NotifyUpdated(ctx context.Context, chains []*domain.ProfitableChain) error {
+	ret := _m.Called(ctx, chains)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.ProfitableChain) error); ok {
+		r0 = rf(ctx, chains)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Run provides a mock function with given fields: ctx
+func (_m *TelegramAlerts) Run(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Send provides a mock function with given fields: ctx, delivery, text
+func (_m *TelegramAlerts) Send(ctx context.Context, delivery *domain.OutboxDelivery, text string) error {
+	ret := _m.Called(ctx, delivery, text)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.OutboxDelivery, string) error); ok {
+		r0 = rf(ctx, delivery, text)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stop provides a mock function with given fields: ctx
+func (_m *TelegramAlerts) Stop(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewTelegramAlerts interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTelegramAlerts creates a new instance of TelegramAlerts. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTelegramAlerts(t mockConstructorTestingTNewTelegramAlerts) *TelegramAlerts {
+	mock := &TelegramAlerts{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,153 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	telegram "github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TelegramBot is an autogenerated mock type for the TelegramBot type
+type TelegramBot struct {
+	mock.Mock
+}
+
+// GetLink provides a mock function with given fields: ctx, userId
+func (_m *TelegramBot) GetLink(ctx context.Context, userId string) (*domain.TelegramLink, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 *domain.TelegramLink
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TelegramLink); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramLink)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HandleUpdate provides a mock function with given fields: ctx, update
+func (_m *TelegramBot) HandleUpdate(ctx context.Context, update *telegram.Update) error {
+	ret := _m.Called(ctx, update)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *telegram.Update) error); ok {
+		r0 = rf(ctx, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// HandleWebhook provides a mock function with given fields: ctx, secret, update
+func (_m *TelegramBot) HandleWebhook(ctx context.Context, secret string, update *telegram.Update) error {
+	ret := _m.Called(ctx, secret, update)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *telegram.Update) error); ok {
+		r0 = rf(ctx, secret, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *TelegramBot) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// LinkCode provides a mock function with given fields: ctx, userId
+func (_m *TelegramBot) LinkCode(ctx context.Context, userId string) (*domain.TelegramLinkCode, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 *domain.TelegramLinkCode
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TelegramLinkCode); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramLinkCode)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Run provides a mock function with given fields: ctx
+func (_m *TelegramBot) Run(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stop provides a mock function with given fields: ctx
+func (_m *TelegramBot) Stop(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Unlink provides a mock function with given fields: ctx, userId
+func (_m *TelegramBot) Unlink(ctx context.Context, userId string) error {
+	ret := _m.Called(ctx, userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewTelegramBot interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTelegramBot creates a new instance of TelegramBot. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTelegramBot(t mockConstructorTestingTNewTelegramBot) *TelegramBot {
+	mock := &TelegramBot{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
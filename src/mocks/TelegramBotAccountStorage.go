@@ -0,0 +1,104 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TelegramBotAccountStorage is an autogenerated mock type for the TelegramBotAccountStorage type
+type TelegramBotAccountStorage struct {
+	mock.Mock
+}
+
+// DeleteBot provides a mock function with given fields: ctx, botId
+func (_m *TelegramBotAccountStorage) DeleteBot(ctx context.Context, botId string) error {
+	ret := _m.Called(ctx, botId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, botId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetBot provides a mock function with given fields: ctx, botId
+func (_m *TelegramBotAccountStorage) GetBot(ctx context.Context, botId string) (*domain.TelegramBotAccount, error) {
+	ret := _m.Called(ctx, botId)
+
+	var r0 *domain.TelegramBotAccount
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TelegramBotAccount); ok {
+		r0 = rf(ctx, botId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramBotAccount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, botId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBots provides a mock function with given fields: ctx
+func (_m *TelegramBotAccountStorage) GetBots(ctx context.Context) ([]*domain.TelegramBotAccount, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*domain.TelegramBotAccount
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.TelegramBotAccount); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.TelegramBotAccount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveBot provides a mock function with given fields: ctx, bot
+func (_m *TelegramBotAccountStorage) SaveBot(ctx context.Context, bot *domain.TelegramBotAccount) error {
+	ret := _m.Called(ctx, bot)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TelegramBotAccount) error); ok {
+		r0 = rf(ctx, bot)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewTelegramBotAccountStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTelegramBotAccountStorage creates a new instance of TelegramBotAccountStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTelegramBotAccountStorage(t mockConstructorTestingTNewTelegramBotAccountStorage) *TelegramBotAccountStorage {
+	mock := &TelegramBotAccountStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,198 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TelegramBotRegistry is an autogenerated mock type for the TelegramBotRegistry type
+type TelegramBotRegistry struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, rq
+func (_m *TelegramBotRegistry) Create(ctx context.Context, rq *domain.TelegramBotAccountRequest) (*domain.TelegramBotAccount, error) {
+	ret := _m.Called(ctx, rq)
+
+	var r0 *domain.TelegramBotAccount
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TelegramBotAccountRequest) *domain.TelegramBotAccount); ok {
+		r0 = rf(ctx, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramBotAccount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.TelegramBotAccountRequest) error); ok {
+		r1 = rf(ctx, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, botId
+func (_m *TelegramBotRegistry) Delete(ctx context.Context, botId string) error {
+	ret := _m.Called(ctx, botId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, botId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Do provides a mock function with given fields: ctx, userId, botId, send
+func (_m *TelegramBotRegistry) Do(ctx context.Context, userId string, botId string, send func(string) error) (string, error) {
+	ret := _m.Called(ctx, userId, botId, send)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, func(string) error) string); ok {
+		r0 = rf(ctx, userId, botId, send)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, func(string) error) error); ok {
+		r1 = rf(ctx, userId, botId, send)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: ctx, botId
+func (_m *TelegramBotRegistry) Get(ctx context.Context, botId string) (*domain.TelegramBotAccount, error) {
+	ret := _m.Called(ctx, botId)
+
+	var r0 *domain.TelegramBotAccount
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TelegramBotAccount); ok {
+		r0 = rf(ctx, botId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramBotAccount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, botId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: ctx
+func (_m *TelegramBotRegistry) GetAll(ctx context.Context) ([]*domain.TelegramBotAccount, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*domain.TelegramBotAccount
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.TelegramBotAccount); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.TelegramBotAccount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *TelegramBotRegistry) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// Token provides a mock function with given fields: ctx, botId
+func (_m *TelegramBotRegistry) Token(ctx context.Context, botId string) (string, error) {
+	ret := _m.Called(ctx, botId)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, botId)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, botId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, botId, rq
+func (_m *TelegramBotRegistry) Update(ctx context.Context, botId string, rq *domain.TelegramBotAccountRequest) (*domain.TelegramBotAccount, error) {
+	ret := _m.Called(ctx, botId, rq)
+
+	var r0 *domain.TelegramBotAccount
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.TelegramBotAccountRequest) *domain.TelegramBotAccount); ok {
+		r0 = rf(ctx, botId, rq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramBotAccount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *domain.TelegramBotAccountRequest) error); ok {
+		r1 = rf(ctx, botId, rq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Validate provides a mock function with given fields: ctx, userId, botId
+func (_m *TelegramBotRegistry) Validate(ctx context.Context, userId string, botId string) error {
+	ret := _m.Called(ctx, userId, botId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userId, botId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewTelegramBotRegistry interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTelegramBotRegistry creates a new instance of TelegramBotRegistry. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTelegramBotRegistry(t mockConstructorTestingTNewTelegramBotRegistry) *TelegramBotRegistry {
+	mock := &TelegramBotRegistry{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,113 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TelegramChannelStorage is an autogenerated mock type for the TelegramChannelStorage type
+type TelegramChannelStorage struct {
+	mock.Mock
+}
+
+// GetChannelVerification provides a mock function with given fields: ctx, userId, chatId
+func (_m *TelegramChannelStorage) GetChannelVerification(ctx context.Context, userId string, chatId int64) (*domain.TelegramChannelVerification, error) {
+	ret := _m.Called(ctx, userId, chatId)
+
+	var r0 *domain.TelegramChannelVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) *domain.TelegramChannelVerification); ok {
+		r0 = rf(ctx, userId, chatId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramChannelVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, userId, chatId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChannelVerificationByCode provides a mock function with given fields: ctx, chatId, code
+func (_m *TelegramChannelStorage) GetChannelVerificationByCode(ctx context.Context, chatId int64, code string) (*domain.TelegramChannelVerification, error) {
+	ret := _m.Called(ctx, chatId, code)
+
+	var r0 *domain.TelegramChannelVerification
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *domain.TelegramChannelVerification); ok {
+		r0 = rf(ctx, chatId, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramChannelVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, chatId, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChannelVerifications provides a mock function with given fields: ctx, userId
+func (_m *TelegramChannelStorage) GetChannelVerifications(ctx context.Context, userId string) ([]*domain.TelegramChannelVerification, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 []*domain.TelegramChannelVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.TelegramChannelVerification); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.TelegramChannelVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveChannelVerification provides a mock function with given fields: ctx, v
+func (_m *TelegramChannelStorage) SaveChannelVerification(ctx context.Context, v *domain.TelegramChannelVerification) error {
+	ret := _m.Called(ctx, v)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TelegramChannelVerification) error); ok {
+		r0 = rf(ctx, v)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewTelegramChannelStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTelegramChannelStorage creates a new instance of TelegramChannelStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTelegramChannelStorage(t mockConstructorTestingTNewTelegramChannelStorage) *TelegramChannelStorage {
+	mock := &TelegramChannelStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,148 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	telegram "github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
+	service "github.com/mikhailbolshakov/cryptocare/src/service"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TelegramChannelVerifier is an autogenerated mock type for the TelegramChannelVerifier type
+type TelegramChannelVerifier struct {
+	mock.Mock
+}
+
+// Confirm provides a mock function with given fields: ctx, userId, chatId
+func (_m *TelegramChannelVerifier) Confirm(ctx context.Context, userId string, chatId int64) (*domain.TelegramChannelVerification, error) {
+	ret := _m.Called(ctx, userId, chatId)
+
+	var r0 *domain.TelegramChannelVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) *domain.TelegramChannelVerification); ok {
+		r0 = rf(ctx, userId, chatId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramChannelVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, userId, chatId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVerifications provides a mock function with given fields: ctx, userId
+func (_m *TelegramChannelVerifier) GetVerifications(ctx context.Context, userId string) ([]*domain.TelegramChannelVerification, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 []*domain.TelegramChannelVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.TelegramChannelVerification); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.TelegramChannelVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HandleMessage provides a mock function with given fields: ctx, msg
+func (_m *TelegramChannelVerifier) HandleMessage(ctx context.Context, msg *telegram.Message) (bool, error) {
+	ret := _m.Called(ctx, msg)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *telegram.Message) bool); ok {
+		r0 = rf(ctx, msg)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *telegram.Message) error); ok {
+		r1 = rf(ctx, msg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Init provides a mock function with given fields: cfg
+func (_m *TelegramChannelVerifier) Init(cfg *service.Config) {
+	_m.Called(cfg)
+}
+
+// IsVerified provides a mock function with given fields: ctx, userId, chatId
+func (_m *TelegramChannelVerifier) IsVerified(ctx context.Context, userId string, chatId int64) (bool, error) {
+	ret := _m.Called(ctx, userId, chatId)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) bool); ok {
+		r0 = rf(ctx, userId, chatId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, userId, chatId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Request provides a mock function with given fields: ctx, userId, chatId
+func (_m *TelegramChannelVerifier) Request(ctx context.Context, userId string, chatId int64) (*domain.TelegramChannelVerification, error) {
+	ret := _m.Called(ctx, userId, chatId)
+
+	var r0 *domain.TelegramChannelVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) *domain.TelegramChannelVerification); ok {
+		r0 = rf(ctx, userId, chatId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramChannelVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, userId, chatId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewTelegramChannelVerifier interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTelegramChannelVerifier creates a new instance of TelegramChannelVerifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTelegramChannelVerifier(t mockConstructorTestingTNewTelegramChannelVerifier) *TelegramChannelVerifier {
+	mock := &TelegramChannelVerifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
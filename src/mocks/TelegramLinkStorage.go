@@ -0,0 +1,141 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TelegramLinkStorage is an autogenerated mock type for the TelegramLinkStorage type
+type TelegramLinkStorage struct {
+	mock.Mock
+}
+
+// DeleteLink provides a mock function with given fields: ctx, userId
+func (_m *TelegramLinkStorage) DeleteLink(ctx context.Context, userId string) error {
+	ret := _m.Called(ctx, userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetLinkByTelegramUser provides a mock function with given fields: ctx, telegramUserId
+func (_m *TelegramLinkStorage) GetLinkByTelegramUser(ctx context.Context, telegramUserId int64) (*domain.TelegramLink, error) {
+	ret := _m.Called(ctx, telegramUserId)
+
+	var r0 *domain.TelegramLink
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *domain.TelegramLink); ok {
+		r0 = rf(ctx, telegramUserId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramLink)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, telegramUserId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLinkByUser provides a mock function with given fields: ctx, userId
+func (_m *TelegramLinkStorage) GetLinkByUser(ctx context.Context, userId string) (*domain.TelegramLink, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 *domain.TelegramLink
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TelegramLink); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramLink)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveLink provides a mock function with given fields: ctx, link
+func (_m *TelegramLinkStorage) SaveLink(ctx context.Context, link *domain.TelegramLink) error {
+	ret := _m.Called(ctx, link)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TelegramLink) error); ok {
+		r0 = rf(ctx, link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveLinkCode provides a mock function with given fields: ctx, code
+func (_m *TelegramLinkStorage) SaveLinkCode(ctx context.Context, code *domain.TelegramLinkCode) error {
+	ret := _m.Called(ctx, code)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TelegramLinkCode) error); ok {
+		r0 = rf(ctx, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TakeLinkCode provides a mock function with given fields: ctx, code
+func (_m *TelegramLinkStorage) TakeLinkCode(ctx context.Context, code string) (*domain.TelegramLinkCode, error) {
+	ret := _m.Called(ctx, code)
+
+	var r0 *domain.TelegramLinkCode
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TelegramLinkCode); ok {
+		r0 = rf(ctx, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TelegramLinkCode)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewTelegramLinkStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTelegramLinkStorage creates a new instance of TelegramLinkStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTelegramLinkStorage(t mockConstructorTestingTNewTelegramLinkStorage) *TelegramLinkStorage {
+	mock := &TelegramLinkStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
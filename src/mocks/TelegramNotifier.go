@@ -6,7 +6,6 @@ import (
 	context "context"
 
 	mock "github.com/stretchr/testify/mock"
-	domain "github.com/mikhailbolshakov/cryptocare/src/domain"
 )
 
 // TelegramNotifier is an autogenerated mock type for the TelegramNotifier type
@@ -14,13 +13,13 @@ type TelegramNotifier struct {
 	mock.Mock
 }
 
-// Init provides a mock function with given fields: ctx
-func (_m *TelegramNotifier) Init(ctx context.Context) error {
-	ret := _m.Called(ctx)
+// EditMessage provides a mock function with given fields: ctx, bot, channel, messageId, text
+func (_m *TelegramNotifier) EditMessage(ctx context.Context, bot string, channel int, messageId int64, text string) error {
+	ret := _m.Called(ctx, bot, channel, messageId, text)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int64, string) error); ok {
+		r0 = rf(ctx, bot, channel, messageId, text)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -28,13 +27,13 @@ func (_m *TelegramNotifier) Init(ctx context.Context) error {
 	return r0
 }
 
-// Notify provides a mock function with given fields: ctx, bot, channels, chains
-func (_m *TelegramNotifier) Notify(ctx context.Context, bot string, channels []int, chains []*domain.ProfitableChain) error {
-	ret := _m.Called(ctx, bot, channels, chains)
+// Send provides a mock function with given fields: ctx, bot, channel, text
+func (_m *TelegramNotifier) Send(ctx context.Context, bot string, channel int, text string) error {
+	ret := _m.Called(ctx, bot, channel, text)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, []int, []*domain.ProfitableChain) error); ok {
-		r0 = rf(ctx, bot, channels, chains)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, string) error); ok {
+		r0 = rf(ctx, bot, channel, text)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -42,6 +41,27 @@ func (_m *TelegramNotifier) Notify(ctx context.Context, bot string, channels []i
 	return r0
 }
 
+// SendMessage provides a mock function with given fields: ctx, bot, channel, text
+func (_m *TelegramNotifier) SendMessage(ctx context.Context, bot string, channel int, text string) (int64, error) {
+	ret := _m.Called(ctx, bot, channel, text)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, string) int64); ok {
+		r0 = rf(ctx, bot, channel, text)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, string) error); ok {
+		r1 = rf(ctx, bot, channel, text)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewTelegramNotifier interface {
 	mock.TestingT
 	Cleanup(func())
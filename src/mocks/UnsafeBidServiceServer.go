@@ -0,0 +1,30 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// UnsafeBidServiceServer is an autogenerated mock type for the UnsafeBidServiceServer type
+type UnsafeBidServiceServer struct {
+	mock.Mock
+}
+
+// mustEmbedUnimplementedBidServiceServer provides a mock function with given fields:
+func (_m *UnsafeBidServiceServer) mustEmbedUnimplementedBidServiceServer() {
+	_m.Called()
+}
+
+type mockConstructorTestingTNewUnsafeBidServiceServer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewUnsafeBidServiceServer creates a new instance of UnsafeBidServiceServer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewUnsafeBidServiceServer(t mockConstructorTestingTNewUnsafeBidServiceServer) *UnsafeBidServiceServer {
+	mock := &UnsafeBidServiceServer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
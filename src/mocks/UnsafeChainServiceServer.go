@@ -0,0 +1,30 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// UnsafeChainServiceServer is an autogenerated mock type for the UnsafeChainServiceServer type
+type UnsafeChainServiceServer struct {
+	mock.Mock
+}
+
+// mustEmbedUnimplementedChainServiceServer provides a mock function with given fields:
+func (_m *UnsafeChainServiceServer) mustEmbedUnimplementedChainServiceServer() {
+	_m.Called()
+}
+
+type mockConstructorTestingTNewUnsafeChainServiceServer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewUnsafeChainServiceServer creates a new instance of UnsafeChainServiceServer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewUnsafeChainServiceServer(t mockConstructorTestingTNewUnsafeChainServiceServer) *UnsafeChainServiceServer {
+	mock := &UnsafeChainServiceServer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
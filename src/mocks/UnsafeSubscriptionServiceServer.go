@@ -0,0 +1,30 @@
+// Code generated by mockery 2.14.0. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// UnsafeSubscriptionServiceServer is an autogenerated mock type for the UnsafeSubscriptionServiceServer type
+type UnsafeSubscriptionServiceServer struct {
+	mock.Mock
+}
+
+// mustEmbedUnimplementedSubscriptionServiceServer provides a mock function with given fields:
+func (_m *UnsafeSubscriptionServiceServer) mustEmbedUnimplementedSubscriptionServiceServer() {
+	_m.Called()
+}
+
+type mockConstructorTestingTNewUnsafeSubscriptionServiceServer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewUnsafeSubscriptionServiceServer creates a new instance of UnsafeSubscriptionServiceServer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewUnsafeSubscriptionServiceServer(t mockConstructorTestingTNewUnsafeSubscriptionServiceServer) *UnsafeSubscriptionServiceServer {
+	mock := &UnsafeSubscriptionServiceServer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
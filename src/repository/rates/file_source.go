@@ -0,0 +1,39 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"os"
+)
+
+// fileSourceImpl reads rates from a json file, the file is re-read on each request, so it can be updated in place
+type fileSourceImpl struct {
+	path string
+}
+
+func NewFileSource(path string) domain.ReferenceRateSource {
+	return &fileSourceImpl{
+		path: path,
+	}
+}
+
+func (s *fileSourceImpl) l() log.CLogger {
+	return service.L().Cmp("rates-file-source")
+}
+
+func (s *fileSourceImpl) GetRates(ctx context.Context, base string) (map[string]float64, error) {
+	s.l().C(ctx).Mth("get-rates").F(log.FF{"path": s.path}).Trc()
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, errors.ErrReferenceRatesSourceGet(err, ctx)
+	}
+	rs := &ratesResponse{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return nil, errors.ErrReferenceRatesSourceGet(err, ctx)
+	}
+	return rs.toRates(ctx, base)
+}
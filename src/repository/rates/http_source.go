@@ -0,0 +1,66 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	httpSourceTimeout = time.Second * 30
+)
+
+// httpSourceImpl requests rates from http endpoint
+// base currency is passed as "base" query param
+type httpSourceImpl struct {
+	url    string
+	client *http.Client
+}
+
+func NewHttpSource(url string) domain.ReferenceRateSource {
+	return &httpSourceImpl{
+		url:    url,
+		client: &http.Client{Timeout: httpSourceTimeout},
+	}
+}
+
+func (s *httpSourceImpl) l() log.CLogger {
+	return service.L().Cmp("rates-http-source")
+}
+
+func (s *httpSourceImpl) GetRates(ctx context.Context, base string) (map[string]float64, error) {
+	s.l().C(ctx).Mth("get-rates").F(log.FF{"url": s.url}).Trc()
+
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return nil, errors.ErrReferenceRatesSourceGet(err, ctx)
+	}
+	q := u.Query()
+	q.Set("base", base)
+	u.RawQuery = q.Encode()
+
+	rq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.ErrReferenceRatesSourceGet(err, ctx)
+	}
+	rs, err := s.client.Do(rq)
+	if err != nil {
+		return nil, errors.ErrReferenceRatesSourceGet(err, ctx)
+	}
+	defer func() { _ = rs.Body.Close() }()
+
+	if rs.StatusCode >= 300 {
+		return nil, errors.ErrReferenceRatesSourceStatus(ctx, rs.StatusCode)
+	}
+	body := &ratesResponse{}
+	if err := json.NewDecoder(rs.Body).Decode(body); err != nil {
+		return nil, errors.ErrReferenceRatesSourceGet(err, ctx)
+	}
+	return body.toRates(ctx, base)
+}
@@ -0,0 +1,56 @@
+package rates
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"strings"
+)
+
+const (
+	SourceTypeFile = "file" // SourceTypeFile rates are read from a local json file
+	SourceTypeHttp = "http" // SourceTypeHttp rates are requested from http endpoint
+
+	// DefaultFilePath rates file of the file source if the path isn't configured
+	DefaultFilePath = "/tmp/cryptocare/reference-rates.json"
+)
+
+// ratesResponse is a format of rates provided by sources
+// rates are units of the asset per one unit of the base currency
+type ratesResponse struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// NewSource creates a reference rate source by config
+// if reference rates aren't configured, rates are read from the default file
+func NewSource(ctx context.Context, cfg *service.ReferenceRates) (domain.ReferenceRateSource, error) {
+	if cfg == nil {
+		cfg = &service.ReferenceRates{}
+	}
+	switch cfg.Source {
+	case "", SourceTypeFile:
+		path := cfg.Path
+		if path == "" {
+			path = DefaultFilePath
+		}
+		return NewFileSource(path), nil
+	case SourceTypeHttp:
+		return NewHttpSource(cfg.Url), nil
+	default:
+		return nil, errors.ErrReferenceRatesSourceTypeInvalid(ctx, cfg.Source)
+	}
+}
+
+// toRates checks base currency of the response and normalizes asset codes
+func (r *ratesResponse) toRates(ctx context.Context, base string) (map[string]float64, error) {
+	if r.Base != "" && !strings.EqualFold(r.Base, base) {
+		return nil, errors.ErrReferenceRatesBaseMismatch(ctx, r.Base, base)
+	}
+	res := make(map[string]float64, len(r.Rates))
+	for asset, rate := range r.Rates {
+		res[strings.ToUpper(strings.TrimSpace(asset))] = rate
+	}
+	return res, nil
+}
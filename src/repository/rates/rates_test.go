@@ -0,0 +1,73 @@
+package rates
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type ratesTestSuite struct {
+	kitTestSuite.Suite
+}
+
+func (s *ratesTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestRatesSuite(t *testing.T) {
+	suite.Run(t, new(ratesTestSuite))
+}
+
+func (s *ratesTestSuite) Test_FileSource() {
+	path := filepath.Join(s.T().TempDir(), "rates.json")
+	s.NoError(os.WriteFile(path, []byte(`{"base":"USD","rates":{"rub":80,"EUR":0.8}}`), 0644))
+
+	rates, err := NewFileSource(path).GetRates(s.Ctx, "USD")
+	s.NoError(err)
+	s.Equal(map[string]float64{"RUB": 80, "EUR": 0.8}, rates)
+
+	_, err = NewFileSource(path).GetRates(s.Ctx, "EUR")
+	s.AssertAppErr(err, errors.ErrCodeReferenceRatesBaseMismatch)
+
+	_, err = NewFileSource(filepath.Join(s.T().TempDir(), "none.json")).GetRates(s.Ctx, "USD")
+	s.AssertAppErr(err, errors.ErrCodeReferenceRatesSourceGet)
+}
+
+func (s *ratesTestSuite) Test_HttpSource() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("base") != "USD" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"base":"USD","rates":{"RUB":80}}`))
+	}))
+	defer srv.Close()
+
+	rates, err := NewHttpSource(srv.URL+"/latest").GetRates(s.Ctx, "USD")
+	s.NoError(err)
+	s.Equal(map[string]float64{"RUB": 80}, rates)
+
+	_, err = NewHttpSource(srv.URL+"/latest").GetRates(s.Ctx, "EUR")
+	s.AssertAppErr(err, errors.ErrCodeReferenceRatesSourceStatus)
+}
+
+func (s *ratesTestSuite) Test_NewSource() {
+	src, err := NewSource(s.Ctx, &service.ReferenceRates{Source: SourceTypeHttp, Url: "http://localhost"})
+	s.NoError(err)
+	s.IsType(&httpSourceImpl{}, src)
+	src, err = NewSource(s.Ctx, &service.ReferenceRates{Path: "/tmp/rates.json"})
+	s.NoError(err)
+	s.IsType(&fileSourceImpl{}, src)
+	// not configured, the default file is taken
+	src, err = NewSource(s.Ctx, nil)
+	s.NoError(err)
+	s.Equal(DefaultFilePath, src.(*fileSourceImpl).path)
+	_, err = NewSource(s.Ctx, &service.ReferenceRates{Source: "ftp"})
+	s.AssertAppErr(err, errors.ErrCodeReferenceRatesSourceTypeInvalid)
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/auth"
 	kitService "github.com/mikhailbolshakov/cryptocare/src/kit/service"
 	kitAero "github.com/mikhailbolshakov/cryptocare/src/kit/storages/aerospike"
@@ -14,63 +15,191 @@ type Adapter interface {
 	kitService.StorageAdapter
 	domain.BidStorage
 	domain.ChainStorage
+	domain.ChainArchiveStorage
 	domain.UserStorage
 	domain.SubscriptionStorage
+	domain.RateHistoryStorage
+	domain.SpreadStorage
+	domain.NotificationOutboxStorage
+	domain.DeliveryPolicyStorage
+	domain.TelegramLinkStorage
+	domain.TelegramChannelStorage
+	domain.TelegramAlertStorage
+	domain.TelegramBotAccountStorage
+	domain.EmailVerificationStorage
 	auth.SessionStorage
 }
 
+const (
+	StorageTypeAero   = "aero"   // StorageTypeAero aerospike storage (default)
+	StorageTypeMemory = "memory" // StorageTypeMemory in-memory storage, data isn't persisted between restarts
+	StorageTypePg     = "pg"     // StorageTypePg postgres storage
+	StorageTypeFile   = "file"   // StorageTypeFile file storage
+)
+
 type adapterImpl struct {
-	*bidStorageImpl
-	*chainStorageImpl
-	*userStorageImpl
-	*sessionStorageImpl
-	*subscriptionStorageImpl
+	domain.BidStorage
+	domain.ChainStorage
+	domain.ChainArchiveStorage
+	domain.SubscriptionStorage
+	domain.RateHistoryStorage
+	domain.SpreadStorage
+	domain.NotificationOutboxStorage
+	domain.DeliveryPolicyStorage
+	domain.TelegramLinkStorage
+	domain.TelegramChannelStorage
+	domain.TelegramAlertStorage
+	domain.TelegramBotAccountStorage
+	domain.EmailVerificationStorage
+	domain.UserStorage
+	auth.SessionStorage
 	aero kitAero.Aerospike
 	pg   *pg.Storage
 }
 
 func NewAdapter() Adapter {
-	a := &adapterImpl{
-		aero: kitAero.New(),
-	}
+	a := &adapterImpl{}
 	return a
 }
 
+// requiredBackends checks which of aerospike and postgres are used by the configured storages
+func requiredBackends(config *service.Config) (needAero, needPg bool) {
+	st := config.Storages
+	needAero = st.Bids != StorageTypeMemory || st.Chains != StorageTypeMemory || st.Spreads != StorageTypeMemory ||
+		(st.Subscriptions != StorageTypeMemory && st.Subscriptions != StorageTypePg) || st.Users != StorageTypeMemory
+	needPg = st.Subscriptions == StorageTypePg || st.RateHistory != StorageTypeMemory || st.Outbox != StorageTypeMemory ||
+		st.DeliveryPolicies != StorageTypeMemory || st.TelegramLinks != StorageTypeMemory || st.TelegramChannels != StorageTypeMemory ||
+		st.TelegramAlerts != StorageTypeMemory || st.TelegramBots != StorageTypeMemory || st.EmailVerifications != StorageTypeMemory ||
+		st.Users != StorageTypeMemory || archiveStorage(config) == StorageTypePg
+	return needAero, needPg
+}
+
+// archiveStorage returns archive storage type, the archive is kept in memory if it isn't configured
+func archiveStorage(config *service.Config) string {
+	if config.Retention == nil || config.Retention.Archive == nil {
+		return StorageTypeMemory
+	}
+	if config.Retention.Archive.Storage == "" {
+		return StorageTypePg
+	}
+	return config.Retention.Archive.Storage
+}
+
 func (c *adapterImpl) Init(ctx context.Context, cfg interface{}) error {
 	config := cfg.(*service.Config)
+	needAero, needPg := requiredBackends(config)
 
 	// init postgres
-	var err error
-	c.pg, err = pg.Open(config.Storages.Pg.Master, service.LF())
-	if err != nil {
-		return err
-	}
-
-	// applying migrations
-	if config.Storages.Pg.MigPath != "" {
-		db, _ := c.pg.Instance.DB()
-		m := pg.NewMigration(db, config.Storages.Pg.MigPath, service.LF())
-		if err := m.Up(); err != nil {
+	if needPg {
+		var err error
+		c.pg, err = pg.Open(config.Storages.Pg.Master, service.LF())
+		if err != nil {
 			return err
 		}
+
+		// applying migrations
+		if config.Storages.Pg.MigPath != "" {
+			db, _ := c.pg.Instance.DB()
+			m := pg.NewMigration(db, config.Storages.Pg.MigPath, service.LF())
+			if err := m.Up(); err != nil {
+				return err
+			}
+		}
 	}
 
 	// init aero
-	err = c.aero.Open(ctx, config.Storages.Aero, service.LF())
-	if err != nil {
-		return err
+	if needAero {
+		c.aero = kitAero.New()
+		if err := c.aero.Open(ctx, config.Storages.Aero, service.LF()); err != nil {
+			return err
+		}
 	}
 
 	// init storages
-	c.bidStorageImpl = newBidStorage(c.aero, config.Storages.Aero)
-	c.chainStorageImpl = newChainStorage(c.aero, config.Storages.Aero)
-	c.userStorageImpl = newUserStorage(c.pg, c.aero, config.Storages.Aero)
-	c.subscriptionStorageImpl = newSubscriptionStorage(c.aero, config.Storages.Aero)
-	err = c.userStorageImpl.init(ctx)
-	if err != nil {
-		return err
-	}
-	c.sessionStorageImpl = newSessionStorage(c.pg, c.aero, config.Storages.Aero)
+	if config.Storages.Bids == StorageTypeMemory {
+		c.BidStorage = NewBidMemStorage()
+	} else {
+		c.BidStorage = newBidStorage(c.aero, config.Storages.Aero)
+	}
+	if config.Storages.Chains == StorageTypeMemory {
+		c.ChainStorage = NewChainMemStorage()
+	} else {
+		c.ChainStorage = newChainStorage(c.aero, config.Storages.Aero)
+	}
+	if config.Storages.Spreads == StorageTypeMemory {
+		c.SpreadStorage = NewSpreadMemStorage()
+	} else {
+		c.SpreadStorage = newSpreadStorage(c.aero, config.Storages.Aero)
+	}
+	switch archiveStorage(config) {
+	case StorageTypePg:
+		c.ChainArchiveStorage = newChainArchivePgStorage(c.pg)
+	case StorageTypeMemory:
+		c.ChainArchiveStorage = NewChainArchiveMemStorage()
+	case StorageTypeFile:
+		c.ChainArchiveStorage = NewChainArchiveFileStorage(config.Retention.Archive.Path)
+	default:
+		return errors.ErrChainArchiveStorageTypeInvalid(ctx, archiveStorage(config))
+	}
+	switch config.Storages.Subscriptions {
+	case StorageTypeMemory:
+		c.SubscriptionStorage = NewSubscriptionMemStorage()
+	case StorageTypePg:
+		c.SubscriptionStorage = newSubscriptionPgStorage(c.pg)
+	default:
+		c.SubscriptionStorage = newSubscriptionStorage(c.aero, config.Storages.Aero)
+	}
+	if config.Storages.RateHistory == StorageTypeMemory {
+		c.RateHistoryStorage = NewRateHistoryMemStorage()
+	} else {
+		c.RateHistoryStorage = newRateHistoryPgStorage(c.pg)
+	}
+	if config.Storages.Outbox == StorageTypeMemory {
+		c.NotificationOutboxStorage = NewOutboxMemStorage()
+	} else {
+		c.NotificationOutboxStorage = newOutboxPgStorage(c.pg)
+	}
+	if config.Storages.DeliveryPolicies == StorageTypeMemory {
+		c.DeliveryPolicyStorage = NewDeliveryPolicyMemStorage()
+	} else {
+		c.DeliveryPolicyStorage = newDeliveryPolicyPgStorage(c.pg)
+	}
+	if config.Storages.TelegramLinks == StorageTypeMemory {
+		c.TelegramLinkStorage = NewTelegramLinkMemStorage()
+	} else {
+		c.TelegramLinkStorage = newTelegramLinkPgStorage(c.pg)
+	}
+	if config.Storages.TelegramChannels == StorageTypeMemory {
+		c.TelegramChannelStorage = NewTelegramChannelMemStorage()
+	} else {
+		c.TelegramChannelStorage = newTelegramChannelPgStorage(c.pg)
+	}
+	if config.Storages.TelegramAlerts == StorageTypeMemory {
+		c.TelegramAlertStorage = NewTelegramAlertMemStorage()
+	} else {
+		c.TelegramAlertStorage = newTelegramAlertPgStorage(c.pg)
+	}
+	if config.Storages.TelegramBots == StorageTypeMemory {
+		c.TelegramBotAccountStorage = NewTelegramBotMemStorage()
+	} else {
+		c.TelegramBotAccountStorage = newTelegramBotPgStorage(c.pg)
+	}
+	if config.Storages.EmailVerifications == StorageTypeMemory {
+		c.EmailVerificationStorage = NewEmailVerificationMemStorage()
+	} else {
+		c.EmailVerificationStorage = newEmailVerificationPgStorage(c.pg)
+	}
+	if config.Storages.Users == StorageTypeMemory {
+		c.UserStorage = NewUserMemStorage()
+		c.SessionStorage = NewSessionMemStorage()
+	} else {
+		users := newUserStorage(c.pg, c.aero, config.Storages.Aero)
+		if err := users.init(ctx); err != nil {
+			return err
+		}
+		c.UserStorage = users
+		c.SessionStorage = newSessionStorage(c.pg, c.aero, config.Storages.Aero)
+	}
 	return nil
 }
 
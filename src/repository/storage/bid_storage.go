@@ -5,6 +5,7 @@ import (
 	aero "github.com/aerospike/aerospike-client-go/v6"
 	"github.com/mikhailbolshakov/cryptocare/src/domain"
 	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
 	kitAero "github.com/mikhailbolshakov/cryptocare/src/kit/storages/aerospike"
 	"github.com/mikhailbolshakov/cryptocare/src/service"
@@ -12,8 +13,13 @@ import (
 
 const (
 	SetBidsP2P = "bids_p2p"
+	// SetBidsPrivate keeps private bids apart from the public market, so they never get to the global snapshot
+	SetBidsPrivate = "bids_private"
 )
 
+// bidLightBins bins requested to build light bids
+var bidLightBins = []string{"src", "trg", "rate", "minLimit", "maxLimit", "available", "exchangeCode", "methods", "observedAt"}
+
 type bidStorageImpl struct {
 	aero kitAero.Aerospike
 	cfg  *kitAero.Config
@@ -32,10 +38,19 @@ func newBidStorage(aero kitAero.Aerospike, cfg *kitAero.Config) *bidStorageImpl
 
 func (b *bidStorageImpl) GetBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
 	b.l().C(ctx).Mth("get-bids-by-ids").Trc()
+	return b.getBidsByIds(ctx, SetBidsP2P, ids)
+}
+
+func (b *bidStorageImpl) GetPrivateBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
+	b.l().C(ctx).Mth("get-private-bids-by-ids").Trc()
+	return b.getBidsByIds(ctx, SetBidsPrivate, ids)
+}
+
+func (b *bidStorageImpl) getBidsByIds(ctx context.Context, set string, ids []string) ([]*domain.Bid, error) {
 	// build keys
 	keys := make([]*aero.Key, len(ids))
 	for i, id := range ids {
-		key, _ := aero.NewKey(b.cfg.Namespace, SetBidsP2P, id)
+		key, _ := aero.NewKey(b.cfg.Namespace, set, id)
 		keys[i] = key
 	}
 	batchPolicy := aero.NewBatchPolicy()
@@ -43,7 +58,6 @@ func (b *bidStorageImpl) GetBidsByIds(ctx context.Context, ids []string) ([]*dom
 	if err != nil {
 		return nil, errors.ErrBidStorageGetBidsByIds(err, ctx)
 	}
-	//res := make([]*domain.Bid, len(records))
 	var res []*domain.Bid
 	for _, r := range records {
 		if r != nil {
@@ -57,12 +71,23 @@ func (b *bidStorageImpl) GetBidsByIds(ctx context.Context, ids []string) ([]*dom
 	return res, nil
 }
 
+// bidSet returns set the bid is stored in
+func bidSet(bid *domain.Bid) string {
+	if bid.Private {
+		return SetBidsPrivate
+	}
+	return SetBidsP2P
+}
+
 func (b *bidStorageImpl) PutBids(ctx context.Context, bids []*domain.Bid, ttlSec uint32) error {
 	b.l().C(ctx).Mth("put-bids").Trc()
+	if ttlSec == 0 {
+		ttlSec = domain.DefaultBidTtlSec
+	}
 	writePolicy := aero.NewWritePolicy(0, ttlSec)
 	writePolicy.SendKey = true
 	for _, bid := range bids {
-		key, err := aero.NewKey(b.cfg.Namespace, SetBidsP2P, bid.Id)
+		key, err := aero.NewKey(b.cfg.Namespace, bidSet(bid), bid.Id)
 		if err != nil {
 			return errors.ErrBidStoragePutBids(err, ctx)
 		}
@@ -78,8 +103,7 @@ func (b *bidStorageImpl) GetBidsLightAll(ctx context.Context) ([]*domain.BidLigh
 	l := b.l().C(ctx).Mth("get-bids-light-all").Trc()
 	// scan all bids
 	scanPolicy := aero.NewScanPolicy()
-	recordSet, err := b.aero.Instance().ScanAll(scanPolicy, b.cfg.Namespace, SetBidsP2P,
-		"src", "trg", "rate", "minLimit", "maxLimit", "available")
+	recordSet, err := b.aero.Instance().ScanAll(scanPolicy, b.cfg.Namespace, SetBidsP2P, bidLightBins...)
 	if err != nil {
 		return nil, errors.ErrBidStorageScanBidsLight(err, ctx)
 	}
@@ -97,3 +121,93 @@ func (b *bidStorageImpl) GetBidsLightAll(ctx context.Context) ([]*domain.BidLigh
 	}
 	return res, nil
 }
+
+func (b *bidStorageImpl) GetBidsByOwner(ctx context.Context, ownerId string) ([]*domain.Bid, error) {
+	b.l().C(ctx).Mth("get-bids-by-owner").F(log.FF{"ownerId": ownerId}).Trc()
+	var res []*domain.Bid
+	for _, set := range []string{SetBidsP2P, SetBidsPrivate} {
+		recordSet, err := b.queryByOwner(ctx, set, ownerId)
+		if err != nil {
+			return nil, err
+		}
+		for r := range recordSet.Results() {
+			if r.Err != nil {
+				return nil, errors.ErrBidStorageGetBidsByOwner(r.Err, ctx)
+			}
+			bd, err := b.toBidDomain(ctx, r.Record)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, bd)
+		}
+	}
+	return res, nil
+}
+
+func (b *bidStorageImpl) GetPrivateBidsLightByOwner(ctx context.Context, ownerId string) ([]*domain.BidLight, error) {
+	b.l().C(ctx).Mth("get-private-bids-light").F(log.FF{"ownerId": ownerId}).Trc()
+	recordSet, err := b.queryByOwner(ctx, SetBidsPrivate, ownerId, append(bidLightBins, "ownerId")...)
+	if err != nil {
+		return nil, err
+	}
+	var res []*domain.BidLight
+	for r := range recordSet.Results() {
+		if r.Err != nil {
+			return nil, errors.ErrBidStorageGetBidsByOwner(r.Err, ctx)
+		}
+		bd, err := b.toBidLightDomain(ctx, r.Record)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, bd)
+	}
+	return res, nil
+}
+
+func (b *bidStorageImpl) GetPrivateBidOwners(ctx context.Context) ([]string, error) {
+	b.l().C(ctx).Mth("get-private-bid-owners").Trc()
+	recordSet, err := b.aero.Instance().ScanAll(aero.NewScanPolicy(), b.cfg.Namespace, SetBidsPrivate, "ownerId")
+	if err != nil {
+		return nil, errors.ErrBidStorageScanBidsLight(err, ctx)
+	}
+	var owners kit.Strings
+	for r := range recordSet.Results() {
+		if r.Err != nil {
+			return nil, errors.ErrBidStorageScanBidsLight(r.Err, ctx)
+		}
+		ownerId, err := kitAero.AsString(ctx, r.Record.Bins, "ownerId")
+		if err != nil {
+			return nil, err
+		}
+		if ownerId != "" {
+			owners = append(owners, ownerId)
+		}
+	}
+	return owners.Distinct(), nil
+}
+
+func (b *bidStorageImpl) queryByOwner(ctx context.Context, set, ownerId string, bins ...string) (*aero.Recordset, error) {
+	queryPolicy := aero.NewQueryPolicy()
+	queryPolicy.SendKey = true
+	queryPolicy.FilterExpression = aero.ExpEq(aero.ExpStringBin("ownerId"), aero.ExpStringVal(ownerId))
+	recordSet, err := b.aero.Instance().Query(queryPolicy, aero.NewStatement(b.cfg.Namespace, set, bins...))
+	if err != nil {
+		return nil, errors.ErrBidStorageGetBidsByOwner(err, ctx)
+	}
+	return recordSet, nil
+}
+
+func (b *bidStorageImpl) DeleteBid(ctx context.Context, bidId string) error {
+	b.l().C(ctx).Mth("delete-bid").F(log.FF{"bidId": bidId}).Trc()
+	// the bid might be in either set
+	for _, set := range []string{SetBidsP2P, SetBidsPrivate} {
+		key, err := aero.NewKey(b.cfg.Namespace, set, bidId)
+		if err != nil {
+			return errors.ErrBidStorageDeleteBid(err, ctx)
+		}
+		if _, err := b.aero.Instance().Delete(nil, key); err != nil {
+			return errors.ErrBidStorageDeleteBid(err, ctx)
+		}
+	}
+	return nil
+}
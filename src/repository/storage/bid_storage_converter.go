@@ -5,6 +5,7 @@ import (
 	aero "github.com/aerospike/aerospike-client-go/v6"
 	"github.com/mikhailbolshakov/cryptocare/src/domain"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/aerospike"
+	"time"
 )
 
 func (b *bidStorageImpl) toBidLightDomain(ctx context.Context, dto *aero.Record) (*domain.BidLight, error) {
@@ -37,6 +38,22 @@ func (b *bidStorageImpl) toBidLightDomain(ctx context.Context, dto *aero.Record)
 	if err != nil {
 		return nil, err
 	}
+	r.ExchangeCode, err = aerospike.AsString(ctx, dto.Bins, "exchangeCode")
+	if err != nil {
+		return nil, err
+	}
+	r.Methods, err = aerospike.AsStrings(ctx, dto.Bins, "methods")
+	if err != nil {
+		return nil, err
+	}
+	r.ObservedAt, err = b.asTime(ctx, dto.Bins, "observedAt")
+	if err != nil {
+		return nil, err
+	}
+	r.OwnerId, err = aerospike.AsString(ctx, dto.Bins, "ownerId")
+	if err != nil {
+		return nil, err
+	}
 	return r, nil
 }
 
@@ -86,11 +103,48 @@ func (b *bidStorageImpl) toBidDomain(ctx context.Context, dto *aero.Record) (*do
 	if err != nil {
 		return nil, err
 	}
+	r.ObservedAt, err = b.asTime(ctx, dto.Bins, "observedAt")
+	if err != nil {
+		return nil, err
+	}
+	r.IngestedAt, err = b.asTime(ctx, dto.Bins, "ingestedAt")
+	if err != nil {
+		return nil, err
+	}
+	r.ExpiresAt, err = b.asTime(ctx, dto.Bins, "expiresAt")
+	if err != nil {
+		return nil, err
+	}
+	r.OwnerId, err = aerospike.AsString(ctx, dto.Bins, "ownerId")
+	if err != nil {
+		return nil, err
+	}
+	// bids put before the type was stored are p2p
+	bidType, err := aerospike.AsString(ctx, dto.Bins, "type")
+	if err != nil {
+		return nil, err
+	}
+	if bidType != "" {
+		r.Type = bidType
+	}
+	r.Private, err = aerospike.AsBool(ctx, dto.Bins, "private")
+	if err != nil {
+		return nil, err
+	}
 	return r, nil
 }
 
+// asTime converts bin with unix nanos to time. Bids put before timestamps were introduced have zero time
+func (b *bidStorageImpl) asTime(ctx context.Context, bm aero.BinMap, bin string) (time.Time, error) {
+	nanos, err := aerospike.AsInt(ctx, bm, bin)
+	if err != nil || nanos == 0 {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(nanos)), nil
+}
+
 func (b *bidStorageImpl) toBidAero(bid *domain.Bid) aero.BinMap {
-	return aero.BinMap{
+	r := aero.BinMap{
 		"src":          bid.SrcAsset,
 		"trg":          bid.TrgAsset,
 		"rate":         bid.Rate,
@@ -101,5 +155,22 @@ func (b *bidStorageImpl) toBidAero(bid *domain.Bid) aero.BinMap {
 		"methods":      bid.Methods,
 		"userId":       bid.UserId,
 		"link":         bid.Link,
+		"type":         bid.Type,
+	}
+	if bid.OwnerId != "" {
+		r["ownerId"] = bid.OwnerId
+	}
+	if bid.Private {
+		r["private"] = true
+	}
+	if !bid.ExpiresAt.IsZero() {
+		r["expiresAt"] = bid.ExpiresAt.UnixNano()
+	}
+	if !bid.ObservedAt.IsZero() {
+		r["observedAt"] = bid.ObservedAt.UnixNano()
+	}
+	if !bid.IngestedAt.IsZero() {
+		r["ingestedAt"] = bid.IngestedAt.UnixNano()
 	}
+	return r
 }
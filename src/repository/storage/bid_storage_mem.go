@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	memcache "github.com/mikhailbolshakov/cryptocare/src/kit/cache"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
+)
+
+// bidMemStorageImpl keeps bids in memory
+// ttl semantic is the same as for aerospike storage: 0 means default ttl
+type bidMemStorageImpl struct {
+	cache memcache.MemCache
+}
+
+func (b *bidMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("bid-mem-storage")
+}
+
+func NewBidMemStorage() domain.BidStorage {
+	return &bidMemStorageImpl{
+		cache: memcache.NewMemCache(),
+	}
+}
+
+func (b *bidMemStorageImpl) GetBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
+	b.l().C(ctx).Mth("get-bids-by-ids").Trc()
+	return b.getBidsByIds(ids, false), nil
+}
+
+func (b *bidMemStorageImpl) GetPrivateBidsByIds(ctx context.Context, ids []string) ([]*domain.Bid, error) {
+	b.l().C(ctx).Mth("get-private-bids-by-ids").Trc()
+	return b.getBidsByIds(ids, true), nil
+}
+
+func (b *bidMemStorageImpl) getBidsByIds(ids []string, private bool) []*domain.Bid {
+	var res []*domain.Bid
+	for _, id := range ids {
+		if v, ok := b.cache.Get(id); ok {
+			if bid := *v.(*domain.Bid); bid.Private == private {
+				res = append(res, &bid)
+			}
+		}
+	}
+	return res
+}
+
+func (b *bidMemStorageImpl) PutBids(ctx context.Context, bids []*domain.Bid, ttlSec uint32) error {
+	b.l().C(ctx).Mth("put-bids").Trc()
+	if ttlSec == 0 {
+		ttlSec = domain.DefaultBidTtlSec
+	}
+	ttl := time.Duration(ttlSec) * time.Second
+	for _, bid := range bids {
+		stored := *bid
+		b.cache.Set(bid.Id, &stored, ttl)
+	}
+	return nil
+}
+
+func (b *bidMemStorageImpl) GetBidsLightAll(ctx context.Context) ([]*domain.BidLight, error) {
+	b.l().C(ctx).Mth("get-bids-light-all").Trc()
+	var res []*domain.BidLight
+	for _, v := range b.cache.Items() {
+		if bid := v.(*domain.Bid); !bid.Private {
+			res = append(res, b.toBidLightDomain(bid))
+		}
+	}
+	return res, nil
+}
+
+func (b *bidMemStorageImpl) GetPrivateBidsLightByOwner(ctx context.Context, ownerId string) ([]*domain.BidLight, error) {
+	b.l().C(ctx).Mth("get-private-bids-light").Trc()
+	var res []*domain.BidLight
+	for _, v := range b.cache.Items() {
+		if bid := v.(*domain.Bid); bid.Private && bid.OwnerId == ownerId {
+			res = append(res, b.toBidLightDomain(bid))
+		}
+	}
+	return res, nil
+}
+
+func (b *bidMemStorageImpl) GetPrivateBidOwners(ctx context.Context) ([]string, error) {
+	b.l().C(ctx).Mth("get-private-bid-owners").Trc()
+	var owners kit.Strings
+	for _, v := range b.cache.Items() {
+		if bid := v.(*domain.Bid); bid.Private {
+			owners = append(owners, bid.OwnerId)
+		}
+	}
+	return owners.Distinct(), nil
+}
+
+func (b *bidMemStorageImpl) GetBidsByOwner(ctx context.Context, ownerId string) ([]*domain.Bid, error) {
+	b.l().C(ctx).Mth("get-bids-by-owner").Trc()
+	var res []*domain.Bid
+	for _, v := range b.cache.Items() {
+		if bid := *v.(*domain.Bid); bid.OwnerId == ownerId {
+			res = append(res, &bid)
+		}
+	}
+	return res, nil
+}
+
+func (b *bidMemStorageImpl) DeleteBid(ctx context.Context, bidId string) error {
+	b.l().C(ctx).Mth("delete-bid").Trc()
+	b.cache.Delete(bidId)
+	return nil
+}
+
+// toBidLightDomain converts bid, bids put without type are considered p2p as aerospike storage does
+func (b *bidMemStorageImpl) toBidLightDomain(bid *domain.Bid) *domain.BidLight {
+	bidType := bid.Type
+	if bidType == "" {
+		bidType = domain.BidTypeP2P
+	}
+	return &domain.BidLight{
+		Id:           bid.Id,
+		Type:         bidType,
+		SrcAsset:     bid.SrcAsset,
+		TrgAsset:     bid.TrgAsset,
+		Rate:         bid.Rate,
+		Available:    bid.Available,
+		MinLimit:     bid.MinLimit,
+		MaxLimit:     bid.MaxLimit,
+		ExchangeCode: bid.ExchangeCode,
+		Methods:      bid.Methods,
+		ObservedAt:   bid.ObservedAt,
+		OwnerId:      bid.OwnerId,
+	}
+}
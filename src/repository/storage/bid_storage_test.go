@@ -76,7 +76,7 @@ func (s *bidStorageTestSuite) Test_PutGet() {
 		bids[i] = s.getBid()
 	}
 	// put to store
-	err := s.storage.PutBids(s.Ctx, bids)
+	err := s.storage.PutBids(s.Ctx, bids, 60)
 	if err != nil {
 		s.Fatal(err)
 	}
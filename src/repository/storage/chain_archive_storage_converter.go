@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+)
+
+func (s *chainArchivePgStorageImpl) toArchivedChainDto(chain *domain.ProfitableChain) *archivedChain {
+	data, _ := json.Marshal(chain)
+	r := &archivedChain{
+		Id:             chain.Id,
+		Asset:          chain.Asset,
+		ProfitShare:    chain.ProfitShare,
+		Depth:          chain.Depth,
+		Data:           string(data),
+		ChainCreatedAt: chain.CreatedAt,
+	}
+	if !chain.ExpiresAt.IsZero() {
+		r.ExpiresAt = &chain.ExpiresAt
+	}
+	return r
+}
+
+func (s *chainArchivePgStorageImpl) toArchivedChainDomain(dto *archivedChain) *domain.ProfitableChain {
+	r := &domain.ProfitableChain{}
+	_ = json.Unmarshal([]byte(dto.Data), r)
+	r.Id = dto.Id
+	r.Archived = true
+	return r
+}
@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// chainArchiveIdsDir folder of chain id index
+	chainArchiveIdsDir = "ids"
+	// chainArchiveHourLayout layout of folders chains are put to by creation hour
+	chainArchiveHourLayout = "20060102/15"
+)
+
+// chainArchiveFileStorageImpl keeps archived chains as gzipped json files
+// chain files are put to folders by creation hour (UTC), e.g. 20221128/14, so chains created within a period are read without walking the whole archive
+// to find a chain by id, the id index keeps the path of the chain file in a file named by chain id
+// index files are spread by subfolders named by the first two symbols of chain id, so folders don't grow too big
+type chainArchiveFileStorageImpl struct {
+	path string
+}
+
+func (s *chainArchiveFileStorageImpl) l() log.CLogger {
+	return service.L().Cmp("chain-archive-file-storage")
+}
+
+func NewChainArchiveFileStorage(path string) domain.ChainArchiveStorage {
+	return &chainArchiveFileStorageImpl{
+		path: path,
+	}
+}
+
+// hourDir returns the folder of chains created within the hour
+func (s *chainArchiveFileStorageImpl) hourDir(t time.Time) string {
+	return filepath.Join(s.path, filepath.FromSlash(t.UTC().Format(chainArchiveHourLayout)))
+}
+
+// chainFile returns the path of the chain file
+func (s *chainArchiveFileStorageImpl) chainFile(chain *domain.ProfitableChain) string {
+	return filepath.Join(s.hourDir(chain.CreatedAt), chain.Id+".json.gz")
+}
+
+// indexFile returns the path of the index file of the chain
+func (s *chainArchiveFileStorageImpl) indexFile(chainId string) string {
+	dir := chainId
+	if len(dir) > 2 {
+		dir = dir[:2]
+	}
+	return filepath.Join(s.path, chainArchiveIdsDir, dir, chainId)
+}
+
+func (s *chainArchiveFileStorageImpl) ArchiveChains(ctx context.Context, chains []*domain.ProfitableChain) error {
+	s.l().C(ctx).Mth("archive").F(log.FF{"count": len(chains)}).Trc()
+	for _, chain := range chains {
+		if err := s.writeChain(chain); err != nil {
+			return errors.ErrChainArchiveStoragePut(err, ctx)
+		}
+	}
+	return nil
+}
+
+// writeChain writes the chain file and then the index file
+// if the chain is overwritten with another creation time, the previous chain file is removed
+func (s *chainArchiveFileStorageImpl) writeChain(chain *domain.ProfitableChain) error {
+	fn := s.chainFile(chain)
+	prev, err := s.indexedFile(chain.Id)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	err = s.writeFile(fn, func(w io.Writer) error {
+		zw := gzip.NewWriter(w)
+		if err := json.NewEncoder(zw).Encode(chain); err != nil {
+			return err
+		}
+		return zw.Close()
+	})
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(s.path, fn)
+	if err != nil {
+		return err
+	}
+	err = s.writeFile(s.indexFile(chain.Id), func(w io.Writer) error {
+		_, err := io.WriteString(w, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if prev != "" && prev != fn {
+		if err := os.Remove(prev); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile writes to a temp file and renames it, so readers never see a partially written file
+func (s *chainArchiveFileStorageImpl) writeFile(fn string, write func(w io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(fn), filepath.Base(fn)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), fn)
+}
+
+// indexedFile reads the path of the chain file from the index
+func (s *chainArchiveFileStorageImpl) indexedFile(chainId string) (string, error) {
+	rel, err := os.ReadFile(s.indexFile(chainId))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.path, filepath.FromSlash(string(rel))), nil
+}
+
+func (s *chainArchiveFileStorageImpl) GetArchivedChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
+	s.l().C(ctx).Mth("get").F(log.FF{"chainId": chainId}).Trc()
+	// chain id is a part of the file path, so it mustn't contain path elements
+	if chainId == "" || filepath.Base(chainId) != chainId {
+		return nil, nil
+	}
+	fn, err := s.indexedFile(chainId)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.ErrChainArchiveStorageGet(err, ctx)
+	}
+	r, err := s.readChain(fn)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.ErrChainArchiveStorageGet(err, ctx)
+	}
+	return r, nil
+}
+
+func (s *chainArchiveFileStorageImpl) readChain(fn string) (*domain.ProfitableChain, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = zr.Close() }()
+	r := &domain.ProfitableChain{}
+	if err := json.NewDecoder(zr).Decode(r); err != nil {
+		return nil, err
+	}
+	r.Archived = true
+	return r, nil
+}
+
+// GetArchivedChains reads folders of the hours within the period starting from the latest one
+// once the limit is reached, older hours aren't read
+func (s *chainArchiveFileStorageImpl) GetArchivedChains(ctx context.Context, from, to time.Time, limit int) ([]*domain.ProfitableChain, error) {
+	s.l().C(ctx).Mth("get-chains").F(log.FF{"from": from, "to": to}).Trc()
+	var r []*domain.ProfitableChain
+	for hour := to.UTC().Truncate(time.Hour); !hour.Before(from.UTC().Truncate(time.Hour)); hour = hour.Add(-time.Hour) {
+		chains, err := s.readHour(hour)
+		if err != nil {
+			return nil, errors.ErrChainArchiveStorageGet(err, ctx)
+		}
+		for _, chain := range chains {
+			if !chain.CreatedAt.Before(from) && chain.CreatedAt.Before(to) {
+				r = append(r, chain)
+			}
+		}
+		if limit > 0 && len(r) >= limit {
+			break
+		}
+	}
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].CreatedAt.After(r[j].CreatedAt)
+	})
+	if limit > 0 && len(r) > limit {
+		r = r[:limit]
+	}
+	return r, nil
+}
+
+// readHour reads chains created within the hour
+func (s *chainArchiveFileStorageImpl) readHour(hour time.Time) ([]*domain.ProfitableChain, error) {
+	dir := s.hourDir(hour)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var r []*domain.ProfitableChain
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json.gz") {
+			continue
+		}
+		chain, err := s.readChain(filepath.Join(dir, e.Name()))
+		if err != nil {
+			// the file might have been removed while reading
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		r = append(r, chain)
+	}
+	return r, nil
+}
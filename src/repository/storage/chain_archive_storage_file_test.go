@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type chainArchiveFileStorageTestSuite struct {
+	kitTestSuite.Suite
+	storage domain.ChainArchiveStorage
+}
+
+func (s *chainArchiveFileStorageTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestChainArchiveFileStorageSuite(t *testing.T) {
+	suite.Run(t, new(chainArchiveFileStorageTestSuite))
+}
+
+func (s *chainArchiveFileStorageTestSuite) SetupTest() {
+	s.storage = NewChainArchiveFileStorage(s.T().TempDir())
+}
+
+func (s *chainArchiveFileStorageTestSuite) Test_ArchiveGet() {
+	now := time.Now().UTC()
+	chain := &domain.ProfitableChain{
+		Id:          kit.NewRandString(),
+		Asset:       "USDT",
+		ProfitShare: 1.02,
+		Depth:       2,
+		Bids:        []*domain.Bid{{Id: kit.NewRandString(), SrcAsset: "USDT", TrgAsset: "RUB", Rate: 60}},
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+	s.NoError(s.storage.ArchiveChains(s.Ctx, []*domain.ProfitableChain{chain}))
+
+	act, err := s.storage.GetArchivedChain(s.Ctx, chain.Id)
+	s.NoError(err)
+	s.NotNil(act)
+	s.True(act.Archived)
+	s.Equal(chain.Asset, act.Asset)
+	s.Equal(chain.ProfitShare, act.ProfitShare)
+	s.Len(act.Bids, 1)
+	s.True(chain.CreatedAt.Equal(act.CreatedAt))
+
+	// overwrite
+	chain.ProfitShare = 1.03
+	s.NoError(s.storage.ArchiveChains(s.Ctx, []*domain.ProfitableChain{chain}))
+	act, err = s.storage.GetArchivedChain(s.Ctx, chain.Id)
+	s.NoError(err)
+	s.Equal(1.03, act.ProfitShare)
+}
+
+func (s *chainArchiveFileStorageTestSuite) Test_Get_NotFound() {
+	act, err := s.storage.GetArchivedChain(s.Ctx, kit.NewRandString())
+	s.NoError(err)
+	s.Nil(act)
+	act, err = s.storage.GetArchivedChain(s.Ctx, "../../etc/passwd")
+	s.NoError(err)
+	s.Nil(act)
+}
+
+func (s *chainArchiveFileStorageTestSuite) Test_GetArchivedChains() {
+	now := time.Now().UTC()
+	var chains []*domain.ProfitableChain
+	for _, age := range []time.Duration{time.Minute, time.Hour, 2 * time.Hour, 5 * time.Hour} {
+		chains = append(chains, &domain.ProfitableChain{Id: kit.NewRandString(), Asset: "USDT", ProfitShare: 1.02, CreatedAt: now.Add(-age)})
+	}
+	s.NoError(s.storage.ArchiveChains(s.Ctx, chains))
+
+	act, err := s.storage.GetArchivedChains(s.Ctx, now.Add(-3*time.Hour), now, 0)
+	s.NoError(err)
+	s.Len(act, 3)
+	// the latest go first
+	s.Equal(chains[0].Id, act[0].Id)
+	s.Equal(chains[2].Id, act[2].Id)
+	s.True(act[0].Archived)
+
+	act, err = s.storage.GetArchivedChains(s.Ctx, now.Add(-3*time.Hour), now.Add(-30*time.Minute), 1)
+	s.NoError(err)
+	s.Len(act, 1)
+	s.Equal(chains[1].Id, act[0].Id)
+}
+
+func (s *chainArchiveFileStorageTestSuite) Test_GetArchivedChains_WhenEmpty() {
+	s.storage = NewChainArchiveFileStorage(filepath.Join(s.T().TempDir(), "none"))
+	act, err := s.storage.GetArchivedChains(s.Ctx, time.Now().Add(-time.Hour), time.Now(), 10)
+	s.NoError(err)
+	s.Empty(act)
+}
+
+func (s *chainArchiveFileStorageTestSuite) Test_ChainsIndexedByCreationHour() {
+	path := s.T().TempDir()
+	s.storage = NewChainArchiveFileStorage(path)
+	createdAt := time.Date(2022, 11, 28, 14, 30, 0, 0, time.UTC)
+	chain := &domain.ProfitableChain{Id: kit.NewRandString(), Asset: "USDT", CreatedAt: createdAt}
+	s.NoError(s.storage.ArchiveChains(s.Ctx, []*domain.ProfitableChain{chain}))
+	s.FileExists(filepath.Join(path, "20221128", "14", chain.Id+".json.gz"))
+
+	// creation time changed, the chain is moved to another hour
+	chain.CreatedAt = createdAt.Add(time.Hour)
+	s.NoError(s.storage.ArchiveChains(s.Ctx, []*domain.ProfitableChain{chain}))
+	s.NoFileExists(filepath.Join(path, "20221128", "14", chain.Id+".json.gz"))
+	s.FileExists(filepath.Join(path, "20221128", "15", chain.Id+".json.gz"))
+	act, err := s.storage.GetArchivedChain(s.Ctx, chain.Id)
+	s.NoError(err)
+	s.True(chain.CreatedAt.Equal(act.CreatedAt))
+
+	chains, err := s.storage.GetArchivedChains(s.Ctx, createdAt.Add(-time.Hour), createdAt.Add(2*time.Hour), 0)
+	s.NoError(err)
+	s.Len(chains, 1)
+}
+
+func (s *chainArchiveFileStorageTestSuite) Test_GetArchivedChains_WhenLimitReached_OlderHoursNotRead() {
+	path := s.T().TempDir()
+	s.storage = NewChainArchiveFileStorage(path)
+	now := time.Now().UTC()
+	latest := &domain.ProfitableChain{Id: kit.NewRandString(), Asset: "USDT", CreatedAt: now.Add(-time.Minute)}
+	old := &domain.ProfitableChain{Id: kit.NewRandString(), Asset: "USDT", CreatedAt: now.Add(-5 * time.Hour)}
+	s.NoError(s.storage.ArchiveChains(s.Ctx, []*domain.ProfitableChain{latest, old}))
+	// break the old hour, it mustn't be read
+	oldFile := filepath.Join(path, filepath.FromSlash(old.CreatedAt.Format("20060102/15")), old.Id+".json.gz")
+	s.NoError(os.WriteFile(oldFile, []byte("broken"), 0644))
+
+	act, err := s.storage.GetArchivedChains(s.Ctx, now.Add(-6*time.Hour), now, 1)
+	s.NoError(err)
+	s.Len(act, 1)
+	s.Equal(latest.Id, act[0].Id)
+
+	_, err = s.storage.GetArchivedChains(s.Ctx, now.Add(-6*time.Hour), now, 0)
+	s.AssertAppErr(err, errors.ErrCodeChainArchiveStorageGet)
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sort"
+	"sync"
+	"time"
+)
+
+// chainArchiveMemStorageImpl keeps archived chains in memory, the archive is lost on restart
+type chainArchiveMemStorageImpl struct {
+	sync.RWMutex
+	chains map[string]*domain.ProfitableChain
+}
+
+func (s *chainArchiveMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("chain-archive-mem-storage")
+}
+
+func NewChainArchiveMemStorage() domain.ChainArchiveStorage {
+	return &chainArchiveMemStorageImpl{
+		chains: make(map[string]*domain.ProfitableChain),
+	}
+}
+
+func (s *chainArchiveMemStorageImpl) ArchiveChains(ctx context.Context, chains []*domain.ProfitableChain) error {
+	s.l().C(ctx).Mth("archive").F(log.FF{"count": len(chains)}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	for _, chain := range chains {
+		stored := *chain
+		s.chains[chain.Id] = &stored
+	}
+	return nil
+}
+
+func (s *chainArchiveMemStorageImpl) GetArchivedChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
+	s.l().C(ctx).Mth("get").F(log.FF{"chainId": chainId}).Trc()
+	s.RLock()
+	defer s.RUnlock()
+	chain, ok := s.chains[chainId]
+	if !ok {
+		return nil, nil
+	}
+	r := *chain
+	return &r, nil
+}
+
+func (s *chainArchiveMemStorageImpl) GetArchivedChains(ctx context.Context, from, to time.Time, limit int) ([]*domain.ProfitableChain, error) {
+	s.l().C(ctx).Mth("get-chains").F(log.FF{"from": from, "to": to}).Trc()
+	s.RLock()
+	defer s.RUnlock()
+	var r []*domain.ProfitableChain
+	for _, chain := range s.chains {
+		if !chain.CreatedAt.Before(from) && chain.CreatedAt.Before(to) {
+			ch := *chain
+			r = append(r, &ch)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].CreatedAt.After(r[j].CreatedAt)
+	})
+	if limit > 0 && len(r) > limit {
+		r = r[:limit]
+	}
+	return r, nil
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type archivedChain struct {
+	pg.GormDto
+	Id             string     `gorm:"column:id"`
+	Asset          string     `gorm:"column:asset"`
+	ProfitShare    float64    `gorm:"column:profit_share"`
+	Depth          int        `gorm:"column:depth"`
+	Data           string     `gorm:"column:data"`
+	ChainCreatedAt time.Time  `gorm:"column:chain_created_at"`
+	ExpiresAt      *time.Time `gorm:"column:expires_at"`
+}
+
+// chainArchivePgStorageImpl keeps archived chains in postgres
+type chainArchivePgStorageImpl struct {
+	pg *pg.Storage
+}
+
+func (s *chainArchivePgStorageImpl) l() log.CLogger {
+	return service.L().Cmp("chain-archive-pg-storage")
+}
+
+func newChainArchivePgStorage(pg *pg.Storage) *chainArchivePgStorageImpl {
+	return &chainArchivePgStorageImpl{
+		pg: pg,
+	}
+}
+
+func (s *chainArchivePgStorageImpl) ArchiveChains(ctx context.Context, chains []*domain.ProfitableChain) error {
+	s.l().C(ctx).Mth("archive").F(log.FF{"count": len(chains)}).Trc()
+	if len(chains) == 0 {
+		return nil
+	}
+	dtos := make([]*archivedChain, len(chains))
+	for i, chain := range chains {
+		dtos[i] = s.toArchivedChainDto(chain)
+	}
+	err := s.pg.Instance.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, UpdateAll: true}).
+		Create(dtos).Error
+	if err != nil {
+		return errors.ErrChainArchiveStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *chainArchivePgStorageImpl) GetArchivedChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
+	s.l().C(ctx).Mth("get").F(log.FF{"chainId": chainId}).Trc()
+	dto := &archivedChain{}
+	res := s.pg.Instance.WithContext(ctx).Where("id = ?", chainId).Limit(1).Find(dto)
+	if res.Error != nil {
+		return nil, errors.ErrChainArchiveStorageGet(res.Error, ctx)
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+	return s.toArchivedChainDomain(dto), nil
+}
+
+func (s *chainArchivePgStorageImpl) GetArchivedChains(ctx context.Context, from, to time.Time, limit int) ([]*domain.ProfitableChain, error) {
+	s.l().C(ctx).Mth("get-chains").F(log.FF{"from": from, "to": to}).Trc()
+	var dtos []*archivedChain
+	q := s.pg.Instance.WithContext(ctx).
+		Where("chain_created_at >= ? and chain_created_at < ?", from, to).
+		Order("chain_created_at desc")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&dtos).Error; err != nil {
+		return nil, errors.ErrChainArchiveStorageGet(err, ctx)
+	}
+	r := make([]*domain.ProfitableChain, len(dtos))
+	for i, dto := range dtos {
+		r[i] = s.toArchivedChainDomain(dto)
+	}
+	return r, nil
+}
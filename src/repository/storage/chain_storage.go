@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	aero "github.com/aerospike/aerospike-client-go/v6"
 	"github.com/aerospike/aerospike-client-go/v6/types"
 	"github.com/mikhailbolshakov/cryptocare/src/domain"
@@ -9,10 +10,13 @@ import (
 	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
 	kitAero "github.com/mikhailbolshakov/cryptocare/src/kit/storages/aerospike"
 	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
 )
 
 const (
 	SetProfitableChains = "profitable_chains"
+	// chainDefaultTtl ttl of the chain if expiration isn't specified
+	chainDefaultTtl = time.Hour
 )
 
 type chainStorageImpl struct {
@@ -33,13 +37,13 @@ func newChainStorage(aero kitAero.Aerospike, cfg *kitAero.Config) *chainStorageI
 
 func (c *chainStorageImpl) SaveProfitableChains(ctx context.Context, chains []*domain.ProfitableChain) error {
 	c.l().C(ctx).Mth("save-chains").Trc()
-	writePolicy := aero.NewWritePolicy(0, 60*60)
-	writePolicy.SendKey = true
 	for _, chain := range chains {
 		key, err := aero.NewKey(c.cfg.Namespace, SetProfitableChains, chain.Id)
 		if err != nil {
 			return errors.ErrChainStoragePutChain(err, ctx)
 		}
+		writePolicy := aero.NewWritePolicy(0, uint32(chainTtl(chain).Seconds()))
+		writePolicy.SendKey = true
 		err = c.aero.Instance().Put(writePolicy, key, c.toProfitableChainAero(chain))
 		if err != nil {
 			return errors.ErrChainStoragePutChain(err, ctx)
@@ -51,7 +55,9 @@ func (c *chainStorageImpl) SaveProfitableChains(ctx context.Context, chains []*d
 func (c *chainStorageImpl) GetProfitableChains(ctx context.Context, rq *domain.GetProfitableChainsRequest) (*domain.GetProfitableChainsResponse, error) {
 	c.l().C(ctx).Mth("get-chains").Trc()
 
-	var exp *aero.Expression
+	// criteria are filtered on the server side except for methods which are compared sanitized after
+	// the matched chains are sorted and paged in memory, so every page (even with a cursor) costs O(N) of the matched chains
+	var exps []*aero.Expression
 
 	// filter by assets
 	var assetExps []*aero.Expression
@@ -59,28 +65,58 @@ func (c *chainStorageImpl) GetProfitableChains(ctx context.Context, rq *domain.G
 		assetExps = append(assetExps, aero.ExpEq(aero.ExpStringBin("asset"), aero.ExpStringVal(asset)))
 	}
 	if len(assetExps) > 1 {
-		exp = aero.ExpOr(assetExps...)
+		exps = append(exps, aero.ExpOr(assetExps...))
 	}
 	if len(assetExps) == 1 {
-		exp = assetExps[0]
+		exps = append(exps, assetExps[0])
+	}
+	if len(rq.ExchangeCodes) > 0 {
+		exps = append(exps, listSubsetExp("exchange_codes", rq.ExchangeCodes))
+	}
+	if len(rq.BidTypes) > 0 {
+		exps = append(exps, listSubsetExp("bid_types", rq.BidTypes))
+	}
+	if rq.MinProfit != 0.0 {
+		exps = append(exps, aero.ExpGreaterEq(aero.ExpFloatBin("profit_share"), aero.ExpFloatVal(1+rq.MinProfit*0.01)))
+	}
+	if rq.MaxProfit != 0.0 {
+		exps = append(exps, aero.ExpLessEq(aero.ExpFloatBin("profit_share"), aero.ExpFloatVal(1+rq.MaxProfit*0.01)))
+	}
+	if rq.MinDepth != 0 {
+		exps = append(exps, aero.ExpGreaterEq(aero.ExpIntBin("depth"), aero.ExpIntVal(int64(rq.MinDepth))))
+	}
+	if rq.MaxDepth != 0 {
+		exps = append(exps, aero.ExpLessEq(aero.ExpIntBin("depth"), aero.ExpIntVal(int64(rq.MaxDepth))))
+	}
+	if rq.MinBaseProfit != 0.0 {
+		exps = append(exps, aero.ExpGreaterEq(aero.ExpFloatBin("base_profit"), aero.ExpFloatVal(rq.MinBaseProfit)))
+	}
+	if rq.MinBaseVolume != 0.0 {
+		exps = append(exps, aero.ExpGreaterEq(aero.ExpFloatBin("base_volume"), aero.ExpFloatVal(rq.MinBaseVolume)))
+	}
+	if rq.CreatedAfter != nil {
+		exps = append(exps, aero.ExpGreater(aero.ExpIntBin("created_at"), aero.ExpIntVal(rq.CreatedAfter.UnixNano())))
 	}
 
 	queryPolicy := aero.NewQueryPolicy()
 	queryPolicy.SendKey = true
-	queryPolicy.MaxRecords = int64(rq.Size)
-	queryPolicy.FilterExpression = exp
-
-	bins := []string{"asset", "profit_share", "methods", "bid_assets", "depth", "exchange_codes", "created_at"}
-	if rq.WithBids {
-		bins = append(bins, "bids")
+	if len(exps) > 1 {
+		queryPolicy.FilterExpression = aero.ExpAnd(exps...)
+	}
+	if len(exps) == 1 {
+		queryPolicy.FilterExpression = exps[0]
 	}
-	statement := aero.NewStatement(c.cfg.Namespace, SetProfitableChains, bins...)
 
-	recordSet, err := c.aero.Instance().Query(queryPolicy, statement)
-	if err != nil {
-		return nil, errors.ErrChainStorageScanChains(err, ctx)
+	// bids are requested for the page only
+	statement := aero.NewStatement(c.cfg.Namespace, SetProfitableChains,
+		"asset", "profit_share", "methods", "bid_assets", "depth", "exchange_codes", "bid_types", "score", "volume",
+		"base_currency", "base_volume", "base_profit", "created_at", "expires_at", "observed_at")
+
+	recordSet, aeroErr := c.aero.Instance().Query(queryPolicy, statement)
+	if aeroErr != nil {
+		return nil, errors.ErrChainStorageScanChains(aeroErr, ctx)
 	}
-	res := &domain.GetProfitableChainsResponse{}
+	var chains []*domain.ProfitableChain
 	for r := range recordSet.Results() {
 		if r.Err != nil {
 			return nil, errors.ErrChainStorageScanChains(r.Err, ctx)
@@ -89,12 +125,67 @@ func (c *chainStorageImpl) GetProfitableChains(ctx context.Context, rq *domain.G
 			if err != nil {
 				return nil, err
 			}
-			res.Chains = append(res.Chains, chain)
+			if matchChainRequest(rq, chain) {
+				chains = append(chains, chain)
+			}
+		}
+	}
+
+	res, err := pageChains(ctx, rq, chains)
+	if err != nil {
+		return nil, err
+	}
+	if rq.WithBids {
+		if err := c.populateBids(ctx, res.Chains); err != nil {
+			return nil, err
 		}
 	}
 	return res, nil
 }
 
+// listSubsetExp matches records which list bin items are all among the given values
+func listSubsetExp(bin string, values []string) *aero.Expression {
+	vals := make([]aero.Value, 0, len(values))
+	for _, v := range values {
+		vals = append(vals, aero.StringValue(v))
+	}
+	// count of items which aren't among the values
+	notAmong := aero.ExpListGetByValueList(aero.ListReturnTypeCount|aero.ListReturnTypeInverted, aero.ExpListVal(vals...), aero.ExpListBin(bin))
+	return aero.ExpEq(notAmong, aero.ExpIntVal(0))
+}
+
+// populateBids retrieves bids of the chains
+func (c *chainStorageImpl) populateBids(ctx context.Context, chains []*domain.ProfitableChain) error {
+	if len(chains) == 0 {
+		return nil
+	}
+	keys := make([]*aero.Key, len(chains))
+	for i, chain := range chains {
+		key, err := aero.NewKey(c.cfg.Namespace, SetProfitableChains, chain.Id)
+		if err != nil {
+			return errors.ErrChainStorageGetChain(err, ctx)
+		}
+		keys[i] = key
+	}
+	records, aeroErr := c.aero.Instance().BatchGet(aero.NewBatchPolicy(), keys, "bids")
+	if aeroErr != nil {
+		return errors.ErrChainStorageGetChain(aeroErr, ctx)
+	}
+	for i, r := range records {
+		if r == nil {
+			continue
+		}
+		bidsb, err := kitAero.AsBytes(ctx, r.Bins, "bids")
+		if err != nil {
+			return err
+		}
+		if bidsb != nil {
+			_ = json.Unmarshal(bidsb, &chains[i].Bids)
+		}
+	}
+	return nil
+}
+
 func (c *chainStorageImpl) GetProfitableChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
 	c.l().C(ctx).Mth("get-chain").F(log.FF{"chainId": chainId}).Trc()
 
@@ -136,3 +227,31 @@ func (c *chainStorageImpl) ProfitableChainExists(ctx context.Context, chainId st
 	}
 	return rec != nil, nil
 }
+
+func (c *chainStorageImpl) GetExpiringChains(ctx context.Context, from, to time.Time) ([]*domain.ProfitableChain, error) {
+	c.l().C(ctx).Mth("get-expiring-chains").Trc()
+
+	queryPolicy := aero.NewQueryPolicy()
+	queryPolicy.SendKey = true
+	queryPolicy.FilterExpression = aero.ExpAnd(
+		aero.ExpGreater(aero.ExpIntBin("expires_at"), aero.ExpIntVal(from.UnixNano())),
+		aero.ExpLessEq(aero.ExpIntBin("expires_at"), aero.ExpIntVal(to.UnixNano())),
+	)
+
+	recordSet, aeroErr := c.aero.Instance().Query(queryPolicy, aero.NewStatement(c.cfg.Namespace, SetProfitableChains))
+	if aeroErr != nil {
+		return nil, errors.ErrChainStorageScanChains(aeroErr, ctx)
+	}
+	var chains []*domain.ProfitableChain
+	for r := range recordSet.Results() {
+		if r.Err != nil {
+			return nil, errors.ErrChainStorageScanChains(r.Err, ctx)
+		}
+		chain, err := c.toProfitableChainDomain(ctx, r.Record)
+		if err != nil {
+			return nil, err
+		}
+		chains = append(chains, chain)
+	}
+	return chains, nil
+}
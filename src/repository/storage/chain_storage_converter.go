@@ -11,16 +11,31 @@ import (
 
 func (c *chainStorageImpl) toProfitableChainAero(chain *domain.ProfitableChain) aero.BinMap {
 	det, _ := json.Marshal(chain.Bids)
-	return aero.BinMap{
+	r := aero.BinMap{
 		"asset":          chain.Asset,
 		"profit_share":   chain.ProfitShare,
 		"depth":          chain.Depth,
 		"methods":        chain.Methods,
 		"bid_assets":     chain.BidAssets,
 		"exchange_codes": chain.ExchangeCodes,
+		"bid_types":      chain.BidTypes,
+		"score":          chain.Score,
+		"volume":         chain.Volume,
 		"created_at":     chain.CreatedAt.UnixNano(),
 		"bids":           det,
 	}
+	if chain.BaseCurrency != "" {
+		r["base_currency"] = chain.BaseCurrency
+		r["base_volume"] = chain.BaseVolume
+		r["base_profit"] = chain.BaseProfit
+	}
+	if !chain.ExpiresAt.IsZero() {
+		r["expires_at"] = chain.ExpiresAt.UnixNano()
+	}
+	if !chain.ObservedAt.IsZero() {
+		r["observed_at"] = chain.ObservedAt.UnixNano()
+	}
+	return r
 }
 
 func (c *chainStorageImpl) toProfitableChainDomain(ctx context.Context, chain *aero.Record) (*domain.ProfitableChain, error) {
@@ -58,7 +73,45 @@ func (c *chainStorageImpl) toProfitableChainDomain(ctx context.Context, chain *a
 	if err != nil {
 		return nil, err
 	}
+	r.BidTypes, err = aerospike.AsStrings(ctx, chain.Bins, "bid_types")
+	if err != nil {
+		return nil, err
+	}
+	r.Score, err = aerospike.AsFloat(ctx, chain.Bins, "score")
+	if err != nil {
+		return nil, err
+	}
+	r.Volume, err = aerospike.AsFloat(ctx, chain.Bins, "volume")
+	if err != nil {
+		return nil, err
+	}
+	r.BaseCurrency, err = aerospike.AsString(ctx, chain.Bins, "base_currency")
+	if err != nil {
+		return nil, err
+	}
+	r.BaseVolume, err = aerospike.AsFloat(ctx, chain.Bins, "base_volume")
+	if err != nil {
+		return nil, err
+	}
+	r.BaseProfit, err = aerospike.AsFloat(ctx, chain.Bins, "base_profit")
+	if err != nil {
+		return nil, err
+	}
 	r.CreatedAt = time.Unix(0, int64(createdAtInt))
+	expiresAtInt, err := aerospike.AsInt(ctx, chain.Bins, "expires_at")
+	if err != nil {
+		return nil, err
+	}
+	if expiresAtInt != 0 {
+		r.ExpiresAt = time.Unix(0, int64(expiresAtInt))
+	}
+	observedAtInt, err := aerospike.AsInt(ctx, chain.Bins, "observed_at")
+	if err != nil {
+		return nil, err
+	}
+	if observedAtInt != 0 {
+		r.ObservedAt = time.Unix(0, int64(observedAtInt))
+	}
 	bidsb, err := aerospike.AsBytes(ctx, chain.Bins, "bids")
 	if err != nil {
 		return nil, err
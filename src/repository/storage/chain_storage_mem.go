@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	memcache "github.com/mikhailbolshakov/cryptocare/src/kit/cache"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"time"
+)
+
+// chainMemStorageImpl keeps profitable chains in memory
+type chainMemStorageImpl struct {
+	cache memcache.MemCache
+}
+
+func (c *chainMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("chain-mem-storage")
+}
+
+func NewChainMemStorage() domain.ChainStorage {
+	return &chainMemStorageImpl{
+		cache: memcache.NewMemCache(),
+	}
+}
+
+func (c *chainMemStorageImpl) SaveProfitableChains(ctx context.Context, chains []*domain.ProfitableChain) error {
+	c.l().C(ctx).Mth("save-chains").Trc()
+	for _, chain := range chains {
+		stored := *chain
+		c.cache.Set(chain.Id, &stored, chainTtl(chain))
+	}
+	return nil
+}
+
+func (c *chainMemStorageImpl) GetProfitableChains(ctx context.Context, rq *domain.GetProfitableChainsRequest) (*domain.GetProfitableChainsResponse, error) {
+	c.l().C(ctx).Mth("get-chains").Trc()
+
+	var chains []*domain.ProfitableChain
+	for _, v := range c.cache.Items() {
+		chain := *v.(*domain.ProfitableChain)
+		if !matchChainRequest(rq, &chain) {
+			continue
+		}
+		if !rq.WithBids {
+			chain.Bids = nil
+		}
+		chains = append(chains, &chain)
+	}
+	return pageChains(ctx, rq, chains)
+}
+
+func (c *chainMemStorageImpl) GetProfitableChain(ctx context.Context, chainId string) (*domain.ProfitableChain, error) {
+	c.l().C(ctx).Mth("get-chain").F(log.FF{"chainId": chainId}).Trc()
+	v, ok := c.cache.Get(chainId)
+	if !ok {
+		return nil, nil
+	}
+	chain := *v.(*domain.ProfitableChain)
+	return &chain, nil
+}
+
+func (c *chainMemStorageImpl) ProfitableChainExists(ctx context.Context, chainId string) (bool, error) {
+	c.l().C(ctx).Mth("chain-exists").F(log.FF{"chainId": chainId}).Trc()
+	_, ok := c.cache.Get(chainId)
+	return ok, nil
+}
+
+func (c *chainMemStorageImpl) GetExpiringChains(ctx context.Context, from, to time.Time) ([]*domain.ProfitableChain, error) {
+	c.l().C(ctx).Mth("get-expiring-chains").Trc()
+	var chains []*domain.ProfitableChain
+	for _, v := range c.cache.Items() {
+		chain := *v.(*domain.ProfitableChain)
+		if chain.ExpiresAt.After(from) && !chain.ExpiresAt.After(to) {
+			chains = append(chains, &chain)
+		}
+	}
+	return chains, nil
+}
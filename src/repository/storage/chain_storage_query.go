@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"sort"
+	"strings"
+	"time"
+)
+
+// chainCursor keeps sort values of the last chain on the page
+// chains might expire between requests, so the cursor doesn't rely on the chain itself
+type chainCursor struct {
+	Id          string  `json:"id"`
+	ProfitShare float64 `json:"p"`
+	Score       float64 `json:"s"`
+	CreatedAt   int64   `json:"c"`
+	BaseProfit  float64 `json:"bp,omitempty"`
+	BaseVolume  float64 `json:"bv,omitempty"`
+}
+
+// defaultChainSort the latest chains go first
+var defaultChainSort = []*kit.SortRequest{{Field: domain.ChainSortFieldCreatedAt, Asc: false}}
+
+// matchChainRequest checks if chain satisfies request criteria
+func matchChainRequest(rq *domain.GetProfitableChainsRequest, chain *domain.ProfitableChain) bool {
+	return (len(rq.Assets) == 0 || kit.Strings(rq.Assets).Contains(chain.Asset)) &&
+		(len(rq.Methods) == 0 || kit.Strings(chain.Methods).Sanitize().Subset(kit.Strings(rq.Methods).Sanitize())) &&
+		(len(rq.ExchangeCodes) == 0 || kit.Strings(chain.ExchangeCodes).Subset(rq.ExchangeCodes)) &&
+		(len(rq.BidTypes) == 0 || kit.Strings(chain.BidTypes).Subset(rq.BidTypes)) &&
+		(rq.MinProfit == 0.0 || chain.ProfitShare >= 1+rq.MinProfit*0.01) &&
+		(rq.MaxProfit == 0.0 || chain.ProfitShare <= 1+rq.MaxProfit*0.01) &&
+		(rq.MinDepth == 0 || chain.Depth >= rq.MinDepth) &&
+		(rq.MaxDepth == 0 || chain.Depth <= rq.MaxDepth) &&
+		(rq.MinBaseProfit == 0.0 || chain.BaseProfit >= rq.MinBaseProfit) &&
+		(rq.MinBaseVolume == 0.0 || chain.BaseVolume >= rq.MinBaseVolume) &&
+		(rq.CreatedAfter == nil || chain.CreatedAt.After(*rq.CreatedAfter))
+}
+
+// chainTtl calculates ttl of the chain in the storage by its expiration
+func chainTtl(chain *domain.ProfitableChain) time.Duration {
+	if chain.ExpiresAt.IsZero() {
+		return chainDefaultTtl
+	}
+	ttl := chain.ExpiresAt.Sub(kit.Now())
+	// chain has already expired, but is kept for a while so the archiver has a chance to catch it
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return ttl
+}
+
+// compareChains compares chains by sort fields. Chain id is used as the last sort field, so order is stable
+func compareChains(sortBy []*kit.SortRequest, a, b *chainCursor) int {
+	for _, s := range sortBy {
+		r := 0
+		switch s.Field {
+		case domain.ChainSortFieldProfit:
+			r = compareFloats(a.ProfitShare, b.ProfitShare)
+		case domain.ChainSortFieldScore:
+			r = compareFloats(a.Score, b.Score)
+		case domain.ChainSortFieldBaseProfit:
+			r = compareFloats(a.BaseProfit, b.BaseProfit)
+		case domain.ChainSortFieldBaseVolume:
+			r = compareFloats(a.BaseVolume, b.BaseVolume)
+		case domain.ChainSortFieldCreatedAt:
+			if a.CreatedAt < b.CreatedAt {
+				r = -1
+			} else if a.CreatedAt > b.CreatedAt {
+				r = 1
+			}
+		}
+		if !s.Asc {
+			r = -r
+		}
+		if r != 0 {
+			return r
+		}
+	}
+	return strings.Compare(a.Id, b.Id)
+}
+
+func compareFloats(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func toChainCursor(chain *domain.ProfitableChain) *chainCursor {
+	return &chainCursor{
+		Id:          chain.Id,
+		ProfitShare: chain.ProfitShare,
+		Score:       chain.Score,
+		CreatedAt:   chain.CreatedAt.UnixNano(),
+		BaseProfit:  chain.BaseProfit,
+		BaseVolume:  chain.BaseVolume,
+	}
+}
+
+func encodeChainCursor(chain *domain.ProfitableChain) string {
+	b, _ := json.Marshal(toChainCursor(chain))
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeChainCursor(ctx context.Context, cursor string) (*chainCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.ErrChainCursorInvalid(ctx)
+	}
+	r := &chainCursor{}
+	if err := json.Unmarshal(b, r); err != nil || r.Id == "" {
+		return nil, errors.ErrChainCursorInvalid(ctx)
+	}
+	return r, nil
+}
+
+// pageChains sorts matched chains and takes the requested page
+// if cursor is specified, the page starts right after the cursor, otherwise page is taken by index
+// page size is defaulted and limited the same way as the arbitrage service does
+func pageChains(ctx context.Context, rq *domain.GetProfitableChainsRequest, chains []*domain.ProfitableChain) (*domain.GetProfitableChainsResponse, error) {
+
+	size := rq.Size
+	if size <= 0 {
+		size = domain.DefaultChainsPageSize
+	}
+	if size > domain.MaxChainsPageSize {
+		return nil, errors.ErrChainsPageSizeExceeded(ctx, domain.MaxChainsPageSize)
+	}
+
+	sortBy := rq.SortBy
+	if len(sortBy) == 0 {
+		sortBy = defaultChainSort
+	}
+	cursors := make(map[*domain.ProfitableChain]*chainCursor, len(chains))
+	for _, ch := range chains {
+		cursors[ch] = toChainCursor(ch)
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		return compareChains(sortBy, cursors[chains[i]], cursors[chains[j]]) < 0
+	})
+
+	// define the first chain on the page
+	start := 0
+	if rq.Cursor != "" {
+		cursor, err := decodeChainCursor(ctx, rq.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		start = sort.Search(len(chains), func(i int) bool {
+			return compareChains(sortBy, cursors[chains[i]], cursor) > 0
+		})
+	} else if rq.Index > 0 {
+		start = rq.Index * size
+	}
+	if start > len(chains) {
+		start = len(chains)
+	}
+	end := len(chains)
+	if start+size < end {
+		end = start + size
+	}
+
+	res := &domain.GetProfitableChainsResponse{
+		PagingResponse: kit.PagingResponse{Total: len(chains)},
+		Chains:         chains[start:end],
+	}
+	res.Index = start / size
+	if end < len(chains) && end > start {
+		res.NextCursor = encodeChainCursor(chains[end-1])
+	}
+	return res, nil
+}
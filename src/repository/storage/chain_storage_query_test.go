@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type chainQueryTestSuite struct {
+	kitTestSuite.Suite
+	storage domain.ChainStorage
+	chains  []*domain.ProfitableChain
+}
+
+func (s *chainQueryTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestChainQuerySuite(t *testing.T) {
+	suite.Run(t, new(chainQueryTestSuite))
+}
+
+func (s *chainQueryTestSuite) SetupTest() {
+	s.storage = NewChainMemStorage()
+	now := time.Now().UTC()
+	s.chains = nil
+	for i := 0; i < 10; i++ {
+		s.chains = append(s.chains, &domain.ProfitableChain{
+			Id:            kit.NewId(),
+			Asset:         "USDT",
+			ProfitShare:   1.0 + float64(i%5)*0.01,
+			Depth:         2 + i%3,
+			Methods:       []string{"Tinkoff"},
+			ExchangeCodes: []string{"binance"},
+			BidTypes:      []string{domain.BidTypeP2P},
+			Score:         float64(i),
+			BaseCurrency:  "USD",
+			BaseProfit:    float64(i) * 10,
+			BaseVolume:    float64(10-i) * 100,
+			CreatedAt:     now.Add(time.Duration(i) * time.Second),
+		})
+	}
+	s.chains[0].ExchangeCodes = []string{"binance", "huobi"}
+	s.chains[1].BidTypes = []string{domain.BidTypeP2P, domain.BidTypeManual}
+	s.chains[2].Methods = []string{"Sber"}
+	s.NoError(s.storage.SaveProfitableChains(s.Ctx, s.chains))
+}
+
+func (s *chainQueryTestSuite) ids(chains []*domain.ProfitableChain) []string {
+	var r []string
+	for _, ch := range chains {
+		r = append(r, ch.Id)
+	}
+	return r
+}
+
+func (s *chainQueryTestSuite) Test_Filters() {
+	createdAfter := s.chains[7].CreatedAt
+	tests := []struct {
+		rq  *domain.GetProfitableChainsRequest
+		ids []string
+	}{
+		{&domain.GetProfitableChainsRequest{ExchangeCodes: []string{"binance"}}, s.ids(s.chains[1:])},
+		{&domain.GetProfitableChainsRequest{BidTypes: []string{domain.BidTypeP2P}}, append(s.ids(s.chains[:1]), s.ids(s.chains[2:])...)},
+		{&domain.GetProfitableChainsRequest{Methods: []string{"sber"}}, s.ids(s.chains[2:3])},
+		{&domain.GetProfitableChainsRequest{MinProfit: 3, MaxProfit: 4}, s.ids([]*domain.ProfitableChain{s.chains[3], s.chains[4], s.chains[8], s.chains[9]})},
+		{&domain.GetProfitableChainsRequest{MinDepth: 4, MaxDepth: 4}, s.ids([]*domain.ProfitableChain{s.chains[2], s.chains[5], s.chains[8]})},
+		{&domain.GetProfitableChainsRequest{CreatedAfter: &createdAfter}, s.ids(s.chains[8:])},
+		{&domain.GetProfitableChainsRequest{MinBaseProfit: 75}, s.ids(s.chains[8:])},
+		{&domain.GetProfitableChainsRequest{MinBaseVolume: 900}, s.ids(s.chains[:2])},
+		{&domain.GetProfitableChainsRequest{Assets: []string{"RUB"}}, nil},
+	}
+	for _, tt := range tests {
+		rs, err := s.storage.GetProfitableChains(s.Ctx, tt.rq)
+		s.NoError(err)
+		s.ElementsMatch(tt.ids, s.ids(rs.Chains))
+		s.Equal(len(tt.ids), rs.Total)
+	}
+}
+
+func (s *chainQueryTestSuite) Test_Sort() {
+	// default sort by createdAt desc
+	rs, err := s.storage.GetProfitableChains(s.Ctx, &domain.GetProfitableChainsRequest{})
+	s.NoError(err)
+	s.Equal(s.chains[9].Id, rs.Chains[0].Id)
+	s.Equal(s.chains[0].Id, rs.Chains[9].Id)
+
+	// sort by score asc
+	rs, err = s.storage.GetProfitableChains(s.Ctx, &domain.GetProfitableChainsRequest{
+		PagingRequest: kit.PagingRequest{SortBy: []*kit.SortRequest{{Field: domain.ChainSortFieldScore, Asc: true}}},
+	})
+	s.NoError(err)
+	s.Equal(s.ids(s.chains), s.ids(rs.Chains))
+
+	// sort by profit desc, then createdAt asc
+	rs, err = s.storage.GetProfitableChains(s.Ctx, &domain.GetProfitableChainsRequest{
+		PagingRequest: kit.PagingRequest{SortBy: []*kit.SortRequest{
+			{Field: domain.ChainSortFieldProfit},
+			{Field: domain.ChainSortFieldCreatedAt, Asc: true},
+		}},
+	})
+	s.NoError(err)
+	s.Equal([]string{s.chains[4].Id, s.chains[9].Id, s.chains[3].Id, s.chains[8].Id}, s.ids(rs.Chains[:4]))
+
+	// sort by base volume asc
+	rs, err = s.storage.GetProfitableChains(s.Ctx, &domain.GetProfitableChainsRequest{
+		PagingRequest: kit.PagingRequest{SortBy: []*kit.SortRequest{{Field: domain.ChainSortFieldBaseVolume, Asc: true}}},
+	})
+	s.NoError(err)
+	s.Equal(s.chains[9].Id, rs.Chains[0].Id)
+	s.Equal(s.chains[0].Id, rs.Chains[9].Id)
+
+	// sort by base profit desc
+	rs, err = s.storage.GetProfitableChains(s.Ctx, &domain.GetProfitableChainsRequest{
+		PagingRequest: kit.PagingRequest{SortBy: []*kit.SortRequest{{Field: domain.ChainSortFieldBaseProfit}}},
+	})
+	s.NoError(err)
+	s.Equal(s.chains[9].Id, rs.Chains[0].Id)
+	s.Equal(s.chains[0].Id, rs.Chains[9].Id)
+}
+
+func (s *chainQueryTestSuite) Test_CursorPaging() {
+	rq := &domain.GetProfitableChainsRequest{
+		PagingRequest: kit.PagingRequest{Size: 4, SortBy: []*kit.SortRequest{{Field: domain.ChainSortFieldProfit}}},
+	}
+	var ids []string
+	pages := 0
+	for {
+		rs, err := s.storage.GetProfitableChains(s.Ctx, rq)
+		s.NoError(err)
+		s.Equal(len(s.chains), rs.Total)
+		s.Equal(pages, rs.Index)
+		ids = append(ids, s.ids(rs.Chains)...)
+		pages++
+		if rs.NextCursor == "" {
+			break
+		}
+		rq.Cursor = rs.NextCursor
+	}
+	s.Equal(3, pages)
+	s.ElementsMatch(s.ids(s.chains), ids)
+
+	// page by index gives the same result
+	rs, err := s.storage.GetProfitableChains(s.Ctx, &domain.GetProfitableChainsRequest{
+		PagingRequest: kit.PagingRequest{Size: 4, Index: 1, SortBy: rq.SortBy},
+	})
+	s.NoError(err)
+	s.Equal(ids[4:8], s.ids(rs.Chains))
+}
+
+func (s *chainQueryTestSuite) Test_CursorPaging_WhenChainOnCursorGone() {
+	rq := &domain.GetProfitableChainsRequest{PagingRequest: kit.PagingRequest{Size: 5}}
+	rs, err := s.storage.GetProfitableChains(s.Ctx, rq)
+	s.NoError(err)
+	s.NotEmpty(rs.NextCursor)
+	// the next page starts after the cursor even if the last chain is gone
+	storage := NewChainMemStorage()
+	s.NoError(storage.SaveProfitableChains(s.Ctx, s.chains[:4]))
+	rq.Cursor = rs.NextCursor
+	rs, err = storage.GetProfitableChains(s.Ctx, rq)
+	s.NoError(err)
+	s.Equal(s.ids([]*domain.ProfitableChain{s.chains[3], s.chains[2], s.chains[1], s.chains[0]}), s.ids(rs.Chains))
+	s.Empty(rs.NextCursor)
+}
+
+func (s *chainQueryTestSuite) Test_InvalidCursor() {
+	_, err := s.storage.GetProfitableChains(s.Ctx, &domain.GetProfitableChainsRequest{Cursor: "invalid"})
+	s.AssertAppErr(err, errors.ErrCodeChainCursorInvalid)
+}
+
+func (s *chainQueryTestSuite) Test_PageSize() {
+	storage := NewChainMemStorage()
+	now := time.Now().UTC()
+	var chains []*domain.ProfitableChain
+	for i := 0; i < domain.DefaultChainsPageSize+1; i++ {
+		chains = append(chains, &domain.ProfitableChain{Id: kit.NewId(), Asset: "USDT", CreatedAt: now.Add(time.Duration(i) * time.Millisecond)})
+	}
+	s.NoError(storage.SaveProfitableChains(s.Ctx, chains))
+
+	// default page size
+	rs, err := storage.GetProfitableChains(s.Ctx, &domain.GetProfitableChainsRequest{})
+	s.NoError(err)
+	s.Len(rs.Chains, domain.DefaultChainsPageSize)
+	s.Equal(domain.DefaultChainsPageSize+1, rs.Total)
+	s.NotEmpty(rs.NextCursor)
+	// the latest go first
+	s.Equal(chains[domain.DefaultChainsPageSize].Id, rs.Chains[0].Id)
+
+	// max page size exceeded
+	_, err = storage.GetProfitableChains(s.Ctx, &domain.GetProfitableChainsRequest{PagingRequest: kit.PagingRequest{Size: domain.MaxChainsPageSize + 1}})
+	s.AssertAppErr(err, errors.ErrCodeChainsPageSizeExceeded)
+}
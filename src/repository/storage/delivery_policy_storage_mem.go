@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sync"
+	"time"
+)
+
+// deliveryPolicyMemStorageImpl keeps state of delivery policies in memory, it's lost on restart and isn't shared by instances
+type deliveryPolicyMemStorageImpl struct {
+	sync.Mutex
+	sent    map[string][]time.Time           // sent times of messages by subscription
+	digests map[string]*domain.PendingDigest // pending digests by subscription
+}
+
+func (s *deliveryPolicyMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("delivery-policy-mem-storage")
+}
+
+func NewDeliveryPolicyMemStorage() domain.DeliveryPolicyStorage {
+	return &deliveryPolicyMemStorageImpl{
+		sent:    make(map[string][]time.Time),
+		digests: make(map[string]*domain.PendingDigest),
+	}
+}
+
+func (s *deliveryPolicyMemStorageImpl) ReserveMessages(ctx context.Context, subscriptionId string, count, maxMessages int, since, now time.Time) (int, error) {
+	s.l().C(ctx).Mth("reserve").F(log.FF{"subscriptionId": subscriptionId}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	var sent []time.Time
+	for _, t := range s.sent[subscriptionId] {
+		if t.After(since) {
+			sent = append(sent, t)
+		}
+	}
+	reserved := reservedMessages(count, maxMessages, len(sent))
+	for i := 0; i < reserved; i++ {
+		sent = append(sent, now)
+	}
+	s.sent[subscriptionId] = sent
+	return reserved, nil
+}
+
+func (s *deliveryPolicyMemStorageImpl) DeleteMessages(ctx context.Context, before time.Time) error {
+	s.l().C(ctx).Mth("delete-messages").Trc()
+	s.Lock()
+	defer s.Unlock()
+	for id, sent := range s.sent {
+		var kept []time.Time
+		for _, t := range sent {
+			if !t.Before(before) {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.sent, id)
+		} else {
+			s.sent[id] = kept
+		}
+	}
+	return nil
+}
+
+func (s *deliveryPolicyMemStorageImpl) AddDigestItems(ctx context.Context, subscriptionId string, items []*domain.DigestItem, maxItems int, now time.Time) error {
+	s.l().C(ctx).Mth("add-digest-items").F(log.FF{"subscriptionId": subscriptionId}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	digest, ok := s.digests[subscriptionId]
+	if !ok {
+		digest = &domain.PendingDigest{
+			Id:             kit.NewId(),
+			SubscriptionId: subscriptionId,
+			From:           now,
+		}
+		s.digests[subscriptionId] = digest
+	}
+	digest.Matched += len(items)
+	digest.Items = mergeDigestItems(digest.Items, items, maxItems)
+	return nil
+}
+
+func (s *deliveryPolicyMemStorageImpl) GetPendingDigests(ctx context.Context) ([]*domain.PendingDigest, error) {
+	s.l().C(ctx).Mth("get-digests").Trc()
+	s.Lock()
+	defer s.Unlock()
+	r := make([]*domain.PendingDigest, 0, len(s.digests))
+	for _, digest := range s.digests {
+		d := *digest
+		d.Items = append([]*domain.DigestItem(nil), digest.Items...)
+		r = append(r, &d)
+	}
+	return r, nil
+}
+
+func (s *deliveryPolicyMemStorageImpl) DeletePendingDigest(ctx context.Context, subscriptionId, digestId string) error {
+	s.l().C(ctx).Mth("delete-digest").F(log.FF{"subscriptionId": subscriptionId}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	if digest, ok := s.digests[subscriptionId]; ok && digest.Id == digestId {
+		delete(s.digests, subscriptionId)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"gorm.io/gorm"
+	"time"
+)
+
+type deliveryMessage struct {
+	Id             string    `gorm:"column:id"`
+	SubscriptionId string    `gorm:"column:subscription_id"`
+	SentAt         time.Time `gorm:"column:sent_at"`
+}
+
+func (deliveryMessage) TableName() string {
+	return "delivery_messages"
+}
+
+type pendingDigest struct {
+	Id             string    `gorm:"column:id"`
+	SubscriptionId string    `gorm:"column:subscription_id"`
+	Matched        int       `gorm:"column:matched"`
+	Items          string    `gorm:"column:items"`
+	StartedAt      time.Time `gorm:"column:started_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at"`
+}
+
+func (pendingDigest) TableName() string {
+	return "pending_digests"
+}
+
+// deliveryPolicyPgStorageImpl keeps state of delivery policies in postgres
+// changes of the same subscription are serialized with advisory locks, so instances don't exceed the limits together
+type deliveryPolicyPgStorageImpl struct {
+	pg *pg.Storage
+}
+
+func (s *deliveryPolicyPgStorageImpl) l() log.CLogger {
+	return service.L().Cmp("delivery-policy-pg-storage")
+}
+
+func newDeliveryPolicyPgStorage(pg *pg.Storage) *deliveryPolicyPgStorageImpl {
+	return &deliveryPolicyPgStorageImpl{
+		pg: pg,
+	}
+}
+
+// lock locks the key till the end of the transaction
+func (s *deliveryPolicyPgStorageImpl) lock(tx *gorm.DB, key string) error {
+	return tx.Exec("select pg_advisory_xact_lock(hashtext(?))", key).Error
+}
+
+func (s *deliveryPolicyPgStorageImpl) ReserveMessages(ctx context.Context, subscriptionId string, count, maxMessages int, since, now time.Time) (int, error) {
+	s.l().C(ctx).Mth("reserve").F(log.FF{"subscriptionId": subscriptionId}).Trc()
+	reserved := 0
+	err := s.pg.Instance.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.lock(tx, "delivery-messages:"+subscriptionId); err != nil {
+			return err
+		}
+		var sent int64
+		err := tx.Model(&deliveryMessage{}).
+			Where("subscription_id = ? and sent_at > ?", subscriptionId, since).
+			Count(&sent).Error
+		if err != nil {
+			return err
+		}
+		reserved = reservedMessages(count, maxMessages, int(sent))
+		if reserved == 0 {
+			return nil
+		}
+		dtos := make([]*deliveryMessage, reserved)
+		for i := range dtos {
+			dtos[i] = &deliveryMessage{Id: kit.NewId(), SubscriptionId: subscriptionId, SentAt: now}
+		}
+		return tx.Create(dtos).Error
+	})
+	if err != nil {
+		return 0, errors.ErrDeliveryPolicyStoragePut(err, ctx)
+	}
+	return reserved, nil
+}
+
+func (s *deliveryPolicyPgStorageImpl) DeleteMessages(ctx context.Context, before time.Time) error {
+	s.l().C(ctx).Mth("delete-messages").Trc()
+	if err := s.pg.Instance.WithContext(ctx).Where("sent_at < ?", before).Delete(&deliveryMessage{}).Error; err != nil {
+		return errors.ErrDeliveryPolicyStorageDel(err, ctx)
+	}
+	return nil
+}
+
+func (s *deliveryPolicyPgStorageImpl) AddDigestItems(ctx context.Context, subscriptionId string, items []*domain.DigestItem, maxItems int, now time.Time) error {
+	s.l().C(ctx).Mth("add-digest-items").F(log.FF{"subscriptionId": subscriptionId}).Trc()
+	err := s.pg.Instance.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.lock(tx, "pending-digests:"+subscriptionId); err != nil {
+			return err
+		}
+		var dtos []*pendingDigest
+		if err := tx.Where("subscription_id = ?", subscriptionId).Limit(1).Find(&dtos).Error; err != nil {
+			return err
+		}
+		if len(dtos) == 0 {
+			digest := &domain.PendingDigest{
+				Id:             kit.NewId(),
+				SubscriptionId: subscriptionId,
+				Items:          mergeDigestItems(nil, items, maxItems),
+				Matched:        len(items),
+				From:           now,
+			}
+			return tx.Create(s.toPendingDigestDto(digest, now)).Error
+		}
+		digest := s.toPendingDigestDomain(dtos[0])
+		digest.Matched += len(items)
+		digest.Items = mergeDigestItems(digest.Items, items, maxItems)
+		dto := s.toPendingDigestDto(digest, now)
+		return tx.Model(&pendingDigest{}).
+			Where("id = ?", digest.Id).
+			Updates(map[string]interface{}{
+				"matched":    dto.Matched,
+				"items":      dto.Items,
+				"updated_at": dto.UpdatedAt,
+			}).Error
+	})
+	if err != nil {
+		return errors.ErrDeliveryPolicyStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *deliveryPolicyPgStorageImpl) GetPendingDigests(ctx context.Context) ([]*domain.PendingDigest, error) {
+	s.l().C(ctx).Mth("get-digests").Trc()
+	var dtos []*pendingDigest
+	if err := s.pg.Instance.WithContext(ctx).Order("started_at").Find(&dtos).Error; err != nil {
+		return nil, errors.ErrDeliveryPolicyStorageGet(err, ctx)
+	}
+	return s.toPendingDigestsDomain(dtos), nil
+}
+
+func (s *deliveryPolicyPgStorageImpl) DeletePendingDigest(ctx context.Context, subscriptionId, digestId string) error {
+	s.l().C(ctx).Mth("delete-digest").F(log.FF{"subscriptionId": subscriptionId}).Trc()
+	err := s.pg.Instance.WithContext(ctx).
+		Where("subscription_id = ? and id = ?", subscriptionId, digestId).
+		Delete(&pendingDigest{}).Error
+	if err != nil {
+		return errors.ErrDeliveryPolicyStorageDel(err, ctx)
+	}
+	return nil
+}
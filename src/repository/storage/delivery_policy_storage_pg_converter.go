@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"time"
+)
+
+// digestItemData is an item of the pending digest stored as json
+type digestItemData struct {
+	OpportunityType string                  `json:"type"`
+	OpportunityId   string                  `json:"id"`
+	Profit          float64                 `json:"profit"`
+	Chain           *domain.ProfitableChain `json:"chain,omitempty"`
+	Spread          *domain.Spread          `json:"spread,omitempty"`
+}
+
+func (s *deliveryPolicyPgStorageImpl) toPendingDigestDto(d *domain.PendingDigest, now time.Time) *pendingDigest {
+	items := make([]*digestItemData, 0, len(d.Items))
+	for _, item := range d.Items {
+		items = append(items, &digestItemData{
+			OpportunityType: item.OpportunityType,
+			OpportunityId:   item.OpportunityId,
+			Profit:          item.Profit,
+			Chain:           item.Chain,
+			Spread:          item.Spread,
+		})
+	}
+	data, _ := json.Marshal(items)
+	return &pendingDigest{
+		Id:             d.Id,
+		SubscriptionId: d.SubscriptionId,
+		Matched:        d.Matched,
+		Items:          string(data),
+		StartedAt:      d.From,
+		UpdatedAt:      now,
+	}
+}
+
+func (s *deliveryPolicyPgStorageImpl) toPendingDigestDomain(dto *pendingDigest) *domain.PendingDigest {
+	var items []*digestItemData
+	_ = json.Unmarshal([]byte(dto.Items), &items)
+	r := &domain.PendingDigest{
+		Id:             dto.Id,
+		SubscriptionId: dto.SubscriptionId,
+		Matched:        dto.Matched,
+		From:           dto.StartedAt,
+	}
+	for _, item := range items {
+		r.Items = append(r.Items, &domain.DigestItem{
+			OpportunityType: item.OpportunityType,
+			OpportunityId:   item.OpportunityId,
+			Profit:          item.Profit,
+			Chain:           item.Chain,
+			Spread:          item.Spread,
+		})
+	}
+	return r
+}
+
+func (s *deliveryPolicyPgStorageImpl) toPendingDigestsDomain(dtos []*pendingDigest) []*domain.PendingDigest {
+	r := make([]*domain.PendingDigest, 0, len(dtos))
+	for _, dto := range dtos {
+		r = append(r, s.toPendingDigestDomain(dto))
+	}
+	return r
+}
@@ -0,0 +1,121 @@
+//go:build integration
+// +build integration
+
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"sync"
+	"testing"
+	"time"
+)
+
+type deliveryPolicyPgStorageTestSuite struct {
+	kitTestSuite.Suite
+	storage domain.DeliveryPolicyStorage
+	pg      *pg.Storage
+}
+
+func (s *deliveryPolicyPgStorageTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+
+	// load config
+	cfg, err := service.LoadConfig()
+	if err != nil {
+		s.Fatal(err)
+	}
+
+	// open postgres and apply migrations
+	s.pg, err = pg.Open(cfg.Storages.Pg.Master, service.LF())
+	if err != nil {
+		s.Fatal(err)
+	}
+	db, _ := s.pg.Instance.DB()
+	if err := pg.NewMigration(db, cfg.Storages.Pg.MigPath, service.LF()).Up(); err != nil {
+		s.Fatal(err)
+	}
+	s.storage = newDeliveryPolicyPgStorage(s.pg)
+}
+
+func (s *deliveryPolicyPgStorageTestSuite) TearDownSuite() {
+	s.pg.Close()
+}
+
+func TestDeliveryPolicyPgStorageSuite(t *testing.T) {
+	suite.Run(t, new(deliveryPolicyPgStorageTestSuite))
+}
+
+func (s *deliveryPolicyPgStorageTestSuite) pendingDigest(subscriptionId string) *domain.PendingDigest {
+	digests, err := s.storage.GetPendingDigests(s.Ctx)
+	s.NoError(err)
+	for _, d := range digests {
+		if d.SubscriptionId == subscriptionId {
+			return d
+		}
+	}
+	return nil
+}
+
+func (s *deliveryPolicyPgStorageTestSuite) Test_ReserveMessages_Concurrently() {
+	subscriptionId := kit.NewId()
+	now := kit.Now().Round(time.Millisecond)
+
+	// instances reserve messages concurrently, no more than max messages are reserved together
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	total := 0
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reserved, err := s.storage.ReserveMessages(s.Ctx, subscriptionId, 2, 3, now.Add(-time.Minute), now)
+			s.NoError(err)
+			mu.Lock()
+			total += reserved
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	s.Equal(3, total)
+
+	// the window is over
+	later := now.Add(2 * time.Minute)
+	reserved, err := s.storage.ReserveMessages(s.Ctx, subscriptionId, 1, 3, later.Add(-time.Minute), later)
+	s.NoError(err)
+	s.Equal(1, reserved)
+}
+
+func (s *deliveryPolicyPgStorageTestSuite) Test_Digests() {
+	subscriptionId := kit.NewId()
+	now := kit.Now().Round(time.Millisecond)
+
+	s.NoError(s.storage.AddDigestItems(s.Ctx, subscriptionId, []*domain.DigestItem{
+		{OpportunityType: domain.OpportunityTypeChain, OpportunityId: "1", Profit: 1.0, Chain: &domain.ProfitableChain{Id: "1", ProfitShare: 1.0}},
+		{OpportunityType: domain.OpportunityTypeChain, OpportunityId: "2", Profit: 3.0, Chain: &domain.ProfitableChain{Id: "2", ProfitShare: 3.0}},
+	}, 2, now))
+	s.NoError(s.storage.AddDigestItems(s.Ctx, subscriptionId, []*domain.DigestItem{
+		{OpportunityType: domain.OpportunityTypeSpread, OpportunityId: "3", Profit: 2.0, Spread: &domain.Spread{Id: "3", SpreadShare: 2.0}},
+	}, 2, now.Add(time.Minute)))
+
+	digest := s.pendingDigest(subscriptionId)
+	s.NotNil(digest)
+	s.Equal(3, digest.Matched)
+	s.True(now.Equal(digest.From))
+	s.Len(digest.Items, 2)
+	s.Equal("2", digest.Items[0].OpportunityId)
+	s.NotNil(digest.Items[0].Chain)
+	s.Equal("3", digest.Items[1].OpportunityId)
+	s.NotNil(digest.Items[1].Spread)
+
+	// other digest isn't deleted
+	s.NoError(s.storage.DeletePendingDigest(s.Ctx, subscriptionId, kit.NewId()))
+	s.NotNil(s.pendingDigest(subscriptionId))
+
+	s.NoError(s.storage.DeletePendingDigest(s.Ctx, subscriptionId, digest.Id))
+	s.Nil(s.pendingDigest(subscriptionId))
+}
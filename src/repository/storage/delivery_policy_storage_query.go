@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"sort"
+)
+
+// mergeDigestItems adds items to the digest items keeping the most profitable first, no more than maxItems
+func mergeDigestItems(items, added []*domain.DigestItem, maxItems int) []*domain.DigestItem {
+	r := make([]*domain.DigestItem, 0, len(items)+len(added))
+	r = append(append(r, items...), added...)
+	sort.SliceStable(r, func(i, j int) bool { return r[i].Profit > r[j].Profit })
+	if maxItems > 0 && len(r) > maxItems {
+		r = r[:maxItems]
+	}
+	return r
+}
+
+// reservedMessages number of messages which might be reserved when sent messages are already within the window
+func reservedMessages(count, maxMessages, sent int) int {
+	r := maxMessages - sent
+	if r > count {
+		r = count
+	}
+	if r < 0 {
+		r = 0
+	}
+	return r
+}
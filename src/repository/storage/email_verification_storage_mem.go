@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sort"
+	"sync"
+)
+
+type emailVerificationKey struct {
+	userId string
+	email  string
+}
+
+// emailVerificationMemStorageImpl keeps email verifications in memory
+type emailVerificationMemStorageImpl struct {
+	sync.Mutex
+	verifications map[emailVerificationKey]*domain.EmailVerification
+}
+
+func (s *emailVerificationMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("email-verification-mem-storage")
+}
+
+func NewEmailVerificationMemStorage() domain.EmailVerificationStorage {
+	return &emailVerificationMemStorageImpl{
+		verifications: make(map[emailVerificationKey]*domain.EmailVerification),
+	}
+}
+
+func (s *emailVerificationMemStorageImpl) SaveEmailVerification(ctx context.Context, v *domain.EmailVerification) error {
+	s.l().C(ctx).Mth("save").F(log.FF{"userId": v.UserId}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	stored := *v
+	s.verifications[emailVerificationKey{userId: v.UserId, email: v.Email}] = &stored
+	return nil
+}
+
+func (s *emailVerificationMemStorageImpl) GetEmailVerification(ctx context.Context, userId, email string) (*domain.EmailVerification, error) {
+	s.l().C(ctx).Mth("get").Trc()
+	s.Lock()
+	defer s.Unlock()
+	if v, ok := s.verifications[emailVerificationKey{userId: userId, email: email}]; ok {
+		r := *v
+		return &r, nil
+	}
+	return nil, nil
+}
+
+func (s *emailVerificationMemStorageImpl) GetEmailVerifications(ctx context.Context, userId string) ([]*domain.EmailVerification, error) {
+	s.l().C(ctx).Mth("get-by-user").Trc()
+	s.Lock()
+	defer s.Unlock()
+	var r []*domain.EmailVerification
+	for _, v := range s.verifications {
+		if v.UserId == userId {
+			c := *v
+			r = append(r, &c)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].CreatedAt.Before(r[j].CreatedAt) })
+	return r, nil
+}
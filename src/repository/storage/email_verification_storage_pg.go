@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type emailVerification struct {
+	UserId     string     `gorm:"column:user_id"`
+	Email      string     `gorm:"column:email"`
+	Code       *string    `gorm:"column:code"`
+	Status     string     `gorm:"column:status"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at"`
+	VerifiedAt *time.Time `gorm:"column:verified_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+}
+
+func (emailVerification) TableName() string {
+	return "email_verifications"
+}
+
+// emailVerificationPgStorageImpl keeps email verifications in postgres
+type emailVerificationPgStorageImpl struct {
+	pg *pg.Storage
+}
+
+func (s *emailVerificationPgStorageImpl) l() log.CLogger {
+	return service.L().Cmp("email-verification-pg-storage")
+}
+
+func newEmailVerificationPgStorage(pg *pg.Storage) *emailVerificationPgStorageImpl {
+	return &emailVerificationPgStorageImpl{
+		pg: pg,
+	}
+}
+
+func (s *emailVerificationPgStorageImpl) SaveEmailVerification(ctx context.Context, v *domain.EmailVerification) error {
+	s.l().C(ctx).Mth("save").F(log.FF{"userId": v.UserId}).Trc()
+	if err := s.pg.Instance.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(s.toVerificationDto(v)).Error; err != nil {
+		return errors.ErrEmailVerificationStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *emailVerificationPgStorageImpl) GetEmailVerification(ctx context.Context, userId, email string) (*domain.EmailVerification, error) {
+	s.l().C(ctx).Mth("get").Trc()
+	var dtos []*emailVerification
+	if err := s.pg.Instance.WithContext(ctx).Where("user_id = ? and email = ?", userId, email).Limit(1).Find(&dtos).Error; err != nil {
+		return nil, errors.ErrEmailVerificationStorageGet(err, ctx)
+	}
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	return s.toVerificationDomain(dtos[0]), nil
+}
+
+func (s *emailVerificationPgStorageImpl) GetEmailVerifications(ctx context.Context, userId string) ([]*domain.EmailVerification, error) {
+	s.l().C(ctx).Mth("get-by-user").Trc()
+	var dtos []*emailVerification
+	if err := s.pg.Instance.WithContext(ctx).Where("user_id = ?", userId).Order("created_at").Find(&dtos).Error; err != nil {
+		return nil, errors.ErrEmailVerificationStorageGet(err, ctx)
+	}
+	var r []*domain.EmailVerification
+	for _, dto := range dtos {
+		r = append(r, s.toVerificationDomain(dto))
+	}
+	return r, nil
+}
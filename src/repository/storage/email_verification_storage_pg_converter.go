@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+)
+
+func (s *emailVerificationPgStorageImpl) toVerificationDto(v *domain.EmailVerification) *emailVerification {
+	return &emailVerification{
+		UserId:     v.UserId,
+		Email:      v.Email,
+		Code:       pg.StringToNull(v.Code),
+		Status:     v.Status,
+		ExpiresAt:  v.ExpiresAt,
+		VerifiedAt: v.VerifiedAt,
+		CreatedAt:  v.CreatedAt,
+	}
+}
+
+func (s *emailVerificationPgStorageImpl) toVerificationDomain(dto *emailVerification) *domain.EmailVerification {
+	return &domain.EmailVerification{
+		UserId:     dto.UserId,
+		Email:      dto.Email,
+		Code:       pg.NullToString(dto.Code),
+		Status:     dto.Status,
+		ExpiresAt:  dto.ExpiresAt,
+		VerifiedAt: dto.VerifiedAt,
+		CreatedAt:  dto.CreatedAt,
+	}
+}
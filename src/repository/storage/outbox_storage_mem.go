@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sort"
+	"sync"
+	"time"
+)
+
+// outboxMemStorageImpl keeps notification outbox in memory
+type outboxMemStorageImpl struct {
+	sync.Mutex
+	deliveries map[string]*domain.OutboxDelivery
+	// opportunities ids of deliveries by opportunity of the notification
+	opportunities map[outboxOpportunityKey]string
+}
+
+type outboxOpportunityKey struct {
+	subscriptionId, notificationId, opportunityId string
+}
+
+func (s *outboxMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("outbox-mem-storage")
+}
+
+func NewOutboxMemStorage() domain.NotificationOutboxStorage {
+	return &outboxMemStorageImpl{
+		deliveries:    make(map[string]*domain.OutboxDelivery),
+		opportunities: make(map[outboxOpportunityKey]string),
+	}
+}
+
+func (s *outboxMemStorageImpl) AddDeliveries(ctx context.Context, deliveries []*domain.OutboxDelivery) error {
+	s.l().C(ctx).Mth("add").F(log.FF{"count": len(deliveries)}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	for _, d := range deliveries {
+		// deliveries without an opportunity aren't deduplicated
+		if d.OpportunityId != "" {
+			key := s.opportunityKey(d)
+			if _, ok := s.opportunities[key]; ok {
+				continue
+			}
+			s.opportunities[key] = d.Id
+		}
+		stored := *d
+		s.deliveries[d.Id] = &stored
+	}
+	return nil
+}
+
+func (s *outboxMemStorageImpl) opportunityKey(d *domain.OutboxDelivery) outboxOpportunityKey {
+	key := outboxOpportunityKey{subscriptionId: d.SubscriptionId, opportunityId: d.OpportunityId}
+	if d.Notification != nil {
+		key.notificationId = d.Notification.Id
+	}
+	return key
+}
+
+func (s *outboxMemStorageImpl) ClaimDeliveries(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]*domain.OutboxDelivery, error) {
+	s.l().C(ctx).Mth("claim").Trc()
+	s.Lock()
+	defer s.Unlock()
+	var due []*domain.OutboxDelivery
+	for _, d := range s.deliveries {
+		if (d.Status == domain.OutboxStatusPending || d.Status == domain.OutboxStatusSending) && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	r := make([]*domain.OutboxDelivery, 0, len(due))
+	for _, d := range due {
+		d.Status = domain.OutboxStatusSending
+		d.Attempts++
+		d.NextAttemptAt = leaseUntil
+		d.UpdatedAt = now
+		claimed := *d
+		r = append(r, &claimed)
+	}
+	return r, nil
+}
+
+func (s *outboxMemStorageImpl) UpdateDelivery(ctx context.Context, delivery *domain.OutboxDelivery) error {
+	s.l().C(ctx).Mth("update").F(log.FF{"deliveryId": delivery.Id}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	if d, ok := s.deliveries[delivery.Id]; ok {
+		d.Status = delivery.Status
+		d.Attempts = delivery.Attempts
+		d.NextAttemptAt = delivery.NextAttemptAt
+		d.LastError = delivery.LastError
+		d.DeliveredAt = delivery.DeliveredAt
+		d.UpdatedAt = delivery.UpdatedAt
+	}
+	return nil
+}
+
+func (s *outboxMemStorageImpl) GetDeliveries(ctx context.Context, ids []string) ([]*domain.OutboxDelivery, error) {
+	s.l().C(ctx).Mth("get").Trc()
+	s.Lock()
+	defer s.Unlock()
+	var r []*domain.OutboxDelivery
+	for _, id := range ids {
+		if d, ok := s.deliveries[id]; ok {
+			found := *d
+			r = append(r, &found)
+		}
+	}
+	return r, nil
+}
+
+func (s *outboxMemStorageImpl) SearchDeliveries(ctx context.Context, rq *domain.SearchOutboxRequest) (*domain.SearchOutboxResponse, error) {
+	s.l().C(ctx).Mth("search").Trc()
+	s.Lock()
+	defer s.Unlock()
+	var found []*domain.OutboxDelivery
+	for _, d := range s.deliveries {
+		if len(rq.Statuses) > 0 && !kit.Strings(rq.Statuses).Contains(d.Status) {
+			continue
+		}
+		if rq.Channel != "" && (d.Notification == nil || d.Notification.Channel != rq.Channel) {
+			continue
+		}
+		if rq.SubscriptionId != "" && d.SubscriptionId != rq.SubscriptionId {
+			continue
+		}
+		item := *d
+		found = append(found, &item)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].CreatedAt.Equal(found[j].CreatedAt) {
+			return found[i].Id < found[j].Id
+		}
+		return found[i].CreatedAt.After(found[j].CreatedAt)
+	})
+	r := &domain.SearchOutboxResponse{
+		PagingResponse: kit.PagingResponse{Total: len(found), Index: rq.Index},
+		Deliveries:     []*domain.OutboxDelivery{},
+	}
+	from := rq.Index * rq.Size
+	if from < len(found) {
+		to := from + rq.Size
+		if rq.Size <= 0 || to > len(found) {
+			to = len(found)
+		}
+		r.Deliveries = found[from:to]
+	}
+	return r, nil
+}
+
+func (s *outboxMemStorageImpl) DeleteDeliveries(ctx context.Context, status string, before time.Time) error {
+	s.l().C(ctx).Mth("delete").F(log.FF{"status": status}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	for id, d := range s.deliveries {
+		if d.Status == status && d.UpdatedAt.Before(before) {
+			delete(s.deliveries, id)
+			if s.opportunities[s.opportunityKey(d)] == id {
+				delete(s.opportunities, s.opportunityKey(d))
+			}
+		}
+	}
+	return nil
+}
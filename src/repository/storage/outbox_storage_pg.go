@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type outboxDelivery struct {
+	Id              string     `gorm:"column:id"`
+	SubscriptionId  string     `gorm:"column:subscription_id"`
+	UserId          *string    `gorm:"column:user_id"`
+	NotificationId  string     `gorm:"column:notification_id"`
+	Channel         string     `gorm:"column:channel"`
+	OpportunityType string     `gorm:"column:opportunity_type"`
+	OpportunityId   string     `gorm:"column:opportunity_id"`
+	Status          string     `gorm:"column:status"`
+	Attempts        int        `gorm:"column:attempts"`
+	NextAttemptAt   time.Time  `gorm:"column:next_attempt_at"`
+	LastError       *string    `gorm:"column:last_error"`
+	DeliveredAt     *time.Time `gorm:"column:delivered_at"`
+	Data            string     `gorm:"column:data"`
+	CreatedAt       time.Time  `gorm:"column:created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at"`
+}
+
+func (outboxDelivery) TableName() string {
+	return "notification_outbox"
+}
+
+// outboxPgStorageImpl keeps notification outbox in postgres
+type outboxPgStorageImpl struct {
+	pg *pg.Storage
+}
+
+func (s *outboxPgStorageImpl) l() log.CLogger {
+	return service.L().Cmp("outbox-pg-storage")
+}
+
+func newOutboxPgStorage(pg *pg.Storage) *outboxPgStorageImpl {
+	return &outboxPgStorageImpl{
+		pg: pg,
+	}
+}
+
+func (s *outboxPgStorageImpl) AddDeliveries(ctx context.Context, deliveries []*domain.OutboxDelivery) error {
+	s.l().C(ctx).Mth("add").F(log.FF{"count": len(deliveries)}).Trc()
+	if len(deliveries) == 0 {
+		return nil
+	}
+	// the same opportunity might be enqueued twice (e.g. by a retried worker), it's delivered once
+	err := s.pg.Instance.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(s.toOutboxDeliveriesDto(deliveries), 500).Error
+	if err != nil {
+		return errors.ErrOutboxStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *outboxPgStorageImpl) ClaimDeliveries(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]*domain.OutboxDelivery, error) {
+	s.l().C(ctx).Mth("claim").Trc()
+	var dtos []*outboxDelivery
+	err := s.pg.Instance.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// concurrent workers skip rows locked by each other
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status in ? and next_attempt_at <= ?", []string{domain.OutboxStatusPending, domain.OutboxStatusSending}, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&dtos).Error
+		if err != nil || len(dtos) == 0 {
+			return err
+		}
+		ids := make([]string, len(dtos))
+		for i, dto := range dtos {
+			ids[i] = dto.Id
+			dto.Status = domain.OutboxStatusSending
+			dto.Attempts++
+			dto.NextAttemptAt = leaseUntil
+			dto.UpdatedAt = now
+		}
+		return tx.Model(&outboxDelivery{}).
+			Where("id in ?", ids).
+			Updates(map[string]interface{}{
+				"status":          domain.OutboxStatusSending,
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": leaseUntil,
+				"updated_at":      now,
+			}).Error
+	})
+	if err != nil {
+		return nil, errors.ErrOutboxStorageClaim(err, ctx)
+	}
+	return s.toOutboxDeliveriesDomain(dtos), nil
+}
+
+func (s *outboxPgStorageImpl) UpdateDelivery(ctx context.Context, delivery *domain.OutboxDelivery) error {
+	s.l().C(ctx).Mth("update").F(log.FF{"deliveryId": delivery.Id}).Trc()
+	err := s.pg.Instance.WithContext(ctx).Model(&outboxDelivery{}).
+		Where("id = ?", delivery.Id).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_error":      pg.StringToNull(delivery.LastError),
+			"delivered_at":    delivery.DeliveredAt,
+			"updated_at":      delivery.UpdatedAt,
+		}).Error
+	if err != nil {
+		return errors.ErrOutboxStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *outboxPgStorageImpl) GetDeliveries(ctx context.Context, ids []string) ([]*domain.OutboxDelivery, error) {
+	s.l().C(ctx).Mth("get").Trc()
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var dtos []*outboxDelivery
+	if err := s.pg.Instance.WithContext(ctx).Where("id in ?", ids).Find(&dtos).Error; err != nil {
+		return nil, errors.ErrOutboxStorageGet(err, ctx)
+	}
+	return s.toOutboxDeliveriesDomain(dtos), nil
+}
+
+func (s *outboxPgStorageImpl) SearchDeliveries(ctx context.Context, rq *domain.SearchOutboxRequest) (*domain.SearchOutboxResponse, error) {
+	s.l().C(ctx).Mth("search").Trc()
+	q := s.pg.Instance.WithContext(ctx).Model(&outboxDelivery{})
+	if len(rq.Statuses) > 0 {
+		q = q.Where("status in ?", rq.Statuses)
+	}
+	if rq.Channel != "" {
+		q = q.Where("channel = ?", rq.Channel)
+	}
+	if rq.SubscriptionId != "" {
+		q = q.Where("subscription_id = ?", rq.SubscriptionId)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, errors.ErrOutboxStorageSearch(err, ctx)
+	}
+	var dtos []*outboxDelivery
+	if err := q.Order("created_at desc, id").Offset(rq.Index * rq.Size).Limit(rq.Size).Find(&dtos).Error; err != nil {
+		return nil, errors.ErrOutboxStorageSearch(err, ctx)
+	}
+	return &domain.SearchOutboxResponse{
+		PagingResponse: kit.PagingResponse{Total: int(total), Index: rq.Index},
+		Deliveries:     s.toOutboxDeliveriesDomain(dtos),
+	}, nil
+}
+
+func (s *outboxPgStorageImpl) DeleteDeliveries(ctx context.Context, status string, before time.Time) error {
+	s.l().C(ctx).Mth("delete").F(log.FF{"status": status}).Trc()
+	err := s.pg.Instance.WithContext(ctx).
+		Where("status = ? and updated_at < ?", status, before).
+		Delete(&outboxDelivery{}).Error
+	if err != nil {
+		return errors.ErrOutboxStorageDel(err, ctx)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+)
+
+// outboxDeliveryData is a payload of the delivery stored as json
+type outboxDeliveryData struct {
+	Notification *domain.SubscriptionNotification `json:"notification,omitempty"`
+	Chain        *domain.ProfitableChain          `json:"chain,omitempty"`
+	Spread       *domain.Spread                   `json:"spread,omitempty"`
+	Digest       *domain.NotificationDigest       `json:"digest,omitempty"`
+}
+
+func (s *outboxPgStorageImpl) toOutboxDeliveryDto(d *domain.OutboxDelivery) *outboxDelivery {
+	data, _ := json.Marshal(&outboxDeliveryData{
+		Notification: d.Notification,
+		Chain:        d.Chain,
+		Spread:       d.Spread,
+		Digest:       d.Digest,
+	})
+	r := &outboxDelivery{
+		Id:              d.Id,
+		SubscriptionId:  d.SubscriptionId,
+		UserId:          pg.StringToNull(d.UserId),
+		OpportunityType: d.OpportunityType,
+		OpportunityId:   d.OpportunityId,
+		Status:          d.Status,
+		Attempts:        d.Attempts,
+		NextAttemptAt:   d.NextAttemptAt,
+		LastError:       pg.StringToNull(d.LastError),
+		DeliveredAt:     d.DeliveredAt,
+		Data:            string(data),
+		CreatedAt:       d.CreatedAt,
+		UpdatedAt:       d.UpdatedAt,
+	}
+	if d.Notification != nil {
+		r.NotificationId = d.Notification.Id
+		r.Channel = d.Notification.Channel
+	}
+	return r
+}
+
+func (s *outboxPgStorageImpl) toOutboxDeliveriesDto(deliveries []*domain.OutboxDelivery) []*outboxDelivery {
+	r := make([]*outboxDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		r = append(r, s.toOutboxDeliveryDto(d))
+	}
+	return r
+}
+
+func (s *outboxPgStorageImpl) toOutboxDeliveryDomain(dto *outboxDelivery) *domain.OutboxDelivery {
+	data := &outboxDeliveryData{}
+	_ = json.Unmarshal([]byte(dto.Data), data)
+	return &domain.OutboxDelivery{
+		Id:              dto.Id,
+		SubscriptionId:  dto.SubscriptionId,
+		UserId:          pg.NullToString(dto.UserId),
+		Notification:    data.Notification,
+		OpportunityType: dto.OpportunityType,
+		OpportunityId:   dto.OpportunityId,
+		Chain:           data.Chain,
+		Spread:          data.Spread,
+		Digest:          data.Digest,
+		Status:          dto.Status,
+		Attempts:        dto.Attempts,
+		NextAttemptAt:   dto.NextAttemptAt,
+		LastError:       pg.NullToString(dto.LastError),
+		DeliveredAt:     dto.DeliveredAt,
+		CreatedAt:       dto.CreatedAt,
+		UpdatedAt:       dto.UpdatedAt,
+	}
+}
+
+func (s *outboxPgStorageImpl) toOutboxDeliveriesDomain(dtos []*outboxDelivery) []*domain.OutboxDelivery {
+	r := make([]*domain.OutboxDelivery, 0, len(dtos))
+	for _, dto := range dtos {
+		r = append(r, s.toOutboxDeliveryDomain(dto))
+	}
+	return r
+}
@@ -0,0 +1,126 @@
+//go:build integration
+// +build integration
+
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type outboxPgStorageTestSuite struct {
+	kitTestSuite.Suite
+	storage domain.NotificationOutboxStorage
+	pg      *pg.Storage
+}
+
+func (s *outboxPgStorageTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+
+	// load config
+	cfg, err := service.LoadConfig()
+	if err != nil {
+		s.Fatal(err)
+	}
+
+	// open postgres and apply migrations
+	s.pg, err = pg.Open(cfg.Storages.Pg.Master, service.LF())
+	if err != nil {
+		s.Fatal(err)
+	}
+	db, _ := s.pg.Instance.DB()
+	if err := pg.NewMigration(db, cfg.Storages.Pg.MigPath, service.LF()).Up(); err != nil {
+		s.Fatal(err)
+	}
+	s.storage = newOutboxPgStorage(s.pg)
+}
+
+func (s *outboxPgStorageTestSuite) TearDownSuite() {
+	s.pg.Close()
+}
+
+func TestOutboxPgStorageSuite(t *testing.T) {
+	suite.Run(t, new(outboxPgStorageTestSuite))
+}
+
+func (s *outboxPgStorageTestSuite) Test_AddClaimUpdate() {
+	now := kit.Now().Round(time.Millisecond)
+	subscriptionId := kit.NewId()
+	d := &domain.OutboxDelivery{
+		Id:             kit.NewId(),
+		SubscriptionId: subscriptionId,
+		UserId:         kit.NewId(),
+		Notification: &domain.SubscriptionNotification{
+			Id:      "n",
+			Channel: domain.SubscriptionNotificationChannelWebhook,
+			Webhook: &domain.SubscriptionWebhookNotificationDetails{Url: "https://example.com", Secret: "0123456789abcdef"},
+		},
+		OpportunityType: domain.OpportunityTypeChain,
+		OpportunityId:   "chain-id",
+		Chain:           &domain.ProfitableChain{Id: "chain-id", Asset: "RUB"},
+		Status:          domain.OutboxStatusPending,
+		NextAttemptAt:   now.Add(-time.Hour),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	s.NoError(s.storage.AddDeliveries(s.Ctx, []*domain.OutboxDelivery{d}))
+
+	claimed, err := s.storage.ClaimDeliveries(s.Ctx, now, 1000, now.Add(time.Minute))
+	s.NoError(err)
+	var found *domain.OutboxDelivery
+	for _, c := range claimed {
+		if c.Id == d.Id {
+			found = c
+		}
+	}
+	s.NotEmpty(found)
+	s.Equal(domain.OutboxStatusSending, found.Status)
+	s.Equal(1, found.Attempts)
+	s.Equal("chain-id", found.Chain.Id)
+	s.Equal(d.Notification.Webhook.Url, found.Notification.Webhook.Url)
+
+	found.Status = domain.OutboxStatusDead
+	found.LastError = "failed"
+	s.NoError(s.storage.UpdateDelivery(s.Ctx, found))
+
+	rs, err := s.storage.SearchDeliveries(s.Ctx, &domain.SearchOutboxRequest{
+		PagingRequest:  kit.PagingRequest{Size: 10},
+		Statuses:       []string{domain.OutboxStatusDead},
+		SubscriptionId: subscriptionId,
+	})
+	s.NoError(err)
+	s.Equal(1, rs.Total)
+	s.Equal("failed", rs.Deliveries[0].LastError)
+}
+
+func (s *outboxPgStorageTestSuite) Test_SameOpportunityAddedOnce() {
+	now := kit.Now().Round(time.Millisecond)
+	subscriptionId := kit.NewId()
+	delivery := func() *domain.OutboxDelivery {
+		return &domain.OutboxDelivery{
+			Id:              kit.NewId(),
+			SubscriptionId:  subscriptionId,
+			Notification:    &domain.SubscriptionNotification{Id: "n", Channel: domain.SubscriptionNotificationChannelWebhook},
+			OpportunityType: domain.OpportunityTypeChain,
+			OpportunityId:   "chain-id",
+			Status:          domain.OutboxStatusPending,
+			NextAttemptAt:   now,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+	}
+	first, duplicate := delivery(), delivery()
+	s.NoError(s.storage.AddDeliveries(s.Ctx, []*domain.OutboxDelivery{first}))
+	s.NoError(s.storage.AddDeliveries(s.Ctx, []*domain.OutboxDelivery{duplicate}))
+
+	found, err := s.storage.GetDeliveries(s.Ctx, []string{first.Id, duplicate.Id})
+	s.NoError(err)
+	s.Len(found, 1)
+	s.Equal(first.Id, found[0].Id)
+}
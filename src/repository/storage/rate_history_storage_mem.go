@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sort"
+	"sync"
+	"time"
+)
+
+type ratePointKey struct {
+	src, trg, exchange, method string
+	time                       int64
+}
+
+// rateHistoryMemStorageImpl keeps rate history in memory
+type rateHistoryMemStorageImpl struct {
+	sync.RWMutex
+	points map[ratePointKey]*domain.RatePoint
+}
+
+func (s *rateHistoryMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("rate-history-mem-storage")
+}
+
+func NewRateHistoryMemStorage() domain.RateHistoryStorage {
+	return &rateHistoryMemStorageImpl{
+		points: make(map[ratePointKey]*domain.RatePoint),
+	}
+}
+
+func (s *rateHistoryMemStorageImpl) SaveRatePoints(ctx context.Context, points []*domain.RatePoint) error {
+	s.l().C(ctx).Mth("save").F(log.FF{"count": len(points)}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	for _, p := range points {
+		stored := *p
+		s.points[ratePointKey{p.SrcAsset, p.TrgAsset, p.ExchangeCode, p.Method, p.Time.UnixNano()}] = &stored
+	}
+	return nil
+}
+
+func (s *rateHistoryMemStorageImpl) GetRatePoints(ctx context.Context, rq *domain.GetRatePointsRequest) ([]*domain.RatePoint, error) {
+	s.l().C(ctx).Mth("get").Trc()
+	s.RLock()
+	defer s.RUnlock()
+	var r []*domain.RatePoint
+	for _, p := range s.points {
+		if p.SrcAsset != rq.SrcAsset || p.TrgAsset != rq.TrgAsset ||
+			(rq.ExchangeCode != "" && p.ExchangeCode != rq.ExchangeCode) ||
+			(rq.Method != "" && p.Method != rq.Method) ||
+			p.Time.Before(rq.From) || !p.Time.Before(rq.To) {
+			continue
+		}
+		point := *p
+		r = append(r, &point)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Time.Before(r[j].Time) })
+	return r, nil
+}
+
+func (s *rateHistoryMemStorageImpl) DeleteRatePoints(ctx context.Context, before time.Time) error {
+	s.l().C(ctx).Mth("delete").Trc()
+	s.Lock()
+	defer s.Unlock()
+	for k, p := range s.points {
+		if p.Time.Before(before) {
+			delete(s.points, k)
+		}
+	}
+	return nil
+}
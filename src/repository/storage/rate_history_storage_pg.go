@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+const (
+	// ratePointsBatchSize max number of points inserted by one statement
+	ratePointsBatchSize = 1000
+)
+
+type ratePoint struct {
+	SrcAsset     string    `gorm:"column:src_asset"`
+	TrgAsset     string    `gorm:"column:trg_asset"`
+	ExchangeCode string    `gorm:"column:exchange_code"`
+	Method       string    `gorm:"column:method"`
+	PointTime    time.Time `gorm:"column:point_time"`
+	BestRate     float64   `gorm:"column:best_rate"`
+	MedianRate   float64   `gorm:"column:median_rate"`
+	Volume       float64   `gorm:"column:volume"`
+	Bids         int       `gorm:"column:bids"`
+}
+
+func (ratePoint) TableName() string {
+	return "rate_history"
+}
+
+// rateHistoryPgStorageImpl keeps rate history in postgres
+type rateHistoryPgStorageImpl struct {
+	pg *pg.Storage
+}
+
+func (s *rateHistoryPgStorageImpl) l() log.CLogger {
+	return service.L().Cmp("rate-history-pg-storage")
+}
+
+func newRateHistoryPgStorage(pg *pg.Storage) *rateHistoryPgStorageImpl {
+	return &rateHistoryPgStorageImpl{
+		pg: pg,
+	}
+}
+
+func (s *rateHistoryPgStorageImpl) SaveRatePoints(ctx context.Context, points []*domain.RatePoint) error {
+	s.l().C(ctx).Mth("save").F(log.FF{"count": len(points)}).Trc()
+	if len(points) == 0 {
+		return nil
+	}
+	dtos := s.toRatePointsDto(points)
+	err := s.pg.Instance.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "src_asset"}, {Name: "trg_asset"}, {Name: "point_time"}, {Name: "exchange_code"}, {Name: "method"}},
+			DoUpdates: clause.AssignmentColumns([]string{"best_rate", "median_rate", "volume", "bids"}),
+		}).
+		CreateInBatches(dtos, ratePointsBatchSize).Error
+	if err != nil {
+		return errors.ErrRateHistoryStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *rateHistoryPgStorageImpl) GetRatePoints(ctx context.Context, rq *domain.GetRatePointsRequest) ([]*domain.RatePoint, error) {
+	s.l().C(ctx).Mth("get").Trc()
+	q := s.pg.Instance.WithContext(ctx).
+		Where("src_asset = ? and trg_asset = ?", rq.SrcAsset, rq.TrgAsset).
+		Where("point_time >= ? and point_time < ?", rq.From, rq.To)
+	if rq.ExchangeCode != "" {
+		q = q.Where("exchange_code = ?", rq.ExchangeCode)
+	}
+	if rq.Method != "" {
+		q = q.Where("method = ?", rq.Method)
+	}
+	var dtos []*ratePoint
+	if err := q.Order("point_time").Find(&dtos).Error; err != nil {
+		return nil, errors.ErrRateHistoryStorageGet(err, ctx)
+	}
+	return s.toRatePointsDomain(dtos), nil
+}
+
+func (s *rateHistoryPgStorageImpl) DeleteRatePoints(ctx context.Context, before time.Time) error {
+	s.l().C(ctx).Mth("delete").Trc()
+	if err := s.pg.Instance.WithContext(ctx).Where("point_time < ?", before).Delete(&ratePoint{}).Error; err != nil {
+		return errors.ErrRateHistoryStorageDel(err, ctx)
+	}
+	return nil
+}
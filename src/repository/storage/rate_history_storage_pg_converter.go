@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+)
+
+func (s *rateHistoryPgStorageImpl) toRatePointsDto(points []*domain.RatePoint) []*ratePoint {
+	r := make([]*ratePoint, len(points))
+	for i, p := range points {
+		r[i] = &ratePoint{
+			SrcAsset:     p.SrcAsset,
+			TrgAsset:     p.TrgAsset,
+			ExchangeCode: p.ExchangeCode,
+			Method:       p.Method,
+			PointTime:    p.Time,
+			BestRate:     p.BestRate,
+			MedianRate:   p.MedianRate,
+			Volume:       p.Volume,
+			Bids:         p.Bids,
+		}
+	}
+	return r
+}
+
+func (s *rateHistoryPgStorageImpl) toRatePointsDomain(dtos []*ratePoint) []*domain.RatePoint {
+	r := make([]*domain.RatePoint, len(dtos))
+	for i, d := range dtos {
+		r[i] = &domain.RatePoint{
+			SrcAsset:     d.SrcAsset,
+			TrgAsset:     d.TrgAsset,
+			ExchangeCode: d.ExchangeCode,
+			Method:       d.Method,
+			Time:         d.PointTime,
+			BestRate:     d.BestRate,
+			MedianRate:   d.MedianRate,
+			Volume:       d.Volume,
+			Bids:         d.Bids,
+		}
+	}
+	return r
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	aero "github.com/aerospike/aerospike-client-go/v6"
+	"github.com/aerospike/aerospike-client-go/v6/types"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	kitAero "github.com/mikhailbolshakov/cryptocare/src/kit/storages/aerospike"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+)
+
+const (
+	SetSpreads = "spreads"
+)
+
+type spreadStorageImpl struct {
+	aero kitAero.Aerospike
+	cfg  *kitAero.Config
+}
+
+func (c *spreadStorageImpl) l() log.CLogger {
+	return service.L().Cmp("spread-storage")
+}
+
+func newSpreadStorage(aero kitAero.Aerospike, cfg *kitAero.Config) *spreadStorageImpl {
+	return &spreadStorageImpl{
+		aero: aero,
+		cfg:  cfg,
+	}
+}
+
+func (c *spreadStorageImpl) SaveSpreads(ctx context.Context, spreads []*domain.Spread) error {
+	c.l().C(ctx).Mth("save-spreads").Trc()
+	for _, spread := range spreads {
+		key, err := aero.NewKey(c.cfg.Namespace, SetSpreads, spread.Id)
+		if err != nil {
+			return errors.ErrSpreadStoragePut(err, ctx)
+		}
+		writePolicy := aero.NewWritePolicy(0, uint32(spreadTtl(spread).Seconds()))
+		writePolicy.SendKey = true
+		err = c.aero.Instance().Put(writePolicy, key, c.toSpreadAero(spread))
+		if err != nil {
+			return errors.ErrSpreadStoragePut(err, ctx)
+		}
+	}
+	return nil
+}
+
+func (c *spreadStorageImpl) GetSpreads(ctx context.Context, rq *domain.GetSpreadsRequest) (*domain.GetSpreadsResponse, error) {
+	c.l().C(ctx).Mth("get-spreads").Trc()
+
+	// spread is filtered on the server side, list criteria are checked after
+	queryPolicy := aero.NewQueryPolicy()
+	queryPolicy.SendKey = true
+	if rq.MinSpread != 0.0 {
+		queryPolicy.FilterExpression = aero.ExpGreaterEq(aero.ExpFloatBin("spread_share"), aero.ExpFloatVal(1+rq.MinSpread*0.01))
+	}
+
+	recordSet, aeroErr := c.aero.Instance().Query(queryPolicy, aero.NewStatement(c.cfg.Namespace, SetSpreads))
+	if aeroErr != nil {
+		return nil, errors.ErrSpreadStorageScan(aeroErr, ctx)
+	}
+	var spreads []*domain.Spread
+	for r := range recordSet.Results() {
+		if r.Err != nil {
+			return nil, errors.ErrSpreadStorageScan(r.Err, ctx)
+		}
+		spread, err := c.toSpreadDomain(ctx, r.Record)
+		if err != nil {
+			return nil, err
+		}
+		if matchSpreadRequest(rq, spread) {
+			spreads = append(spreads, spread)
+		}
+	}
+	return pageSpreads(rq, spreads), nil
+}
+
+func (c *spreadStorageImpl) GetSpread(ctx context.Context, spreadId string) (*domain.Spread, error) {
+	c.l().C(ctx).Mth("get-spread").F(log.FF{"spreadId": spreadId}).Trc()
+
+	key, aeroErr := aero.NewKey(c.cfg.Namespace, SetSpreads, spreadId)
+	if aeroErr != nil {
+		return nil, errors.ErrSpreadStorageGet(aeroErr, ctx)
+	}
+
+	policy := aero.NewPolicy()
+	policy.SendKey = true
+	rec, aeroErr := c.aero.Instance().Get(policy, key)
+	if aeroErr != nil && !aeroErr.Matches(types.KEY_NOT_FOUND_ERROR) {
+		return nil, errors.ErrSpreadStorageGet(aeroErr, ctx)
+	}
+	return c.toSpreadDomain(ctx, rec)
+}
+
+func (c *spreadStorageImpl) SpreadExists(ctx context.Context, spreadId string) (bool, error) {
+	c.l().C(ctx).Mth("spread-exists").F(log.FF{"spreadId": spreadId}).Trc()
+
+	key, aeroErr := aero.NewKey(c.cfg.Namespace, SetSpreads, spreadId)
+	if aeroErr != nil {
+		return false, errors.ErrSpreadStorageGet(aeroErr, ctx)
+	}
+
+	policy := aero.NewPolicy()
+	policy.SendKey = true
+	rec, aeroErr := c.aero.Instance().GetHeader(policy, key)
+	if aeroErr != nil {
+		if aeroErr.Matches(types.KEY_NOT_FOUND_ERROR) {
+			return false, nil
+		}
+		return false, errors.ErrSpreadStorageGet(aeroErr, ctx)
+	}
+	return rec != nil, nil
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	aero "github.com/aerospike/aerospike-client-go/v6"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/aerospike"
+	"time"
+)
+
+func (c *spreadStorageImpl) toSpreadAero(spread *domain.Spread) aero.BinMap {
+	buy, _ := json.Marshal(spread.Buy)
+	sell, _ := json.Marshal(spread.Sell)
+	r := aero.BinMap{
+		"type":           spread.Type,
+		"base_asset":     spread.BaseAsset,
+		"quote_asset":    spread.QuoteAsset,
+		"buy":            buy,
+		"sell":           sell,
+		"buy_price":      spread.BuyPrice,
+		"sell_price":     spread.SellPrice,
+		"spread_share":   spread.SpreadShare,
+		"volume":         spread.Volume,
+		"profit":         spread.Profit,
+		"exchange_codes": spread.ExchangeCodes,
+		"methods":        spread.Methods,
+		"created_at":     spread.CreatedAt.UnixNano(),
+	}
+	if !spread.ExpiresAt.IsZero() {
+		r["expires_at"] = spread.ExpiresAt.UnixNano()
+	}
+	if !spread.ObservedAt.IsZero() {
+		r["observed_at"] = spread.ObservedAt.UnixNano()
+	}
+	return r
+}
+
+func (c *spreadStorageImpl) toSpreadDomain(ctx context.Context, rec *aero.Record) (*domain.Spread, error) {
+	if rec == nil {
+		return nil, nil
+	}
+	var err error
+	r := &domain.Spread{
+		Id: rec.Key.Value().String(),
+	}
+	r.Type, err = aerospike.AsString(ctx, rec.Bins, "type")
+	if err != nil {
+		return nil, err
+	}
+	r.BaseAsset, err = aerospike.AsString(ctx, rec.Bins, "base_asset")
+	if err != nil {
+		return nil, err
+	}
+	r.QuoteAsset, err = aerospike.AsString(ctx, rec.Bins, "quote_asset")
+	if err != nil {
+		return nil, err
+	}
+	r.BuyPrice, err = aerospike.AsFloat(ctx, rec.Bins, "buy_price")
+	if err != nil {
+		return nil, err
+	}
+	r.SellPrice, err = aerospike.AsFloat(ctx, rec.Bins, "sell_price")
+	if err != nil {
+		return nil, err
+	}
+	r.SpreadShare, err = aerospike.AsFloat(ctx, rec.Bins, "spread_share")
+	if err != nil {
+		return nil, err
+	}
+	r.Volume, err = aerospike.AsFloat(ctx, rec.Bins, "volume")
+	if err != nil {
+		return nil, err
+	}
+	r.Profit, err = aerospike.AsFloat(ctx, rec.Bins, "profit")
+	if err != nil {
+		return nil, err
+	}
+	r.ExchangeCodes, err = aerospike.AsStrings(ctx, rec.Bins, "exchange_codes")
+	if err != nil {
+		return nil, err
+	}
+	r.Methods, err = aerospike.AsStrings(ctx, rec.Bins, "methods")
+	if err != nil {
+		return nil, err
+	}
+	createdAtInt, err := aerospike.AsInt(ctx, rec.Bins, "created_at")
+	if err != nil {
+		return nil, err
+	}
+	r.CreatedAt = time.Unix(0, int64(createdAtInt))
+	expiresAtInt, err := aerospike.AsInt(ctx, rec.Bins, "expires_at")
+	if err != nil {
+		return nil, err
+	}
+	if expiresAtInt != 0 {
+		r.ExpiresAt = time.Unix(0, int64(expiresAtInt))
+	}
+	observedAtInt, err := aerospike.AsInt(ctx, rec.Bins, "observed_at")
+	if err != nil {
+		return nil, err
+	}
+	if observedAtInt != 0 {
+		r.ObservedAt = time.Unix(0, int64(observedAtInt))
+	}
+	buy, err := aerospike.AsBytes(ctx, rec.Bins, "buy")
+	if err != nil {
+		return nil, err
+	}
+	if buy != nil {
+		_ = json.Unmarshal(buy, &r.Buy)
+	}
+	sell, err := aerospike.AsBytes(ctx, rec.Bins, "sell")
+	if err != nil {
+		return nil, err
+	}
+	if sell != nil {
+		_ = json.Unmarshal(sell, &r.Sell)
+	}
+	return r, nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	memcache "github.com/mikhailbolshakov/cryptocare/src/kit/cache"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+)
+
+// spreadMemStorageImpl keeps spreads in memory
+type spreadMemStorageImpl struct {
+	cache memcache.MemCache
+}
+
+func (c *spreadMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("spread-mem-storage")
+}
+
+func NewSpreadMemStorage() domain.SpreadStorage {
+	return &spreadMemStorageImpl{
+		cache: memcache.NewMemCache(),
+	}
+}
+
+func (c *spreadMemStorageImpl) SaveSpreads(ctx context.Context, spreads []*domain.Spread) error {
+	c.l().C(ctx).Mth("save-spreads").Trc()
+	for _, spread := range spreads {
+		stored := *spread
+		c.cache.Set(spread.Id, &stored, spreadTtl(spread))
+	}
+	return nil
+}
+
+func (c *spreadMemStorageImpl) GetSpreads(ctx context.Context, rq *domain.GetSpreadsRequest) (*domain.GetSpreadsResponse, error) {
+	c.l().C(ctx).Mth("get-spreads").Trc()
+	var spreads []*domain.Spread
+	for _, v := range c.cache.Items() {
+		spread := *v.(*domain.Spread)
+		if matchSpreadRequest(rq, &spread) {
+			spreads = append(spreads, &spread)
+		}
+	}
+	return pageSpreads(rq, spreads), nil
+}
+
+func (c *spreadMemStorageImpl) GetSpread(ctx context.Context, spreadId string) (*domain.Spread, error) {
+	c.l().C(ctx).Mth("get-spread").F(log.FF{"spreadId": spreadId}).Trc()
+	v, ok := c.cache.Get(spreadId)
+	if !ok {
+		return nil, nil
+	}
+	spread := *v.(*domain.Spread)
+	return &spread, nil
+}
+
+func (c *spreadMemStorageImpl) SpreadExists(ctx context.Context, spreadId string) (bool, error) {
+	c.l().C(ctx).Mth("spread-exists").F(log.FF{"spreadId": spreadId}).Trc()
+	_, ok := c.cache.Get(spreadId)
+	return ok, nil
+}
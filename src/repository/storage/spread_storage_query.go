@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"sort"
+	"time"
+)
+
+const (
+	// spreadDefaultTtl ttl of the spread if expiration isn't specified
+	spreadDefaultTtl = time.Hour
+)
+
+// matchSpreadRequest checks if spread satisfies request criteria
+func matchSpreadRequest(rq *domain.GetSpreadsRequest, spread *domain.Spread) bool {
+	return (len(rq.Assets) == 0 || kit.Strings(rq.Assets).Contains(spread.BaseAsset) || kit.Strings(rq.Assets).Contains(spread.QuoteAsset)) &&
+		(len(rq.Types) == 0 || kit.Strings(rq.Types).Contains(spread.Type)) &&
+		(len(rq.ExchangeCodes) == 0 || kit.Strings(spread.ExchangeCodes).Subset(rq.ExchangeCodes)) &&
+		(len(rq.Methods) == 0 || kit.Strings(spread.Methods).Sanitize().Subset(kit.Strings(rq.Methods).Sanitize())) &&
+		(rq.MinSpread == 0.0 || spread.SpreadShare >= 1+rq.MinSpread*0.01)
+}
+
+// spreadTtl calculates ttl of the spread in the storage by its expiration
+func spreadTtl(spread *domain.Spread) time.Duration {
+	if spread.ExpiresAt.IsZero() {
+		return spreadDefaultTtl
+	}
+	ttl := spread.ExpiresAt.Sub(kit.Now())
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return ttl
+}
+
+// pageSpreads sorts matched spreads (the highest spreads go first) and takes the requested page
+func pageSpreads(rq *domain.GetSpreadsRequest, spreads []*domain.Spread) *domain.GetSpreadsResponse {
+	sort.Slice(spreads, func(i, j int) bool {
+		if spreads[i].SpreadShare != spreads[j].SpreadShare {
+			return spreads[i].SpreadShare > spreads[j].SpreadShare
+		}
+		return spreads[i].Id < spreads[j].Id
+	})
+	start := 0
+	if rq.Size > 0 {
+		start = rq.Index * rq.Size
+	}
+	if start > len(spreads) {
+		start = len(spreads)
+	}
+	end := len(spreads)
+	if rq.Size > 0 && start+rq.Size < end {
+		end = start + rq.Size
+	}
+	return &domain.GetSpreadsResponse{
+		PagingResponse: kit.PagingResponse{Total: len(spreads), Index: rq.Index},
+		Spreads:        spreads[start:end],
+	}
+}
@@ -0,0 +1,774 @@
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/auth"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type memStorageTestSuite struct {
+	kitTestSuite.Suite
+}
+
+func (s *memStorageTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestMemStorageSuite(t *testing.T) {
+	suite.Run(t, new(memStorageTestSuite))
+}
+
+func (s *memStorageTestSuite) Test_Bids_PutGet() {
+	storage := NewBidMemStorage()
+	bid := &domain.Bid{Id: kit.NewId(), Type: domain.BidTypeP2P, SrcAsset: "RUB", TrgAsset: "USDT", Rate: 60.0, Methods: []string{"tinkoff"}}
+	s.NoError(storage.PutBids(s.Ctx, []*domain.Bid{bid}, 60))
+
+	bids, err := storage.GetBidsByIds(s.Ctx, []string{bid.Id, kit.NewId()})
+	s.NoError(err)
+	s.Len(bids, 1)
+	s.Equal(bid, bids[0])
+
+	lights, err := storage.GetBidsLightAll(s.Ctx)
+	s.NoError(err)
+	s.Len(lights, 1)
+	s.Equal(bid.Id, lights[0].Id)
+	s.Equal(bid.Rate, lights[0].Rate)
+}
+
+func (s *memStorageTestSuite) Test_Bids_Expired() {
+	storage := NewBidMemStorage()
+	bid := &domain.Bid{Id: kit.NewId(), SrcAsset: "RUB", TrgAsset: "USDT", Rate: 60.0}
+	s.NoError(storage.PutBids(s.Ctx, []*domain.Bid{bid}, 1))
+	time.Sleep(time.Millisecond * 1100)
+	bids, err := storage.GetBidsByIds(s.Ctx, []string{bid.Id})
+	s.NoError(err)
+	s.Empty(bids)
+	lights, err := storage.GetBidsLightAll(s.Ctx)
+	s.NoError(err)
+	s.Empty(lights)
+}
+
+func (s *memStorageTestSuite) Test_Bids_ByOwnerDelete() {
+	storage := NewBidMemStorage()
+	ownerId := kit.NewId()
+	manual := &domain.Bid{Id: kit.NewId(), Type: domain.BidTypeManual, SrcAsset: "RUB", TrgAsset: "USDT", Rate: 0.016, OwnerId: ownerId}
+	other := &domain.Bid{Id: kit.NewId(), Type: domain.BidTypeP2P, SrcAsset: "RUB", TrgAsset: "USDT", Rate: 0.016}
+	s.NoError(storage.PutBids(s.Ctx, []*domain.Bid{manual, other}, 60))
+
+	bids, err := storage.GetBidsByOwner(s.Ctx, ownerId)
+	s.NoError(err)
+	s.Len(bids, 1)
+	s.Equal(manual.Id, bids[0].Id)
+
+	s.NoError(storage.DeleteBid(s.Ctx, manual.Id))
+	bids, err = storage.GetBidsByOwner(s.Ctx, ownerId)
+	s.NoError(err)
+	s.Empty(bids)
+	bids, err = storage.GetBidsByIds(s.Ctx, []string{manual.Id, other.Id})
+	s.NoError(err)
+	s.Len(bids, 1)
+}
+
+func (s *memStorageTestSuite) Test_Bids_Private() {
+	storage := NewBidMemStorage()
+	ownerId := kit.NewId()
+	private := &domain.Bid{Id: kit.NewId(), Type: domain.BidTypeManual, SrcAsset: "RUB", TrgAsset: "USDT", Rate: 0.016, OwnerId: ownerId, Private: true}
+	public := &domain.Bid{Id: kit.NewId(), Type: domain.BidTypeP2P, SrcAsset: "RUB", TrgAsset: "USDT", Rate: 0.016}
+	s.NoError(storage.PutBids(s.Ctx, []*domain.Bid{private, public}, 60))
+
+	// private bids never go to the public market
+	lights, err := storage.GetBidsLightAll(s.Ctx)
+	s.NoError(err)
+	s.Len(lights, 1)
+	s.Equal(public.Id, lights[0].Id)
+	bids, err := storage.GetBidsByIds(s.Ctx, []string{private.Id, public.Id})
+	s.NoError(err)
+	s.Len(bids, 1)
+
+	s.Equal(domain.BidTypeP2P, lights[0].Type)
+
+	lights, err = storage.GetPrivateBidsLightByOwner(s.Ctx, ownerId)
+	s.NoError(err)
+	s.Len(lights, 1)
+	s.Equal(ownerId, lights[0].OwnerId)
+	s.Equal(domain.BidTypeManual, lights[0].Type)
+	bids, err = storage.GetPrivateBidsByIds(s.Ctx, []string{private.Id, public.Id})
+	s.NoError(err)
+	s.Len(bids, 1)
+	s.True(bids[0].Private)
+
+	owners, err := storage.GetPrivateBidOwners(s.Ctx)
+	s.NoError(err)
+	s.Equal([]string{ownerId}, owners)
+
+	s.NoError(storage.DeleteBid(s.Ctx, private.Id))
+	owners, err = storage.GetPrivateBidOwners(s.Ctx)
+	s.NoError(err)
+	s.Empty(owners)
+}
+
+func (s *memStorageTestSuite) Test_Chains() {
+	storage := NewChainMemStorage()
+	now := time.Now().UTC()
+	chains := []*domain.ProfitableChain{
+		{Id: kit.NewId(), Asset: "USDT", ProfitShare: 1.01, Depth: 3, CreatedAt: now.Add(-time.Minute), Bids: []*domain.Bid{{Id: kit.NewId()}}},
+		{Id: kit.NewId(), Asset: "USDT", ProfitShare: 1.02, Depth: 3, CreatedAt: now, Bids: []*domain.Bid{{Id: kit.NewId()}}},
+		{Id: kit.NewId(), Asset: "RUB", ProfitShare: 1.03, Depth: 4, CreatedAt: now, Bids: []*domain.Bid{{Id: kit.NewId()}}},
+	}
+	s.NoError(storage.SaveProfitableChains(s.Ctx, chains))
+
+	exists, err := storage.ProfitableChainExists(s.Ctx, chains[0].Id)
+	s.NoError(err)
+	s.True(exists)
+	exists, err = storage.ProfitableChainExists(s.Ctx, kit.NewId())
+	s.NoError(err)
+	s.False(exists)
+
+	chain, err := storage.GetProfitableChain(s.Ctx, chains[0].Id)
+	s.NoError(err)
+	s.Equal(chains[0], chain)
+
+	rs, err := storage.GetProfitableChains(s.Ctx, &domain.GetProfitableChainsRequest{
+		PagingRequest: kit.PagingRequest{Size: 1},
+		Assets:        []string{"USDT"},
+	})
+	s.NoError(err)
+	s.Len(rs.Chains, 1)
+	s.Equal(chains[1].Id, rs.Chains[0].Id)
+	s.Empty(rs.Chains[0].Bids)
+
+	rs, err = storage.GetProfitableChains(s.Ctx, &domain.GetProfitableChainsRequest{WithBids: true})
+	s.NoError(err)
+	s.Len(rs.Chains, 3)
+	for _, ch := range rs.Chains {
+		s.NotEmpty(ch.Bids)
+	}
+}
+
+func (s *memStorageTestSuite) Test_Chains_Expiring() {
+	storage := NewChainMemStorage()
+	now := time.Now().UTC()
+	chains := []*domain.ProfitableChain{
+		{Id: kit.NewId(), Asset: "USDT", CreatedAt: now, ExpiresAt: now.Add(time.Minute)},
+		{Id: kit.NewId(), Asset: "USDT", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+	}
+	s.NoError(storage.SaveProfitableChains(s.Ctx, chains))
+
+	rs, err := storage.GetExpiringChains(s.Ctx, now, now.Add(time.Minute*2))
+	s.NoError(err)
+	s.Len(rs, 1)
+	s.Equal(chains[0].Id, rs[0].Id)
+
+	rs, err = storage.GetExpiringChains(s.Ctx, now.Add(time.Minute), now.Add(time.Hour))
+	s.NoError(err)
+	s.Len(rs, 1)
+	s.Equal(chains[1].Id, rs[0].Id)
+}
+
+func (s *memStorageTestSuite) Test_Subscriptions() {
+	storage := NewSubscriptionMemStorage()
+	userId := kit.NewId()
+	active := &domain.Subscription{Id: kit.NewId(), UserId: userId, IsActive: true, Filter: &domain.SubscriptionChainFilter{Assets: []string{"USDT"}},
+		Notifications: []*domain.SubscriptionNotification{{Id: kit.NewId(), Channel: domain.SubscriptionNotificationChannelEmail, Email: &domain.SubscriptionEmailNotificationDetails{To: []string{"a@example.com"}}}}}
+	inactive := &domain.Subscription{Id: kit.NewId(), UserId: userId, Filter: &domain.SubscriptionChainFilter{}}
+	another := &domain.Subscription{Id: kit.NewId(), UserId: kit.NewId(), IsActive: true, Filter: &domain.SubscriptionChainFilter{}}
+	s.NoError(storage.SaveSubscription(s.Ctx, active))
+	s.NoError(storage.SaveSubscription(s.Ctx, inactive))
+	s.NoError(storage.SaveSubscription(s.Ctx, another))
+
+	// changes made by caller don't affect stored subscription
+	active.Filter.MinProfit = 1.5
+	subs, err := storage.GetSubscription(s.Ctx, active.Id)
+	s.NoError(err)
+	s.Empty(subs.Filter.MinProfit)
+	// nor changes of retrieved nested slices
+	subs.Filter.Assets[0] = "BTC"
+	subs.Notifications[0].IsActive = true
+	subs.Notifications[0].Email.To[0] = "b@example.com"
+	subs.Notifications = append(subs.Notifications, &domain.SubscriptionNotification{Id: kit.NewId()})
+	subs, err = storage.GetSubscription(s.Ctx, active.Id)
+	s.NoError(err)
+	s.Equal([]string{"USDT"}, subs.Filter.Assets)
+	s.Len(subs.Notifications, 1)
+	s.False(subs.Notifications[0].IsActive)
+	s.Equal([]string{"a@example.com"}, subs.Notifications[0].Email.To)
+
+	ss, err := storage.SearchSubscriptions(s.Ctx, &domain.SearchSubscriptionsRequest{UserId: userId})
+	s.NoError(err)
+	s.Len(ss, 1)
+	ss, err = storage.SearchSubscriptions(s.Ctx, &domain.SearchSubscriptionsRequest{UserId: userId, WithInActive: true})
+	s.NoError(err)
+	s.Len(ss, 2)
+	ss, err = storage.SearchSubscriptions(s.Ctx, &domain.SearchSubscriptionsRequest{})
+	s.NoError(err)
+	s.Len(ss, 2)
+
+	s.NoError(storage.DeleteSubscription(s.Ctx, active.Id))
+	subs, err = storage.GetSubscription(s.Ctx, active.Id)
+	s.NoError(err)
+	s.Nil(subs)
+}
+
+func (s *memStorageTestSuite) Test_Subscriptions_SearchByOpportunity() {
+	storage := NewSubscriptionMemStorage()
+	usdt := &domain.Subscription{Id: kit.NewId(), IsActive: true, Filter: &domain.SubscriptionChainFilter{Assets: []string{"USDT"}, MinProfit: 2}}
+	anyAsset := &domain.Subscription{Id: kit.NewId(), IsActive: true, Filter: &domain.SubscriptionChainFilter{}}
+	spreads := &domain.Subscription{Id: kit.NewId(), IsActive: true, Filter: &domain.SubscriptionChainFilter{Opportunities: []string{domain.OpportunityTypeSpread}}}
+	for _, subs := range []*domain.Subscription{usdt, anyAsset, spreads} {
+		s.NoError(storage.SaveSubscription(s.Ctx, subs))
+	}
+	ids := func(rq *domain.SearchSubscriptionsRequest) []string {
+		ss, err := storage.SearchSubscriptions(s.Ctx, rq)
+		s.NoError(err)
+		var r []string
+		for _, subs := range ss {
+			r = append(r, subs.Id)
+		}
+		return r
+	}
+	s.ElementsMatch([]string{usdt.Id, anyAsset.Id}, ids(&domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeChain}))
+	s.ElementsMatch([]string{spreads.Id}, ids(&domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeSpread}))
+	s.ElementsMatch([]string{anyAsset.Id, spreads.Id}, ids(&domain.SearchSubscriptionsRequest{Assets: []string{"RUB"}}))
+	s.ElementsMatch([]string{usdt.Id, anyAsset.Id, spreads.Id}, ids(&domain.SearchSubscriptionsRequest{Assets: []string{"RUB", "USDT"}}))
+	// min profit isn't reached
+	s.ElementsMatch([]string{anyAsset.Id}, ids(&domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeChain, ProfitShare: 1.01}))
+	s.ElementsMatch([]string{usdt.Id, anyAsset.Id}, ids(&domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeChain, ProfitShare: 1.02}))
+}
+
+func (s *memStorageTestSuite) Test_RateHistory() {
+	storage := NewRateHistoryMemStorage()
+	now := time.Now().UTC().Truncate(time.Minute)
+	points := []*domain.RatePoint{
+		{SrcAsset: "USD", TrgAsset: "RUB", ExchangeCode: "binance", Method: "M1", Time: now.Add(-time.Minute), BestRate: 60},
+		{SrcAsset: "USD", TrgAsset: "RUB", ExchangeCode: "huobi", Method: "M1", Time: now, BestRate: 61},
+		{SrcAsset: "USD", TrgAsset: "EUR", ExchangeCode: "binance", Method: "M1", Time: now, BestRate: 1},
+	}
+	s.NoError(storage.SaveRatePoints(s.Ctx, points))
+	// overwrite the point
+	s.NoError(storage.SaveRatePoints(s.Ctx, []*domain.RatePoint{{SrcAsset: "USD", TrgAsset: "RUB", ExchangeCode: "binance", Method: "M1", Time: now.Add(-time.Minute), BestRate: 62}}))
+
+	rs, err := storage.GetRatePoints(s.Ctx, &domain.GetRatePointsRequest{SrcAsset: "USD", TrgAsset: "RUB", From: now.Add(-time.Hour), To: now.Add(time.Minute)})
+	s.NoError(err)
+	s.Len(rs, 2)
+	s.Equal(62.0, rs[0].BestRate)
+	s.Equal(61.0, rs[1].BestRate)
+
+	rs, err = storage.GetRatePoints(s.Ctx, &domain.GetRatePointsRequest{SrcAsset: "USD", TrgAsset: "RUB", ExchangeCode: "huobi", From: now.Add(-time.Hour), To: now.Add(time.Minute)})
+	s.NoError(err)
+	s.Len(rs, 1)
+
+	s.NoError(storage.DeleteRatePoints(s.Ctx, now))
+	rs, err = storage.GetRatePoints(s.Ctx, &domain.GetRatePointsRequest{SrcAsset: "USD", TrgAsset: "RUB", From: now.Add(-time.Hour), To: now.Add(time.Minute)})
+	s.NoError(err)
+	s.Len(rs, 1)
+}
+
+func (s *memStorageTestSuite) Test_Spreads() {
+	storage := NewSpreadMemStorage()
+	now := time.Now().UTC()
+	spreads := []*domain.Spread{
+		{Id: kit.NewId(), Type: domain.SpreadTypeCrossExchange, BaseAsset: "USDT", QuoteAsset: "RUB", SpreadShare: 1.01, ExchangeCodes: []string{"binance", "huobi"}, CreatedAt: now},
+		{Id: kit.NewId(), Type: domain.SpreadTypeCrossMethod, BaseAsset: "USDT", QuoteAsset: "RUB", SpreadShare: 1.03, ExchangeCodes: []string{"binance"}, CreatedAt: now},
+		{Id: kit.NewId(), Type: domain.SpreadTypeCrossExchange, BaseAsset: "BTC", QuoteAsset: "USD", SpreadShare: 1.02, ExchangeCodes: []string{"huobi", "bybit"}, CreatedAt: now},
+	}
+	s.NoError(storage.SaveSpreads(s.Ctx, spreads))
+
+	exists, err := storage.SpreadExists(s.Ctx, spreads[0].Id)
+	s.NoError(err)
+	s.True(exists)
+	spread, err := storage.GetSpread(s.Ctx, kit.NewId())
+	s.NoError(err)
+	s.Nil(spread)
+	spread, err = storage.GetSpread(s.Ctx, spreads[0].Id)
+	s.NoError(err)
+	s.Equal(spreads[0], spread)
+
+	// the highest spreads go first
+	rs, err := storage.GetSpreads(s.Ctx, &domain.GetSpreadsRequest{})
+	s.NoError(err)
+	s.Equal(3, rs.Total)
+	s.Equal(spreads[1].Id, rs.Spreads[0].Id)
+	s.Equal(spreads[2].Id, rs.Spreads[1].Id)
+
+	rs, err = storage.GetSpreads(s.Ctx, &domain.GetSpreadsRequest{Assets: []string{"RUB"}, Types: []string{domain.SpreadTypeCrossExchange}})
+	s.NoError(err)
+	s.Len(rs.Spreads, 1)
+	s.Equal(spreads[0].Id, rs.Spreads[0].Id)
+
+	rs, err = storage.GetSpreads(s.Ctx, &domain.GetSpreadsRequest{ExchangeCodes: []string{"binance", "huobi"}, MinSpread: 1.5})
+	s.NoError(err)
+	s.Len(rs.Spreads, 1)
+	s.Equal(spreads[1].Id, rs.Spreads[0].Id)
+
+	rs, err = storage.GetSpreads(s.Ctx, &domain.GetSpreadsRequest{PagingRequest: kit.PagingRequest{Size: 2, Index: 1}})
+	s.NoError(err)
+	s.Equal(3, rs.Total)
+	s.Len(rs.Spreads, 1)
+	s.Equal(spreads[0].Id, rs.Spreads[0].Id)
+}
+
+func (s *memStorageTestSuite) Test_Outbox_ClaimUpdate() {
+	storage := NewOutboxMemStorage()
+	now := kit.Now()
+	due := &domain.OutboxDelivery{Id: kit.NewId(), Status: domain.OutboxStatusPending, NextAttemptAt: now.Add(-time.Second), CreatedAt: now}
+	later := &domain.OutboxDelivery{Id: kit.NewId(), Status: domain.OutboxStatusPending, NextAttemptAt: now.Add(time.Minute), CreatedAt: now}
+	s.NoError(storage.AddDeliveries(s.Ctx, []*domain.OutboxDelivery{due, later}))
+
+	claimed, err := storage.ClaimDeliveries(s.Ctx, now, 10, now.Add(time.Minute))
+	s.NoError(err)
+	s.Len(claimed, 1)
+	s.Equal(due.Id, claimed[0].Id)
+	s.Equal(domain.OutboxStatusSending, claimed[0].Status)
+	s.Equal(1, claimed[0].Attempts)
+
+	// claimed delivery isn't claimed again until the lease expires
+	claimed, err = storage.ClaimDeliveries(s.Ctx, now, 10, now.Add(time.Minute))
+	s.NoError(err)
+	s.Empty(claimed)
+	claimed, err = storage.ClaimDeliveries(s.Ctx, now.Add(time.Minute), 10, now.Add(time.Minute*2))
+	s.NoError(err)
+	s.Len(claimed, 2)
+
+	delivered := *due
+	delivered.Status = domain.OutboxStatusDelivered
+	delivered.UpdatedAt = now
+	s.NoError(storage.UpdateDelivery(s.Ctx, &delivered))
+	found, err := storage.GetDeliveries(s.Ctx, []string{due.Id})
+	s.NoError(err)
+	s.Len(found, 1)
+	s.Equal(domain.OutboxStatusDelivered, found[0].Status)
+
+	// only deliveries with the given status are deleted
+	s.NoError(storage.DeleteDeliveries(s.Ctx, domain.OutboxStatusDead, now.Add(time.Second)))
+	found, err = storage.GetDeliveries(s.Ctx, []string{due.Id, later.Id})
+	s.NoError(err)
+	s.Len(found, 2)
+	s.NoError(storage.DeleteDeliveries(s.Ctx, domain.OutboxStatusDelivered, now.Add(time.Second)))
+	found, err = storage.GetDeliveries(s.Ctx, []string{due.Id, later.Id})
+	s.NoError(err)
+	s.Len(found, 1)
+	s.Equal(later.Id, found[0].Id)
+}
+
+func (s *memStorageTestSuite) Test_Outbox_SameOpportunityAddedOnce() {
+	storage := NewOutboxMemStorage()
+	now := kit.Now()
+	notification := &domain.SubscriptionNotification{Id: kit.NewId()}
+	delivery := func(opportunityId string) *domain.OutboxDelivery {
+		return &domain.OutboxDelivery{Id: kit.NewId(), SubscriptionId: "subs", Notification: notification, OpportunityId: opportunityId,
+			Status: domain.OutboxStatusPending, NextAttemptAt: now, CreatedAt: now}
+	}
+	first, duplicate, digest, anotherDigest := delivery("chain"), delivery("chain"), delivery(""), delivery("")
+	s.NoError(storage.AddDeliveries(s.Ctx, []*domain.OutboxDelivery{first, digest}))
+	s.NoError(storage.AddDeliveries(s.Ctx, []*domain.OutboxDelivery{duplicate, anotherDigest}))
+
+	found, err := storage.GetDeliveries(s.Ctx, []string{first.Id, duplicate.Id, digest.Id, anotherDigest.Id})
+	s.NoError(err)
+	s.Len(found, 3)
+	for _, d := range found {
+		s.NotEqual(duplicate.Id, d.Id)
+	}
+}
+
+func (s *memStorageTestSuite) Test_Outbox_Search() {
+	storage := NewOutboxMemStorage()
+	now := kit.Now()
+	subscriptionId := kit.NewId()
+	var deliveries []*domain.OutboxDelivery
+	for i := 0; i < 5; i++ {
+		deliveries = append(deliveries, &domain.OutboxDelivery{
+			Id:             kit.NewId(),
+			SubscriptionId: subscriptionId,
+			Notification:   &domain.SubscriptionNotification{Channel: domain.SubscriptionNotificationChannelWebhook},
+			Status:         domain.OutboxStatusDead,
+			CreatedAt:      now.Add(time.Duration(i) * time.Second),
+		})
+	}
+	deliveries[0].Status = domain.OutboxStatusDelivered
+	s.NoError(storage.AddDeliveries(s.Ctx, deliveries))
+
+	rs, err := storage.SearchDeliveries(s.Ctx, &domain.SearchOutboxRequest{
+		PagingRequest:  kit.PagingRequest{Size: 3},
+		Statuses:       []string{domain.OutboxStatusDead},
+		Channel:        domain.SubscriptionNotificationChannelWebhook,
+		SubscriptionId: subscriptionId,
+	})
+	s.NoError(err)
+	s.Equal(4, rs.Total)
+	s.Len(rs.Deliveries, 3)
+	// the latest go first
+	s.Equal(deliveries[4].Id, rs.Deliveries[0].Id)
+
+	rs, err = storage.SearchDeliveries(s.Ctx, &domain.SearchOutboxRequest{PagingRequest: kit.PagingRequest{Size: 3, Index: 1}, Statuses: []string{domain.OutboxStatusDead}})
+	s.NoError(err)
+	s.Len(rs.Deliveries, 1)
+	s.Equal(deliveries[1].Id, rs.Deliveries[0].Id)
+
+	rs, err = storage.SearchDeliveries(s.Ctx, &domain.SearchOutboxRequest{PagingRequest: kit.PagingRequest{Size: 3}, Channel: domain.SubscriptionNotificationChannelEmail})
+	s.NoError(err)
+	s.Empty(rs.Deliveries)
+}
+
+func (s *memStorageTestSuite) Test_TelegramLinks() {
+	storage := NewTelegramLinkMemStorage()
+
+	// code is taken once
+	code := &domain.TelegramLinkCode{Code: kit.NewRandString(), UserId: kit.NewId(), ExpiresAt: kit.Now().Add(time.Minute)}
+	s.NoError(storage.SaveLinkCode(s.Ctx, code))
+	taken, err := storage.TakeLinkCode(s.Ctx, code.Code)
+	s.NoError(err)
+	s.Equal(code, taken)
+	taken, err = storage.TakeLinkCode(s.Ctx, code.Code)
+	s.NoError(err)
+	s.Nil(taken)
+
+	link := &domain.TelegramLink{UserId: code.UserId, TelegramUserId: 100, ChatId: 100, Username: "trader", CreatedAt: kit.Now()}
+	s.NoError(storage.SaveLink(s.Ctx, link))
+	found, err := storage.GetLinkByTelegramUser(s.Ctx, 100)
+	s.NoError(err)
+	s.Equal(link, found)
+
+	// telegram account linked to another user is moved
+	other := &domain.TelegramLink{UserId: kit.NewId(), TelegramUserId: 100, ChatId: 100, CreatedAt: kit.Now()}
+	s.NoError(storage.SaveLink(s.Ctx, other))
+	found, err = storage.GetLinkByUser(s.Ctx, link.UserId)
+	s.NoError(err)
+	s.Nil(found)
+	found, err = storage.GetLinkByTelegramUser(s.Ctx, 100)
+	s.NoError(err)
+	s.Equal(other.UserId, found.UserId)
+
+	s.NoError(storage.DeleteLink(s.Ctx, other.UserId))
+	found, err = storage.GetLinkByUser(s.Ctx, other.UserId)
+	s.NoError(err)
+	s.Nil(found)
+}
+
+func (s *memStorageTestSuite) Test_TelegramChannels() {
+	storage := NewTelegramChannelMemStorage()
+
+	v := &domain.TelegramChannelVerification{
+		UserId:    kit.NewId(),
+		ChatId:    -100,
+		Code:      kit.NewRandString(),
+		Status:    domain.TelegramChannelVerificationPending,
+		ExpiresAt: kit.Now().Add(time.Minute),
+		CreatedAt: kit.Now(),
+	}
+	s.NoError(storage.SaveChannelVerification(s.Ctx, v))
+	found, err := storage.GetChannelVerificationByCode(s.Ctx, -100, v.Code)
+	s.NoError(err)
+	s.Equal(v, found)
+	found, err = storage.GetChannelVerificationByCode(s.Ctx, -200, v.Code)
+	s.NoError(err)
+	s.Nil(found)
+
+	// the same channel verified by another user is kept separately
+	other := &domain.TelegramChannelVerification{UserId: kit.NewId(), ChatId: -100, Status: domain.TelegramChannelVerificationPending, CreatedAt: kit.Now()}
+	s.NoError(storage.SaveChannelVerification(s.Ctx, other))
+	v.Status = domain.TelegramChannelVerificationVerified
+	s.NoError(storage.SaveChannelVerification(s.Ctx, v))
+	found, err = storage.GetChannelVerification(s.Ctx, v.UserId, -100)
+	s.NoError(err)
+	s.Equal(domain.TelegramChannelVerificationVerified, found.Status)
+
+	all, err := storage.GetChannelVerifications(s.Ctx, other.UserId)
+	s.NoError(err)
+	s.Len(all, 1)
+	s.Equal(domain.TelegramChannelVerificationPending, all[0].Status)
+}
+
+func (s *memStorageTestSuite) Test_TelegramAlerts() {
+	storage := NewTelegramAlertMemStorage()
+
+	now := kit.Now()
+	alert := &domain.TelegramAlert{
+		ChatId:    -100,
+		MessageId: 1,
+		ChainId:   "chain",
+		Chain:     &domain.ProfitableChain{Id: "chain", ProfitShare: 1.05},
+		Profit:    1.05,
+		Status:    domain.TelegramAlertActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.NoError(storage.SaveAlert(s.Ctx, alert))
+	// the same chain sent to another chat
+	s.NoError(storage.SaveAlert(s.Ctx, &domain.TelegramAlert{ChatId: -200, MessageId: 1, ChainId: "chain", Status: domain.TelegramAlertExpired,
+		CreatedAt: now, UpdatedAt: now.Add(-time.Hour)}))
+
+	found, err := storage.GetAlertsByChains(s.Ctx, []string{"chain", "other"})
+	s.NoError(err)
+	s.Len(found, 1)
+	s.Equal(alert, found[0])
+
+	alert.Pending = true
+	s.NoError(storage.SaveAlert(s.Ctx, alert))
+	active, err := storage.GetActiveAlerts(s.Ctx)
+	s.NoError(err)
+	s.Len(active, 1)
+	s.True(active[0].Pending)
+
+	s.NoError(storage.DeleteAlerts(s.Ctx, now.Add(-time.Minute)))
+	s.NoError(storage.DeleteAlerts(s.Ctx, now.Add(-time.Minute)))
+	found, err = storage.GetAlertsByChains(s.Ctx, []string{"chain"})
+	s.NoError(err)
+	s.Len(found, 1)
+	s.Len(storage.(*telegramAlertMemStorageImpl).alerts, 1)
+}
+
+func (s *memStorageTestSuite) Test_TelegramBots() {
+	storage := NewTelegramBotMemStorage()
+
+	now := kit.Now()
+	bot := &domain.TelegramBotAccount{
+		Id:             kit.NewId(),
+		Name:           "partner",
+		EncryptedToken: "encrypted",
+		UserIds:        []string{kit.NewId()},
+		IsActive:       true,
+		Health:         domain.TelegramBotHealthy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	s.NoError(storage.SaveBot(s.Ctx, bot))
+	s.NoError(storage.SaveBot(s.Ctx, &domain.TelegramBotAccount{Id: kit.NewId(), Name: "other", CreatedAt: now.Add(time.Second)}))
+	found, err := storage.GetBot(s.Ctx, bot.Id)
+	s.NoError(err)
+	s.Equal(bot, found)
+
+	bot.Health = domain.TelegramBotUnhealthy
+	s.NoError(storage.SaveBot(s.Ctx, bot))
+	all, err := storage.GetBots(s.Ctx)
+	s.NoError(err)
+	s.Len(all, 2)
+	s.Equal(domain.TelegramBotUnhealthy, all[0].Health)
+
+	s.NoError(storage.DeleteBot(s.Ctx, bot.Id))
+	found, err = storage.GetBot(s.Ctx, bot.Id)
+	s.NoError(err)
+	s.Nil(found)
+}
+
+func (s *memStorageTestSuite) Test_UsersSessions() {
+	users := NewUserMemStorage()
+	user := &auth.User{Id: kit.NewId(), Username: "user@example.com", Roles: []string{"client"}}
+	s.NoError(users.CreateUser(s.Ctx, user))
+	found, err := users.GetByUsername(s.Ctx, user.Username)
+	s.NoError(err)
+	s.Equal(user, found)
+	found.Roles[0] = "changed"
+	found, err = users.GetUser(s.Ctx, user.Id)
+	s.NoError(err)
+	s.Equal("client", found.Roles[0])
+	s.NoError(users.DeleteUser(s.Ctx, user))
+	found, err = users.GetUser(s.Ctx, user.Id)
+	s.NoError(err)
+	s.Nil(found)
+
+	sessions := NewSessionMemStorage()
+	sess := &auth.Session{Id: kit.NewId(), UserId: user.Id, LoginAt: kit.Now()}
+	s.NoError(sessions.CreateSession(s.Ctx, sess))
+	ss, err := sessions.GetByUser(s.Ctx, user.Id)
+	s.NoError(err)
+	s.Len(ss, 1)
+	s.NoError(sessions.Logout(s.Ctx, sess.Id, kit.Now()))
+	ss, err = sessions.GetByUser(s.Ctx, user.Id)
+	s.NoError(err)
+	s.Empty(ss)
+	stored, err := sessions.Get(s.Ctx, sess.Id)
+	s.NoError(err)
+	s.NotNil(stored.LogoutAt)
+}
+
+func (s *memStorageTestSuite) Test_RequiredBackends() {
+	memory := &service.Storages{
+		Bids:               StorageTypeMemory,
+		Chains:             StorageTypeMemory,
+		Subscriptions:      StorageTypeMemory,
+		RateHistory:        StorageTypeMemory,
+		Spreads:            StorageTypeMemory,
+		Outbox:             StorageTypeMemory,
+		DeliveryPolicies:   StorageTypeMemory,
+		TelegramLinks:      StorageTypeMemory,
+		TelegramChannels:   StorageTypeMemory,
+		TelegramAlerts:     StorageTypeMemory,
+		TelegramBots:       StorageTypeMemory,
+		Users:              StorageTypeMemory,
+		EmailVerifications: StorageTypeMemory,
+	}
+	cfg := &service.Config{Storages: memory, Retention: &service.Retention{Archive: &service.ChainArchive{Storage: StorageTypeFile}}}
+	needAero, needPg := requiredBackends(cfg)
+	s.False(needAero)
+	s.False(needPg)
+
+	// pg subscriptions don't need aerospike
+	memory.Subscriptions = StorageTypePg
+	needAero, needPg = requiredBackends(cfg)
+	s.False(needAero)
+	s.True(needPg)
+
+	memory.Subscriptions = StorageTypeMemory
+	memory.Bids = StorageTypeAero
+	needAero, needPg = requiredBackends(cfg)
+	s.True(needAero)
+	s.False(needPg)
+
+	// throttling windows and pending digests are shared by instances through postgres
+	memory.Bids = StorageTypeMemory
+	memory.DeliveryPolicies = StorageTypePg
+	needAero, needPg = requiredBackends(cfg)
+	s.False(needAero)
+	s.True(needPg)
+
+	// telegram channel verifications, alerts and bots are chosen separately from telegram links
+	memory.DeliveryPolicies = StorageTypeMemory
+	memory.TelegramChannels = StorageTypePg
+	needAero, needPg = requiredBackends(cfg)
+	s.False(needAero)
+	s.True(needPg)
+
+	memory.TelegramChannels = StorageTypeMemory
+	memory.TelegramAlerts = StorageTypePg
+	needAero, needPg = requiredBackends(cfg)
+	s.False(needAero)
+	s.True(needPg)
+
+	memory.TelegramAlerts = StorageTypeMemory
+	memory.TelegramBots = StorageTypePg
+	needAero, needPg = requiredBackends(cfg)
+	s.False(needAero)
+	s.True(needPg)
+
+	// archive isn't configured, it's kept in memory
+	memory.TelegramBots = StorageTypeMemory
+	needAero, needPg = requiredBackends(&service.Config{Storages: memory})
+	s.False(needAero)
+	s.False(needPg)
+
+	// defaults
+	needAero, needPg = requiredBackends(&service.Config{Storages: &service.Storages{}, Retention: &service.Retention{Archive: &service.ChainArchive{}}})
+	s.True(needAero)
+	s.True(needPg)
+}
+
+func (s *memStorageTestSuite) Test_DeliveryPolicies_ReserveMessages() {
+	storage := NewDeliveryPolicyMemStorage()
+	subsId := kit.NewId()
+	now := time.Now().UTC()
+
+	reserved, err := storage.ReserveMessages(s.Ctx, subsId, 2, 3, now.Add(-time.Minute), now)
+	s.NoError(err)
+	s.Equal(2, reserved)
+
+	// only one message is left within the window
+	reserved, err = storage.ReserveMessages(s.Ctx, subsId, 2, 3, now.Add(-time.Minute), now)
+	s.NoError(err)
+	s.Equal(1, reserved)
+	reserved, err = storage.ReserveMessages(s.Ctx, subsId, 1, 3, now.Add(-time.Minute), now)
+	s.NoError(err)
+	s.Equal(0, reserved)
+
+	// the window is over
+	later := now.Add(2 * time.Minute)
+	reserved, err = storage.ReserveMessages(s.Ctx, subsId, 5, 3, later.Add(-time.Minute), later)
+	s.NoError(err)
+	s.Equal(3, reserved)
+
+	// other subscriptions aren't affected
+	reserved, err = storage.ReserveMessages(s.Ctx, kit.NewId(), 1, 3, now.Add(-time.Minute), now)
+	s.NoError(err)
+	s.Equal(1, reserved)
+
+	// messages sent before are deleted
+	s.NoError(storage.DeleteMessages(s.Ctx, later))
+	reserved, err = storage.ReserveMessages(s.Ctx, subsId, 5, 3, now.Add(-time.Hour), later)
+	s.NoError(err)
+	s.Equal(0, reserved)
+}
+
+func (s *memStorageTestSuite) Test_DeliveryPolicies_Digests() {
+	storage := NewDeliveryPolicyMemStorage()
+	subsId := kit.NewId()
+	now := time.Now().UTC()
+
+	s.NoError(storage.AddDigestItems(s.Ctx, subsId, []*domain.DigestItem{
+		{OpportunityType: domain.OpportunityTypeChain, OpportunityId: "1", Profit: 1.0},
+		{OpportunityType: domain.OpportunityTypeChain, OpportunityId: "2", Profit: 3.0},
+	}, 2, now))
+	s.NoError(storage.AddDigestItems(s.Ctx, subsId, []*domain.DigestItem{
+		{OpportunityType: domain.OpportunityTypeSpread, OpportunityId: "3", Profit: 2.0},
+	}, 2, now.Add(time.Minute)))
+
+	digests, err := storage.GetPendingDigests(s.Ctx)
+	s.NoError(err)
+	s.Len(digests, 1)
+	digest := digests[0]
+	s.NotEmpty(digest.Id)
+	s.Equal(subsId, digest.SubscriptionId)
+	s.Equal(3, digest.Matched)
+	s.Equal(now, digest.From)
+	// the most profitable items are kept
+	s.Len(digest.Items, 2)
+	s.Equal("2", digest.Items[0].OpportunityId)
+	s.Equal("3", digest.Items[1].OpportunityId)
+
+	// other digest isn't deleted
+	s.NoError(storage.DeletePendingDigest(s.Ctx, subsId, kit.NewId()))
+	digests, err = storage.GetPendingDigests(s.Ctx)
+	s.NoError(err)
+	s.Len(digests, 1)
+
+	s.NoError(storage.DeletePendingDigest(s.Ctx, subsId, digest.Id))
+	digests, err = storage.GetPendingDigests(s.Ctx)
+	s.NoError(err)
+	s.Empty(digests)
+
+	// a new digest is started
+	s.NoError(storage.AddDigestItems(s.Ctx, subsId, []*domain.DigestItem{{OpportunityId: "4", Profit: 1.0}}, 2, now.Add(time.Hour)))
+	digests, err = storage.GetPendingDigests(s.Ctx)
+	s.NoError(err)
+	s.Len(digests, 1)
+	s.NotEqual(digest.Id, digests[0].Id)
+	s.Equal(1, digests[0].Matched)
+	s.Equal(now.Add(time.Hour), digests[0].From)
+}
+
+func (s *memStorageTestSuite) Test_ChainArchive() {
+	storage := NewChainArchiveMemStorage()
+	now := time.Now().UTC()
+	chains := []*domain.ProfitableChain{
+		{Id: kit.NewId(), Asset: "USDT", CreatedAt: now.Add(-3 * time.Hour)},
+		{Id: kit.NewId(), Asset: "USDT", CreatedAt: now.Add(-2 * time.Hour)},
+		{Id: kit.NewId(), Asset: "USDT", CreatedAt: now.Add(-time.Hour)},
+	}
+	s.NoError(storage.ArchiveChains(s.Ctx, chains))
+
+	stored, err := storage.GetArchivedChain(s.Ctx, chains[0].Id)
+	s.NoError(err)
+	s.Equal(chains[0].Id, stored.Id)
+	// stored chain isn't affected by the caller
+	stored.Asset = "BTC"
+	stored, _ = storage.GetArchivedChain(s.Ctx, chains[0].Id)
+	s.Equal("USDT", stored.Asset)
+
+	stored, err = storage.GetArchivedChain(s.Ctx, kit.NewId())
+	s.NoError(err)
+	s.Nil(stored)
+
+	// the latest go first
+	rs, err := storage.GetArchivedChains(s.Ctx, now.Add(-3*time.Hour), now, 0)
+	s.NoError(err)
+	s.Len(rs, 3)
+	s.Equal(chains[2].Id, rs[0].Id)
+
+	rs, err = storage.GetArchivedChains(s.Ctx, now.Add(-3*time.Hour), now.Add(-time.Hour), 1)
+	s.NoError(err)
+	s.Len(rs, 1)
+	s.Equal(chains[1].Id, rs[0].Id)
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	kitAero "github.com/mikhailbolshakov/cryptocare/src/kit/storages/aerospike"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+)
+
+// MigrateSubscriptionsAeroToPg copies all the subscriptions (including inactive) from aerospike to postgres
+// migration is idempotent, so it's safe to run it many times
+// returns number of copied subscriptions
+func MigrateSubscriptionsAeroToPg(ctx context.Context, cfg *service.Config) (int, error) {
+	l := service.L().Cmp("subscription-migration").C(ctx).Mth("aero-to-pg")
+
+	// open postgres and apply migrations, so that the subscriptions table exists
+	pgStorage, err := pg.Open(cfg.Storages.Pg.Master, service.LF())
+	if err != nil {
+		return 0, err
+	}
+	defer pgStorage.Close()
+	if cfg.Storages.Pg.MigPath != "" {
+		db, _ := pgStorage.Instance.DB()
+		if err := pg.NewMigration(db, cfg.Storages.Pg.MigPath, service.LF()).Up(); err != nil {
+			return 0, err
+		}
+	}
+
+	// open aerospike
+	aero := kitAero.New()
+	if err := aero.Open(ctx, cfg.Storages.Aero, service.LF()); err != nil {
+		return 0, err
+	}
+	defer func() { _ = aero.Close(ctx) }()
+
+	return copySubscriptions(ctx, l, newSubscriptionStorage(aero, cfg.Storages.Aero), newSubscriptionPgStorage(pgStorage))
+}
+
+// copySubscriptions copies all the subscriptions from one storage to another
+func copySubscriptions(ctx context.Context, l log.CLogger, from, to domain.SubscriptionStorage) (int, error) {
+	subscriptions, err := from.SearchSubscriptions(ctx, &domain.SearchSubscriptionsRequest{WithInActive: true})
+	if err != nil {
+		return 0, err
+	}
+	for i, subs := range subscriptions {
+		if err := to.SaveSubscription(ctx, subs); err != nil {
+			return i, err
+		}
+		l.F(log.FF{"subscriptionId": subs.Id}).Dbg("copied")
+	}
+	l.F(log.FF{"count": len(subscriptions)}).Inf("done")
+	return len(subscriptions), nil
+}
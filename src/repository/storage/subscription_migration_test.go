@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type subscriptionMigrationTestSuite struct {
+	kitTestSuite.Suite
+}
+
+func (s *subscriptionMigrationTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+}
+
+func TestSubscriptionMigrationSuite(t *testing.T) {
+	suite.Run(t, new(subscriptionMigrationTestSuite))
+}
+
+func (s *subscriptionMigrationTestSuite) Test_Copy_Idempotent() {
+	from, to := NewSubscriptionMemStorage(), NewSubscriptionMemStorage()
+	active := &domain.Subscription{Id: kit.NewId(), UserId: kit.NewId(), IsActive: true, Filter: &domain.SubscriptionChainFilter{Assets: []string{"USDT"}}}
+	inactive := &domain.Subscription{Id: kit.NewId(), UserId: kit.NewId(), Filter: &domain.SubscriptionChainFilter{}}
+	s.NoError(from.SaveSubscription(s.Ctx, active))
+	s.NoError(from.SaveSubscription(s.Ctx, inactive))
+
+	for i := 0; i < 2; i++ {
+		count, err := copySubscriptions(s.Ctx, service.L(), from, to)
+		s.NoError(err)
+		s.Equal(2, count)
+	}
+
+	rs, err := to.SearchSubscriptions(s.Ctx, &domain.SearchSubscriptionsRequest{WithInActive: true})
+	s.NoError(err)
+	s.Len(rs, 2)
+	actual, err := to.GetSubscription(s.Ctx, inactive.Id)
+	s.NoError(err)
+	s.Equal(inactive, actual)
+}
@@ -17,6 +17,7 @@ const (
 
 type subscriptionDetails struct {
 	Notifications []*domain.SubscriptionNotification `json:"notifications,omitempty"` // Notifications notifications
+	Policy        *domain.SubscriptionDeliveryPolicy `json:"policy,omitempty"`        // Policy delivery policy
 }
 
 type subscriptionStorageImpl struct {
@@ -114,7 +115,10 @@ func (s *subscriptionStorageImpl) SearchSubscriptions(ctx context.Context, rq *d
 			if err != nil {
 				return nil, err
 			}
-			res = append(res, subs)
+			// the filter is kept as json, so opportunity criteria are checked here
+			if matchSubscriptionRequest(rq, subs) {
+				res = append(res, subs)
+			}
 		}
 	}
 	return res, nil
@@ -11,6 +11,7 @@ import (
 func (s *subscriptionStorageImpl) toSubscriptionAero(subs *domain.Subscription) aero.BinMap {
 	det, _ := json.Marshal(&subscriptionDetails{
 		Notifications: subs.Notifications,
+		Policy:        subs.Policy,
 	})
 	return aero.BinMap{
 		"user_id":        subs.UserId,
@@ -20,6 +21,8 @@ func (s *subscriptionStorageImpl) toSubscriptionAero(subs *domain.Subscription)
 		"flt_exchanges":  subs.Filter.Exchanges,
 		"flt_min_profit": subs.Filter.MinProfit,
 		"flt_max_depth":  subs.Filter.MaxDepth,
+		"flt_opp":        subs.Filter.Opportunities,
+		"flt_expr":       subs.Filter.Expression,
 		"details":        det,
 	}
 }
@@ -61,6 +64,14 @@ func (s *subscriptionStorageImpl) toSubscriptionDomain(ctx context.Context, subs
 	if err != nil {
 		return nil, err
 	}
+	r.Filter.Opportunities, err = aerospike.AsStrings(ctx, subs.Bins, "flt_opp")
+	if err != nil {
+		return nil, err
+	}
+	r.Filter.Expression, err = aerospike.AsString(ctx, subs.Bins, "flt_expr")
+	if err != nil {
+		return nil, err
+	}
 	details, err := aerospike.AsBytes(ctx, subs.Bins, "details")
 	if err != nil {
 		return nil, err
@@ -69,6 +80,7 @@ func (s *subscriptionStorageImpl) toSubscriptionDomain(ctx context.Context, subs
 		det := &subscriptionDetails{}
 		_ = json.Unmarshal(details, &det)
 		r.Notifications = det.Notifications
+		r.Policy = det.Policy
 	}
 	return r, nil
 }
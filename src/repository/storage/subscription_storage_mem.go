@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	memcache "github.com/mikhailbolshakov/cryptocare/src/kit/cache"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+)
+
+// subscriptionMemStorageImpl keeps subscriptions in memory, subscriptions never expire
+type subscriptionMemStorageImpl struct {
+	cache memcache.MemCache
+}
+
+func (s *subscriptionMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("subscription-mem-storage")
+}
+
+func NewSubscriptionMemStorage() domain.SubscriptionStorage {
+	return &subscriptionMemStorageImpl{
+		cache: memcache.NewMemCache(),
+	}
+}
+
+func (s *subscriptionMemStorageImpl) SaveSubscription(ctx context.Context, subs *domain.Subscription) error {
+	s.l().C(ctx).Mth("save").Trc()
+	s.cache.Set(subs.Id, s.copy(subs), memcache.Forever)
+	return nil
+}
+
+func (s *subscriptionMemStorageImpl) GetSubscription(ctx context.Context, subsId string) (*domain.Subscription, error) {
+	s.l().C(ctx).Mth("get").F(log.FF{"subscriptionId": subsId}).Trc()
+	v, ok := s.cache.Get(subsId)
+	if !ok {
+		return nil, nil
+	}
+	return s.copy(v.(*domain.Subscription)), nil
+}
+
+func (s *subscriptionMemStorageImpl) DeleteSubscription(ctx context.Context, subsId string) error {
+	s.l().Mth("delete").C(ctx).F(log.FF{"subsId": subsId}).Trc()
+	s.cache.Delete(subsId)
+	return nil
+}
+
+func (s *subscriptionMemStorageImpl) SearchSubscriptions(ctx context.Context, rq *domain.SearchSubscriptionsRequest) ([]*domain.Subscription, error) {
+	s.l().C(ctx).Mth("search").Trc()
+	var res []*domain.Subscription
+	for _, v := range s.cache.Items() {
+		subs := v.(*domain.Subscription)
+		if !matchSubscriptionRequest(rq, subs) {
+			continue
+		}
+		res = append(res, s.copy(subs))
+	}
+	return res, nil
+}
+
+// copy makes a deep copy of subscription, so that stored subscription isn't affected by changes made by caller
+func (s *subscriptionMemStorageImpl) copy(subs *domain.Subscription) *domain.Subscription {
+	r := *subs
+	if subs.Filter != nil {
+		flt := *subs.Filter
+		flt.Assets = copyStrings(flt.Assets)
+		flt.Methods = copyStrings(flt.Methods)
+		flt.Exchanges = copyStrings(flt.Exchanges)
+		flt.Opportunities = copyStrings(flt.Opportunities)
+		r.Filter = &flt
+	}
+	if subs.Notifications != nil {
+		r.Notifications = make([]*domain.SubscriptionNotification, len(subs.Notifications))
+		for i, n := range subs.Notifications {
+			r.Notifications[i] = s.copyNotification(n)
+		}
+	}
+	if subs.Policy != nil {
+		policy := *subs.Policy
+		if policy.QuietHours != nil {
+			quietHours := *policy.QuietHours
+			policy.QuietHours = &quietHours
+		}
+		if policy.Digest != nil {
+			digest := *policy.Digest
+			policy.Digest = &digest
+		}
+		r.Policy = &policy
+	}
+	return &r
+}
+
+func (s *subscriptionMemStorageImpl) copyNotification(n *domain.SubscriptionNotification) *domain.SubscriptionNotification {
+	if n == nil {
+		return nil
+	}
+	r := *n
+	if n.Telegram != nil {
+		telegram := *n.Telegram
+		r.Telegram = &telegram
+	}
+	if n.Email != nil {
+		email := *n.Email
+		email.To = copyStrings(email.To)
+		r.Email = &email
+	}
+	if n.Webhook != nil {
+		webhook := *n.Webhook
+		r.Webhook = &webhook
+	}
+	if n.Templates != nil {
+		templates := *n.Templates
+		for _, t := range []**domain.NotificationTemplate{&templates.Chain, &templates.Spread, &templates.Digest} {
+			if *t != nil {
+				tmpl := **t
+				*t = &tmpl
+			}
+		}
+		r.Templates = &templates
+	}
+	return &r
+}
+
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(s)), s...)
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type subscription struct {
+	pg.GormDto
+	Id       string  `gorm:"column:id"`
+	UserId   *string `gorm:"column:user_id"`
+	IsActive bool    `gorm:"column:is_active"`
+	Filter   string  `gorm:"column:filter"`
+	Details  string  `gorm:"column:details"`
+}
+
+// subscriptionPgStorageImpl keeps subscriptions in postgres
+type subscriptionPgStorageImpl struct {
+	pg *pg.Storage
+}
+
+func (s *subscriptionPgStorageImpl) l() log.CLogger {
+	return service.L().Cmp("subscription-pg-storage")
+}
+
+func newSubscriptionPgStorage(pg *pg.Storage) *subscriptionPgStorageImpl {
+	return &subscriptionPgStorageImpl{
+		pg: pg,
+	}
+}
+
+func (s *subscriptionPgStorageImpl) SaveSubscription(ctx context.Context, subs *domain.Subscription) error {
+	s.l().C(ctx).Mth("save").F(log.FF{"subscriptionId": subs.Id}).Trc()
+	dto := s.toSubscriptionDto(subs)
+	err := s.pg.Instance.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// lock the existing subscription to prevent concurrent updates
+		var existent []*subscription
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Unscoped().Where("id = ?", subs.Id).Limit(1).Find(&existent)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return tx.Create(dto).Error
+		}
+		// updating a deleted subscription restores it
+		return tx.Unscoped().Model(dto).Omit("created_at").Select("*").Updates(dto).Error
+	})
+	if err != nil {
+		return errors.ErrSubscriptionStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *subscriptionPgStorageImpl) GetSubscription(ctx context.Context, subsId string) (*domain.Subscription, error) {
+	s.l().C(ctx).Mth("get").F(log.FF{"subscriptionId": subsId}).Trc()
+	dto := &subscription{}
+	res := s.pg.Instance.WithContext(ctx).Where("id = ?", subsId).Limit(1).Find(dto)
+	if res.Error != nil {
+		return nil, errors.ErrSubscriptionStorageGet(res.Error, ctx)
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+	return s.toSubscriptionDomain(dto), nil
+}
+
+func (s *subscriptionPgStorageImpl) DeleteSubscription(ctx context.Context, subsId string) error {
+	s.l().Mth("delete").C(ctx).F(log.FF{"subsId": subsId}).Trc()
+	if err := s.pg.Instance.WithContext(ctx).Delete(&subscription{Id: subsId}).Error; err != nil {
+		return errors.ErrSubscriptionStorageDel(err, ctx)
+	}
+	return nil
+}
+
+func (s *subscriptionPgStorageImpl) SearchSubscriptions(ctx context.Context, rq *domain.SearchSubscriptionsRequest) ([]*domain.Subscription, error) {
+	s.l().C(ctx).Mth("search").Trc()
+	q := s.pg.Instance.WithContext(ctx).Model(&subscription{})
+	if !rq.WithInActive {
+		q = q.Where("is_active")
+	}
+	if rq.UserId != "" {
+		q = q.Where("user_id = ?", rq.UserId)
+	}
+	// opportunity criteria are checked against the filter kept as jsonb
+	switch rq.Opportunity {
+	case "":
+	case domain.OpportunityTypeChain:
+		// chains are notified if opportunities aren't specified
+		q = q.Where("(coalesce(jsonb_array_length(filter->'opportunities'), 0) = 0 or exists (select 1 from jsonb_array_elements_text(filter->'opportunities') o where o = ?))", rq.Opportunity)
+	default:
+		q = q.Where("exists (select 1 from jsonb_array_elements_text(filter->'opportunities') o where o = ?)", rq.Opportunity)
+	}
+	if len(rq.Assets) > 0 {
+		q = q.Where("(coalesce(jsonb_array_length(filter->'assets'), 0) = 0 or exists (select 1 from jsonb_array_elements_text(filter->'assets') a where a in ?))", rq.Assets)
+	}
+	if rq.ProfitShare != 0.0 {
+		q = q.Where("1 + coalesce((filter->>'minProfit')::float8, 0) * 0.01 <= ?", rq.ProfitShare)
+	}
+	var dtos []*subscription
+	if err := q.Order("created_at").Find(&dtos).Error; err != nil {
+		return nil, errors.ErrSubscriptionStorageSearch(err, ctx)
+	}
+	return s.toSubscriptionsDomain(dtos), nil
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+)
+
+func (s *subscriptionPgStorageImpl) toSubscriptionDto(subs *domain.Subscription) *subscription {
+	if subs == nil {
+		return nil
+	}
+	flt := subs.Filter
+	if flt == nil {
+		flt = &domain.SubscriptionChainFilter{}
+	}
+	fltBytes, _ := json.Marshal(flt)
+	detBytes, _ := json.Marshal(&subscriptionDetails{
+		Notifications: subs.Notifications,
+		Policy:        subs.Policy,
+	})
+	return &subscription{
+		Id:       subs.Id,
+		UserId:   pg.StringToNull(subs.UserId),
+		IsActive: subs.IsActive,
+		Filter:   string(fltBytes),
+		Details:  string(detBytes),
+	}
+}
+
+func (s *subscriptionPgStorageImpl) toSubscriptionDomain(dto *subscription) *domain.Subscription {
+	if dto == nil {
+		return nil
+	}
+	r := &domain.Subscription{
+		Id:       dto.Id,
+		UserId:   pg.NullToString(dto.UserId),
+		IsActive: dto.IsActive,
+		Filter:   &domain.SubscriptionChainFilter{},
+	}
+	_ = json.Unmarshal([]byte(dto.Filter), r.Filter)
+	det := &subscriptionDetails{}
+	_ = json.Unmarshal([]byte(dto.Details), det)
+	r.Notifications = det.Notifications
+	r.Policy = det.Policy
+	return r
+}
+
+func (s *subscriptionPgStorageImpl) toSubscriptionsDomain(dtos []*subscription) []*domain.Subscription {
+	var r []*domain.Subscription
+	for _, dto := range dtos {
+		r = append(r, s.toSubscriptionDomain(dto))
+	}
+	return r
+}
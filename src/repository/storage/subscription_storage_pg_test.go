@@ -0,0 +1,107 @@
+//go:build integration
+// +build integration
+
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type subscriptionPgStorageTestSuite struct {
+	kitTestSuite.Suite
+	storage domain.SubscriptionStorage
+	pg      *pg.Storage
+}
+
+func (s *subscriptionPgStorageTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+
+	// load config
+	cfg, err := service.LoadConfig()
+	if err != nil {
+		s.Fatal(err)
+	}
+
+	// open postgres and apply migrations
+	s.pg, err = pg.Open(cfg.Storages.Pg.Master, service.LF())
+	if err != nil {
+		s.Fatal(err)
+	}
+	db, _ := s.pg.Instance.DB()
+	if err := pg.NewMigration(db, cfg.Storages.Pg.MigPath, service.LF()).Up(); err != nil {
+		s.Fatal(err)
+	}
+	s.storage = newSubscriptionPgStorage(s.pg)
+}
+
+func (s *subscriptionPgStorageTestSuite) TearDownSuite() {
+	s.pg.Close()
+}
+
+func TestSubscriptionPgStorageSuite(t *testing.T) {
+	suite.Run(t, new(subscriptionPgStorageTestSuite))
+}
+
+func (s *subscriptionPgStorageTestSuite) Test_SearchByUser() {
+	userId := kit.NewId()
+	active := &domain.Subscription{Id: kit.NewId(), UserId: userId, IsActive: true, Filter: &domain.SubscriptionChainFilter{Assets: []string{"USDT"}}}
+	inactive := &domain.Subscription{Id: kit.NewId(), UserId: userId, Filter: &domain.SubscriptionChainFilter{}}
+	s.NoError(s.storage.SaveSubscription(s.Ctx, active))
+	s.NoError(s.storage.SaveSubscription(s.Ctx, inactive))
+
+	rs, err := s.storage.SearchSubscriptions(s.Ctx, &domain.SearchSubscriptionsRequest{UserId: userId})
+	s.NoError(err)
+	s.Len(rs, 1)
+	s.Equal(active, rs[0])
+
+	rs, err = s.storage.SearchSubscriptions(s.Ctx, &domain.SearchSubscriptionsRequest{UserId: userId, WithInActive: true})
+	s.NoError(err)
+	s.Len(rs, 2)
+}
+
+func (s *subscriptionPgStorageTestSuite) Test_SearchByOpportunity() {
+	userId := kit.NewId()
+	usdt := &domain.Subscription{Id: kit.NewId(), UserId: userId, IsActive: true, Filter: &domain.SubscriptionChainFilter{Assets: []string{"USDT"}, MinProfit: 2}}
+	anyAsset := &domain.Subscription{Id: kit.NewId(), UserId: userId, IsActive: true, Filter: &domain.SubscriptionChainFilter{}}
+	spreads := &domain.Subscription{Id: kit.NewId(), UserId: userId, IsActive: true, Filter: &domain.SubscriptionChainFilter{Opportunities: []string{domain.OpportunityTypeSpread}}}
+	for _, subs := range []*domain.Subscription{usdt, anyAsset, spreads} {
+		s.NoError(s.storage.SaveSubscription(s.Ctx, subs))
+	}
+	ids := func(rq *domain.SearchSubscriptionsRequest) []string {
+		rq.UserId = userId
+		ss, err := s.storage.SearchSubscriptions(s.Ctx, rq)
+		s.NoError(err)
+		var r []string
+		for _, subs := range ss {
+			r = append(r, subs.Id)
+		}
+		return r
+	}
+	s.ElementsMatch([]string{usdt.Id, anyAsset.Id}, ids(&domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeChain}))
+	s.ElementsMatch([]string{spreads.Id}, ids(&domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeSpread}))
+	s.ElementsMatch([]string{anyAsset.Id, spreads.Id}, ids(&domain.SearchSubscriptionsRequest{Assets: []string{"RUB"}}))
+	s.ElementsMatch([]string{usdt.Id, anyAsset.Id, spreads.Id}, ids(&domain.SearchSubscriptionsRequest{Assets: []string{"RUB", "USDT"}}))
+	// min profit isn't reached
+	s.ElementsMatch([]string{anyAsset.Id}, ids(&domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeChain, ProfitShare: 1.01}))
+	s.ElementsMatch([]string{usdt.Id, anyAsset.Id}, ids(&domain.SearchSubscriptionsRequest{Opportunity: domain.OpportunityTypeChain, ProfitShare: 1.02}))
+}
+
+func (s *subscriptionPgStorageTestSuite) Test_SaveDeleted_Restored() {
+	subs := &domain.Subscription{Id: kit.NewId(), IsActive: true, Filter: &domain.SubscriptionChainFilter{MinProfit: 1.5}}
+	s.NoError(s.storage.SaveSubscription(s.Ctx, subs))
+	s.NoError(s.storage.DeleteSubscription(s.Ctx, subs.Id))
+	actual, err := s.storage.GetSubscription(s.Ctx, subs.Id)
+	s.NoError(err)
+	s.Nil(actual)
+
+	s.NoError(s.storage.SaveSubscription(s.Ctx, subs))
+	actual, err = s.storage.GetSubscription(s.Ctx, subs.Id)
+	s.NoError(err)
+	s.Equal(subs, actual)
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+)
+
+// matchSubscriptionRequest checks if subscription satisfies request criteria
+// it's used by storages which can't query the filter, pg storage checks the same criteria in sql
+func matchSubscriptionRequest(rq *domain.SearchSubscriptionsRequest, subs *domain.Subscription) bool {
+	if !rq.WithInActive && !subs.IsActive {
+		return false
+	}
+	if rq.UserId != "" && subs.UserId != rq.UserId {
+		return false
+	}
+	filter := subs.Filter
+	if filter == nil {
+		filter = &domain.SubscriptionChainFilter{}
+	}
+	if rq.Opportunity != "" {
+		// chains are notified if opportunities aren't specified
+		if len(filter.Opportunities) == 0 && rq.Opportunity != domain.OpportunityTypeChain ||
+			len(filter.Opportunities) > 0 && !kit.Strings(filter.Opportunities).Contains(rq.Opportunity) {
+			return false
+		}
+	}
+	if len(rq.Assets) > 0 && len(filter.Assets) > 0 && len(kit.Strings(filter.Assets).Intersect(rq.Assets)) == 0 {
+		return false
+	}
+	if rq.ProfitShare != 0.0 && rq.ProfitShare < 1+filter.MinProfit*0.01 {
+		return false
+	}
+	return true
+}
@@ -53,11 +53,12 @@ func (s *subscriptionStorageTestSuite) getSubscription() *domain.Subscription {
 		UserId:   kit.NewRandString(),
 		IsActive: true,
 		Filter: &domain.SubscriptionChainFilter{
-			Assets:    []string{"RUB", "USD"},
-			Methods:   []string{"M1", "M2"},
-			Exchanges: []string{"binance", "huobi"},
-			MaxDepth:  5,
-			MinProfit: 0.5,
+			Assets:     []string{"RUB", "USD"},
+			Methods:    []string{"M1", "M2"},
+			Exchanges:  []string{"binance", "huobi"},
+			MaxDepth:   5,
+			MinProfit:  0.5,
+			Expression: `profit > 1 && !("bybit" in exchanges)`,
 		},
 		Notifications: []*domain.SubscriptionNotification{
 			{
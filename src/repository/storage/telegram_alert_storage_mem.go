@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sort"
+	"sync"
+	"time"
+)
+
+type telegramAlertKey struct {
+	chatId    int64
+	messageId int64
+}
+
+// telegramAlertMemStorageImpl keeps telegram alerts in memory
+type telegramAlertMemStorageImpl struct {
+	sync.Mutex
+	alerts map[telegramAlertKey]*domain.TelegramAlert
+}
+
+func (s *telegramAlertMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-alert-mem-storage")
+}
+
+func NewTelegramAlertMemStorage() domain.TelegramAlertStorage {
+	return &telegramAlertMemStorageImpl{
+		alerts: make(map[telegramAlertKey]*domain.TelegramAlert),
+	}
+}
+
+func (s *telegramAlertMemStorageImpl) SaveAlert(ctx context.Context, alert *domain.TelegramAlert) error {
+	s.l().C(ctx).Mth("save").F(log.FF{"chatId": alert.ChatId, "messageId": alert.MessageId}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	stored := *alert
+	s.alerts[telegramAlertKey{chatId: alert.ChatId, messageId: alert.MessageId}] = &stored
+	return nil
+}
+
+// find returns copies of alerts matching the predicate, the oldest go first
+func (s *telegramAlertMemStorageImpl) find(match func(a *domain.TelegramAlert) bool) []*domain.TelegramAlert {
+	s.Lock()
+	defer s.Unlock()
+	var r []*domain.TelegramAlert
+	for _, a := range s.alerts {
+		if match(a) {
+			c := *a
+			r = append(r, &c)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].CreatedAt.Before(r[j].CreatedAt) })
+	return r
+}
+
+func (s *telegramAlertMemStorageImpl) GetAlertsByChains(ctx context.Context, chainIds []string) ([]*domain.TelegramAlert, error) {
+	s.l().C(ctx).Mth("get-by-chains").Trc()
+	ids := make(map[string]struct{}, len(chainIds))
+	for _, id := range chainIds {
+		ids[id] = struct{}{}
+	}
+	return s.find(func(a *domain.TelegramAlert) bool {
+		_, ok := ids[a.ChainId]
+		return ok && a.Status == domain.TelegramAlertActive
+	}), nil
+}
+
+func (s *telegramAlertMemStorageImpl) GetActiveAlerts(ctx context.Context) ([]*domain.TelegramAlert, error) {
+	s.l().C(ctx).Mth("get-active").Trc()
+	return s.find(func(a *domain.TelegramAlert) bool {
+		return a.Status == domain.TelegramAlertActive
+	}), nil
+}
+
+func (s *telegramAlertMemStorageImpl) DeleteAlerts(ctx context.Context, updatedBefore time.Time) error {
+	s.l().C(ctx).Mth("delete").Trc()
+	s.Lock()
+	defer s.Unlock()
+	for k, a := range s.alerts {
+		if a.UpdatedAt.Before(updatedBefore) {
+			delete(s.alerts, k)
+		}
+	}
+	return nil
+}
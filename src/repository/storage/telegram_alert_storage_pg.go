@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type telegramAlert struct {
+	ChatId         int64     `gorm:"column:chat_id"`
+	MessageId      int64     `gorm:"column:message_id"`
+	ChainId        string    `gorm:"column:chain_id"`
+	DeliveryId     string    `gorm:"column:delivery_id"`
+	BotId          *string   `gorm:"column:bot_id"`
+	SubscriptionId string    `gorm:"column:subscription_id"`
+	UserId         *string   `gorm:"column:user_id"`
+	Profit         float64   `gorm:"column:profit"`
+	Status         string    `gorm:"column:status"`
+	Pending        bool      `gorm:"column:pending"`
+	Data           string    `gorm:"column:data"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at"`
+}
+
+func (telegramAlert) TableName() string {
+	return "telegram_alerts"
+}
+
+// telegramAlertPgStorageImpl keeps telegram alerts in postgres
+type telegramAlertPgStorageImpl struct {
+	pg *pg.Storage
+}
+
+func (s *telegramAlertPgStorageImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-alert-pg-storage")
+}
+
+func newTelegramAlertPgStorage(pg *pg.Storage) *telegramAlertPgStorageImpl {
+	return &telegramAlertPgStorageImpl{
+		pg: pg,
+	}
+}
+
+func (s *telegramAlertPgStorageImpl) SaveAlert(ctx context.Context, alert *domain.TelegramAlert) error {
+	s.l().C(ctx).Mth("save").F(log.FF{"chatId": alert.ChatId, "messageId": alert.MessageId}).Trc()
+	if err := s.pg.Instance.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(s.toAlertDto(alert)).Error; err != nil {
+		return errors.ErrTelegramAlertStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *telegramAlertPgStorageImpl) getAlerts(ctx context.Context, query string, args ...interface{}) ([]*domain.TelegramAlert, error) {
+	var dtos []*telegramAlert
+	if err := s.pg.Instance.WithContext(ctx).Where(query, args...).Order("created_at").Find(&dtos).Error; err != nil {
+		return nil, errors.ErrTelegramAlertStorageGet(err, ctx)
+	}
+	r := make([]*domain.TelegramAlert, 0, len(dtos))
+	for _, dto := range dtos {
+		r = append(r, s.toAlertDomain(dto))
+	}
+	return r, nil
+}
+
+func (s *telegramAlertPgStorageImpl) GetAlertsByChains(ctx context.Context, chainIds []string) ([]*domain.TelegramAlert, error) {
+	s.l().C(ctx).Mth("get-by-chains").Trc()
+	if len(chainIds) == 0 {
+		return nil, nil
+	}
+	return s.getAlerts(ctx, "chain_id in ? and status = ?", chainIds, domain.TelegramAlertActive)
+}
+
+func (s *telegramAlertPgStorageImpl) GetActiveAlerts(ctx context.Context) ([]*domain.TelegramAlert, error) {
+	s.l().C(ctx).Mth("get-active").Trc()
+	return s.getAlerts(ctx, "status = ?", domain.TelegramAlertActive)
+}
+
+func (s *telegramAlertPgStorageImpl) DeleteAlerts(ctx context.Context, updatedBefore time.Time) error {
+	s.l().C(ctx).Mth("delete").Trc()
+	if err := s.pg.Instance.WithContext(ctx).Where("updated_at < ?", updatedBefore).Delete(&telegramAlert{}).Error; err != nil {
+		return errors.ErrTelegramAlertStorageDel(err, ctx)
+	}
+	return nil
+}
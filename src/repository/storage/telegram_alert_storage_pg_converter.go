@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+)
+
+// telegramAlertData is a payload of the alert stored as json
+type telegramAlertData struct {
+	Notification *domain.SubscriptionNotification `json:"notification,omitempty"`
+	Chain        *domain.ProfitableChain          `json:"chain,omitempty"`
+}
+
+func (s *telegramAlertPgStorageImpl) toAlertDto(a *domain.TelegramAlert) *telegramAlert {
+	data, _ := json.Marshal(&telegramAlertData{
+		Notification: a.Notification,
+		Chain:        a.Chain,
+	})
+	return &telegramAlert{
+		ChatId:         a.ChatId,
+		MessageId:      a.MessageId,
+		ChainId:        a.ChainId,
+		DeliveryId:     a.DeliveryId,
+		BotId:          pg.StringToNull(a.BotId),
+		SubscriptionId: a.SubscriptionId,
+		UserId:         pg.StringToNull(a.UserId),
+		Profit:         a.Profit,
+		Status:         a.Status,
+		Pending:        a.Pending,
+		Data:           string(data),
+		CreatedAt:      a.CreatedAt,
+		UpdatedAt:      a.UpdatedAt,
+	}
+}
+
+func (s *telegramAlertPgStorageImpl) toAlertDomain(dto *telegramAlert) *domain.TelegramAlert {
+	data := &telegramAlertData{}
+	_ = json.Unmarshal([]byte(dto.Data), data)
+	return &domain.TelegramAlert{
+		ChatId:         dto.ChatId,
+		MessageId:      dto.MessageId,
+		ChainId:        dto.ChainId,
+		DeliveryId:     dto.DeliveryId,
+		BotId:          pg.NullToString(dto.BotId),
+		SubscriptionId: dto.SubscriptionId,
+		UserId:         pg.NullToString(dto.UserId),
+		Notification:   data.Notification,
+		Chain:          data.Chain,
+		Profit:         dto.Profit,
+		Status:         dto.Status,
+		Pending:        dto.Pending,
+		CreatedAt:      dto.CreatedAt,
+		UpdatedAt:      dto.UpdatedAt,
+	}
+}
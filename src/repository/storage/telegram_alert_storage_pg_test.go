@@ -0,0 +1,102 @@
+//go:build integration
+// +build integration
+
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+type telegramAlertPgStorageTestSuite struct {
+	kitTestSuite.Suite
+	storage domain.TelegramAlertStorage
+	pg      *pg.Storage
+}
+
+func (s *telegramAlertPgStorageTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+
+	// load config
+	cfg, err := service.LoadConfig()
+	if err != nil {
+		s.Fatal(err)
+	}
+
+	// open postgres and apply migrations
+	s.pg, err = pg.Open(cfg.Storages.Pg.Master, service.LF())
+	if err != nil {
+		s.Fatal(err)
+	}
+	db, _ := s.pg.Instance.DB()
+	if err := pg.NewMigration(db, cfg.Storages.Pg.MigPath, service.LF()).Up(); err != nil {
+		s.Fatal(err)
+	}
+	s.storage = newTelegramAlertPgStorage(s.pg)
+}
+
+func (s *telegramAlertPgStorageTestSuite) TearDownSuite() {
+	s.pg.Close()
+}
+
+func TestTelegramAlertPgStorageSuite(t *testing.T) {
+	suite.Run(t, new(telegramAlertPgStorageTestSuite))
+}
+
+func (s *telegramAlertPgStorageTestSuite) Test_Alert() {
+	chainId := kit.NewId()
+	now := kit.Now()
+	alert := &domain.TelegramAlert{
+		ChatId:         -rand.Int63(),
+		MessageId:      rand.Int63(),
+		ChainId:        chainId,
+		DeliveryId:     kit.NewId(),
+		SubscriptionId: kit.NewId(),
+		UserId:         kit.NewId(),
+		Notification:   &domain.SubscriptionNotification{Id: kit.NewId(), Channel: domain.SubscriptionNotificationChannelTelegram},
+		Chain:          &domain.ProfitableChain{Id: chainId, Asset: "USDT", ProfitShare: 1.05},
+		Profit:         1.05,
+		Status:         domain.TelegramAlertActive,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	s.NoError(s.storage.SaveAlert(s.Ctx, alert))
+	found, err := s.storage.GetAlertsByChains(s.Ctx, []string{chainId})
+	s.NoError(err)
+	s.Len(found, 1)
+	s.Equal(alert.MessageId, found[0].MessageId)
+	s.Equal(alert.Notification.Id, found[0].Notification.Id)
+	s.Equal(1.05, found[0].Chain.ProfitShare)
+
+	// alert is updated
+	alert.Pending = true
+	alert.Chain.ProfitShare = 1.03
+	s.NoError(s.storage.SaveAlert(s.Ctx, alert))
+	active, err := s.storage.GetActiveAlerts(s.Ctx)
+	s.NoError(err)
+	var activeAlert *domain.TelegramAlert
+	for _, a := range active {
+		if a.ChainId == chainId {
+			activeAlert = a
+		}
+	}
+	s.NotNil(activeAlert)
+	s.True(activeAlert.Pending)
+	s.Equal(1.03, activeAlert.Chain.ProfitShare)
+
+	// expired alerts aren't retrieved by chains
+	alert.Status = domain.TelegramAlertExpired
+	s.NoError(s.storage.SaveAlert(s.Ctx, alert))
+	found, err = s.storage.GetAlertsByChains(s.Ctx, []string{chainId})
+	s.NoError(err)
+	s.Empty(found)
+
+	s.NoError(s.storage.DeleteAlerts(s.Ctx, now.Add(time.Second)))
+}
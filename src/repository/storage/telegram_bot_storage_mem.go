@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sort"
+	"sync"
+)
+
+// telegramBotMemStorageImpl keeps telegram bots in memory
+type telegramBotMemStorageImpl struct {
+	sync.Mutex
+	bots map[string]*domain.TelegramBotAccount
+}
+
+func (s *telegramBotMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-bot-mem-storage")
+}
+
+func NewTelegramBotMemStorage() domain.TelegramBotAccountStorage {
+	return &telegramBotMemStorageImpl{
+		bots: make(map[string]*domain.TelegramBotAccount),
+	}
+}
+
+func (s *telegramBotMemStorageImpl) SaveBot(ctx context.Context, bot *domain.TelegramBotAccount) error {
+	s.l().C(ctx).Mth("save").F(log.FF{"botId": bot.Id}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	stored := *bot
+	s.bots[bot.Id] = &stored
+	return nil
+}
+
+func (s *telegramBotMemStorageImpl) GetBot(ctx context.Context, botId string) (*domain.TelegramBotAccount, error) {
+	s.l().C(ctx).Mth("get").F(log.FF{"botId": botId}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	if b, ok := s.bots[botId]; ok {
+		c := *b
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (s *telegramBotMemStorageImpl) GetBots(ctx context.Context) ([]*domain.TelegramBotAccount, error) {
+	s.l().C(ctx).Mth("get-all").Trc()
+	s.Lock()
+	defer s.Unlock()
+	r := make([]*domain.TelegramBotAccount, 0, len(s.bots))
+	for _, b := range s.bots {
+		c := *b
+		r = append(r, &c)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].CreatedAt.Before(r[j].CreatedAt) })
+	return r, nil
+}
+
+func (s *telegramBotMemStorageImpl) DeleteBot(ctx context.Context, botId string) error {
+	s.l().C(ctx).Mth("delete").F(log.FF{"botId": botId}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	delete(s.bots, botId)
+	return nil
+}
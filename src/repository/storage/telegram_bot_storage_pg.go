@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type telegramBot struct {
+	Id          string     `gorm:"column:id"`
+	Name        string     `gorm:"column:name"`
+	Username    *string    `gorm:"column:username"`
+	Token       string     `gorm:"column:token"`
+	SecondaryId *string    `gorm:"column:secondary_id"`
+	RatePerSec  float64    `gorm:"column:rate_per_sec"`
+	IsActive    bool       `gorm:"column:is_active"`
+	Health      string     `gorm:"column:health"`
+	LastError   *string    `gorm:"column:last_error"`
+	FailedAt    *time.Time `gorm:"column:failed_at"`
+	Data        string     `gorm:"column:data"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at"`
+}
+
+func (telegramBot) TableName() string {
+	return "telegram_bots"
+}
+
+// telegramBotPgStorageImpl keeps telegram bots in postgres
+type telegramBotPgStorageImpl struct {
+	pg *pg.Storage
+}
+
+func (s *telegramBotPgStorageImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-bot-pg-storage")
+}
+
+func newTelegramBotPgStorage(pg *pg.Storage) *telegramBotPgStorageImpl {
+	return &telegramBotPgStorageImpl{
+		pg: pg,
+	}
+}
+
+func (s *telegramBotPgStorageImpl) SaveBot(ctx context.Context, bot *domain.TelegramBotAccount) error {
+	s.l().C(ctx).Mth("save").F(log.FF{"botId": bot.Id}).Trc()
+	if err := s.pg.Instance.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(s.toBotDto(bot)).Error; err != nil {
+		return errors.ErrTelegramBotStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *telegramBotPgStorageImpl) GetBot(ctx context.Context, botId string) (*domain.TelegramBotAccount, error) {
+	s.l().C(ctx).Mth("get").F(log.FF{"botId": botId}).Trc()
+	var dtos []*telegramBot
+	if err := s.pg.Instance.WithContext(ctx).Where("id = ?", botId).Limit(1).Find(&dtos).Error; err != nil {
+		return nil, errors.ErrTelegramBotStorageGet(err, ctx)
+	}
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	return s.toBotDomain(dtos[0]), nil
+}
+
+func (s *telegramBotPgStorageImpl) GetBots(ctx context.Context) ([]*domain.TelegramBotAccount, error) {
+	s.l().C(ctx).Mth("get-all").Trc()
+	var dtos []*telegramBot
+	if err := s.pg.Instance.WithContext(ctx).Order("created_at").Find(&dtos).Error; err != nil {
+		return nil, errors.ErrTelegramBotStorageGet(err, ctx)
+	}
+	r := make([]*domain.TelegramBotAccount, 0, len(dtos))
+	for _, dto := range dtos {
+		r = append(r, s.toBotDomain(dto))
+	}
+	return r, nil
+}
+
+func (s *telegramBotPgStorageImpl) DeleteBot(ctx context.Context, botId string) error {
+	s.l().C(ctx).Mth("delete").F(log.FF{"botId": botId}).Trc()
+	if err := s.pg.Instance.WithContext(ctx).Where("id = ?", botId).Delete(&telegramBot{}).Error; err != nil {
+		return errors.ErrTelegramBotStorageDel(err, ctx)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"encoding/json"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+)
+
+// telegramBotData is a payload of the bot stored as json
+type telegramBotData struct {
+	UserIds []string `json:"userIds,omitempty"`
+}
+
+func (s *telegramBotPgStorageImpl) toBotDto(b *domain.TelegramBotAccount) *telegramBot {
+	data, _ := json.Marshal(&telegramBotData{
+		UserIds: b.UserIds,
+	})
+	return &telegramBot{
+		Id:          b.Id,
+		Name:        b.Name,
+		Username:    pg.StringToNull(b.Username),
+		Token:       b.EncryptedToken,
+		SecondaryId: pg.StringToNull(b.SecondaryId),
+		RatePerSec:  b.RatePerSec,
+		IsActive:    b.IsActive,
+		Health:      b.Health,
+		LastError:   pg.StringToNull(b.LastError),
+		FailedAt:    b.FailedAt,
+		Data:        string(data),
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+	}
+}
+
+func (s *telegramBotPgStorageImpl) toBotDomain(dto *telegramBot) *domain.TelegramBotAccount {
+	data := &telegramBotData{}
+	_ = json.Unmarshal([]byte(dto.Data), data)
+	return &domain.TelegramBotAccount{
+		Id:             dto.Id,
+		Name:           dto.Name,
+		Username:       pg.NullToString(dto.Username),
+		EncryptedToken: dto.Token,
+		UserIds:        data.UserIds,
+		SecondaryId:    pg.NullToString(dto.SecondaryId),
+		RatePerSec:     dto.RatePerSec,
+		IsActive:       dto.IsActive,
+		Health:         dto.Health,
+		LastError:      pg.NullToString(dto.LastError),
+		FailedAt:       dto.FailedAt,
+		CreatedAt:      dto.CreatedAt,
+		UpdatedAt:      dto.UpdatedAt,
+	}
+}
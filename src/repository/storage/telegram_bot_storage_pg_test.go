@@ -0,0 +1,93 @@
+//go:build integration
+// +build integration
+
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type telegramBotPgStorageTestSuite struct {
+	kitTestSuite.Suite
+	storage domain.TelegramBotAccountStorage
+	pg      *pg.Storage
+}
+
+func (s *telegramBotPgStorageTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+
+	// load config
+	cfg, err := service.LoadConfig()
+	if err != nil {
+		s.Fatal(err)
+	}
+
+	// open postgres and apply migrations
+	s.pg, err = pg.Open(cfg.Storages.Pg.Master, service.LF())
+	if err != nil {
+		s.Fatal(err)
+	}
+	db, _ := s.pg.Instance.DB()
+	if err := pg.NewMigration(db, cfg.Storages.Pg.MigPath, service.LF()).Up(); err != nil {
+		s.Fatal(err)
+	}
+	s.storage = newTelegramBotPgStorage(s.pg)
+}
+
+func (s *telegramBotPgStorageTestSuite) TearDownSuite() {
+	s.pg.Close()
+}
+
+func TestTelegramBotPgStorageSuite(t *testing.T) {
+	suite.Run(t, new(telegramBotPgStorageTestSuite))
+}
+
+func (s *telegramBotPgStorageTestSuite) Test_Bot() {
+	now := kit.Now()
+	bot := &domain.TelegramBotAccount{
+		Id:             kit.NewId(),
+		Name:           "partner",
+		Username:       "partner_bot",
+		EncryptedToken: "encrypted",
+		UserIds:        []string{kit.NewId(), kit.NewId()},
+		RatePerSec:     10,
+		IsActive:       true,
+		Health:         domain.TelegramBotHealthy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	s.NoError(s.storage.SaveBot(s.Ctx, bot))
+	found, err := s.storage.GetBot(s.Ctx, bot.Id)
+	s.NoError(err)
+	s.Equal(bot.UserIds, found.UserIds)
+	s.Equal("encrypted", found.EncryptedToken)
+	s.Equal("partner_bot", found.Username)
+	s.Nil(found.FailedAt)
+
+	// health is updated
+	bot.Health, bot.LastError, bot.FailedAt = domain.TelegramBotUnhealthy, "timeout", &now
+	s.NoError(s.storage.SaveBot(s.Ctx, bot))
+	all, err := s.storage.GetBots(s.Ctx)
+	s.NoError(err)
+	var updated *domain.TelegramBotAccount
+	for _, b := range all {
+		if b.Id == bot.Id {
+			updated = b
+		}
+	}
+	s.NotNil(updated)
+	s.Equal(domain.TelegramBotUnhealthy, updated.Health)
+	s.Equal("timeout", updated.LastError)
+	s.NotNil(updated.FailedAt)
+
+	s.NoError(s.storage.DeleteBot(s.Ctx, bot.Id))
+	found, err = s.storage.GetBot(s.Ctx, bot.Id)
+	s.NoError(err)
+	s.Nil(found)
+}
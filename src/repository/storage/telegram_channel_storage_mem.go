@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sort"
+	"sync"
+)
+
+type telegramChannelKey struct {
+	userId string
+	chatId int64
+}
+
+// telegramChannelMemStorageImpl keeps telegram channel verifications in memory
+type telegramChannelMemStorageImpl struct {
+	sync.Mutex
+	verifications map[telegramChannelKey]*domain.TelegramChannelVerification
+}
+
+func (s *telegramChannelMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-channel-mem-storage")
+}
+
+func NewTelegramChannelMemStorage() domain.TelegramChannelStorage {
+	return &telegramChannelMemStorageImpl{
+		verifications: make(map[telegramChannelKey]*domain.TelegramChannelVerification),
+	}
+}
+
+func (s *telegramChannelMemStorageImpl) SaveChannelVerification(ctx context.Context, v *domain.TelegramChannelVerification) error {
+	s.l().C(ctx).Mth("save").F(log.FF{"userId": v.UserId, "chatId": v.ChatId}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	stored := *v
+	s.verifications[telegramChannelKey{userId: v.UserId, chatId: v.ChatId}] = &stored
+	return nil
+}
+
+func (s *telegramChannelMemStorageImpl) GetChannelVerification(ctx context.Context, userId string, chatId int64) (*domain.TelegramChannelVerification, error) {
+	s.l().C(ctx).Mth("get").Trc()
+	s.Lock()
+	defer s.Unlock()
+	if v, ok := s.verifications[telegramChannelKey{userId: userId, chatId: chatId}]; ok {
+		r := *v
+		return &r, nil
+	}
+	return nil, nil
+}
+
+func (s *telegramChannelMemStorageImpl) GetChannelVerificationByCode(ctx context.Context, chatId int64, code string) (*domain.TelegramChannelVerification, error) {
+	s.l().C(ctx).Mth("get-by-code").Trc()
+	s.Lock()
+	defer s.Unlock()
+	for _, v := range s.verifications {
+		if v.ChatId == chatId && v.Code == code {
+			r := *v
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *telegramChannelMemStorageImpl) GetChannelVerifications(ctx context.Context, userId string) ([]*domain.TelegramChannelVerification, error) {
+	s.l().C(ctx).Mth("get-by-user").Trc()
+	s.Lock()
+	defer s.Unlock()
+	var r []*domain.TelegramChannelVerification
+	for _, v := range s.verifications {
+		if v.UserId == userId {
+			c := *v
+			r = append(r, &c)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].CreatedAt.Before(r[j].CreatedAt) })
+	return r, nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type telegramChannelVerification struct {
+	UserId     string     `gorm:"column:user_id"`
+	ChatId     int64      `gorm:"column:chat_id"`
+	Code       *string    `gorm:"column:code"`
+	Status     string     `gorm:"column:status"`
+	Method     *string    `gorm:"column:method"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at"`
+	VerifiedAt *time.Time `gorm:"column:verified_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+}
+
+func (telegramChannelVerification) TableName() string {
+	return "telegram_channel_verifications"
+}
+
+// telegramChannelPgStorageImpl keeps telegram channel verifications in postgres
+type telegramChannelPgStorageImpl struct {
+	pg *pg.Storage
+}
+
+func (s *telegramChannelPgStorageImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-channel-pg-storage")
+}
+
+func newTelegramChannelPgStorage(pg *pg.Storage) *telegramChannelPgStorageImpl {
+	return &telegramChannelPgStorageImpl{
+		pg: pg,
+	}
+}
+
+func (s *telegramChannelPgStorageImpl) SaveChannelVerification(ctx context.Context, v *domain.TelegramChannelVerification) error {
+	s.l().C(ctx).Mth("save").F(log.FF{"userId": v.UserId, "chatId": v.ChatId}).Trc()
+	if err := s.pg.Instance.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(s.toVerificationDto(v)).Error; err != nil {
+		return errors.ErrTelegramChannelStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *telegramChannelPgStorageImpl) getVerification(ctx context.Context, query string, args ...interface{}) (*domain.TelegramChannelVerification, error) {
+	var dtos []*telegramChannelVerification
+	if err := s.pg.Instance.WithContext(ctx).Where(query, args...).Limit(1).Find(&dtos).Error; err != nil {
+		return nil, errors.ErrTelegramChannelStorageGet(err, ctx)
+	}
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	return s.toVerificationDomain(dtos[0]), nil
+}
+
+func (s *telegramChannelPgStorageImpl) GetChannelVerification(ctx context.Context, userId string, chatId int64) (*domain.TelegramChannelVerification, error) {
+	s.l().C(ctx).Mth("get").Trc()
+	return s.getVerification(ctx, "user_id = ? and chat_id = ?", userId, chatId)
+}
+
+func (s *telegramChannelPgStorageImpl) GetChannelVerificationByCode(ctx context.Context, chatId int64, code string) (*domain.TelegramChannelVerification, error) {
+	s.l().C(ctx).Mth("get-by-code").Trc()
+	return s.getVerification(ctx, "chat_id = ? and code = ?", chatId, code)
+}
+
+func (s *telegramChannelPgStorageImpl) GetChannelVerifications(ctx context.Context, userId string) ([]*domain.TelegramChannelVerification, error) {
+	s.l().C(ctx).Mth("get-by-user").Trc()
+	var dtos []*telegramChannelVerification
+	if err := s.pg.Instance.WithContext(ctx).Where("user_id = ?", userId).Order("created_at").Find(&dtos).Error; err != nil {
+		return nil, errors.ErrTelegramChannelStorageGet(err, ctx)
+	}
+	var r []*domain.TelegramChannelVerification
+	for _, dto := range dtos {
+		r = append(r, s.toVerificationDomain(dto))
+	}
+	return r, nil
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+)
+
+func (s *telegramChannelPgStorageImpl) toVerificationDto(v *domain.TelegramChannelVerification) *telegramChannelVerification {
+	return &telegramChannelVerification{
+		UserId:     v.UserId,
+		ChatId:     v.ChatId,
+		Code:       pg.StringToNull(v.Code),
+		Status:     v.Status,
+		Method:     pg.StringToNull(v.Method),
+		ExpiresAt:  v.ExpiresAt,
+		VerifiedAt: v.VerifiedAt,
+		CreatedAt:  v.CreatedAt,
+	}
+}
+
+func (s *telegramChannelPgStorageImpl) toVerificationDomain(dto *telegramChannelVerification) *domain.TelegramChannelVerification {
+	return &domain.TelegramChannelVerification{
+		UserId:     dto.UserId,
+		ChatId:     dto.ChatId,
+		Code:       pg.NullToString(dto.Code),
+		Status:     dto.Status,
+		Method:     pg.NullToString(dto.Method),
+		ExpiresAt:  dto.ExpiresAt,
+		VerifiedAt: dto.VerifiedAt,
+		CreatedAt:  dto.CreatedAt,
+	}
+}
@@ -0,0 +1,86 @@
+//go:build integration
+// +build integration
+
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+type telegramChannelPgStorageTestSuite struct {
+	kitTestSuite.Suite
+	storage domain.TelegramChannelStorage
+	pg      *pg.Storage
+}
+
+func (s *telegramChannelPgStorageTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+
+	// load config
+	cfg, err := service.LoadConfig()
+	if err != nil {
+		s.Fatal(err)
+	}
+
+	// open postgres and apply migrations
+	s.pg, err = pg.Open(cfg.Storages.Pg.Master, service.LF())
+	if err != nil {
+		s.Fatal(err)
+	}
+	db, _ := s.pg.Instance.DB()
+	if err := pg.NewMigration(db, cfg.Storages.Pg.MigPath, service.LF()).Up(); err != nil {
+		s.Fatal(err)
+	}
+	s.storage = newTelegramChannelPgStorage(s.pg)
+}
+
+func (s *telegramChannelPgStorageTestSuite) TearDownSuite() {
+	s.pg.Close()
+}
+
+func TestTelegramChannelPgStorageSuite(t *testing.T) {
+	suite.Run(t, new(telegramChannelPgStorageTestSuite))
+}
+
+func (s *telegramChannelPgStorageTestSuite) Test_Verification() {
+	chatId := -rand.Int63()
+	v := &domain.TelegramChannelVerification{
+		UserId:    kit.NewId(),
+		ChatId:    chatId,
+		Code:      kit.NewRandString(),
+		Status:    domain.TelegramChannelVerificationPending,
+		ExpiresAt: kit.Now().Add(time.Minute),
+		CreatedAt: kit.Now(),
+	}
+	s.NoError(s.storage.SaveChannelVerification(s.Ctx, v))
+	found, err := s.storage.GetChannelVerificationByCode(s.Ctx, chatId, v.Code)
+	s.NoError(err)
+	s.Equal(v.UserId, found.UserId)
+	s.Equal(domain.TelegramChannelVerificationPending, found.Status)
+
+	// verification is updated
+	verifiedAt := kit.Now()
+	v.Status, v.Method, v.VerifiedAt = domain.TelegramChannelVerificationVerified, domain.TelegramChannelVerifiedByCode, &verifiedAt
+	s.NoError(s.storage.SaveChannelVerification(s.Ctx, v))
+	found, err = s.storage.GetChannelVerification(s.Ctx, v.UserId, chatId)
+	s.NoError(err)
+	s.Equal(domain.TelegramChannelVerificationVerified, found.Status)
+	s.Equal(domain.TelegramChannelVerifiedByCode, found.Method)
+	s.NotNil(found.VerifiedAt)
+
+	all, err := s.storage.GetChannelVerifications(s.Ctx, v.UserId)
+	s.NoError(err)
+	s.Len(all, 1)
+
+	found, err = s.storage.GetChannelVerification(s.Ctx, kit.NewId(), chatId)
+	s.NoError(err)
+	s.Nil(found)
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sync"
+)
+
+// telegramLinkMemStorageImpl keeps telegram links in memory
+type telegramLinkMemStorageImpl struct {
+	sync.Mutex
+	codes map[string]*domain.TelegramLinkCode
+	links map[string]*domain.TelegramLink
+}
+
+func (s *telegramLinkMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-link-mem-storage")
+}
+
+func NewTelegramLinkMemStorage() domain.TelegramLinkStorage {
+	return &telegramLinkMemStorageImpl{
+		codes: make(map[string]*domain.TelegramLinkCode),
+		links: make(map[string]*domain.TelegramLink),
+	}
+}
+
+func (s *telegramLinkMemStorageImpl) SaveLinkCode(ctx context.Context, code *domain.TelegramLinkCode) error {
+	s.l().C(ctx).Mth("save-code").Trc()
+	s.Lock()
+	defer s.Unlock()
+	stored := *code
+	s.codes[code.Code] = &stored
+	return nil
+}
+
+func (s *telegramLinkMemStorageImpl) TakeLinkCode(ctx context.Context, code string) (*domain.TelegramLinkCode, error) {
+	s.l().C(ctx).Mth("take-code").Trc()
+	s.Lock()
+	defer s.Unlock()
+	stored, ok := s.codes[code]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.codes, code)
+	return stored, nil
+}
+
+func (s *telegramLinkMemStorageImpl) SaveLink(ctx context.Context, link *domain.TelegramLink) error {
+	s.l().C(ctx).Mth("save-link").F(log.FF{"userId": link.UserId}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	for userId, l := range s.links {
+		if l.TelegramUserId == link.TelegramUserId {
+			delete(s.links, userId)
+		}
+	}
+	stored := *link
+	s.links[link.UserId] = &stored
+	return nil
+}
+
+func (s *telegramLinkMemStorageImpl) GetLinkByUser(ctx context.Context, userId string) (*domain.TelegramLink, error) {
+	s.l().C(ctx).Mth("get-by-user").Trc()
+	s.Lock()
+	defer s.Unlock()
+	if l, ok := s.links[userId]; ok {
+		r := *l
+		return &r, nil
+	}
+	return nil, nil
+}
+
+func (s *telegramLinkMemStorageImpl) GetLinkByTelegramUser(ctx context.Context, telegramUserId int64) (*domain.TelegramLink, error) {
+	s.l().C(ctx).Mth("get-by-telegram-user").Trc()
+	s.Lock()
+	defer s.Unlock()
+	for _, l := range s.links {
+		if l.TelegramUserId == telegramUserId {
+			r := *l
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *telegramLinkMemStorageImpl) DeleteLink(ctx context.Context, userId string) error {
+	s.l().C(ctx).Mth("delete-link").Trc()
+	s.Lock()
+	defer s.Unlock()
+	delete(s.links, userId)
+	return nil
+}
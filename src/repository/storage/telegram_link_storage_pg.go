@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/errors"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type telegramLinkCode struct {
+	Code      string    `gorm:"column:code"`
+	UserId    string    `gorm:"column:user_id"`
+	Url       *string   `gorm:"column:url"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+}
+
+func (telegramLinkCode) TableName() string {
+	return "telegram_link_codes"
+}
+
+type telegramLink struct {
+	UserId         string    `gorm:"column:user_id"`
+	TelegramUserId int64     `gorm:"column:telegram_user_id"`
+	ChatId         int64     `gorm:"column:chat_id"`
+	Username       *string   `gorm:"column:username"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+}
+
+func (telegramLink) TableName() string {
+	return "telegram_links"
+}
+
+// telegramLinkPgStorageImpl keeps telegram links in postgres
+type telegramLinkPgStorageImpl struct {
+	pg *pg.Storage
+}
+
+func (s *telegramLinkPgStorageImpl) l() log.CLogger {
+	return service.L().Cmp("telegram-link-pg-storage")
+}
+
+func newTelegramLinkPgStorage(pg *pg.Storage) *telegramLinkPgStorageImpl {
+	return &telegramLinkPgStorageImpl{
+		pg: pg,
+	}
+}
+
+func (s *telegramLinkPgStorageImpl) SaveLinkCode(ctx context.Context, code *domain.TelegramLinkCode) error {
+	s.l().C(ctx).Mth("save-code").Trc()
+	if err := s.pg.Instance.WithContext(ctx).Create(s.toLinkCodeDto(code)).Error; err != nil {
+		return errors.ErrTelegramLinkStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *telegramLinkPgStorageImpl) TakeLinkCode(ctx context.Context, code string) (*domain.TelegramLinkCode, error) {
+	s.l().C(ctx).Mth("take-code").Trc()
+	var dtos []*telegramLinkCode
+	// code is deleted returning the row, so it can be taken once
+	if err := s.pg.Instance.WithContext(ctx).Clauses(clause.Returning{}).Where("code = ?", code).Delete(&dtos).Error; err != nil {
+		return nil, errors.ErrTelegramLinkStorageDel(err, ctx)
+	}
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	return s.toLinkCodeDomain(dtos[0]), nil
+}
+
+func (s *telegramLinkPgStorageImpl) SaveLink(ctx context.Context, link *domain.TelegramLink) error {
+	s.l().C(ctx).Mth("save-link").F(log.FF{"userId": link.UserId}).Trc()
+	err := s.pg.Instance.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// telegram account is linked to one user only
+		if err := tx.Where("telegram_user_id = ? and user_id != ?", link.TelegramUserId, link.UserId).Delete(&telegramLink{}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(s.toLinkDto(link)).Error
+	})
+	if err != nil {
+		return errors.ErrTelegramLinkStoragePut(err, ctx)
+	}
+	return nil
+}
+
+func (s *telegramLinkPgStorageImpl) getLink(ctx context.Context, query string, arg interface{}) (*domain.TelegramLink, error) {
+	var dtos []*telegramLink
+	if err := s.pg.Instance.WithContext(ctx).Where(query, arg).Limit(1).Find(&dtos).Error; err != nil {
+		return nil, errors.ErrTelegramLinkStorageGet(err, ctx)
+	}
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+	return s.toLinkDomain(dtos[0]), nil
+}
+
+func (s *telegramLinkPgStorageImpl) GetLinkByUser(ctx context.Context, userId string) (*domain.TelegramLink, error) {
+	s.l().C(ctx).Mth("get-by-user").Trc()
+	return s.getLink(ctx, "user_id = ?", userId)
+}
+
+func (s *telegramLinkPgStorageImpl) GetLinkByTelegramUser(ctx context.Context, telegramUserId int64) (*domain.TelegramLink, error) {
+	s.l().C(ctx).Mth("get-by-telegram-user").Trc()
+	return s.getLink(ctx, "telegram_user_id = ?", telegramUserId)
+}
+
+func (s *telegramLinkPgStorageImpl) DeleteLink(ctx context.Context, userId string) error {
+	s.l().C(ctx).Mth("delete-link").Trc()
+	if err := s.pg.Instance.WithContext(ctx).Where("user_id = ?", userId).Delete(&telegramLink{}).Error; err != nil {
+		return errors.ErrTelegramLinkStorageDel(err, ctx)
+	}
+	return nil
+}
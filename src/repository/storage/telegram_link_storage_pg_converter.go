@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+)
+
+func (s *telegramLinkPgStorageImpl) toLinkCodeDto(c *domain.TelegramLinkCode) *telegramLinkCode {
+	return &telegramLinkCode{
+		Code:      c.Code,
+		UserId:    c.UserId,
+		Url:       pg.StringToNull(c.Url),
+		ExpiresAt: c.ExpiresAt,
+	}
+}
+
+func (s *telegramLinkPgStorageImpl) toLinkCodeDomain(dto *telegramLinkCode) *domain.TelegramLinkCode {
+	return &domain.TelegramLinkCode{
+		Code:      dto.Code,
+		UserId:    dto.UserId,
+		Url:       pg.NullToString(dto.Url),
+		ExpiresAt: dto.ExpiresAt,
+	}
+}
+
+func (s *telegramLinkPgStorageImpl) toLinkDto(l *domain.TelegramLink) *telegramLink {
+	return &telegramLink{
+		UserId:         l.UserId,
+		TelegramUserId: l.TelegramUserId,
+		ChatId:         l.ChatId,
+		Username:       pg.StringToNull(l.Username),
+		CreatedAt:      l.CreatedAt,
+	}
+}
+
+func (s *telegramLinkPgStorageImpl) toLinkDomain(dto *telegramLink) *domain.TelegramLink {
+	return &domain.TelegramLink{
+		UserId:         dto.UserId,
+		TelegramUserId: dto.TelegramUserId,
+		ChatId:         dto.ChatId,
+		Username:       pg.NullToString(dto.Username),
+		CreatedAt:      dto.CreatedAt,
+	}
+}
@@ -0,0 +1,85 @@
+//go:build integration
+// +build integration
+
+package storage
+
+import (
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	kitTestSuite "github.com/mikhailbolshakov/cryptocare/src/kit/test/suite"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"github.com/stretchr/testify/suite"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+type telegramLinkPgStorageTestSuite struct {
+	kitTestSuite.Suite
+	storage domain.TelegramLinkStorage
+	pg      *pg.Storage
+}
+
+func (s *telegramLinkPgStorageTestSuite) SetupSuite() {
+	s.Suite.Init(service.LF())
+
+	// load config
+	cfg, err := service.LoadConfig()
+	if err != nil {
+		s.Fatal(err)
+	}
+
+	// open postgres and apply migrations
+	s.pg, err = pg.Open(cfg.Storages.Pg.Master, service.LF())
+	if err != nil {
+		s.Fatal(err)
+	}
+	db, _ := s.pg.Instance.DB()
+	if err := pg.NewMigration(db, cfg.Storages.Pg.MigPath, service.LF()).Up(); err != nil {
+		s.Fatal(err)
+	}
+	s.storage = newTelegramLinkPgStorage(s.pg)
+}
+
+func (s *telegramLinkPgStorageTestSuite) TearDownSuite() {
+	s.pg.Close()
+}
+
+func TestTelegramLinkPgStorageSuite(t *testing.T) {
+	suite.Run(t, new(telegramLinkPgStorageTestSuite))
+}
+
+func (s *telegramLinkPgStorageTestSuite) Test_LinkCode() {
+	code := &domain.TelegramLinkCode{Code: kit.NewRandString(), UserId: kit.NewId(), ExpiresAt: kit.Now().Add(time.Minute).Round(time.Millisecond)}
+	s.NoError(s.storage.SaveLinkCode(s.Ctx, code))
+	taken, err := s.storage.TakeLinkCode(s.Ctx, code.Code)
+	s.NoError(err)
+	s.Equal(code.UserId, taken.UserId)
+	s.True(code.ExpiresAt.Equal(taken.ExpiresAt))
+	taken, err = s.storage.TakeLinkCode(s.Ctx, code.Code)
+	s.NoError(err)
+	s.Nil(taken)
+}
+
+func (s *telegramLinkPgStorageTestSuite) Test_Link() {
+	tgUserId := rand.Int63()
+	link := &domain.TelegramLink{UserId: kit.NewId(), TelegramUserId: tgUserId, ChatId: tgUserId, Username: "trader", CreatedAt: kit.Now()}
+	s.NoError(s.storage.SaveLink(s.Ctx, link))
+	found, err := s.storage.GetLinkByTelegramUser(s.Ctx, tgUserId)
+	s.NoError(err)
+	s.Equal(link.UserId, found.UserId)
+	s.Equal("trader", found.Username)
+
+	// telegram account linked to another user is moved
+	other := &domain.TelegramLink{UserId: kit.NewId(), TelegramUserId: tgUserId, ChatId: tgUserId, CreatedAt: kit.Now()}
+	s.NoError(s.storage.SaveLink(s.Ctx, other))
+	found, err = s.storage.GetLinkByUser(s.Ctx, link.UserId)
+	s.NoError(err)
+	s.Nil(found)
+
+	s.NoError(s.storage.DeleteLink(s.Ctx, other.UserId))
+	found, err = s.storage.GetLinkByTelegramUser(s.Ctx, tgUserId)
+	s.NoError(err)
+	s.Nil(found)
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"github.com/mikhailbolshakov/cryptocare/src/domain"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/auth"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
+	"github.com/mikhailbolshakov/cryptocare/src/service"
+	"sync"
+	"time"
+)
+
+// userMemStorageImpl keeps users in memory
+type userMemStorageImpl struct {
+	sync.RWMutex
+	users map[string]*auth.User
+}
+
+func (s *userMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("user-mem-storage")
+}
+
+func NewUserMemStorage() domain.UserStorage {
+	return &userMemStorageImpl{
+		users: make(map[string]*auth.User),
+	}
+}
+
+func (s *userMemStorageImpl) copy(u *auth.User) *auth.User {
+	r := *u
+	r.Groups = append([]string(nil), u.Groups...)
+	r.Roles = append([]string(nil), u.Roles...)
+	return &r
+}
+
+func (s *userMemStorageImpl) CreateUser(ctx context.Context, user *auth.User) error {
+	s.l().Mth("create").C(ctx).F(log.FF{"userId": user.Id}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	s.users[user.Id] = s.copy(user)
+	return nil
+}
+
+func (s *userMemStorageImpl) UpdateUser(ctx context.Context, user *auth.User) error {
+	s.l().Mth("update").C(ctx).F(log.FF{"userId": user.Id}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	s.users[user.Id] = s.copy(user)
+	return nil
+}
+
+func (s *userMemStorageImpl) GetByUsername(ctx context.Context, username string) (*auth.User, error) {
+	s.l().Mth("get").C(ctx).F(log.FF{"username": username}).Trc()
+	if username == "" {
+		return nil, nil
+	}
+	s.RLock()
+	defer s.RUnlock()
+	for _, u := range s.users {
+		if u.Username == username {
+			return s.copy(u), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *userMemStorageImpl) GetUser(ctx context.Context, userId string) (*auth.User, error) {
+	s.l().Mth("get").C(ctx).F(log.FF{"userId": userId}).Trc()
+	s.RLock()
+	defer s.RUnlock()
+	if u, ok := s.users[userId]; ok {
+		return s.copy(u), nil
+	}
+	return nil, nil
+}
+
+func (s *userMemStorageImpl) GetUserByIds(ctx context.Context, userIds []string) ([]*auth.User, error) {
+	s.l().Mth("get-ids").C(ctx).Trc()
+	s.RLock()
+	defer s.RUnlock()
+	r := []*auth.User{}
+	for _, id := range userIds {
+		if u, ok := s.users[id]; ok {
+			r = append(r, s.copy(u))
+		}
+	}
+	return r, nil
+}
+
+func (s *userMemStorageImpl) DeleteUser(ctx context.Context, u *auth.User) error {
+	s.l().C(ctx).Mth("delete").F(log.FF{"userId": u.Id}).Dbg()
+	s.Lock()
+	defer s.Unlock()
+	delete(s.users, u.Id)
+	return nil
+}
+
+// sessionMemStorageImpl keeps sessions in memory
+type sessionMemStorageImpl struct {
+	sync.RWMutex
+	sessions map[string]*auth.Session
+}
+
+func (s *sessionMemStorageImpl) l() log.CLogger {
+	return service.L().Cmp("session-mem-storage")
+}
+
+func NewSessionMemStorage() auth.SessionStorage {
+	return &sessionMemStorageImpl{
+		sessions: make(map[string]*auth.Session),
+	}
+}
+
+func (s *sessionMemStorageImpl) copy(sess *auth.Session) *auth.Session {
+	r := *sess
+	r.Roles = append([]string(nil), sess.Roles...)
+	return &r
+}
+
+func (s *sessionMemStorageImpl) Get(ctx context.Context, sid string) (*auth.Session, error) {
+	s.l().Mth("get").C(ctx).F(log.FF{"sid": sid}).Trc()
+	s.RLock()
+	defer s.RUnlock()
+	if sess, ok := s.sessions[sid]; ok {
+		return s.copy(sess), nil
+	}
+	return nil, nil
+}
+
+func (s *sessionMemStorageImpl) GetByUser(ctx context.Context, uid string) ([]*auth.Session, error) {
+	s.l().C(ctx).Mth("get-by-user").F(log.FF{"uid": uid}).Trc()
+	s.RLock()
+	defer s.RUnlock()
+	r := []*auth.Session{}
+	for _, sess := range s.sessions {
+		if sess.UserId == uid && sess.LogoutAt == nil {
+			r = append(r, s.copy(sess))
+		}
+	}
+	return r, nil
+}
+
+func (s *sessionMemStorageImpl) CreateSession(ctx context.Context, session *auth.Session) error {
+	s.l().C(ctx).Mth("create").F(log.FF{"sid": session.Id}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	s.sessions[session.Id] = s.copy(session)
+	return nil
+}
+
+func (s *sessionMemStorageImpl) UpdateLastActivity(ctx context.Context, sid string, lastActivity time.Time) error {
+	s.l().Mth("update-activity").C(ctx).F(log.FF{"sid": sid}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	if sess, ok := s.sessions[sid]; ok {
+		sess.LastActivityAt = lastActivity
+	}
+	return nil
+}
+
+func (s *sessionMemStorageImpl) Logout(ctx context.Context, sid string, logoutAt time.Time) error {
+	s.l().Mth("logout").C(ctx).F(log.FF{"sid": sid}).Trc()
+	s.Lock()
+	defer s.Unlock()
+	if sess, ok := s.sessions[sid]; ok {
+		sess.LogoutAt = &logoutAt
+	}
+	return nil
+}
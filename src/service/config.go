@@ -3,10 +3,12 @@ package service
 import (
 	"github.com/mikhailbolshakov/cryptocare/src/kit/auth"
 	kitConfig "github.com/mikhailbolshakov/cryptocare/src/kit/config"
+	kitGrpc "github.com/mikhailbolshakov/cryptocare/src/kit/grpc"
 	kitHttp "github.com/mikhailbolshakov/cryptocare/src/kit/http"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/log"
 	kitAero "github.com/mikhailbolshakov/cryptocare/src/kit/storages/aerospike"
 	"github.com/mikhailbolshakov/cryptocare/src/kit/storages/pg"
+	"github.com/mikhailbolshakov/cryptocare/src/kit/telegram"
 	"os"
 	"path/filepath"
 )
@@ -15,8 +17,28 @@ import (
 // You can remove not needed types or add your own
 
 type Storages struct {
-	Aero *kitAero.Config
-	Pg   *pg.DbClusterConfig
+	Aero          *kitAero.Config
+	Pg            *pg.DbClusterConfig
+	Bids          string // Bids bid storage type (aero, memory)
+	Chains        string // Chains chain storage type (aero, memory)
+	Subscriptions string // Subscriptions subscription storage type (aero, memory, pg)
+	RateHistory   string `config:"rate-history"` // RateHistory rate history storage type (pg, memory)
+	Spreads       string // Spreads spread storage type (aero, memory)
+	Outbox        string // Outbox notification outbox storage type (pg, memory)
+	// DeliveryPolicies throttling windows and pending digests storage type (pg, memory)
+	DeliveryPolicies string `config:"delivery-policies"`
+	// TelegramLinks telegram account links storage type (pg, memory)
+	TelegramLinks string `config:"telegram-links"`
+	// TelegramChannels telegram channel verifications storage type (pg, memory)
+	TelegramChannels string `config:"telegram-channels"`
+	// TelegramAlerts telegram chain alerts storage type (pg, memory)
+	TelegramAlerts string `config:"telegram-alerts"`
+	// TelegramBots telegram bot accounts storage type (pg, memory)
+	TelegramBots string `config:"telegram-bots"`
+	// Users users and sessions storage type (pg, memory), pg storage caches users and sessions in aerospike
+	Users string
+	// EmailVerifications email address verifications storage type (pg, memory)
+	EmailVerifications string `config:"email-verifications"`
 }
 
 type Api struct {
@@ -31,11 +53,96 @@ type Address struct {
 }
 
 type ArbitrageNotificationTelegram struct {
-	Bot string
+	Bot               string
+	BaseUrl           string              `config:"base-url"` // BaseUrl Bot API url, public Bot API if empty
+	Commands          *TelegramCommands   // Commands interactive bot commands
+	ChannelCodeTtlSec int                 `config:"channel-code-ttl-sec"` // ChannelCodeTtlSec how long a code verifying a channel is valid
+	RateLimit         *telegram.RateLimit `config:"rate-limit"`           // RateLimit limits of sending messages, Bot API limits if empty
+	LiveAlerts        *TelegramLiveAlerts `config:"live-alerts"`          // LiveAlerts chain alerts updated while chains change
+	Bots              *TelegramBots       `config:"bots"`                 // Bots registry of bots notifications are sent from
+}
+
+// TelegramBots registry of bots, the bot configured by Bot is registered as the default one
+type TelegramBots struct {
+	TokenKey    string `config:"token-key"`    // TokenKey key tokens of bots are encrypted with, bots can't be registered if empty
+	CooldownSec int    `config:"cooldown-sec"` // CooldownSec how long a failed bot is tried after its secondary bot
+	ReloadSec   int    `config:"reload-sec"`   // ReloadSec how often bots are reloaded from storage
+}
+
+// TelegramLiveAlerts chain alerts are edited as profit of the chain changes and marked expired when the chain dies
+type TelegramLiveAlerts struct {
+	Enabled         bool
+	PeriodSec       int     `config:"period-sec"`        // PeriodSec how often alerts are refreshed
+	MinProfitChange float64 `config:"min-profit-change"` // MinProfitChange min change of profit in percents the alert is edited on
+	RetentionHours  int     `config:"retention-hours"`   // RetentionHours how long alerts are kept after the last update
+}
+
+// TelegramCommands bot receiving commands of users, updates are received by long polling if webhook url is empty
+type TelegramCommands struct {
+	Enabled        bool
+	Username       string // Username bot username, used to build links to the bot
+	WebhookUrl     string `config:"webhook-url"`       // WebhookUrl public url of the webhook endpoint, long polling is used if empty
+	WebhookSecret  string `config:"webhook-secret"`    // WebhookSecret secret Bot API passes with webhook requests
+	PollTimeoutSec int    `config:"poll-timeout-sec"`  // PollTimeoutSec long polling timeout
+	LinkCodeTtlSec int    `config:"link-code-ttl-sec"` // LinkCodeTtlSec how long a link code is valid
+}
+
+// ArbitrageNotificationEmail smtp server email notifications are sent through, email channel is disabled if host is empty
+type ArbitrageNotificationEmail struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string // From sender address
+	// CodeTtlSec how long a code verifying a recipient address is valid
+	CodeTtlSec int `config:"code-ttl-sec"`
+}
+
+type ArbitrageNotificationWebhook struct {
+	TimeoutSec int `config:"timeout-sec"` // TimeoutSec timeout of webhook request
+}
+
+// NotificationOutbox durable delivery of notifications
+type NotificationOutbox struct {
+	Workers        int // Workers number of delivery workers
+	BatchSize      int `config:"batch-size"`      // BatchSize how many deliveries a worker claims at once
+	PeriodMs       int `config:"period-ms"`       // PeriodMs how often workers poll the outbox
+	MaxAttempts    int `config:"max-attempts"`    // MaxAttempts delivery is moved to the dead-letter state after this number of failed attempts
+	BackoffSec     int `config:"backoff-sec"`     // BackoffSec delay before the first retry, it's doubled on each next retry
+	MaxBackoffSec  int `config:"max-backoff-sec"` // MaxBackoffSec max delay between retries
+	LeaseSec       int `config:"lease-sec"`       // LeaseSec claimed deliveries are claimed again if not completed during this time (e.g. worker crashed)
+	RetentionHours int `config:"retention-hours"` // RetentionHours how long delivered deliveries are kept
+	// DeadRetentionHours how long dead deliveries are kept, so that they can be replayed
+	DeadRetentionHours int `config:"dead-retention-hours"`
+}
+
+// NotificationTemplate Go text/template templates of a message
+type NotificationTemplate struct {
+	Subject string // Subject template of the message subject, supported by email only
+	Body    string // Body template of the message body
+}
+
+// NotificationTemplates templates by delivery type, default templates of the channel are used if empty
+type NotificationTemplates struct {
+	Chain  *NotificationTemplate
+	Spread *NotificationTemplate
+	Digest *NotificationTemplate
+}
+
+// ArbitrageNotificationTemplates global templates of channels, notifications of subscriptions can override them
+type ArbitrageNotificationTemplates struct {
+	PanelUrl string `config:"panel-url"` // PanelUrl base url of the panel, links to chain details point to it
+	Telegram *NotificationTemplates
+	Email    *NotificationTemplates
+	Webhook  *NotificationTemplates
 }
 
 type ArbitrageNotification struct {
-	Telegram *ArbitrageNotificationTelegram
+	Telegram  *ArbitrageNotificationTelegram
+	Email     *ArbitrageNotificationEmail
+	Webhook   *ArbitrageNotificationWebhook
+	Outbox    *NotificationOutbox
+	Templates *ArbitrageNotificationTemplates
 }
 
 type Arbitrage struct {
@@ -45,23 +152,107 @@ type Arbitrage struct {
 	BidProviderPeriodSec   int     `config:"bid-provider-period-sec"`
 	MinProfit              float64 `config:"min-profit"`
 	CheckLimit             bool    `config:"check-limit"`
+	BidMaxAgeSec           int     `config:"bid-max-age-sec"` // BidMaxAgeSec bids observed earlier are ignored when finding chains, 0 - no restriction
 	Notification           *ArbitrageNotification
+	Spread                 *SpreadDetector
+	Private                *PrivateChains
+}
+
+type PrivateChains struct {
+	Enabled   bool // Enabled if owners are notified about chains with their private bids
+	PeriodSec int  `config:"period-sec"` // PeriodSec how often private chains are searched
+}
+
+type SpreadDetector struct {
+	Enabled   bool    // Enabled if spreads are detected
+	PeriodSec int     `config:"period-sec"` // PeriodSec how often spreads are computed
+	MinSpread float64 `config:"min-spread"` // MinSpread min spread in percents
+	TtlSec    int     `config:"ttl-sec"`    // TtlSec how long spreads are kept in storage
+}
+
+// ChainRetentionClass retention of chains with profit not less than MinProfit
+type ChainRetentionClass struct {
+	MinProfit float64 `config:"min-profit"` // MinProfit min profit of the class in percents
+	TtlSec    int     `config:"ttl-sec"`    // TtlSec how long chains of the class are kept in storage
+}
+
+type ChainRetention struct {
+	DefaultTtlSec int                    `config:"default-ttl-sec"` // DefaultTtlSec ttl of chains not falling into any class
+	Classes       []*ChainRetentionClass // Classes retention classes by profit
+}
+
+type BidRetention struct {
+	P2PTtlSec    int `config:"p2p-ttl-sec"`    // P2PTtlSec ttl of p2p bids
+	SpotTtlSec   int `config:"spot-ttl-sec"`   // SpotTtlSec ttl of spot bids
+	ManualTtlSec int `config:"manual-ttl-sec"` // ManualTtlSec ttl of manual bids
+	// ManualMinTtlSec min ttl of manual bids the owner is allowed to specify
+	ManualMinTtlSec int `config:"manual-min-ttl-sec"`
+	// ManualMaxTtlSec max ttl of manual bids the owner is allowed to specify
+	ManualMaxTtlSec int `config:"manual-max-ttl-sec"`
+}
+
+type ChainArchive struct {
+	Enabled   bool   // Enabled if expiring chains are archived
+	Storage   string // Storage archive storage type (pg, file, memory)
+	Path      string // Path folder for file archive
+	PeriodSec int    `config:"period-sec"` // PeriodSec how often archiver looks for expiring chains
+}
+
+type RateHistory struct {
+	Enabled       bool // Enabled if rate history is collected
+	ResolutionSec int  `config:"resolution-sec"` // ResolutionSec time series resolution
+	RetentionDays int  `config:"retention-days"` // RetentionDays how long points are kept
+}
+
+type ReferenceRates struct {
+	Base      string // Base currency chain profits and volumes are normalized to
+	Source    string // Source type of rates source (file, http)
+	Path      string // Path to the rates file for the file source
+	Url       string // Url of rates for the http source
+	PeriodSec int    `config:"period-sec"` // PeriodSec how often rates are refreshed
+}
+
+type Market struct {
+	History        *RateHistory
+	ReferenceRates *ReferenceRates `config:"reference-rates"`
+}
+
+type Retention struct {
+	Chains  *ChainRetention
+	Bids    *BidRetention
+	Archive *ChainArchive
 }
 
 type Dev struct {
 	Enabled               bool
 	BidGeneratorPeriodSec int `config:"bid-gen-period-sec"`
 	BidGeneratorBidsCount int `config:"bid-gen-bids-count"`
+	Simulator             *MarketSimulator
+}
+
+// MarketSimulator simulated market generating bids in dev mode
+type MarketSimulator struct {
+	Seed        int64   // Seed of the random generator, the same seed reproduces the same market. 0 - random seed is taken and logged
+	Exchanges   string  // Exchanges comma separated list of simulated exchanges
+	Methods     string  // Methods comma separated list of simulated payment methods
+	Merchants   int     // Merchants number of simulated merchants exposing bids
+	Volatility  float64 // Volatility std deviation of asset price change per step in percents
+	Correlation float64 // Correlation of asset price changes with the common market move (0..1)
+	Spread      float64 // Spread min discount of bids to the mid rate in percents, it keeps the simulated market free of arbitrage
+	Scenario    string  // Scenario path to a yaml scenario injecting arbitrage cycles at given times
 }
 
 type Config struct {
 	Log       *log.Config
 	Http      *kitHttp.Config
+	Grpc      *kitGrpc.Config
 	Api       *Api
 	Storages  *Storages
 	Auth      *auth.Config
 	Dev       *Dev
 	Arbitrage *Arbitrage
+	Retention *Retention
+	Market    *Market
 }
 
 func LoadConfig() (*Config, error) {
@@ -26,8 +26,8 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/arbitrage/bids": {
-            "post": {
+        "/arbitrage/archive/chains/{chainId}": {
+            "get": {
                 "consumes": [
                     "application/json"
                 ],
@@ -35,25 +35,29 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscription"
+                    "arbitrage"
                 ],
-                "summary": "allows creation or updating an exchange bid",
+                "summary": "retrieves archived chain by id",
                 "parameters": [
                     {
-                        "description": "bid request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/http.BidRequest"
-                        }
+                        "type": "string",
+                        "description": "chain id",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.Bid"
+                            "$ref": "#/definitions/http.ProfitableChain"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
                         }
                     },
                     "500": {
@@ -65,7 +69,7 @@ var doc = `{
                 }
             }
         },
-        "/arbitrage/chains": {
+        "/arbitrage/bids": {
             "get": {
                 "consumes": [
                     "application/json"
@@ -74,26 +78,14 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "arbitrage"
+                    "bids"
                 ],
-                "summary": "retrieves profitable deal chains by criteria",
+                "summary": "retrieves manual bids of the owner",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "comma separated list of assets",
-                        "name": "assets",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "if chains are retrieved with bid info",
-                        "name": "withBids",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "page size",
-                        "name": "size",
+                        "description": "owner id, the caller by default. Only admin is allowed to request bids of other users",
+                        "name": "ownerId",
                         "in": "query"
                     }
                 ],
@@ -101,45 +93,14 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.ProfitableChains"
+                            "$ref": "#/definitions/http.Bids"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/http.Error"
                         }
-                    }
-                }
-            }
-        },
-        "/arbitrage/chains/{chainId}/details": {
-            "get": {
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "arbitrage"
-                ],
-                "summary": "retrieves profitable deal chain details by id",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "chain id",
-                        "name": "chainId",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/http.ProfitableChain"
-                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -148,9 +109,7 @@ var doc = `{
                         }
                     }
                 }
-            }
-        },
-        "/auth/login": {
+            },
             "post": {
                 "consumes": [
                     "application/json"
@@ -159,17 +118,17 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "bids"
                 ],
-                "summary": "logins user by email/password",
+                "summary": "creates a manual bid (e.g. OTC quote) owned by the caller",
                 "parameters": [
                     {
-                        "description": "auth request",
-                        "name": "loginRequest",
+                        "description": "bid request",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.LoginRequest"
+                            "$ref": "#/definitions/http.ManualBidRequest"
                         }
                     }
                 ],
@@ -177,33 +136,8 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.LoginResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/http.Error"
+                            "$ref": "#/definitions/http.Bid"
                         }
-                    }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "logouts user",
-                "responses": {
-                    "200": {
-                        "description": ""
                     },
                     "400": {
                         "description": "Bad Request",
@@ -220,8 +154,9 @@ var doc = `{
                 }
             }
         },
-        "/auth/password": {
+        "/arbitrage/bids/bulk": {
             "post": {
+                "description": "bids are validated before storing, so either all bids are created or none",
                 "consumes": [
                     "application/json"
                 ],
@@ -229,23 +164,26 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "bids"
                 ],
-                "summary": "sets a new password for the user",
+                "summary": "creates manual bids owned by the caller in bulk",
                 "parameters": [
                     {
-                        "description": "set password request",
+                        "description": "bids request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.SetPasswordRequest"
+                            "$ref": "#/definitions/http.ManualBidsRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": ""
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.Bids"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -262,8 +200,9 @@ var doc = `{
                 }
             }
         },
-        "/auth/registration": {
-            "post": {
+        "/arbitrage/bids/staleness": {
+            "get": {
+                "description": "bids observed earlier than the configured max age are stale and ignored when finding chains",
                 "consumes": [
                     "application/json"
                 ],
@@ -271,25 +210,14 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
-                ],
-                "summary": "registers a new client",
-                "parameters": [
-                    {
-                        "description": "registration request",
-                        "name": "regRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/http.ClientRegistrationRequest"
-                        }
-                    }
+                    "arbitrage"
                 ],
+                "summary": "retrieves bids freshness by exchanges",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.ClientUser"
+                            "$ref": "#/definitions/http.ExchangesStaleness"
                         }
                     },
                     "500": {
@@ -301,8 +229,8 @@ var doc = `{
                 }
             }
         },
-        "/auth/token/refresh": {
-            "post": {
+        "/arbitrage/bids/{bidId}": {
+            "get": {
                 "consumes": [
                     "application/json"
                 ],
@@ -310,18 +238,33 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "bids"
+                ],
+                "summary": "retrieves a manual bid",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "bid id",
+                        "name": "bidId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "refreshes auth token",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.SessionToken"
+                            "$ref": "#/definitions/http.Bid"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/http.Error"
                         }
@@ -333,23 +276,9 @@ var doc = `{
                         }
                     }
                 }
-            }
-        },
-        "/ready": {
-            "get": {
-                "tags": [
-                    "system"
-                ],
-                "summary": "check system is ready",
-                "responses": {
-                    "200": {
-                        "description": ""
-                    }
-                }
-            }
-        },
-        "/users/{userId}/subscriptions": {
-            "get": {
+            },
+            "put": {
+                "description": "only the owner or admin is allowed to update the bid, ttl is counted from the update time",
                 "consumes": [
                     "application/json"
                 ],
@@ -357,23 +286,44 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscription"
+                    "bids"
                 ],
-                "summary": "retrieves user's subscriptions",
+                "summary": "updates a manual bid",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user id",
-                        "name": "userId",
+                        "description": "bid id",
+                        "name": "bidId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "bid request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ManualBidRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.Subscriptions"
+                            "$ref": "#/definitions/http.Bid"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
                         }
                     },
                     "500": {
@@ -384,7 +334,8 @@ var doc = `{
                     }
                 }
             },
-            "post": {
+            "delete": {
+                "description": "only the owner or admin is allowed to withdraw the bid",
                 "consumes": [
                     "application/json"
                 ],
@@ -392,32 +343,32 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscription"
+                    "bids"
                 ],
-                "summary": "creates a new user subscription",
+                "summary": "withdraws a manual bid",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user id",
-                        "name": "userId",
+                        "description": "bid id",
+                        "name": "bidId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "subscription request",
-                        "name": "regRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/http.SubscriptionRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/http.Subscription"
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
                         }
                     },
                     "500": {
@@ -429,8 +380,9 @@ var doc = `{
                 }
             }
         },
-        "/users/{userId}/subscriptions/{subscriptionId}": {
-            "put": {
+        "/arbitrage/chains": {
+            "get": {
+                "description": "chains are sorted by creation time (the latest first) unless sortBy is specified\nto page through the result pass nextCursor from the previous response as cursor",
                 "consumes": [
                     "application/json"
                 ],
@@ -438,40 +390,1761 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscription"
+                    "arbitrage"
                 ],
-                "summary": "updates a subscription",
+                "summary": "retrieves profitable deal chains by criteria",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user id",
-                        "name": "userId",
-                        "in": "path",
-                        "required": true
+                        "description": "comma separated list of assets",
+                        "name": "assets",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "user id",
-                        "name": "subscriptionId",
-                        "in": "path",
-                        "required": true
+                        "description": "comma separated list of methods",
+                        "name": "methods",
+                        "in": "query"
                     },
                     {
-                        "description": "subscription request",
-                        "name": "regRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/http.SubscriptionRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/http.Subscription"
-                        }
+                        "type": "string",
+                        "description": "comma separated list of exchange codes",
+                        "name": "exchanges",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated list of bid types",
+                        "name": "bidTypes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min profit in percents",
+                        "name": "minProfit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max profit in percents",
+                        "name": "maxProfit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "min chain depth",
+                        "name": "minDepth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max chain depth",
+                        "name": "maxDepth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min absolute profit in the base currency",
+                        "name": "minBaseProfit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min volume in the base currency",
+                        "name": "minBaseVolume",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "chains created after the time (RFC3339)",
+                        "name": "createdAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "if chains are retrieved with bid info",
+                        "name": "withBids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated sort fields (profit, createdAt, score, baseProfit, baseVolume) with direction, e.g. 'profit desc,createdAt desc'",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor returned with the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page index (ignored if cursor is specified)",
+                        "name": "index",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfitableChains"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/arbitrage/chains/search": {
+            "get": {
+                "description": "chains containing private bids are visible to the caller only, the most profitable go first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "arbitrage"
+                ],
+                "summary": "finds chains on demand combining the public market with private bids of the caller",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "comma separated list of assets chains start and end with, assets of the caller's private bids by default",
+                        "name": "assets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "if true, only chains containing private bids are retrieved",
+                        "name": "onlyPrivate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max number of chains",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfitableChains"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/arbitrage/chains/{chainId}/details": {
+            "get": {
+                "description": "if the chain has expired, it's retrieved from the archive",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "arbitrage"
+                ],
+                "summary": "retrieves profitable deal chain details by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chain id",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfitableChain"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/arbitrage/spreads": {
+            "get": {
+                "description": "spreads are sorted by spread (the highest first)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "arbitrage"
+                ],
+                "summary": "retrieves detected cross-exchange and cross-method spreads by criteria",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "comma separated list of assets (either base or quote)",
+                        "name": "assets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated list of spread types (cross-exchange, cross-method)",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated list of exchange codes",
+                        "name": "exchanges",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated list of methods",
+                        "name": "methods",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min spread in percents",
+                        "name": "minSpread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page index",
+                        "name": "index",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.Spreads"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/arbitrage/spreads/{spreadId}": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "arbitrage"
+                ],
+                "summary": "retrieves spread by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "spread id",
+                        "name": "spreadId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.Spread"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "logins user by email/password",
+                "parameters": [
+                    {
+                        "description": "auth request",
+                        "name": "loginRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "logouts user",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "sets a new password for the user",
+                "parameters": [
+                    {
+                        "description": "set password request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/registration": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "registers a new client",
+                "parameters": [
+                    {
+                        "description": "registration request",
+                        "name": "regRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ClientRegistrationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.ClientUser"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/token/refresh": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "refreshes auth token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SessionToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/market/overview": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "market"
+                ],
+                "summary": "retrieves matrix of the best rates for all pairs per exchange and overall from the current bids",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "takes into account bids with the payment method only",
+                        "name": "method",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.MarketOverview"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/market/pairs/{src}/{trg}/history": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "market"
+                ],
+                "summary": "retrieves rate history of the pair as OHLC candles of the best rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "source asset",
+                        "name": "src",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "target asset",
+                        "name": "trg",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "exchange code",
+                        "name": "exchange",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "payment method",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "period start (RFC3339), by default 24h before the end",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "period end (RFC3339), by default now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "candle duration in seconds, by default 3600",
+                        "name": "resolution",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.RateHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/market/reference-rates": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "market"
+                ],
+                "summary": "retrieves reference FX rates used to normalize chains to the base currency",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.ReferenceRates"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/outbox": {
+            "get": {
+                "description": "the latest deliveries go first. Only admin is allowed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "retrieves notification deliveries recorded in the outbox",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "comma separated list of statuses (pending, sending, delivered, dead)",
+                        "name": "statuses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "notification channel",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "subscription id",
+                        "name": "subscriptionId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page index",
+                        "name": "index",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.OutboxDeliveries"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/outbox/replay": {
+            "post": {
+                "description": "attempts start over. Only dead deliveries are allowed to be replayed. Only admin is allowed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "moves dead deliveries back to the outbox queue",
+                "parameters": [
+                    {
+                        "description": "replay request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.OutboxReplayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.OutboxDeliveriesList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/telegram/bots": {
+            "get": {
+                "description": "the default bot goes first. Only admin is allowed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "retrieves all telegram bots with their health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.TelegramBot"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "the token is checked by Bot API and stored encrypted. Subscriptions of the users of the bot are notified by it unless they select another bot. Only admin is allowed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "registers a telegram bot notifications are sent from",
+                "parameters": [
+                    {
+                        "description": "bot",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.TelegramBotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.TelegramBot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/telegram/bots/{botId}": {
+            "get": {
+                "description": "Only admin is allowed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "retrieves a telegram bot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "bot id",
+                        "name": "botId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.TelegramBot"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "empty token keeps the current one. The default bot is configured and can't be updated. Only admin is allowed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "updates a telegram bot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "bot id",
+                        "name": "botId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "bot",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.TelegramBotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.TelegramBot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "notifications selecting the bot are sent by the bot of the user or the default one. Only admin is allowed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "deletes a telegram bot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "bot id",
+                        "name": "botId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/templates/preview": {
+            "post": {
+                "description": "if template isn't specified, the template the channel uses by default is rendered. If chainId isn't specified, a sample chain is taken",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "renders a notification template against a sample opportunity",
+                "parameters": [
+                    {
+                        "description": "preview request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.TemplatePreviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.TemplatePreview"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/ready": {
+            "get": {
+                "tags": [
+                    "system"
+                ],
+                "summary": "check system is ready",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/telegram/webhook": {
+            "post": {
+                "description": "Bot API passes the configured secret in X-Telegram-Bot-Api-Secret-Token header",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telegram"
+                ],
+                "summary": "receives bot updates from Bot API",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/email/addresses": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email"
+                ],
+                "summary": "retrieves email verifications of the user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.EmailVerification"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/email/addresses/verification": {
+            "post": {
+                "description": "email notifications stay inactive until all the recipients are confirmed. The code isn't returned, it's sent to the address only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email"
+                ],
+                "summary": "sends a code confirming the recipient address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "address",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.EmailVerificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.EmailVerification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/email/addresses/verification/confirm": {
+            "post": {
+                "description": "email notifications of the user waiting for confirmation of the address are activated",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email"
+                ],
+                "summary": "confirms the recipient address with the code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "address and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.EmailVerificationConfirmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.EmailVerification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/subscriptions": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "retrieves user's subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.Subscriptions"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "creates a new user subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "subscription request",
+                        "name": "regRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.Subscription"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/subscriptions/preview": {
+            "post": {
+                "description": "evaluates the filter against chains found within the last hours and estimates how many alerts the filter gives per day",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "backtests a subscription filter against the chain history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "preview request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SubscriptionPreviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SubscriptionPreview"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/subscriptions/{subscriptionId}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "updates a subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "subscriptionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "subscription request",
+                        "name": "regRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.Subscription"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/telegram": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telegram"
+                ],
+                "summary": "retrieves linked telegram account of the user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.TelegramLink"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telegram"
+                ],
+                "summary": "unlinks telegram account of the user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/telegram/channels": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telegram"
+                ],
+                "summary": "retrieves telegram channel verifications of the user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.TelegramChannelVerification"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/telegram/channels/{channelId}/verification": {
+            "post": {
+                "description": "the user posts \"/verify code\" to the channel, notifications to the channel stay inactive until it's verified",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telegram"
+                ],
+                "summary": "issues a one-time code verifying the telegram channel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "telegram channel id",
+                        "name": "channelId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.TelegramChannelVerification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/telegram/channels/{channelId}/verification/confirm": {
+            "post": {
+                "description": "the channel is verified if the code has been posted or both the bot and the linked telegram account are admins of the channel",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telegram"
+                ],
+                "summary": "confirms the telegram channel through Bot API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "telegram channel id",
+                        "name": "channelId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.TelegramChannelVerification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/telegram/link": {
+            "post": {
+                "description": "the user sends \"/link code\" to the bot or opens the returned url, the code can be used once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telegram"
+                ],
+                "summary": "issues a one-time code linking a telegram account to the user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.TelegramLinkCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Error"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -487,6 +2160,10 @@ var doc = `{
         "http.Bid": {
             "type": "object",
             "properties": {
+                "ageSec": {
+                    "description": "AgeSec - how old the bid quote is (in seconds) by the moment of response",
+                    "type": "integer"
+                },
                 "available": {
                     "description": "Available - available volume",
                     "type": "number"
@@ -495,10 +2172,18 @@ var doc = `{
                     "description": "ExchangeCode - exchange code",
                     "type": "string"
                 },
+                "expiresAt": {
+                    "description": "ExpiresAt - when the bid expires",
+                    "type": "string"
+                },
                 "id": {
                     "description": "Id",
                     "type": "string"
                 },
+                "ingestedAt": {
+                    "description": "IngestedAt - when the bid has been put to the storage",
+                    "type": "string"
+                },
                 "link": {
                     "description": "Link - link to the bid on the exchange",
                     "type": "string"
@@ -518,6 +2203,18 @@ var doc = `{
                     "description": "MinLimit - min limit",
                     "type": "number"
                 },
+                "observedAt": {
+                    "description": "ObservedAt - when the bid has been observed on the source",
+                    "type": "string"
+                },
+                "ownerId": {
+                    "description": "OwnerId - user who manages the manual bid",
+                    "type": "string"
+                },
+                "private": {
+                    "description": "Private - if true, the bid is taken into account only in calculations of the owner",
+                    "type": "boolean"
+                },
                 "rate": {
                     "description": "Rate - conversion rate",
                     "type": "number"
@@ -540,23 +2237,209 @@ var doc = `{
                 }
             }
         },
-        "http.BidRequest": {
+        "http.Bids": {
             "type": "object",
             "properties": {
-                "available": {
-                    "description": "Available available volume",
-                    "type": "number"
+                "bids": {
+                    "description": "Bids - bids",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.Bid"
+                    }
+                }
+            }
+        },
+        "http.ClientRegistrationRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email - user's email",
+                    "type": "string"
+                },
+                "firstName": {
+                    "description": "FirstName - user's first name",
+                    "type": "string"
+                },
+                "lastName": {
+                    "description": "LastName - user's last name",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Password - password",
+                    "type": "string"
+                }
+            }
+        },
+        "http.ClientUser": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email - email",
+                    "type": "string"
+                },
+                "firstName": {
+                    "description": "FirstName - user's first name",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Id - user ID",
+                    "type": "string"
+                },
+                "lastName": {
+                    "description": "LastName - user's last name",
+                    "type": "string"
+                }
+            }
+        },
+        "http.EmailVerification": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email recipient address",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "ExpiresAt code expiration time",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status verification status (pending, verified)",
+                    "type": "string"
+                },
+                "verifiedAt": {
+                    "description": "VerifiedAt when verified",
+                    "type": "string"
+                }
+            }
+        },
+        "http.EmailVerificationConfirmRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code code sent to the address",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email recipient address",
+                    "type": "string"
+                }
+            }
+        },
+        "http.EmailVerificationRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email recipient address",
+                    "type": "string"
+                }
+            }
+        },
+        "http.Error": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is error code provided by error producer",
+                    "type": "string"
+                },
+                "details": {
+                    "description": "Details is additional info provided by error producer",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "message": {
+                    "description": "Message is error description",
+                    "type": "string"
+                },
+                "translationKey": {
+                    "description": "TranslationKey is error code translation key",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is error type (panic, system, business)",
+                    "type": "string"
+                }
+            }
+        },
+        "http.ExchangeStaleness": {
+            "type": "object",
+            "properties": {
+                "bids": {
+                    "description": "Bids - number of bids",
+                    "type": "integer"
                 },
                 "exchangeCode": {
                     "description": "ExchangeCode - exchange code",
                     "type": "string"
                 },
-                "id": {
-                    "description": "Id",
+                "latestAgeSec": {
+                    "description": "LatestAgeSec - age of the latest bid in seconds",
+                    "type": "integer"
+                },
+                "latestObservedAt": {
+                    "description": "LatestObservedAt - when the latest bid has been observed",
+                    "type": "string"
+                },
+                "oldestObservedAt": {
+                    "description": "OldestObservedAt - when the oldest bid has been observed",
+                    "type": "string"
+                },
+                "staleBids": {
+                    "description": "StaleBids - number of bids older than max age, they are ignored when finding chains",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.ExchangesStaleness": {
+            "type": "object",
+            "properties": {
+                "exchanges": {
+                    "description": "Exchanges - staleness by exchanges",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.ExchangeStaleness"
+                    }
+                }
+            }
+        },
+        "http.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email - login",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Password - password",
+                    "type": "string"
+                }
+            }
+        },
+        "http.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "description": "Token - auth token must be passed as  \"Authorization Bearer\" header for all the requests (except ones which don't require authorization)",
+                    "$ref": "#/definitions/http.SessionToken"
+                },
+                "userId": {
+                    "description": "UserId - ID of account",
+                    "type": "string"
+                }
+            }
+        },
+        "http.ManualBidRequest": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "description": "Available available volume",
+                    "type": "number"
+                },
+                "exchangeCode": {
+                    "description": "ExchangeCode - exchange code, might be a name of the OTC desk",
                     "type": "string"
                 },
                 "link": {
-                    "description": "Link - link to the bid",
+                    "description": "Link - link to the quote",
                     "type": "string"
                 },
                 "maxLimit": {
@@ -574,125 +2457,269 @@ var doc = `{
                     "description": "MinLimit - minimum limit",
                     "type": "number"
                 },
+                "observedAt": {
+                    "description": "ObservedAt - when the quote has been observed, if empty, the current time is taken",
+                    "type": "string"
+                },
+                "private": {
+                    "description": "Private - if true, the bid is combined with the public market only in calculations of the owner",
+                    "type": "boolean"
+                },
                 "rate": {
                     "description": "Rate - conversion rate",
                     "type": "number"
                 },
-                "src": {
-                    "description": "SrcAsset - source asset",
-                    "type": "string"
+                "src": {
+                    "description": "SrcAsset - source asset",
+                    "type": "string"
+                },
+                "trg": {
+                    "description": "TrgAsset - target asset",
+                    "type": "string"
+                },
+                "ttlSec": {
+                    "description": "TtlSec - how long the bid lives (in seconds), if empty, the default ttl is taken",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.ManualBidsRequest": {
+            "type": "object",
+            "properties": {
+                "bids": {
+                    "description": "Bids - bids",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.ManualBidRequest"
+                    }
+                }
+            }
+        },
+        "http.MarketOverview": {
+            "type": "object",
+            "properties": {
+                "assets": {
+                    "description": "Assets - all assets",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "exchanges": {
+                    "description": "Exchanges - all exchanges",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "trg": {
-                    "description": "TrgAsset - target asset",
+                "pairs": {
+                    "description": "Pairs - pairs ordered by source and target assets",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.PairOverview"
+                    }
+                }
+            }
+        },
+        "http.NotificationTemplate": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "description": "Body template of the message body",
                     "type": "string"
                 },
-                "userId": {
-                    "description": "UserId - user who expose the bid",
+                "subject": {
+                    "description": "Subject template of the message subject, supported by email only",
                     "type": "string"
                 }
             }
         },
-        "http.ClientRegistrationRequest": {
+        "http.NotificationTemplates": {
             "type": "object",
             "properties": {
-                "email": {
-                    "description": "Email - user's email",
-                    "type": "string"
+                "chain": {
+                    "description": "Chain template of chain messages",
+                    "$ref": "#/definitions/http.NotificationTemplate"
                 },
-                "firstName": {
-                    "description": "FirstName - user's first name",
-                    "type": "string"
+                "digest": {
+                    "description": "Digest template of digest messages",
+                    "$ref": "#/definitions/http.NotificationTemplate"
                 },
-                "lastName": {
-                    "description": "LastName - user's last name",
-                    "type": "string"
+                "spread": {
+                    "description": "Spread template of spread messages",
+                    "$ref": "#/definitions/http.NotificationTemplate"
+                }
+            }
+        },
+        "http.OutboxDeliveries": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "description": "Deliveries",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.OutboxDelivery"
+                    }
                 },
-                "password": {
-                    "description": "Password - password",
-                    "type": "string"
+                "index": {
+                    "description": "Index page index",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Total number of deliveries satisfying criteria",
+                    "type": "integer"
                 }
             }
         },
-        "http.ClientUser": {
+        "http.OutboxDeliveriesList": {
             "type": "object",
             "properties": {
-                "email": {
-                    "description": "Email - email",
+                "deliveries": {
+                    "description": "Deliveries",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.OutboxDelivery"
+                    }
+                }
+            }
+        },
+        "http.OutboxDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts - number of attempts made",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "CreatedAt - when the delivery is recorded",
                     "type": "string"
                 },
-                "firstName": {
-                    "description": "FirstName - user's first name",
+                "deliveredAt": {
+                    "description": "DeliveredAt - when the delivery succeeded",
                     "type": "string"
                 },
                 "id": {
-                    "description": "Id - user ID",
+                    "description": "Id - delivery id",
                     "type": "string"
                 },
-                "lastName": {
-                    "description": "LastName - user's last name",
+                "lastError": {
+                    "description": "LastError - error of the last failed attempt",
                     "type": "string"
-                }
-            }
-        },
-        "http.Error": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "description": "Code is error code provided by error producer",
+                },
+                "nextAttemptAt": {
+                    "description": "NextAttemptAt - when the delivery is attempted next",
                     "type": "string"
                 },
-                "details": {
-                    "description": "Details is additional info provided by error producer",
-                    "type": "object",
-                    "additionalProperties": true
+                "notification": {
+                    "description": "Notification - notification the opportunity is delivered to",
+                    "$ref": "#/definitions/http.SubscriptionNotification"
                 },
-                "message": {
-                    "description": "Message is error description",
+                "opportunityId": {
+                    "description": "OpportunityId - chain or spread id",
                     "type": "string"
                 },
-                "translationKey": {
-                    "description": "TranslationKey is error code translation key",
+                "opportunityType": {
+                    "description": "OpportunityType - type of the opportunity (chain, spread)",
                     "type": "string"
                 },
-                "type": {
-                    "description": "Type is error type (panic, system, business)",
+                "status": {
+                    "description": "Status - delivery status (pending, sending, delivered, dead)",
+                    "type": "string"
+                },
+                "subscriptionId": {
+                    "description": "SubscriptionId - subscription the delivery is made for",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt - when the delivery is updated last time",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "UserId - owner of the subscription",
                     "type": "string"
                 }
             }
         },
-        "http.LoginRequest": {
+        "http.OutboxReplayRequest": {
             "type": "object",
             "properties": {
-                "email": {
-                    "description": "Email - login",
+                "ids": {
+                    "description": "Ids - ids of dead deliveries",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "http.PairOverview": {
+            "type": "object",
+            "properties": {
+                "exchanges": {
+                    "description": "Exchanges - aggregated per exchange",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.PairRate"
+                    }
+                },
+                "overall": {
+                    "description": "Overall - aggregated across all exchanges",
+                    "$ref": "#/definitions/http.PairRate"
+                },
+                "src": {
+                    "description": "Src - source asset",
                     "type": "string"
                 },
-                "password": {
-                    "description": "Password - password",
+                "trg": {
+                    "description": "Trg - target asset",
                     "type": "string"
                 }
             }
         },
-        "http.LoginResponse": {
+        "http.PairRate": {
             "type": "object",
             "properties": {
-                "token": {
-                    "description": "Token - auth token must be passed as  \"Authorization Bearer\" header for all the requests (except ones which don't require authorization)",
-                    "$ref": "#/definitions/http.SessionToken"
+                "bestRate": {
+                    "description": "BestRate - the best rate",
+                    "type": "number"
                 },
-                "userId": {
-                    "description": "UserId - ID of account",
+                "bids": {
+                    "description": "Bids - number of bids",
+                    "type": "integer"
+                },
+                "exchangeCode": {
+                    "description": "ExchangeCode - exchange code, for the overall rate it's the exchange with the best rate",
                     "type": "string"
+                },
+                "volume": {
+                    "description": "Volume - total available volume",
+                    "type": "number"
                 }
             }
         },
         "http.ProfitableChain": {
             "type": "object",
             "properties": {
+                "archived": {
+                    "description": "Archived - if the chain has expired and retrieved from the archive",
+                    "type": "boolean"
+                },
                 "asset": {
                     "description": "Asset - the target asset",
                     "type": "string"
                 },
+                "baseCurrency": {
+                    "description": "BaseCurrency - currency the chain is normalized to, empty if reference rate is unknown",
+                    "type": "string"
+                },
+                "baseProfit": {
+                    "description": "BaseProfit - absolute profit in the base currency if the whole volume is traded",
+                    "type": "number"
+                },
+                "baseVolume": {
+                    "description": "BaseVolume - volume in the base currency",
+                    "type": "number"
+                },
                 "bidAssets": {
                     "description": "BidAssets sequence of asset for each bids like [RUB, USD, USDT]",
                     "type": "array",
@@ -700,6 +2727,13 @@ var doc = `{
                         "type": "string"
                     }
                 },
+                "bidTypes": {
+                    "description": "BidTypes distinct types of bids",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "bids": {
                     "description": "Bids sequence of bids",
                     "type": "array",
@@ -722,6 +2756,10 @@ var doc = `{
                         "type": "string"
                     }
                 },
+                "expiresAt": {
+                    "description": "ExpiresAt - when this chain expires",
+                    "type": "string"
+                },
                 "id": {
                     "description": "Id - chain Id, calculated as hash from bidIds",
                     "type": "string"
@@ -733,9 +2771,25 @@ var doc = `{
                         "type": "string"
                     }
                 },
+                "observedAt": {
+                    "description": "ObservedAt - when the oldest bid of the chain has been observed",
+                    "type": "string"
+                },
+                "ownerId": {
+                    "description": "OwnerId - user whose private bids the chain contains",
+                    "type": "string"
+                },
                 "profitShare": {
                     "description": "ProfitShare profit share",
                     "type": "number"
+                },
+                "score": {
+                    "description": "Score profit (in percents) per one conversion",
+                    "type": "number"
+                },
+                "volume": {
+                    "description": "Volume - executable volume of the chain in the chain asset",
+                    "type": "number"
                 }
             }
         },
@@ -748,6 +2802,95 @@ var doc = `{
                     "items": {
                         "$ref": "#/definitions/http.ProfitableChain"
                     }
+                },
+                "index": {
+                    "description": "Index page index",
+                    "type": "integer"
+                },
+                "nextCursor": {
+                    "description": "NextCursor cursor to retrieve the next page, empty if it's the last page",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total number of chains satisfying criteria",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.RateCandle": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "description": "Close - the best rate at the end of the candle",
+                    "type": "number"
+                },
+                "high": {
+                    "description": "High - max of the best rate",
+                    "type": "number"
+                },
+                "low": {
+                    "description": "Low - min of the best rate",
+                    "type": "number"
+                },
+                "medianRate": {
+                    "description": "MedianRate - median rate within the candle",
+                    "type": "number"
+                },
+                "open": {
+                    "description": "Open - the best rate at the beginning of the candle",
+                    "type": "number"
+                },
+                "time": {
+                    "description": "Time - candle start",
+                    "type": "string"
+                },
+                "volume": {
+                    "description": "Volume - average available volume within the candle",
+                    "type": "number"
+                }
+            }
+        },
+        "http.RateHistory": {
+            "type": "object",
+            "properties": {
+                "candles": {
+                    "description": "Candles - candles ordered by time",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.RateCandle"
+                    }
+                },
+                "resolutionSec": {
+                    "description": "ResolutionSec - candle duration in seconds",
+                    "type": "integer"
+                },
+                "src": {
+                    "description": "Src - source asset",
+                    "type": "string"
+                },
+                "trg": {
+                    "description": "Trg - target asset",
+                    "type": "string"
+                }
+            }
+        },
+        "http.ReferenceRates": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "description": "Base - base currency",
+                    "type": "string"
+                },
+                "rates": {
+                    "description": "Rates - price of one unit of the asset in the base currency",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt - when rates have been loaded, empty if rates aren't loaded yet",
+                    "type": "string"
                 }
             }
         },
@@ -766,26 +2909,121 @@ var doc = `{
                     "description": "RefreshToken",
                     "type": "string"
                 },
-                "refreshTokenExpiresAt": {
-                    "description": "RefreshToken - when refresh token expires",
-                    "type": "string"
+                "refreshTokenExpiresAt": {
+                    "description": "RefreshToken - when refresh token expires",
+                    "type": "string"
+                },
+                "sessionId": {
+                    "description": "SessionId - session ID",
+                    "type": "string"
+                }
+            }
+        },
+        "http.SetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "newPassword": {
+                    "description": "NewPassword - new password",
+                    "type": "string"
+                },
+                "prevPassword": {
+                    "description": "PrevPassword - current password",
+                    "type": "string"
+                }
+            }
+        },
+        "http.Spread": {
+            "type": "object",
+            "properties": {
+                "baseAsset": {
+                    "description": "BaseAsset - asset which is bought and sold",
+                    "type": "string"
+                },
+                "buy": {
+                    "description": "Buy - bid converting the quote asset to the base asset",
+                    "$ref": "#/definitions/http.Bid"
+                },
+                "buyPrice": {
+                    "description": "BuyPrice - price of the base asset when buying",
+                    "type": "number"
+                },
+                "createdAt": {
+                    "description": "CreatedAt - when the spread has been detected",
+                    "type": "string"
+                },
+                "exchangeCodes": {
+                    "description": "ExchangeCodes - exchanges of both legs",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "expiresAt": {
+                    "description": "ExpiresAt - when the spread expires",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Id - spread Id",
+                    "type": "string"
+                },
+                "methods": {
+                    "description": "Methods - methods of both legs",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "observedAt": {
+                    "description": "ObservedAt - when the oldest bid has been observed",
+                    "type": "string"
+                },
+                "profit": {
+                    "description": "Profit - profit in the quote asset if the whole volume is traded",
+                    "type": "number"
+                },
+                "quoteAsset": {
+                    "description": "QuoteAsset - asset the base asset is paid with",
+                    "type": "string"
+                },
+                "sell": {
+                    "description": "Sell - bid converting the base asset to the quote asset",
+                    "$ref": "#/definitions/http.Bid"
+                },
+                "sellPrice": {
+                    "description": "SellPrice - price of the base asset when selling",
+                    "type": "number"
+                },
+                "spread": {
+                    "description": "Spread - spread in percents",
+                    "type": "number"
                 },
-                "sessionId": {
-                    "description": "SessionId - session ID",
+                "type": {
+                    "description": "Type - spread type (cross-exchange, cross-method)",
                     "type": "string"
+                },
+                "volume": {
+                    "description": "Volume - amount of the base asset which can be traded",
+                    "type": "number"
                 }
             }
         },
-        "http.SetPasswordRequest": {
+        "http.Spreads": {
             "type": "object",
             "properties": {
-                "newPassword": {
-                    "description": "NewPassword - new password",
-                    "type": "string"
+                "index": {
+                    "description": "Index page index",
+                    "type": "integer"
                 },
-                "prevPassword": {
-                    "description": "PrevPassword - current password",
-                    "type": "string"
+                "spreads": {
+                    "description": "Spreads",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.Spread"
+                    }
+                },
+                "total": {
+                    "description": "Total number of spreads satisfying criteria",
+                    "type": "integer"
                 }
             }
         },
@@ -811,6 +3049,10 @@ var doc = `{
                         "$ref": "#/definitions/http.SubscriptionNotification"
                     }
                 },
+                "policy": {
+                    "description": "Policy delivery policy",
+                    "$ref": "#/definitions/http.SubscriptionDeliveryPolicy"
+                },
                 "userId": {
                     "description": "UserId owner of the subscription. Might be empty",
                     "type": "string"
@@ -834,6 +3076,10 @@ var doc = `{
                         "type": "string"
                     }
                 },
+                "expression": {
+                    "description": "Expression boolean expression an opportunity must satisfy in addition to the other criteria, e.g. profit \u003e= 1.5 \u0026\u0026 !(\"bybit\" in exchanges) \u0026\u0026 volumeUsd \u003e 500\nvariables: opportunity, asset, baseCurrency (strings); profit (percents), depth, score, volume, baseVolume, baseProfit, volumeUsd, profitUsd, ageSec (numbers);\nvolumeUsd, profitUsd are available only if the base currency is USD;\nassets, exchanges, methods, bidTypes (lists of strings). Operators: \u0026\u0026 || ! == != \u003c \u003c= \u003e \u003e= + - * / in, function size()",
+                    "type": "string"
+                },
                 "maxDepth": {
                     "description": "MaxDepth max depth of chains",
                     "type": "integer"
@@ -846,8 +3092,65 @@ var doc = `{
                     }
                 },
                 "minProfit": {
-                    "description": "MinProfit min profit of chains",
+                    "description": "MinProfit min profit of chains, for spreads it's a min spread",
                     "type": "number"
+                },
+                "opportunities": {
+                    "description": "Opportunities types of opportunities (chain, spread) subscription is notified about. If empty, only chains are notified",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "http.SubscriptionDeliveryPolicy": {
+            "type": "object",
+            "properties": {
+                "digest": {
+                    "description": "Digest if specified, opportunities are sent as periodic digests instead of a message per opportunity",
+                    "$ref": "#/definitions/http.SubscriptionDigest"
+                },
+                "maxMessages": {
+                    "description": "MaxMessages max number of messages per window, unlimited if empty",
+                    "type": "integer"
+                },
+                "quietHours": {
+                    "description": "QuietHours opportunities matched during quiet hours are dropped, digests are postponed",
+                    "$ref": "#/definitions/http.SubscriptionQuietHours"
+                },
+                "windowSec": {
+                    "description": "WindowSec throttling window in seconds (60 - 86400)",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.SubscriptionDigest": {
+            "type": "object",
+            "properties": {
+                "maxItems": {
+                    "description": "MaxItems max number of the most profitable opportunities in the digest, 10 if empty",
+                    "type": "integer"
+                },
+                "periodMin": {
+                    "description": "PeriodMin how often the digest is sent in minutes (5 - 1440)",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.SubscriptionEmailNotificationDetails": {
+            "type": "object",
+            "properties": {
+                "to": {
+                    "description": "To recipients addresses",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "verified": {
+                    "description": "Verified if all the recipients are confirmed, notifications to unconfirmed addresses stay inactive (read only)",
+                    "type": "boolean"
                 }
             }
         },
@@ -858,6 +3161,10 @@ var doc = `{
                     "description": "Channel notification channel",
                     "type": "string"
                 },
+                "email": {
+                    "description": "Email email details",
+                    "$ref": "#/definitions/http.SubscriptionEmailNotificationDetails"
+                },
                 "id": {
                     "description": "Id notification id",
                     "type": "string"
@@ -869,17 +3176,140 @@ var doc = `{
                 "telegram": {
                     "description": "Telegram telegram details",
                     "$ref": "#/definitions/http.SubscriptionTelegramNotificationDetails"
+                },
+                "templates": {
+                    "description": "Templates custom message templates",
+                    "$ref": "#/definitions/http.NotificationTemplates"
+                },
+                "webhook": {
+                    "description": "Webhook webhook details",
+                    "$ref": "#/definitions/http.SubscriptionWebhookNotificationDetails"
                 }
             }
         },
         "http.SubscriptionNotificationRequest": {
             "type": "object",
             "properties": {
+                "channel": {
+                    "description": "Channel notification channel (telegram, email, webhook), telegram if empty",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email email details",
+                    "$ref": "#/definitions/http.SubscriptionEmailNotificationDetails"
+                },
                 "isActive": {
                     "type": "boolean"
                 },
+                "templates": {
+                    "description": "Templates custom message templates",
+                    "$ref": "#/definitions/http.NotificationTemplates"
+                },
+                "tgBotId": {
+                    "description": "TelegramBotId bot telegram notifications are sent from, if empty, the bot of the user or the default one",
+                    "type": "string"
+                },
                 "tgChannel": {
+                    "description": "TelegramChannel telegram channel",
+                    "type": "integer"
+                },
+                "webhook": {
+                    "description": "Webhook webhook details",
+                    "$ref": "#/definitions/http.SubscriptionWebhookNotificationDetails"
+                }
+            }
+        },
+        "http.SubscriptionPreview": {
+            "type": "object",
+            "properties": {
+                "estimatedDaily": {
+                    "description": "EstimatedDaily estimated number of alerts per day, delivery policies aren't taken into account",
+                    "type": "number"
+                },
+                "from": {
+                    "description": "From start of the evaluated period",
+                    "type": "string"
+                },
+                "historyFrom": {
+                    "description": "HistoryFrom when the oldest chain of the history has been found",
+                    "type": "string"
+                },
+                "hours": {
+                    "description": "Hours matches by hours, the oldest hour goes first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.SubscriptionPreviewHour"
+                    }
+                },
+                "matches": {
+                    "description": "Matches number of chains matching the filter",
+                    "type": "integer"
+                },
+                "samples": {
+                    "description": "Samples the most profitable matching chains",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.ProfitableChain"
+                    }
+                },
+                "scanned": {
+                    "description": "Scanned number of chains evaluated",
+                    "type": "integer"
+                },
+                "to": {
+                    "description": "To end of the evaluated period",
+                    "type": "string"
+                },
+                "truncated": {
+                    "description": "Truncated if the history has more chains than evaluated, so matches are lower bound",
+                    "type": "boolean"
+                }
+            }
+        },
+        "http.SubscriptionPreviewHour": {
+            "type": "object",
+            "properties": {
+                "hour": {
+                    "description": "Hour start of the hour",
+                    "type": "string"
+                },
+                "matches": {
+                    "description": "Matches number of chains matching the filter found within the hour",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.SubscriptionPreviewRequest": {
+            "type": "object",
+            "properties": {
+                "filter": {
+                    "description": "Filter candidate filter",
+                    "$ref": "#/definitions/http.SubscriptionChainFilter"
+                },
+                "hours": {
+                    "description": "Hours how many last hours of the history are evaluated (1..168), 24 if empty",
                     "type": "integer"
+                },
+                "samples": {
+                    "description": "Samples max number of sample chains (1..50), 5 if empty",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.SubscriptionQuietHours": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "From start of quiet hours (HH:MM)",
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "Timezone IANA timezone of the subscriber (e.g. Europe/Moscow), UTC if empty",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "To end of quiet hours (HH:MM), if less than From, quiet hours cross midnight",
+                    "type": "string"
                 }
             }
         },
@@ -896,15 +3326,40 @@ var doc = `{
                     "items": {
                         "$ref": "#/definitions/http.SubscriptionNotificationRequest"
                     }
+                },
+                "policy": {
+                    "description": "Policy delivery policy, all matched opportunities are sent immediately if empty",
+                    "$ref": "#/definitions/http.SubscriptionDeliveryPolicy"
                 }
             }
         },
         "http.SubscriptionTelegramNotificationDetails": {
             "type": "object",
             "properties": {
+                "botId": {
+                    "description": "BotId bot notifications are sent from, if empty, the bot of the user or the default one",
+                    "type": "string"
+                },
                 "channel": {
                     "description": "Channel telegram channel",
                     "type": "integer"
+                },
+                "verified": {
+                    "description": "Verified if the channel is verified, notifications to unverified channels are inactive",
+                    "type": "boolean"
+                }
+            }
+        },
+        "http.SubscriptionWebhookNotificationDetails": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "description": "Secret key payloads are signed with (HMAC-SHA256), generated if empty",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Url https endpoint payloads are posted to",
+                    "type": "string"
                 }
             }
         },
@@ -918,6 +3373,191 @@ var doc = `{
                     }
                 }
             }
+        },
+        "http.TelegramBot": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "CreatedAt when registered",
+                    "type": "string"
+                },
+                "failedAt": {
+                    "description": "FailedAt when the bot failed last time",
+                    "type": "string"
+                },
+                "health": {
+                    "description": "Health health status (healthy, unhealthy)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Id bot id, the default bot has id \"default\"",
+                    "type": "string"
+                },
+                "isActive": {
+                    "description": "IsActive if the bot is active",
+                    "type": "boolean"
+                },
+                "lastError": {
+                    "description": "LastError the last error of the bot",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name bot name",
+                    "type": "string"
+                },
+                "ratePerSec": {
+                    "description": "RatePerSec messages per second the bot sends to all chats",
+                    "type": "number"
+                },
+                "secondaryId": {
+                    "description": "SecondaryId bot messages fail over to",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt when updated",
+                    "type": "string"
+                },
+                "userIds": {
+                    "description": "UserIds users notified by the bot by default",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "username": {
+                    "description": "Username telegram username of the bot",
+                    "type": "string"
+                }
+            }
+        },
+        "http.TelegramBotRequest": {
+            "type": "object",
+            "properties": {
+                "isActive": {
+                    "description": "IsActive if the bot is active",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "Name bot name",
+                    "type": "string"
+                },
+                "ratePerSec": {
+                    "description": "RatePerSec messages per second the bot sends to all chats, Bot API limit if empty",
+                    "type": "number"
+                },
+                "secondaryId": {
+                    "description": "SecondaryId bot messages fail over to if the bot fails",
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Token bot token, it's checked by Bot API. On update empty token keeps the current one",
+                    "type": "string"
+                },
+                "userIds": {
+                    "description": "UserIds users notified by the bot by default, if empty, the bot is shared and selected by subscriptions explicitly",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "http.TelegramChannelVerification": {
+            "type": "object",
+            "properties": {
+                "channelId": {
+                    "description": "ChannelId telegram chat id of the channel",
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "Code one-time code to post to the channel, empty if verified",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "ExpiresAt code expiration time",
+                    "type": "string"
+                },
+                "method": {
+                    "description": "Method how the channel has been verified (code, admin, link)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status verification status (pending, verified)",
+                    "type": "string"
+                },
+                "verifiedAt": {
+                    "description": "VerifiedAt when verified",
+                    "type": "string"
+                }
+            }
+        },
+        "http.TelegramLink": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "CreatedAt when linked",
+                    "type": "string"
+                },
+                "telegramUserId": {
+                    "description": "TelegramUserId telegram user id",
+                    "type": "integer"
+                },
+                "username": {
+                    "description": "Username telegram username",
+                    "type": "string"
+                }
+            }
+        },
+        "http.TelegramLinkCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code one-time code",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "ExpiresAt code expiration time",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Url deep link opening the bot with the code",
+                    "type": "string"
+                }
+            }
+        },
+        "http.TemplatePreview": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "description": "Body message body",
+                    "type": "string"
+                },
+                "subject": {
+                    "description": "Subject message subject",
+                    "type": "string"
+                }
+            }
+        },
+        "http.TemplatePreviewRequest": {
+            "type": "object",
+            "properties": {
+                "chainId": {
+                    "description": "ChainId chain to render, if empty, a sample chain is taken",
+                    "type": "string"
+                },
+                "channel": {
+                    "description": "Channel notification channel (telegram, email, webhook)",
+                    "type": "string"
+                },
+                "template": {
+                    "description": "Template template to render, if empty, the template the channel uses by default is rendered",
+                    "$ref": "#/definitions/http.NotificationTemplate"
+                },
+                "type": {
+                    "description": "Type delivery type (chain, spread, digest)",
+                    "type": "string"
+                }
+            }
         }
     }
 }`